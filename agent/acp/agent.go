@@ -15,6 +15,26 @@ import (
 
 func init() {
 	core.RegisterAgent("acp", New)
+	core.RegisterAgentOptions("acp", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "cmd", Type: core.OptionStringOrList},
+		{Name: "cli_path", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "command", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "args", Type: core.OptionStringList},
+		{Name: "env", Type: core.OptionTable},
+		{Name: "auth_method", Type: core.OptionString},
+		{Name: "display_name", Type: core.OptionString},
+		{Name: "mode", Type: core.OptionString},
+		{Name: "vision", Type: core.OptionBool},
+	},
+	Conflicts: [][]string{
+		{"cmd", "cli_path", "command"},
+	},
 }
 
 // Agent runs an ACP (Agent Client Protocol) agent subprocess over stdio JSON-RPC.
@@ -34,6 +54,10 @@ type Agent struct {
 	// session/new. Empty means "use whatever the agent selects by default".
 	mode string
 
+	// noVision marks agents whose model cannot read the images referenced
+	// in prompts (options["vision"] = false).
+	noVision bool
+
 	// listUnsupported caches a negative result after we probe the agent
 	// for sessionCapabilities.list once. Eliminates spawn cost on
 	// subsequent `/ls` invocations against agents that don't implement
@@ -67,7 +91,7 @@ var _ sessionCallbacks = (*Agent)(nil)
 // New builds an acp agent from project options.
 // Required: options["command"] — executable name or path for the ACP agent.
 // Optional: options["args"], options["env"], options["auth_method"],
-// options["display_name"], options["mode"].
+// options["display_name"], options["mode"], options["vision"].
 func New(opts map[string]any) (core.Agent, error) {
 	workDir, _ := opts["work_dir"].(string)
 	if workDir == "" {
@@ -93,6 +117,7 @@ func New(opts map[string]any) (core.Agent, error) {
 	}
 	mode, _ := opts["mode"].(string)
 	mode = strings.TrimSpace(mode)
+	vision, ok := opts["vision"].(bool)
 
 	return &Agent{
 		workDir:     workDir,
@@ -104,6 +129,7 @@ func New(opts map[string]any) (core.Agent, error) {
 		authMethod:  authMethod,
 		displayName: displayName,
 		mode:        mode,
+		noVision:    ok && !vision,
 	}, nil
 }
 
@@ -177,6 +203,13 @@ func parseStringSlice(v any) []string {
 
 func (a *Agent) Name() string { return "acp" }
 
+// SupportsImages implements core.ImageSupportReporter.
+func (a *Agent) SupportsImages() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !a.noVision
+}
+
 func (a *Agent) SetWorkDir(dir string) {
 	a.mu.Lock()
 	a.workDir = dir
@@ -213,6 +246,9 @@ func (a *Agent) WorkspaceAgentOptions() map[string]any {
 	if a.displayName != "" {
 		opts["display_name"] = a.displayName
 	}
+	if a.noVision {
+		opts["vision"] = false
+	}
 	return opts
 }
 
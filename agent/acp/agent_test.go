@@ -70,3 +70,24 @@ func TestWorkspaceAgentOptions(t *testing.T) {
 		t.Fatalf("display_name = %q, want Copilot ACP", got)
 	}
 }
+
+func TestNew_VisionOption(t *testing.T) {
+	a, err := New(map[string]any{"command": "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.(core.ImageSupportReporter).SupportsImages() {
+		t.Fatal("images unsupported by default")
+	}
+
+	a, err = New(map[string]any{"command": "true", "vision": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.(core.ImageSupportReporter).SupportsImages() {
+		t.Fatal("vision = false still reports image support")
+	}
+	if got, ok := a.(*Agent).WorkspaceAgentOptions()["vision"].(bool); !ok || got {
+		t.Fatalf("workspace vision = %v, %v; want false", got, ok)
+	}
+}
@@ -21,6 +21,23 @@ import (
 
 func init() {
 	core.RegisterAgent("antigravity", New)
+	core.RegisterAgentOptions("antigravity", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "cmd", Type: core.OptionStringOrList},
+		{Name: "cli_path", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "command", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "mode", Type: core.OptionString},
+		{Name: "model", Type: core.OptionString},
+		{Name: "timeout_mins", Type: core.OptionInt},
+	},
+	Conflicts: [][]string{
+		{"cmd", "cli_path", "command"},
+	},
 }
 
 // Agent drives the Antigravity CLI (agy) in headless mode.
@@ -374,6 +391,12 @@ func (a *Agent) ListProviders() []core.ProviderConfig {
 	return result
 }
 
+// ProviderKeyEnv returns the env entry that passes key to a session as the
+// active provider's API key.
+func (a *Agent) ProviderKeyEnv(key string) []string {
+	return []string{"GEMINI_API_KEY=" + key}
+}
+
 func (a *Agent) providerEnvLocked() []string {
 	if a.activeIdx < 0 || a.activeIdx >= len(a.providers) {
 		return nil
@@ -22,6 +22,37 @@ import (
 
 func init() {
 	core.RegisterAgent("claudecode", New)
+	core.RegisterAgentOptions("claudecode", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "cmd", Type: core.OptionStringOrList},
+		{Name: "cli_path", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "command", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "mode", Type: core.OptionString},
+		{Name: "model", Type: core.OptionString},
+		{Name: "cmd_args_flag", Type: core.OptionString},
+		{Name: "cli_args_flag", Type: core.OptionString, Deprecated: "use cmd_args_flag"},
+		{Name: "allowed_tools", Type: core.OptionStringList},
+		{Name: "disallowed_tools", Type: core.OptionStringList},
+		{Name: "system_prompt", Type: core.OptionString},
+		{Name: "append_system_prompt", Type: core.OptionString},
+		{Name: "reasoning_effort", Type: core.OptionString},
+		{Name: "max_context_tokens", Type: core.OptionInt},
+		{Name: "plugin_dir", Type: core.OptionStringOrList},
+		{Name: "env", Type: core.OptionTable},
+		{Name: "router_url", Type: core.OptionString},
+		{Name: "router_api_key", Type: core.OptionString},
+		{Name: "run_as_user", Type: core.OptionString},
+		{Name: "run_as_env", Type: core.OptionStringList},
+	},
+	Conflicts: [][]string{
+		{"cmd", "cli_path", "command"},
+		{"cmd_args_flag", "cli_args_flag"},
+	},
 }
 
 // Agent drives Claude Code CLI using --input-format stream-json
@@ -1166,9 +1197,9 @@ func (a *Agent) ListProviders() []core.ProviderConfig {
 //  1. We use ANTHROPIC_AUTH_TOKEN (Bearer) instead of ANTHROPIC_API_KEY
 //     (x-api-key). Claude Code validates API keys against api.anthropic.com
 //     which hangs for third-party endpoints; Bearer auth skips that check.
-//  2. If the provider sets thinking (e.g. "disabled"), a local reverse proxy
-//     rewrites the thinking parameter for compatibility with providers that
-//     don't support adaptive thinking.
+//  2. If the provider sets thinking (e.g. "disabled") or [proxy] rules, a
+//     local reverse proxy rewrites requests (thinking, model names, stripped
+//     fields, headers, max_tokens) for compatibility with the provider.
 //
 // For env-only providers (Bedrock, Vertex, Foundry) that don't set base_url
 // but use CLAUDE_CODE_USE_BEDROCK/VERTEX/FOUNDRY env vars, the thinking
@@ -1179,11 +1210,16 @@ func (a *Agent) providerEnvLocked() []string {
 		return nil
 	}
 	p := a.providers[a.activeIdx]
+	rules := p.Proxy
+	rules.Thinking = p.Thinking
+	if rules.CassetteMode == core.CassetteRecord {
+		rules.Secrets = append([]string{p.APIKey}, p.APIKeys...)
+	}
 	var env []string
 
 	if p.BaseURL != "" {
-		if p.Thinking != "" {
-			if err := a.ensureProviderProxyLocked(p.BaseURL, p.Thinking); err != nil {
+		if !rules.Empty() {
+			if err := a.ensureProviderProxyLocked(p.BaseURL, rules); err != nil {
 				slog.Error("providerproxy: failed to start", "error", err)
 				env = append(env, "ANTHROPIC_BASE_URL="+p.BaseURL)
 			} else {
@@ -1202,13 +1238,13 @@ func (a *Agent) providerEnvLocked() []string {
 			env = append(env, "ANTHROPIC_MODEL="+p.Model)
 		}
 	} else {
-		// Check for env-only providers (Bedrock, Vertex, Foundry) that need thinking rewrite.
-		if p.Thinking != "" {
+		// Check for env-only providers (Bedrock, Vertex, Foundry) that need request rewrites.
+		if !rules.Empty() {
 			providerType := detectEnvOnlyProviderType(p.Env)
 			if providerType != "" {
 				targetURL := getDefaultEndpointForProviderType(providerType)
 				if targetURL != "" {
-					if err := a.ensureProviderProxyLocked(targetURL, p.Thinking); err != nil {
+					if err := a.ensureProviderProxyLocked(targetURL, rules); err != nil {
 						slog.Error("providerproxy: failed to start for "+providerType, "error", err)
 						a.stopProviderProxyLocked()
 					} else {
@@ -1216,7 +1252,7 @@ func (a *Agent) providerEnvLocked() []string {
 						baseURLEnvVar := getBaseURLEnvVarForProviderType(providerType)
 						env = append(env, baseURLEnvVar+"="+a.proxyLocalURL)
 						env = append(env, "NO_PROXY=127.0.0.1")
-						slog.Info("claudecode: thinking rewrite proxy enabled for "+providerType,
+						slog.Info("claudecode: provider proxy enabled for "+providerType,
 							"target", targetURL, "local", a.proxyLocalURL, "thinking", p.Thinking)
 					}
 				} else {
@@ -1243,6 +1279,18 @@ func (a *Agent) providerEnvLocked() []string {
 	return env
 }
 
+// ProviderKeyEnv returns the env entries that pass key to a session as the
+// active provider's API key. Providers with a base URL send it as a bearer
+// token, like providerEnvLocked.
+func (a *Agent) ProviderKeyEnv(key string) []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.activeIdx >= 0 && a.activeIdx < len(a.providers) && a.providers[a.activeIdx].BaseURL != "" {
+		return []string{"ANTHROPIC_AUTH_TOKEN=" + key, "ANTHROPIC_API_KEY="}
+	}
+	return []string{"ANTHROPIC_API_KEY=" + key}
+}
+
 func (a *Agent) runtimeEnvLocked() []string {
 	// configEnv (from config.toml [env]) is lower priority than provider keys or
 	// session-injected vars, but must survive SetSessionEnv calls (which only
@@ -1286,12 +1334,14 @@ func claudeEnvManagesProviderRouting(env []string) bool {
 	return false
 }
 
-func (a *Agent) ensureProviderProxyLocked(targetURL, thinkingOverride string) error {
-	if a.providerProxy != nil && a.proxyLocalURL != "" {
+// ensureProviderProxyLocked starts the provider proxy, replacing a running
+// one whose target or rules no longer match the active provider.
+func (a *Agent) ensureProviderProxyLocked(targetURL string, rules core.ProxyRules) error {
+	if a.providerProxy != nil && a.proxyLocalURL != "" && a.providerProxy.Serves(targetURL, rules) {
 		return nil
 	}
 	a.stopProviderProxyLocked()
-	proxy, localURL, err := core.NewProviderProxy(targetURL, thinkingOverride)
+	proxy, localURL, err := core.NewProviderProxyWithRules(targetURL, rules)
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -931,3 +932,15 @@ func TestNew_WorkDirDoesNotExist(t *testing.T) {
 		t.Fatalf("expected 'does not exist' in error, got: %v", err)
 	}
 }
+
+func TestAgent_ProviderKeyEnv(t *testing.T) {
+	a := &Agent{providers: []core.ProviderConfig{{Name: "direct"}, {Name: "relay", BaseURL: "https://relay.example.com"}}}
+	a.activeIdx = 0
+	if got := a.ProviderKeyEnv("sk-2"); !slices.Equal(got, []string{"ANTHROPIC_API_KEY=sk-2"}) {
+		t.Errorf("direct provider env = %q", got)
+	}
+	a.activeIdx = 1
+	if got := a.ProviderKeyEnv("sk-2"); !slices.Equal(got, []string{"ANTHROPIC_AUTH_TOKEN=sk-2", "ANTHROPIC_API_KEY="}) {
+		t.Errorf("base URL provider env = %q", got)
+	}
+}
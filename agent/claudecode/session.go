@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -836,6 +837,17 @@ func (cs *claudeSession) handleResult(raw map[string]any) {
 		cs.usageMu.Unlock()
 	}
 
+	// The CLI reports failed API calls as an error result ("API Error: 529
+	// ... overloaded"). Surface provider outages as EventError so the engine
+	// can retry the turn on another provider.
+	if isErr, _ := raw["is_error"].(bool); isErr && !isCompaction && core.IsProviderOutage(errors.New(content)) {
+		select {
+		case cs.events <- core.Event{Type: core.EventError, Error: errors.New(content), SessionID: cs.CurrentSessionID()}:
+		case <-cs.ctx.Done():
+		}
+		return
+	}
+
 	evt := core.Event{
 		Type:                     core.EventResult,
 		Content:                  content,
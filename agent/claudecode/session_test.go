@@ -49,6 +49,39 @@ func TestHandleResultParsesUsage(t *testing.T) {
 	}
 }
 
+func TestHandleResultProviderOutageIsError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cs := &claudeSession{
+		events: make(chan core.Event, 4),
+		ctx:    ctx,
+	}
+	cs.sessionID.Store("test-session")
+	cs.alive.Store(true)
+
+	cs.handleResult(map[string]any{
+		"type":       "result",
+		"is_error":   true,
+		"result":     `API Error: 529 {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+		"session_id": "test-session",
+	})
+	evt := <-cs.events
+	if evt.Type != core.EventError || evt.Error == nil {
+		t.Fatalf("event = %+v, want EventError", evt)
+	}
+
+	cs.handleResult(map[string]any{
+		"type":       "result",
+		"is_error":   true,
+		"result":     "Prompt is too long",
+		"session_id": "test-session",
+	})
+	if evt := <-cs.events; evt.Type != core.EventResult {
+		t.Fatalf("non-outage error result type = %q, want %q", evt.Type, core.EventResult)
+	}
+}
+
 // TestHandleResultCompactionSubtypeIsNotTerminal is a regression test for
 // issue #481: Claude Code's mid-turn context compaction emits a
 // `type:"result"` event with `subtype:"compact"` (newer CLI) or
@@ -20,6 +20,29 @@ import (
 
 func init() {
 	core.RegisterAgent("codex", New)
+	core.RegisterAgentOptions("codex", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "cmd", Type: core.OptionStringOrList},
+		{Name: "cli_path", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "command", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "mode", Type: core.OptionString},
+		{Name: "model", Type: core.OptionString},
+		{Name: "backend", Type: core.OptionString},
+		{Name: "app_server_url", Type: core.OptionString},
+		{Name: "codex_home", Type: core.OptionString},
+		{Name: "system_prompt", Type: core.OptionString},
+		{Name: "append_system_prompt", Type: core.OptionString},
+		{Name: "reasoning_effort", Type: core.OptionString},
+		{Name: "env", Type: core.OptionTable},
+	},
+	Conflicts: [][]string{
+		{"cmd", "cli_path", "command"},
+	},
 }
 
 // Agent drives OpenAI Codex CLI using `codex exec --json`.
@@ -489,6 +512,10 @@ func (a *Agent) StartSession(ctx context.Context, sessionID string) (core.AgentS
 		baseURL = a.providers[a.activeIdx].BaseURL
 	}
 	provName, provAPIKey, provWireAPI, provHeaders := a.activeProviderCodexConfig()
+	if key := getenvFromList(a.sessionEnv, "OPENAI_API_KEY"); key != "" && provAPIKey != "" {
+		// A rotated pool key for this session replaces the configured one.
+		provAPIKey = key
+	}
 	a.mu.Unlock()
 
 	if provName != "" {
@@ -749,6 +776,12 @@ func (a *Agent) ListProviders() []core.ProviderConfig {
 	return result
 }
 
+// ProviderKeyEnv returns the env entry that passes key to a session as the
+// active provider's API key.
+func (a *Agent) ProviderKeyEnv(key string) []string {
+	return []string{"OPENAI_API_KEY=" + key}
+}
+
 func (a *Agent) providerEnvLocked() []string {
 	if a.activeIdx < 0 || a.activeIdx >= len(a.providers) {
 		return nil
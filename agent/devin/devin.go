@@ -33,6 +33,24 @@ import (
 
 func init() {
 	core.RegisterAgent("devin", New)
+	core.RegisterAgentOptions("devin", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "command", Type: core.OptionString},
+		{Name: "cmd", Type: core.OptionStringOrList},
+		{Name: "args", Type: core.OptionStringList},
+		{Name: "env", Type: core.OptionTable},
+		{Name: "auth_method", Type: core.OptionString},
+		{Name: "display_name", Type: core.OptionString},
+		{Name: "mode", Type: core.OptionString},
+	},
+	Conflicts: [][]string{
+		{"cmd", "command"},
+	},
 }
 
 // Agent embeds *acp.Agent so it inherits StartSession, ListSessions,
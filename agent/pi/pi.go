@@ -18,6 +18,25 @@ import (
 
 func init() {
 	core.RegisterAgent("pi", New)
+	core.RegisterAgentOptions("pi", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "cmd", Type: core.OptionStringOrList},
+		{Name: "cli_path", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "command", Type: core.OptionString, Deprecated: "use cmd"},
+		{Name: "mode", Type: core.OptionString},
+		{Name: "model", Type: core.OptionString},
+		{Name: "thinking", Type: core.OptionString},
+		{Name: "rpc", Type: core.OptionBool},
+		{Name: "vision", Type: core.OptionBool},
+	},
+	Conflicts: [][]string{
+		{"cmd", "cli_path", "command"},
+	},
 }
 
 // Agent drives the pi coding agent CLI.
@@ -30,6 +49,7 @@ type Agent struct {
 	mode         string // "default" | "yolo"
 	thinking     string // reasoning effort: off, minimal, low, medium, high, xhigh
 	rpc          bool   // true = --mode rpc (persistent, extension_ui); false = --mode json (one-shot, default)
+	noVision     bool   // model cannot read attached images (vision = false)
 	sessionEnv   []string
 	mu           sync.Mutex
 }
@@ -44,6 +64,7 @@ func New(opts map[string]any) (core.Agent, error) {
 	mode = normalizeMode(mode)
 	thinking, _ := opts["thinking"].(string)
 	rpc, _ := opts["rpc"].(bool)
+	vision, hasVision := opts["vision"].(bool)
 
 	cmd, extraArgs := core.ParseCmdOpts(opts, "pi")
 
@@ -67,6 +88,7 @@ func New(opts map[string]any) (core.Agent, error) {
 		mode:         mode,
 		thinking:     thinking,
 		rpc:          rpc,
+		noVision:     hasVision && !vision,
 	}, nil
 }
 
@@ -83,6 +105,13 @@ func (a *Agent) Name() string           { return "pi" }
 func (a *Agent) CLIBinaryName() string  { return a.cmd }
 func (a *Agent) CLIDisplayName() string { return "Pi" }
 
+// SupportsImages implements core.ImageSupportReporter.
+func (a *Agent) SupportsImages() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return !a.noVision
+}
+
 // WorkspaceAgentOptions implements core.WorkspaceAgentOptionSnapshotter.
 // It returns the user-configured options that must propagate to per-workspace
 // agents reconstructed by the engine in multi-workspace mode. work_dir is
@@ -102,6 +131,9 @@ func (a *Agent) WorkspaceAgentOptions() map[string]any {
 	if a.thinking != "" {
 		opts["thinking"] = a.thinking
 	}
+	if a.noVision {
+		opts["vision"] = false
+	}
 	return opts
 }
 
@@ -2825,3 +2825,17 @@ func TestHandleAgentEnd_SkipsAssistantWithoutUsage(t *testing.T) {
 		t.Errorf("InputTokens = %d, want 3000", usage.InputTokens)
 	}
 }
+
+func TestNew_VisionOption(t *testing.T) {
+	ag, err := New(map[string]any{"cmd": "echo", "vision": false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	a := ag.(*Agent)
+	if a.SupportsImages() {
+		t.Error("vision = false still reports image support")
+	}
+	if got := a.WorkspaceAgentOptions()["vision"]; got != false {
+		t.Errorf("workspace vision = %v, want false", got)
+	}
+}
@@ -19,6 +19,16 @@ import (
 
 func init() {
 	core.RegisterAgent("reasonix", New)
+	core.RegisterAgentOptions("reasonix", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "serve_url", Type: core.OptionString, Required: true},
+		{Name: "mode", Type: core.OptionString},
+	},
 }
 
 // Agent drives a remote reasonix serve instance.
@@ -16,6 +16,25 @@ import (
 
 func init() {
 	core.RegisterAgent("tmux", New)
+	core.RegisterAgentOptions("tmux", optionSchema)
+}
+
+// optionSchema lists the options New reads; work_dir and provider are added by
+// core.RegisterAgentOptions.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "session", Type: core.OptionString, Required: true},
+		{Name: "pane", Type: core.OptionString},
+		{Name: "window_per_session", Type: core.OptionBool},
+		{Name: "auto_create", Type: core.OptionBool},
+		{Name: "init_command", Type: core.OptionString},
+		{Name: "shell", Type: core.OptionString},
+		{Name: "startup_wait_ms", Type: core.OptionInt},
+		{Name: "poll_interval_ms", Type: core.OptionInt},
+		{Name: "prompt_pattern", Type: core.OptionString},
+		{Name: "strip_patterns", Type: core.OptionStringList},
+		{Name: "strip_input_block", Type: core.OptionBool},
+	},
 }
 
 // Agent drives a persistent tmux pane as an interactive shell agent.
@@ -143,6 +162,10 @@ func New(opts map[string]any) (core.Agent, error) {
 
 func (a *Agent) Name() string { return "tmux" }
 
+// SupportsImages implements core.ImageSupportReporter: keystrokes sent to a
+// pane cannot carry images, so they are dropped.
+func (a *Agent) SupportsImages() bool { return false }
+
 func (a *Agent) StartSession(ctx context.Context, sessionID string) (core.AgentSession, error) {
 	a.mu.RLock()
 	sessionName := a.sessionName
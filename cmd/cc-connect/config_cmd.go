@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	ccconnect "github.com/chenhg5/cc-connect"
 	"github.com/chenhg5/cc-connect/config"
+	"github.com/chenhg5/cc-connect/core"
 )
 
-func runConfig(args []string) {
+func runConfig(ctx context.Context, args []string) {
 	if len(args) == 0 {
 		printConfigUsage()
 		os.Exit(1)
@@ -18,9 +25,19 @@ func runConfig(args []string) {
 	case "example":
 		fmt.Print(ccconnect.ConfigExampleTOML)
 	case "format", "fmt":
-		runConfigFormat(args[1:])
+		runConfigFormat(ctx, args[1:])
 	case "path":
 		fmt.Println(resolveConfigPath(""))
+	case "validate", "check":
+		runConfigValidate(args[1:])
+	case "schema":
+		runConfigSchema()
+	case "history":
+		runConfigHistory(args[1:])
+	case "diff":
+		runConfigDiff(args[1:])
+	case "rollback":
+		runConfigRollback(ctx, args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown config subcommand: %s\n", args[0])
 		printConfigUsage()
@@ -28,7 +45,7 @@ func runConfig(args []string) {
 	}
 }
 
-func runConfigFormat(args []string) {
+func runConfigFormat(ctx context.Context, args []string) {
 	fs := flag.NewFlagSet("config format", flag.ExitOnError)
 	configPath := fs.String("config", "", "path to config file (default: auto-detect)")
 	_ = fs.Parse(args)
@@ -39,13 +56,262 @@ func runConfigFormat(args []string) {
 		os.Exit(1)
 	}
 
-	if err := config.FormatConfigFile(path); err != nil {
+	if err := config.FormatConfigFile(ctx, path); err != nil {
 		fmt.Fprintf(os.Stderr, "Error formatting config: %v\n", err)
 		os.Exit(1)
 	}
 	fmt.Printf("Formatted %s\n", path)
 }
 
+func runConfigValidate(args []string) {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to config file (default: auto-detect)")
+	asJSON := fs.Bool("json", false, "print diagnostics as JSON")
+	_ = fs.Parse(args)
+
+	path := resolveConfigPath(*configPath)
+	diags, err := config.Validate(path, checkConfigOptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	errCount, warnCount := 0, 0
+	for _, d := range diags {
+		if d.Severity == "error" {
+			errCount++
+		} else {
+			warnCount++
+		}
+	}
+
+	if *asJSON {
+		if diags == nil {
+			diags = []config.Diagnostic{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]any{
+			"file":        path,
+			"valid":       errCount == 0,
+			"errors":      errCount,
+			"warnings":    warnCount,
+			"diagnostics": diags,
+		})
+	} else {
+		for _, d := range diags {
+			fmt.Println(d.String())
+		}
+		if errCount == 0 {
+			fmt.Printf("%s: OK (%d warning(s))\n", path, warnCount)
+		} else {
+			fmt.Printf("%s: %d error(s), %d warning(s)\n", path, errCount, warnCount)
+		}
+	}
+	if errCount > 0 {
+		os.Exit(1)
+	}
+}
+
+// checkConfigOptions checks an options table against the schema registered
+// with the platform or agent factory.
+func checkConfigOptions(kind, typeName string, opts map[string]any) ([]config.OptionIssue, bool) {
+	var schema core.OptionSchema
+	var hasSchema bool
+	switch kind {
+	case "platform":
+		if !slices.Contains(core.ListRegisteredPlatforms(), typeName) {
+			return nil, false
+		}
+		schema, hasSchema = core.PlatformOptionSchema(typeName)
+	case "agent":
+		if !slices.Contains(core.ListRegisteredAgents(), typeName) {
+			return nil, false
+		}
+		schema, hasSchema = core.AgentOptionSchema(typeName)
+	}
+	if !hasSchema {
+		return nil, true
+	}
+	var issues []config.OptionIssue
+	for _, issue := range schema.Check(opts) {
+		issues = append(issues, config.OptionIssue{Key: issue.Key, Message: issue.Message, Warning: issue.Warning})
+	}
+	return issues, true
+}
+
+func runConfigSchema() {
+	platforms := map[string]map[string]any{}
+	for _, name := range core.ListRegisteredPlatforms() {
+		if s, ok := core.PlatformOptionSchema(name); ok {
+			platforms[name] = s.JSONSchema()
+		} else {
+			platforms[name] = map[string]any{"type": "object"}
+		}
+	}
+	agents := map[string]map[string]any{}
+	for _, name := range core.ListRegisteredAgents() {
+		if s, ok := core.AgentOptionSchema(name); ok {
+			agents[name] = s.JSONSchema()
+		} else {
+			agents[name] = map[string]any{"type": "object"}
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(config.JSONSchema(platforms, agents))
+}
+
+func runConfigHistory(args []string) {
+	fs := flag.NewFlagSet("config history", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to config file (default: auto-detect)")
+	asJSON := fs.Bool("json", false, "print versions as JSON")
+	_ = fs.Parse(args)
+	initConfigPath(*configFile)
+
+	versions, err := config.ConfigHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *asJSON {
+		if versions == nil {
+			versions = []config.ConfigVersion{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(versions)
+		return
+	}
+	if len(versions) == 0 {
+		fmt.Println("No config changes recorded yet.")
+		return
+	}
+	fmt.Printf("%-5s  %-19s  %-24s  %s\n", "ID", "TIME", "ACTOR", "REASON")
+	for _, v := range versions {
+		reason := v.Reason
+		if filepath.Clean(v.File) != filepath.Clean(absPath(config.ConfigPath)) {
+			reason += " [" + filepath.Base(v.File) + "]"
+		}
+		fmt.Printf("#%-4d  %-19s  %-24s  %s\n", v.ID, v.Time.Format("2006-01-02 15:04:05"), v.Actor, strings.TrimSpace(reason))
+	}
+}
+
+func runConfigDiff(args []string) {
+	fs := flag.NewFlagSet("config diff", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to config file (default: auto-detect)")
+	_ = fs.Parse(args)
+	initConfigPath(*configFile)
+
+	ids, ok := parseVersionArgs(fs.Args())
+	if !ok || len(ids) > 2 {
+		fmt.Fprintln(os.Stderr, "Usage: cc-connect config diff <n> [<m>]")
+		os.Exit(1)
+	}
+	var diff string
+	var err error
+	if len(ids) == 1 {
+		diff, err = config.DiffConfigVersion(ids[0])
+	} else {
+		diff, err = config.DiffConfigVersions(ids[0], ids[1])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if diff == "" {
+		fmt.Println("No differences.")
+		return
+	}
+	fmt.Println(diff)
+}
+
+func runConfigRollback(ctx context.Context, args []string) {
+	fs := flag.NewFlagSet("config rollback", flag.ExitOnError)
+	configFile := fs.String("config", "", "path to config file (default: auto-detect)")
+	_ = fs.Parse(args)
+	initConfigPath(*configFile)
+
+	ids, ok := parseVersionArgs(fs.Args())
+	if !ok || len(ids) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: cc-connect config rollback <n>")
+		os.Exit(1)
+	}
+	v, err := config.RollbackConfig(ctx, ids[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Restored %s to version #%d.\n", v.File, v.ID)
+	fmt.Println("A running cc-connect picks this up on its next reload (/config reload, or automatically with auto_reload).")
+}
+
+// parseVersionArgs parses config version IDs such as "3" or "#3".
+func parseVersionArgs(args []string) ([]int, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	ids := make([]int, len(args))
+	for i, a := range args {
+		n, err := strconv.Atoi(strings.TrimPrefix(a, "#"))
+		if err != nil || n <= 0 {
+			return nil, false
+		}
+		ids[i] = n
+	}
+	return ids, true
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// configHistoryForCore adapts the config history for the engine and the
+// management API.
+func configHistoryForCore() ([]core.ConfigVersionInfo, error) {
+	versions, err := config.ConfigHistory()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]core.ConfigVersionInfo, len(versions))
+	for i, v := range versions {
+		out[i] = core.ConfigVersionInfo{ID: v.ID, Time: v.Time, Actor: v.Actor, Reason: v.Reason, File: v.File}
+	}
+	return out, nil
+}
+
+// configDiffForCore shows what change from did when to is 0, else compares
+// two versions.
+func configDiffForCore(from, to int) (string, error) {
+	if to == 0 {
+		return config.DiffConfigVersion(from)
+	}
+	return config.DiffConfigVersions(from, to)
+}
+
+// redactedConfigDiffForCore is configDiffForCore with secret values masked,
+// for showing in chats.
+func redactedConfigDiffForCore(from, to int) (string, error) {
+	diff, err := configDiffForCore(from, to)
+	return config.RedactConfigDiff(diff), err
+}
+
+func rollbackConfigForCore(ctx context.Context, id int) error {
+	_, err := config.RollbackConfig(configChangeCtx(ctx), id)
+	return err
+}
+
+// configChangeCtx carries the change the engine or the management API
+// attached to ctx over to the config history.
+func configChangeCtx(ctx context.Context) context.Context {
+	if c, ok := core.ConfigChangeFrom(ctx); ok {
+		return config.WithChangeSource(ctx, config.ChangeSource{Actor: c.Actor, Reason: c.Reason})
+	}
+	return ctx
+}
+
 func printConfigUsage() {
 	fmt.Fprintf(os.Stderr, `Usage: cc-connect config <subcommand>
 
@@ -53,14 +319,28 @@ Subcommands:
   example    Print a complete annotated config.toml example
   format     Format the config file (alias: fmt)
   path       Print the resolved config file path
+  validate   Check the config for unknown keys, wrong types and bad options (alias: check)
+  schema     Print a JSON Schema for config.toml
+  history    List recorded config changes (newest first)
+  diff       Show what change <n> did, or compare versions <n> and <m>
+  rollback   Restore the config to how it was before change <n>
 
-Flags for 'format':
+Flags for 'format', 'validate', 'history', 'diff' and 'rollback':
   --config <path>   Path to config file (default: auto-detect)
 
+Flags for 'validate' and 'history':
+  --json            Print JSON
+
 Examples:
   cc-connect config example              Print example config
   cc-connect config example > config.toml  Save example config
   cc-connect config format               Format default config file
   cc-connect config fmt --config /path/to/config.toml
+  cc-connect config validate             Check the default config file
+  cc-connect config validate --json      Machine-readable diagnostics
+  cc-connect config schema > cc-connect.schema.json
+  cc-connect config history              List recent config changes
+  cc-connect config diff 12              Show what change #12 did
+  cc-connect config rollback 12          Undo change #12 and everything after it
 `)
 }
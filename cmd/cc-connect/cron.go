@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -58,7 +59,10 @@ func closeCronResponseBody(body io.Closer) {
 
 func runCronAdd(args []string) {
 	var project, sessionKey, cronExpr, prompt, execCmd, desc, dataDir, sessionMode string
+	var retryDelay, onFailure, then, precheck string
+	var timezone, excludeDates, excludeICS, jitter string
 	var timeoutMins *int
+	var retries int
 	var silent bool
 
 	var positional []string
@@ -114,6 +118,56 @@ func runCronAdd(args []string) {
 				}
 				timeoutMins = &n
 			}
+		case "--retries":
+			if i+1 < len(args) {
+				i++
+				n, err := strconv.Atoi(args[i])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: invalid --retries: %v\n", err)
+					os.Exit(1)
+				}
+				retries = n
+			}
+		case "--retry-delay":
+			if i+1 < len(args) {
+				i++
+				retryDelay = args[i]
+			}
+		case "--on-failure":
+			if i+1 < len(args) {
+				i++
+				onFailure = args[i]
+			}
+		case "--then":
+			if i+1 < len(args) {
+				i++
+				then = args[i]
+			}
+		case "--precheck":
+			if i+1 < len(args) {
+				i++
+				precheck = args[i]
+			}
+		case "--timezone", "--tz":
+			if i+1 < len(args) {
+				i++
+				timezone = args[i]
+			}
+		case "--exclude-dates":
+			if i+1 < len(args) {
+				i++
+				excludeDates = args[i]
+			}
+		case "--exclude-ics":
+			if i+1 < len(args) {
+				i++
+				excludeICS = args[i]
+			}
+		case "--jitter":
+			if i+1 < len(args) {
+				i++
+				jitter = args[i]
+			}
 		case "--silent":
 			silent = true
 		case "--help", "-h":
@@ -175,6 +229,42 @@ func runCronAdd(args []string) {
 	if timeoutMins != nil {
 		body["timeout_mins"] = *timeoutMins
 	}
+	if retries != 0 {
+		body["retries"] = retries
+	}
+	if retryDelay != "" {
+		body["retry_delay"] = retryDelay
+	}
+	if onFailure != "" {
+		body["on_failure"] = onFailure
+	}
+	if then != "" {
+		body["then"] = then
+	}
+	if precheck != "" {
+		body["precheck"] = precheck
+	}
+	if timezone != "" {
+		body["timezone"] = timezone
+	}
+	if excludeDates != "" {
+		var dates []string
+		for _, d := range strings.Split(excludeDates, ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				dates = append(dates, d)
+			}
+		}
+		body["exclude_dates"] = dates
+	}
+	if excludeICS != "" {
+		if abs, err := filepath.Abs(excludeICS); err == nil {
+			excludeICS = abs
+		}
+		body["exclude_ics"] = excludeICS
+	}
+	if jitter != "" {
+		body["jitter"] = jitter
+	}
 	payload, _ := json.Marshal(body)
 
 	resp, err := apiPost(sockPath, "/cron/add", payload)
@@ -575,15 +665,17 @@ func parseCronEditValue(field, valueStr string) (any, error) {
 			return nil, fmt.Errorf("%s must be true or false", field)
 		}
 		return v, nil
-	case "timeout_mins":
+	case "timeout_mins", "retries":
 		v, err := strconv.Atoi(valueStr)
 		if err != nil {
-			return nil, fmt.Errorf("timeout_mins must be an integer")
+			return nil, fmt.Errorf("%s must be an integer", field)
 		}
 		return v, nil
 	default:
 		// String fields: project, session_key, cron_expr, prompt, exec,
-		// work_dir, description, session_mode, mode
+		// work_dir, description, session_mode, mode, retry_delay,
+		// on_failure, then, timezone, jitter, exclude_ics, exclude_dates
+		// (comma-separated)
 		return valueStr, nil
 	}
 }
@@ -607,8 +699,9 @@ Commands:
   list      List all scheduled tasks
   exec <id> Trigger a scheduled task immediately
   edit      Edit a scheduled task field
-  info <id> [field]  Show detailed info of a scheduled task
-                     (optionally filter to a single field)
+  info <id> [field]  Show detailed info of a scheduled task, including
+                     recent run history (optionally filter to a single
+                     field, e.g. "runs")
   del <id>  Delete a scheduled task
 
 Run 'cc-connect cron <command> --help' for details.`)
@@ -628,6 +721,18 @@ Options:
       --desc <text>          Short description
       --session-mode <mode>  reuse (default) or new-per-run — fresh agent session each run
       --timeout-mins <n>     Max minutes to wait per run (0 = no limit; default 30 if omitted)
+      --retries <n>          Extra attempts after a failed run (0-10, default 0)
+      --retry-delay <dur>    Wait between attempts, e.g. 30s or 5m (default 1m)
+      --on-failure <target>  Alert target after the last failed attempt: a session key,
+                             or "session" for the job's own session
+      --then <id>            Run job <id> after this job succeeds (chaining)
+      --precheck <command>   Shell command run first; the job is skipped unless it
+                             exits 0. Its stdout is available as {{precheck_output}}
+      --timezone <zone>      IANA time zone for the schedule, e.g. Asia/Shanghai
+                             (alias --tz; default: server local time)
+      --exclude-dates <list> Comma-separated YYYY-MM-DD dates to skip (in the job's zone)
+      --exclude-ics <path>   iCalendar file whose event days are skipped (re-read each run)
+      --jitter <dur>         Delay each scheduled run by a random amount up to <dur>, e.g. 10m
       --silent               Suppress cron start notification
       --data-dir <path>      Data directory (default: ~/.cc-connect)
   -h, --help                 Show this help
@@ -636,6 +741,11 @@ Examples:
   cc-connect cron add --cron "0 6 * * *" --prompt "Collect GitHub trending data" --desc "Daily Trending"
   cc-connect cron add --cron "*/30 * * * *" --exec "df -h" --desc "Disk usage check"
   cc-connect cron add --cron "0 9 * * *" --prompt "Daily standup reminder" --silent
+  cc-connect cron add --cron "0 2 * * *" --exec "git pull" --retries 3 --retry-delay 5m --then <test-job-id>
+  cc-connect cron add --cron "0 18 * * *" --precheck 'git log --since=1.day --oneline | grep .' \
+    --prompt "Review these new commits: {{precheck_output}}"
+  cc-connect cron add --cron "30 9 * * 1-5" --timezone Europe/Berlin --exclude-ics ~/holidays-de.ics \
+    --jitter 5m --prompt "Summarize overnight alerts"
   cc-connect cron add 0 6 * * * Collect GitHub trending data and send me a summary`)
 }
 
@@ -665,8 +775,16 @@ Editable Fields (string):
   prompt        Task prompt (runs through agent)
   exec          Shell command (runs directly)
   work_dir      Working directory for exec
+  precheck      Shell command that must exit 0 for the job to run
   description   Short description
   session_mode  reuse or new_per_run
+  retry_delay   Wait between attempts, e.g. "5m"
+  on_failure    Session key to alert after the last failed attempt ("session" = own session)
+  then          Job ID to run after this job succeeds ("" to unlink)
+  timezone      IANA time zone for the schedule ("" = server local time)
+  exclude_dates Comma-separated YYYY-MM-DD dates to skip
+  exclude_ics   Path to an iCalendar file whose event days are skipped
+  jitter        Random delay window before scheduled runs, e.g. "10m"
 
 Editable Fields (bool: true/false):
   enabled       Enable or disable the task
@@ -675,6 +793,7 @@ Editable Fields (bool: true/false):
 
 Editable Fields (int: number):
   timeout_mins  Max minutes per run (0 = no limit)
+  retries       Extra attempts after a failed run (0-10)
 
 Read-only Fields (cannot be edited):
   id, created_at, last_run, last_error
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -83,7 +84,7 @@ type registrationFlowResult struct {
 	Platform    string // feishu or lark
 }
 
-func runFeishu(args []string) {
+func runFeishu(ctx context.Context, args []string) {
 	if len(args) == 0 {
 		printFeishuUsage()
 		return
@@ -91,11 +92,11 @@ func runFeishu(args []string) {
 
 	switch args[0] {
 	case "setup":
-		runFeishuSetup(args[1:], feishuSetupModeAuto)
+		runFeishuSetup(ctx, args[1:], feishuSetupModeAuto)
 	case "new", "create":
-		runFeishuSetup(args[1:], feishuSetupModeNew)
+		runFeishuSetup(ctx, args[1:], feishuSetupModeNew)
 	case "bind", "link":
-		runFeishuSetup(args[1:], feishuSetupModeBind)
+		runFeishuSetup(ctx, args[1:], feishuSetupModeBind)
 	case "help", "--help", "-h":
 		printFeishuUsage()
 	default:
@@ -105,7 +106,7 @@ func runFeishu(args []string) {
 	}
 }
 
-func runFeishuSetup(args []string, requestedMode string) {
+func runFeishuSetup(ctx context.Context, args []string, requestedMode string) {
 	fs := flag.NewFlagSet("feishu "+requestedMode, flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	project := fs.String("project", "", "project name (optional if only one project)")
@@ -188,7 +189,7 @@ func runFeishuSetup(args []string, requestedMode string) {
 		provisionType = "feishu"
 	}
 	workDir, _ := os.Getwd()
-	provisionResult, err := config.EnsureProjectWithFeishuPlatform(config.EnsureProjectWithFeishuOptions{
+	provisionResult, err := config.EnsureProjectWithFeishuPlatform(ctx, config.EnsureProjectWithFeishuOptions{
 		ProjectName:  targetProject,
 		PlatformType: provisionType,
 		WorkDir:      workDir,
@@ -203,7 +204,7 @@ func runFeishuSetup(args []string, requestedMode string) {
 		fmt.Printf("Project %q had no Feishu/Lark platform, added one automatically.\n", targetProject)
 	}
 
-	saveResult, err := config.SaveFeishuPlatformCredentials(config.FeishuCredentialUpdateOptions{
+	saveResult, err := config.SaveFeishuPlatformCredentials(ctx, config.FeishuCredentialUpdateOptions{
 		ProjectName:       targetProject,
 		PlatformIndex:     *platformIndex,
 		PlatformType:      finalPlatformType,
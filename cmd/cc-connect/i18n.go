@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chenhg5/cc-connect/config"
+	"github.com/chenhg5/cc-connect/core"
+)
+
+func runI18n(args []string) {
+	if len(args) == 0 {
+		printI18nUsage()
+		return
+	}
+
+	sub := args[0]
+	var dataDir, configPath, lang string
+	verbose := false
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--data-dir":
+			if i+1 < len(rest) {
+				i++
+				dataDir = rest[i]
+			}
+		case "--config":
+			if i+1 < len(rest) {
+				i++
+				configPath = rest[i]
+			}
+		case "--lang":
+			if i+1 < len(rest) {
+				i++
+				lang = rest[i]
+			}
+		case "-v", "--verbose":
+			verbose = true
+		case "--help", "-h":
+			printI18nUsage()
+			return
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown option: %s\n", rest[i])
+			printI18nUsage()
+			os.Exit(1)
+		}
+	}
+
+	switch sub {
+	case "check":
+		if dataDir == "" {
+			dataDir = config.DataDirFromConfig(resolveConfigPath(configPath))
+		}
+		if !runI18nCheck(core.I18nOverrideDir(dataDir), lang, verbose) {
+			os.Exit(1)
+		}
+	case "help":
+		printI18nUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown i18n subcommand: %s\n", sub)
+		printI18nUsage()
+		os.Exit(1)
+	}
+}
+
+// runI18nCheck prints a per-language report of the message catalogs and
+// reports whether they are usable: every override file parsed and no
+// message has unknown keys or mismatched format arguments. Missing keys
+// only fall back and are not an error.
+func runI18nCheck(overrideDir, lang string, verbose bool) bool {
+	ok := true
+	if err := core.LoadI18nOverrides(overrideDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		ok = false
+	}
+	fmt.Printf("Overrides: %s\n\n", overrideDir)
+
+	found := false
+	for _, r := range core.CheckI18nCatalogs() {
+		if lang != "" && !strings.EqualFold(string(r.Lang), lang) {
+			continue
+		}
+		found = true
+		chain := make([]string, 0, len(r.Fallback))
+		for _, l := range r.Fallback {
+			chain = append(chain, string(l))
+		}
+		fallback := ""
+		if len(chain) > 0 {
+			fallback = ", falls back to " + strings.Join(chain, " → ")
+		}
+		fmt.Printf("%-6s %-10s %d keys, %d missing%s\n", r.Lang, r.Name, r.Keys, len(r.Missing), fallback)
+		printI18nKeys("unknown key", r.Unknown)
+		printI18nKeys("format arguments differ from en", r.BadFormat)
+		if verbose {
+			printI18nKeys("missing", r.Missing)
+		}
+		if len(r.Unknown) > 0 || len(r.BadFormat) > 0 {
+			ok = false
+		}
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "Error: no catalog for language %q\n", lang)
+		return false
+	}
+	return ok
+}
+
+func printI18nKeys(label string, keys []core.MsgKey) {
+	for _, k := range keys {
+		fmt.Printf("         %s: %s\n", label, k)
+	}
+}
+
+func printI18nUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: cc-connect i18n <subcommand> [options]
+
+Inspect the UI message catalogs. Built-in catalogs can be overridden or
+extended with <data_dir>/i18n/<lang>.json files.
+
+Subcommands:
+  check                Report missing, unknown and malformed keys per language
+
+Options:
+  --config <path>      Config file whose data_dir holds the overrides (default: auto-detect)
+  --data-dir <path>    Data directory holding the i18n/ override folder
+  --lang <code>        Only report this language
+  -v, --verbose        Also list the missing keys
+
+Examples:
+  cc-connect i18n check
+  cc-connect i18n check --lang de -v
+`)
+}
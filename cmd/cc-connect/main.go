@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -206,14 +207,14 @@ var topLevelCommandHandlers = map[string]func([]string){
 	"config-example": func(_ []string) {
 		fmt.Print(ccconnect.ConfigExampleTOML)
 	},
-	"config": runConfig,
 	"update": func(_ []string) {
 		runUpdate()
 	},
 	"check-update": func(_ []string) {
 		checkUpdate()
 	},
-	"provider":  runProviderCommand,
+	"secrets":   runSecrets,
+	"i18n":      runI18n,
 	"send":      runSend,
 	"cron":      runCron,
 	"timer":     runTimer,
@@ -222,12 +223,8 @@ var topLevelCommandHandlers = map[string]func([]string){
 	"sessions":  runSessions,
 	"agent-sid": runAgentSID,
 	"daemon":    runDaemon,
-	"feishu":    runFeishu,
 	"tuitui":    runTuiTui,
-	"weixin":    runWeixin,
-	"yuanbao":   runYuanbao,
 	"doctor":    runDoctor,
-	"web":       runWeb,
 }
 
 func main() {
@@ -335,6 +332,11 @@ func main() {
 	}
 
 	config.ConfigPath = configPath
+	core.SetRedactedSecrets(cfg.SecretValues())
+	core.SetAttachmentConversion(attachmentConvertCfg(cfg.AttachmentConvert))
+	if err := core.LoadI18nOverrides(core.I18nOverrideDir(cfg.DataDir)); err != nil {
+		slog.Warn("i18n: some message overrides were skipped", "error", err)
+	}
 	slog.Info("config loaded", "path", configPath)
 
 	if len(cfg.Projects) == 0 {
@@ -355,614 +357,17 @@ func main() {
 		os.Exit(1)
 	}
 
-	engines := make([]*core.Engine, 0, len(cfg.Projects))
-	effectiveWorkDirs := make([]string, 0, len(cfg.Projects))
-
+	rt := newAppRuntime(configPath, rootOpts, cfg)
 	for _, proj := range cfg.Projects {
-		// Inject project-level run_as_user / run_as_env into the agent's
-		// opts map so agents that support isolation can pick them up
-		// without needing their own top-level config plumbing.
-		if proj.RunAsUser != "" {
-			if proj.Agent.Options == nil {
-				proj.Agent.Options = map[string]any{}
-			}
-			proj.Agent.Options["run_as_user"] = proj.RunAsUser
-			if len(proj.RunAsEnv) > 0 {
-				proj.Agent.Options["run_as_env"] = proj.RunAsEnv
-			}
+		pr, err := rt.buildProject(cfg, proj)
+		if errors.Is(err, errSkipProject) {
+			continue
 		}
-		agent, err := core.CreateAgent(proj.Agent.Type, buildAgentOptions(cfg.DataDir, proj))
 		if err != nil {
-			slog.Error("failed to create agent", "project", proj.Name, "error", err)
+			slog.Error("failed to build project", "project", proj.Name, "error", err)
 			os.Exit(1)
 		}
-
-		providerWiring := wireAgentProviders(agent, proj.Agent)
-
-		var platforms []core.Platform
-		for _, pc := range proj.Platforms {
-			opts := make(map[string]any, len(pc.Options)+2)
-			for k, v := range pc.Options {
-				opts[k] = v
-			}
-			opts["cc_data_dir"] = cfg.DataDir
-			opts["cc_project"] = proj.Name
-			p, err := core.CreatePlatform(pc.Type, opts)
-			if err != nil {
-				slog.Error("failed to create platform", "project", proj.Name, "type", pc.Type, "error", err)
-				os.Exit(1)
-			}
-			platforms = append(platforms, p)
-		}
-
-		workDir, _ := proj.Agent.Options["work_dir"].(string)
-		projectState := core.NewProjectStateStore(projectStatePath(cfg.DataDir, proj.Name))
-		effectiveWorkDir := applyProjectStateOverride(proj.Name, agent, workDir, projectState)
-		startInitialRefreshIfReady(agent, providerWiring)
-		sessionFile := sessionStorePath(cfg.DataDir, proj.Name, effectiveWorkDir)
-
-		// Parse language setting
-		var lang core.Language
-		switch cfg.Language {
-		case "zh", "chinese":
-			lang = core.LangChinese
-		case "zh-TW", "zh_TW", "zhtw":
-			lang = core.LangTraditionalChinese
-		case "ja", "japanese":
-			lang = core.LangJapanese
-		case "es", "spanish":
-			lang = core.LangSpanish
-		case "en", "english":
-			lang = core.LangEnglish
-		default:
-			lang = core.LangAuto // auto-detect
-		}
-
-		engine := core.NewEngine(proj.Name, agent, platforms, sessionFile, lang)
-		// Wire display settings including show_context_indicator and reply_footer
-		// Global [display] config can be overridden by project-level settings
-		_, _, _, _, _, showCtx, showFooter, _ := config.EffectiveDisplay(cfg, &proj)
-		engine.SetShowContextIndicator(showCtx)
-		showWorkdir := true
-		if proj.ShowWorkdirIndicator != nil {
-			showWorkdir = *proj.ShowWorkdirIndicator
-		}
-		engine.SetShowWorkdirIndicator(showWorkdir)
-		engine.SetReplyFooterEnabled(showFooter)
-		engine.SetAttachmentSendEnabled(cfg.AttachmentSend != "off")
-		engine.SetFilterExternalSessions(proj.FilterExternalSessions != nil && *proj.FilterExternalSessions)
-		engine.SetBaseWorkDir(workDir)
-		engine.SetProjectStateStore(projectState)
-		engine.SetDataDir(cfg.DataDir)
-
-		// Wire multi-workspace mode
-		if proj.Mode == "multi-workspace" {
-			baseDir := proj.BaseDir
-			if strings.HasPrefix(baseDir, "~/") {
-				home, _ := os.UserHomeDir()
-				baseDir = filepath.Join(home, baseDir[2:])
-			}
-			if err := os.MkdirAll(baseDir, 0o755); err != nil {
-				slog.Error("failed to create base_dir", "path", baseDir, "err", err)
-				continue
-			}
-			bindingStore := filepath.Join(cfg.DataDir, "workspace_bindings.json")
-			engine.SetMultiWorkspace(baseDir, bindingStore)
-			if proj.WorkspaceInitAllowLocalPaths != nil {
-				engine.SetWorkspaceInitAllowLocalPaths(*proj.WorkspaceInitAllowLocalPaths)
-			}
-			idleMins := cfg.WorkspaceIdleTimeoutMins
-			if idleMins == nil && proj.WorkspaceIdleTimeoutMinsLegacy != nil {
-				slog.Warn("workspace_idle_timeout_mins under [[projects]] is deprecated; move it to the top level of config.toml. Honoring the legacy value for backwards compatibility.",
-					"project", proj.Name, "value", *proj.WorkspaceIdleTimeoutMinsLegacy)
-				idleMins = proj.WorkspaceIdleTimeoutMinsLegacy
-			}
-			if idleMins != nil {
-				mins := *idleMins
-				if mins <= 0 {
-					engine.SetWorkspaceIdleTimeout(0)
-				} else {
-					engine.SetWorkspaceIdleTimeout(time.Duration(mins) * time.Minute)
-				}
-			}
-			if proj.SkipGit != nil {
-				engine.SetSkipGit(*proj.SkipGit)
-			}
-			slog.Info("multi-workspace mode enabled", "project", proj.Name, "base_dir", baseDir)
-		}
-
-		// Wire terminal observation (--observe / [projects.observe])
-		observeEnabled := rootOpts.observe
-		obsChan := rootOpts.observeChannel
-		if proj.Observe != nil {
-			if !observeEnabled && proj.Observe.Enabled {
-				observeEnabled = true
-			}
-			if obsChan == "" && proj.Observe.Channel != "" {
-				obsChan = proj.Observe.Channel
-			}
-		}
-		if observeEnabled {
-			if obsChan == "" {
-				slog.Error("observe: channel is required (use --observe-channel or set channel in [projects.observe])")
-				os.Exit(1)
-			}
-			hasSlack := false
-			for _, p := range platforms {
-				if p.Name() == "slack" {
-					hasSlack = true
-					break
-				}
-			}
-			if !hasSlack {
-				slog.Warn("observe requires a Slack platform; ignoring")
-			} else {
-				projectDir := resolveClaudeProjectDir(workDir)
-				if projectDir == "" {
-					slog.Warn("observe: could not find Claude Code project directory", "workDir", workDir)
-				} else {
-					sessionKey := fmt.Sprintf("slack:%s", obsChan)
-					engine.SetObserveConfig(projectDir, sessionKey)
-				}
-			}
-		}
-
-		// Wire global custom commands
-		for _, c := range cfg.Commands {
-			engine.AddCommand(c.Name, c.Description, c.Prompt, c.Exec, c.WorkDir, "config")
-		}
-
-		// Wire command persistence callbacks
-		engine.SetCommandSaveAddFunc(func(name, description, prompt, exec, workDir string) error {
-			return config.AddCommand(config.CommandConfig{Name: name, Description: description, Prompt: prompt, Exec: exec, WorkDir: workDir})
-		})
-		engine.SetCommandSaveDelFunc(func(name string) error {
-			return config.RemoveCommand(name)
-		})
-
-		// Wire global aliases
-		for _, a := range cfg.Aliases {
-			engine.AddAlias(a.Name, a.Command)
-		}
-		engine.SetAliasSaveAddFunc(func(name, command string) error {
-			return config.AddAlias(config.AliasConfig{Name: name, Command: command})
-		})
-		engine.SetAliasSaveDelFunc(func(name string) error {
-			return config.RemoveAlias(name)
-		})
-
-		// Wire banned words
-		if len(cfg.BannedWords) > 0 {
-			engine.SetBannedWords(cfg.BannedWords)
-		}
-
-		// Wire disabled commands (project-level)
-		if len(proj.DisabledCommands) > 0 {
-			engine.SetDisabledCommands(proj.DisabledCommands)
-		}
-
-		// Wire admin allowlist for privileged commands
-		engine.SetAdminFrom(proj.AdminFrom)
-
-		// Wire per-user role-based policies
-		if proj.Users != nil {
-			engine.SetUserRoles(buildUserRoleManager(proj.Users))
-		}
-
-		// Wire display truncation settings (includes legacy quiet → display mapping)
-		{
-			mode, tm, tool, tmlen, toollen, _, _, hideAgentFooter := config.EffectiveDisplay(cfg, &proj)
-			historyMaxLen := config.EffectiveHistoryMaxLen(cfg, &proj)
-			engine.SetDisplayConfig(core.DisplayCfg{
-				Mode:             mode,
-				CardMode:         config.EffectiveCardMode(cfg, &proj),
-				ThinkingMessages: tm,
-				ThinkingMaxLen:   tmlen,
-				ToolMaxLen:       toollen,
-				ToolMessages:     tool,
-				HistoryMaxLen:    &historyMaxLen,
-				HideAgentFooter:  hideAgentFooter,
-			})
-		}
-
-		// Wire shell configuration
-		shell, shellFlag, shellProfile := config.EffectiveShell(cfg, &proj)
-		engine.SetShell(shell, shellFlag, shellProfile)
-
-		// Wire hooks
-		if len(cfg.Hooks) > 0 {
-			coreHooks := make([]core.HookConfig, len(cfg.Hooks))
-			for i, h := range cfg.Hooks {
-				coreHooks[i] = core.HookConfig{
-					Event:   h.Event,
-					Type:    h.Type,
-					Command: h.Command,
-					URL:     h.URL,
-					Timeout: h.Timeout,
-					Async:   h.Async,
-				}
-			}
-			engine.SetHooks(core.NewHookManager(proj.Name, coreHooks, shell, shellFlag, shellProfile))
-		}
-
-		// Wire local reference normalization / rendering
-		engine.SetReferenceConfig(core.ReferenceRenderCfg{
-			NormalizeAgents: proj.References.NormalizeAgents,
-			RenderPlatforms: proj.References.RenderPlatforms,
-			DisplayPath:     proj.References.DisplayPath,
-			MarkerStyle:     proj.References.MarkerStyle,
-			EnclosureStyle:  proj.References.EnclosureStyle,
-		})
-
-		// Wire streaming preview
-		{
-			spcfg := core.DefaultStreamPreviewCfg()
-			if cfg.StreamPreview.Enabled != nil {
-				spcfg.Enabled = *cfg.StreamPreview.Enabled
-			}
-			if cfg.StreamPreview.IntervalMs != nil {
-				spcfg.IntervalMs = *cfg.StreamPreview.IntervalMs
-			}
-			if cfg.StreamPreview.MinDeltaChars != nil {
-				spcfg.MinDeltaChars = *cfg.StreamPreview.MinDeltaChars
-			}
-			if cfg.StreamPreview.MaxChars != nil {
-				spcfg.MaxChars = *cfg.StreamPreview.MaxChars
-			}
-			if cfg.StreamPreview.DisabledPlatforms != nil {
-				spcfg.DisabledPlatforms = cfg.StreamPreview.DisabledPlatforms
-			}
-			engine.SetStreamPreviewCfg(spcfg)
-		}
-
-		// Wire instant reply
-		if cfg.InstantReply.Enabled != nil && *cfg.InstantReply.Enabled {
-			engine.SetInstantReply(core.InstantReplyCfg{
-				Enabled: true,
-				Content: cfg.InstantReply.Content,
-			})
-		}
-
-		// Wire rate limiting
-		{
-			maxMsg := 20
-			windowSecs := 60
-			if cfg.RateLimit.MaxMessages != nil {
-				maxMsg = *cfg.RateLimit.MaxMessages
-			}
-			if cfg.RateLimit.WindowSecs != nil {
-				windowSecs = *cfg.RateLimit.WindowSecs
-			}
-			if maxMsg > 0 {
-				engine.SetRateLimitCfg(core.RateLimitCfg{
-					MaxMessages: maxMsg,
-					Window:      time.Duration(windowSecs) * time.Second,
-				})
-			}
-		}
-		// Wire outgoing rate limiting
-		{
-			var maxPS float64
-			if cfg.OutgoingRateLimit.MaxPerSecond != nil {
-				maxPS = *cfg.OutgoingRateLimit.MaxPerSecond
-			}
-			var burst int
-			if cfg.OutgoingRateLimit.Burst != nil {
-				burst = *cfg.OutgoingRateLimit.Burst
-			}
-			defaults := core.OutgoingRateLimitCfg{MaxPerSecond: maxPS, Burst: burst}
-			overrides := make(map[string]core.OutgoingRateLimitCfg)
-			for name, pc := range cfg.OutgoingRateLimit.Platforms {
-				var mps float64
-				if pc.MaxPerSecond != nil {
-					mps = *pc.MaxPerSecond
-				}
-				var b int
-				if pc.Burst != nil {
-					b = *pc.Burst
-				}
-				overrides[name] = core.OutgoingRateLimitCfg{MaxPerSecond: mps, Burst: b}
-			}
-			if maxPS > 0 || len(overrides) > 0 {
-				engine.SetOutgoingRateLimitCfg(defaults, overrides)
-			}
-		}
-
-		engine.SetDisplaySaveFunc(func(mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error {
-			return config.SaveDisplayConfig(mode, thinkingMessages, thinkingMaxLen, toolMaxLen, toolMessages)
-		})
-
-		// Wire idle timeout
-		if cfg.IdleTimeoutMins != nil {
-			mins := *cfg.IdleTimeoutMins
-			if mins <= 0 {
-				engine.SetEventIdleTimeout(0)
-			} else {
-				engine.SetEventIdleTimeout(time.Duration(mins) * time.Minute)
-			}
-		}
-
-		// Wire max turn time (absolute per-turn wall-clock cap; 0 = disabled)
-		if cfg.MaxTurnTimeMins != nil && *cfg.MaxTurnTimeMins > 0 {
-			engine.SetMaxTurnTime(time.Duration(*cfg.MaxTurnTimeMins) * time.Minute)
-		}
-
-		// Wire queue depth
-		if cfg.Queue.MaxDepth != nil && *cfg.Queue.MaxDepth > 0 {
-			engine.SetMaxQueuedMessages(*cfg.Queue.MaxDepth)
-		}
-
-		// Wire auto-compress settings
-		if proj.AutoCompress.Enabled != nil && *proj.AutoCompress.Enabled {
-			minGap := 30 * time.Minute
-			if proj.AutoCompress.MinGapMins != nil {
-				minGap = time.Duration(*proj.AutoCompress.MinGapMins) * time.Minute
-			}
-			maxTokens := derefInt(proj.AutoCompress.MaxTokens)
-			if maxTokens <= 0 {
-				maxTokens = 12000
-			}
-			engine.SetAutoCompressConfig(true, maxTokens, minGap)
-		}
-		resetIdle, defaulted := resolveResetOnIdle(proj.ResetOnIdleMins)
-		engine.SetResetOnIdle(resetIdle)
-		if defaulted {
-			slog.Info("project: reset_on_idle_mins not set, applying default — set reset_on_idle_mins = 0 to opt out, see docs/usage.md",
-				"project", proj.Name, "default_minutes", defaultResetOnIdleMins)
-		}
-		if proj.AgentSessionIdleTimeoutMins != nil {
-			mins := *proj.AgentSessionIdleTimeoutMins
-			if mins <= 0 {
-				engine.SetAgentSessionIdleTimeout(0)
-			} else {
-				engine.SetAgentSessionIdleTimeout(time.Duration(mins) * time.Minute)
-			}
-		}
-
-		// Wire sender injection
-		if proj.InjectSender != nil {
-			engine.SetInjectSender(*proj.InjectSender)
-		}
-
-		// Wire speech-to-text if enabled
-		if cfg.Speech.Enabled {
-			speechCfg := core.SpeechCfg{
-				Enabled:  true,
-				Language: cfg.Speech.Language,
-			}
-			switch cfg.Speech.Provider {
-			case "groq":
-				apiKey := cfg.Speech.Groq.APIKey
-				model := cfg.Speech.Groq.Model
-				if model == "" {
-					model = "whisper-large-v3-turbo"
-				}
-				if apiKey != "" {
-					speechCfg.STT = core.NewOpenAIWhisper(apiKey, "https://api.groq.com/openai/v1", model)
-				} else {
-					slog.Warn("speech: groq provider enabled but api_key is empty")
-				}
-			case "qwen":
-				apiKey := cfg.Speech.Qwen.APIKey
-				baseURL := cfg.Speech.Qwen.BaseURL
-				model := cfg.Speech.Qwen.Model
-				if apiKey != "" {
-					speechCfg.STT = core.NewQwenASR(apiKey, baseURL, model)
-				} else {
-					slog.Warn("speech: qwen provider enabled but api_key is empty")
-				}
-			case "gemini":
-				apiKey := cfg.Speech.Gemini.APIKey
-				model := cfg.Speech.Gemini.Model
-				if apiKey != "" {
-					speechCfg.STT = core.NewGeminiSTT(apiKey, model)
-				} else {
-					slog.Warn("speech: gemini provider enabled but api_key is empty")
-				}
-			default: // "openai" or unspecified
-				apiKey := cfg.Speech.OpenAI.APIKey
-				baseURL := cfg.Speech.OpenAI.BaseURL
-				model := cfg.Speech.OpenAI.Model
-				if apiKey != "" {
-					speechCfg.STT = core.NewOpenAIWhisper(apiKey, baseURL, model)
-				} else {
-					slog.Warn("speech: openai provider enabled but api_key is empty")
-				}
-			}
-			if speechCfg.STT != nil {
-				engine.SetSpeechConfig(speechCfg)
-				slog.Info("speech: enabled", "provider", cfg.Speech.Provider)
-			}
-		}
-
-		// Wire text-to-speech if enabled
-		ttsEffective := config.ResolveTTSConfigForProject(cfg.TTS, proj.Name)
-		if ttsEffective.Enabled {
-			ttsCfg := &core.TTSCfg{
-				Enabled:      true,
-				Voice:        ttsEffective.Voice,
-				LanguageType: ttsEffective.LanguageType,
-				Speed:        ttsEffective.Speed,
-				MaxTextLen:   ttsEffective.MaxTextLen,
-			}
-			initMode := ttsEffective.TTSMode
-			switch initMode {
-			case "always", "voice_only":
-			case "":
-				initMode = "voice_only"
-			default:
-				slog.Warn("tts: invalid tts_mode in config, falling back to voice_only", "tts_mode", initMode)
-				initMode = "voice_only"
-			}
-			ttsCfg.SetTTSMode(initMode)
-			switch ttsEffective.Provider {
-			case "qwen":
-				apiKey := cfg.TTS.Qwen.APIKey
-				baseURL := cfg.TTS.Qwen.BaseURL
-				model := cfg.TTS.Qwen.Model
-				if apiKey != "" {
-					ttsCfg.TTS = core.NewQwenTTS(apiKey, baseURL, model, nil)
-					ttsCfg.Provider = "qwen"
-				} else {
-					slog.Warn("tts: qwen provider enabled but api_key is empty")
-				}
-			case "minimax":
-				apiKey := cfg.TTS.MiniMax.APIKey
-				baseURL := cfg.TTS.MiniMax.BaseURL
-				model := cfg.TTS.MiniMax.Model
-				if apiKey == "" {
-					localCfg, err := config.LoadMiniMaxLocalConfig(cfg.DataDir, cfg.TTS.MiniMax.ConfigFile)
-					if err != nil {
-						slog.Warn("tts: failed to load minimax local config", "error", err)
-					} else {
-						apiKey = localCfg.APIKey
-						if baseURL == "" {
-							if localCfg.BaseURL != "" {
-								baseURL = localCfg.BaseURL
-							} else if localCfg.APIHost != "" {
-								baseURL = localCfg.APIHost
-							}
-						}
-					}
-				}
-				if apiKey != "" {
-					ttsCfg.TTS = core.NewMiniMaxTTS(apiKey, baseURL, model, nil)
-					ttsCfg.Provider = "minimax"
-				} else {
-					slog.Warn("tts: minimax provider enabled but api_key is empty")
-				}
-			case "mimo":
-				apiKey := cfg.TTS.Mimo.APIKey
-				baseURL := cfg.TTS.Mimo.BaseURL
-				model := cfg.TTS.Mimo.Model
-				if apiKey != "" {
-					ttsCfg.TTS = core.NewMimoTTS(apiKey, baseURL, model, nil)
-					ttsCfg.Provider = "mimo"
-				} else {
-					slog.Warn("tts: mimo provider enabled but api_key is empty")
-				}
-			case "espeak":
-				voice := ttsEffective.Voice
-				if voice == "" {
-					voice = "zh" // default to Chinese
-				}
-				ttsCfg.TTS = core.NewEspeakTTS("", voice)
-				ttsCfg.Provider = "espeak"
-			case "pico":
-				voice := ttsEffective.Voice
-				if voice == "" {
-					voice = "zh-CN" // default to Chinese (Simplified)
-				}
-				ttsCfg.TTS = core.NewPicoTTS("", voice)
-				ttsCfg.Provider = "pico"
-			case "edge":
-				voice := ttsEffective.Voice
-				if voice == "" {
-					voice = "zh-CN-XiaoxiaoNeural" // default Chinese neural voice
-				}
-				ttsCfg.TTS = core.NewEdgeTTS(voice)
-				ttsCfg.Provider = "edge"
-			default: // "openai" or unspecified
-				apiKey := cfg.TTS.OpenAI.APIKey
-				baseURL := cfg.TTS.OpenAI.BaseURL
-				model := cfg.TTS.OpenAI.Model
-				if apiKey != "" {
-					ttsCfg.TTS = core.NewOpenAITTS(apiKey, baseURL, model, nil)
-					ttsCfg.Provider = "openai"
-				} else {
-					slog.Warn("tts: openai provider enabled but api_key is empty")
-				}
-			}
-			if ttsCfg.TTS != nil {
-				engine.SetTTSConfig(ttsCfg)
-				engine.SetTTSSaveFunc(func(mode string) error {
-					return config.SaveTTSMode(mode)
-				})
-				slog.Info("tts: enabled", "provider", ttsCfg.Provider, "voice", ttsCfg.Voice, "mode", initMode)
-			}
-		}
-
-		// Set up save callback for auto-detected language
-		if lang == core.LangAuto {
-			engine.SetLanguageSaveFunc(func(l core.Language) error {
-				return config.SaveLanguage(string(l))
-			})
-		}
-
-		// Set up save callbacks for provider management
-		projName := proj.Name
-		engine.SetProviderSaveFunc(func(providerName string) error {
-			return config.SaveActiveProvider(projName, providerName)
-		})
-		engine.SetProviderAddSaveFunc(func(p core.ProviderConfig) error {
-			cp := config.ProviderConfig{
-				Name: p.Name, APIKey: p.APIKey, BaseURL: p.BaseURL,
-				Model: p.Model, Models: convertCoreModels(p.Models), Thinking: p.Thinking, Env: p.Env,
-			}
-			if p.CodexWireAPI != "" || len(p.CodexHTTPHeaders) > 0 {
-				cp.Codex = &config.CodexProviderConfig{
-					WireAPI: p.CodexWireAPI, HTTPHeaders: p.CodexHTTPHeaders,
-				}
-			}
-			return config.AddProviderToConfig(projName, cp)
-		})
-		engine.SetProviderRemoveSaveFunc(func(name string) error {
-			return config.RemoveProviderFromConfig(projName, name)
-		})
-		engine.SetProviderModelSaveFunc(func(providerName, model string) error {
-			return config.SaveProviderModel(projName, providerName, model)
-		})
-		engine.SetProviderRefsSaveFunc(func(refs []string) error {
-			return config.SaveProviderRefs(projName, refs)
-		})
-		engine.SetListGlobalProvidersFunc(func(agentType string) ([]core.ProviderConfig, error) {
-			globals, err := config.ListGlobalProviders()
-			if err != nil {
-				return nil, err
-			}
-			var result []core.ProviderConfig
-			for _, g := range globals {
-				if len(g.AgentTypes) > 0 && !containsString(g.AgentTypes, agentType) {
-					continue
-				}
-				result = append(result, configProviderToCore(g.ResolveForAgent(agentType)))
-			}
-			return result, nil
-		})
-		engine.SetModelSaveFunc(func(model string) error {
-			return config.SaveAgentModel(projName, model)
-		})
-
-		// Wire config reload
-		capturedEngine := engine
-		capturedProjName := projName
-		engine.SetConfigReloadFunc(func() (*core.ConfigReloadResult, error) {
-			return reloadConfig(configPath, capturedProjName, capturedEngine)
-		})
-
-		// Wire /web command callbacks
-		engine.SetWebSetupFunc(func() (int, string, bool, error) {
-			mgmtToken := core.GenerateToken(16)
-			bridgeToken := core.GenerateToken(16)
-			result, err := config.EnableWebAdmin(mgmtToken, bridgeToken)
-			if err != nil {
-				return 0, "", false, err
-			}
-			return result.ManagementPort, result.ManagementToken, !result.AlreadyEnabled, nil
-		})
-		engine.SetWebStatusFunc(func() string {
-			if cfg.Management.Enabled == nil || !*cfg.Management.Enabled {
-				return ""
-			}
-			port := cfg.Management.Port
-			if port == 0 {
-				port = 9820
-			}
-			return fmt.Sprintf("http://localhost:%d", port)
-		})
-
-		engines = append(engines, engine)
-		effectiveWorkDirs = append(effectiveWorkDirs, effectiveWorkDir)
+		rt.add(pr)
 	}
 
 	// Start cron scheduler
@@ -979,10 +384,7 @@ func main() {
 		if cfg.Cron.SessionMode != "" {
 			cronSched.SetDefaultSessionMode(cfg.Cron.SessionMode)
 		}
-		for i, e := range engines {
-			cronSched.RegisterEngine(cfg.Projects[i].Name, e)
-			e.SetCronScheduler(cronSched)
-		}
+		rt.cronSched = cronSched
 	}
 
 	// Start timer scheduler
@@ -999,20 +401,15 @@ func main() {
 		if cfg.Cron.SessionMode != "" {
 			timerSched.SetDefaultSessionMode(cfg.Cron.SessionMode)
 		}
-		for i, e := range engines {
-			timerSched.RegisterEngine(cfg.Projects[i].Name, e)
-			e.SetTimerScheduler(timerSched)
-		}
+		rt.timerSched = timerSched
 	}
 
 	// Start heartbeat scheduler
 	heartbeatSched := core.NewHeartbeatScheduler(cfg.DataDir)
-	for i, proj := range cfg.Projects {
-		hbCfg := buildHeartbeatConfig(proj.Heartbeat)
-		if hbCfg.Enabled {
-			heartbeatSched.Register(proj.Name, hbCfg, engines[i], effectiveWorkDirs[i])
-		}
-		engines[i].SetHeartbeatScheduler(heartbeatSched)
+	rt.heartbeatSched = heartbeatSched
+	engines := rt.engines()
+	for _, pr := range rt.list() {
+		rt.registerSchedulers(pr)
 	}
 
 	var startErrors []error
@@ -1064,10 +461,9 @@ func main() {
 			slog.Error("bridge: failed to create server - token is required (or set insecure=true for local dev)")
 			os.Exit(1)
 		}
-		for i, e := range engines {
-			bp := bridgeSrv.NewPlatform(cfg.Projects[i].Name)
-			bridgeSrv.RegisterEngine(cfg.Projects[i].Name, e, bp)
-			e.AddPlatform(bp)
+		rt.bridgeSrv = bridgeSrv
+		for _, pr := range rt.list() {
+			rt.registerBridge(pr)
 		}
 		bridgeSrv.Start()
 	}
@@ -1084,8 +480,9 @@ func main() {
 			path = "/hook"
 		}
 		webhookSrv = core.NewWebhookServer(port, cfg.Webhook.Token, path)
-		for i, e := range engines {
-			webhookSrv.RegisterEngine(cfg.Projects[i].Name, e)
+		rt.webhookSrv = webhookSrv
+		for _, pr := range rt.list() {
+			webhookSrv.RegisterEngine(pr.cfg.Name, pr.engine)
 		}
 		webhookSrv.Start()
 	}
@@ -1098,8 +495,10 @@ func main() {
 			port = 9820
 		}
 		mgmtSrv = core.NewManagementServer(port, cfg.Management.Token, cfg.Management.CORSOrigins)
-		for i, e := range engines {
-			mgmtSrv.RegisterEngine(cfg.Projects[i].Name, e)
+		rt.mgmtSrv = mgmtSrv
+		mgmtSrv.SetReloadFunc(rt.Reload)
+		for _, pr := range rt.list() {
+			mgmtSrv.RegisterEngine(pr.cfg.Name, pr.engine)
 		}
 		if cronSched != nil {
 			mgmtSrv.SetCronScheduler(cronSched)
@@ -1111,12 +510,12 @@ func main() {
 		if bridgeSrv != nil {
 			mgmtSrv.SetBridgeServer(bridgeSrv)
 		}
-		mgmtSrv.SetSetupFeishuSave(func(req core.FeishuSetupSaveRequest) error {
+		mgmtSrv.SetSetupFeishuSave(func(ctx context.Context, req core.FeishuSetupSaveRequest) error {
 			platType := req.PlatformType
 			if platType == "" {
 				platType = "feishu"
 			}
-			_, err := config.EnsureProjectWithFeishuPlatform(config.EnsureProjectWithFeishuOptions{
+			_, err := config.EnsureProjectWithFeishuPlatform(configChangeCtx(ctx), config.EnsureProjectWithFeishuOptions{
 				ProjectName:  req.ProjectName,
 				PlatformType: platType,
 				WorkDir:      req.WorkDir,
@@ -1125,7 +524,7 @@ func main() {
 			if err != nil {
 				return fmt.Errorf("ensure project: %w", err)
 			}
-			_, err = config.SaveFeishuPlatformCredentials(config.FeishuCredentialUpdateOptions{
+			_, err = config.SaveFeishuPlatformCredentials(configChangeCtx(ctx), config.FeishuCredentialUpdateOptions{
 				ProjectName:       req.ProjectName,
 				PlatformType:      platType,
 				AppID:             req.AppID,
@@ -1135,8 +534,8 @@ func main() {
 			})
 			return err
 		})
-		mgmtSrv.SetSetupWeixinSave(func(req core.WeixinSetupSaveRequest) error {
-			_, err := config.EnsureProjectWithWeixinPlatform(config.EnsureProjectWithWeixinOptions{
+		mgmtSrv.SetSetupWeixinSave(func(ctx context.Context, req core.WeixinSetupSaveRequest) error {
+			_, err := config.EnsureProjectWithWeixinPlatform(configChangeCtx(ctx), config.EnsureProjectWithWeixinOptions{
 				ProjectName: req.ProjectName,
 				WorkDir:     req.WorkDir,
 				AgentType:   req.AgentType,
@@ -1144,7 +543,7 @@ func main() {
 			if err != nil {
 				return fmt.Errorf("ensure project: %w", err)
 			}
-			_, err = config.SaveWeixinPlatformCredentials(config.WeixinCredentialUpdateOptions{
+			_, err = config.SaveWeixinPlatformCredentials(configChangeCtx(ctx), config.WeixinCredentialUpdateOptions{
 				ProjectName:       req.ProjectName,
 				Token:             req.Token,
 				BaseURL:           req.BaseURL,
@@ -1154,15 +553,17 @@ func main() {
 			})
 			return err
 		})
-		mgmtSrv.SetAddPlatformToProject(func(projectName, platType string, opts map[string]any, workDir, agentType string) error {
+		mgmtSrv.SetAddPlatformToProject(func(ctx context.Context, projectName, platType string, opts map[string]any, workDir, agentType string) error {
 			if opts == nil {
 				opts = map[string]any{}
 			}
-			return config.AddPlatformToProject(projectName, config.PlatformConfig{Type: platType, Options: opts}, workDir, agentType)
+			return config.AddPlatformToProject(configChangeCtx(ctx), projectName, config.PlatformConfig{Type: platType, Options: opts}, workDir, agentType)
 		})
-		mgmtSrv.SetRemoveProject(config.RemoveProject)
-		mgmtSrv.SetSaveProjectSettings(func(name string, u core.ProjectSettingsUpdate) error {
-			return config.SaveProjectSettings(name, config.ProjectSettingsUpdate{
+		mgmtSrv.SetRemoveProject(func(ctx context.Context, name string) error {
+			return config.RemoveProject(configChangeCtx(ctx), name)
+		})
+		mgmtSrv.SetSaveProjectSettings(func(ctx context.Context, name string, u core.ProjectSettingsUpdate) error {
+			return config.SaveProjectSettings(configChangeCtx(ctx), name, config.ProjectSettingsUpdate{
 				Language:             u.Language,
 				AdminFrom:            u.AdminFrom,
 				DisabledCommands:     u.DisabledCommands,
@@ -1177,10 +578,13 @@ func main() {
 			})
 		})
 		mgmtSrv.SetGetProjectConfig(config.GetProjectConfigDetails)
-		mgmtSrv.SetSaveProviderRefs(config.SaveProviderRefs)
+		mgmtSrv.SetSaveProviderRefs(func(ctx context.Context, projectName string, refs []string) error {
+			return config.SaveProviderRefs(configChangeCtx(ctx), projectName, refs)
+		})
 		mgmtSrv.SetConfigFilePath(configPath)
+		mgmtSrv.SetConfigHistoryFuncs(configHistoryForCore, configDiffForCore, rollbackConfigForCore)
 		mgmtSrv.SetGetGlobalSettings(config.GetGlobalSettings)
-		mgmtSrv.SetSaveGlobalSettings(func(updates map[string]any) error {
+		mgmtSrv.SetSaveGlobalSettings(func(ctx context.Context, updates map[string]any) error {
 			u := config.GlobalSettingsUpdate{}
 			if v, ok := updates["language"].(string); ok {
 				u.Language = &v
@@ -1224,7 +628,7 @@ func main() {
 				iv := int(v)
 				u.RateLimitWindow = &iv
 			}
-			return config.SaveGlobalSettings(u)
+			return config.SaveGlobalSettings(configChangeCtx(ctx), u)
 		})
 		mgmtSrv.SetListGlobalProviders(func() ([]core.GlobalProviderInfo, error) {
 			providers, err := config.ListGlobalProviders()
@@ -1237,14 +641,14 @@ func main() {
 			}
 			return out, nil
 		})
-		mgmtSrv.SetAddGlobalProvider(func(info core.GlobalProviderInfo) error {
-			return config.AddGlobalProvider(globalProviderToConfig(info))
+		mgmtSrv.SetAddGlobalProvider(func(ctx context.Context, info core.GlobalProviderInfo) error {
+			return config.AddGlobalProvider(configChangeCtx(ctx), globalProviderToConfig(info))
 		})
-		mgmtSrv.SetUpdateGlobalProvider(func(name string, info core.GlobalProviderInfo) error {
-			return config.UpdateGlobalProvider(name, globalProviderToConfig(info))
+		mgmtSrv.SetUpdateGlobalProvider(func(ctx context.Context, name string, info core.GlobalProviderInfo) error {
+			return config.UpdateGlobalProvider(configChangeCtx(ctx), name, globalProviderToConfig(info))
 		})
-		mgmtSrv.SetRemoveGlobalProvider(func(name string) error {
-			return config.RemoveGlobalProvider(name)
+		mgmtSrv.SetRemoveGlobalProvider(func(ctx context.Context, name string) error {
+			return config.RemoveGlobalProvider(configChangeCtx(ctx), name)
 		})
 		mgmtSrv.SetFetchPresets(core.FetchProviderPresets)
 		mgmtSrv.SetFetchSkillPresets(core.FetchSkillPresets)
@@ -1276,19 +680,11 @@ func main() {
 		apiSrv.SetRelayManager(relayMgr)
 
 		// Create shared DirHistory for all engines
-		dirHistory := core.NewDirHistory(cfg.DataDir)
-
-		for i, e := range engines {
-			apiSrv.RegisterEngine(cfg.Projects[i].Name, e)
-			e.SetRelayManager(relayMgr)
-			e.SetDirHistory(dirHistory)
-
-			// Ensure initial work_dir is in history
-			if initWorkDir := effectiveWorkDirs[i]; initWorkDir != "" {
-				if !dirHistory.Contains(cfg.Projects[i].Name, initWorkDir) {
-					dirHistory.Add(cfg.Projects[i].Name, initWorkDir)
-				}
-			}
+		rt.apiSrv = apiSrv
+		rt.relayMgr = relayMgr
+		rt.dirHistory = core.NewDirHistory(cfg.DataDir)
+		for _, pr := range rt.list() {
+			rt.registerAPI(pr)
 		}
 		if cronSched != nil {
 			apiSrv.SetCronScheduler(cronSched)
@@ -1300,6 +696,9 @@ func main() {
 	}
 
 	slog.Info("cc-connect is running", "projects", len(engines))
+	if cfg.AutoReload != nil && *cfg.AutoReload {
+		rt.startWatcher()
+	}
 
 	// After startup, check if we were restarted and queue the success
 	// notification. The engine dispatches it on the first OnPlatformReady
@@ -1325,6 +724,7 @@ func main() {
 	}
 
 	slog.Info("shutting down...")
+	rt.stopWatcher()
 	if mgmtSrv != nil {
 		mgmtSrv.Stop()
 	}
@@ -1344,7 +744,7 @@ func main() {
 	if apiSrv != nil {
 		apiSrv.Stop()
 	}
-	for _, e := range engines {
+	for _, e := range rt.engines() {
 		if err := e.Stop(); err != nil {
 			slog.Error("shutdown error", "error", err)
 		}
@@ -1385,6 +785,11 @@ func runTopLevelCommand(args []string) bool {
 	if len(args) == 0 {
 		return false
 	}
+	if run, ok := configWritingCommands[args[0]]; ok {
+		ctx := config.WithChangeSource(context.Background(), config.ChangeSource{Actor: "cli", Reason: cliChangeReason(args)})
+		run(ctx, args[1:])
+		return true
+	}
 	handler, ok := topLevelCommandHandlers[args[0]]
 	if !ok {
 		return false
@@ -1393,6 +798,28 @@ func runTopLevelCommand(args []string) bool {
 	return true
 }
 
+// configWritingCommands are the subcommands that may edit config.toml. They
+// run with a context that records their writes in the config history with
+// actor "cli".
+var configWritingCommands = map[string]func(context.Context, []string){
+	"config":   runConfig,
+	"provider": runProviderCommand,
+	"feishu":   runFeishu,
+	"weixin":   runWeixin,
+	"yuanbao":  runYuanbao,
+	"web":      runWeb,
+}
+
+// cliChangeReason names a subcommand for the config history, without flag
+// values such as API keys.
+func cliChangeReason(args []string) string {
+	reason := "cc-connect " + args[0]
+	if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+		reason += " " + args[1]
+	}
+	return reason
+}
+
 type rootCLIOptions struct {
 	configPath     string
 	force          bool
@@ -1680,6 +1107,15 @@ Commands:
     format           Format the config file (alias: fmt)
     path             Print the resolved config file path
 
+  secrets            Manage encrypted secrets referenced as "secret:<name>"
+    set              Store a secret (reads stdin when no value is given)
+    get              Print a secret
+    list             List secret names
+    delete           Remove a secret
+
+  i18n               Inspect UI message catalogs and data-dir overrides
+    check            Report missing, unknown and malformed keys per language
+
   update             Check for updates and upgrade the binary (--pre for beta)
   check-update       Check if a newer version is available
   config-example     (deprecated: use 'config example' instead)
@@ -1721,33 +1157,73 @@ func setupLogger(level string, w io.Writer) {
 	})))
 }
 
-// reloadConfig re-reads config.toml and applies hot-reloadable settings
-// (display, providers, commands) to the given engine.
-func reloadConfig(configPath, projName string, engine *core.Engine) (*core.ConfigReloadResult, error) {
-	cfg, err := config.Load(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("reload config: %w", err)
+// providerFailoverChain returns the project's provider_failover list without
+// the names that match none of its providers.
+func providerFailoverChain(proj config.ProjectConfig) []string {
+	var chain []string
+	for _, name := range proj.Agent.ProviderFailover {
+		if !slices.ContainsFunc(proj.Agent.Providers, func(p config.ProviderConfig) bool { return p.Name == name }) {
+			slog.Warn("provider_failover: unknown provider, skipping", "project", proj.Name, "provider", name)
+			continue
+		}
+		chain = append(chain, name)
 	}
+	return chain
+}
 
-	result := &core.ConfigReloadResult{}
+func providerFailoverCooldown(proj config.ProjectConfig) time.Duration {
+	if proj.Agent.ProviderFailoverCooldownMins == nil {
+		return 0
+	}
+	return time.Duration(*proj.Agent.ProviderFailoverCooldownMins) * time.Minute
+}
 
-	// Re-apply process-global hot-reloadable settings.
-	if globalAPIServer != nil {
-		globalAPIServer.SetMaxAttachmentSize(resolveMaxAttachmentSize(cfg))
+// attachmentConvertCfg converts [attachment_convert] into core settings.
+// Zero limits fall back to the core defaults.
+func attachmentConvertCfg(c config.AttachmentConvertConfig) core.AttachmentConvertCfg {
+	ac := core.AttachmentConvertCfg{
+		Enabled:   c.Enabled != nil && *c.Enabled,
+		PDFToText: c.PDFToText,
+	}
+	if c.MaxArchiveFiles != nil {
+		ac.MaxArchiveFiles = *c.MaxArchiveFiles
+	}
+	if c.MaxArchiveSizeMB != nil {
+		ac.MaxArchiveBytes = int64(*c.MaxArchiveSizeMB) << 20
 	}
+	if c.MaxTextKB != nil {
+		ac.MaxTextBytes = int64(*c.MaxTextKB) << 10
+	}
+	return ac
+}
 
-	// Find the matching project
-	var proj *config.ProjectConfig
-	for i := range cfg.Projects {
-		if cfg.Projects[i].Name == projName {
-			proj = &cfg.Projects[i]
-			break
+// providerHealthCfg converts [provider_health] into the engine's probe
+// settings. Probing stays off unless enabled.
+func providerHealthCfg(c config.ProviderHealthConfig) core.ProviderHealthCfg {
+	if c.Enabled == nil || !*c.Enabled {
+		return core.ProviderHealthCfg{}
+	}
+	secs := func(v *int, def int) time.Duration {
+		if v != nil && *v > 0 {
+			return time.Duration(*v) * time.Second
 		}
+		return time.Duration(def) * time.Second
 	}
-	if proj == nil {
-		return nil, fmt.Errorf("project %q not found in config", projName)
+	hc := core.ProviderHealthCfg{
+		Interval:     secs(c.IntervalSecs, 300),
+		Timeout:      secs(c.TimeoutSecs, 10),
+		OpenDuration: secs(c.OpenSecs, 600),
 	}
+	if c.FailureThreshold != nil {
+		hc.FailureThreshold = *c.FailureThreshold
+	}
+	return hc
+}
 
+// applyEngineSettings re-applies the hot-reloadable settings (display,
+// providers, commands, access rules, ...) of one project to its running
+// engine. Structural changes are handled by appRuntime.Reload.
+func applyEngineSettings(cfg *config.Config, proj *config.ProjectConfig, engine *core.Engine, result *core.ConfigReloadResult) {
 	// Reload display config (includes legacy quiet → display mapping)
 	mode, tm, tool, tmlen, toollen, showCtx, showFooter, hideAgentFooter := config.EffectiveDisplay(cfg, proj)
 	historyMaxLen := config.EffectiveHistoryMaxLen(cfg, proj)
@@ -1760,6 +1236,7 @@ func reloadConfig(configPath, projName string, engine *core.Engine) (*core.Confi
 		ToolMessages:     tool,
 		HistoryMaxLen:    &historyMaxLen,
 		HideAgentFooter:  hideAgentFooter,
+		PagedReplies:     config.EffectivePagedReplies(cfg, proj),
 	})
 	result.DisplayUpdated = true
 
@@ -1786,6 +1263,7 @@ func reloadConfig(configPath, projName string, engine *core.Engine) (*core.Confi
 	} else {
 		engine.SetAutoCompressConfig(false, 0, 0)
 	}
+	engine.SetAutoAttachConfig(autoAttachToCore(proj.AutoAttach))
 	resetIdle, defaulted := resolveResetOnIdle(proj.ResetOnIdleMins)
 	engine.SetResetOnIdle(resetIdle)
 	if defaulted {
@@ -1824,6 +1302,15 @@ func reloadConfig(configPath, projName string, engine *core.Engine) (*core.Confi
 	// Reload filter_external_sessions
 	engine.SetFilterExternalSessions(proj.FilterExternalSessions != nil && *proj.FilterExternalSessions)
 
+	// Reload local reference rendering
+	engine.SetReferenceConfig(core.ReferenceRenderCfg{
+		NormalizeAgents: proj.References.NormalizeAgents,
+		RenderPlatforms: proj.References.RenderPlatforms,
+		DisplayPath:     proj.References.DisplayPath,
+		MarkerStyle:     proj.References.MarkerStyle,
+		EnclosureStyle:  proj.References.EnclosureStyle,
+	})
+
 	// Reload providers
 	if ps, ok := engine.GetAgent().(core.ProviderSwitcher); ok {
 		providers := make([]core.ProviderConfig, len(proj.Agent.Providers))
@@ -1831,12 +1318,14 @@ func reloadConfig(configPath, projName string, engine *core.Engine) (*core.Confi
 			providers[i] = configProviderToCore(p)
 		}
 		ps.SetProviders(providers)
-		result.ProvidersUpdated = len(providers)
+		result.ProvidersUpdated += len(providers)
 
 		if active, _ := proj.Agent.Options["provider"].(string); active != "" {
 			ps.SetActiveProvider(active)
 		}
 	}
+	engine.SetProviderFailover(providerFailoverChain(*proj), providerFailoverCooldown(*proj))
+	engine.SetProviderHealthCheck(providerHealthCfg(cfg.ProviderHealth))
 
 	// Reload custom commands
 	engine.ClearCommands("config")
@@ -1866,9 +1355,6 @@ func reloadConfig(configPath, projName string, engine *core.Engine) (*core.Confi
 	} else {
 		engine.SetUserRoles(nil)
 	}
-
-	slog.Info("config reloaded", "project", projName)
-	return result, nil
 }
 
 func buildUserRoleManager(uc *config.UsersConfig) *core.UserRoleManager {
@@ -1910,6 +1396,22 @@ func configProviderToCore(p config.ProviderConfig) core.ProviderConfig {
 		Model: p.Model, Models: convertProviderModels(p.Models),
 		Thinking: p.Thinking, Env: p.Env,
 	}
+	c.APIKeys, c.APIKeyEnv, c.KeyRotation = p.APIKeys, p.APIKeyEnv, p.KeyRotation
+	if p.KeyCooldownSecs != nil {
+		c.KeyCooldown = time.Duration(*p.KeyCooldownSecs) * time.Second
+	}
+	if p.Proxy != nil {
+		c.Proxy = core.ProxyRules{
+			ModelMap: p.Proxy.ModelMap, StripFields: p.Proxy.StripFields,
+			StripBetas: p.Proxy.StripBetas, Headers: p.Proxy.Headers,
+			MaxTokens: p.Proxy.MaxTokens, LogTraffic: p.Proxy.LogTraffic,
+			CassetteMode: p.Proxy.CassetteMode, CassetteDir: p.Proxy.CassetteDir,
+			CassetteLooseMatch: p.Proxy.CassetteLooseMatch,
+		}
+		if p.Proxy.Protocol == core.ProxyProtocolOpenAI {
+			c.Proxy.Protocol = core.ProxyProtocolOpenAI
+		}
+	}
 	if p.Codex != nil {
 		c.CodexWireAPI = p.Codex.WireAPI
 		c.CodexHTTPHeaders = p.Codex.HTTPHeaders
@@ -2053,12 +1555,14 @@ func convertCoreModels(ms []core.ModelOption) []config.ProviderModelConfig {
 
 func buildHeartbeatConfig(hc config.HeartbeatConfig) core.HeartbeatConfig {
 	cfg := core.HeartbeatConfig{
+		Name:         hc.Name,
 		IntervalMins: 30,
 		OnlyWhenIdle: true,
 		Silent:       true,
 		TimeoutMins:  30,
 		SessionKey:   hc.SessionKey,
 		Prompt:       hc.Prompt,
+		PromptFile:   hc.PromptFile,
 	}
 	if hc.Enabled != nil {
 		cfg.Enabled = *hc.Enabled
@@ -2078,6 +1582,17 @@ func buildHeartbeatConfig(hc config.HeartbeatConfig) core.HeartbeatConfig {
 	return cfg
 }
 
+// autoAttachToCore converts [projects.auto_attach]; core fills in defaults
+// for unset fields.
+func autoAttachToCore(c config.AutoAttachConfig) core.AutoAttachCfg {
+	return core.AutoAttachCfg{
+		Enabled:     c.Enabled != nil && *c.Enabled,
+		Patterns:    c.Patterns,
+		MaxFileSize: int64(derefInt(c.MaxFileSizeMB)) << 20,
+		MaxFiles:    derefInt(c.MaxFiles),
+	}
+}
+
 func derefInt(v *int) int {
 	if v == nil {
 		return 0
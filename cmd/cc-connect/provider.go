@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"flag"
@@ -15,7 +16,7 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-func runProviderCommand(args []string) {
+func runProviderCommand(ctx context.Context, args []string) {
 	if len(args) == 0 {
 		printProviderUsage()
 		os.Exit(1)
@@ -23,17 +24,17 @@ func runProviderCommand(args []string) {
 
 	switch args[0] {
 	case "add":
-		runProviderAdd(args[1:])
+		runProviderAdd(ctx, args[1:])
 	case "list":
 		runProviderList(args[1:])
 	case "remove":
-		runProviderRemove(args[1:])
+		runProviderRemove(ctx, args[1:])
 	case "import":
-		runProviderImport(args[1:])
+		runProviderImport(ctx, args[1:])
 	case "presets":
 		runProviderPresets(args[1:])
 	case "global":
-		runProviderGlobal(args[1:])
+		runProviderGlobal(ctx, args[1:])
 	case "help", "--help", "-h":
 		printProviderUsage()
 	default:
@@ -70,7 +71,7 @@ func initConfigPath(flagValue string) {
 	config.ConfigPath = resolveConfigPath(flagValue)
 }
 
-func runProviderAdd(args []string) {
+func runProviderAdd(ctx context.Context, args []string) {
 	fs := flag.NewFlagSet("provider add", flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	project := fs.String("project", "", "project name (required)")
@@ -99,7 +100,7 @@ func runProviderAdd(args []string) {
 		p.Env = parseEnvStr(*envStr)
 	}
 
-	if err := config.AddProviderToConfig(*project, p); err != nil {
+	if err := config.AddProviderToConfig(ctx, *project, p); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -179,7 +180,7 @@ func listProjectProviders(projectName string) {
 	}
 }
 
-func runProviderRemove(args []string) {
+func runProviderRemove(ctx context.Context, args []string) {
 	fs := flag.NewFlagSet("provider remove", flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	project := fs.String("project", "", "project name (required)")
@@ -194,7 +195,7 @@ func runProviderRemove(args []string) {
 
 	initConfigPath(*configFile)
 
-	if err := config.RemoveProviderFromConfig(*project, *name); err != nil {
+	if err := config.RemoveProviderFromConfig(ctx, *project, *name); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -204,7 +205,7 @@ func runProviderRemove(args []string) {
 
 // ── Import from cc-switch ──────────────────────────────────────
 
-func runProviderImport(args []string) {
+func runProviderImport(ctx context.Context, args []string) {
 	fs := flag.NewFlagSet("provider import", flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	project := fs.String("project", "", "target project name (auto-detect if only one)")
@@ -278,7 +279,7 @@ func runProviderImport(args []string) {
 			continue
 		}
 
-		if err := config.AddProviderToConfig(targetProject, provider); err != nil {
+		if err := config.AddProviderToConfig(ctx, targetProject, provider); err != nil {
 			if strings.Contains(err.Error(), "already exists") {
 				fmt.Printf("  ⏭ Skip %q: already exists\n", provider.Name)
 				skipped++
@@ -580,7 +581,7 @@ func runProviderPresets(args []string) {
 
 // ── Global provider management ─────────────────────────────────
 
-func runProviderGlobal(args []string) {
+func runProviderGlobal(ctx context.Context, args []string) {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, `Usage: cc-connect provider global <command>
 
@@ -595,9 +596,9 @@ Commands:
 	case "list":
 		runGlobalProviderList(args[1:])
 	case "add":
-		runGlobalProviderAdd(args[1:])
+		runGlobalProviderAdd(ctx, args[1:])
 	case "remove":
-		runGlobalProviderRemove(args[1:])
+		runGlobalProviderRemove(ctx, args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown global subcommand: %s\n", args[0])
 		os.Exit(1)
@@ -643,7 +644,7 @@ func runGlobalProviderList(args []string) {
 	}
 }
 
-func runGlobalProviderAdd(args []string) {
+func runGlobalProviderAdd(ctx context.Context, args []string) {
 	fs := flag.NewFlagSet("provider global add", flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	name := fs.String("name", "", "provider name (required)")
@@ -673,7 +674,7 @@ func runGlobalProviderAdd(args []string) {
 		p.Env = parseEnvStr(*envStr)
 	}
 
-	if err := config.AddGlobalProvider(p); err != nil {
+	if err := config.AddGlobalProvider(ctx, p); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -683,7 +684,7 @@ func runGlobalProviderAdd(args []string) {
 	fmt.Printf("  [projects.agent]\n  provider_refs = [\"%s\"]\n", *name)
 }
 
-func runGlobalProviderRemove(args []string) {
+func runGlobalProviderRemove(ctx context.Context, args []string) {
 	fs := flag.NewFlagSet("provider global remove", flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	name := fs.String("name", "", "provider name (required)")
@@ -697,7 +698,7 @@ func runGlobalProviderRemove(args []string) {
 
 	initConfigPath(*configFile)
 
-	if err := config.RemoveGlobalProvider(*name); err != nil {
+	if err := config.RemoveGlobalProvider(ctx, *name); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
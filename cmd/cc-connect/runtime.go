@@ -0,0 +1,1213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chenhg5/cc-connect/config"
+	"github.com/chenhg5/cc-connect/core"
+)
+
+// errSkipProject is returned by buildProject for a project that cannot be
+// set up but should not abort startup (e.g. an unusable base_dir).
+var errSkipProject = errors.New("project skipped")
+
+// configWatchInterval is how often the auto_reload watcher polls config.toml.
+const configWatchInterval = 2 * time.Second
+
+// projectRuntime is one running [[projects]] entry together with the config
+// it was built from, so a reload can tell what changed.
+type projectRuntime struct {
+	cfg       config.ProjectConfig
+	engine    *core.Engine
+	agent     core.Agent
+	platforms []core.Platform // parallel to cfg.Platforms; nil where creation failed
+	workDir   string          // effective work_dir after project state overrides
+}
+
+// appRuntime owns the running projects and every component they are
+// registered with, so a config reload can add, remove or rebuild projects
+// without restarting the process. Components that are disabled stay nil.
+type appRuntime struct {
+	mu         sync.Mutex // serializes reloads
+	configPath string
+	rootOpts   rootCLIOptions
+	cfg        *config.Config
+	projects   []*projectRuntime // in config order
+
+	cronSched      *core.CronScheduler
+	timerSched     *core.TimerScheduler
+	heartbeatSched *core.HeartbeatScheduler
+	bridgeSrv      *core.BridgeServer
+	webhookSrv     *core.WebhookServer
+	mgmtSrv        *core.ManagementServer
+	apiSrv         *core.APIServer
+	relayMgr       *core.RelayManager
+	dirHistory     *core.DirHistory
+	ttsCache       *core.TTSCache // shared by all projects; see sharedTTSCache
+
+	watchMu   sync.Mutex
+	watchStop chan struct{}
+}
+
+func newAppRuntime(configPath string, rootOpts rootCLIOptions, cfg *config.Config) *appRuntime {
+	return &appRuntime{configPath: configPath, rootOpts: rootOpts, cfg: cfg}
+}
+
+// sharedTTSCache returns the TTS cache for dir, reusing the one already
+// handed to other projects so that a single lock guards the directory.
+// Projects are built one at a time, at startup or under mu during reload.
+func (rt *appRuntime) sharedTTSCache(dir string, maxBytes int64) *core.TTSCache {
+	c := core.NewTTSCache(dir, maxBytes)
+	if old := rt.ttsCache; old != nil && old.Dir == c.Dir && old.MaxBytes == c.MaxBytes {
+		return old
+	}
+	rt.ttsCache = c
+	return c
+}
+
+// add appends a project built during startup.
+func (rt *appRuntime) add(pr *projectRuntime) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.projects = append(rt.projects, pr)
+}
+
+// list returns a snapshot of the running projects.
+func (rt *appRuntime) list() []*projectRuntime {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return append([]*projectRuntime(nil), rt.projects...)
+}
+
+// engines returns the engines of the running projects.
+func (rt *appRuntime) engines() []*core.Engine {
+	prs := rt.list()
+	engines := make([]*core.Engine, len(prs))
+	for i, pr := range prs {
+		engines[i] = pr.engine
+	}
+	return engines
+}
+
+// registerSchedulers wires a project into the cron, timer and heartbeat
+// schedulers.
+func (rt *appRuntime) registerSchedulers(pr *projectRuntime) {
+	name, e := pr.cfg.Name, pr.engine
+	if rt.cronSched != nil {
+		rt.cronSched.RegisterEngine(name, e)
+		e.SetCronScheduler(rt.cronSched)
+	}
+	if rt.timerSched != nil {
+		rt.timerSched.RegisterEngine(name, e)
+		e.SetTimerScheduler(rt.timerSched)
+	}
+	if rt.heartbeatSched != nil {
+		rt.registerHeartbeats(pr)
+		e.SetHeartbeatScheduler(rt.heartbeatSched)
+	}
+}
+
+func (rt *appRuntime) registerHeartbeats(pr *projectRuntime) {
+	for _, hb := range pr.cfg.AllHeartbeats() {
+		hbCfg := buildHeartbeatConfig(hb)
+		if hbCfg.Enabled {
+			rt.heartbeatSched.Register(pr.cfg.Name, hbCfg, pr.engine, pr.workDir)
+		}
+	}
+}
+
+// registerBridge gives a project its bridge platform.
+func (rt *appRuntime) registerBridge(pr *projectRuntime) {
+	if rt.bridgeSrv == nil {
+		return
+	}
+	bp := rt.bridgeSrv.NewPlatform(pr.cfg.Name)
+	rt.bridgeSrv.RegisterEngine(pr.cfg.Name, pr.engine, bp)
+	pr.engine.AddPlatform(bp)
+}
+
+// registerAPI wires a project into the local API server, relay and
+// directory history.
+func (rt *appRuntime) registerAPI(pr *projectRuntime) {
+	if rt.apiSrv == nil {
+		return
+	}
+	name, e := pr.cfg.Name, pr.engine
+	rt.apiSrv.RegisterEngine(name, e)
+	e.SetRelayManager(rt.relayMgr)
+	e.SetDirHistory(rt.dirHistory)
+
+	// Ensure initial work_dir is in history
+	if pr.workDir != "" && !rt.dirHistory.Contains(name, pr.workDir) {
+		rt.dirHistory.Add(name, pr.workDir)
+	}
+}
+
+// attach registers and starts a project built after startup.
+func (rt *appRuntime) attach(pr *projectRuntime) {
+	rt.registerSchedulers(pr)
+	if err := pr.engine.Start(); err != nil {
+		slog.Warn("engine start partially failed (some platforms may be unavailable)", "project", pr.cfg.Name, "error", err)
+	}
+	rt.registerBridge(pr)
+	if rt.webhookSrv != nil {
+		rt.webhookSrv.RegisterEngine(pr.cfg.Name, pr.engine)
+	}
+	if rt.mgmtSrv != nil {
+		rt.mgmtSrv.RegisterEngine(pr.cfg.Name, pr.engine)
+	}
+	rt.registerAPI(pr)
+}
+
+// detach unregisters a project everywhere and stops its engine.
+func (rt *appRuntime) detach(pr *projectRuntime) {
+	name := pr.cfg.Name
+	if rt.cronSched != nil {
+		rt.cronSched.UnregisterEngine(name)
+	}
+	if rt.timerSched != nil {
+		rt.timerSched.UnregisterEngine(name)
+	}
+	if rt.heartbeatSched != nil {
+		rt.heartbeatSched.Unregister(name)
+	}
+	if rt.bridgeSrv != nil {
+		rt.bridgeSrv.UnregisterEngine(name)
+	}
+	if rt.webhookSrv != nil {
+		rt.webhookSrv.UnregisterEngine(name)
+	}
+	if rt.mgmtSrv != nil {
+		rt.mgmtSrv.UnregisterEngine(name)
+	}
+	if rt.apiSrv != nil {
+		rt.apiSrv.UnregisterEngine(name)
+	}
+	if err := pr.engine.Stop(); err != nil {
+		slog.Error("engine stop error", "project", name, "error", err)
+	}
+}
+
+// buildProject creates the engine for one [[projects]] entry with its agent
+// and platforms fully wired, but does not start it.
+func (rt *appRuntime) buildProject(cfg *config.Config, proj config.ProjectConfig) (*projectRuntime, error) {
+	var platforms []core.Platform
+	for _, pc := range proj.Platforms {
+		p, err := createProjectPlatform(cfg, proj, pc)
+		if err != nil {
+			return nil, err
+		}
+		platforms = append(platforms, p)
+	}
+
+	pa, err := buildProjectAgent(cfg, proj)
+	if err != nil {
+		return nil, err
+	}
+	agent, workDir, projectState, effectiveWorkDir := pa.agent, pa.workDir, pa.state, pa.effectiveWorkDir
+	sessionFile := sessionStorePath(cfg.DataDir, proj.Name, effectiveWorkDir)
+
+	// Parse language setting
+	lang, ok := core.ParseLanguage(cfg.Language)
+	if !ok {
+		if cfg.Language != "" {
+			slog.Warn("unknown language, auto-detecting instead", "language", cfg.Language)
+		}
+		lang = core.LangAuto // auto-detect
+	}
+
+	engine := core.NewEngine(proj.Name, agent, platforms, sessionFile, lang)
+	// Wire display settings including show_context_indicator and reply_footer
+	// Global [display] config can be overridden by project-level settings
+	_, _, _, _, _, showCtx, showFooter, _ := config.EffectiveDisplay(cfg, &proj)
+	engine.SetShowContextIndicator(showCtx)
+	showWorkdir := true
+	if proj.ShowWorkdirIndicator != nil {
+		showWorkdir = *proj.ShowWorkdirIndicator
+	}
+	engine.SetShowWorkdirIndicator(showWorkdir)
+	engine.SetReplyFooterEnabled(showFooter)
+	engine.SetAttachmentSendEnabled(cfg.AttachmentSend != "off")
+	engine.SetFilterExternalSessions(proj.FilterExternalSessions != nil && *proj.FilterExternalSessions)
+	engine.SetBaseWorkDir(workDir)
+	engine.SetProjectStateStore(projectState)
+	engine.SetDataDir(cfg.DataDir)
+
+	// Wire multi-workspace mode
+	if proj.Mode == "multi-workspace" {
+		baseDir := proj.BaseDir
+		if strings.HasPrefix(baseDir, "~/") {
+			home, _ := os.UserHomeDir()
+			baseDir = filepath.Join(home, baseDir[2:])
+		}
+		if err := os.MkdirAll(baseDir, 0o755); err != nil {
+			slog.Error("failed to create base_dir", "path", baseDir, "err", err)
+			return nil, errSkipProject
+		}
+		bindingStore := filepath.Join(cfg.DataDir, "workspace_bindings.json")
+		engine.SetMultiWorkspace(baseDir, bindingStore)
+		if proj.WorkspaceInitAllowLocalPaths != nil {
+			engine.SetWorkspaceInitAllowLocalPaths(*proj.WorkspaceInitAllowLocalPaths)
+		}
+		idleMins := cfg.WorkspaceIdleTimeoutMins
+		if idleMins == nil && proj.WorkspaceIdleTimeoutMinsLegacy != nil {
+			slog.Warn("workspace_idle_timeout_mins under [[projects]] is deprecated; move it to the top level of config.toml. Honoring the legacy value for backwards compatibility.",
+				"project", proj.Name, "value", *proj.WorkspaceIdleTimeoutMinsLegacy)
+			idleMins = proj.WorkspaceIdleTimeoutMinsLegacy
+		}
+		if idleMins != nil {
+			mins := *idleMins
+			if mins <= 0 {
+				engine.SetWorkspaceIdleTimeout(0)
+			} else {
+				engine.SetWorkspaceIdleTimeout(time.Duration(mins) * time.Minute)
+			}
+		}
+		if proj.SkipGit != nil {
+			engine.SetSkipGit(*proj.SkipGit)
+		}
+		slog.Info("multi-workspace mode enabled", "project", proj.Name, "base_dir", baseDir)
+	}
+
+	// Wire terminal observation (--observe / [projects.observe])
+	observeEnabled := rt.rootOpts.observe
+	obsChan := rt.rootOpts.observeChannel
+	if proj.Observe != nil {
+		if !observeEnabled && proj.Observe.Enabled {
+			observeEnabled = true
+		}
+		if obsChan == "" && proj.Observe.Channel != "" {
+			obsChan = proj.Observe.Channel
+		}
+	}
+	if observeEnabled {
+		if obsChan == "" {
+			return nil, fmt.Errorf("observe: channel is required (use --observe-channel or set channel in [projects.observe])")
+		}
+		hasSlack := false
+		for _, p := range platforms {
+			if p.Name() == "slack" {
+				hasSlack = true
+				break
+			}
+		}
+		if !hasSlack {
+			slog.Warn("observe requires a Slack platform; ignoring")
+		} else {
+			projectDir := resolveClaudeProjectDir(workDir)
+			if projectDir == "" {
+				slog.Warn("observe: could not find Claude Code project directory", "workDir", workDir)
+			} else {
+				sessionKey := fmt.Sprintf("slack:%s", obsChan)
+				engine.SetObserveConfig(projectDir, sessionKey)
+			}
+		}
+	}
+
+	// Wire global custom commands
+	for _, c := range cfg.Commands {
+		engine.AddCommand(c.Name, c.Description, c.Prompt, c.Exec, c.WorkDir, "config")
+	}
+
+	// Wire command persistence callbacks
+	engine.SetCommandSaveAddFunc(func(ctx context.Context, name, description, prompt, exec, workDir string) error {
+		return config.AddCommand(configChangeCtx(ctx), config.CommandConfig{Name: name, Description: description, Prompt: prompt, Exec: exec, WorkDir: workDir})
+	})
+	engine.SetCommandSaveDelFunc(func(ctx context.Context, name string) error {
+		return config.RemoveCommand(configChangeCtx(ctx), name)
+	})
+
+	// Wire global aliases
+	for _, a := range cfg.Aliases {
+		engine.AddAlias(a.Name, a.Command)
+	}
+	engine.SetAliasSaveAddFunc(func(ctx context.Context, name, command string) error {
+		return config.AddAlias(configChangeCtx(ctx), config.AliasConfig{Name: name, Command: command})
+	})
+	engine.SetAliasSaveDelFunc(func(ctx context.Context, name string) error {
+		return config.RemoveAlias(configChangeCtx(ctx), name)
+	})
+
+	// Wire banned words
+	if len(cfg.BannedWords) > 0 {
+		engine.SetBannedWords(cfg.BannedWords)
+	}
+
+	// Wire disabled commands (project-level)
+	if len(proj.DisabledCommands) > 0 {
+		engine.SetDisabledCommands(proj.DisabledCommands)
+	}
+
+	// Wire admin allowlist for privileged commands
+	engine.SetAdminFrom(proj.AdminFrom)
+
+	// Wire per-user role-based policies
+	if proj.Users != nil {
+		engine.SetUserRoles(buildUserRoleManager(proj.Users))
+	}
+
+	// Wire display truncation settings (includes legacy quiet → display mapping)
+	{
+		mode, tm, tool, tmlen, toollen, _, _, hideAgentFooter := config.EffectiveDisplay(cfg, &proj)
+		historyMaxLen := config.EffectiveHistoryMaxLen(cfg, &proj)
+		engine.SetDisplayConfig(core.DisplayCfg{
+			Mode:             mode,
+			CardMode:         config.EffectiveCardMode(cfg, &proj),
+			ThinkingMessages: tm,
+			ThinkingMaxLen:   tmlen,
+			ToolMaxLen:       toollen,
+			ToolMessages:     tool,
+			HistoryMaxLen:    &historyMaxLen,
+			HideAgentFooter:  hideAgentFooter,
+			PagedReplies:     config.EffectivePagedReplies(cfg, &proj),
+		})
+	}
+
+	// Wire shell configuration
+	shell, shellFlag, shellProfile := config.EffectiveShell(cfg, &proj)
+	engine.SetShell(shell, shellFlag, shellProfile)
+
+	// Wire hooks
+	if len(cfg.Hooks) > 0 {
+		coreHooks := make([]core.HookConfig, len(cfg.Hooks))
+		for i, h := range cfg.Hooks {
+			coreHooks[i] = core.HookConfig{
+				Event:   h.Event,
+				Type:    h.Type,
+				Command: h.Command,
+				URL:     h.URL,
+				Timeout: h.Timeout,
+				Async:   h.Async,
+			}
+		}
+		engine.SetHooks(core.NewHookManager(proj.Name, coreHooks, shell, shellFlag, shellProfile))
+	}
+
+	// Wire local reference normalization / rendering
+	engine.SetReferenceConfig(core.ReferenceRenderCfg{
+		NormalizeAgents: proj.References.NormalizeAgents,
+		RenderPlatforms: proj.References.RenderPlatforms,
+		DisplayPath:     proj.References.DisplayPath,
+		MarkerStyle:     proj.References.MarkerStyle,
+		EnclosureStyle:  proj.References.EnclosureStyle,
+	})
+
+	// Wire streaming preview
+	{
+		spcfg := core.DefaultStreamPreviewCfg()
+		if cfg.StreamPreview.Enabled != nil {
+			spcfg.Enabled = *cfg.StreamPreview.Enabled
+		}
+		if cfg.StreamPreview.IntervalMs != nil {
+			spcfg.IntervalMs = *cfg.StreamPreview.IntervalMs
+		}
+		if cfg.StreamPreview.MinDeltaChars != nil {
+			spcfg.MinDeltaChars = *cfg.StreamPreview.MinDeltaChars
+		}
+		if cfg.StreamPreview.MaxChars != nil {
+			spcfg.MaxChars = *cfg.StreamPreview.MaxChars
+		}
+		if cfg.StreamPreview.DisabledPlatforms != nil {
+			spcfg.DisabledPlatforms = cfg.StreamPreview.DisabledPlatforms
+		}
+		engine.SetStreamPreviewCfg(spcfg)
+	}
+
+	// Wire instant reply
+	if cfg.InstantReply.Enabled != nil && *cfg.InstantReply.Enabled {
+		engine.SetInstantReply(core.InstantReplyCfg{
+			Enabled: true,
+			Content: cfg.InstantReply.Content,
+		})
+	}
+
+	// Wire rate limiting
+	{
+		maxMsg := 20
+		windowSecs := 60
+		if cfg.RateLimit.MaxMessages != nil {
+			maxMsg = *cfg.RateLimit.MaxMessages
+		}
+		if cfg.RateLimit.WindowSecs != nil {
+			windowSecs = *cfg.RateLimit.WindowSecs
+		}
+		if maxMsg > 0 {
+			engine.SetRateLimitCfg(core.RateLimitCfg{
+				MaxMessages: maxMsg,
+				Window:      time.Duration(windowSecs) * time.Second,
+			})
+		}
+	}
+	// Wire outgoing rate limiting
+	{
+		var maxPS float64
+		if cfg.OutgoingRateLimit.MaxPerSecond != nil {
+			maxPS = *cfg.OutgoingRateLimit.MaxPerSecond
+		}
+		var burst int
+		if cfg.OutgoingRateLimit.Burst != nil {
+			burst = *cfg.OutgoingRateLimit.Burst
+		}
+		defaults := core.OutgoingRateLimitCfg{MaxPerSecond: maxPS, Burst: burst}
+		overrides := make(map[string]core.OutgoingRateLimitCfg)
+		for name, pc := range cfg.OutgoingRateLimit.Platforms {
+			var mps float64
+			if pc.MaxPerSecond != nil {
+				mps = *pc.MaxPerSecond
+			}
+			var b int
+			if pc.Burst != nil {
+				b = *pc.Burst
+			}
+			overrides[name] = core.OutgoingRateLimitCfg{MaxPerSecond: mps, Burst: b}
+		}
+		if maxPS > 0 || len(overrides) > 0 {
+			engine.SetOutgoingRateLimitCfg(defaults, overrides)
+		}
+	}
+
+	engine.SetDisplaySaveFunc(func(ctx context.Context, mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error {
+		return config.SaveDisplayConfig(configChangeCtx(ctx), mode, thinkingMessages, thinkingMaxLen, toolMaxLen, toolMessages)
+	})
+
+	// Wire idle timeout
+	if cfg.IdleTimeoutMins != nil {
+		mins := *cfg.IdleTimeoutMins
+		if mins <= 0 {
+			engine.SetEventIdleTimeout(0)
+		} else {
+			engine.SetEventIdleTimeout(time.Duration(mins) * time.Minute)
+		}
+	}
+
+	// Wire max turn time (absolute per-turn wall-clock cap; 0 = disabled)
+	if cfg.MaxTurnTimeMins != nil && *cfg.MaxTurnTimeMins > 0 {
+		engine.SetMaxTurnTime(time.Duration(*cfg.MaxTurnTimeMins) * time.Minute)
+	}
+
+	// Wire queue depth
+	if cfg.Queue.MaxDepth != nil && *cfg.Queue.MaxDepth > 0 {
+		engine.SetMaxQueuedMessages(*cfg.Queue.MaxDepth)
+	}
+
+	// Wire auto-compress settings
+	if proj.AutoCompress.Enabled != nil && *proj.AutoCompress.Enabled {
+		minGap := 30 * time.Minute
+		if proj.AutoCompress.MinGapMins != nil {
+			minGap = time.Duration(*proj.AutoCompress.MinGapMins) * time.Minute
+		}
+		maxTokens := derefInt(proj.AutoCompress.MaxTokens)
+		if maxTokens <= 0 {
+			maxTokens = 12000
+		}
+		engine.SetAutoCompressConfig(true, maxTokens, minGap)
+	}
+	engine.SetAutoAttachConfig(autoAttachToCore(proj.AutoAttach))
+	resetIdle, defaulted := resolveResetOnIdle(proj.ResetOnIdleMins)
+	engine.SetResetOnIdle(resetIdle)
+	if defaulted {
+		slog.Info("project: reset_on_idle_mins not set, applying default — set reset_on_idle_mins = 0 to opt out, see docs/usage.md",
+			"project", proj.Name, "default_minutes", defaultResetOnIdleMins)
+	}
+	if proj.AgentSessionIdleTimeoutMins != nil {
+		mins := *proj.AgentSessionIdleTimeoutMins
+		if mins <= 0 {
+			engine.SetAgentSessionIdleTimeout(0)
+		} else {
+			engine.SetAgentSessionIdleTimeout(time.Duration(mins) * time.Minute)
+		}
+	}
+
+	// Wire sender injection
+	if proj.InjectSender != nil {
+		engine.SetInjectSender(*proj.InjectSender)
+	}
+
+	// Wire speech-to-text if enabled
+	if cfg.Speech.Enabled {
+		speechCfg := core.SpeechCfg{
+			Enabled:  true,
+			Language: cfg.Speech.Language,
+			Chunk: core.SpeechChunkCfg{
+				Window:      time.Duration(cfg.Speech.ChunkSecs) * time.Second,
+				Concurrency: cfg.Speech.MaxParallel,
+			},
+		}
+		switch cfg.Speech.Provider {
+		case "groq":
+			apiKey := cfg.Speech.Groq.APIKey
+			model := cfg.Speech.Groq.Model
+			if model == "" {
+				model = "whisper-large-v3-turbo"
+			}
+			if apiKey != "" {
+				speechCfg.STT = core.NewOpenAIWhisper(apiKey, "https://api.groq.com/openai/v1", model)
+			} else {
+				slog.Warn("speech: groq provider enabled but api_key is empty")
+			}
+		case "qwen":
+			apiKey := cfg.Speech.Qwen.APIKey
+			baseURL := cfg.Speech.Qwen.BaseURL
+			model := cfg.Speech.Qwen.Model
+			if apiKey != "" {
+				speechCfg.STT = core.NewQwenASR(apiKey, baseURL, model)
+			} else {
+				slog.Warn("speech: qwen provider enabled but api_key is empty")
+			}
+		case "gemini":
+			apiKey := cfg.Speech.Gemini.APIKey
+			model := cfg.Speech.Gemini.Model
+			if apiKey != "" {
+				speechCfg.STT = core.NewGeminiSTT(apiKey, model)
+			} else {
+				slog.Warn("speech: gemini provider enabled but api_key is empty")
+			}
+		case "local":
+			local := cfg.Speech.Local
+			if local.Model != "" {
+				timeout := time.Duration(local.TimeoutSecs) * time.Second
+				speechCfg.STT = core.NewLocalWhisper(local.Engine, local.Path, local.Model, local.Threads, timeout)
+			} else {
+				slog.Warn("speech: local provider enabled but model is empty")
+			}
+		default: // "openai" or unspecified
+			apiKey := cfg.Speech.OpenAI.APIKey
+			baseURL := cfg.Speech.OpenAI.BaseURL
+			model := cfg.Speech.OpenAI.Model
+			if apiKey != "" {
+				speechCfg.STT = core.NewOpenAIWhisper(apiKey, baseURL, model)
+			} else {
+				slog.Warn("speech: openai provider enabled but api_key is empty")
+			}
+		}
+		if speechCfg.STT != nil {
+			engine.SetSpeechConfig(speechCfg)
+			slog.Info("speech: enabled", "provider", cfg.Speech.Provider)
+		}
+	}
+
+	// Wire local OCR for agents that cannot read images
+	if cfg.OCR.Enabled {
+		engine.SetOCRConfig(core.OCRCfg{
+			Enabled:  true,
+			Provider: "tesseract",
+			OCR:      core.NewTesseractOCR(cfg.OCR.Tesseract.Path, cfg.OCR.Tesseract.Languages),
+		})
+		slog.Info("ocr: enabled", "provider", "tesseract", "languages", cfg.OCR.Tesseract.Languages)
+	}
+
+	// Wire text-to-speech if enabled
+	ttsEffective := config.ResolveTTSConfigForProject(cfg.TTS, proj.Name)
+	if ttsEffective.Enabled {
+		ttsCfg := &core.TTSCfg{
+			Enabled:      true,
+			Voice:        ttsEffective.Voice,
+			LanguageType: ttsEffective.LanguageType,
+			Speed:        ttsEffective.Speed,
+			MaxTextLen:   ttsEffective.MaxTextLen,
+			Streaming:    cfg.TTS.Streaming,
+		}
+		if cfg.TTS.Cache.Enabled {
+			dir := cfg.TTS.Cache.Dir
+			if dir == "" {
+				dir = filepath.Join(cfg.DataDir, "tts-cache")
+			}
+			ttsCfg.Cache = rt.sharedTTSCache(dir, int64(cfg.TTS.Cache.MaxSizeMB)<<20)
+		}
+		initMode := ttsEffective.TTSMode
+		switch initMode {
+		case "always", "voice_only":
+		case "":
+			initMode = "voice_only"
+		default:
+			slog.Warn("tts: invalid tts_mode in config, falling back to voice_only", "tts_mode", initMode)
+			initMode = "voice_only"
+		}
+		ttsCfg.SetTTSMode(initMode)
+		switch ttsEffective.Provider {
+		case "qwen":
+			apiKey := cfg.TTS.Qwen.APIKey
+			baseURL := cfg.TTS.Qwen.BaseURL
+			model := cfg.TTS.Qwen.Model
+			if apiKey != "" {
+				ttsCfg.TTS = core.NewQwenTTS(apiKey, baseURL, model, nil)
+				ttsCfg.Provider = "qwen"
+			} else {
+				slog.Warn("tts: qwen provider enabled but api_key is empty")
+			}
+		case "minimax":
+			apiKey := cfg.TTS.MiniMax.APIKey
+			baseURL := cfg.TTS.MiniMax.BaseURL
+			model := cfg.TTS.MiniMax.Model
+			if apiKey == "" {
+				localCfg, err := config.LoadMiniMaxLocalConfig(cfg.DataDir, cfg.TTS.MiniMax.ConfigFile)
+				if err != nil {
+					slog.Warn("tts: failed to load minimax local config", "error", err)
+				} else {
+					apiKey = localCfg.APIKey
+					if baseURL == "" {
+						if localCfg.BaseURL != "" {
+							baseURL = localCfg.BaseURL
+						} else if localCfg.APIHost != "" {
+							baseURL = localCfg.APIHost
+						}
+					}
+				}
+			}
+			if apiKey != "" {
+				ttsCfg.TTS = core.NewMiniMaxTTS(apiKey, baseURL, model, nil)
+				ttsCfg.Provider = "minimax"
+			} else {
+				slog.Warn("tts: minimax provider enabled but api_key is empty")
+			}
+		case "mimo":
+			apiKey := cfg.TTS.Mimo.APIKey
+			baseURL := cfg.TTS.Mimo.BaseURL
+			model := cfg.TTS.Mimo.Model
+			if apiKey != "" {
+				ttsCfg.TTS = core.NewMimoTTS(apiKey, baseURL, model, nil)
+				ttsCfg.Provider = "mimo"
+			} else {
+				slog.Warn("tts: mimo provider enabled but api_key is empty")
+			}
+		case "espeak":
+			voice := ttsEffective.Voice
+			if voice == "" {
+				voice = "zh" // default to Chinese
+			}
+			ttsCfg.TTS = core.NewEspeakTTS("", voice)
+			ttsCfg.Provider = "espeak"
+		case "pico":
+			voice := ttsEffective.Voice
+			if voice == "" {
+				voice = "zh-CN" // default to Chinese (Simplified)
+			}
+			ttsCfg.TTS = core.NewPicoTTS("", voice)
+			ttsCfg.Provider = "pico"
+		case "edge":
+			voice := ttsEffective.Voice
+			if voice == "" {
+				voice = "zh-CN-XiaoxiaoNeural" // default Chinese neural voice
+			}
+			ttsCfg.TTS = core.NewEdgeTTS(voice)
+			ttsCfg.Provider = "edge"
+		default: // "openai" or unspecified
+			apiKey := cfg.TTS.OpenAI.APIKey
+			baseURL := cfg.TTS.OpenAI.BaseURL
+			model := cfg.TTS.OpenAI.Model
+			if apiKey != "" {
+				ttsCfg.TTS = core.NewOpenAITTS(apiKey, baseURL, model, nil)
+				ttsCfg.Provider = "openai"
+			} else {
+				slog.Warn("tts: openai provider enabled but api_key is empty")
+			}
+		}
+		if ttsCfg.TTS != nil {
+			engine.SetTTSConfig(ttsCfg)
+			engine.SetTTSSaveFunc(func(ctx context.Context, mode string) error {
+				return config.SaveTTSMode(configChangeCtx(ctx), mode)
+			})
+			slog.Info("tts: enabled", "provider", ttsCfg.Provider, "voice", ttsCfg.Voice, "mode", initMode, "streaming", ttsCfg.Streaming, "cache", ttsCfg.Cache != nil)
+		}
+	}
+
+	// Set up save callback for auto-detected language
+	if lang == core.LangAuto {
+		engine.SetLanguageSaveFunc(func(l core.Language) error {
+			return config.SaveLanguage(context.Background(), string(l))
+		})
+	}
+
+	engine.SetProviderFailover(providerFailoverChain(proj), providerFailoverCooldown(proj))
+	engine.SetProviderHealthCheck(providerHealthCfg(cfg.ProviderHealth))
+
+	// Set up save callbacks for provider management
+	projName := proj.Name
+	engine.SetProviderSaveFunc(func(ctx context.Context, providerName string) error {
+		return config.SaveActiveProvider(configChangeCtx(ctx), projName, providerName)
+	})
+	engine.SetProviderAddSaveFunc(func(ctx context.Context, p core.ProviderConfig) error {
+		cp := config.ProviderConfig{
+			Name: p.Name, APIKey: p.APIKey, BaseURL: p.BaseURL,
+			Model: p.Model, Models: convertCoreModels(p.Models), Thinking: p.Thinking, Env: p.Env,
+		}
+		if p.CodexWireAPI != "" || len(p.CodexHTTPHeaders) > 0 {
+			cp.Codex = &config.CodexProviderConfig{
+				WireAPI: p.CodexWireAPI, HTTPHeaders: p.CodexHTTPHeaders,
+			}
+		}
+		return config.AddProviderToConfig(configChangeCtx(ctx), projName, cp)
+	})
+	engine.SetProviderRemoveSaveFunc(func(ctx context.Context, name string) error {
+		return config.RemoveProviderFromConfig(configChangeCtx(ctx), projName, name)
+	})
+	engine.SetProviderModelSaveFunc(func(ctx context.Context, providerName, model string) error {
+		return config.SaveProviderModel(configChangeCtx(ctx), projName, providerName, model)
+	})
+	engine.SetProviderRefsSaveFunc(func(ctx context.Context, refs []string) error {
+		return config.SaveProviderRefs(configChangeCtx(ctx), projName, refs)
+	})
+	engine.SetListGlobalProvidersFunc(func(agentType string) ([]core.ProviderConfig, error) {
+		globals, err := config.ListGlobalProviders()
+		if err != nil {
+			return nil, err
+		}
+		var result []core.ProviderConfig
+		for _, g := range globals {
+			if len(g.AgentTypes) > 0 && !containsString(g.AgentTypes, agentType) {
+				continue
+			}
+			result = append(result, configProviderToCore(g.ResolveForAgent(agentType)))
+		}
+		return result, nil
+	})
+	engine.SetModelSaveFunc(func(ctx context.Context, model string) error {
+		return config.SaveAgentModel(configChangeCtx(ctx), projName, model)
+	})
+
+	// Wire config reload: /reload re-applies the whole config file, not
+	// just this project.
+	engine.SetConfigReloadFunc(rt.Reload)
+	engine.SetConfigHistoryFuncs(configHistoryForCore, redactedConfigDiffForCore, rollbackConfigForCore)
+
+	// Wire /web command callbacks
+	engine.SetWebSetupFunc(func(ctx context.Context) (int, string, bool, error) {
+		mgmtToken := core.GenerateToken(16)
+		bridgeToken := core.GenerateToken(16)
+		result, err := config.EnableWebAdmin(configChangeCtx(ctx), mgmtToken, bridgeToken)
+		if err != nil {
+			return 0, "", false, err
+		}
+		return result.ManagementPort, result.ManagementToken, !result.AlreadyEnabled, nil
+	})
+	engine.SetWebStatusFunc(func() string {
+		if cfg.Management.Enabled == nil || !*cfg.Management.Enabled {
+			return ""
+		}
+		port := cfg.Management.Port
+		if port == 0 {
+			port = 9820
+		}
+		return fmt.Sprintf("http://localhost:%d", port)
+	})
+
+	return &projectRuntime{
+		cfg:       proj,
+		engine:    engine,
+		agent:     agent,
+		platforms: platforms,
+		workDir:   effectiveWorkDir,
+	}, nil
+}
+
+// projectAgent is an agent created for a project, plus the work dirs and
+// state store derived while creating it.
+type projectAgent struct {
+	agent            core.Agent
+	workDir          string // configured work_dir
+	effectiveWorkDir string // work_dir after project state overrides
+	state            *core.ProjectStateStore
+}
+
+func buildProjectAgent(cfg *config.Config, proj config.ProjectConfig) (*projectAgent, error) {
+	agent, err := core.CreateAgent(proj.Agent.Type, projectAgentOptions(cfg.DataDir, proj))
+	if err != nil {
+		return nil, fmt.Errorf("create agent: %w", err)
+	}
+	providerWiring := wireAgentProviders(agent, proj.Agent)
+
+	workDir, _ := proj.Agent.Options["work_dir"].(string)
+	projectState := core.NewProjectStateStore(projectStatePath(cfg.DataDir, proj.Name))
+	effectiveWorkDir := applyProjectStateOverride(proj.Name, agent, workDir, projectState)
+	startInitialRefreshIfReady(agent, providerWiring)
+	return &projectAgent{
+		agent:            agent,
+		workDir:          workDir,
+		effectiveWorkDir: effectiveWorkDir,
+		state:            projectState,
+	}, nil
+}
+
+// projectAgentOptions builds the agent options for a project. Project-level
+// run_as_user / run_as_env are injected so agents that support isolation can
+// pick them up without needing their own top-level config plumbing.
+func projectAgentOptions(dataDir string, proj config.ProjectConfig) map[string]any {
+	opts := buildAgentOptions(dataDir, proj)
+	if proj.RunAsUser != "" {
+		opts["run_as_user"] = proj.RunAsUser
+		if len(proj.RunAsEnv) > 0 {
+			opts["run_as_env"] = proj.RunAsEnv
+		}
+	}
+	return opts
+}
+
+func createProjectPlatform(cfg *config.Config, proj config.ProjectConfig, pc config.PlatformConfig) (core.Platform, error) {
+	opts := make(map[string]any, len(pc.Options)+2)
+	for k, v := range pc.Options {
+		opts[k] = v
+	}
+	opts["cc_data_dir"] = cfg.DataDir
+	opts["cc_project"] = proj.Name
+	p, err := core.CreatePlatform(pc.Type, opts)
+	if err != nil {
+		return nil, fmt.Errorf("create platform %s: %w", pc.Type, err)
+	}
+	return p, nil
+}
+
+// Reload re-reads the config file and brings the running projects in line
+// with it. New projects are started and removed ones stopped; for the rest
+// only the platforms whose settings changed are restarted, and a changed
+// agent configuration swaps in a new agent that serves new sessions.
+// Projects whose agent type, workspace mode or run_as settings changed are
+// rebuilt. Unaffected projects keep running untouched.
+func (rt *appRuntime) Reload() (*core.ConfigReloadResult, error) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	cfg, err := config.Load(rt.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("reload config: %w", err)
+	}
+	if err := runRunAsUserStartupChecks(context.Background(), runAsChecksFor(rt.projects, cfg)); err != nil {
+		return nil, fmt.Errorf("reload config: %w", err)
+	}
+
+	result := &core.ConfigReloadResult{}
+	core.SetRedactedSecrets(cfg.SecretValues())
+	core.SetAttachmentConversion(attachmentConvertCfg(cfg.AttachmentConvert))
+	if err := core.LoadI18nOverrides(core.I18nOverrideDir(cfg.DataDir)); err != nil {
+		slog.Warn("i18n: some message overrides were skipped", "error", err)
+	}
+
+	// Re-apply process-global hot-reloadable settings.
+	if globalAPIServer != nil {
+		globalAPIServer.SetMaxAttachmentSize(resolveMaxAttachmentSize(cfg))
+	}
+
+	current := make(map[string]*projectRuntime, len(rt.projects))
+	for _, pr := range rt.projects {
+		current[pr.cfg.Name] = pr
+	}
+	wanted := make(map[string]bool, len(cfg.Projects))
+	for _, proj := range cfg.Projects {
+		wanted[proj.Name] = true
+	}
+	for _, pr := range rt.projects {
+		if !wanted[pr.cfg.Name] {
+			rt.detach(pr)
+			result.ProjectsRemoved = append(result.ProjectsRemoved, pr.cfg.Name)
+		}
+	}
+
+	next := make([]*projectRuntime, 0, len(cfg.Projects))
+	for _, proj := range cfg.Projects {
+		pr, ok := current[proj.Name]
+		if ok && !projectNeedsRebuild(rt.cfg, pr.cfg, cfg, proj) {
+			rt.reconcileProject(cfg, pr, proj, result)
+			next = append(next, pr)
+			continue
+		}
+
+		npr, err := rt.buildProject(cfg, proj)
+		if err != nil {
+			// Keep a project that failed to rebuild running on its old config.
+			slog.Error("reload: failed to build project", "project", proj.Name, "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", proj.Name, err))
+			if ok {
+				next = append(next, pr)
+			}
+			continue
+		}
+		if ok {
+			rt.detach(pr)
+			result.ProjectsRebuilt = append(result.ProjectsRebuilt, proj.Name)
+		} else {
+			result.ProjectsAdded = append(result.ProjectsAdded, proj.Name)
+		}
+		rt.attach(npr)
+		next = append(next, npr)
+	}
+
+	result.RestartRequired = restartRequiredSettings(rt.cfg, cfg)
+	autoReloadChanged := !reflect.DeepEqual(rt.cfg.AutoReload, cfg.AutoReload)
+	rt.cfg = cfg
+	rt.projects = next
+	if autoReloadChanged {
+		if cfg.AutoReload != nil && *cfg.AutoReload {
+			rt.startWatcher()
+		} else {
+			rt.stopWatcher()
+		}
+	}
+
+	slog.Info("config reloaded",
+		"added", result.ProjectsAdded,
+		"removed", result.ProjectsRemoved,
+		"rebuilt", result.ProjectsRebuilt,
+		"platforms_started", result.PlatformsStarted,
+		"platforms_stopped", result.PlatformsStopped,
+		"agents_swapped", result.AgentsSwapped,
+		"restart_required", result.RestartRequired,
+	)
+	return result, nil
+}
+
+// reconcileProject applies a changed config to a project that keeps its
+// engine: platforms and the agent are replaced only where their settings
+// differ, everything else is re-applied in place.
+func (rt *appRuntime) reconcileProject(cfg *config.Config, pr *projectRuntime, proj config.ProjectConfig, result *core.ConfigReloadResult) {
+	rt.reconcilePlatforms(cfg, pr, proj, result)
+
+	workDirBefore := pr.workDir
+	if !reflect.DeepEqual(agentFingerprint(rt.cfg.DataDir, pr.cfg), agentFingerprint(cfg.DataDir, proj)) {
+		pa, err := buildProjectAgent(cfg, proj)
+		if err != nil {
+			slog.Error("reload: failed to create agent", "project", proj.Name, "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", proj.Name, err))
+		} else {
+			// Sessions already running keep the old agent until they end.
+			pr.engine.RetireAgent(pr.engine.SwapAgent(pa.agent))
+			pr.agent = pa.agent
+			pr.workDir = pa.effectiveWorkDir
+			pr.engine.SetBaseWorkDir(pa.workDir)
+			result.AgentsSwapped = append(result.AgentsSwapped, proj.Name)
+		}
+	}
+
+	if rt.heartbeatSched != nil && (pr.workDir != workDirBefore ||
+		!reflect.DeepEqual(pr.cfg.AllHeartbeats(), proj.AllHeartbeats())) {
+		rt.heartbeatSched.Unregister(proj.Name)
+		pr.cfg.Heartbeat, pr.cfg.Heartbeats = proj.Heartbeat, proj.Heartbeats
+		rt.registerHeartbeats(pr)
+	}
+
+	applyEngineSettings(cfg, &proj, pr.engine, result)
+	pr.cfg = proj
+}
+
+// reconcilePlatforms keeps every platform whose type and options are
+// unchanged, stops the ones that disappeared or changed, and starts the
+// new ones. Old platforms are stopped first so a changed entry can reuse
+// the same bot credentials.
+func (rt *appRuntime) reconcilePlatforms(cfg *config.Config, pr *projectRuntime, proj config.ProjectConfig, result *core.ConfigReloadResult) {
+	next := make([]core.Platform, len(proj.Platforms))
+	kept := make([]bool, len(pr.platforms))
+	for i, pc := range proj.Platforms {
+		for j, old := range pr.cfg.Platforms {
+			if j < len(pr.platforms) && !kept[j] && pr.platforms[j] != nil && reflect.DeepEqual(old, pc) {
+				kept[j] = true
+				next[i] = pr.platforms[j]
+				break
+			}
+		}
+	}
+
+	for j, p := range pr.platforms {
+		if kept[j] || p == nil {
+			continue
+		}
+		if err := pr.engine.StopPlatform(p); err != nil {
+			slog.Warn("reload: platform stop error", "project", proj.Name, "platform", p.Name(), "error", err)
+		}
+		result.PlatformsStopped = append(result.PlatformsStopped, proj.Name+"/"+p.Name())
+	}
+
+	for i, pc := range proj.Platforms {
+		if next[i] != nil {
+			continue
+		}
+		p, err := createProjectPlatform(cfg, proj, pc)
+		if err != nil {
+			slog.Error("reload: failed to create platform", "project", proj.Name, "type", pc.Type, "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", proj.Name, err))
+			continue
+		}
+		if err := pr.engine.StartPlatform(p); err != nil {
+			slog.Error("reload: failed to start platform", "project", proj.Name, "platform", p.Name(), "error", err)
+			result.Errors = append(result.Errors, fmt.Sprintf("%s/%s: %v", proj.Name, p.Name(), err))
+		}
+		next[i] = p
+		result.PlatformsStarted = append(result.PlatformsStarted, proj.Name+"/"+p.Name())
+	}
+	pr.platforms = next
+}
+
+// agentFingerprint is what decides whether a reload needs a new agent. The
+// active provider and provider list are switched in place by
+// applyEngineSettings and are left out.
+func agentFingerprint(dataDir string, proj config.ProjectConfig) map[string]any {
+	opts := projectAgentOptions(dataDir, proj)
+	delete(opts, "provider")
+	return opts
+}
+
+// projectNeedsRebuild reports whether a project changed in a way that
+// cannot be applied to its running engine.
+func projectNeedsRebuild(oldCfg *config.Config, old config.ProjectConfig, newCfg *config.Config, proj config.ProjectConfig) bool {
+	if old.Agent.Type != proj.Agent.Type ||
+		old.Mode != proj.Mode ||
+		old.BaseDir != proj.BaseDir ||
+		old.RunAsUser != proj.RunAsUser ||
+		!reflect.DeepEqual(old.RunAsEnv, proj.RunAsEnv) ||
+		!reflect.DeepEqual(old.Observe, proj.Observe) ||
+		!reflect.DeepEqual(old.SkipGit, proj.SkipGit) ||
+		!reflect.DeepEqual(old.WorkspaceInitAllowLocalPaths, proj.WorkspaceInitAllowLocalPaths) ||
+		!reflect.DeepEqual(old.WorkspaceIdleTimeoutMinsLegacy, proj.WorkspaceIdleTimeoutMinsLegacy) {
+		return true
+	}
+	// Multi-workspace idle timeout falls back to the top-level setting.
+	return proj.Mode == "multi-workspace" &&
+		!reflect.DeepEqual(oldCfg.WorkspaceIdleTimeoutMins, newCfg.WorkspaceIdleTimeoutMins)
+}
+
+// hotReloadSettings are the top-level config keys a reload applies without
+// a restart. Every other top-level change is reported as RestartRequired.
+var hotReloadSettings = map[string]bool{
+	"Projects":            true,
+	"Commands":            true,
+	"Aliases":             true,
+	"BannedWords":         true,
+	"Display":             true,
+	"InstantReply":        true,
+	"AttachmentSend":      true,
+	"MaxAttachmentSizeMB": true,
+	"AttachmentConvert":   true,
+	"Quiet":               true,
+	"Providers":           true,
+	"ProviderPresetsURL":  true,
+	"AutoReload":          true,
+}
+
+// restartRequiredSettings lists the top-level settings (by TOML key) that
+// changed between two configs but only take effect after a restart.
+func restartRequiredSettings(oldCfg, newCfg *config.Config) []string {
+	if oldCfg == nil || newCfg == nil {
+		return nil
+	}
+	var changed []string
+	ov, nv := reflect.ValueOf(oldCfg).Elem(), reflect.ValueOf(newCfg).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || hotReloadSettings[f.Name] {
+			continue
+		}
+		if reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("toml"), ",")[0]
+		if key == "" || key == "-" {
+			key = f.Name
+		}
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// runAsChecksFor returns a copy of cfg limited to the projects whose
+// run_as settings are new or changed, so a reload only re-runs the
+// run_as_user checks it has to.
+func runAsChecksFor(running []*projectRuntime, cfg *config.Config) *config.Config {
+	prev := make(map[string]config.ProjectConfig, len(running))
+	for _, pr := range running {
+		prev[pr.cfg.Name] = pr.cfg
+	}
+	checkCfg := *cfg
+	checkCfg.Projects = nil
+	for _, proj := range cfg.Projects {
+		if proj.RunAsUser == "" {
+			continue
+		}
+		old, ok := prev[proj.Name]
+		if ok && old.RunAsUser == proj.RunAsUser && reflect.DeepEqual(old.Agent.Options["work_dir"], proj.Agent.Options["work_dir"]) {
+			continue
+		}
+		checkCfg.Projects = append(checkCfg.Projects, proj)
+	}
+	return &checkCfg
+}
+
+// startWatcher polls the config file and reloads when it changes. Editors
+// often write a file in several steps, so a change is only acted on once
+// the file has been stable for one poll interval.
+func (rt *appRuntime) startWatcher() {
+	rt.watchMu.Lock()
+	defer rt.watchMu.Unlock()
+	if rt.watchStop != nil || rt.configPath == "" {
+		return
+	}
+	stop := make(chan struct{})
+	rt.watchStop = stop
+	go rt.watchConfig(stop)
+	slog.Info("config: auto reload enabled", "path", rt.configPath)
+}
+
+// stopWatcher stops the auto_reload watcher if it is running.
+func (rt *appRuntime) stopWatcher() {
+	rt.watchMu.Lock()
+	defer rt.watchMu.Unlock()
+	if rt.watchStop == nil {
+		return
+	}
+	close(rt.watchStop)
+	rt.watchStop = nil
+	slog.Info("config: auto reload disabled")
+}
+
+func (rt *appRuntime) watchConfig(stop <-chan struct{}) {
+	last := configFileStamp(rt.configPath)
+	pending := false
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		stamp := configFileStamp(rt.configPath)
+		if stamp != last {
+			last = stamp
+			pending = true
+			continue
+		}
+		if !pending || stamp == "" {
+			continue
+		}
+		pending = false
+		if _, err := rt.Reload(); err != nil {
+			slog.Error("config: auto reload failed", "error", err)
+		}
+	}
+}
+
+// configFileStamp identifies the current version of the config by the size
+// and modification time of the main file and every file it includes; it is
+// empty when the main file cannot be read.
+func configFileStamp(path string) string {
+	fi, err := os.Stat(filepath.Clean(path))
+	if err != nil {
+		return ""
+	}
+	stamp := fmt.Sprintf("%d/%d", fi.Size(), fi.ModTime().UnixNano())
+	included, _ := config.IncludedConfigFiles(path)
+	for _, file := range included {
+		if fi, err := os.Stat(file); err == nil {
+			stamp += fmt.Sprintf(";%s:%d/%d", file, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return stamp
+}
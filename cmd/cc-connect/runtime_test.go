@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/chenhg5/cc-connect/config"
+	"github.com/chenhg5/cc-connect/core"
+)
+
+type reloadStubPlatform struct {
+	id      string
+	stopped bool
+}
+
+func (p *reloadStubPlatform) Name() string                             { return "reload-stub" }
+func (p *reloadStubPlatform) Start(core.MessageHandler) error          { return nil }
+func (p *reloadStubPlatform) Reply(context.Context, any, string) error { return nil }
+func (p *reloadStubPlatform) Send(context.Context, any, string) error  { return nil }
+func (p *reloadStubPlatform) Stop() error                              { p.stopped = true; return nil }
+
+func init() {
+	core.RegisterPlatform("reload-stub", func(opts map[string]any) (core.Platform, error) {
+		id, _ := opts["id"].(string)
+		return &reloadStubPlatform{id: id}, nil
+	})
+}
+
+func TestReconcilePlatforms_RestartsOnlyChangedPlatforms(t *testing.T) {
+	cfg := &config.Config{DataDir: t.TempDir()}
+	oldProj := config.ProjectConfig{Name: "p", Platforms: []config.PlatformConfig{
+		{Type: "reload-stub", Options: map[string]any{"id": "keep"}},
+		{Type: "reload-stub", Options: map[string]any{"id": "change"}},
+		{Type: "reload-stub", Options: map[string]any{"id": "drop"}},
+	}}
+	var platforms []core.Platform
+	for _, pc := range oldProj.Platforms {
+		p, err := createProjectPlatform(cfg, oldProj, pc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		platforms = append(platforms, p)
+	}
+	engine := core.NewEngine("p", &stubMainAgent{}, platforms, "", core.LangEnglish)
+	pr := &projectRuntime{cfg: oldProj, engine: engine, platforms: platforms}
+	rt := newAppRuntime("", rootCLIOptions{}, cfg)
+
+	newProj := oldProj
+	newProj.Platforms = []config.PlatformConfig{
+		{Type: "reload-stub", Options: map[string]any{"id": "changed"}},
+		{Type: "reload-stub", Options: map[string]any{"id": "keep"}},
+		{Type: "reload-stub", Options: map[string]any{"id": "new"}},
+	}
+	result := &core.ConfigReloadResult{}
+	rt.reconcilePlatforms(cfg, pr, newProj, result)
+
+	if pr.platforms[1] != platforms[0] {
+		t.Error("unchanged platform should be kept, even when moved")
+	}
+	if platforms[0].(*reloadStubPlatform).stopped {
+		t.Error("unchanged platform must not be stopped")
+	}
+	if !platforms[1].(*reloadStubPlatform).stopped || !platforms[2].(*reloadStubPlatform).stopped {
+		t.Error("changed and removed platforms should be stopped")
+	}
+	if got := pr.platforms[0].(*reloadStubPlatform).id; got != "changed" {
+		t.Errorf("platforms[0] id = %q, want changed", got)
+	}
+	if len(result.PlatformsStopped) != 2 || len(result.PlatformsStarted) != 2 {
+		t.Errorf("stopped=%v started=%v", result.PlatformsStopped, result.PlatformsStarted)
+	}
+}
+
+func TestProjectNeedsRebuild(t *testing.T) {
+	base := config.ProjectConfig{Name: "p", Agent: config.AgentConfig{Type: "claudecode", Options: map[string]any{"work_dir": "/a"}}}
+	cfg := &config.Config{}
+
+	same := base
+	same.AdminFrom = "*"
+	same.Agent.Options = map[string]any{"work_dir": "/b"}
+	if projectNeedsRebuild(cfg, base, cfg, same) {
+		t.Error("agent options and access rules should not need a rebuild")
+	}
+
+	changedType := base
+	changedType.Agent.Type = "codex"
+	if !projectNeedsRebuild(cfg, base, cfg, changedType) {
+		t.Error("a new agent type should need a rebuild")
+	}
+
+	runAs := base
+	runAs.RunAsUser = "bot"
+	if !projectNeedsRebuild(cfg, base, cfg, runAs) {
+		t.Error("run_as_user changes should need a rebuild")
+	}
+
+	mins := 10
+	multi := base
+	multi.Mode = "multi-workspace"
+	if !projectNeedsRebuild(cfg, multi, &config.Config{WorkspaceIdleTimeoutMins: &mins}, multi) {
+		t.Error("top-level workspace idle timeout should rebuild multi-workspace projects")
+	}
+	if projectNeedsRebuild(cfg, base, &config.Config{WorkspaceIdleTimeoutMins: &mins}, base) {
+		t.Error("top-level workspace idle timeout should not affect single-workspace projects")
+	}
+}
+
+func TestAgentFingerprint_IgnoresActiveProvider(t *testing.T) {
+	a := config.ProjectConfig{Name: "p", Agent: config.AgentConfig{Options: map[string]any{"model": "x", "provider": "one"}}}
+	b := config.ProjectConfig{Name: "p", Agent: config.AgentConfig{Options: map[string]any{"model": "x", "provider": "two"}}}
+	if !reflect.DeepEqual(agentFingerprint("/d", a), agentFingerprint("/d", b)) {
+		t.Error("switching the active provider should not replace the agent")
+	}
+	b.Agent.Options["model"] = "y"
+	if reflect.DeepEqual(agentFingerprint("/d", a), agentFingerprint("/d", b)) {
+		t.Error("a changed model should replace the agent")
+	}
+}
+
+func TestRestartRequiredSettings(t *testing.T) {
+	on := true
+	oldCfg := &config.Config{DataDir: "/d", Language: "en"}
+	newCfg := &config.Config{DataDir: "/d", Language: "zh", AutoReload: &on, BannedWords: []string{"x"}}
+	newCfg.Management.Port = 9999
+	newCfg.Projects = []config.ProjectConfig{{Name: "p"}}
+
+	got := restartRequiredSettings(oldCfg, newCfg)
+	want := []string{"language", "management"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("restartRequiredSettings = %v, want %v", got, want)
+	}
+}
+
+func TestRunAsChecksFor_OnlyNewOrChangedProjects(t *testing.T) {
+	wd := map[string]any{"work_dir": "/w"}
+	running := []*projectRuntime{{cfg: config.ProjectConfig{Name: "same", RunAsUser: "u", Agent: config.AgentConfig{Options: wd}}}}
+	cfg := &config.Config{Projects: []config.ProjectConfig{
+		{Name: "same", RunAsUser: "u", Agent: config.AgentConfig{Options: wd}},
+		{Name: "added", RunAsUser: "u2"},
+		{Name: "plain"},
+	}}
+	got := runAsChecksFor(running, cfg)
+	if len(got.Projects) != 1 || got.Projects[0].Name != "added" {
+		t.Errorf("checked projects = %+v, want only added", got.Projects)
+	}
+	if len(cfg.Projects) != 3 {
+		t.Error("runAsChecksFor must not modify the loaded config")
+	}
+}
+
+func TestSharedTTSCache_OneInstancePerDir(t *testing.T) {
+	rt := newAppRuntime("", rootCLIOptions{}, &config.Config{})
+	dir := t.TempDir()
+	a := rt.sharedTTSCache(dir, 0)
+	if b := rt.sharedTTSCache(dir, 0); b != a {
+		t.Fatal("second project got its own cache for the same directory")
+	}
+	if c := rt.sharedTTSCache(dir, 1<<20); c == a || c.MaxBytes != 1<<20 {
+		t.Fatal("changed size limit reused the old cache")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chenhg5/cc-connect/config"
+)
+
+func runSecrets(args []string) {
+	if len(args) == 0 {
+		printSecretsUsage()
+		return
+	}
+
+	sub := args[0]
+	var name, value, dataDir, configPath string
+	var positional []string
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case "--data-dir":
+			if i+1 < len(rest) {
+				i++
+				dataDir = rest[i]
+			}
+		case "--config":
+			if i+1 < len(rest) {
+				i++
+				configPath = rest[i]
+			}
+		case "--help", "-h":
+			printSecretsUsage()
+			return
+		default:
+			positional = append(positional, rest[i])
+		}
+	}
+	if len(positional) > 0 {
+		name = positional[0]
+	}
+	if len(positional) > 1 {
+		value = positional[1]
+	}
+	if dataDir == "" {
+		dataDir = config.DataDirFromConfig(resolveConfigPath(configPath))
+	}
+	store := config.OpenSecretStore(dataDir)
+
+	switch sub {
+	case "set":
+		if name == "" {
+			fmt.Fprintln(os.Stderr, "Error: secret name is required")
+			printSecretsUsage()
+			os.Exit(1)
+		}
+		if len(positional) < 2 {
+			// Reading from stdin keeps the value out of shell history.
+			v, err := readSecretValue(os.Stdin)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			value = v
+		}
+		if err := store.Set(name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Secret %q saved. Reference it in config.toml as \"secret:%s\".\n", name, name)
+	case "get":
+		if name == "" {
+			fmt.Fprintln(os.Stderr, "Error: secret name is required")
+			printSecretsUsage()
+			os.Exit(1)
+		}
+		v, err := store.Get(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(v)
+	case "list", "ls":
+		names, err := store.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(names) == 0 {
+			fmt.Printf("No secrets stored in %s\n", store.Path())
+			return
+		}
+		for _, n := range names {
+			fmt.Println(n)
+		}
+	case "delete", "del", "rm", "remove":
+		if name == "" {
+			fmt.Fprintln(os.Stderr, "Error: secret name is required")
+			printSecretsUsage()
+			os.Exit(1)
+		}
+		if err := store.Delete(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Secret %q deleted.\n", name)
+	case "help":
+		printSecretsUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown secrets subcommand: %s\n", sub)
+		printSecretsUsage()
+		os.Exit(1)
+	}
+}
+
+// readSecretValue reads a secret from r: the first line when r is a
+// terminal-style stream, without its line ending.
+func readSecretValue(r io.Reader) (string, error) {
+	if f, ok := r.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, "Value: ")
+		}
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read value: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty value")
+	}
+	return line, nil
+}
+
+func printSecretsUsage() {
+	fmt.Fprintf(os.Stderr, `Usage: cc-connect secrets <subcommand> [options]
+
+Manage the encrypted secrets file referenced from config.toml as
+"secret:<name>" (e.g. token = "secret:slack_bot").
+
+Subcommands:
+  set <name> [value]   Store a secret (reads the value from stdin when omitted)
+  get <name>           Print a secret
+  list                 List secret names (alias: ls)
+  delete <name>        Remove a secret (aliases: del, rm)
+
+Options:
+  --config <path>      Config file whose data_dir holds the secrets (default: auto-detect)
+  --data-dir <path>    Data directory holding secrets.json and secrets.key
+
+The key is kept in <data_dir>/secrets.key; set CC_SECRETS_KEY_FILE to keep it
+elsewhere. Other reference forms: "file:/path" and "cmd:<shell command>".
+
+Examples:
+  cc-connect secrets set slack_bot
+  echo "$TOKEN" | cc-connect secrets set telegram_token
+  cc-connect secrets list
+`)
+}
@@ -38,7 +38,7 @@ func runTimer(args []string) {
 }
 
 func runTimerAdd(args []string) {
-	var project, sessionKey, delay, atTime, prompt, execCmd, desc, dataDir, sessionMode string
+	var project, sessionKey, delay, atTime, prompt, execCmd, desc, dataDir, sessionMode, precheck string
 	var timeoutMins *int
 	var mute bool
 
@@ -80,6 +80,11 @@ func runTimerAdd(args []string) {
 				i++
 				desc = args[i]
 			}
+		case "--precheck":
+			if i+1 < len(args) {
+				i++
+				precheck = args[i]
+			}
 		case "--data-dir":
 			if i+1 < len(args) {
 				i++
@@ -164,6 +169,9 @@ func runTimerAdd(args []string) {
 	if timeoutMins != nil {
 		body["timeout_mins"] = *timeoutMins
 	}
+	if precheck != "" {
+		body["precheck"] = precheck
+	}
 	payload, _ := json.Marshal(body)
 
 	resp, err := apiPost(sockPath, "/timer/add", payload)
@@ -412,6 +420,8 @@ Options:
       --prompt <text>        Task prompt (runs through agent)
       --exec <command>       Shell command (runs directly, mutually exclusive with --prompt)
       --desc <text>          Short description
+      --precheck <command>   Shell command run first; the timer is skipped unless it
+                             exits 0. Its stdout is available as {{precheck_output}}
       --session-mode <mode>  reuse (default) or new-per-run
       --timeout-mins <n>     Max minutes to wait per run (0 = no limit; default 30)
       --mute                 Suppress all messages (start + result)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -12,7 +13,7 @@ import (
 	"github.com/chenhg5/cc-connect/core"
 )
 
-func runWeb(args []string) {
+func runWeb(ctx context.Context, args []string) {
 	configPath := resolveConfigPath("")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "Config file not found: %s\nRun cc-connect first to create a default config.\n", configPath)
@@ -40,7 +41,7 @@ func runWeb(args []string) {
 
 		mgmtToken := core.GenerateToken(16)
 		bridgeToken := core.GenerateToken(16)
-		result, err := config.EnableWebAdmin(mgmtToken, bridgeToken)
+		result, err := config.EnableWebAdmin(ctx, mgmtToken, bridgeToken)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error enabling web admin: %v\n", err)
 			os.Exit(1)
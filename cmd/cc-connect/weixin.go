@@ -49,7 +49,7 @@ type weixinQRStatusResponse struct {
 	IlinkUserID string `json:"ilink_user_id"`
 }
 
-func runWeixin(args []string) {
+func runWeixin(ctx context.Context, args []string) {
 	if len(args) == 0 {
 		printWeixinUsage()
 		return
@@ -57,11 +57,11 @@ func runWeixin(args []string) {
 
 	switch args[0] {
 	case "setup":
-		runWeixinSetup(args[1:], weixinSetupModeAuto)
+		runWeixinSetup(ctx, args[1:], weixinSetupModeAuto)
 	case "new", "create":
-		runWeixinSetup(args[1:], weixinSetupModeNew)
+		runWeixinSetup(ctx, args[1:], weixinSetupModeNew)
 	case "bind", "link":
-		runWeixinSetup(args[1:], weixinSetupModeBind)
+		runWeixinSetup(ctx, args[1:], weixinSetupModeBind)
 	case "help", "--help", "-h":
 		printWeixinUsage()
 	default:
@@ -71,7 +71,7 @@ func runWeixin(args []string) {
 	}
 }
 
-func runWeixinSetup(args []string, requestedMode string) {
+func runWeixinSetup(ctx context.Context, args []string, requestedMode string) {
 	fs := flag.NewFlagSet("weixin "+requestedMode, flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	project := fs.String("project", "", "project name (optional if only one project)")
@@ -167,7 +167,7 @@ func runWeixinSetup(args []string, requestedMode string) {
 	}
 
 	workDir, _ := os.Getwd()
-	provision, err := config.EnsureProjectWithWeixinPlatform(config.EnsureProjectWithWeixinOptions{
+	provision, err := config.EnsureProjectWithWeixinPlatform(ctx, config.EnsureProjectWithWeixinOptions{
 		ProjectName: targetProject,
 		WorkDir:     workDir,
 	})
@@ -194,7 +194,7 @@ func runWeixinSetup(args []string, requestedMode string) {
 		saveOpts.CDNBaseURL = strings.TrimRight(s, "/")
 	}
 
-	saveResult, err := config.SaveWeixinPlatformCredentials(saveOpts)
+	saveResult, err := config.SaveWeixinPlatformCredentials(ctx, saveOpts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: update config failed: %v\n", err)
 		os.Exit(1)
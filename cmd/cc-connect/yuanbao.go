@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
@@ -10,7 +11,7 @@ import (
 	"github.com/chenhg5/cc-connect/platform/yuanbao"
 )
 
-func runYuanbao(args []string) {
+func runYuanbao(ctx context.Context, args []string) {
 	if len(args) == 0 {
 		printYuanbaoUsage()
 		return
@@ -18,9 +19,9 @@ func runYuanbao(args []string) {
 
 	switch args[0] {
 	case "setup":
-		runYuanbaoSetup(args[1:], yuanbaoSetupModeAuto)
+		runYuanbaoSetup(ctx, args[1:], yuanbaoSetupModeAuto)
 	case "bind", "link":
-		runYuanbaoSetup(args[1:], yuanbaoSetupModeBind)
+		runYuanbaoSetup(ctx, args[1:], yuanbaoSetupModeBind)
 	case "help", "--help", "-h":
 		printYuanbaoUsage()
 	default:
@@ -35,7 +36,7 @@ const (
 	yuanbaoSetupModeBind = "bind"
 )
 
-func runYuanbaoSetup(args []string, requestedMode string) {
+func runYuanbaoSetup(ctx context.Context, args []string, requestedMode string) {
 	fs := flag.NewFlagSet("yuanbao "+requestedMode, flag.ExitOnError)
 	configFile := fs.String("config", "", "path to config file")
 	project := fs.String("project", "", "project name (optional if only one project)")
@@ -84,7 +85,7 @@ func runYuanbaoSetup(args []string, requestedMode string) {
 	}
 
 	workDir, _ := os.Getwd()
-	provision, err := config.EnsureProjectWithYuanbaoPlatform(config.EnsureProjectWithYuanbaoOptions{
+	provision, err := config.EnsureProjectWithYuanbaoPlatform(ctx, config.EnsureProjectWithYuanbaoOptions{
 		ProjectName: targetProject,
 		WorkDir:     workDir,
 	})
@@ -98,7 +99,7 @@ func runYuanbaoSetup(args []string, requestedMode string) {
 		fmt.Printf("Project %q had no Yuanbao platform; added one automatically.\n", targetProject)
 	}
 
-	saveResult, err := config.SaveYuanbaoPlatformCredentials(config.YuanbaoCredentialUpdateOptions{
+	saveResult, err := config.SaveYuanbaoPlatformCredentials(ctx, config.YuanbaoCredentialUpdateOptions{
 		ProjectName:   targetProject,
 		PlatformIndex: *platformIndex,
 		BotToken:      botToken,
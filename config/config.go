@@ -1,14 +1,17 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -98,6 +101,7 @@ type Config struct {
 	Log                LogConfig               `toml:"log"`
 	Language           string                  `toml:"language"` // "en" or "zh", default is "en"
 	Speech             SpeechConfig            `toml:"speech"`
+	OCR                OCRConfig               `toml:"ocr"`
 	TTS                TTSConfig               `toml:"tts"`
 	Display            DisplayConfig           `toml:"display"`
 	StreamPreview      StreamPreviewConfig     `toml:"stream_preview"`      // real-time streaming preview
@@ -105,6 +109,7 @@ type Config struct {
 	RateLimit          RateLimitConfig         `toml:"rate_limit"`          // per-session rate limiting
 	OutgoingRateLimit  OutgoingRateLimitConfig `toml:"outgoing_rate_limit"` // outgoing message throttling
 	Relay              RelayConfig             `toml:"relay"`               // bot-to-bot relay behavior
+	ProviderHealth     ProviderHealthConfig    `toml:"provider_health"`     // periodic provider probes and circuit breaker
 	Cron               CronConfig              `toml:"cron"`
 	Queue              QueueConfig             `toml:"queue"`
 	Webhook            WebhookConfig           `toml:"webhook"`
@@ -134,6 +139,20 @@ type Config struct {
 	// (50 MiB). Raise it to send larger files; the request body limit on the
 	// API side scales with this value to account for base64 expansion.
 	MaxAttachmentSizeMB int `toml:"max_attachment_size_mb,omitempty"`
+	// AttachmentConvert turns inbound documents (office files, PDFs,
+	// archives) into text agents can read, saved next to the original.
+	AttachmentConvert AttachmentConvertConfig `toml:"attachment_convert"`
+	// AutoReload watches the config file and applies changes automatically,
+	// as if /reload had been sent. Projects and platforms are added, removed
+	// or restarted individually; see docs/usage.md ("Reloading config").
+	AutoReload *bool `toml:"auto_reload,omitempty"`
+	// Include lists glob patterns, relative to this file, of extra config
+	// files holding [[projects]], [[providers]], [[commands]] and [[aliases]].
+	// conf.d/*.toml next to this file is always merged too; see include.go.
+	Include []string `toml:"include,omitempty"`
+
+	// secretValues holds what secret references resolved to; see SecretValues.
+	secretValues []string
 }
 
 // CronConfig controls cron job behavior.
@@ -147,6 +166,27 @@ type QueueConfig struct {
 	MaxDepth *int `toml:"max_depth"` // max queued messages per session; default 5
 }
 
+// ProviderHealthConfig controls the periodic health probes of providers and
+// the circuit breaker built on them.
+type ProviderHealthConfig struct {
+	Enabled          *bool `toml:"enabled"`           // default false
+	IntervalSecs     *int  `toml:"interval_secs"`     // seconds between probe rounds; default 300
+	TimeoutSecs      *int  `toml:"timeout_secs"`      // per-probe timeout; default 10
+	FailureThreshold *int  `toml:"failure_threshold"` // consecutive failures that open the circuit; default 3
+	OpenSecs         *int  `toml:"open_secs"`         // seconds an open circuit waits before a retry probe; default 600
+}
+
+// AttachmentConvertConfig controls the conversion of inbound attachments:
+// archives are unpacked, docx/xlsx/pptx are extracted to markdown and PDFs
+// to text with pdftotext.
+type AttachmentConvertConfig struct {
+	Enabled          *bool  `toml:"enabled"`                       // default false
+	PDFToText        string `toml:"pdftotext,omitempty"`           // pdftotext binary; default "pdftotext" from PATH
+	MaxArchiveFiles  *int   `toml:"max_archive_files,omitempty"`   // files unpacked per archive; default 200
+	MaxArchiveSizeMB *int   `toml:"max_archive_size_mb,omitempty"` // unpacked bytes per archive; default 100
+	MaxTextKB        *int   `toml:"max_text_kb,omitempty"`         // extracted text kept per document; default 1024
+}
+
 // WebhookConfig controls the external HTTP webhook endpoint.
 type WebhookConfig struct {
 	Enabled *bool  `toml:"enabled"`         // default false
@@ -202,6 +242,7 @@ type DisplayConfig struct {
 	ShowContextIndicator *bool   `toml:"show_context_indicator"` // whether [ctx: ~N%] suffix is shown; default true
 	ReplyFooter          *bool   `toml:"reply_footer"`           // whether Codex-like footer is shown; default true
 	HideAgentFooter      *bool   `toml:"hide_agent_footer"`      // strip agent-emitted model/token footer lines; default false
+	PagedReplies         *bool   `toml:"paged_replies"`          // send long replies as navigable pages on card/button platforms; default false
 }
 
 // StreamPreviewConfig controls real-time streaming preview in IM.
@@ -263,9 +304,13 @@ type RelayConfig struct {
 // SpeechConfig configures speech-to-text for voice messages.
 type SpeechConfig struct {
 	Enabled  bool   `toml:"enabled"`
-	Provider string `toml:"provider"` // "openai" | "groq" | "qwen" | "gemini"
+	Provider string `toml:"provider"` // "openai" | "groq" | "qwen" | "gemini" | "local"
 	Language string `toml:"language"` // e.g. "zh", "en"; empty = auto-detect
-	OpenAI   struct {
+	// ChunkSecs splits recordings longer than this many seconds at silences
+	// and transcribes the parts concurrently; default 300.
+	ChunkSecs   int `toml:"chunk_secs,omitempty"`
+	MaxParallel int `toml:"max_parallel,omitempty"` // chunks transcribed at once; default 3
+	OpenAI      struct {
 		APIKey  string `toml:"api_key"`
 		BaseURL string `toml:"base_url"`
 		Model   string `toml:"model"`
@@ -283,6 +328,24 @@ type SpeechConfig struct {
 		APIKey string `toml:"api_key"`
 		Model  string `toml:"model"`
 	} `toml:"gemini"`
+	Local struct {
+		Engine      string `toml:"engine"`       // "whisper.cpp" (default) | "faster-whisper"
+		Path        string `toml:"path"`         // binary; default "whisper-cli" / "whisper-ctranslate2"
+		Model       string `toml:"model"`        // ggml model file (whisper.cpp); model name or directory (faster-whisper)
+		Threads     int    `toml:"threads"`      // 0 = engine default
+		TimeoutSecs int    `toml:"timeout_secs"` // per transcription; default 300
+	} `toml:"local"`
+}
+
+// OCRConfig configures local text recognition for images sent to agents
+// that cannot read them (tmux, or acp/pi agents with vision = false).
+type OCRConfig struct {
+	Enabled   bool   `toml:"enabled"`
+	Provider  string `toml:"provider"` // "tesseract" (default)
+	Tesseract struct {
+		Path      string `toml:"path"`      // tesseract binary; default "tesseract" from PATH
+		Languages string `toml:"languages"` // -l value, e.g. "eng+chi_sim"; empty = tesseract default
+	} `toml:"tesseract"`
 }
 
 // TTSConfig configures text-to-speech output (mirrors SpeechConfig style).
@@ -295,7 +358,9 @@ type TTSConfig struct {
 	LanguageType string                    `toml:"language_type"` // optional provider-specific language hint
 	TTSMode      string                    `toml:"tts_mode"`      // "voice_only" (default) | "always"
 	MaxTextLen   int                       `toml:"max_text_len"`  // max rune count before skipping TTS; 0 = no limit
-	Agents       map[string]TTSAgentConfig `toml:"agents"`        // per-project/agent voice overrides keyed by [[projects]].name
+	Streaming    bool                      `toml:"streaming"`     // synthesize each paragraph while the reply streams; max_text_len then splits instead of skipping
+	Cache        TTSCacheConfig            `toml:"cache"`
+	Agents       map[string]TTSAgentConfig `toml:"agents"` // per-project/agent voice overrides keyed by [[projects]].name
 	OpenAI       struct {
 		APIKey  string `toml:"api_key"`
 		BaseURL string `toml:"base_url"`
@@ -319,6 +384,13 @@ type TTSConfig struct {
 	} `toml:"mimo"`
 }
 
+// TTSCacheConfig configures the on-disk cache of synthesized clips.
+type TTSCacheConfig struct {
+	Enabled   bool   `toml:"enabled"`
+	Dir       string `toml:"dir,omitempty"`         // default: <data_dir>/tts-cache
+	MaxSizeMB int    `toml:"max_size_mb,omitempty"` // default 200
+}
+
 // TTSAgentConfig overrides global [tts] synthesis parameters for one project.
 // Keys are project names, which map naturally to cc-connect's agent workspaces
 // (for example assistant, reviewer).
@@ -436,11 +508,13 @@ func expandUserPath(path string) string {
 
 // HeartbeatConfig controls periodic heartbeat for a project.
 type HeartbeatConfig struct {
+	Name         string `toml:"name,omitempty"`           // identifies the heartbeat in /heartbeat and the API; default "default"
 	Enabled      *bool  `toml:"enabled"`                  // default false
 	IntervalMins *int   `toml:"interval_mins,omitempty"`  // minutes between heartbeats; default 30
 	OnlyWhenIdle *bool  `toml:"only_when_idle,omitempty"` // only fire when the session is not busy; default true
 	SessionKey   string `toml:"session_key,omitempty"`    // target session key (e.g. "telegram:123:123"); required
-	Prompt       string `toml:"prompt,omitempty"`         // explicit prompt; if empty, reads HEARTBEAT.md from work_dir
+	Prompt       string `toml:"prompt,omitempty"`         // explicit prompt; if empty, reads prompt_file or HEARTBEAT.md from work_dir
+	PromptFile   string `toml:"prompt_file,omitempty"`    // prompt file, relative to work_dir unless absolute
 	Silent       *bool  `toml:"silent,omitempty"`         // suppress heartbeat notification; default true
 	TimeoutMins  *int   `toml:"timeout_mins,omitempty"`   // max execution time; default 30
 }
@@ -452,6 +526,15 @@ type AutoCompressConfig struct {
 	MinGapMins *int  `toml:"min_gap_mins,omitempty"` // minimum minutes between auto-compress runs (default 30)
 }
 
+// AutoAttachConfig controls offering files the agent created or modified
+// during a turn as chat attachments.
+type AutoAttachConfig struct {
+	Enabled       *bool    `toml:"enabled,omitempty"`          // default false
+	Patterns      []string `toml:"patterns,omitempty"`         // globs on the file name (or relative path when they contain "/"); default images and office/PDF/CSV/zip
+	MaxFileSizeMB *int     `toml:"max_file_size_mb,omitempty"` // larger files are listed but not sent; default 20
+	MaxFiles      *int     `toml:"max_files,omitempty"`        // files listed per turn; default 10
+}
+
 // ObserveConfig controls forwarding of native terminal Claude Code sessions to a messaging platform.
 type ObserveConfig struct {
 	Enabled bool   `toml:"enabled"`
@@ -482,6 +565,10 @@ type ProjectConfig struct {
 	Platforms                    []PlatformConfig   `toml:"platforms"`
 	Heartbeat                    HeartbeatConfig    `toml:"heartbeat"`
 	AutoCompress                 AutoCompressConfig `toml:"auto_compress"`
+	AutoAttach                   AutoAttachConfig   `toml:"auto_attach"`
+	// Heartbeats declares additional named heartbeats ([[projects.heartbeats]]),
+	// each with its own session, prompt, interval and idle/silent rules.
+	Heartbeats []HeartbeatConfig `toml:"heartbeats,omitempty"`
 	// ResetOnIdleMins automatically rotates to a new cc-connect session after
 	// the current session has been inactive for the specified number of minutes.
 	// 0 or nil disables the behavior.
@@ -559,6 +646,13 @@ type AgentConfig struct {
 	Options      map[string]any   `toml:"options"`
 	ProviderRefs []string         `toml:"provider_refs,omitempty"` // references to global [[providers]] by name
 	Providers    []ProviderConfig `toml:"providers"`
+	// ProviderFailover lists provider names, in order of preference, to
+	// retry a turn on when the active provider is rate limited (429),
+	// overloaded (529) or failing (5xx). Empty disables failover.
+	ProviderFailover []string `toml:"provider_failover,omitempty"`
+	// ProviderFailoverCooldownMins is how long a failed provider is skipped
+	// before switching back to it. nil or 0 = 10 minutes.
+	ProviderFailoverCooldownMins *int `toml:"provider_failover_cooldown_mins,omitempty"`
 }
 
 // ProviderModelConfig defines a selectable model entry for a provider,
@@ -581,6 +675,35 @@ type ProviderConfig struct {
 	AgentModels     map[string]string                `toml:"agent_models,omitempty"`      // per-agent-type default model (e.g. codex = "openai/gpt-5.3-codex")
 	AgentModelLists map[string][]ProviderModelConfig `toml:"agent_model_lists,omitempty"` // per-agent-type model lists (overrides Models when matched)
 	Codex           *CodexProviderConfig             `toml:"codex,omitempty"`             // Codex-specific provider settings
+	// APIKeys are extra keys for the same endpoint, rotated per new agent
+	// session together with APIKey. A key that hits a rate limit is skipped
+	// for KeyCooldownSecs.
+	APIKeys         []string `toml:"api_keys,omitempty"`
+	APIKeyEnv       string   `toml:"api_key_env,omitempty"`       // env var that receives the rotated key (default: api_key)
+	KeyRotation     string   `toml:"key_rotation,omitempty"`      // "round_robin" (default) or "least_limited"
+	KeyCooldownSecs *int     `toml:"key_cooldown_secs,omitempty"` // default 300
+	// Proxy declares request rewrites applied by the local provider proxy
+	// for Anthropic-compatible vendors (Claude Code only).
+	Proxy *ProviderProxyConfig `toml:"proxy,omitempty"`
+}
+
+// ProviderProxyConfig is the [providers.proxy] table: rewrites applied to
+// Messages requests before they reach the provider.
+type ProviderProxyConfig struct {
+	Protocol    string            `toml:"protocol,omitempty"`     // upstream API: "anthropic" (default) or "openai" (/v1/chat/completions)
+	ModelMap    map[string]string `toml:"model_map,omitempty"`    // request model -> vendor model; "prefix*" keys match by prefix
+	StripFields []string          `toml:"strip_fields,omitempty"` // body fields removed from the request, system, tools and content blocks (e.g. "cache_control")
+	StripBetas  []string          `toml:"strip_betas,omitempty"`  // values removed from the anthropic-beta header
+	Headers     map[string]string `toml:"headers,omitempty"`      // headers set on every upstream request
+	MaxTokens   int               `toml:"max_tokens,omitempty"`   // cap for max_tokens; 0 = no cap
+	LogTraffic  bool              `toml:"log_traffic,omitempty"`  // log request/response metadata and token usage
+	// CassetteMode "record" writes request/response pairs (secrets redacted)
+	// to CassetteDir; "replay" serves them back without contacting the provider.
+	CassetteMode string `toml:"cassette_mode,omitempty"`
+	CassetteDir  string `toml:"cassette_dir,omitempty"`
+	// CassetteLooseMatch lets replay serve the next recording for the same
+	// path when none has the same request body.
+	CassetteLooseMatch bool `toml:"cassette_loose_match,omitempty"`
 }
 
 // CodexProviderConfig holds Codex CLI-specific provider fields
@@ -626,6 +749,9 @@ func load(path string) (*Config, error) {
 	if err := toml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
+	if err := mergeIncludes(cfg, path); err != nil {
+		return nil, err
+	}
 	resolveEnvInConfig(cfg)
 	if cfg.DataDir == "" {
 		if home, err := os.UserHomeDir(); err == nil {
@@ -634,6 +760,9 @@ func load(path string) (*Config, error) {
 			cfg.DataDir = ".cc-connect"
 		}
 	}
+	if err := resolveSecretsInConfig(cfg); err != nil {
+		return nil, err
+	}
 	cfg.AttachmentSend = strings.ToLower(strings.TrimSpace(cfg.AttachmentSend))
 	if cfg.AttachmentSend == "" {
 		cfg.AttachmentSend = "on"
@@ -670,10 +799,12 @@ func Load(path string) (*Config, error) {
 var envPlaceholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
 
 func resolveEnvInConfig(cfg *Config) {
-	resolveEnvValue(reflect.ValueOf(cfg))
+	mapConfigStrings(reflect.ValueOf(cfg), resolveEnvPlaceholders)
 }
 
-func resolveEnvValue(v reflect.Value) {
+// mapConfigStrings replaces every string reachable from v, including map
+// values and strings inside interface values, with fn(string).
+func mapConfigStrings(v reflect.Value, fn func(string) string) {
 	if !v.IsValid() {
 		return
 	}
@@ -681,24 +812,24 @@ func resolveEnvValue(v reflect.Value) {
 	switch v.Kind() {
 	case reflect.Pointer:
 		if !v.IsNil() {
-			resolveEnvValue(v.Elem())
+			mapConfigStrings(v.Elem(), fn)
 		}
 	case reflect.Struct:
 		for i := 0; i < v.NumField(); i++ {
-			resolveEnvValue(v.Field(i))
+			mapConfigStrings(v.Field(i), fn)
 		}
 	case reflect.String:
 		if v.CanSet() {
-			v.SetString(resolveEnvPlaceholders(v.String()))
+			v.SetString(fn(v.String()))
 		}
 	case reflect.Slice, reflect.Array:
 		for i := 0; i < v.Len(); i++ {
 			elem := v.Index(i)
 			if elem.CanSet() {
-				elem.Set(resolveEnvClone(elem))
+				elem.Set(mapConfigStringsClone(elem, fn))
 				continue
 			}
-			resolveEnvValue(elem)
+			mapConfigStrings(elem, fn)
 		}
 	case reflect.Map:
 		if v.IsNil() {
@@ -706,17 +837,17 @@ func resolveEnvValue(v reflect.Value) {
 		}
 		iter := v.MapRange()
 		for iter.Next() {
-			v.SetMapIndex(iter.Key(), resolveEnvClone(iter.Value()))
+			v.SetMapIndex(iter.Key(), mapConfigStringsClone(iter.Value(), fn))
 		}
 	case reflect.Interface:
 		if v.IsNil() || !v.CanSet() {
 			return
 		}
-		v.Set(resolveEnvClone(v.Elem()))
+		v.Set(mapConfigStringsClone(v.Elem(), fn))
 	}
 }
 
-func resolveEnvClone(v reflect.Value) reflect.Value {
+func mapConfigStringsClone(v reflect.Value, fn func(string) string) reflect.Value {
 	if !v.IsValid() {
 		return v
 	}
@@ -724,7 +855,7 @@ func resolveEnvClone(v reflect.Value) reflect.Value {
 	switch v.Kind() {
 	case reflect.String:
 		out := reflect.New(v.Type()).Elem()
-		out.SetString(resolveEnvPlaceholders(v.String()))
+		out.SetString(fn(v.String()))
 		return out
 	case reflect.Pointer:
 		if v.IsNil() {
@@ -732,12 +863,12 @@ func resolveEnvClone(v reflect.Value) reflect.Value {
 		}
 		out := reflect.New(v.Type().Elem())
 		out.Elem().Set(v.Elem())
-		resolveEnvValue(out.Elem())
+		mapConfigStrings(out.Elem(), fn)
 		return out
 	case reflect.Struct:
 		out := reflect.New(v.Type()).Elem()
 		out.Set(v)
-		resolveEnvValue(out)
+		mapConfigStrings(out, fn)
 		return out
 	case reflect.Slice:
 		if v.IsNil() {
@@ -745,13 +876,13 @@ func resolveEnvClone(v reflect.Value) reflect.Value {
 		}
 		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
 		for i := 0; i < v.Len(); i++ {
-			out.Index(i).Set(resolveEnvClone(v.Index(i)))
+			out.Index(i).Set(mapConfigStringsClone(v.Index(i), fn))
 		}
 		return out
 	case reflect.Array:
 		out := reflect.New(v.Type()).Elem()
 		for i := 0; i < v.Len(); i++ {
-			out.Index(i).Set(resolveEnvClone(v.Index(i)))
+			out.Index(i).Set(mapConfigStringsClone(v.Index(i), fn))
 		}
 		return out
 	case reflect.Map:
@@ -761,7 +892,7 @@ func resolveEnvClone(v reflect.Value) reflect.Value {
 		out := reflect.MakeMapWithSize(v.Type(), v.Len())
 		iter := v.MapRange()
 		for iter.Next() {
-			out.SetMapIndex(iter.Key(), resolveEnvClone(iter.Value()))
+			out.SetMapIndex(iter.Key(), mapConfigStringsClone(iter.Value(), fn))
 		}
 		return out
 	case reflect.Interface:
@@ -769,7 +900,7 @@ func resolveEnvClone(v reflect.Value) reflect.Value {
 			return reflect.Zero(v.Type())
 		}
 		out := reflect.New(v.Type()).Elem()
-		out.Set(resolveEnvClone(v.Elem()))
+		out.Set(mapConfigStringsClone(v.Elem(), fn))
 		return out
 	default:
 		return v
@@ -939,6 +1070,19 @@ func EffectiveHistoryMaxLen(cfg *Config, proj *ProjectConfig) int {
 	return 1000
 }
 
+// EffectivePagedReplies reports whether long final replies are sent as
+// navigable pages. Resolution: project [display] > global [display] >
+// default false.
+func EffectivePagedReplies(cfg *Config, proj *ProjectConfig) bool {
+	if proj != nil && proj.Display != nil && proj.Display.PagedReplies != nil {
+		return *proj.Display.PagedReplies
+	}
+	if cfg != nil && cfg.Display.PagedReplies != nil {
+		return *cfg.Display.PagedReplies
+	}
+	return false
+}
+
 // EffectiveShell returns the shell binary, flag, and init command for the project.
 // Resolution: per-project > global > platform default.
 // The flag is auto-detected: "/C" for cmd, "-Command" for powershell/pwsh, "-c" for everything else.
@@ -1020,14 +1164,65 @@ func (c *Config) validateInternal(permissive bool) error {
 	default:
 		return fmt.Errorf("config: relay.visibility must be \"full\", \"summary\", or \"none\"")
 	}
+	for key, v := range map[string]*int{
+		"interval_secs":     c.ProviderHealth.IntervalSecs,
+		"timeout_secs":      c.ProviderHealth.TimeoutSecs,
+		"failure_threshold": c.ProviderHealth.FailureThreshold,
+		"open_secs":         c.ProviderHealth.OpenSecs,
+	} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("config: provider_health.%s must be >= 0", key)
+		}
+	}
+	switch c.Speech.Local.Engine {
+	case "", "whisper.cpp", "faster-whisper":
+	default:
+		return fmt.Errorf("config: speech.local.engine must be \"whisper.cpp\" or \"faster-whisper\"")
+	}
+	if c.Speech.Local.TimeoutSecs < 0 {
+		return fmt.Errorf("config: speech.local.timeout_secs must be >= 0")
+	}
+	if c.Speech.ChunkSecs < 0 {
+		return fmt.Errorf("config: speech.chunk_secs must be >= 0")
+	}
+	if c.Speech.MaxParallel < 0 {
+		return fmt.Errorf("config: speech.max_parallel must be >= 0")
+	}
+	if c.TTS.Cache.MaxSizeMB < 0 {
+		return fmt.Errorf("config: tts.cache.max_size_mb must be >= 0")
+	}
+	switch c.OCR.Provider {
+	case "", "tesseract":
+	default:
+		return fmt.Errorf("config: ocr.provider must be \"tesseract\"")
+	}
+	for key, v := range map[string]*int{
+		"max_archive_files":   c.AttachmentConvert.MaxArchiveFiles,
+		"max_archive_size_mb": c.AttachmentConvert.MaxArchiveSizeMB,
+		"max_text_kb":         c.AttachmentConvert.MaxTextKB,
+	} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("config: attachment_convert.%s must be >= 0", key)
+		}
+	}
+	for i, p := range c.Providers {
+		if err := validateProviderKeyPool(fmt.Sprintf("providers[%d]", i), p); err != nil {
+			return err
+		}
+	}
 	if len(c.Projects) == 0 {
 		return fmt.Errorf("config: at least one [[projects]] entry is required")
 	}
+	projectIndex := make(map[string]int, len(c.Projects))
 	for i, proj := range c.Projects {
 		prefix := fmt.Sprintf("projects[%d]", i)
 		if proj.Name == "" {
 			return fmt.Errorf("config: %s.name is required", prefix)
 		}
+		if prev, ok := projectIndex[proj.Name]; ok {
+			return fmt.Errorf("config: %s.name %q is already used by projects[%d]", prefix, proj.Name, prev)
+		}
+		projectIndex[proj.Name] = i
 		if proj.Agent.Type == "" {
 			return fmt.Errorf("config: %s.agent.type is required", prefix)
 		}
@@ -1053,6 +1248,14 @@ func (c *Config) validateInternal(permissive bool) error {
 		if proj.AgentSessionIdleTimeoutMins != nil && *proj.AgentSessionIdleTimeoutMins < 0 {
 			return fmt.Errorf("config: %s.agent_session_idle_timeout_mins must be >= 0", prefix)
 		}
+		if proj.Agent.ProviderFailoverCooldownMins != nil && *proj.Agent.ProviderFailoverCooldownMins < 0 {
+			return fmt.Errorf("config: %s.agent.provider_failover_cooldown_mins must be >= 0", prefix)
+		}
+		for j, p := range proj.Agent.Providers {
+			if err := validateProviderKeyPool(fmt.Sprintf("%s.agent.providers[%d]", prefix, j), p); err != nil {
+				return err
+			}
+		}
 		if err := validateRunAsUser(prefix, proj.RunAsUser); err != nil {
 			return err
 		}
@@ -1068,6 +1271,99 @@ func (c *Config) validateInternal(permissive bool) error {
 		if err := validateDisplayConfig(prefix+".display", proj.Display); err != nil {
 			return err
 		}
+		if err := validateAutoAttachConfig(prefix, proj.AutoAttach); err != nil {
+			return err
+		}
+		if err := validateHeartbeats(prefix, proj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateProviderKeyPool(prefix string, p ProviderConfig) error {
+	switch p.KeyRotation {
+	case "", "round_robin", "least_limited":
+	default:
+		return fmt.Errorf("config: %s.key_rotation must be \"round_robin\" or \"least_limited\"", prefix)
+	}
+	if p.KeyCooldownSecs != nil && *p.KeyCooldownSecs < 0 {
+		return fmt.Errorf("config: %s.key_cooldown_secs must be >= 0", prefix)
+	}
+	if p.Proxy != nil {
+		switch p.Proxy.Protocol {
+		case "", "anthropic", "openai":
+		default:
+			return fmt.Errorf("config: %s.proxy.protocol must be \"anthropic\" or \"openai\"", prefix)
+		}
+		if p.Proxy.MaxTokens < 0 {
+			return fmt.Errorf("config: %s.proxy.max_tokens must be >= 0", prefix)
+		}
+		switch p.Proxy.CassetteMode {
+		case "":
+		case "record", "replay":
+			if strings.TrimSpace(p.Proxy.CassetteDir) == "" {
+				return fmt.Errorf("config: %s.proxy.cassette_dir is required with cassette_mode", prefix)
+			}
+		default:
+			return fmt.Errorf("config: %s.proxy.cassette_mode must be \"record\" or \"replay\"", prefix)
+		}
+	}
+	return nil
+}
+
+// DefaultHeartbeatName is the name given to the legacy [projects.heartbeat]
+// table and to [[projects.heartbeats]] entries without a name.
+const DefaultHeartbeatName = "default"
+
+// reservedHeartbeatNames are /heartbeat subcommands; a heartbeat named like
+// one of them could not be addressed from chat.
+var reservedHeartbeatNames = map[string]bool{
+	"status": true, "list": true, "pause": true, "stop": true, "resume": true,
+	"start": true, "run": true, "trigger": true, "interval": true, "help": true,
+}
+
+// AllHeartbeats returns the project's heartbeats: the legacy [projects.heartbeat]
+// table (when configured) followed by [[projects.heartbeats]], with empty
+// names filled in as DefaultHeartbeatName.
+func (p *ProjectConfig) AllHeartbeats() []HeartbeatConfig {
+	var out []HeartbeatConfig
+	legacy := p.Heartbeat
+	if legacy.Enabled != nil || legacy.SessionKey != "" || legacy.Prompt != "" || legacy.PromptFile != "" {
+		if legacy.Name == "" {
+			legacy.Name = DefaultHeartbeatName
+		}
+		out = append(out, legacy)
+	}
+	for _, hb := range p.Heartbeats {
+		if hb.Name == "" {
+			hb.Name = DefaultHeartbeatName
+		}
+		out = append(out, hb)
+	}
+	return out
+}
+
+func validateHeartbeats(prefix string, proj ProjectConfig) error {
+	seen := make(map[string]bool)
+	for _, hb := range proj.AllHeartbeats() {
+		name := hb.Name
+		if strings.ContainsAny(name, " \t/") {
+			return fmt.Errorf("config: %s heartbeat name %q must not contain spaces or '/'", prefix, name)
+		}
+		if reservedHeartbeatNames[strings.ToLower(name)] {
+			return fmt.Errorf("config: %s heartbeat name %q is reserved", prefix, name)
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("config: %s has duplicate heartbeat name %q", prefix, name)
+		}
+		seen[strings.ToLower(name)] = true
+		if hb.IntervalMins != nil && *hb.IntervalMins < 0 {
+			return fmt.Errorf("config: %s heartbeat %q interval_mins must be >= 0", prefix, name)
+		}
+		if hb.TimeoutMins != nil && *hb.TimeoutMins < 0 {
+			return fmt.Errorf("config: %s heartbeat %q timeout_mins must be >= 0", prefix, name)
+		}
 	}
 	return nil
 }
@@ -1158,6 +1454,21 @@ func validateReferenceConfig(prefix string, rc ReferenceConfig) error {
 	return nil
 }
 
+func validateAutoAttachConfig(prefix string, c AutoAttachConfig) error {
+	for _, p := range c.Patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("config: %s.auto_attach.patterns has invalid glob %q", prefix, p)
+		}
+	}
+	if c.MaxFileSizeMB != nil && *c.MaxFileSizeMB < 0 {
+		return fmt.Errorf("config: %s.auto_attach.max_file_size_mb must be >= 0", prefix)
+	}
+	if c.MaxFiles != nil && *c.MaxFiles < 0 {
+		return fmt.Errorf("config: %s.auto_attach.max_files must be >= 0", prefix)
+	}
+	return nil
+}
+
 // validateUsersConfig checks the [projects.users] section for consistency.
 func validateUsersConfig(prefix string, u *UsersConfig) error {
 	if u == nil {
@@ -1197,23 +1508,24 @@ func validateUsersConfig(prefix string, u *UsersConfig) error {
 
 // SaveActiveProvider persists the active provider name for a project.
 // It uses surgical text editing to preserve comments and unknown fields.
-func SaveActiveProvider(projectName, providerName string) error {
+func SaveActiveProvider(ctx context.Context, projectName, providerName string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	return patchProjectAgentOption(projectName, "provider", providerName)
+	return patchProjectAgentOption(ctx, projectName, "provider", providerName)
 }
 
 // SaveProviderModel persists the selected model for a provider in a project.
 // It first looks in the project's inline providers, then falls back to
 // global [[providers]] if the provider is referenced via provider_refs.
 // Uses surgical text editing to preserve comments and unknown fields.
-func SaveProviderModel(projectName, providerName, model string) error {
+func SaveProviderModel(ctx context.Context, projectName, providerName, model string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1246,7 +1558,7 @@ func SaveProviderModel(projectName, providerName, model string) error {
 			if j < len(projSpan.agentProviders) {
 				ps := projSpan.agentProviders[j]
 				lines = upsertTomlStringKey(lines, ps.start+1, ps.end, "model", model)
-				return writeRawConfig(joinConfigLines(lines, hadTrailing))
+				return writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing))
 			}
 			break
 		}
@@ -1254,13 +1566,26 @@ func SaveProviderModel(projectName, providerName, model string) error {
 
 	for _, ref := range cfg.Projects[projectIdx].Agent.ProviderRefs {
 		if ref == providerName {
-			return patchGlobalProviderField(lines, hadTrailing, cfg, providerName, "model", model)
+			return patchGlobalProviderField(ctx, providerName, "model", model)
 		}
 	}
 	return fmt.Errorf("provider %q not found in project %q", providerName, projectName)
 }
 
-func patchGlobalProviderField(lines []string, hadTrailing bool, cfg *Config, providerName, key, value string) error {
+// patchGlobalProviderField does a surgical update of one key in the
+// [[providers]] entry named providerName, in whichever file defines it.
+// The caller must hold configMu.
+func patchGlobalProviderField(ctx context.Context, providerName, key, value string) error {
+	path := configFileDefining("providers", providerName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	cfg := &Config{}
+	if err := toml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	lines, hadTrailing := splitConfigLines(string(data))
 	globalStarts := make([]int, 0, 4)
 	for i := range lines {
 		if matchTableHeader(lines[i], "[[providers]]") {
@@ -1283,27 +1608,28 @@ func patchGlobalProviderField(lines []string, hadTrailing bool, cfg *Config, pro
 			}
 		}
 		lines = upsertTomlStringKey(lines, gstart+1, gend, key, value)
-		return writeRawConfig(joinConfigLines(lines, hadTrailing))
+		return writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing))
 	}
 	return fmt.Errorf("global provider %q not found", providerName)
 }
 
 // SaveAgentModel persists the selected default model for a project's agent.
 // It uses surgical text editing to preserve comments and unknown fields.
-func SaveAgentModel(projectName, model string) error {
+func SaveAgentModel(ctx context.Context, projectName, model string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	return patchProjectAgentOption(projectName, "model", model)
+	return patchProjectAgentOption(ctx, projectName, "model", model)
 }
 
 // AddProviderToConfig adds a provider to a project's agent config and saves.
-func AddProviderToConfig(projectName string, provider ProviderConfig) error {
+func AddProviderToConfig(ctx context.Context, projectName string, provider ProviderConfig) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1328,19 +1654,20 @@ func AddProviderToConfig(projectName string, provider ProviderConfig) error {
 	if !found {
 		return fmt.Errorf("project %q not found in config", projectName)
 	}
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // RemoveProviderFromConfig removes a provider from a project's agent config and saves.
 // For global providers referenced via provider_refs, it removes the reference
 // instead of deleting the global definition.
-func RemoveProviderFromConfig(projectName, providerName string) error {
+func RemoveProviderFromConfig(ctx context.Context, projectName, providerName string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1372,12 +1699,15 @@ func RemoveProviderFromConfig(projectName, providerName string) error {
 				break
 			}
 		}
+		if found {
+			cfg.Projects[i].Agent.ProviderFailover = slices.DeleteFunc(cfg.Projects[i].Agent.ProviderFailover, func(name string) bool { return name == providerName })
+		}
 		break
 	}
 	if !found {
 		return fmt.Errorf("provider %q not found in project %q", providerName, projectName)
 	}
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // ResolveProviderRefs merges global [[providers]] into each project that uses
@@ -1449,11 +1779,12 @@ func containsString(ss []string, s string) bool {
 
 // ── Global provider CRUD ───────────────────────────────────────
 
-// ListGlobalProviders returns the top-level [[providers]] list.
+// ListGlobalProviders returns the top-level [[providers]] list, including
+// providers defined in included files.
 func ListGlobalProviders() ([]ProviderConfig, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
-	cfg, err := loadLocked()
+	cfg, err := loadMergedLocked()
 	if err != nil {
 		return nil, err
 	}
@@ -1461,9 +1792,12 @@ func ListGlobalProviders() ([]ProviderConfig, error) {
 }
 
 // AddGlobalProvider appends a provider to the top-level [[providers]] and saves.
-func AddGlobalProvider(provider ProviderConfig) error {
+func AddGlobalProvider(ctx context.Context, provider ProviderConfig) error {
 	configMu.Lock()
 	defer configMu.Unlock()
+	if path := configFileDefining("providers", provider.Name); path != ConfigPath {
+		return fmt.Errorf("global provider %q already exists in %s", provider.Name, path)
+	}
 	cfg, err := loadLocked()
 	if err != nil {
 		return err
@@ -1474,14 +1808,18 @@ func AddGlobalProvider(provider ProviderConfig) error {
 		}
 	}
 	cfg.Providers = append(cfg.Providers, provider)
-	return saveConfig(cfg)
+	return saveConfig(ctx, cfg)
 }
 
 // UpdateGlobalProvider replaces an existing global provider by name.
-func UpdateGlobalProvider(name string, provider ProviderConfig) error {
+func UpdateGlobalProvider(ctx context.Context, name string, provider ProviderConfig) error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	cfg, err := loadLocked()
+	if ConfigPath == "" {
+		return fmt.Errorf("config path not set")
+	}
+	path := configFileDefining("providers", name)
+	cfg, err := loadConfigFile(path)
 	if err != nil {
 		return err
 	}
@@ -1489,49 +1827,77 @@ func UpdateGlobalProvider(name string, provider ProviderConfig) error {
 		if cfg.Providers[i].Name == name {
 			provider.Name = name // name is immutable in update
 			cfg.Providers[i] = provider
-			return saveConfig(cfg)
+			return saveConfigTo(ctx, path, cfg)
 		}
 	}
 	return fmt.Errorf("global provider %q not found", name)
 }
 
 // RemoveGlobalProvider removes a provider from top-level [[providers]] and
-// also strips the name from every project's provider_refs, then saves.
-func RemoveGlobalProvider(name string) error {
+// also strips the name from every project's provider_refs, then saves each
+// file that changed.
+func RemoveGlobalProvider(ctx context.Context, name string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	cfg, err := loadLocked()
+	merged, err := loadMergedLocked()
 	if err != nil {
 		return err
 	}
 	found := false
-	for i := range cfg.Providers {
-		if cfg.Providers[i].Name == name {
-			cfg.Providers = append(cfg.Providers[:i], cfg.Providers[i+1:]...)
-			found = true
-			break
-		}
+	for _, p := range merged.Providers {
+		found = found || p.Name == name
 	}
 	if !found {
 		return fmt.Errorf("global provider %q not found", name)
 	}
-	for i := range cfg.Projects {
-		refs := cfg.Projects[i].Agent.ProviderRefs
-		for j := 0; j < len(refs); j++ {
-			if refs[j] == name {
-				cfg.Projects[i].Agent.ProviderRefs = append(refs[:j], refs[j+1:]...)
+	included, err := IncludedConfigFiles(ConfigPath)
+	if err != nil {
+		return err
+	}
+	for _, path := range append([]string{ConfigPath}, included...) {
+		cfg, err := loadConfigFile(path)
+		if err != nil {
+			return err
+		}
+		changed := false
+		for i := range cfg.Providers {
+			if cfg.Providers[i].Name == name {
+				cfg.Providers = append(cfg.Providers[:i], cfg.Providers[i+1:]...)
+				changed = true
 				break
 			}
 		}
+		for i := range cfg.Projects {
+			refs := cfg.Projects[i].Agent.ProviderRefs
+			for j := 0; j < len(refs); j++ {
+				if refs[j] == name {
+					cfg.Projects[i].Agent.ProviderRefs = append(refs[:j], refs[j+1:]...)
+					cfg.Projects[i].Agent.ProviderFailover = slices.DeleteFunc(cfg.Projects[i].Agent.ProviderFailover, func(n string) bool { return n == name })
+					changed = true
+					break
+				}
+			}
+		}
+		if changed {
+			if err := saveConfigTo(ctx, path, cfg); err != nil {
+				return err
+			}
+		}
 	}
-	return saveConfig(cfg)
+	return nil
 }
 
 func loadLocked() (*Config, error) {
 	if ConfigPath == "" {
 		return nil, fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	return loadConfigFile(ConfigPath)
+}
+
+// loadConfigFile parses one config file on its own: no includes, env
+// placeholders or secret references are resolved.
+func loadConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -1542,37 +1908,23 @@ func loadLocked() (*Config, error) {
 	return cfg, nil
 }
 
-func saveConfig(cfg *Config) error {
-	dir := filepath.Dir(ConfigPath)
-	tmp, err := os.CreateTemp(dir, ".config-*.tmp")
-	if err != nil {
-		return fmt.Errorf("create temp config: %w", err)
-	}
-	tmpPath := tmp.Name()
+func saveConfig(ctx context.Context, cfg *Config) error {
+	return saveConfigTo(ctx, ConfigPath, cfg)
+}
 
+// saveConfigTo re-encodes cfg into path. Included files only get the
+// sections they are allowed to hold.
+func saveConfigTo(ctx context.Context, path string, cfg *Config) error {
+	var v any = cfg
+	if path != ConfigPath {
+		v = &includedConfig{Providers: cfg.Providers, Projects: cfg.Projects, Commands: cfg.Commands, Aliases: cfg.Aliases}
+	}
 	var buf strings.Builder
-	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
-		tmp.Close()
-		os.Remove(tmpPath)
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
 		return fmt.Errorf("encode config: %w", err)
 	}
-
-	formatted := formatTOML(buf.String())
-	if _, err := tmp.WriteString(formatted); err != nil {
-		tmp.Close()
-		os.Remove(tmpPath)
-		return fmt.Errorf("write config: %w", err)
-	}
-	if err := tmp.Sync(); err != nil {
-		tmp.Close()
-		os.Remove(tmpPath)
-		return err
-	}
-	if err := tmp.Close(); err != nil {
-		os.Remove(tmpPath)
-		return err
-	}
-	return os.Rename(tmpPath, ConfigPath)
+	snapshotConfig(ctx, path, "")
+	return replaceConfigFile(path, formatTOML(buf.String()))
 }
 
 // formatTOML post-processes raw TOML encoder output to improve readability:
@@ -1651,24 +2003,17 @@ func formatTOML(raw string) string {
 
 // SaveLanguage saves the language setting to the config file.
 // Uses surgical text editing to preserve comments and unknown fields.
-func SaveLanguage(lang string) error {
+func SaveLanguage(ctx context.Context, lang string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	return patchTopLevelField("language", lang)
+	return patchTopLevelField(ctx, "language", lang)
 }
 
-// ListProjects returns project names from the config file.
+// ListProjects returns project names from the config file and its includes.
 func ListProjects() ([]string, error) {
-	if ConfigPath == "" {
-		return nil, fmt.Errorf("config path not set")
-	}
-	data, err := os.ReadFile(ConfigPath)
+	cfg, err := loadMergedLocked()
 	if err != nil {
-		return nil, fmt.Errorf("read config: %w", err)
-	}
-	cfg := &Config{}
-	if err := toml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+		return nil, err
 	}
 	var names []string
 	for _, p := range cfg.Projects {
@@ -1678,13 +2023,14 @@ func ListProjects() ([]string, error) {
 }
 
 // AddCommand adds a global custom command and persists to config.
-func AddCommand(cmd CommandConfig) error {
+func AddCommand(ctx context.Context, cmd CommandConfig) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := configFileDefining("commands", cmd.Name)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1698,17 +2044,18 @@ func AddCommand(cmd CommandConfig) error {
 		}
 	}
 	cfg.Commands = append(cfg.Commands, cmd)
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // RemoveCommand removes a global custom command and persists to config.
-func RemoveCommand(name string) error {
+func RemoveCommand(ctx context.Context, name string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := configFileDefining("commands", name)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1729,17 +2076,18 @@ func RemoveCommand(name string) error {
 		return fmt.Errorf("command %q not found", name)
 	}
 	cfg.Commands = remaining
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // AddAlias adds a global alias and persists to config.
-func AddAlias(alias AliasConfig) error {
+func AddAlias(ctx context.Context, alias AliasConfig) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := configFileDefining("aliases", alias.Name)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1750,21 +2098,22 @@ func AddAlias(alias AliasConfig) error {
 	for i, a := range cfg.Aliases {
 		if a.Name == alias.Name {
 			cfg.Aliases[i] = alias
-			return saveConfig(cfg)
+			return saveConfigTo(ctx, path, cfg)
 		}
 	}
 	cfg.Aliases = append(cfg.Aliases, alias)
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // RemoveAlias removes a global alias and persists to config.
-func RemoveAlias(name string) error {
+func RemoveAlias(ctx context.Context, name string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := configFileDefining("aliases", name)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -1785,36 +2134,36 @@ func RemoveAlias(name string) error {
 		return fmt.Errorf("alias %q not found", name)
 	}
 	cfg.Aliases = remaining
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // SaveDisplayConfig persists the display settings to the config file.
 // Uses surgical text editing to preserve comments and unknown fields.
-func SaveDisplayConfig(mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error {
+func SaveDisplayConfig(ctx context.Context, mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if mode != nil {
-		if err := patchSectionField("display", "mode", quoteTomlString(*mode)); err != nil {
+		if err := patchSectionField(ctx, "display", "mode", quoteTomlString(*mode)); err != nil {
 			return err
 		}
 	}
 	if thinkingMessages != nil {
-		if err := patchSectionField("display", "thinking_messages", fmt.Sprintf("%t", *thinkingMessages)); err != nil {
+		if err := patchSectionField(ctx, "display", "thinking_messages", fmt.Sprintf("%t", *thinkingMessages)); err != nil {
 			return err
 		}
 	}
 	if thinkingMaxLen != nil {
-		if err := patchSectionField("display", "thinking_max_len", fmt.Sprintf("%d", *thinkingMaxLen)); err != nil {
+		if err := patchSectionField(ctx, "display", "thinking_max_len", fmt.Sprintf("%d", *thinkingMaxLen)); err != nil {
 			return err
 		}
 	}
 	if toolMaxLen != nil {
-		if err := patchSectionField("display", "tool_max_len", fmt.Sprintf("%d", *toolMaxLen)); err != nil {
+		if err := patchSectionField(ctx, "display", "tool_max_len", fmt.Sprintf("%d", *toolMaxLen)); err != nil {
 			return err
 		}
 	}
 	if toolMessages != nil {
-		if err := patchSectionField("display", "tool_messages", fmt.Sprintf("%t", *toolMessages)); err != nil {
+		if err := patchSectionField(ctx, "display", "tool_messages", fmt.Sprintf("%t", *toolMessages)); err != nil {
 			return err
 		}
 	}
@@ -1823,10 +2172,10 @@ func SaveDisplayConfig(mode *string, thinkingMessages *bool, thinkingMaxLen, too
 
 // SaveTTSMode persists the TTS mode setting to the config file.
 // Uses surgical text editing to preserve comments and unknown fields.
-func SaveTTSMode(mode string) error {
+func SaveTTSMode(ctx context.Context, mode string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	return patchSectionField("tts", "tts_mode", quoteTomlString(mode))
+	return patchSectionField(ctx, "tts", "tts_mode", quoteTomlString(mode))
 }
 
 // GetProjectProviders returns providers for a given project.
@@ -1834,7 +2183,8 @@ func GetProjectProviders(projectName string) ([]ProviderConfig, string, error) {
 	if ConfigPath == "" {
 		return nil, "", fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, "", fmt.Errorf("read config: %w", err)
 	}
@@ -1893,7 +2243,7 @@ type FeishuCredentialUpdateResult struct {
 // EnsureProjectWithFeishuPlatform ensures target project exists. If project does
 // not exist, it creates one with a Feishu/Lark platform so credentials can be
 // written immediately.
-func EnsureProjectWithFeishuPlatform(opts EnsureProjectWithFeishuOptions) (*EnsureProjectWithFeishuResult, error) {
+func EnsureProjectWithFeishuPlatform(ctx context.Context, opts EnsureProjectWithFeishuOptions) (*EnsureProjectWithFeishuResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 
@@ -1913,7 +2263,8 @@ func EnsureProjectWithFeishuPlatform(opts EnsureProjectWithFeishuOptions) (*Ensu
 		return nil, fmt.Errorf("invalid platform type %q (want feishu or lark)", opts.PlatformType)
 	}
 
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -1948,7 +2299,7 @@ func EnsureProjectWithFeishuPlatform(opts EnsureProjectWithFeishuOptions) (*Ensu
 				block = append(block, "")
 			}
 			lines = insertLines(lines, insertAt, block)
-			if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+			if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 				return nil, err
 			}
 			platformIdx = len(cfg.Projects[i].Platforms)
@@ -2001,7 +2352,7 @@ func EnsureProjectWithFeishuPlatform(opts EnsureProjectWithFeishuOptions) (*Ensu
 	lines = append(lines, fmt.Sprintf("type = %s", quoteTomlString(platformType)))
 	lines = append(lines, "")
 	lines = append(lines, "[projects.platforms.options]")
-	if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+	if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 		return nil, err
 	}
 
@@ -2016,7 +2367,7 @@ func EnsureProjectWithFeishuPlatform(opts EnsureProjectWithFeishuOptions) (*Ensu
 
 // SaveFeishuPlatformCredentials updates app_id/app_secret for a project's
 // Feishu/Lark platform and persists the config atomically.
-func SaveFeishuPlatformCredentials(opts FeishuCredentialUpdateOptions) (*FeishuCredentialUpdateResult, error) {
+func SaveFeishuPlatformCredentials(ctx context.Context, opts FeishuCredentialUpdateOptions) (*FeishuCredentialUpdateResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 
@@ -2036,7 +2387,8 @@ func SaveFeishuPlatformCredentials(opts FeishuCredentialUpdateOptions) (*FeishuC
 		return nil, fmt.Errorf("invalid platform type %q (want feishu or lark)", opts.PlatformType)
 	}
 
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(opts.ProjectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -2147,7 +2499,7 @@ func SaveFeishuPlatformCredentials(opts FeishuCredentialUpdateOptions) (*FeishuC
 		span = reloadSpan()
 	}
 
-	if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+	if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 		return nil, err
 	}
 
@@ -2271,7 +2623,7 @@ type WeixinCredentialUpdateResult struct {
 }
 
 // EnsureProjectWithWeixinPlatform ensures the target project exists and has a weixin platform entry.
-func EnsureProjectWithWeixinPlatform(opts EnsureProjectWithWeixinOptions) (*EnsureProjectWithWeixinResult, error) {
+func EnsureProjectWithWeixinPlatform(ctx context.Context, opts EnsureProjectWithWeixinOptions) (*EnsureProjectWithWeixinResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 
@@ -2283,7 +2635,8 @@ func EnsureProjectWithWeixinPlatform(opts EnsureProjectWithWeixinOptions) (*Ensu
 		return nil, fmt.Errorf("project name is required")
 	}
 
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -2318,7 +2671,7 @@ func EnsureProjectWithWeixinPlatform(opts EnsureProjectWithWeixinOptions) (*Ensu
 				block = append(block, "")
 			}
 			lines = insertLines(lines, insertAt, block)
-			if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+			if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 				return nil, err
 			}
 			platformIdx = len(cfg.Projects[i].Platforms)
@@ -2370,7 +2723,7 @@ func EnsureProjectWithWeixinPlatform(opts EnsureProjectWithWeixinOptions) (*Ensu
 	lines = append(lines, `type = "weixin"`)
 	lines = append(lines, "")
 	lines = append(lines, "[projects.platforms.options]")
-	if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+	if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 		return nil, err
 	}
 
@@ -2383,7 +2736,7 @@ func EnsureProjectWithWeixinPlatform(opts EnsureProjectWithWeixinOptions) (*Ensu
 }
 
 // SaveWeixinPlatformCredentials updates token (and optional fields) for a project's Weixin platform.
-func SaveWeixinPlatformCredentials(opts WeixinCredentialUpdateOptions) (*WeixinCredentialUpdateResult, error) {
+func SaveWeixinPlatformCredentials(ctx context.Context, opts WeixinCredentialUpdateOptions) (*WeixinCredentialUpdateResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 
@@ -2400,7 +2753,8 @@ func SaveWeixinPlatformCredentials(opts WeixinCredentialUpdateOptions) (*WeixinC
 		return nil, fmt.Errorf("platform index must be >= 0")
 	}
 
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(opts.ProjectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -2520,7 +2874,7 @@ func SaveWeixinPlatformCredentials(opts WeixinCredentialUpdateOptions) (*WeixinC
 		span = reloadSpan()
 	}
 
-	if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+	if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 		return nil, err
 	}
 
@@ -2559,7 +2913,7 @@ type EnsureProjectWithYuanbaoResult struct {
 }
 
 // EnsureProjectWithYuanbaoPlatform ensures the target project exists and has a yuanbao platform entry.
-func EnsureProjectWithYuanbaoPlatform(opts EnsureProjectWithYuanbaoOptions) (*EnsureProjectWithYuanbaoResult, error) {
+func EnsureProjectWithYuanbaoPlatform(ctx context.Context, opts EnsureProjectWithYuanbaoOptions) (*EnsureProjectWithYuanbaoResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 
@@ -2571,7 +2925,8 @@ func EnsureProjectWithYuanbaoPlatform(opts EnsureProjectWithYuanbaoOptions) (*En
 		return nil, fmt.Errorf("project name is required")
 	}
 
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -2606,7 +2961,7 @@ func EnsureProjectWithYuanbaoPlatform(opts EnsureProjectWithYuanbaoOptions) (*En
 				block = append(block, "")
 			}
 			lines = insertLines(lines, insertAt, block)
-			if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+			if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 				return nil, err
 			}
 			platformIdx = len(cfg.Projects[i].Platforms)
@@ -2658,7 +3013,7 @@ func EnsureProjectWithYuanbaoPlatform(opts EnsureProjectWithYuanbaoOptions) (*En
 	lines = append(lines, `type = "yuanbao"`)
 	lines = append(lines, "")
 	lines = append(lines, "[projects.platforms.options]")
-	if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+	if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 		return nil, err
 	}
 
@@ -2691,7 +3046,7 @@ type YuanbaoCredentialUpdateResult struct {
 
 // SaveYuanbaoPlatformCredentials updates bot_token (and optional fields)
 // for a project's Yuanbao platform.
-func SaveYuanbaoPlatformCredentials(opts YuanbaoCredentialUpdateOptions) (*YuanbaoCredentialUpdateResult, error) {
+func SaveYuanbaoPlatformCredentials(ctx context.Context, opts YuanbaoCredentialUpdateOptions) (*YuanbaoCredentialUpdateResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 
@@ -2710,7 +3065,8 @@ func SaveYuanbaoPlatformCredentials(opts YuanbaoCredentialUpdateOptions) (*Yuanb
 		return nil, fmt.Errorf("platform index must be >= 0")
 	}
 
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(opts.ProjectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read config: %w", err)
 	}
@@ -2817,7 +3173,7 @@ func SaveYuanbaoPlatformCredentials(opts YuanbaoCredentialUpdateOptions) (*Yuanb
 		span = reloadSpan()
 	}
 
-	if err := writeRawConfig(joinConfigLines(lines, hadTrailing)); err != nil {
+	if err := writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing)); err != nil {
 		return nil, err
 	}
 
@@ -2884,6 +3240,23 @@ func cloneAgentConfig(in AgentConfig) AgentConfig {
 					p.AgentModelLists[k] = append([]ProviderModelConfig(nil), v...)
 				}
 			}
+			p.APIKeys = append([]string(nil), in.Providers[i].APIKeys...)
+			p.APIKeyEnv = in.Providers[i].APIKeyEnv
+			p.KeyRotation = in.Providers[i].KeyRotation
+			p.KeyCooldownSecs = in.Providers[i].KeyCooldownSecs
+			if px := in.Providers[i].Proxy; px != nil {
+				p.Proxy = &ProviderProxyConfig{
+					Protocol:    px.Protocol,
+					ModelMap:    cloneStringMap(px.ModelMap),
+					StripFields: append([]string(nil), px.StripFields...),
+					StripBetas:  append([]string(nil), px.StripBetas...),
+					Headers:     cloneStringMap(px.Headers),
+					MaxTokens:   px.MaxTokens,
+					LogTraffic:  px.LogTraffic,
+				}
+				p.Proxy.CassetteMode, p.Proxy.CassetteDir = px.CassetteMode, px.CassetteDir
+				p.Proxy.CassetteLooseMatch = px.CassetteLooseMatch
+			}
 			if in.Providers[i].Codex != nil {
 				p.Codex = &CodexProviderConfig{
 					EnvKey:      in.Providers[i].Codex.EnvKey,
@@ -2923,11 +3296,12 @@ func cloneStringMap(in map[string]string) map[string]string {
 // under [projects.agent.options] for the given project. It preserves all
 // comments, unknown fields, and formatting in the config file.
 // The caller must hold configMu.
-func patchProjectAgentOption(projectName, key, value string) error {
+func patchProjectAgentOption(ctx context.Context, projectName, key, value string) error {
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -2979,12 +3353,12 @@ func patchProjectAgentOption(projectName, key, value string) error {
 	}
 
 	lines = upsertTomlStringKey(lines, projSpan.agentOptionsStart+1, projSpan.agentOptionsEnd, key, value)
-	return writeRawConfig(joinConfigLines(lines, hadTrailing))
+	return writeRawConfigTo(ctx, path, joinConfigLines(lines, hadTrailing))
 }
 
 // patchTopLevelField does a surgical text-level update of a single top-level
 // key in the config file. The caller must hold configMu.
-func patchTopLevelField(key, value string) error {
+func patchTopLevelField(ctx context.Context, key, value string) error {
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
@@ -3007,7 +3381,7 @@ func patchTopLevelField(key, value string) error {
 	for i := 0; i <= topEnd && i < len(lines); i++ {
 		if matchTomlStringKey(lines[i], key) {
 			lines[i] = replaceTomlStringKeyLine(lines[i], key, value)
-			return writeRawConfig(joinConfigLines(lines, hadTrailing))
+			return writeRawConfig(ctx, joinConfigLines(lines, hadTrailing))
 		}
 	}
 	// Key not found; insert before the first section header.
@@ -3016,12 +3390,12 @@ func patchTopLevelField(key, value string) error {
 		insertAt = 0
 	}
 	lines = insertLines(lines, insertAt, []string{fmt.Sprintf("%s = %s", key, quoteTomlString(value))})
-	return writeRawConfig(joinConfigLines(lines, hadTrailing))
+	return writeRawConfig(ctx, joinConfigLines(lines, hadTrailing))
 }
 
 // patchSectionField does a surgical text-level update of a single key
 // under a given [section] in the config file. The caller must hold configMu.
-func patchSectionField(section, key, tomlValue string) error {
+func patchSectionField(ctx context.Context, section, key, tomlValue string) error {
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
@@ -3060,11 +3434,11 @@ func patchSectionField(section, key, tomlValue string) error {
 		}
 		block := []string{"", header, fmt.Sprintf("%s = %s", key, tomlValue)}
 		lines = insertLines(lines, insertAt, block)
-		return writeRawConfig(joinConfigLines(lines, hadTrailing))
+		return writeRawConfig(ctx, joinConfigLines(lines, hadTrailing))
 	}
 
 	lines = upsertTomlRawKey(lines, sectionStart+1, sectionEnd, key, tomlValue)
-	return writeRawConfig(joinConfigLines(lines, hadTrailing))
+	return writeRawConfig(ctx, joinConfigLines(lines, hadTrailing))
 }
 
 type rawProjectSpan struct {
@@ -3378,13 +3752,14 @@ type ProjectSettingsUpdate struct {
 }
 
 // SaveProjectSettings persists project-level settings and the global language to config.toml.
-func SaveProjectSettings(projectName string, update ProjectSettingsUpdate) error {
+func SaveProjectSettings(ctx context.Context, projectName string, update ProjectSettingsUpdate) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -3394,6 +3769,12 @@ func SaveProjectSettings(projectName string, update ProjectSettingsUpdate) error
 	}
 
 	if update.Language != nil {
+		if path != ConfigPath {
+			// Language is a top-level setting and lives in the main file.
+			if err := patchTopLevelField(ctx, "language", *update.Language); err != nil {
+				return err
+			}
+		}
 		cfg.Language = *update.Language
 	}
 
@@ -3507,7 +3888,7 @@ func SaveProjectSettings(projectName string, update ProjectSettingsUpdate) error
 				proj.Platforms[j].Options["allow_from"] = strings.TrimSpace(af)
 			}
 		}
-		return saveConfig(cfg)
+		return saveConfigTo(ctx, path, cfg)
 	}
 	return fmt.Errorf("project %q not found", projectName)
 }
@@ -3517,7 +3898,8 @@ func GetProjectConfigDetails(projectName string) map[string]any {
 	if ConfigPath == "" {
 		return nil
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil
 	}
@@ -3570,13 +3952,14 @@ func GetProjectConfigDetails(projectName string) map[string]any {
 }
 
 // SaveProviderRefs updates provider_refs for a project.
-func SaveProviderRefs(projectName string, refs []string) error {
+func SaveProviderRefs(ctx context.Context, projectName string, refs []string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -3587,20 +3970,21 @@ func SaveProviderRefs(projectName string, refs []string) error {
 	for i := range cfg.Projects {
 		if cfg.Projects[i].Name == projectName {
 			cfg.Projects[i].Agent.ProviderRefs = refs
-			return saveConfig(cfg)
+			return saveConfigTo(ctx, path, cfg)
 		}
 	}
 	return fmt.Errorf("project %q not found", projectName)
 }
 
 // RemoveProject removes a project from the config file.
-func RemoveProject(projectName string) error {
+func RemoveProject(ctx context.Context, projectName string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -3619,19 +4003,20 @@ func RemoveProject(projectName string) error {
 	if !found {
 		return fmt.Errorf("project %q not found", projectName)
 	}
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
 }
 
 // AddPlatformToProject appends a platform config to a project.
 // If the project doesn't exist, it is created using agentType and workDir when provided,
 // otherwise agent config is cloned from the first existing project when present.
-func AddPlatformToProject(projectName string, platform PlatformConfig, workDir, agentType string) error {
+func AddPlatformToProject(ctx context.Context, projectName string, platform PlatformConfig, workDir, agentType string) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
 		return fmt.Errorf("config path not set")
 	}
-	data, err := os.ReadFile(ConfigPath)
+	path := projectConfigFile(projectName)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
@@ -3645,7 +4030,7 @@ func AddPlatformToProject(projectName string, platform PlatformConfig, workDir,
 	for i := range cfg.Projects {
 		if cfg.Projects[i].Name == projectName {
 			cfg.Projects[i].Platforms = append(cfg.Projects[i].Platforms, platform)
-			return saveConfig(cfg)
+			return saveConfigTo(ctx, path, cfg)
 		}
 	}
 	agentCfg := AgentConfig{Type: "codex", Options: map[string]any{}}
@@ -3668,12 +4053,21 @@ func AddPlatformToProject(projectName string, platform PlatformConfig, workDir,
 		Agent:     agentCfg,
 		Platforms: []PlatformConfig{platform},
 	})
-	return saveConfig(cfg)
+	return saveConfigTo(ctx, path, cfg)
+}
+
+func writeRawConfig(ctx context.Context, content string) error {
+	return writeRawConfigTo(ctx, ConfigPath, content)
+}
+
+func writeRawConfigTo(ctx context.Context, path, content string) error {
+	snapshotConfig(ctx, path, "")
+	return replaceConfigFile(path, formatTOML(content))
 }
 
-func writeRawConfig(content string) error {
-	content = formatTOML(content)
-	dir := filepath.Dir(ConfigPath)
+// replaceConfigFile atomically replaces path with content.
+func replaceConfigFile(path, content string) error {
+	dir := filepath.Dir(path)
 	tmp, err := os.CreateTemp(dir, ".config-*.tmp")
 	if err != nil {
 		return fmt.Errorf("create temp config: %w", err)
@@ -3693,12 +4087,12 @@ func writeRawConfig(content string) error {
 		os.Remove(tmpPath)
 		return err
 	}
-	return os.Rename(tmpPath, ConfigPath)
+	return os.Rename(tmpPath, path)
 }
 
 // FormatConfigFile reads the config file at the given path, formats it, and
 // writes it back. It validates the TOML syntax before writing.
-func FormatConfigFile(path string) error {
+func FormatConfigFile(ctx context.Context, path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Errorf("read config: %w", err)
@@ -3711,27 +4105,8 @@ func FormatConfigFile(path string) error {
 	if formatted == string(data) {
 		return nil
 	}
-	dir := filepath.Dir(path)
-	tmp, err := os.CreateTemp(dir, ".config-*.tmp")
-	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
-	}
-	tmpPath := tmp.Name()
-	if _, err := tmp.WriteString(formatted); err != nil {
-		tmp.Close()
-		os.Remove(tmpPath)
-		return fmt.Errorf("write formatted config: %w", err)
-	}
-	if err := tmp.Sync(); err != nil {
-		tmp.Close()
-		os.Remove(tmpPath)
-		return err
-	}
-	if err := tmp.Close(); err != nil {
-		os.Remove(tmpPath)
-		return err
-	}
-	return os.Rename(tmpPath, path)
+	snapshotConfig(ctx, path, "")
+	return replaceConfigFile(path, formatted)
 }
 
 // GetGlobalSettings reads global settings from config.toml.
@@ -3837,7 +4212,7 @@ type GlobalSettingsUpdate struct {
 }
 
 // SaveGlobalSettings persists global settings to config.toml.
-func SaveGlobalSettings(u GlobalSettingsUpdate) error {
+func SaveGlobalSettings(ctx context.Context, u GlobalSettingsUpdate) error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
@@ -3890,7 +4265,7 @@ func SaveGlobalSettings(u GlobalSettingsUpdate) error {
 	if u.QueueMaxDepth != nil {
 		cfg.Queue.MaxDepth = u.QueueMaxDepth
 	}
-	return saveConfig(cfg)
+	return saveConfig(ctx, cfg)
 }
 
 // WebSetupResult holds the config values after enabling web admin.
@@ -3904,7 +4279,7 @@ type WebSetupResult struct {
 
 // EnableWebAdmin enables the bridge and management sections in config.toml.
 // If already enabled, returns the existing config values without changes.
-func EnableWebAdmin(mgmtToken, bridgeToken string) (*WebSetupResult, error) {
+func EnableWebAdmin(ctx context.Context, mgmtToken, bridgeToken string) (*WebSetupResult, error) {
 	configMu.Lock()
 	defer configMu.Unlock()
 	if ConfigPath == "" {
@@ -3962,7 +4337,7 @@ func EnableWebAdmin(mgmtToken, bridgeToken string) (*WebSetupResult, error) {
 	}
 
 	if changed {
-		if err := saveConfig(cfg); err != nil {
+		if err := saveConfig(ctx, cfg); err != nil {
 			return nil, fmt.Errorf("save config: %w", err)
 		}
 	}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -30,6 +31,16 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: `projects[0].name is required`,
 		},
+		{
+			name: "rejects duplicate project names",
+			cfg: Config{
+				Projects: []ProjectConfig{
+					validProject("demo"),
+					validProject("demo"),
+				},
+			},
+			wantErr: `projects[1].name "demo" is already used by projects[0]`,
+		},
 		{
 			name: "requires agent type",
 			cfg: Config{
@@ -521,6 +532,21 @@ func TestEffectiveDisplayHideAgentFooter(t *testing.T) {
 	}
 }
 
+func TestEffectivePagedReplies(t *testing.T) {
+	tru := true
+	fal := false
+	if EffectivePagedReplies(&Config{}, &ProjectConfig{}) {
+		t.Error("default should be false")
+	}
+	global := &Config{Display: DisplayConfig{PagedReplies: &tru}}
+	if !EffectivePagedReplies(global, &ProjectConfig{}) {
+		t.Error("global true not applied")
+	}
+	if EffectivePagedReplies(global, &ProjectConfig{Display: &DisplayConfig{PagedReplies: &fal}}) {
+		t.Error("project should override global")
+	}
+}
+
 func TestValidateProjectDisplayConfig(t *testing.T) {
 	mode := "verbose"
 	cardMode := "modern"
@@ -717,7 +743,7 @@ func TestListProjects(t *testing.T) {
 func TestSaveLanguage(t *testing.T) {
 	writeTestConfig(t, baseConfigTOML)
 
-	if err := SaveLanguage("zh"); err != nil {
+	if err := SaveLanguage(context.Background(), "zh"); err != nil {
 		t.Fatalf("SaveLanguage() error: %v", err)
 	}
 
@@ -730,7 +756,7 @@ func TestSaveLanguage(t *testing.T) {
 func TestProviderConfig_SaveActiveProviderAndGetProjectProviders(t *testing.T) {
 	writeTestConfig(t, providerConfigTOML)
 
-	if err := SaveActiveProvider("demo", "backup"); err != nil {
+	if err := SaveActiveProvider(context.Background(), "demo", "backup"); err != nil {
 		t.Fatalf("SaveActiveProvider() error: %v", err)
 	}
 
@@ -750,10 +776,10 @@ func TestProviderConfig_AddAndRemove(t *testing.T) {
 	writeTestConfig(t, providerConfigTOML)
 
 	newProvider := ProviderConfig{Name: "relay", APIKey: "sk-relay", BaseURL: "https://example.com"}
-	if err := AddProviderToConfig("demo", newProvider); err != nil {
+	if err := AddProviderToConfig(context.Background(), "demo", newProvider); err != nil {
 		t.Fatalf("AddProviderToConfig() error: %v", err)
 	}
-	if err := AddProviderToConfig("demo", newProvider); err == nil {
+	if err := AddProviderToConfig(context.Background(), "demo", newProvider); err == nil {
 		t.Fatal("AddProviderToConfig() duplicate provider: expected error")
 	}
 
@@ -762,18 +788,34 @@ func TestProviderConfig_AddAndRemove(t *testing.T) {
 		t.Fatalf("provider count after add = %d, want 3", len(cfg.Projects[0].Agent.Providers))
 	}
 
-	if err := RemoveProviderFromConfig("demo", "relay"); err != nil {
+	if err := RemoveProviderFromConfig(context.Background(), "demo", "relay"); err != nil {
 		t.Fatalf("RemoveProviderFromConfig() error: %v", err)
 	}
-	if err := RemoveProviderFromConfig("demo", "relay"); err == nil {
+	if err := RemoveProviderFromConfig(context.Background(), "demo", "relay"); err == nil {
 		t.Fatal("RemoveProviderFromConfig() missing provider: expected error")
 	}
 }
 
+func TestProviderConfig_RemoveDropsFailoverEntry(t *testing.T) {
+	writeTestConfig(t, strings.Replace(providerConfigTOML, `type = "claudecode"`,
+		"type = \"claudecode\"\nprovider_failover = [\"primary\", \"backup\"]\nprovider_failover_cooldown_mins = 5", 1))
+
+	if err := RemoveProviderFromConfig(context.Background(), "demo", "backup"); err != nil {
+		t.Fatalf("RemoveProviderFromConfig() error: %v", err)
+	}
+	agent := readTestConfig(t).Projects[0].Agent
+	if len(agent.ProviderFailover) != 1 || agent.ProviderFailover[0] != "primary" {
+		t.Fatalf("provider_failover = %v, want [primary]", agent.ProviderFailover)
+	}
+	if agent.ProviderFailoverCooldownMins == nil || *agent.ProviderFailoverCooldownMins != 5 {
+		t.Fatalf("provider_failover_cooldown_mins = %v, want 5", agent.ProviderFailoverCooldownMins)
+	}
+}
+
 func TestProviderConfig_SaveProviderModel(t *testing.T) {
 	writeTestConfig(t, providerConfigTOML)
 
-	if err := SaveProviderModel("demo", "primary", "gpt-5.4"); err != nil {
+	if err := SaveProviderModel(context.Background(), "demo", "primary", "gpt-5.4"); err != nil {
 		t.Fatalf("SaveProviderModel() error: %v", err)
 	}
 
@@ -781,7 +823,7 @@ func TestProviderConfig_SaveProviderModel(t *testing.T) {
 	if got := cfg.Projects[0].Agent.Providers[0].Model; got != "gpt-5.4" {
 		t.Fatalf("provider model = %q, want gpt-5.4", got)
 	}
-	if err := SaveProviderModel("demo", "missing", "gpt-4.1"); err == nil {
+	if err := SaveProviderModel(context.Background(), "demo", "missing", "gpt-4.1"); err == nil {
 		t.Fatal("SaveProviderModel() missing provider: expected error")
 	}
 }
@@ -789,7 +831,7 @@ func TestProviderConfig_SaveProviderModel(t *testing.T) {
 func TestSaveAgentModel(t *testing.T) {
 	writeTestConfig(t, providerConfigTOML)
 
-	if err := SaveAgentModel("demo", "gpt-5.4"); err != nil {
+	if err := SaveAgentModel(context.Background(), "demo", "gpt-5.4"); err != nil {
 		t.Fatalf("SaveAgentModel() error: %v", err)
 	}
 
@@ -842,7 +884,7 @@ token = "test-token"
 func TestSaveActiveProvider_PreservesCommentsAndUnknownFields(t *testing.T) {
 	writeTestConfig(t, providerConfigWithCommentsTOML)
 
-	if err := SaveActiveProvider("demo", "backup"); err != nil {
+	if err := SaveActiveProvider(context.Background(), "demo", "backup"); err != nil {
 		t.Fatalf("SaveActiveProvider() error: %v", err)
 	}
 
@@ -887,7 +929,7 @@ func TestSaveActiveProvider_PreservesCommentsAndUnknownFields(t *testing.T) {
 func TestSaveAgentModel_PreservesCommentsAndUnknownFields(t *testing.T) {
 	writeTestConfig(t, providerConfigWithCommentsTOML)
 
-	if err := SaveAgentModel("demo", "gpt-5.4"); err != nil {
+	if err := SaveAgentModel(context.Background(), "demo", "gpt-5.4"); err != nil {
 		t.Fatalf("SaveAgentModel() error: %v", err)
 	}
 
@@ -914,7 +956,7 @@ func TestSaveAgentModel_PreservesCommentsAndUnknownFields(t *testing.T) {
 func TestSaveProviderModel_PreservesCommentsAndUnknownFields(t *testing.T) {
 	writeTestConfig(t, providerConfigWithCommentsTOML)
 
-	if err := SaveProviderModel("demo", "primary", "gpt-5.4"); err != nil {
+	if err := SaveProviderModel(context.Background(), "demo", "primary", "gpt-5.4"); err != nil {
 		t.Fatalf("SaveProviderModel() error: %v", err)
 	}
 
@@ -938,7 +980,7 @@ func TestSaveProviderModel_PreservesCommentsAndUnknownFields(t *testing.T) {
 func TestSaveLanguage_PreservesComments(t *testing.T) {
 	writeTestConfig(t, providerConfigWithCommentsTOML)
 
-	if err := SaveLanguage("zh"); err != nil {
+	if err := SaveLanguage(context.Background(), "zh"); err != nil {
 		t.Fatalf("SaveLanguage() error: %v", err)
 	}
 
@@ -975,7 +1017,7 @@ custom_display = "keep" # also keep
 
 	thinking := 200
 	toolShow := false
-	if err := SaveDisplayConfig(nil, nil, &thinking, nil, &toolShow); err != nil {
+	if err := SaveDisplayConfig(context.Background(), nil, nil, &thinking, nil, &toolShow); err != nil {
 		t.Fatalf("SaveDisplayConfig() error: %v", err)
 	}
 
@@ -1010,7 +1052,7 @@ tts_mode = "auto"
 `
 	writeTestConfig(t, configWithTTS)
 
-	if err := SaveTTSMode("always"); err != nil {
+	if err := SaveTTSMode(context.Background(), "always"); err != nil {
 		t.Fatalf("SaveTTSMode() error: %v", err)
 	}
 
@@ -1162,7 +1204,7 @@ app_id = "beta-app"
 func TestSaveActiveProvider_MultiProject(t *testing.T) {
 	writeTestConfig(t, multiProjectConfigTOML)
 
-	if err := SaveActiveProvider("beta", "openai"); err != nil {
+	if err := SaveActiveProvider(context.Background(), "beta", "openai"); err != nil {
 		t.Fatalf("SaveActiveProvider() error: %v", err)
 	}
 
@@ -1211,7 +1253,7 @@ token = "demo-token"
 func TestSaveProviderModel_GlobalProviderRef(t *testing.T) {
 	writeTestConfig(t, globalProviderRefConfigTOML)
 
-	if err := SaveProviderModel("demo", "shared-openai", "gpt-5"); err != nil {
+	if err := SaveProviderModel(context.Background(), "demo", "shared-openai", "gpt-5"); err != nil {
 		t.Fatalf("SaveProviderModel() error: %v", err)
 	}
 
@@ -1241,10 +1283,10 @@ func TestCommandConfig_AddAndRemove(t *testing.T) {
 	writeTestConfig(t, baseConfigTOML)
 
 	cmd := CommandConfig{Name: "review", Description: "code review", Prompt: "review {{args}}"}
-	if err := AddCommand(cmd); err != nil {
+	if err := AddCommand(context.Background(), cmd); err != nil {
 		t.Fatalf("AddCommand() error: %v", err)
 	}
-	if err := AddCommand(cmd); err == nil {
+	if err := AddCommand(context.Background(), cmd); err == nil {
 		t.Fatal("AddCommand() duplicate command: expected error")
 	}
 
@@ -1253,10 +1295,10 @@ func TestCommandConfig_AddAndRemove(t *testing.T) {
 		t.Fatalf("commands after add = %#v, want one review command", cfg.Commands)
 	}
 
-	if err := RemoveCommand("review"); err != nil {
+	if err := RemoveCommand(context.Background(), "review"); err != nil {
 		t.Fatalf("RemoveCommand() error: %v", err)
 	}
-	if err := RemoveCommand("review"); err == nil {
+	if err := RemoveCommand(context.Background(), "review"); err == nil {
 		t.Fatal("RemoveCommand() missing command: expected error")
 	}
 }
@@ -1264,10 +1306,10 @@ func TestCommandConfig_AddAndRemove(t *testing.T) {
 func TestAliasConfig_AddAndRemove(t *testing.T) {
 	writeTestConfig(t, baseConfigTOML)
 
-	if err := AddAlias(AliasConfig{Name: "帮助", Command: "/help"}); err != nil {
+	if err := AddAlias(context.Background(), AliasConfig{Name: "帮助", Command: "/help"}); err != nil {
 		t.Fatalf("AddAlias() error: %v", err)
 	}
-	if err := AddAlias(AliasConfig{Name: "帮助", Command: "/list"}); err != nil {
+	if err := AddAlias(context.Background(), AliasConfig{Name: "帮助", Command: "/list"}); err != nil {
 		t.Fatalf("AddAlias() update error: %v", err)
 	}
 
@@ -1276,10 +1318,10 @@ func TestAliasConfig_AddAndRemove(t *testing.T) {
 		t.Fatalf("aliases after update = %#v, want one updated alias", cfg.Aliases)
 	}
 
-	if err := RemoveAlias("帮助"); err != nil {
+	if err := RemoveAlias(context.Background(), "帮助"); err != nil {
 		t.Fatalf("RemoveAlias() error: %v", err)
 	}
-	if err := RemoveAlias("帮助"); err == nil {
+	if err := RemoveAlias(context.Background(), "帮助"); err == nil {
 		t.Fatal("RemoveAlias() missing alias: expected error")
 	}
 }
@@ -1290,7 +1332,7 @@ func TestDisplayConfig_Save(t *testing.T) {
 	thinking := 120
 	tool := 240
 	showTools := false
-	if err := SaveDisplayConfig(nil, nil, &thinking, &tool, &showTools); err != nil {
+	if err := SaveDisplayConfig(context.Background(), nil, nil, &thinking, &tool, &showTools); err != nil {
 		t.Fatalf("SaveDisplayConfig() error: %v", err)
 	}
 
@@ -1306,7 +1348,7 @@ func TestDisplayConfig_Save(t *testing.T) {
 	}
 
 	thinking = 360
-	if err := SaveDisplayConfig(nil, nil, &thinking, nil, nil); err != nil {
+	if err := SaveDisplayConfig(context.Background(), nil, nil, &thinking, nil, nil); err != nil {
 		t.Fatalf("SaveDisplayConfig() second update error: %v", err)
 	}
 
@@ -1325,7 +1367,7 @@ func TestDisplayConfig_Save(t *testing.T) {
 func TestTTSConfig_SaveMode(t *testing.T) {
 	writeTestConfig(t, baseConfigTOML)
 
-	if err := SaveTTSMode("always"); err != nil {
+	if err := SaveTTSMode(context.Background(), "always"); err != nil {
 		t.Fatalf("SaveTTSMode() error: %v", err)
 	}
 
@@ -1419,7 +1461,7 @@ func TestSaveFeishuPlatformCredentials_UpdateFirstCandidateAndAllowFrom(t *testi
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := SaveFeishuPlatformCredentials(FeishuCredentialUpdateOptions{
+	result, err := SaveFeishuPlatformCredentials(context.Background(), FeishuCredentialUpdateOptions{
 		ProjectName:       "alpha",
 		AppID:             "cli_new_app",
 		AppSecret:         "sec_new_secret",
@@ -1460,7 +1502,7 @@ func TestSaveFeishuPlatformCredentials_SelectByIndexAndOverrideType(t *testing.T
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := SaveFeishuPlatformCredentials(FeishuCredentialUpdateOptions{
+	result, err := SaveFeishuPlatformCredentials(context.Background(), FeishuCredentialUpdateOptions{
 		ProjectName:       "alpha",
 		PlatformIndex:     2,
 		PlatformType:      "feishu",
@@ -1503,7 +1545,7 @@ func TestSaveFeishuPlatformCredentials_AppendsOwnerToAllowFrom(t *testing.T) {
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := SaveFeishuPlatformCredentials(FeishuCredentialUpdateOptions{
+	result, err := SaveFeishuPlatformCredentials(context.Background(), FeishuCredentialUpdateOptions{
 		ProjectName:       "alpha",
 		PlatformIndex:     2,
 		PlatformType:      "feishu",
@@ -1531,7 +1573,7 @@ func TestSaveFeishuPlatformCredentials_LeavesWildcardAllowFromUnchanged(t *testi
 	configPath := writeConfigFixture(t, strings.Replace(feishuConfigFixture, `allow_from = "ou_existing_owner"`, `allow_from = "*"`, 1))
 	patchConfigPath(t, configPath)
 
-	result, err := SaveFeishuPlatformCredentials(FeishuCredentialUpdateOptions{
+	result, err := SaveFeishuPlatformCredentials(context.Background(), FeishuCredentialUpdateOptions{
 		ProjectName:       "alpha",
 		PlatformIndex:     2,
 		OwnerOpenID:       "ou_new_owner",
@@ -1558,7 +1600,7 @@ func TestSaveFeishuPlatformCredentials_ReturnsIndexRangeError(t *testing.T) {
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	_, err := SaveFeishuPlatformCredentials(FeishuCredentialUpdateOptions{
+	_, err := SaveFeishuPlatformCredentials(context.Background(), FeishuCredentialUpdateOptions{
 		ProjectName:   "alpha",
 		PlatformIndex: 3,
 		AppID:         "cli_any",
@@ -1576,7 +1618,7 @@ func TestEnsureProjectWithFeishuPlatform_CreatesMissingProject(t *testing.T) {
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := EnsureProjectWithFeishuPlatform(EnsureProjectWithFeishuOptions{
+	result, err := EnsureProjectWithFeishuPlatform(context.Background(), EnsureProjectWithFeishuOptions{
 		ProjectName:  "gamma",
 		PlatformType: "lark",
 		WorkDir:      "/tmp/gamma",
@@ -1614,7 +1656,7 @@ func TestEnsureProjectWithFeishuPlatform_AddsPlatformWhenProjectExistsWithoutFei
 	configPath := writeConfigFixture(t, projectWithoutFeishuFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := EnsureProjectWithFeishuPlatform(EnsureProjectWithFeishuOptions{
+	result, err := EnsureProjectWithFeishuPlatform(context.Background(), EnsureProjectWithFeishuOptions{
 		ProjectName:  "beta",
 		PlatformType: "feishu",
 	})
@@ -1642,7 +1684,7 @@ func TestSaveFeishuPlatformCredentials_PreservesCommentsAndUnknownFields(t *test
 	configPath := writeConfigFixture(t, preserveFormatFixture)
 	patchConfigPath(t, configPath)
 
-	_, err := SaveFeishuPlatformCredentials(FeishuCredentialUpdateOptions{
+	_, err := SaveFeishuPlatformCredentials(context.Background(), FeishuCredentialUpdateOptions{
 		ProjectName: "alpha",
 		AppID:       "cli_new_app",
 		AppSecret:   "sec_new_secret",
@@ -1724,6 +1766,47 @@ func TestLoad_RejectsNegativeResetOnIdleMins(t *testing.T) {
 	}
 }
 
+func TestLoad_ParsesNamedHeartbeats(t *testing.T) {
+	configPath := writeConfigFixture(t, projectWithNamedHeartbeatsFixture)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	hbs := cfg.Projects[0].AllHeartbeats()
+	if len(hbs) != 3 {
+		t.Fatalf("AllHeartbeats() = %d entries, want 3", len(hbs))
+	}
+	if hbs[0].Name != DefaultHeartbeatName || hbs[0].SessionKey != "telegram:1:1" {
+		t.Errorf("legacy heartbeat = %q/%q, want default/telegram:1:1", hbs[0].Name, hbs[0].SessionKey)
+	}
+	if hbs[1].Name != "inbox" || hbs[1].PromptFile != "prompts/inbox.md" {
+		t.Errorf("heartbeat[1] = %q/%q", hbs[1].Name, hbs[1].PromptFile)
+	}
+	if hbs[2].Name != "builds" || hbs[2].IntervalMins == nil || *hbs[2].IntervalMins != 120 {
+		t.Errorf("heartbeat[2] = %+v", hbs[2])
+	}
+}
+
+func TestLoad_RejectsInvalidHeartbeatNames(t *testing.T) {
+	for _, tc := range []struct {
+		name, extra, want string
+	}{
+		{"duplicate", "[[projects.heartbeats]]\nname = \"inbox\"\n[[projects.heartbeats]]\nname = \"inbox\"\n", "duplicate heartbeat name"},
+		{"unnamed twice", "[projects.heartbeat]\nenabled = true\n[[projects.heartbeats]]\nenabled = true\n", "duplicate heartbeat name"},
+		{"reserved", "[[projects.heartbeats]]\nname = \"pause\"\n", "reserved"},
+		{"slash", "[[projects.heartbeats]]\nname = \"a/b\"\n", "must not contain"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			configPath := writeConfigFixture(t, projectWithResetOnIdleFixture+tc.extra)
+			_, err := Load(configPath)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Load error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
 func TestLoad_ParsesAgentSessionIdleTimeoutMins(t *testing.T) {
 	configPath := writeConfigFixture(t, projectWithAgentSessionIdleTimeoutFixture)
 
@@ -1999,6 +2082,198 @@ func TestLoadRejectsNegativeRelayTimeout(t *testing.T) {
 	}
 }
 
+func TestLoadRejectsNegativeProviderHealthSettings(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1", "[provider_health]\nenabled = true\nfailure_threshold = -2", 1)
+	_, err := Load(writeConfigFixture(t, fixture))
+	if err == nil || !strings.Contains(err.Error(), "provider_health.failure_threshold must be >= 0") {
+		t.Fatalf("err = %v, want provider_health.failure_threshold error", err)
+	}
+}
+
+func TestLoadProviderKeyPool(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", "", 1) + `
+[[projects.agent.providers]]
+name = "pool"
+api_key = "sk-1"
+api_keys = ["sk-2", "sk-3"]
+key_rotation = "least_limited"
+key_cooldown_secs = 60
+`
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	p := cfg.Projects[0].Agent.Providers[0]
+	if len(p.APIKeys) != 2 || p.KeyRotation != "least_limited" || p.KeyCooldownSecs == nil || *p.KeyCooldownSecs != 60 {
+		t.Fatalf("provider = %+v", p)
+	}
+
+	bad := strings.Replace(fixture, `"least_limited"`, `"random"`, 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "key_rotation") {
+		t.Fatalf("err = %v, want key_rotation error", err)
+	}
+}
+
+func TestLoadProviderProxyRules(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", "", 1) + `
+[[projects.agent.providers]]
+name = "vendor"
+base_url = "https://vendor.example.com"
+
+[projects.agent.providers.proxy]
+protocol = "openai"
+model_map = { "claude-sonnet-*" = "vendor/sonnet" }
+strip_fields = ["cache_control"]
+strip_betas = ["context-1m-2025-08-07"]
+headers = { "X-Org" = "acme" }
+max_tokens = 8192
+log_traffic = true
+`
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	px := cfg.Projects[0].Agent.Providers[0].Proxy
+	if px == nil || px.Protocol != "openai" || px.ModelMap["claude-sonnet-*"] != "vendor/sonnet" || px.StripFields[0] != "cache_control" ||
+		px.Headers["X-Org"] != "acme" || px.MaxTokens != 8192 || !px.LogTraffic {
+		t.Fatalf("proxy = %+v", px)
+	}
+
+	bad := strings.Replace(fixture, "max_tokens = 8192", "max_tokens = -1", 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "proxy.max_tokens") {
+		t.Fatalf("err = %v, want proxy.max_tokens error", err)
+	}
+	bad = strings.Replace(fixture, `protocol = "openai"`, `protocol = "grpc"`, 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "proxy.protocol") {
+		t.Fatalf("err = %v, want proxy.protocol error", err)
+	}
+	bad = strings.Replace(fixture, "log_traffic = true", "cassette_mode = \"replay\"", 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "proxy.cassette_dir") {
+		t.Fatalf("err = %v, want proxy.cassette_dir error", err)
+	}
+}
+
+func TestLoadAutoAttachConfig(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", "", 1) + `
+[projects.auto_attach]
+enabled = true
+patterns = ["*.pdf", "out/*.png"]
+max_file_size_mb = 5
+`
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	aa := cfg.Projects[0].AutoAttach
+	if aa.Enabled == nil || !*aa.Enabled || len(aa.Patterns) != 2 || aa.MaxFileSizeMB == nil || *aa.MaxFileSizeMB != 5 || aa.MaxFiles != nil {
+		t.Fatalf("auto_attach = %+v", aa)
+	}
+
+	bad := strings.Replace(fixture, `"*.pdf"`, `"[.pdf"`, 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "auto_attach.patterns") {
+		t.Fatalf("err = %v, want auto_attach.patterns error", err)
+	}
+}
+
+func TestLoadAttachmentConvertConfig(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", `[attachment_convert]
+enabled = true
+pdftotext = "/usr/bin/pdftotext"
+max_archive_files = 50
+`, 1)
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ac := cfg.AttachmentConvert
+	if ac.Enabled == nil || !*ac.Enabled || ac.PDFToText != "/usr/bin/pdftotext" || ac.MaxArchiveFiles == nil || *ac.MaxArchiveFiles != 50 || ac.MaxTextKB != nil {
+		t.Fatalf("attachment_convert = %+v", ac)
+	}
+
+	bad := strings.Replace(fixture, "max_archive_files = 50", "max_archive_size_mb = -1", 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "attachment_convert.max_archive_size_mb") {
+		t.Fatalf("err = %v, want attachment_convert.max_archive_size_mb error", err)
+	}
+}
+
+func TestLoadLocalSpeechConfig(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", `[speech]
+enabled = true
+provider = "local"
+chunk_secs = 120
+max_parallel = 2
+
+[speech.local]
+engine = "faster-whisper"
+model = "/models/small"
+timeout_secs = 60
+`, 1)
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if l := cfg.Speech.Local; l.Engine != "faster-whisper" || l.Model != "/models/small" || l.TimeoutSecs != 60 {
+		t.Fatalf("speech.local = %+v", l)
+	}
+	if cfg.Speech.ChunkSecs != 120 || cfg.Speech.MaxParallel != 2 {
+		t.Fatalf("speech chunking = %d secs x %d", cfg.Speech.ChunkSecs, cfg.Speech.MaxParallel)
+	}
+	neg := strings.Replace(fixture, "max_parallel = 2", "max_parallel = -1", 1)
+	if _, err := Load(writeConfigFixture(t, neg)); err == nil || !strings.Contains(err.Error(), "speech.max_parallel") {
+		t.Fatalf("err = %v, want speech.max_parallel error", err)
+	}
+
+	bad := strings.Replace(fixture, `"faster-whisper"`, `"vosk"`, 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "speech.local.engine") {
+		t.Fatalf("err = %v, want speech.local.engine error", err)
+	}
+}
+
+func TestLoadTTSStreamingConfig(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", `[tts]
+enabled = true
+provider = "edge"
+streaming = true
+
+[tts.cache]
+enabled = true
+max_size_mb = 50
+`, 1)
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.TTS.Streaming || !cfg.TTS.Cache.Enabled || cfg.TTS.Cache.MaxSizeMB != 50 {
+		t.Fatalf("tts = streaming %v, cache %+v", cfg.TTS.Streaming, cfg.TTS.Cache)
+	}
+
+	bad := strings.Replace(fixture, "max_size_mb = 50", "max_size_mb = -1", 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "tts.cache.max_size_mb") {
+		t.Fatalf("err = %v, want tts.cache.max_size_mb error", err)
+	}
+}
+
+func TestLoadOCRConfig(t *testing.T) {
+	fixture := strings.Replace(relayConfigNegativeFixture, "[relay]\ntimeout_secs = -1\n", `[ocr]
+enabled = true
+
+[ocr.tesseract]
+languages = "eng+chi_sim"
+`, 1)
+	cfg, err := Load(writeConfigFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.OCR.Enabled || cfg.OCR.Tesseract.Languages != "eng+chi_sim" {
+		t.Fatalf("ocr = %+v", cfg.OCR)
+	}
+
+	bad := strings.Replace(fixture, "enabled = true\n", "enabled = true\nprovider = \"cloud\"\n", 1)
+	if _, err := Load(writeConfigFixture(t, bad)); err == nil || !strings.Contains(err.Error(), "ocr.provider") {
+		t.Fatalf("err = %v, want ocr.provider error", err)
+	}
+}
+
 func TestLoadRejectsInvalidRelayVisibility(t *testing.T) {
 	configPath := writeConfigFixture(t, relayConfigInvalidVisibilityFixture)
 
@@ -2162,6 +2437,40 @@ type = "telegram"
 bot_token = "token_xxx"
 `
 
+const projectWithNamedHeartbeatsFixture = `
+[[projects]]
+name = "beta"
+
+[projects.agent]
+type = "codex"
+
+[projects.agent.options]
+work_dir = "/tmp/beta"
+
+[[projects.platforms]]
+type = "telegram"
+
+[projects.platforms.options]
+bot_token = "token_xxx"
+
+[projects.heartbeat]
+enabled = true
+session_key = "telegram:1:1"
+
+[[projects.heartbeats]]
+name = "inbox"
+enabled = true
+session_key = "telegram:1:1"
+prompt_file = "prompts/inbox.md"
+
+[[projects.heartbeats]]
+name = "builds"
+enabled = true
+session_key = "telegram:-100:0"
+interval_mins = 120
+only_when_idle = false
+`
+
 const projectWithNegativeResetOnIdleFixture = `
 [[projects]]
 name = "beta"
@@ -2631,7 +2940,7 @@ func TestEnsureProjectWithWeixinPlatform_CreatesMissingProject(t *testing.T) {
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := EnsureProjectWithWeixinPlatform(EnsureProjectWithWeixinOptions{
+	result, err := EnsureProjectWithWeixinPlatform(context.Background(), EnsureProjectWithWeixinOptions{
 		ProjectName: "gamma",
 		WorkDir:     "/tmp/gamma",
 	})
@@ -2665,7 +2974,7 @@ func TestEnsureProjectWithWeixinPlatform_AddsPlatformWhenMissing(t *testing.T) {
 	configPath := writeConfigFixture(t, projectWithoutFeishuFixture)
 	patchConfigPath(t, configPath)
 
-	result, err := EnsureProjectWithWeixinPlatform(EnsureProjectWithWeixinOptions{
+	result, err := EnsureProjectWithWeixinPlatform(context.Background(), EnsureProjectWithWeixinOptions{
 		ProjectName: "beta",
 	})
 	if err != nil {
@@ -2692,7 +3001,7 @@ func TestSaveWeixinPlatformCredentials_UpdateToken(t *testing.T) {
 	configPath := writeConfigFixture(t, weixinConfigFixture)
 	patchConfigPath(t, configPath)
 
-	_, err := SaveWeixinPlatformCredentials(WeixinCredentialUpdateOptions{
+	_, err := SaveWeixinPlatformCredentials(context.Background(), WeixinCredentialUpdateOptions{
 		ProjectName: "alpha",
 		Token:       "new_weixin_token",
 		BaseURL:     "https://ilinkai.weixin.qq.com",
@@ -2716,7 +3025,7 @@ func TestSaveWeixinPlatformCredentials_AppendsScannedUserToAllowFrom(t *testing.
 	configPath := writeConfigFixture(t, strings.Replace(weixinConfigFixture, `base_url = "https://ilink.example"`, "base_url = \"https://ilink.example\"\nallow_from = \"wx_user_1\"", 1))
 	patchConfigPath(t, configPath)
 
-	result, err := SaveWeixinPlatformCredentials(WeixinCredentialUpdateOptions{
+	result, err := SaveWeixinPlatformCredentials(context.Background(), WeixinCredentialUpdateOptions{
 		ProjectName:       "alpha",
 		Token:             "new_weixin_token",
 		ScannedUserID:     "wx_user_2",
@@ -2740,7 +3049,7 @@ func TestSaveWeixinPlatformCredentials_LeavesWildcardAllowFromUnchanged(t *testi
 	configPath := writeConfigFixture(t, strings.Replace(weixinConfigFixture, `base_url = "https://ilink.example"`, "base_url = \"https://ilink.example\"\nallow_from = \"*\"", 1))
 	patchConfigPath(t, configPath)
 
-	result, err := SaveWeixinPlatformCredentials(WeixinCredentialUpdateOptions{
+	result, err := SaveWeixinPlatformCredentials(context.Background(), WeixinCredentialUpdateOptions{
 		ProjectName:       "alpha",
 		Token:             "new_weixin_token",
 		ScannedUserID:     "wx_user_2",
@@ -2768,7 +3077,7 @@ func TestSaveProjectSettings_ExtraFields(t *testing.T) {
 	hideWorkdir := false
 	wd := "/tmp/patched"
 	mode := "yolo"
-	err := SaveProjectSettings("alpha", ProjectSettingsUpdate{
+	err := SaveProjectSettings(context.Background(), "alpha", ProjectSettingsUpdate{
 		WorkDir:              &wd,
 		Mode:                 &mode,
 		ShowContextIndicator: &show,
@@ -2822,7 +3131,7 @@ func TestAddPlatformToProject_NewProjectWithAgentTypeAndWorkDir(t *testing.T) {
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	err := AddPlatformToProject("sigma", PlatformConfig{Type: "slack", Options: map[string]any{"token": "x"}}, "/sigma", "gemini")
+	err := AddPlatformToProject(context.Background(), "sigma", PlatformConfig{Type: "slack", Options: map[string]any{"token": "x"}}, "/sigma", "gemini")
 	if err != nil {
 		t.Fatalf("AddPlatformToProject: %v", err)
 	}
@@ -2849,7 +3158,7 @@ func TestAddPlatformToProject_NewProjectClonesAgentWhenAgentTypeEmpty(t *testing
 	configPath := writeConfigFixture(t, feishuConfigFixture)
 	patchConfigPath(t, configPath)
 
-	err := AddPlatformToProject("tau", PlatformConfig{Type: "slack", Options: map[string]any{"token": "x"}}, "", "")
+	err := AddPlatformToProject(context.Background(), "tau", PlatformConfig{Type: "slack", Options: map[string]any{"token": "x"}}, "", "")
 	if err != nil {
 		t.Fatalf("AddPlatformToProject: %v", err)
 	}
@@ -2915,7 +3224,7 @@ func TestFormatConfigFile(t *testing.T) {
 	messy := "language = \"en\"   \n\n\n\n[[projects]]\nname = \"test\"\n\n\n[projects.agent]\ntype = \"codex\"\n\n[projects.agent.options]\n\n[[projects.platforms]]\ntype = \"telegram\"\n\n[projects.platforms.options]\ntoken = \"abc\"\n"
 	os.WriteFile(path, []byte(messy), 0o644)
 
-	if err := FormatConfigFile(path); err != nil {
+	if err := FormatConfigFile(context.Background(), path); err != nil {
 		t.Fatalf("FormatConfigFile: %v", err)
 	}
 
@@ -2939,7 +3248,7 @@ func TestFormatConfigFile(t *testing.T) {
 
 	t.Run("no-op when already formatted", func(t *testing.T) {
 		before, _ := os.ReadFile(path)
-		if err := FormatConfigFile(path); err != nil {
+		if err := FormatConfigFile(context.Background(), path); err != nil {
 			t.Fatalf("second FormatConfigFile: %v", err)
 		}
 		after, _ := os.ReadFile(path)
@@ -2951,7 +3260,7 @@ func TestFormatConfigFile(t *testing.T) {
 	t.Run("rejects invalid TOML", func(t *testing.T) {
 		badPath := filepath.Join(dir, "bad.toml")
 		os.WriteFile(badPath, []byte("[invalid\n"), 0o644)
-		if err := FormatConfigFile(badPath); err == nil {
+		if err := FormatConfigFile(context.Background(), badPath); err == nil {
 			t.Error("expected error for invalid TOML")
 		}
 	})
@@ -3451,7 +3760,7 @@ func TestRemoveGlobalProvider_CleansUpProviderRefs(t *testing.T) {
 `
 	writeTestConfig(t, input)
 
-	if err := RemoveGlobalProvider("prov-a"); err != nil {
+	if err := RemoveGlobalProvider(context.Background(), "prov-a"); err != nil {
 		t.Fatalf("RemoveGlobalProvider: %v", err)
 	}
 
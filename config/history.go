@@ -0,0 +1,350 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// maxConfigHistory is how many snapshots are kept; older ones are pruned.
+const maxConfigHistory = 50
+
+// ConfigVersion describes one snapshot in the config history. A snapshot
+// holds a config file as it was just before a programmatic write, so
+// rolling back to version n undoes change n and everything after it.
+type ConfigVersion struct {
+	ID     int       `json:"id"`
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`            // who made the change, e.g. "api", "cli" or "telegram:12345"
+	Reason string    `json:"reason,omitempty"` // what was changed, e.g. "/provider add relay"
+	File   string    `json:"file"`             // config file the snapshot was taken of
+	Size   int       `json:"size"`
+}
+
+// ChangeSource says who made a config change and why.
+type ChangeSource struct {
+	Actor  string // e.g. "api", "cli" or "telegram:12345"
+	Reason string // e.g. "/provider add relay"
+}
+
+type changeSourceKey struct{}
+
+// WithChangeSource returns a copy of ctx that attributes the config writes
+// made with it to src. Writes made with a context that carries no source
+// are recorded with actor "cc-connect".
+func WithChangeSource(ctx context.Context, src ChangeSource) context.Context {
+	return context.WithValue(ctx, changeSourceKey{}, src)
+}
+
+// changeSourceFrom returns the source carried by ctx, or the zero value.
+func changeSourceFrom(ctx context.Context) ChangeSource {
+	src, _ := ctx.Value(changeSourceKey{}).(ChangeSource)
+	return src
+}
+
+// configHistoryDir returns the directory holding snapshots of the config
+// files that belong to the main config file.
+func configHistoryDir() string {
+	if ConfigPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(ConfigPath), ".config-history")
+}
+
+// snapshotConfig saves the current content of path to the config history
+// before it is overwritten. Failures are logged and never block the write.
+// The snapshot is attributed to the change source carried by ctx; reason,
+// when non-empty, replaces the source's reason. The caller must hold configMu.
+func snapshotConfig(ctx context.Context, path, reason string) {
+	dir := configHistoryDir()
+	if dir == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return // nothing to snapshot yet
+	}
+	src := changeSourceFrom(ctx)
+	actor := src.Actor
+	if actor == "" {
+		actor = "cc-connect"
+	}
+	if reason == "" {
+		reason = src.Reason
+	}
+	if err := appendConfigVersion(dir, path, actor, reason, data); err != nil {
+		slog.Warn("config: could not record config history", "file", path, "error", err)
+	}
+}
+
+func appendConfigVersion(dir, path, actor, reason string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	versions, err := readConfigHistory(dir)
+	if err != nil {
+		return err
+	}
+	id := 1
+	if len(versions) > 0 {
+		id = versions[len(versions)-1].ID + 1
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	v := ConfigVersion{ID: id, Time: time.Now(), Actor: actor, Reason: reason, File: path, Size: len(data)}
+	if err := os.WriteFile(snapshotPath(dir, id), data, 0o600); err != nil {
+		return err
+	}
+	versions = append(versions, v)
+	for len(versions) > maxConfigHistory {
+		os.Remove(snapshotPath(dir, versions[0].ID))
+		versions = versions[1:]
+	}
+	return writeConfigHistory(dir, versions)
+}
+
+func snapshotPath(dir string, id int) string {
+	return filepath.Join(dir, fmt.Sprintf("%06d.toml", id))
+}
+
+func readConfigHistory(dir string) ([]ConfigVersion, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []ConfigVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("parse config history: %w", err)
+	}
+	return versions, nil
+}
+
+func writeConfigHistory(dir string, versions []ConfigVersion) error {
+	data, err := json.MarshalIndent(versions, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, "index.json.tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, "index.json"))
+}
+
+// ConfigHistory returns the recorded config versions, newest first.
+func ConfigHistory() ([]ConfigVersion, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	dir := configHistoryDir()
+	if dir == "" {
+		return nil, fmt.Errorf("config path not set")
+	}
+	versions, err := readConfigHistory(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+		versions[i], versions[j] = versions[j], versions[i]
+	}
+	return versions, nil
+}
+
+// lookupConfigVersion returns version id, its content and the versions
+// recorded after it. The caller must hold configMu.
+func lookupConfigVersion(id int) (ConfigVersion, string, []ConfigVersion, error) {
+	dir := configHistoryDir()
+	if dir == "" {
+		return ConfigVersion{}, "", nil, fmt.Errorf("config path not set")
+	}
+	versions, err := readConfigHistory(dir)
+	if err != nil {
+		return ConfigVersion{}, "", nil, err
+	}
+	for i, v := range versions {
+		if v.ID != id {
+			continue
+		}
+		data, err := os.ReadFile(snapshotPath(dir, id))
+		if err != nil {
+			return ConfigVersion{}, "", nil, fmt.Errorf("read config version %d: %w", id, err)
+		}
+		return v, string(data), versions[i+1:], nil
+	}
+	return ConfigVersion{}, "", nil, fmt.Errorf("config version %d not found", id)
+}
+
+// DiffConfigVersion shows what change id did: the snapshot taken before it
+// against the next snapshot of the same file, or the file as it is now.
+func DiffConfigVersion(id int) (string, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	v, before, later, err := lookupConfigVersion(id)
+	if err != nil {
+		return "", err
+	}
+	for _, next := range later {
+		if next.File == v.File {
+			_, after, _, err := lookupConfigVersion(next.ID)
+			if err != nil {
+				return "", err
+			}
+			return unifiedDiff(fmt.Sprintf("#%d", id), fmt.Sprintf("#%d", next.ID), before, after), nil
+		}
+	}
+	after, err := os.ReadFile(v.File)
+	if err != nil {
+		return "", fmt.Errorf("read config: %w", err)
+	}
+	return unifiedDiff(fmt.Sprintf("#%d", id), "current", before, string(after)), nil
+}
+
+// DiffConfigVersions compares two snapshots.
+func DiffConfigVersions(from, to int) (string, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	_, before, _, err := lookupConfigVersion(from)
+	if err != nil {
+		return "", err
+	}
+	_, after, _, err := lookupConfigVersion(to)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(fmt.Sprintf("#%d", from), fmt.Sprintf("#%d", to), before, after), nil
+}
+
+// RollbackConfig restores the file of version id to its content in that
+// snapshot. The content it replaces is snapshotted first, so a rollback can
+// itself be rolled back. Callers should reload the config afterwards.
+func RollbackConfig(ctx context.Context, id int) (ConfigVersion, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	v, content, _, err := lookupConfigVersion(id)
+	if err != nil {
+		return ConfigVersion{}, err
+	}
+	var probe map[string]any
+	if _, err := toml.Decode(content, &probe); err != nil {
+		return ConfigVersion{}, fmt.Errorf("config version %d is not valid TOML: %w", id, err)
+	}
+	snapshotConfig(ctx, v.File, fmt.Sprintf("rollback to #%d", id))
+	if err := replaceConfigFile(v.File, content); err != nil {
+		return ConfigVersion{}, err
+	}
+	return v, nil
+}
+
+var secretLinePattern = regexp.MustCompile(`(?i)^([-+ ]\s*"?[A-Za-z0-9_.-]*(?:key|token|secret|password|credential)[A-Za-z0-9_.-]*"?\s*=\s*)(.+)$`)
+
+// RedactConfigDiff masks the values of secret-looking keys in a diff, for
+// showing it in chats and logs.
+func RedactConfigDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		if m := secretLinePattern.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + `"***"`
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unifiedDiff returns a unified diff of two texts with three lines of
+// context, or "" when they are equal. Config files are small, so a plain
+// LCS table is fine.
+func unifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type op struct {
+		kind byte // ' ', '-' or '+'
+		text string
+		ai   int // line index in a (for ' ' and '-')
+		bi   int // line index in b (for ' ' and '+')
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			ops = append(ops, op{' ', x[i], i, j})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', x[i], i, j})
+			i++
+		default:
+			ops = append(ops, op{'+', y[j], i, j})
+			j++
+		}
+	}
+
+	const context = 3
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		lo := max(start-context, 0)
+		hi := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				hi = k
+				continue
+			}
+			if k-hi > 2*context {
+				break
+			}
+		}
+		hi = min(hi+context, len(ops)-1)
+
+		aLen, bLen := 0, 0
+		for _, o := range ops[lo : hi+1] {
+			if o.kind != '+' {
+				aLen++
+			}
+			if o.kind != '-' {
+				bLen++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", ops[lo].ai+1, aLen, ops[lo].bi+1, bLen)
+		for _, o := range ops[lo : hi+1] {
+			sb.WriteByte(o.kind)
+			sb.WriteString(o.text)
+			sb.WriteByte('\n')
+		}
+		start = hi + 1
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
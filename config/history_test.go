@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConfigHistory_RecordsSnapshotsWithSource(t *testing.T) {
+	writeTestConfig(t, providerConfigTOML)
+
+	if err := SaveActiveProvider(context.Background(), "demo", "backup"); err != nil {
+		t.Fatalf("SaveActiveProvider() error: %v", err)
+	}
+	ctx := WithChangeSource(context.Background(), ChangeSource{Actor: "telegram:42", Reason: "/provider add relay"})
+	if err := AddProviderToConfig(ctx, "demo", ProviderConfig{Name: "relay", APIKey: "sk-relay"}); err != nil {
+		t.Fatalf("AddProviderToConfig() error: %v", err)
+	}
+
+	versions, err := ConfigHistory()
+	if err != nil {
+		t.Fatalf("ConfigHistory() error: %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("versions = %d, want 2", len(versions))
+	}
+	if versions[0].ID != 2 || versions[0].Actor != "telegram:42" || versions[0].Reason != "/provider add relay" {
+		t.Errorf("newest version = %+v", versions[0])
+	}
+	if versions[1].ID != 1 || versions[1].Actor != "cc-connect" {
+		t.Errorf("oldest version = %+v", versions[1])
+	}
+
+	diff, err := DiffConfigVersion(2)
+	if err != nil {
+		t.Fatalf("DiffConfigVersion() error: %v", err)
+	}
+	if !strings.Contains(diff, `name = "relay"`) || !strings.HasPrefix(diff, "--- #2\n+++ current\n@@ ") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+	diff, err = DiffConfigVersion(1)
+	if err != nil {
+		t.Fatalf("DiffConfigVersion() error: %v", err)
+	}
+	if !strings.Contains(diff, "+++ #2") || !strings.Contains(diff, `+provider = "backup"`) {
+		t.Errorf("change #1 should compare against #2:\n%s", diff)
+	}
+}
+
+func TestConfigHistory_OverlappingChanges(t *testing.T) {
+	writeTestConfig(t, providerConfigTOML)
+
+	sources := []ChangeSource{
+		{Actor: "api", Reason: "PUT /projects/demo"},
+		{Actor: "telegram:42", Reason: "/lang zh"},
+		{Actor: "cli", Reason: "provider add relay"},
+	}
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := WithChangeSource(context.Background(), src)
+			for range 5 {
+				if err := SaveLanguage(ctx, []string{"en", "zh", "ja"}[i]); err != nil {
+					t.Errorf("SaveLanguage() error: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	versions, err := ConfigHistory()
+	if err != nil {
+		t.Fatalf("ConfigHistory() error: %v", err)
+	}
+	if len(versions) != 15 {
+		t.Fatalf("versions = %d, want 15", len(versions))
+	}
+	counts := map[ChangeSource]int{}
+	for _, v := range versions {
+		counts[ChangeSource{Actor: v.Actor, Reason: v.Reason}]++
+	}
+	for _, src := range sources {
+		if counts[src] != 5 {
+			t.Errorf("changes attributed to %+v = %d, want 5 (all: %v)", src, counts[src], counts)
+		}
+	}
+}
+
+func TestConfigHistory_Rollback(t *testing.T) {
+	writeTestConfig(t, providerConfigTOML)
+	original, err := os.ReadFile(ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddProviderToConfig(context.Background(), "demo", ProviderConfig{Name: "relay", APIKey: "sk-relay"}); err != nil {
+		t.Fatalf("AddProviderToConfig() error: %v", err)
+	}
+	if err := SaveActiveProvider(context.Background(), "demo", "relay"); err != nil {
+		t.Fatalf("SaveActiveProvider() error: %v", err)
+	}
+
+	if _, err := RollbackConfig(context.Background(), 1); err != nil {
+		t.Fatalf("RollbackConfig() error: %v", err)
+	}
+	restored, err := os.ReadFile(ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(restored) != string(original) {
+		t.Errorf("rollback did not restore the original file:\n%s", restored)
+	}
+
+	versions, _ := ConfigHistory()
+	if len(versions) != 3 || versions[0].Reason != "rollback to #1" {
+		t.Fatalf("rollback should be recorded as a new version, got %+v", versions)
+	}
+	if _, err := RollbackConfig(context.Background(), 99); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+func TestConfigHistory_Prunes(t *testing.T) {
+	writeTestConfig(t, providerConfigTOML)
+	for i := 0; i < maxConfigHistory+3; i++ {
+		if err := SaveLanguage(context.Background(), []string{"en", "zh"}[i%2]); err != nil {
+			t.Fatalf("SaveLanguage() error: %v", err)
+		}
+	}
+	versions, err := ConfigHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != maxConfigHistory || versions[len(versions)-1].ID != 4 {
+		t.Fatalf("got %d versions, oldest #%d", len(versions), versions[len(versions)-1].ID)
+	}
+	if _, _, _, err := lookupConfigVersion(3); err == nil {
+		t.Error("pruned version should be gone")
+	}
+}
+
+func TestRedactConfigDiff(t *testing.T) {
+	diff := "--- #1\n+++ #2\n@@ -1,3 +1,3 @@\n name = \"relay\"\n-api_key = \"sk-old\"\n+api_key = \"sk-new\"\n+  bot_token = \"123:abc\"\n base_url = \"https://x\""
+	got := RedactConfigDiff(diff)
+	for _, secret := range []string{"sk-old", "sk-new", "123:abc"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("secret %q not redacted:\n%s", secret, got)
+		}
+	}
+	if !strings.Contains(got, `base_url = "https://x"`) || !strings.Contains(got, `-api_key = "***"`) {
+		t.Errorf("unexpected redaction:\n%s", got)
+	}
+}
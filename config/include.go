@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A config can be split across files. The main config.toml may list glob
+// patterns in `include`, resolved relative to its own directory, and every
+// *.toml file in a conf.d/ directory next to it is picked up as well:
+//
+//	include = ["projects/*.toml"]
+//
+// Files are merged in a fixed order: the main file, then each include
+// pattern in the order written (matches sorted lexically), then conf.d/ in
+// lexical order. A file matched more than once is read once. Included files
+// may only contain [[projects]], [[providers]], [[commands]] and [[aliases]];
+// entries are appended in merge order and names must be unique across all
+// files, so there is never a question of which definition wins.
+const confDirName = "conf.d"
+
+// includedConfig is the subset of Config an included file may define.
+type includedConfig struct {
+	Providers []ProviderConfig `toml:"providers,omitempty"`
+	Projects  []ProjectConfig  `toml:"projects,omitempty"`
+	Commands  []CommandConfig  `toml:"commands,omitempty"`
+	Aliases   []AliasConfig    `toml:"aliases,omitempty"`
+}
+
+var includableKeys = map[string]bool{
+	"providers": true,
+	"projects":  true,
+	"commands":  true,
+	"aliases":   true,
+}
+
+// IncludedConfigFiles returns the files merged into the config at mainPath,
+// in merge order and excluding mainPath itself.
+func IncludedConfigFiles(mainPath string) ([]string, error) {
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var partial struct {
+		Include []string `toml:"include"`
+	}
+	if _, err := toml.Decode(string(data), &partial); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return includeFiles(mainPath, partial.Include)
+}
+
+func includeFiles(mainPath string, patterns []string) ([]string, error) {
+	base := filepath.Dir(mainPath)
+	seen := map[string]bool{filepath.Clean(mainPath): true}
+	var files []string
+	add := func(matches []string) {
+		sort.Strings(matches)
+		for _, m := range matches {
+			m = filepath.Clean(m)
+			if seen[m] || strings.HasPrefix(filepath.Base(m), ".") {
+				continue
+			}
+			if fi, err := os.Stat(m); err != nil || fi.IsDir() {
+				continue
+			}
+			seen[m] = true
+			files = append(files, m)
+		}
+	}
+	for _, pattern := range patterns {
+		p := expandUserPath(strings.TrimSpace(pattern))
+		if p == "" {
+			continue
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(base, p)
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		// A plain path is a promise that the file exists; a glob that matches
+		// nothing is fine (e.g. an empty projects/ directory).
+		if len(matches) == 0 && !strings.ContainsAny(p, "*?[") {
+			return nil, fmt.Errorf("include %q: file not found", pattern)
+		}
+		add(matches)
+	}
+	matches, _ := filepath.Glob(filepath.Join(base, confDirName, "*.toml"))
+	add(matches)
+	return files, nil
+}
+
+// readIncludedConfig parses one included file and rejects keys that only
+// make sense in the main config.
+func readIncludedConfig(path string) (*includedConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read included config: %w", err)
+	}
+	inc := &includedConfig{}
+	md, err := toml.Decode(string(data), inc)
+	if err != nil {
+		return nil, fmt.Errorf("parse included config %s: %w", path, err)
+	}
+	for _, key := range md.Keys() {
+		if len(key) > 0 && !includableKeys[key[0]] {
+			return nil, fmt.Errorf("included config %s: %q is not allowed here; included files may only define [[projects]], [[providers]], [[commands]] and [[aliases]]", path, key[0])
+		}
+	}
+	return inc, nil
+}
+
+// mergeIncludes appends the entries of every included file to cfg.
+func mergeIncludes(cfg *Config, mainPath string) error {
+	files, err := includeFiles(mainPath, cfg.Include)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	// Only definitions from different files conflict. Duplicates within one
+	// file are handled as they are without includes, so adding an include
+	// never breaks a config that loaded before.
+	owners := map[string]string{}
+	claim := func(kind, name, file string) error {
+		key := kind + "\x00" + name
+		if prev, ok := owners[key]; ok && prev != file {
+			return fmt.Errorf("%s %q is defined in both %s and %s", kind, name, prev, file)
+		}
+		owners[key] = file
+		return nil
+	}
+	claimAll := func(file string, inc *includedConfig) error {
+		for _, p := range inc.Projects {
+			if err := claim("project", p.Name, file); err != nil {
+				return err
+			}
+		}
+		for _, p := range inc.Providers {
+			if err := claim("provider", p.Name, file); err != nil {
+				return err
+			}
+		}
+		for _, c := range inc.Commands {
+			if err := claim("command", c.Name, file); err != nil {
+				return err
+			}
+		}
+		for _, a := range inc.Aliases {
+			if err := claim("alias", a.Name, file); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	main := &includedConfig{Providers: cfg.Providers, Projects: cfg.Projects, Commands: cfg.Commands, Aliases: cfg.Aliases}
+	if err := claimAll(mainPath, main); err != nil {
+		return err
+	}
+	for _, file := range files {
+		inc, err := readIncludedConfig(file)
+		if err != nil {
+			return err
+		}
+		if err := claimAll(file, inc); err != nil {
+			return err
+		}
+		cfg.Providers = append(cfg.Providers, inc.Providers...)
+		cfg.Projects = append(cfg.Projects, inc.Projects...)
+		cfg.Commands = append(cfg.Commands, inc.Commands...)
+		cfg.Aliases = append(cfg.Aliases, inc.Aliases...)
+	}
+	return nil
+}
+
+// configFileDefining returns the file that defines the named item of kind
+// ("projects", "providers", "commands" or "aliases"), so writers edit it in
+// place. Items that are not defined anywhere yet belong to the main config.
+// The caller must hold configMu when it goes on to write the file.
+func configFileDefining(kind, name string) string {
+	files, err := IncludedConfigFiles(ConfigPath)
+	if err != nil {
+		return ConfigPath
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		inc := &includedConfig{}
+		if err := toml.Unmarshal(data, inc); err != nil {
+			continue
+		}
+		if inc.defines(kind, name) {
+			return file
+		}
+	}
+	return ConfigPath
+}
+
+func (inc *includedConfig) defines(kind, name string) bool {
+	switch kind {
+	case "projects":
+		for _, p := range inc.Projects {
+			if p.Name == name {
+				return true
+			}
+		}
+	case "providers":
+		for _, p := range inc.Providers {
+			if p.Name == name {
+				return true
+			}
+		}
+	case "commands":
+		for _, c := range inc.Commands {
+			if c.Name == name {
+				return true
+			}
+		}
+	case "aliases":
+		for _, a := range inc.Aliases {
+			if a.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// projectConfigFile returns the file that defines projectName.
+func projectConfigFile(projectName string) string {
+	return configFileDefining("projects", projectName)
+}
+
+// loadMergedLocked reads the main config and every included file, without
+// env or secret resolution, for read-only listings.
+func loadMergedLocked() (*Config, error) {
+	cfg, err := loadLocked()
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeIncludes(cfg, ConfigPath); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
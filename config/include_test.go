@@ -0,0 +1,240 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const includeMainFixture = `include = ["projects/*.toml"]
+language = "en"
+
+[[providers]]
+name = "shared"
+api_key = "sk-shared"
+
+[[projects]]
+name = "main"
+
+[projects.agent]
+type = "claudecode"
+
+[[projects.platforms]]
+type = "telegram"
+
+[projects.platforms.options]
+token = "t-main"
+`
+
+const includeBetaFixture = `# beta lives in its own file
+[[projects]]
+name = "beta"
+
+[projects.agent]
+type = "codex"
+provider_refs = ["shared"]
+
+[[projects.agent.providers]]
+name = "own"
+api_key = "sk-own"
+
+[[projects.platforms]]
+type = "telegram"
+
+[projects.platforms.options]
+token = "t-beta"
+`
+
+func writeIncludeFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "config.toml")
+}
+
+func TestLoad_MergesIncludesAndConfD(t *testing.T) {
+	path := writeIncludeFixture(t, map[string]string{
+		"config.toml":         includeMainFixture,
+		"projects/beta.toml":  includeBetaFixture,
+		"conf.d/20-cmd.toml":  "[[commands]]\nname = \"later\"\nprompt = \"b\"\n",
+		"conf.d/10-cmd.toml":  "[[commands]]\nname = \"first\"\nprompt = \"a\"\n",
+		"conf.d/.swap.toml":   "not toml at all",
+		"conf.d/notes.txt":    "ignored",
+		"projects/README.md":  "ignored",
+		"projects/empty.toml": "",
+	})
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var names []string
+	for _, p := range cfg.Projects {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "main,beta" {
+		t.Fatalf("projects = %v, want [main beta]", names)
+	}
+	if len(cfg.Commands) != 2 || cfg.Commands[0].Name != "first" || cfg.Commands[1].Name != "later" {
+		t.Fatalf("commands = %+v, want first then later", cfg.Commands)
+	}
+	// provider_refs in an included project resolve against the main file's providers.
+	if got := cfg.Projects[1].Agent.Providers; len(got) != 2 {
+		t.Fatalf("beta providers = %+v, want own + shared", got)
+	}
+}
+
+func TestLoad_IncludeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "duplicate project",
+			files: map[string]string{
+				"config.toml":        includeMainFixture,
+				"projects/beta.toml": includeBetaFixture,
+				"conf.d/beta.toml":   includeBetaFixture,
+			},
+			want: `project "beta" is defined in both`,
+		},
+		{
+			name: "top-level key in included file",
+			files: map[string]string{
+				"config.toml":        includeMainFixture,
+				"projects/beta.toml": "language = \"zh\"\n" + includeBetaFixture,
+			},
+			want: `"language" is not allowed here`,
+		},
+		{
+			name: "missing literal include",
+			files: map[string]string{
+				"config.toml": strings.Replace(includeMainFixture, "projects/*.toml", "extra.toml", 1),
+			},
+			want: `include "extra.toml": file not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeIncludeFixture(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_IncludeKeepsSameFileDuplicates(t *testing.T) {
+	const dupCommands = `
+[[commands]]
+name = "deploy"
+prompt = "first"
+
+[[commands]]
+name = "deploy"
+prompt = "second"
+`
+	if _, err := Load(writeConfigFixture(t, strings.Replace(includeMainFixture, `include = ["projects/*.toml"]`, "", 1)+dupCommands)); err != nil {
+		t.Fatalf("Load without include: %v", err)
+	}
+	path := writeIncludeFixture(t, map[string]string{
+		"config.toml":        includeMainFixture + dupCommands,
+		"projects/beta.toml": includeBetaFixture,
+	})
+	if _, err := Load(path); err != nil {
+		t.Fatalf("adding an unrelated include must not reject duplicates within config.toml: %v", err)
+	}
+}
+
+func TestWriters_EditTheFileThatDefinesTheItem(t *testing.T) {
+	path := writeIncludeFixture(t, map[string]string{
+		"config.toml":        includeMainFixture,
+		"projects/beta.toml": includeBetaFixture,
+	})
+	patchConfigPath(t, path)
+	betaPath := filepath.Join(filepath.Dir(path), "projects", "beta.toml")
+	mainBefore, _ := os.ReadFile(path)
+
+	if err := SaveAgentModel(context.Background(), "beta", "gpt-5"); err != nil {
+		t.Fatalf("SaveAgentModel: %v", err)
+	}
+	if err := SaveProviderModel(context.Background(), "beta", "own", "own-model"); err != nil {
+		t.Fatalf("SaveProviderModel: %v", err)
+	}
+	mode := "yolo"
+	if err := SaveProjectSettings(context.Background(), "beta", ProjectSettingsUpdate{Mode: &mode}); err != nil {
+		t.Fatalf("SaveProjectSettings: %v", err)
+	}
+	if err := AddProviderToConfig(context.Background(), "beta", ProviderConfig{Name: "extra", APIKey: "sk-extra"}); err != nil {
+		t.Fatalf("AddProviderToConfig: %v", err)
+	}
+	if err := RemoveProviderFromConfig(context.Background(), "beta", "own"); err != nil {
+		t.Fatalf("RemoveProviderFromConfig: %v", err)
+	}
+
+	if mainAfter, _ := os.ReadFile(path); string(mainAfter) != string(mainBefore) {
+		t.Fatalf("main config changed:\n%s", mainAfter)
+	}
+	beta := readConfigFixture(t, betaPath)
+	if len(beta.Projects) != 1 || beta.Projects[0].Name != "beta" {
+		t.Fatalf("beta.toml projects = %+v", beta.Projects)
+	}
+	opts := beta.Projects[0].Agent.Options
+	if stringMapValue(opts, "model") != "gpt-5" || stringMapValue(opts, "mode") != "yolo" {
+		t.Fatalf("beta agent options = %v", opts)
+	}
+	if got := beta.Projects[0].Agent.Providers; len(got) != 1 || got[0].Name != "extra" {
+		t.Fatalf("beta providers = %+v, want [extra]", got)
+	}
+	raw, _ := os.ReadFile(betaPath)
+	if strings.Contains(string(raw), "language") || strings.Contains(string(raw), "[log]") {
+		t.Fatalf("included file gained main-only keys:\n%s", raw)
+	}
+	if _, err := Load(path); err != nil {
+		t.Fatalf("Load after writes: %v", err)
+	}
+}
+
+func TestGlobalProviderWriters_FollowIncludes(t *testing.T) {
+	path := writeIncludeFixture(t, map[string]string{
+		"config.toml":           strings.Replace(includeMainFixture, "[[providers]]\nname = \"shared\"\napi_key = \"sk-shared\"\n", "", 1),
+		"conf.d/providers.toml": "[[providers]]\nname = \"shared\"\napi_key = \"sk-shared\"\n",
+		"projects/beta.toml":    includeBetaFixture,
+	})
+	patchConfigPath(t, path)
+	provPath := filepath.Join(filepath.Dir(path), "conf.d", "providers.toml")
+
+	list, err := ListGlobalProviders()
+	if err != nil || len(list) != 1 || list[0].Name != "shared" {
+		t.Fatalf("ListGlobalProviders = %+v, %v", list, err)
+	}
+	if err := AddGlobalProvider(context.Background(), ProviderConfig{Name: "shared"}); err == nil {
+		t.Fatal("AddGlobalProvider accepted a name defined in an included file")
+	}
+	if err := SaveProviderModel(context.Background(), "beta", "shared", "m2"); err != nil {
+		t.Fatalf("SaveProviderModel(context.Background(), ref): %v", err)
+	}
+	if got := readConfigFixture(t, provPath).Providers[0].Model; got != "m2" {
+		t.Fatalf("shared model = %q, want m2", got)
+	}
+	if err := RemoveGlobalProvider(context.Background(), "shared"); err != nil {
+		t.Fatalf("RemoveGlobalProvider: %v", err)
+	}
+	if got := readConfigFixture(t, provPath).Providers; len(got) != 0 {
+		t.Fatalf("providers.toml still has %+v", got)
+	}
+	betaPath := filepath.Join(filepath.Dir(path), "projects", "beta.toml")
+	if refs := readConfigFixture(t, betaPath).Projects[0].Agent.ProviderRefs; len(refs) != 0 {
+		t.Fatalf("beta provider_refs = %v, want none", refs)
+	}
+}
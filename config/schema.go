@@ -0,0 +1,103 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// JSONSchema returns a JSON Schema (draft 2020-12) for config.toml, derived
+// from Config. platforms and agents map each registered type to the schema of
+// its options table; they are attached to [[projects.platforms]] and
+// [projects.agent] by type. Editors with TOML schema support can use it for
+// completion and inline errors.
+func JSONSchema(platforms, agents map[string]map[string]any) map[string]any {
+	root := schemaFor(reflect.TypeOf(Config{}))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["title"] = "cc-connect config.toml"
+
+	project := nestedSchema(root, "projects", "items")
+	if project == nil {
+		return root
+	}
+	if plat := nestedSchema(project, "platforms", "items"); plat != nil {
+		attachTypedOptions(plat, platforms)
+	}
+	if agent := nestedSchema(project, "agent"); agent != nil {
+		attachTypedOptions(agent, agents)
+	}
+	return root
+}
+
+// nestedSchema follows property names (and "items") down an object schema.
+func nestedSchema(s map[string]any, path ...string) map[string]any {
+	for _, p := range path {
+		if p == "items" {
+			next, _ := s["items"].(map[string]any)
+			s = next
+		} else {
+			props, _ := s["properties"].(map[string]any)
+			next, _ := props[p].(map[string]any)
+			s = next
+		}
+		if s == nil {
+			return nil
+		}
+	}
+	return s
+}
+
+// attachTypedOptions restricts "type" to the registered names and picks the
+// options schema that matches it.
+func attachTypedOptions(s map[string]any, byType map[string]map[string]any) {
+	if len(byType) == 0 {
+		return
+	}
+	names := make([]string, 0, len(byType))
+	for name := range byType {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	props, _ := s["properties"].(map[string]any)
+	if props == nil {
+		return
+	}
+	props["type"] = map[string]any{"type": "string", "enum": names}
+	var cases []any
+	for _, name := range names {
+		cases = append(cases, map[string]any{
+			"if":   map[string]any{"properties": map[string]any{"type": map[string]any{"const": name}}, "required": []string{"type"}},
+			"then": map[string]any{"properties": map[string]any{"options": byType[name]}},
+		})
+	}
+	s["allOf"] = cases
+}
+
+func schemaFor(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		props := map[string]any{}
+		for name, ft := range tomlFields(t) {
+			props[name] = schemaFor(ft)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem())}
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{}
+}
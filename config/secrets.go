@@ -0,0 +1,465 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Secret references let a credential in config.toml point at a secret
+// instead of containing it. Only values under credential-like keys are
+// considered (see isCredentialKey), so a prompt or command can never run a
+// cmd: reference. A value is a reference only when the whole string has one
+// of these forms:
+//
+//	file:/run/secrets/slack_token   contents of the file
+//	cmd:pass show slack/token       stdout of the command, run with the shell
+//	secret:slack_token              entry in the encrypted secrets store
+//
+// file: needs an absolute or ~/ path and cmd: must not be followed by a
+// space, so prose such as "cmd: run the tests" in a prompt is left alone.
+// Trailing newlines are trimmed. ${VAR} placeholders are resolved first, so
+// a reference may itself come from the environment. Unlike a missing
+// environment variable, a reference that cannot be resolved fails the load.
+const (
+	secretRefFile   = "file:"
+	secretRefCmd    = "cmd:"
+	secretRefSecret = "secret:"
+)
+
+// secretCmdTimeout bounds how long a cmd: reference may run.
+var secretCmdTimeout = 30 * time.Second
+
+// secretNamePattern is what `cc-connect secrets set` accepts as a name.
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.\-/]*$`)
+
+// ErrSecretNotFound is returned by SecretStore.Get for an unknown name.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// isSecretRef reports whether s is a secret reference.
+func isSecretRef(s string) bool {
+	switch {
+	case strings.HasPrefix(s, secretRefFile):
+		path := s[len(secretRefFile):]
+		return filepath.IsAbs(path) || strings.HasPrefix(path, "/") || strings.HasPrefix(path, "~/")
+	case strings.HasPrefix(s, secretRefCmd):
+		cmd := s[len(secretRefCmd):]
+		return cmd != "" && strings.TrimLeft(cmd, " \t") == cmd
+	case strings.HasPrefix(s, secretRefSecret):
+		return secretNamePattern.MatchString(s[len(secretRefSecret):])
+	}
+	return false
+}
+
+// secretResolver resolves the references in one config load and remembers
+// the values it produced so they can be redacted from logs.
+type secretResolver struct {
+	dataDir string
+	shell   string
+	flag    string
+	store   *SecretStore
+	values  []string
+	err     error
+}
+
+func (r *secretResolver) resolve(s string) string {
+	if r.err != nil || !isSecretRef(s) {
+		return s
+	}
+	val, err := r.lookup(s)
+	if err != nil {
+		// The reference names where the secret lives, not the secret itself,
+		// so it is safe to include in the error.
+		r.err = fmt.Errorf("resolve secret reference %q: %w", s, err)
+		return s
+	}
+	val = strings.TrimRight(val, "\r\n")
+	r.values = append(r.values, val)
+	return val
+}
+
+func (r *secretResolver) lookup(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, secretRefFile):
+		data, err := os.ReadFile(expandUserPath(ref[len(secretRefFile):]))
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case strings.HasPrefix(ref, secretRefCmd):
+		ctx, cancel := context.WithTimeout(context.Background(), secretCmdTimeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, r.shell, r.flag, strings.TrimSpace(ref[len(secretRefCmd):]))
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%w: %s", err, msg)
+			}
+			return "", err
+		}
+		return string(out), nil
+	default:
+		if r.store == nil {
+			r.store = OpenSecretStore(r.dataDir)
+		}
+		return r.store.Get(ref[len(secretRefSecret):])
+	}
+}
+
+// resolveSecretsInConfig replaces the secret references in the credential
+// fields of cfg with their values. It runs after env placeholders and the
+// data_dir default are applied. cmd: references use the global shell.
+func resolveSecretsInConfig(cfg *Config) error {
+	shell, flag, _ := EffectiveShell(cfg, nil)
+	r := &secretResolver{dataDir: cfg.DataDir, shell: shell, flag: flag}
+	v := reflect.ValueOf(cfg).Elem()
+	v.Set(mapCredentialStrings(v, "", r.resolve))
+	if r.err != nil {
+		return r.err
+	}
+	cfg.secretValues = r.values
+	return nil
+}
+
+// credentialWords are the trailing key words that mark a credential, as in
+// token, bot_token, app_secret, api_key, api_keys or OPENAI_API_KEY.
+var credentialWords = map[string]bool{
+	"token": true, "secret": true, "password": true, "passwd": true,
+	"key": true, "keys": true, "apikey": true, "credential": true,
+	"credentials": true, "authorization": true,
+}
+
+// nonCredentialKeys end in a credential word but name something else.
+var nonCredentialKeys = map[string]bool{
+	"session_key": true,
+}
+
+// isCredentialKey reports whether a TOML key or map key holds a credential.
+// Only the last word counts, so api_key_env or key_rotation do not match.
+func isCredentialKey(key string) bool {
+	key = strings.ToLower(key)
+	if nonCredentialKeys[key] {
+		return false
+	}
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	return len(words) > 0 && credentialWords[words[len(words)-1]]
+}
+
+// mapCredentialStrings returns a copy of v with fn applied to the strings
+// stored under credential keys. key is the TOML key or map key v was found
+// under; list elements inherit the key of their list.
+func mapCredentialStrings(v reflect.Value, key string, fn func(string) string) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if !isCredentialKey(key) {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(fn(v.String()))
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(mapCredentialStrings(v.Elem(), key, fn))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !out.Field(i).CanSet() {
+				continue
+			}
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+			if name == "" {
+				name = t.Field(i).Name
+			}
+			out.Field(i).Set(mapCredentialStrings(v.Field(i), name, fn))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(mapCredentialStrings(v.Index(i), key, fn))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(mapCredentialStrings(v.Index(i), key, fn))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := iter.Key()
+			name := ""
+			if k.Kind() == reflect.String {
+				name = k.String()
+			}
+			out.SetMapIndex(k, mapCredentialStrings(iter.Value(), name, fn))
+		}
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(mapCredentialStrings(v.Elem(), key, fn))
+		return out
+	default:
+		return v
+	}
+}
+
+// SecretValues returns the values that secret references in the loaded
+// config resolved to, for redaction from logs and error messages.
+func (c *Config) SecretValues() []string {
+	return c.secretValues
+}
+
+// DataDirFromConfig returns the data_dir set in the config file at path, or
+// the default ~/.cc-connect. Unlike Load it resolves no secret references, so
+// it works before the secrets a config names have been stored.
+func DataDirFromConfig(path string) string {
+	var partial struct {
+		DataDir string `toml:"data_dir"`
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		_, _ = toml.Decode(string(data), &partial)
+	}
+	if dir := resolveEnvPlaceholders(partial.DataDir); dir != "" {
+		return expandUserPath(dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".cc-connect")
+	}
+	return ".cc-connect"
+}
+
+// SecretStore is the encrypted local secrets file managed by
+// `cc-connect secrets`. Each value is sealed separately with AES-256-GCM,
+// using its name as additional data, so names can be listed without the key.
+// The key lives in <data_dir>/secrets.key unless CC_SECRETS_KEY_FILE points
+// elsewhere; keep it out of backups that include the secrets file.
+type SecretStore struct {
+	path    string
+	keyPath string
+}
+
+type secretFile struct {
+	Version int               `json:"version"`
+	Secrets map[string]string `json:"secrets"`
+}
+
+// OpenSecretStore returns the secrets store in dataDir. Nothing is read
+// until the store is used.
+func OpenSecretStore(dataDir string) *SecretStore {
+	keyPath := os.Getenv("CC_SECRETS_KEY_FILE")
+	if keyPath == "" {
+		keyPath = filepath.Join(dataDir, "secrets.key")
+	}
+	return &SecretStore{
+		path:    filepath.Join(dataDir, "secrets.json"),
+		keyPath: expandUserPath(keyPath),
+	}
+}
+
+// Path returns the location of the secrets file.
+func (s *SecretStore) Path() string { return s.path }
+
+// List returns the stored secret names in sorted order.
+func (s *SecretStore) List() ([]string, error) {
+	f, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(f.Secrets))
+	for name := range f.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Get decrypts one secret.
+func (s *SecretStore) Get(name string) (string, error) {
+	f, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := f.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	gcm, err := s.cipher(false)
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("secret %s: corrupt entry", name)
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("secret %s: decrypt failed (wrong key?)", name)
+	}
+	return string(plain), nil
+}
+
+// Set encrypts and stores a secret, creating the key on first use.
+func (s *SecretStore) Set(name, value string) error {
+	if !secretNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '_', '.', '-' and '/'", name)
+	}
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	gcm, err := s.cipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	f.Secrets[name] = base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), []byte(name)))
+	return s.write(f)
+}
+
+// Delete removes a secret. It reports ErrSecretNotFound for unknown names.
+func (s *SecretStore) Delete(name string) error {
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Secrets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	delete(f.Secrets, name)
+	return s.write(f)
+}
+
+func (s *SecretStore) read() (*secretFile, error) {
+	f := &secretFile{Version: 1, Secrets: map[string]string{}}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secrets file: %w", err)
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("parse secrets file: %w", err)
+	}
+	if f.Secrets == nil {
+		f.Secrets = map[string]string{}
+	}
+	return f, nil
+}
+
+func (s *SecretStore) write(f *secretFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFilePrivate(s.path, append(data, '\n'))
+}
+
+func (s *SecretStore) cipher(create bool) (cipher.AEAD, error) {
+	key, err := s.loadKey(create)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *SecretStore) loadKey(create bool) ([]byte, error) {
+	data, err := os.ReadFile(s.keyPath)
+	if os.IsNotExist(err) && create {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := writeFilePrivate(s.keyPath, []byte(base64.StdEncoding.EncodeToString(key)+"\n")); err != nil {
+			return nil, fmt.Errorf("write secrets key: %w", err)
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read secrets key: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("secrets key %s is not a base64-encoded 32-byte key", s.keyPath)
+	}
+	return key, nil
+}
+
+// writeFilePrivate atomically writes a file readable only by its owner.
+func writeFilePrivate(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad_ResolvesSecretReferences(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd: reference uses a POSIX shell command")
+	}
+	root := t.TempDir()
+	t.Setenv("CC_ROOT", root)
+	t.Setenv("CC_SECRETS_KEY_FILE", "")
+
+	if err := os.WriteFile(filepath.Join(root, "tg_token"), []byte("tg-from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := OpenSecretStore(filepath.Join(root, "state")).Set("relay/api_key", "sk-from-store"); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := writeConfigFixture(t, `
+data_dir = "${CC_ROOT}/state"
+
+[webhook]
+token = "cmd:printf hook-from-cmd"
+
+[[projects]]
+name = "demo"
+
+[projects.agent]
+type = "codex"
+
+[projects.agent.options]
+work_dir = "/tmp"
+note = "file: not a reference when used in prose"
+prompt = "cmd: run the tests"
+
+[[projects.agent.providers]]
+name = "relay"
+api_key = "secret:relay/api_key"
+
+[[projects.platforms]]
+type = "telegram"
+
+[projects.platforms.options]
+token = "file:${CC_ROOT}/tg_token"
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if got := cfg.Webhook.Token; got != "hook-from-cmd" {
+		t.Errorf("cmd: reference = %q", got)
+	}
+	if got := cfg.Projects[0].Agent.Providers[0].APIKey; got != "sk-from-store" {
+		t.Errorf("secret: reference = %q", got)
+	}
+	if got := stringMapValue(cfg.Projects[0].Platforms[0].Options, "token"); got != "tg-from-file" {
+		t.Errorf("file: reference = %q", got)
+	}
+	if got := stringMapValue(cfg.Projects[0].Agent.Options, "note"); !strings.HasPrefix(got, "file: not") {
+		t.Errorf("a value that is not a path must be left alone, got %q", got)
+	}
+	if got := stringMapValue(cfg.Projects[0].Agent.Options, "prompt"); got != "cmd: run the tests" {
+		t.Errorf("cmd: followed by a space must be left alone, got %q", got)
+	}
+	vals := cfg.SecretValues()
+	for _, want := range []string{"hook-from-cmd", "sk-from-store", "tg-from-file"} {
+		if !slices.Contains(vals, want) {
+			t.Errorf("SecretValues() = %v, missing %q", vals, want)
+		}
+	}
+}
+
+func TestLoad_SecretReferencesOnlyInCredentialFields(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cmd: reference uses a POSIX shell command")
+	}
+	root := t.TempDir()
+	t.Setenv("CC_ROOT", root)
+	if err := os.WriteFile(filepath.Join(root, "openai_key"), []byte("sk-env\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := writeConfigFixture(t, `
+data_dir = "${CC_ROOT}/state"
+
+[[commands]]
+name = "marker"
+exec = "cmd:touch ${CC_ROOT}/exec-ran"
+
+[[projects]]
+name = "demo"
+
+[projects.agent]
+type = "codex"
+
+[projects.agent.options]
+prompt = "cmd:touch ${CC_ROOT}/prompt-ran"
+
+[[projects.agent.providers]]
+name = "relay"
+api_key_env = "file:${CC_ROOT}/openai_key"
+
+[projects.agent.providers.env]
+OPENAI_API_KEY = "file:${CC_ROOT}/openai_key"
+
+[[projects.platforms]]
+type = "telegram"
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	for _, name := range []string{"exec-ran", "prompt-ran"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			t.Errorf("a cmd: value outside a credential field was executed (%s exists)", name)
+		}
+	}
+	if got := cfg.Commands[0].Exec; !strings.HasPrefix(got, "cmd:touch") {
+		t.Errorf("commands exec = %q, want it left alone", got)
+	}
+	p := cfg.Projects[0].Agent.Providers[0]
+	if got := p.Env["OPENAI_API_KEY"]; got != "sk-env" {
+		t.Errorf("env credential = %q, want it resolved", got)
+	}
+	if got := p.APIKeyEnv; !strings.HasPrefix(got, "file:") {
+		t.Errorf("api_key_env = %q, want it left alone", got)
+	}
+}
+
+func TestLoad_FailsOnUnresolvableSecretReference(t *testing.T) {
+	configPath := writeConfigFixture(t, `
+data_dir = "`+filepath.ToSlash(t.TempDir())+`"
+
+[[projects]]
+name = "demo"
+
+[projects.agent]
+type = "codex"
+
+[[projects.platforms]]
+type = "telegram"
+
+[projects.platforms.options]
+token = "secret:missing"
+`)
+	_, err := Load(configPath)
+	if err == nil || !errors.Is(err, ErrSecretNotFound) || !strings.Contains(err.Error(), "secret:missing") {
+		t.Fatalf("Load() error = %v, want a secret-not-found error naming the reference", err)
+	}
+}
+
+func TestSecretStore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CC_SECRETS_KEY_FILE", "")
+	store := OpenSecretStore(dir)
+
+	if names, err := store.List(); err != nil || len(names) != 0 {
+		t.Fatalf("empty store List() = %v, %v", names, err)
+	}
+	if err := store.Set("b_token", "value-b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("a_token", "value-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("bad name", "x"); err == nil {
+		t.Error("names with spaces should be rejected")
+	}
+
+	names, _ := store.List()
+	if !slices.Equal(names, []string{"a_token", "b_token"}) {
+		t.Errorf("List() = %v", names)
+	}
+	if got, err := store.Get("a_token"); err != nil || got != "value-a" {
+		t.Errorf("Get(a_token) = %q, %v", got, err)
+	}
+	data, _ := os.ReadFile(store.Path())
+	if strings.Contains(string(data), "value-a") {
+		t.Error("secrets file must not contain plaintext values")
+	}
+	if runtime.GOOS != "windows" {
+		if fi, err := os.Stat(filepath.Join(dir, "secrets.key")); err != nil || fi.Mode().Perm() != 0o600 {
+			t.Errorf("secrets.key mode = %v, %v; want 0600", fi.Mode().Perm(), err)
+		}
+	}
+
+	if err := store.Delete("a_token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("a_token"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get after Delete error = %v", err)
+	}
+
+	// A different key cannot decrypt the store.
+	otherKey := filepath.Join(t.TempDir(), "other.key")
+	t.Setenv("CC_SECRETS_KEY_FILE", otherKey)
+	if err := OpenSecretStore(t.TempDir()).Set("x", "y"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSecretStore(dir).Get("b_token"); err == nil {
+		t.Error("Get with the wrong key should fail")
+	}
+}
+
+func TestDataDirFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfigFixture(t, `data_dir = "`+filepath.ToSlash(dir)+`"
+[webhook]
+token = "secret:not-stored-yet"
+`)
+	if got := DataDirFromConfig(path); got != filepath.ToSlash(dir) && got != dir {
+		t.Errorf("DataDirFromConfig() = %q, want %q", got, dir)
+	}
+}
@@ -0,0 +1,582 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Diagnostic is one problem found by Validate.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"` // 1-based; 0 when the problem has no single location
+	Path     string `json:"path,omitempty"` // e.g. projects[0].platforms[1].options.token
+	Severity string `json:"severity"`       // "error" or "warning"
+	Message  string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	loc := d.File
+	if d.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, d.Severity, d.Message)
+}
+
+// OptionIssue is a problem in a platform's or agent's options table, as
+// reported by an OptionChecker.
+type OptionIssue struct {
+	Key     string
+	Message string
+	Warning bool
+}
+
+// OptionChecker checks the options table of one platform (kind "platform")
+// or agent (kind "agent") of the given type. known is false when no such
+// type is registered. The config package does not know the factories, so
+// the caller supplies this; see cmd/cc-connect/config_cmd.go.
+type OptionChecker func(kind, typeName string, opts map[string]any) (issues []OptionIssue, known bool)
+
+// Validate checks the config file at path and everything it includes. It
+// reports TOML syntax errors, unknown keys, values of the wrong type, option
+// problems found by check (which may be nil), and the errors Load would
+// return, each with the file and line where possible. The returned error is
+// only set when the main file cannot be read.
+func Validate(path string, check OptionChecker) ([]Diagnostic, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	v := &validator{check: check}
+	files := []string{path}
+	included, err := IncludedConfigFiles(path)
+	if err != nil {
+		var perr toml.ParseError
+		if !errors.As(err, &perr) {
+			v.add(path, 0, "", "error", err.Error())
+		}
+	}
+	files = append(files, included...)
+
+	for i, file := range files {
+		v.checkFile(file, i > 0)
+	}
+	if !v.blocking {
+		v.checkSemantics(path)
+	}
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		if v.diags[i].File != v.diags[j].File {
+			return v.fileOrder(v.diags[i].File) < v.fileOrder(v.diags[j].File)
+		}
+		return v.diags[i].Line < v.diags[j].Line
+	})
+	return v.diags, nil
+}
+
+type validatedFile struct {
+	path     string
+	lines    map[string]int
+	projects int
+}
+
+type validator struct {
+	check    OptionChecker
+	files    []validatedFile
+	diags    []Diagnostic
+	blocking bool // syntax or type errors; Load would fail on them first
+}
+
+func (v *validator) add(file string, line int, path, severity, msg string) {
+	v.diags = append(v.diags, Diagnostic{File: file, Line: line, Path: path, Severity: severity, Message: msg})
+}
+
+func (v *validator) fileOrder(file string) int {
+	for i, f := range v.files {
+		if f.path == file {
+			return i
+		}
+	}
+	return len(v.files)
+}
+
+// parseErrorLine returns the line of a TOML parse error. The parser reports
+// an unexpected newline on the line after it, so the line is counted from the
+// byte offset of the offending token instead.
+func parseErrorLine(data []byte, perr toml.ParseError) int {
+	if start := perr.Position.Start; start >= 0 && start < len(data) {
+		return bytes.Count(data[:start], []byte("\n")) + 1
+	}
+	return perr.Position.Line
+}
+
+func (v *validator) checkFile(file string, included bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		v.add(file, 0, "", "error", err.Error())
+		v.blocking = true
+		return
+	}
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		line := 0
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			line = parseErrorLine(data, perr)
+			err = errors.New(perr.Message)
+		}
+		v.add(file, line, "", "error", "invalid TOML: "+err.Error())
+		v.blocking = true
+		v.files = append(v.files, validatedFile{path: file})
+		return
+	}
+	vf := validatedFile{path: file, lines: indexTOMLLines(string(data))}
+	if projects, ok := raw["projects"].([]map[string]any); ok {
+		vf.projects = len(projects)
+	}
+	v.files = append(v.files, vf)
+
+	t := reflect.TypeOf(Config{})
+	if included {
+		t = reflect.TypeOf(includedConfig{})
+	}
+	v.walk(vf, "", raw, t, included)
+	v.checkOptions(vf, raw)
+}
+
+// walk compares a decoded TOML value with the Go type it is loaded into.
+func (v *validator) walk(f validatedFile, path string, val any, t reflect.Type, included bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		table, ok := val.(map[string]any)
+		if !ok {
+			v.typeError(f, path, "a table", val)
+			return
+		}
+		fields := tomlFields(t)
+		for _, key := range sortedKeys(table) {
+			child := joinPath(path, key)
+			field, ok := fields[key]
+			if !ok {
+				msg := fmt.Sprintf("unknown key %q", key)
+				if path != "" {
+					msg += " in " + path
+				}
+				if included && path == "" {
+					msg = fmt.Sprintf("%q is not allowed in an included file; only [[projects]], [[providers]], [[commands]] and [[aliases]] are", key)
+					v.blocking = true
+				} else if near := closestName(key, fields); near != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", near)
+				}
+				v.add(f.path, lineFor(f.lines, child), child, "error", msg)
+				continue
+			}
+			v.walk(f, child, table[key], field, included)
+		}
+	case reflect.Slice:
+		var items []any
+		switch list := val.(type) {
+		case []any:
+			items = list
+		case []map[string]any:
+			for _, m := range list {
+				items = append(items, m)
+			}
+		default:
+			v.typeError(f, path, "an array", val)
+			return
+		}
+		for i, item := range items {
+			v.walk(f, fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), included)
+		}
+	case reflect.Map:
+		table, ok := val.(map[string]any)
+		if !ok {
+			v.typeError(f, path, "a table", val)
+			return
+		}
+		if t.Elem().Kind() == reflect.Interface {
+			return // free-form options; see checkOptions
+		}
+		for _, key := range sortedKeys(table) {
+			v.walk(f, joinPath(path, key), table[key], t.Elem(), included)
+		}
+	case reflect.String:
+		if _, ok := val.(string); !ok {
+			v.typeError(f, path, "a string", val)
+		}
+	case reflect.Bool:
+		if _, ok := val.(bool); !ok {
+			v.typeError(f, path, "true or false", val)
+		}
+	case reflect.Int, reflect.Int64:
+		if _, ok := val.(int64); !ok {
+			v.typeError(f, path, "an integer", val)
+		}
+	case reflect.Float64:
+		switch val.(type) {
+		case int64, float64:
+		default:
+			v.typeError(f, path, "a number", val)
+		}
+	}
+}
+
+func (v *validator) typeError(f validatedFile, path, want string, got any) {
+	v.add(f.path, lineFor(f.lines, path), path, "error", fmt.Sprintf("%s must be %s, got %s", path, want, tomlValueType(got)))
+	v.blocking = true
+}
+
+// checkOptions runs the OptionChecker over every platform and agent options
+// table in one file.
+func (v *validator) checkOptions(f validatedFile, raw map[string]any) {
+	if v.check == nil {
+		return
+	}
+	projects, _ := raw["projects"].([]map[string]any)
+	for i, proj := range projects {
+		prefix := fmt.Sprintf("projects[%d]", i)
+		if agent, ok := proj["agent"].(map[string]any); ok {
+			v.checkOptionTable(f, "agent", prefix+".agent", agent)
+		}
+		platforms, _ := proj["platforms"].([]map[string]any)
+		for j, plat := range platforms {
+			v.checkOptionTable(f, "platform", fmt.Sprintf("%s.platforms[%d]", prefix, j), plat)
+		}
+	}
+}
+
+func (v *validator) checkOptionTable(f validatedFile, kind, path string, table map[string]any) {
+	typeName, _ := table["type"].(string)
+	if typeName == "" {
+		return // reported by the semantic check
+	}
+	opts, _ := table["options"].(map[string]any)
+	if opts == nil {
+		opts = map[string]any{}
+	}
+	issues, known := v.check(kind, typeName, opts)
+	if !known {
+		v.add(f.path, lineFor(f.lines, path+".type"), path+".type", "error", fmt.Sprintf("unknown %s type %q", kind, typeName))
+		return
+	}
+	for _, issue := range issues {
+		p := path + ".options." + issue.Key
+		line := lineFor(f.lines, p)
+		if _, set := opts[issue.Key]; !set {
+			// Missing options point at the table that should hold them.
+			p = path + ".options"
+			if line = f.lines[p]; line == 0 {
+				line = lineFor(f.lines, path)
+			}
+		}
+		severity := "error"
+		if issue.Warning {
+			severity = "warning"
+		}
+		v.add(f.path, line, p, severity, fmt.Sprintf("%s: %s", path, issue.Message))
+	}
+}
+
+var diagPathPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*(\[\d+\])?(\.[A-Za-z_][A-Za-z0-9_]*(\[\d+\])?)*`)
+
+// checkSemantics loads the config the way the service does and maps the
+// first error it reports back to a file and line.
+func (v *validator) checkSemantics(path string) {
+	cfg, err := load(path)
+	if err == nil {
+		err = cfg.validate()
+	}
+	if err == nil {
+		return
+	}
+	msg := strings.TrimPrefix(err.Error(), "config: ")
+	for _, candidate := range diagPathPattern.FindAllString(msg, -1) {
+		if !strings.ContainsAny(candidate, ".[") {
+			continue
+		}
+		if len(v.files) == 0 {
+			break
+		}
+		file, local := v.locate(candidate)
+		if line := lineFor(file.lines, local); line > 0 {
+			v.add(file.path, line, local, "error", msg)
+			return
+		}
+	}
+	v.add(path, 0, "", "error", msg)
+}
+
+var mergedIndexPattern = regexp.MustCompile(`^projects\[(\d+)\]`)
+
+// locate maps a path in the merged config to the file that defines it and
+// the path within that file.
+func (v *validator) locate(path string) (validatedFile, string) {
+	m := mergedIndexPattern.FindStringSubmatch(path)
+	if m == nil {
+		return v.files[0], path
+	}
+	idx, _ := strconv.Atoi(m[1])
+	for _, f := range v.files {
+		if idx < f.projects {
+			return f, fmt.Sprintf("projects[%d]", idx) + path[len(m[0]):]
+		}
+		idx -= f.projects
+	}
+	return v.files[0], path
+}
+
+func tomlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func closestName(key string, fields map[string]reflect.Type) string {
+	best, bestDist := "", 3
+	for _, name := range sortedKeys(fields) {
+		if d := levenshtein(key, name); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func tomlValueType(v any) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int64:
+		return "an integer"
+	case float64:
+		return "a float"
+	case time.Time:
+		return "a date"
+	case []any:
+		return "an array"
+	case []map[string]any:
+		return "an array of tables"
+	case map[string]any:
+		return "a table"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// lineFor returns the line of path, or of its nearest ancestor that has one.
+func lineFor(lines map[string]int, path string) int {
+	for path != "" {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			break
+		}
+		path = path[:cut]
+	}
+	return 0
+}
+
+// indexTOMLLines maps the path of every table header and key in a TOML
+// document to its 1-based line, using the same path syntax as Diagnostic.
+// It understands the subset of TOML config files use: bare, quoted and
+// dotted keys, [tables], [[arrays of tables]] and values spanning lines.
+func indexTOMLLines(data string) map[string]int {
+	lines := map[string]int{}
+	counts := map[string]int{} // array-of-tables path → entries seen
+	table := ""
+	depth := 0  // open [ and { of a multi-line value
+	multi := "" // open multi-line string delimiter
+	for n, raw := range strings.Split(data, "\n") {
+		lineNo := n + 1
+		line := strings.TrimSpace(raw)
+		if multi != "" {
+			if strings.Contains(line, multi) {
+				multi = ""
+			}
+			continue
+		}
+		if depth > 0 {
+			depth += bracketDepth(line)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[[") {
+			end := strings.Index(line, "]]")
+			if end < 0 {
+				continue
+			}
+			parent, last := resolveHeader(splitTOMLKey(line[2:end]), counts)
+			arr := joinPath(parent, last)
+			table = fmt.Sprintf("%s[%d]", arr, counts[arr])
+			counts[arr]++
+			lines[table] = lineNo
+			if _, ok := lines[arr]; !ok {
+				lines[arr] = lineNo
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			parent, last := resolveHeader(splitTOMLKey(line[1:end]), counts)
+			table = joinPath(parent, last)
+			lines[table] = lineNo
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		path := table
+		for _, seg := range splitTOMLKey(line[:eq]) {
+			path = joinPath(path, seg)
+			if _, ok := lines[path]; !ok {
+				lines[path] = lineNo
+			}
+		}
+		value := strings.TrimSpace(line[eq+1:])
+		for _, delim := range []string{`"""`, `'''`} {
+			if strings.HasPrefix(value, delim) && !strings.Contains(value[3:], delim) {
+				multi = delim
+			}
+		}
+		if multi == "" {
+			depth = bracketDepth(value)
+		}
+	}
+	return lines
+}
+
+// resolveHeader turns header segments into a path, adding the current index
+// of every array of tables it passes through.
+func resolveHeader(segs []string, counts map[string]int) (parent, last string) {
+	if len(segs) == 0 {
+		return "", ""
+	}
+	for _, seg := range segs[:len(segs)-1] {
+		parent = joinPath(parent, seg)
+		if c := counts[parent]; c > 0 {
+			parent = fmt.Sprintf("%s[%d]", parent, c-1)
+		}
+	}
+	return parent, segs[len(segs)-1]
+}
+
+func splitTOMLKey(key string) []string {
+	var segs []string
+	var cur strings.Builder
+	quote := byte(0)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			segs = append(segs, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		case c == ' ' || c == '\t':
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	return append(segs, strings.TrimSpace(cur.String()))
+}
+
+// bracketDepth returns how many [ and { a value line leaves open, ignoring
+// brackets inside strings and comments.
+func bracketDepth(s string) int {
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func findDiag(diags []Diagnostic, substr string) *Diagnostic {
+	for i := range diags {
+		if strings.Contains(diags[i].Message, substr) {
+			return &diags[i]
+		}
+	}
+	return nil
+}
+
+func TestValidate_UnknownKeysAndWrongTypes(t *testing.T) {
+	path := writeConfigFixture(t, `languag = "en"
+idle_timeout_mins = "5"
+
+[[projects]]
+name = "demo"
+quiet = 1
+
+[projects.agent]
+type = "claudecode"
+
+[[projects.platforms]]
+type = "telegram"
+`)
+	diags, err := Validate(path, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	tests := []struct {
+		substr string
+		line   int
+	}{
+		{`unknown key "languag" (did you mean "language"?)`, 1},
+		{"idle_timeout_mins must be an integer, got a string", 2},
+		{"projects[0].quiet must be true or false, got an integer", 6},
+	}
+	for _, tt := range tests {
+		d := findDiag(diags, tt.substr)
+		if d == nil {
+			t.Fatalf("missing diagnostic %q in %+v", tt.substr, diags)
+		}
+		if d.Line != tt.line || d.Severity != "error" {
+			t.Errorf("%q: line=%d severity=%s, want line %d error", tt.substr, d.Line, d.Severity, tt.line)
+		}
+	}
+}
+
+func TestValidate_OptionChecker(t *testing.T) {
+	path := writeConfigFixture(t, `[[projects]]
+name = "demo"
+
+[projects.agent]
+type = "claudecode"
+
+[[projects.platforms]]
+type = "telegram"
+
+[projects.platforms.options]
+tokn = "x"
+
+[[projects.platforms]]
+type = "nope"
+`)
+	check := func(kind, typeName string, opts map[string]any) ([]OptionIssue, bool) {
+		if typeName == "nope" {
+			return nil, false
+		}
+		if kind != "platform" {
+			return nil, true
+		}
+		var issues []OptionIssue
+		if _, ok := opts["token"]; !ok {
+			issues = append(issues, OptionIssue{Key: "token", Message: `option "token" is required`})
+		}
+		if _, ok := opts["tokn"]; ok {
+			issues = append(issues, OptionIssue{Key: "tokn", Message: `unknown option "tokn"`})
+		}
+		return issues, true
+	}
+	diags, err := Validate(path, check)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if d := findDiag(diags, `unknown option "tokn"`); d == nil || d.Line != 11 || d.Path != "projects[0].platforms[0].options.tokn" {
+		t.Errorf("unknown option diagnostic = %+v", d)
+	}
+	// A missing option points at the options table that should hold it.
+	if d := findDiag(diags, `"token" is required`); d == nil || d.Line != 10 {
+		t.Errorf("required option diagnostic = %+v", d)
+	}
+	if d := findDiag(diags, `unknown platform type "nope"`); d == nil || d.Line != 14 {
+		t.Errorf("unknown type diagnostic = %+v", d)
+	}
+}
+
+func TestValidate_SemanticErrorInIncludedFile(t *testing.T) {
+	path := writeIncludeFixture(t, map[string]string{
+		"config.toml": includeMainFixture,
+		"projects/beta.toml": `[[projects]]
+name = "beta"
+
+[projects.agent]
+type = ""
+
+[[projects.platforms]]
+type = "telegram"
+`,
+	})
+	diags, err := Validate(path, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	d := findDiag(diags, "agent.type is required")
+	if d == nil {
+		t.Fatalf("missing agent.type diagnostic in %+v", diags)
+	}
+	if filepath.Base(d.File) != "beta.toml" || d.Line != 5 || d.Path != "projects[0].agent.type" {
+		t.Errorf("diagnostic = %+v, want beta.toml:5 projects[0].agent.type", d)
+	}
+}
+
+func TestValidate_DuplicateProjectName(t *testing.T) {
+	path := writeConfigFixture(t, `[[projects]]
+name = "demo"
+
+[projects.agent]
+type = "claudecode"
+
+[[projects.platforms]]
+type = "telegram"
+
+[[projects]]
+name = "demo"
+
+[projects.agent]
+type = "codex"
+
+[[projects.platforms]]
+type = "telegram"
+`)
+	diags, err := Validate(path, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	d := findDiag(diags, `"demo" is already used by projects[0]`)
+	if d == nil {
+		t.Fatalf("missing duplicate project diagnostic in %+v", diags)
+	}
+	if d.Line != 11 || d.Path != "projects[1].name" {
+		t.Errorf("diagnostic = %+v, want line 11 projects[1].name", d)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Errorf("Load error = %v, want duplicate project name", err)
+	}
+}
+
+func TestValidate_SyntaxError(t *testing.T) {
+	path := writeConfigFixture(t, "language = \"en\"\n[[projects]\nname = 1\n")
+	diags, err := Validate(path, nil)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Line != 2 || !strings.HasPrefix(diags[0].Message, "invalid TOML") {
+		t.Fatalf("diags = %+v, want one syntax error on line 2", diags)
+	}
+}
+
+func TestIndexTOMLLines(t *testing.T) {
+	lines := indexTOMLLines(`a = 1
+prompt = """
+x = "not a key"
+"""
+list = [
+  "y = 2",
+]
+
+[[projects]]
+name = "one"
+
+[[projects]]
+name = "two"
+"quoted.key" = true
+
+[projects.agent.options]
+env = { A = "1" }
+
+[[projects.platforms]]
+type = "slack"
+`)
+	want := map[string]int{
+		"a":                             1,
+		"prompt":                        2,
+		"list":                          5,
+		"projects[0]":                   9,
+		"projects[1].name":              13,
+		"projects[1].quoted.key":        14,
+		"projects[1].agent.options":     16,
+		"projects[1].agent.options.env": 17,
+		"projects[1].platforms[0].type": 20,
+	}
+	for path, line := range want {
+		if got := lines[path]; got != line {
+			t.Errorf("line of %s = %d, want %d", path, got, line)
+		}
+	}
+	if _, ok := lines["x"]; ok {
+		t.Error("key inside a multi-line string was indexed")
+	}
+	if got := lineFor(lines, "projects[1].agent.options.model"); got != 16 {
+		t.Errorf("lineFor falls back to the enclosing table: got %d, want 16", got)
+	}
+}
+
+func TestJSONSchema_AttachesTypedOptions(t *testing.T) {
+	opts := map[string]any{"type": "object", "properties": map[string]any{"token": map[string]any{"type": "string"}}}
+	s := JSONSchema(map[string]map[string]any{"telegram": opts}, nil)
+	plat := nestedSchema(s, "projects", "items", "platforms", "items")
+	if plat == nil {
+		t.Fatal("no schema for projects.platforms")
+	}
+	typ := nestedSchema(plat, "type")
+	if enum, _ := typ["enum"].([]string); len(enum) != 1 || enum[0] != "telegram" {
+		t.Errorf("platform type schema = %v", typ)
+	}
+	cases, _ := plat["allOf"].([]any)
+	if len(cases) != 1 {
+		t.Fatalf("allOf = %v", plat["allOf"])
+	}
+	if got := nestedSchema(s, "idle_timeout_mins")["type"]; got != "integer" {
+		t.Errorf("idle_timeout_mins type = %v", got)
+	}
+	if s["additionalProperties"] != false {
+		t.Error("top level should reject unknown keys")
+	}
+}
@@ -122,6 +122,16 @@ func (s *APIServer) RegisterEngine(name string, e *Engine) {
 	}
 }
 
+// UnregisterEngine removes a project that was dropped by a config reload.
+func (s *APIServer) UnregisterEngine(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.engines, name)
+	if s.relay != nil {
+		s.relay.UnregisterEngine(name)
+	}
+}
+
 func (s *APIServer) SetRelayManager(rm *RelayManager) {
 	s.relay = rm
 }
@@ -315,17 +325,26 @@ func (s *APIServer) handleSessions(w http.ResponseWriter, r *http.Request) {
 
 // CronAddRequest is the JSON body for POST /cron/add.
 type CronAddRequest struct {
-	Project     string `json:"project"`
-	SessionKey  string `json:"session_key"`
-	CronExpr    string `json:"cron_expr"`
-	Prompt      string `json:"prompt"`
-	Exec        string `json:"exec"`
-	WorkDir     string `json:"work_dir"`
-	Description string `json:"description"`
-	Silent      *bool  `json:"silent,omitempty"`
-	SessionMode string `json:"session_mode,omitempty"`
-	Mode        string `json:"mode,omitempty"`
-	TimeoutMins *int   `json:"timeout_mins,omitempty"`
+	Project      string   `json:"project"`
+	SessionKey   string   `json:"session_key"`
+	CronExpr     string   `json:"cron_expr"`
+	Prompt       string   `json:"prompt"`
+	Exec         string   `json:"exec"`
+	WorkDir      string   `json:"work_dir"`
+	Precheck     string   `json:"precheck,omitempty"`
+	Description  string   `json:"description"`
+	Silent       *bool    `json:"silent,omitempty"`
+	SessionMode  string   `json:"session_mode,omitempty"`
+	Mode         string   `json:"mode,omitempty"`
+	TimeoutMins  *int     `json:"timeout_mins,omitempty"`
+	Retries      int      `json:"retries,omitempty"`
+	RetryDelay   string   `json:"retry_delay,omitempty"`
+	OnFailure    string   `json:"on_failure,omitempty"`
+	Then         string   `json:"then,omitempty"`
+	Timezone     string   `json:"timezone,omitempty"`
+	ExcludeDates []string `json:"exclude_dates,omitempty"`
+	ExcludeICS   string   `json:"exclude_ics,omitempty"`
+	Jitter       string   `json:"jitter,omitempty"`
 }
 
 func (s *APIServer) handleCronAdd(w http.ResponseWriter, r *http.Request) {
@@ -392,19 +411,28 @@ func (s *APIServer) handleCronAdd(w http.ResponseWriter, r *http.Request) {
 	}
 
 	job := &CronJob{
-		ID:          GenerateCronID(),
-		Project:     project,
-		SessionKey:  sessionKey,
-		CronExpr:    req.CronExpr,
-		Prompt:      req.Prompt,
-		Exec:        req.Exec,
-		WorkDir:     req.WorkDir,
-		Description: req.Description,
-		Enabled:     true,
-		Silent:      req.Silent,
-		SessionMode: NormalizeCronSessionMode(req.SessionMode),
-		Mode:        req.Mode,
-		TimeoutMins: req.TimeoutMins,
+		ID:           GenerateCronID(),
+		Project:      project,
+		SessionKey:   sessionKey,
+		CronExpr:     req.CronExpr,
+		Prompt:       req.Prompt,
+		Exec:         req.Exec,
+		WorkDir:      req.WorkDir,
+		Precheck:     req.Precheck,
+		Description:  req.Description,
+		Enabled:      true,
+		Silent:       req.Silent,
+		SessionMode:  NormalizeCronSessionMode(req.SessionMode),
+		Mode:         req.Mode,
+		TimeoutMins:  req.TimeoutMins,
+		Retries:      req.Retries,
+		RetryDelay:   req.RetryDelay,
+		OnFailure:    req.OnFailure,
+		Then:         req.Then,
+		Timezone:     req.Timezone,
+		ExcludeDates: req.ExcludeDates,
+		ExcludeICS:   req.ExcludeICS,
+		Jitter:       req.Jitter,
 	}
 	job.CreatedAt = time.Now()
 
@@ -521,7 +549,14 @@ func (s *APIServer) handleCronInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	apiJSON(w, http.StatusOK, job)
+	apiJSON(w, http.StatusOK, cronInfoResponse{CronJob: job, Runs: s.cron.store.Runs(id)})
+}
+
+// cronInfoResponse is the GET /cron/info payload: the job fields plus its
+// recorded run history (newest first).
+type cronInfoResponse struct {
+	*CronJob
+	Runs []CronRun `json:"runs,omitempty"`
 }
 
 func (s *APIServer) handleCronEdit(w http.ResponseWriter, r *http.Request) {
@@ -576,6 +611,7 @@ type TimerAddRequest struct {
 	Prompt      string `json:"prompt"`
 	Exec        string `json:"exec"`
 	WorkDir     string `json:"work_dir"`
+	Precheck    string `json:"precheck,omitempty"`
 	Description string `json:"description"`
 	Silent      *bool  `json:"silent,omitempty"`
 	Mute        bool   `json:"mute,omitempty"`
@@ -658,6 +694,7 @@ func (s *APIServer) handleTimerAdd(w http.ResponseWriter, r *http.Request) {
 		Prompt:      req.Prompt,
 		Exec:        req.Exec,
 		WorkDir:     req.WorkDir,
+		Precheck:    req.Precheck,
 		Description: req.Description,
 		Silent:      req.Silent,
 		Mute:        req.Mute,
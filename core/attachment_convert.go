@@ -0,0 +1,384 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// AttachmentConverter turns a saved inbound attachment into files an agent
+// can read (text, markdown, unpacked archive contents). Derived files are
+// written next to the original.
+type AttachmentConverter interface {
+	// Name identifies the converter in logs.
+	Name() string
+	// Accepts reports whether the converter handles the file.
+	Accepts(path, mimeType string) bool
+	// Convert writes the derived files and returns their paths.
+	Convert(ctx context.Context, path string, cfg AttachmentConvertCfg) ([]string, error)
+}
+
+// AttachmentConvertCfg controls inbound attachment conversion.
+type AttachmentConvertCfg struct {
+	Enabled         bool
+	PDFToText       string // pdftotext binary; PDFs are passed through when it is not installed
+	MaxArchiveFiles int    // files unpacked per archive
+	MaxArchiveBytes int64  // total unpacked bytes per archive
+	MaxTextBytes    int64  // extracted text kept per document
+}
+
+const (
+	defaultMaxArchiveFiles = 200
+	defaultMaxArchiveBytes = 100 << 20
+	defaultMaxTextBytes    = 1 << 20
+	attachmentConvertLimit = time.Minute
+	// maxNestedConversions caps the documents converted inside one
+	// archive; the rest are unpacked but left as they are.
+	maxNestedConversions = 20
+)
+
+var (
+	attachmentConvertMu  sync.RWMutex
+	attachmentConvertCfg AttachmentConvertCfg
+	attachmentConverters []AttachmentConverter
+)
+
+// SetAttachmentConversion configures the conversion SaveFilesToDisk applies
+// to inbound files. It is called on every config load and reload.
+func SetAttachmentConversion(cfg AttachmentConvertCfg) {
+	if cfg.PDFToText == "" {
+		cfg.PDFToText = "pdftotext"
+	}
+	if cfg.MaxArchiveFiles <= 0 {
+		cfg.MaxArchiveFiles = defaultMaxArchiveFiles
+	}
+	if cfg.MaxArchiveBytes <= 0 {
+		cfg.MaxArchiveBytes = defaultMaxArchiveBytes
+	}
+	if cfg.MaxTextBytes <= 0 {
+		cfg.MaxTextBytes = defaultMaxTextBytes
+	}
+	attachmentConvertMu.Lock()
+	attachmentConvertCfg = cfg
+	attachmentConvertMu.Unlock()
+}
+
+// RegisterAttachmentConverter adds a converter. Converters are tried in
+// registration order and the first one that accepts a file handles it.
+func RegisterAttachmentConverter(c AttachmentConverter) {
+	attachmentConvertMu.Lock()
+	attachmentConverters = append(attachmentConverters, c)
+	attachmentConvertMu.Unlock()
+}
+
+func init() {
+	RegisterAttachmentConverter(archiveConverter{})
+	RegisterAttachmentConverter(officeConverter{})
+	RegisterAttachmentConverter(pdfConverter{})
+}
+
+// convertAttachment runs the first matching converter on a saved file and
+// returns the derived paths. Failures are logged; the original file is
+// always kept.
+func convertAttachment(path, mimeType string) []string {
+	attachmentConvertMu.RLock()
+	cfg := attachmentConvertCfg
+	converters := attachmentConverters
+	attachmentConvertMu.RUnlock()
+	if !cfg.Enabled {
+		return nil
+	}
+	return runAttachmentConverters(context.Background(), converters, path, mimeType, cfg)
+}
+
+// runAttachmentConverters runs the first converter that accepts path. The
+// conversion gets attachmentConvertLimit, cut short by any deadline of ctx.
+func runAttachmentConverters(ctx context.Context, converters []AttachmentConverter, path, mimeType string, cfg AttachmentConvertCfg) []string {
+	for _, c := range converters {
+		if !c.Accepts(path, mimeType) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(ctx, attachmentConvertLimit)
+		out, err := c.Convert(ctx, path, cfg)
+		cancel()
+		if err != nil {
+			slog.Warn("attachment conversion failed", "converter", c.Name(), "path", path, "error", err)
+		} else if len(out) > 0 {
+			slog.Debug("attachment converted", "converter", c.Name(), "path", path, "outputs", len(out))
+		}
+		return out
+	}
+	return nil
+}
+
+func attachmentExt(name string) string {
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz"} {
+		if strings.HasSuffix(lower, ext) {
+			return ext
+		}
+	}
+	return filepath.Ext(lower)
+}
+
+// writeDerivedText writes text to name, keeping at most max bytes.
+func writeDerivedText(name, text string, max int64) error {
+	if int64(len(text)) > max {
+		cut := int(max)
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + fmt.Sprintf("\n\n[truncated: %d bytes omitted]\n", len(text)-cut)
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, text); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ── archives ────────────────────────────────────────────────
+
+// archiveConverter unpacks zip and tar archives into "<name>_unpacked/"
+// and converts up to maxNestedConversions documents inside, all within the
+// archive's deadline. Entries escaping the directory, links and anything
+// beyond the configured limits are skipped.
+type archiveConverter struct{}
+
+func (archiveConverter) Name() string { return "archive" }
+
+func (archiveConverter) Accepts(path, mimeType string) bool {
+	switch attachmentExt(path) {
+	case ".zip", ".tar", ".tgz", ".tar.gz":
+		return true
+	}
+	return mimeType == "application/zip" && filepath.Ext(path) == ""
+}
+
+var errArchiveLimit = errors.New("archive exceeds unpack limits")
+
+func (archiveConverter) Convert(ctx context.Context, src string, cfg AttachmentConvertCfg) ([]string, error) {
+	ext := attachmentExt(src)
+	dest := strings.TrimSuffix(src, filepath.Ext(src))
+	if ext == ".tar.gz" {
+		dest = src[:len(src)-len(ext)]
+	}
+	dest += "_unpacked"
+	if err := os.Mkdir(dest, 0o755); err != nil {
+		return nil, err
+	}
+	u := &archiveUnpacker{dest: dest, cfg: cfg}
+	var err error
+	switch ext {
+	case ".tar", ".tgz", ".tar.gz":
+		err = u.untar(ctx, src, ext != ".tar")
+	default:
+		err = u.unzip(ctx, src)
+	}
+	if errors.Is(err, errArchiveLimit) {
+		slog.Warn("attachment archive truncated", "path", src, "files", u.files, "bytes", u.bytes)
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert documents inside the archive, but do not unpack nested
+	// archives. The conversions share the archive's deadline.
+	attachmentConvertMu.RLock()
+	converters := attachmentConverters
+	attachmentConvertMu.RUnlock()
+	var nested []AttachmentConverter
+	for _, c := range converters {
+		if _, isArchive := c.(archiveConverter); !isArchive {
+			nested = append(nested, c)
+		}
+	}
+	converted := 0
+	for _, name := range u.written {
+		if ctx.Err() != nil {
+			slog.Warn("attachment archive conversion stopped", "path", src, "converted", converted, "error", ctx.Err())
+			break
+		}
+		if !acceptedByAny(nested, name) {
+			continue
+		}
+		if converted == maxNestedConversions {
+			slog.Warn("attachment archive has too many documents to convert", "path", src, "limit", maxNestedConversions)
+			break
+		}
+		runAttachmentConverters(ctx, nested, name, "", cfg)
+		converted++
+	}
+	return []string{dest}, nil
+}
+
+func acceptedByAny(converters []AttachmentConverter, path string) bool {
+	for _, c := range converters {
+		if c.Accepts(path, "") {
+			return true
+		}
+	}
+	return false
+}
+
+type archiveUnpacker struct {
+	dest    string
+	cfg     AttachmentConvertCfg
+	files   int
+	bytes   int64
+	written []string
+}
+
+// target maps an entry name to a path under dest, or "" if it would escape.
+func (u *archiveUnpacker) target(name string) string {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	if name == "/" {
+		return ""
+	}
+	return filepath.Join(u.dest, filepath.FromSlash(name[1:]))
+}
+
+func (u *archiveUnpacker) extract(ctx context.Context, name string, mode fs.FileMode, r io.Reader) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !mode.IsRegular() {
+		return nil
+	}
+	dst := u.target(name)
+	if dst == "" {
+		return nil
+	}
+	if u.files >= u.cfg.MaxArchiveFiles {
+		return errArchiveLimit
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	remaining := u.cfg.MaxArchiveBytes - u.bytes
+	n, err := io.Copy(f, io.LimitReader(r, remaining+1))
+	_ = f.Close()
+	u.files++
+	u.bytes += n
+	if err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	if n > remaining {
+		_ = os.Remove(dst)
+		return errArchiveLimit
+	}
+	u.written = append(u.written, dst)
+	return nil
+}
+
+func (u *archiveUnpacker) unzip(ctx context.Context, src string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		err = u.extract(ctx, f.Name, f.Mode(), rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *archiveUnpacker) untar(ctx context.Context, src string, gzipped bool) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := u.extract(ctx, hdr.Name, hdr.FileInfo().Mode(), tr); err != nil {
+			return err
+		}
+	}
+}
+
+// ── PDF ─────────────────────────────────────────────────────
+
+// pdfConverter extracts PDF text to "<name>.txt" with the external
+// pdftotext tool (poppler-utils), when it is installed.
+type pdfConverter struct{}
+
+func (pdfConverter) Name() string { return "pdftotext" }
+
+func (pdfConverter) Accepts(path, mimeType string) bool {
+	return attachmentExt(path) == ".pdf" || mimeType == "application/pdf"
+}
+
+func (pdfConverter) Convert(ctx context.Context, src string, cfg AttachmentConvertCfg) ([]string, error) {
+	bin, err := exec.LookPath(cfg.PDFToText)
+	if err != nil {
+		slog.Debug("attachment conversion: pdftotext not available", "binary", cfg.PDFToText)
+		return nil, nil
+	}
+	out, err := exec.CommandContext(ctx, bin, "-layout", "-enc", "UTF-8", src, "-").Output()
+	if err != nil {
+		return nil, fmt.Errorf("pdftotext: %w", err)
+	}
+	if strings.TrimSpace(string(out)) == "" {
+		return nil, nil // scanned PDF without a text layer
+	}
+	dst := src + ".txt"
+	if err := writeDerivedText(dst, string(out), cfg.MaxTextBytes); err != nil {
+		return nil, err
+	}
+	return []string{dst}, nil
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := files[name]
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func enableAttachmentConversion(t *testing.T, cfg AttachmentConvertCfg) {
+	t.Helper()
+	cfg.Enabled = true
+	SetAttachmentConversion(cfg)
+	t.Cleanup(func() { SetAttachmentConversion(AttachmentConvertCfg{}) })
+}
+
+const testDocx = `<w:document xmlns:w="w"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Quarterly report</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Revenue grew </w:t></w:r><w:r><w:t>12%.</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>Region</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>Sales</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>EU</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>4|2</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+</w:body></w:document>`
+
+func TestSaveFilesToDisk_ConvertsDocx(t *testing.T) {
+	enableAttachmentConversion(t, AttachmentConvertCfg{})
+	paths := SaveFilesToDisk(t.TempDir(), "m1", []FileAttachment{{
+		FileName: "report.docx",
+		Data:     buildZip(t, map[string]string{"word/document.xml": testDocx}),
+	}})
+	if len(paths) != 2 || paths[1] != paths[0]+".md" {
+		t.Fatalf("paths = %v", paths)
+	}
+	got, err := os.ReadFile(paths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Quarterly report\n\nRevenue grew 12%.\n\n| Region | Sales |\n| --- | --- |\n| EU | 4\\|2 |\n\n"
+	if string(got) != want {
+		t.Fatalf("markdown =\n%q\nwant\n%q", got, want)
+	}
+	if prompt := AppendFileRefs("read this", paths); !strings.Contains(prompt, paths[0]) || !strings.Contains(prompt, paths[1]) {
+		t.Fatalf("prompt = %q", prompt)
+	}
+}
+
+func TestSaveFilesToDisk_ConversionDisabledByDefault(t *testing.T) {
+	paths := SaveFilesToDisk(t.TempDir(), "m1", []FileAttachment{{
+		FileName: "report.docx",
+		Data:     buildZip(t, map[string]string{"word/document.xml": testDocx}),
+	}})
+	if len(paths) != 1 {
+		t.Fatalf("paths = %v", paths)
+	}
+}
+
+func TestXLSXToMarkdown(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"xl/workbook.xml":            `<workbook><sheets><sheet name="Budget" sheetId="1" r:id="rId1" xmlns:r="r"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Item</t></si><si><r><t>Cost</t></r></si><si><t>Rent</t></si></sst>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>1200</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>Paid</t></is></c><c r="B3" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+	})
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := xlsxToMarkdown(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Budget\n\n| Item | Cost |  |\n| --- | --- | --- |\n| Rent |  | 1200 |\n| Paid | TRUE |  |\n\n"
+	if got != want {
+		t.Fatalf("markdown =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestPPTXToMarkdown_SlidesInNumericOrder(t *testing.T) {
+	slide := func(lines ...string) string {
+		var sb strings.Builder
+		sb.WriteString(`<p:sld xmlns:a="a" xmlns:p="p"><p:cSld><p:spTree>`)
+		for _, l := range lines {
+			sb.WriteString("<a:p><a:r><a:t>" + l + "</a:t></a:r></a:p>")
+		}
+		sb.WriteString(`</p:spTree></p:cSld></p:sld>`)
+		return sb.String()
+	}
+	data := buildZip(t, map[string]string{
+		"ppt/slides/slide10.xml":           slide("Thanks"),
+		"ppt/slides/slide2.xml":            slide("Agenda", "Results"),
+		"ppt/slides/slide1.xml":            slide("Title"),
+		"ppt/slides/_rels/slide1.xml.rels": "<Relationships/>",
+	})
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := pptxToMarkdown(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Slide 1\n\nTitle\n\n## Slide 2\n\nAgenda\nResults\n\n## Slide 10\n\nThanks\n\n"
+	if got != want {
+		t.Fatalf("markdown =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestArchiveConverter_UnpacksWithinLimits(t *testing.T) {
+	enableAttachmentConversion(t, AttachmentConvertCfg{MaxArchiveFiles: 3})
+	paths := SaveFilesToDisk(t.TempDir(), "m1", []FileAttachment{{
+		FileName: "bundle.zip",
+		Data: buildZip(t, map[string]string{
+			"../escape.txt":    "nope",
+			"docs/notes.docx":  string(buildZip(t, map[string]string{"word/document.xml": testDocx})),
+			"src/main.go":      "package main",
+			"src/extra/one.go": "package extra",
+			"src/extra/two.go": "package extra",
+		}),
+	}})
+	if len(paths) != 2 || filepath.Base(paths[1]) != "bundle_unpacked" {
+		t.Fatalf("paths = %v", paths)
+	}
+	dir := paths[1]
+	if _, err := os.Stat(filepath.Join(dir, "escape.txt")); err != nil {
+		t.Fatalf("escaping entry not contained in the unpack directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs", "notes.docx.md")); err != nil {
+		t.Fatalf("nested docx not converted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "src", "main.go")); err == nil {
+		t.Fatal("file beyond max_archive_files was unpacked")
+	}
+}
+
+func TestArchiveConverter_ByteLimit(t *testing.T) {
+	enableAttachmentConversion(t, AttachmentConvertCfg{MaxArchiveBytes: 10})
+	paths := SaveFilesToDisk(t.TempDir(), "m1", []FileAttachment{{
+		FileName: "big.zip",
+		Data:     buildZip(t, map[string]string{"big.txt": strings.Repeat("x", 100)}),
+	}})
+	if len(paths) != 2 {
+		t.Fatalf("paths = %v", paths)
+	}
+	if _, err := os.Stat(filepath.Join(paths[1], "big.txt")); err == nil {
+		t.Fatal("file over the byte limit was kept")
+	}
+}
+
+// countingConverter accepts ".doc" files and records each conversion.
+type countingConverter struct {
+	calls    *int
+	onConv   func()
+	deadline *time.Time
+}
+
+func (countingConverter) Name() string { return "counting" }
+
+func (countingConverter) Accepts(path, _ string) bool { return strings.HasSuffix(path, ".doc") }
+
+func (c countingConverter) Convert(ctx context.Context, _ string, _ AttachmentConvertCfg) ([]string, error) {
+	*c.calls++
+	*c.deadline, _ = ctx.Deadline()
+	if c.onConv != nil {
+		c.onConv()
+	}
+	return nil, nil
+}
+
+func useAttachmentConverters(t *testing.T, converters ...AttachmentConverter) {
+	t.Helper()
+	attachmentConvertMu.Lock()
+	saved := attachmentConverters
+	attachmentConverters = converters
+	attachmentConvertMu.Unlock()
+	t.Cleanup(func() {
+		attachmentConvertMu.Lock()
+		attachmentConverters = saved
+		attachmentConvertMu.Unlock()
+	})
+}
+
+func TestArchiveConverter_NestedConversionsShareDeadlineAndAreCapped(t *testing.T) {
+	files := map[string]string{"readme.txt": "hi"}
+	for i := range maxNestedConversions + 5 {
+		files[fmt.Sprintf("doc%02d.doc", i)] = "x"
+	}
+	var calls int
+	var deadline time.Time
+	useAttachmentConverters(t, archiveConverter{}, countingConverter{calls: &calls, deadline: &deadline})
+
+	src := filepath.Join(t.TempDir(), "docs.zip")
+	if err := os.WriteFile(src, buildZip(t, files), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archiveDeadline := time.Now().Add(5 * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), archiveDeadline)
+	defer cancel()
+	cfg := AttachmentConvertCfg{MaxArchiveFiles: 100, MaxArchiveBytes: 1 << 20}
+	if _, err := (archiveConverter{}).Convert(ctx, src, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if calls != maxNestedConversions {
+		t.Errorf("nested conversions = %d, want %d", calls, maxNestedConversions)
+	}
+	if deadline.After(archiveDeadline) {
+		t.Errorf("nested deadline %v is later than the archive's %v", deadline, archiveDeadline)
+	}
+
+	// Once the archive's context ends, no further documents are converted.
+	calls = 0
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	stopping := countingConverter{calls: &calls, deadline: &deadline, onConv: cancel}
+	useAttachmentConverters(t, archiveConverter{}, stopping)
+	src = filepath.Join(t.TempDir(), "docs.zip")
+	if err := os.WriteFile(src, buildZip(t, files), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (archiveConverter{}).Convert(ctx, src, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("conversions after the deadline = %d, want 1", calls)
+	}
+}
+
+func TestWriteDerivedText_Truncates(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "out.txt")
+	if err := writeDerivedText(name, "héllo world", 2); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(name)
+	if !strings.HasPrefix(string(got), "h\n\n[truncated: 11 bytes omitted]") {
+		t.Fatalf("got %q", got)
+	}
+}
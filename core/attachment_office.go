@@ -0,0 +1,502 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// officeConverter extracts the text of Office Open XML documents (docx,
+// xlsx, pptx) into "<name>.md" without external tools.
+type officeConverter struct{}
+
+func (officeConverter) Name() string { return "office" }
+
+func (officeConverter) Accepts(path, _ string) bool {
+	switch attachmentExt(path) {
+	case ".docx", ".xlsx", ".pptx":
+		return true
+	}
+	return false
+}
+
+func (officeConverter) Convert(_ context.Context, src string, cfg AttachmentConvertCfg) ([]string, error) {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var text string
+	switch attachmentExt(src) {
+	case ".docx":
+		text, err = docxToMarkdown(&zr.Reader)
+	case ".xlsx":
+		text, err = xlsxToMarkdown(&zr.Reader)
+	case ".pptx":
+		text, err = pptxToMarkdown(&zr.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	dst := src + ".md"
+	if err := writeDerivedText(dst, text, cfg.MaxTextBytes); err != nil {
+		return nil, err
+	}
+	return []string{dst}, nil
+}
+
+// maxOfficePartBytes bounds a single XML part read from a document, so a
+// crafted file cannot expand into gigabytes of memory.
+const maxOfficePartBytes = 64 << 20
+
+func readZipPart(r *zip.Reader, name string) ([]byte, error) {
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, maxOfficePartBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxOfficePartBytes {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
+
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// markdownCell makes s safe to place inside a markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeMarkdownTable(sb *strings.Builder, rows [][]string) {
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return
+	}
+	for i, row := range rows {
+		cells := make([]string, width)
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = markdownCell(row[j])
+			}
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", width) + "\n")
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// ── docx ────────────────────────────────────────────────────
+
+var docxHeadingStyle = regexp.MustCompile(`^(?i:heading)\s*([1-6])$`)
+
+func docxToMarkdown(r *zip.Reader) (string, error) {
+	data, err := readZipPart(r, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		out     strings.Builder
+		para    strings.Builder
+		heading int
+		inText  bool
+		depth   int // table nesting
+		rows    [][]string
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				heading = 0
+			case "pStyle":
+				if m := docxHeadingStyle.FindStringSubmatch(xmlAttr(t, "val")); m != nil {
+					heading, _ = strconv.Atoi(m[1])
+				}
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteByte('\t')
+			case "br", "cr":
+				para.WriteByte('\n')
+			case "tbl":
+				depth++
+				if depth == 1 {
+					rows = nil
+				}
+			case "tr":
+				if depth == 1 {
+					rows = append(rows, nil)
+				}
+			case "tc":
+				if depth == 1 && len(rows) > 0 {
+					rows[len(rows)-1] = append(rows[len(rows)-1], "")
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				if text == "" {
+					continue
+				}
+				if depth > 0 {
+					if len(rows) > 0 && len(rows[len(rows)-1]) > 0 {
+						row := rows[len(rows)-1]
+						row[len(row)-1] = strings.TrimSpace(row[len(row)-1] + " " + text)
+					}
+					continue
+				}
+				if heading > 0 {
+					out.WriteString(strings.Repeat("#", heading) + " ")
+				}
+				out.WriteString(text + "\n\n")
+			case "tbl":
+				depth--
+				if depth == 0 {
+					writeMarkdownTable(&out, rows)
+				}
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+// ── xlsx ────────────────────────────────────────────────────
+
+func xlsxToMarkdown(r *zip.Reader) (string, error) {
+	var shared []string
+	if data, err := readZipPart(r, "xl/sharedStrings.xml"); err == nil {
+		if shared, err = xlsxSharedStrings(data); err != nil {
+			return "", err
+		}
+	}
+	sheets, err := xlsxSheets(r)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for _, s := range sheets {
+		data, err := readZipPart(r, s.part)
+		if err != nil {
+			return "", err
+		}
+		rows, err := xlsxRows(data, shared)
+		if err != nil {
+			return "", fmt.Errorf("sheet %s: %w", s.name, err)
+		}
+		out.WriteString("## " + s.name + "\n\n")
+		writeMarkdownTable(&out, rows)
+	}
+	return out.String(), nil
+}
+
+func xlsxSharedStrings(data []byte) ([]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		out    []string
+		cur    strings.Builder
+		inText bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "t":
+				inText = true
+			case "rPh": // phonetic hints would duplicate the text
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, cur.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				cur.Write(t)
+			}
+		}
+	}
+}
+
+type xlsxSheet struct {
+	name string
+	part string
+}
+
+// xlsxSheets lists worksheets in workbook order with their part names.
+func xlsxSheets(r *zip.Reader) ([]xlsxSheet, error) {
+	rels := map[string]string{}
+	if data, err := readZipPart(r, "xl/_rels/workbook.xml.rels"); err == nil {
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				break
+			}
+			if el, ok := tok.(xml.StartElement); ok && el.Name.Local == "Relationship" {
+				target := xmlAttr(el, "Target")
+				if strings.HasPrefix(target, "/") {
+					target = strings.TrimPrefix(target, "/")
+				} else {
+					target = path.Join("xl", target)
+				}
+				rels[xmlAttr(el, "Id")] = target
+			}
+		}
+	}
+	data, err := readZipPart(r, "xl/workbook.xml")
+	if err != nil {
+		return nil, err
+	}
+	var sheets []xlsxSheet
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		el, ok := tok.(xml.StartElement)
+		if !ok || el.Name.Local != "sheet" {
+			continue
+		}
+		part := rels[xmlAttr(el, "id")]
+		if part == "" {
+			part = fmt.Sprintf("xl/worksheets/sheet%d.xml", len(sheets)+1)
+		}
+		sheets = append(sheets, xlsxSheet{name: xmlAttr(el, "name"), part: part})
+	}
+	return sheets, nil
+}
+
+// xlsxColumn returns the zero-based column of a cell reference like "C7".
+func xlsxColumn(ref string) int {
+	col := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+	}
+	return col - 1
+}
+
+// maxXLSXColumns keeps a stray cell far to the right from padding every
+// row with thousands of empty cells.
+const maxXLSXColumns = 256
+
+func xlsxRows(data []byte, shared []string) ([][]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		rows      [][]string
+		row       []string
+		col       int
+		cellType  string
+		value     strings.Builder
+		inValue   bool
+		rowHasVal bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row, rowHasVal = nil, false
+			case "c":
+				cellType = xmlAttr(t, "t")
+				col = len(row)
+				if ref := xmlAttr(t, "r"); ref != "" {
+					col = xlsxColumn(ref)
+				}
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				v := value.String()
+				switch cellType {
+				case "s":
+					if i, err := strconv.Atoi(v); err == nil && i >= 0 && i < len(shared) {
+						v = shared[i]
+					}
+				case "b":
+					v = map[string]string{"0": "FALSE", "1": "TRUE"}[v]
+				}
+				if v == "" || col < 0 || col >= maxXLSXColumns {
+					continue
+				}
+				for len(row) <= col {
+					row = append(row, "")
+				}
+				row[col] = v
+				rowHasVal = true
+			case "row":
+				if rowHasVal {
+					rows = append(rows, row)
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// ── pptx ────────────────────────────────────────────────────
+
+var pptxSlidePart = regexp.MustCompile(`^ppt/slides/slide(\d+)\.xml$`)
+
+func pptxToMarkdown(r *zip.Reader) (string, error) {
+	type slide struct {
+		n    int
+		name string
+	}
+	var slides []slide
+	for _, f := range r.File {
+		if m := pptxSlidePart.FindStringSubmatch(f.Name); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			slides = append(slides, slide{n, f.Name})
+		}
+	}
+	sort.Slice(slides, func(i, j int) bool { return slides[i].n < slides[j].n })
+
+	var out strings.Builder
+	for _, s := range slides {
+		data, err := readZipPart(r, s.name)
+		if err != nil {
+			return "", err
+		}
+		lines, err := pptxSlideText(data)
+		if err != nil {
+			return "", fmt.Errorf("slide %d: %w", s.n, err)
+		}
+		fmt.Fprintf(&out, "## Slide %d\n\n", s.n)
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+		out.WriteString("\n")
+	}
+	return out.String(), nil
+}
+
+func pptxSlideText(data []byte) ([]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		lines  []string
+		para   strings.Builder
+		inText bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+			case "t":
+				inText = true
+			case "br":
+				para.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if text := strings.TrimSpace(para.String()); text != "" {
+					lines = append(lines, text)
+				}
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+}
@@ -0,0 +1,552 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Auto attach: when enabled for a project, the work dir is snapshotted
+// before every turn and, once the turn's reply is out, files the agent
+// created or modified that match the configured globs are offered as
+// attachments with a "send all / pick" card.
+
+// AutoAttachCfg controls offering files created during a turn.
+type AutoAttachCfg struct {
+	Enabled     bool
+	Patterns    []string // globs on the file name, or on the relative path when they contain "/"
+	MaxFileSize int64    // bytes; larger files are listed but cannot be sent
+	MaxFiles    int      // files listed per offer
+}
+
+// DefaultAutoAttachPatterns are used when no patterns are configured.
+var DefaultAutoAttachPatterns = []string{
+	"*.png", "*.jpg", "*.jpeg", "*.gif", "*.webp", "*.svg",
+	"*.pdf", "*.csv", "*.xlsx", "*.docx", "*.pptx", "*.zip",
+}
+
+const (
+	defaultAutoAttachMaxFileSize = 20 << 20
+	defaultAutoAttachMaxFiles    = 10
+	// maxSnapshotFiles bounds the work dir scan; larger trees are skipped.
+	maxSnapshotFiles = 20000
+	// attachOfferIDPrefix marks offer IDs in /attach arguments, so an ID
+	// is never mistaken for a file number.
+	attachOfferIDPrefix = "o-"
+)
+
+// SetAutoAttachConfig configures offering files created during a turn.
+func (e *Engine) SetAutoAttachConfig(cfg AutoAttachCfg) {
+	if len(cfg.Patterns) == 0 {
+		cfg.Patterns = DefaultAutoAttachPatterns
+	}
+	if cfg.MaxFileSize <= 0 {
+		cfg.MaxFileSize = defaultAutoAttachMaxFileSize
+	}
+	if cfg.MaxFiles <= 0 {
+		cfg.MaxFiles = defaultAutoAttachMaxFiles
+	}
+	e.autoAttach = cfg
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// workspaceSnapshot records size and mtime of every non-ignored file.
+type workspaceSnapshot struct {
+	dir   string
+	files map[string]fileStamp // slash-separated path relative to dir
+}
+
+var errSnapshotTooLarge = errors.New("too many files")
+
+func snapshotWorkspace(dir string) (*workspaceSnapshot, error) {
+	paths, err := listWorkspaceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	snap := &workspaceSnapshot{dir: dir, files: make(map[string]fileStamp, len(paths))}
+	for _, rel := range paths {
+		info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel)))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		snap.files[rel] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return snap, nil
+}
+
+// listWorkspaceFiles lists tracked and untracked files that git does not
+// ignore. Outside a git repository it walks the tree and applies the
+// top-level .gitignore.
+func listWorkspaceFiles(dir string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+	if err == nil {
+		var paths []string
+		for _, p := range bytes.Split(out, []byte{0}) {
+			if len(p) > 0 {
+				paths = append(paths, string(p))
+			}
+		}
+		if len(paths) > maxSnapshotFiles {
+			return nil, errSnapshotTooLarge
+		}
+		return paths, nil
+	}
+
+	ignore := readGitignore(filepath.Join(dir, ".gitignore"))
+	var paths []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || gitignoreMatch(ignore, rel, true) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if gitignoreMatch(ignore, rel, false) {
+			return nil
+		}
+		paths = append(paths, rel)
+		if len(paths) > maxSnapshotFiles {
+			return errSnapshotTooLarge
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// readGitignore returns the simple patterns of a .gitignore file. Negations
+// are not supported and are skipped.
+func readGitignore(name string) []string {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var patterns []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+func gitignoreMatch(patterns []string, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		dirOnly := strings.HasSuffix(p, "/")
+		p = strings.TrimSuffix(p, "/")
+		if dirOnly && !isDir {
+			continue
+		}
+		if anchored := strings.Contains(p, "/"); anchored {
+			if ok, _ := path.Match(strings.TrimPrefix(p, "/"), rel); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAutoAttachPattern matches a glob against the file name, or against
+// the relative path when the glob contains a "/".
+func matchAutoAttachPattern(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		target := path.Base(rel)
+		if strings.Contains(p, "/") {
+			target = rel
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// changedSince returns files in after that are new or modified relative to
+// s and match patterns, sorted by path.
+func (s *workspaceSnapshot) changedSince(after *workspaceSnapshot, patterns []string) []autoAttachFile {
+	var out []autoAttachFile
+	for rel, st := range after.files {
+		if prev, ok := s.files[rel]; ok && prev == st {
+			continue
+		}
+		if matchAutoAttachPattern(patterns, rel) {
+			out = append(out, autoAttachFile{rel: rel, size: st.size})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].rel < out[j].rel })
+	return out
+}
+
+type autoAttachFile struct {
+	rel  string
+	size int64
+	sent bool
+}
+
+// attachOffer is the pending offer of one turn's files.
+type attachOffer struct {
+	id         string
+	sessionKey string
+	platform   Platform
+	replyCtx   any
+	dir        string
+	files      []autoAttachFile
+	more       int // matching files left out by MaxFiles
+
+	mu     sync.Mutex // guards files[].sent and closed; held while sending
+	closed bool
+}
+
+// attachOffers keeps the latest offer per session.
+type attachOffers struct {
+	mu     sync.Mutex
+	offers map[string]*attachOffer
+}
+
+func (a *attachOffers) put(o *attachOffer) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.offers == nil {
+		a.offers = make(map[string]*attachOffer)
+	}
+	a.offers[o.sessionKey] = o
+}
+
+// get returns the session's latest offer; a non-empty id must match it.
+func (a *attachOffers) get(sessionKey, id string) *attachOffer {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	o := a.offers[sessionKey]
+	if o == nil || (id != "" && o.id != id) {
+		return nil
+	}
+	return o
+}
+
+// turnFilesDir returns the directory the agent works in for state.
+func (e *Engine) turnFilesDir(state *interactiveState) string {
+	state.mu.Lock()
+	dir, agent := state.workspaceDir, state.agent
+	state.mu.Unlock()
+	if dir != "" {
+		return dir
+	}
+	for _, a := range []Agent{agent, e.GetAgent()} {
+		if wd, ok := a.(interface{ GetWorkDir() string }); ok {
+			if d := strings.TrimSpace(wd.GetWorkDir()); d != "" {
+				return d
+			}
+		}
+	}
+	return ""
+}
+
+// beginTurnFiles snapshots the work dir before a turn is sent to the agent.
+func (e *Engine) beginTurnFiles(state *interactiveState) {
+	if !e.autoAttach.Enabled {
+		return
+	}
+	var snap *workspaceSnapshot
+	if dir := e.turnFilesDir(state); dir != "" {
+		var err error
+		if snap, err = snapshotWorkspace(dir); err != nil {
+			slog.Warn("auto attach: snapshot failed", "dir", dir, "error", err)
+			snap = nil
+		}
+	}
+	state.mu.Lock()
+	state.turnFiles = snap
+	state.mu.Unlock()
+}
+
+// offerTurnFiles offers the files created or modified since beginTurnFiles.
+func (e *Engine) offerTurnFiles(state *interactiveState, p Platform, replyCtx any, sessionKey string) {
+	state.mu.Lock()
+	before := state.turnFiles
+	state.turnFiles = nil
+	state.mu.Unlock()
+	if before == nil || !e.autoAttach.Enabled || !e.attachmentSendEnabled {
+		return
+	}
+	_, files := p.(FileSender)
+	_, images := p.(ImageSender)
+	if !files && !images {
+		return
+	}
+	after, err := snapshotWorkspace(before.dir)
+	if err != nil {
+		slog.Warn("auto attach: snapshot failed", "dir", before.dir, "error", err)
+		return
+	}
+	changed := before.changedSince(after, e.autoAttach.Patterns)
+	if len(changed) == 0 {
+		return
+	}
+	o := &attachOffer{
+		id:         attachOfferIDPrefix + newShortID(),
+		sessionKey: sessionKey,
+		platform:   p,
+		replyCtx:   replyCtx,
+		dir:        before.dir,
+		files:      changed,
+	}
+	if len(changed) > e.autoAttach.MaxFiles {
+		o.files, o.more = changed[:e.autoAttach.MaxFiles], len(changed)-e.autoAttach.MaxFiles
+	}
+	e.attachOffers.put(o)
+	slog.Info("auto attach: offering files", "session", sessionKey, "files", len(o.files), "more", o.more)
+	e.sendAttachOffer(p, replyCtx, o)
+}
+
+func (e *Engine) sendAttachOffer(p Platform, replyCtx any, o *attachOffer) {
+	if supportsCards(p) {
+		e.sendWithCard(p, replyCtx, e.renderAttachOfferCard(o, "act", ""))
+		return
+	}
+	card := e.renderAttachOfferCard(o, "cmd", "")
+	if _, ok := p.(InlineButtonSender); ok {
+		e.replyWithButtons(p, replyCtx, e.attachOfferText(o), card.CollectButtons())
+		return
+	}
+	e.send(p, replyCtx, e.attachOfferText(o)+"\n\n"+e.i18n.T(MsgAutoAttachHint))
+}
+
+func formatFileSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func (e *Engine) attachOfferLine(i int, f autoAttachFile) string {
+	line := fmt.Sprintf("%d. `%s` · %s", i+1, f.rel, formatFileSize(f.size))
+	switch {
+	case f.sent:
+		line += " ✅"
+	case f.size > e.autoAttach.MaxFileSize:
+		line += " " + e.i18n.T(MsgAutoAttachTooLarge)
+	}
+	return line
+}
+
+func (e *Engine) attachOfferText(o *attachOffer) string {
+	var sb strings.Builder
+	sb.WriteString(e.i18n.Tf(MsgAutoAttachTitle, len(o.files)+o.more))
+	for i, f := range o.files {
+		sb.WriteString("\n")
+		sb.WriteString(e.attachOfferLine(i, f))
+	}
+	if o.more > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(e.i18n.Tf(MsgAutoAttachMore, o.more))
+	}
+	return sb.String()
+}
+
+// renderAttachOfferCard lists the offered files with a send button each,
+// plus "send all" and "dismiss". prefix is "act" for card callbacks or
+// "cmd" for inline buttons that re-enter as /attach commands.
+func (e *Engine) renderAttachOfferCard(o *attachOffer, prefix, note string) *Card {
+	action := func(arg string) string { return fmt.Sprintf("%s:/attach %s %s", prefix, o.id, arg) }
+	cb := NewCard().Title(e.i18n.Tf(MsgAutoAttachTitle, len(o.files)+o.more), "blue")
+	for i, f := range o.files {
+		if o.closed || f.sent || f.size > e.autoAttach.MaxFileSize {
+			cb.Markdown(e.attachOfferLine(i, f))
+			continue
+		}
+		cb.ListItem(e.attachOfferLine(i, f), e.i18n.T(MsgAutoAttachSend), action(strconv.Itoa(i+1)))
+	}
+	if o.more > 0 {
+		cb.Note(e.i18n.Tf(MsgAutoAttachMore, o.more))
+	}
+	if note != "" {
+		cb.Note(note)
+	}
+	if !o.closed {
+		cb.Buttons(
+			PrimaryBtn(e.i18n.T(MsgAutoAttachSendAll), action("all")),
+			DefaultBtn(e.i18n.T(MsgAutoAttachDismiss), action("dismiss")),
+		)
+	}
+	return cb.Build()
+}
+
+// parseAttachArgs splits "[id] all|dismiss|<n>..." into the offer id, the
+// 1-based file numbers to send (nil for all) and whether to dismiss. IDs
+// carry attachOfferIDPrefix; other arguments that are not numbers are ignored.
+func parseAttachArgs(args []string) (id string, picks []int, dismiss bool) {
+	for _, a := range args {
+		switch {
+		case strings.HasPrefix(a, attachOfferIDPrefix):
+			id = a
+		case strings.EqualFold(a, "all"):
+		case strings.EqualFold(a, "dismiss"):
+			dismiss = true
+		default:
+			if n, err := strconv.Atoi(a); err == nil {
+				picks = append(picks, n)
+			}
+		}
+	}
+	return id, picks, dismiss
+}
+
+// sendOfferedFiles sends the picked files (all when picks is empty) and
+// returns a summary for the user.
+func (e *Engine) sendOfferedFiles(o *attachOffer, picks []int) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(picks) == 0 {
+		for i := range o.files {
+			picks = append(picks, i+1)
+		}
+	}
+	sent := 0
+	var failed []string
+	if o.closed {
+		return e.i18n.T(MsgAutoAttachExpired)
+	}
+	for _, n := range picks {
+		if n < 1 || n > len(o.files) {
+			continue
+		}
+		f := &o.files[n-1]
+		if f.sent || f.size > e.autoAttach.MaxFileSize {
+			continue
+		}
+		if err := e.sendWorkspaceFile(o.platform, o.replyCtx, filepath.Join(o.dir, filepath.FromSlash(f.rel))); err != nil {
+			slog.Warn("auto attach: send failed", "file", f.rel, "error", err)
+			failed = append(failed, f.rel)
+			continue
+		}
+		f.sent = true
+		sent++
+	}
+	if len(failed) > 0 {
+		return e.i18n.Tf(MsgAutoAttachFailed, sent, strings.Join(failed, ", "))
+	}
+	return e.i18n.Tf(MsgAutoAttachSent, sent)
+}
+
+// sendWorkspaceFile sends an image through ImageSender when possible and
+// anything else through FileSender.
+func (e *Engine) sendWorkspaceFile(p Platform, replyCtx any, name string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if info.Size() > e.autoAttach.MaxFileSize {
+		return fmt.Errorf("%s exceeds %s", filepath.Base(name), formatFileSize(e.autoAttach.MaxFileSize))
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	base := filepath.Base(name)
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(base)))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	if err := e.waitOutgoing(p); err != nil {
+		return err
+	}
+	if is, ok := p.(ImageSender); ok && strings.HasPrefix(mimeType, "image/") && mimeType != "image/svg+xml" {
+		return is.SendImage(e.ctx, replyCtx, ImageAttachment{MimeType: mimeType, Data: data, FileName: base})
+	}
+	fs, ok := p.(FileSender)
+	if !ok {
+		return ErrNotSupported
+	}
+	return fs.SendFile(e.ctx, replyCtx, FileAttachment{MimeType: mimeType, Data: data, FileName: base})
+}
+
+// renderAttachNav handles act:/attach card actions. Files are sent in the
+// background and the card is refreshed with the result.
+func (e *Engine) renderAttachNav(args, sessionKey string) *Card {
+	id, picks, dismiss := parseAttachArgs(strings.Fields(args))
+	o := e.attachOffers.get(e.interactiveKeyForSessionKey(sessionKey), id)
+	if o == nil {
+		return e.simpleCard("", "grey", e.i18n.T(MsgAutoAttachExpired))
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if dismiss {
+		o.closed = true
+		return e.renderAttachOfferCard(o, "act", e.i18n.T(MsgAutoAttachDismissed))
+	}
+	go func() {
+		note := e.sendOfferedFiles(o, picks)
+		refresher, ok := o.platform.(CardRefresher)
+		if !ok {
+			return
+		}
+		o.mu.Lock()
+		card := e.renderCardForPlatform(o.platform, e.renderAttachOfferCard(o, "act", note))
+		o.mu.Unlock()
+		if err := refresher.RefreshCard(e.ctx, sessionKey, card); err != nil {
+			slog.Debug("auto attach: refresh card failed", "error", err)
+		}
+	}()
+	return e.renderAttachOfferCard(o, "act", e.i18n.T(MsgAutoAttachSending))
+}
+
+// cmdAttach implements /attach [all|<n>...|dismiss] for the session's latest
+// offer of files created during a turn.
+func (e *Engine) cmdAttach(p Platform, msg *Message, args []string) {
+	id, picks, dismiss := parseAttachArgs(args)
+	o := e.attachOffers.get(e.interactiveKeyForSessionKey(msg.SessionKey), id)
+	if o == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgAutoAttachExpired))
+		return
+	}
+	if dismiss {
+		o.mu.Lock()
+		o.closed = true
+		o.mu.Unlock()
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgAutoAttachDismissed))
+		return
+	}
+	e.reply(p, msg.ReplyCtx, e.sendOfferedFiles(o, picks))
+}
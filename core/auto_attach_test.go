@@ -0,0 +1,161 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	name := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkspaceSnapshot_ChangedSinceRespectsGitignore(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, ".gitignore", "build/\n*.log\n")
+	writeTestFile(t, dir, "data.csv", "a,b\n")
+	writeTestFile(t, dir, "old.png", "png")
+
+	before, err := snapshotWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "data.csv", "a,b\n1,2\n")
+	writeTestFile(t, dir, "out/chart.png", "png")
+	writeTestFile(t, dir, "build/report.pdf", "pdf")
+	writeTestFile(t, dir, "notes.txt", "text")
+	writeTestFile(t, dir, "debug.log", "log")
+
+	after, err := snapshotWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range before.changedSince(after, DefaultAutoAttachPatterns) {
+		got = append(got, f.rel)
+	}
+	if strings.Join(got, ",") != "data.csv,out/chart.png" {
+		t.Fatalf("changed = %v", got)
+	}
+}
+
+func TestMatchAutoAttachPattern(t *testing.T) {
+	patterns := []string{"*.pdf", "reports/*.md"}
+	for rel, want := range map[string]bool{
+		"a/b/paper.pdf":     true,
+		"reports/weekly.md": true,
+		"docs/readme.md":    false,
+	} {
+		if got := matchAutoAttachPattern(patterns, rel); got != want {
+			t.Errorf("%s: got %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestOfferTurnFiles_SendsPickedFiles(t *testing.T) {
+	dir := t.TempDir()
+	p := &stubMediaPlatform{stubPlatformEngine: stubPlatformEngine{n: "test"}}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetAttachmentSendEnabled(true)
+	e.SetAutoAttachConfig(AutoAttachCfg{Enabled: true, MaxFileSize: 8})
+	state := &interactiveState{platform: p, workspaceDir: dir}
+
+	e.beginTurnFiles(state)
+	writeTestFile(t, dir, "chart.png", "png")
+	writeTestFile(t, dir, "huge.pdf", "0123456789")
+	writeTestFile(t, dir, "table.csv", "a,b")
+	e.offerTurnFiles(state, p, "ctx", "s1")
+
+	sent := p.getSent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "`chart.png`") || !strings.Contains(sent[0], "(too large)") || !strings.Contains(sent[0], "/attach all") {
+		t.Fatalf("offer = %v", sent)
+	}
+
+	e.cmdAttach(p, &Message{SessionKey: "s1", ReplyCtx: "ctx"}, []string{"3"})
+	if len(p.files) != 1 || p.files[0].FileName != "table.csv" || len(p.images) != 0 {
+		t.Fatalf("after /attach 3: images=%d files=%+v", len(p.images), p.files)
+	}
+	e.cmdAttach(p, &Message{SessionKey: "s1", ReplyCtx: "ctx"}, []string{"all"})
+	if len(p.images) != 1 || p.images[0].MimeType != "image/png" || len(p.files) != 1 {
+		t.Fatalf("after /attach all: images=%d files=%d", len(p.images), len(p.files))
+	}
+	if last := p.getSent(); !strings.Contains(last[len(last)-1], "Sent 1 file") {
+		t.Fatalf("summary = %q", last[len(last)-1])
+	}
+}
+
+func TestOfferTurnFiles_CardActions(t *testing.T) {
+	dir := t.TempDir()
+	p := &stubCardFilePlatform{stubCardPlatform: stubCardPlatform{stubPlatformEngine: stubPlatformEngine{n: "feishu"}}, files: make(chan FileAttachment, 2)}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetAttachmentSendEnabled(true)
+	e.SetAutoAttachConfig(AutoAttachCfg{Enabled: true})
+	state := &interactiveState{platform: p, workspaceDir: dir}
+
+	e.beginTurnFiles(state)
+	writeTestFile(t, dir, "report.pdf", "pdf")
+	e.offerTurnFiles(state, p, "ctx", "s1")
+	if len(p.sentCards) != 1 {
+		t.Fatalf("sent %d cards", len(p.sentCards))
+	}
+	rows := p.sentCards[0].CollectButtons()
+	sendAll := rows[len(rows)-1][0].Data
+	if !strings.HasPrefix(sendAll, "act:/attach ") || !strings.HasSuffix(sendAll, " all") {
+		t.Fatalf("send all = %q", sendAll)
+	}
+
+	e.handleCardNav(sendAll, "s1")
+	select {
+	case f := <-p.files:
+		if f.FileName != "report.pdf" {
+			t.Fatalf("sent %s", f.FileName)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("send all sent nothing")
+	}
+
+	card := e.handleCardNav(rows[len(rows)-1][1].Data, "s1")
+	if card == nil || card.HasButtons() {
+		t.Fatalf("dismissed card still has buttons: %+v", card)
+	}
+}
+
+func TestOfferTurnFiles_DisabledTakesNoSnapshot(t *testing.T) {
+	p := &stubMediaPlatform{stubPlatformEngine: stubPlatformEngine{n: "test"}}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	state := &interactiveState{platform: p, workspaceDir: t.TempDir()}
+	e.beginTurnFiles(state)
+	if state.turnFiles != nil {
+		t.Fatal("snapshot taken while auto attach is disabled")
+	}
+}
+
+func TestParseAttachArgs(t *testing.T) {
+	for _, c := range []struct {
+		args    []string
+		id      string
+		picks   []int
+		dismiss bool
+	}{
+		{[]string{"all"}, "", nil, false},
+		{[]string{"1", "3"}, "", []int{1, 3}, false},
+		{[]string{"o-12345678", "all"}, "o-12345678", nil, false},
+		{[]string{"o-00000002", "2"}, "o-00000002", []int{2}, false},
+		{[]string{"o-abcdef01", "dismiss"}, "o-abcdef01", nil, true},
+	} {
+		id, picks, dismiss := parseAttachArgs(c.args)
+		if id != c.id || !slices.Equal(picks, c.picks) || dismiss != c.dismiss {
+			t.Errorf("parseAttachArgs(%q) = %q, %v, %v", c.args, id, picks, dismiss)
+		}
+	}
+}
@@ -210,6 +210,13 @@ func (bs *BridgeServer) RegisterEngine(projectName string, engine *Engine, bp *B
 	bs.engines[projectName] = &bridgeEngineRef{engine: engine, platform: bp}
 }
 
+// UnregisterEngine detaches a project that was removed by a config reload.
+func (bs *BridgeServer) UnregisterEngine(projectName string) {
+	bs.enginesMu.Lock()
+	defer bs.enginesMu.Unlock()
+	delete(bs.engines, projectName)
+}
+
 // Start launches the HTTP/WebSocket server.
 func (bs *BridgeServer) Start() {
 	mux := http.NewServeMux()
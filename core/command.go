@@ -186,6 +186,34 @@ func (r *CommandRegistry) ListAll() []*CustomCommand {
 // placeholderRe matches {{1}}, {{2*}}, {{args}}, and variants with defaults like {{1:foo}}.
 var placeholderRe = regexp.MustCompile(`\{\{(\d+\*?|args)(:[^}]*)?\}\}`)
 
+// namedPlaceholderRe matches named variables such as {{precheck_output}} or
+// {{precheck_output:default}}.
+var namedPlaceholderRe = regexp.MustCompile(`\{\{([a-z][a-z0-9_]*)(:[^}]*)?\}\}`)
+
+// ExpandPromptVars is ExpandPrompt plus named variables: each {{name}} or
+// {{name:default}} whose name is a key of vars is replaced by its value (or
+// the default when the value is empty). Unknown names are left untouched.
+// Variable values are inserted after positional expansion, so they are never
+// re-expanded.
+func ExpandPromptVars(template string, args []string, vars map[string]string) string {
+	result := ExpandPrompt(template, args)
+	if len(vars) == 0 {
+		return result
+	}
+	return namedPlaceholderRe.ReplaceAllStringFunc(result, func(match string) string {
+		inner := match[2 : len(match)-2]
+		key, defaultVal, hasDefault := strings.Cut(inner, ":")
+		v, ok := vars[key]
+		if !ok {
+			return match
+		}
+		if v == "" && hasDefault {
+			return defaultVal
+		}
+		return v
+	})
+}
+
 // ExpandPrompt replaces template placeholders with the provided arguments.
 //
 // Supported placeholders:
@@ -270,3 +270,31 @@ func TestAllowList(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandPromptVars(t *testing.T) {
+	vars := map[string]string{"precheck_output": "abc123 fix bug", "empty": ""}
+	tests := []struct {
+		name     string
+		template string
+		args     []string
+		want     string
+	}{
+		{"named", "Review: {{precheck_output}}", nil, "Review: abc123 fix bug"},
+		{"default used when empty", "Out: {{empty:none}}", nil, "Out: none"},
+		{"unknown left untouched", "Keep {{other}} as is", nil, "Keep {{other}} as is"},
+		{"mixed with positional", "{{1}}: {{precheck_output}}", []string{"commits"}, "commits: abc123 fix bug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandPromptVars(tt.template, tt.args, vars); got != tt.want {
+				t.Errorf("ExpandPromptVars() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Variable values must not be re-expanded as positional placeholders.
+	got := ExpandPromptVars("{{precheck_output}}", nil, map[string]string{"precheck_output": "{{1}}"})
+	if got != "{{1}}" {
+		t.Errorf("value re-expanded: got %q", got)
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	mathrand "math/rand/v2"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -21,31 +23,83 @@ import (
 
 // CronJob represents a persisted scheduled task.
 type CronJob struct {
-	ID          string    `json:"id"`
-	Project     string    `json:"project"`
-	SessionKey  string    `json:"session_key"`
-	CronExpr    string    `json:"cron_expr"`
-	Prompt      string    `json:"prompt"`
-	Exec        string    `json:"exec,omitempty"`     // shell command; mutually exclusive with Prompt
-	WorkDir     string    `json:"work_dir,omitempty"` // working directory for exec; empty = agent work_dir
-	Description string    `json:"description"`
-	Enabled     bool      `json:"enabled"`
-	Silent      *bool     `json:"silent,omitempty"`       // suppress start notification; nil = use global default
-	Mute        bool      `json:"mute,omitempty"`         // suppress ALL messages (start + result); job runs silently
-	SessionMode string    `json:"session_mode,omitempty"` // "" or "reuse" = share active session; "new_per_run" = fresh session each run
-	Mode        string    `json:"mode,omitempty"`         // permission mode override for this job; "" = use project default
-	TimeoutMins *int      `json:"timeout_mins,omitempty"` // nil = default 30m wait; 0 = no limit; >0 = minutes
-	CreatedAt   time.Time `json:"created_at"`
-	LastRun     time.Time `json:"last_run,omitempty"`
-	LastError   string    `json:"last_error,omitempty"`
+	ID           string    `json:"id"`
+	Project      string    `json:"project"`
+	SessionKey   string    `json:"session_key"`
+	CronExpr     string    `json:"cron_expr"`
+	Prompt       string    `json:"prompt"`
+	Exec         string    `json:"exec,omitempty"`     // shell command; mutually exclusive with Prompt
+	WorkDir      string    `json:"work_dir,omitempty"` // working directory for exec; empty = agent work_dir
+	Precheck     string    `json:"precheck,omitempty"` // shell command gating the run; non-zero exit skips it
+	Description  string    `json:"description"`
+	Enabled      bool      `json:"enabled"`
+	Silent       *bool     `json:"silent,omitempty"`        // suppress start notification; nil = use global default
+	Mute         bool      `json:"mute,omitempty"`          // suppress ALL messages (start + result); job runs silently
+	SessionMode  string    `json:"session_mode,omitempty"`  // "" or "reuse" = share active session; "new_per_run" = fresh session each run
+	Mode         string    `json:"mode,omitempty"`          // permission mode override for this job; "" = use project default
+	TimeoutMins  *int      `json:"timeout_mins,omitempty"`  // nil = default 30m wait; 0 = no limit; >0 = minutes
+	Retries      int       `json:"retries,omitempty"`       // extra attempts after a failed run
+	RetryDelay   string    `json:"retry_delay,omitempty"`   // Go duration between attempts, e.g. "5m"; "" = 1m
+	OnFailure    string    `json:"on_failure,omitempty"`    // session key alerted after the final failed attempt; "session" = the job's own session
+	Then         string    `json:"then,omitempty"`          // job ID to run after this job succeeds
+	Timezone     string    `json:"timezone,omitempty"`      // IANA zone the cron expression is evaluated in; "" = server local time
+	ExcludeDates []string  `json:"exclude_dates,omitempty"` // YYYY-MM-DD dates (in Timezone) on which scheduled runs are skipped
+	ExcludeICS   string    `json:"exclude_ics,omitempty"`   // path to an .ics calendar whose event days are skipped
+	Jitter       string    `json:"jitter,omitempty"`        // random delay window before scheduled runs, e.g. "10m"
+	CreatedAt    time.Time `json:"created_at"`
+	LastRun      time.Time `json:"last_run,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
 }
 
+// CronRun records the outcome of a single cron job execution.
+type CronRun struct {
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	DurationMs   int64     `json:"duration_ms"`
+	Manual       bool      `json:"manual,omitempty"`
+	Skipped      bool      `json:"skipped,omitempty"`      // precheck exited non-zero; nothing ran
+	Attempt      int       `json:"attempt,omitempty"`      // 1-based; >1 for retries
+	ChainedFrom  string    `json:"chained_from,omitempty"` // ID of the job whose success triggered this run
+	ExitCode     *int      `json:"exit_code,omitempty"`    // shell jobs only
+	Error        string    `json:"error,omitempty"`
+	InputTokens  int       `json:"input_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	Output       string    `json:"output,omitempty"` // final agent reply or shell output, truncated
+}
+
+// CronRunResult is what the engine reports back after executing a job.
+type CronRunResult struct {
+	Output       string
+	ExitCode     *int
+	InputTokens  int
+	OutputTokens int
+}
+
+const (
+	maxCronRunHistory = 20   // runs kept per job
+	maxCronRunOutput  = 4000 // runes of output kept per run
+)
+
 // IsShellJob returns true if the job runs a shell command directly.
 func (j *CronJob) IsShellJob() bool {
 	return j.Exec != ""
 }
 
-const defaultCronJobTimeout = 30 * time.Minute
+// RunsShell reports whether running the job executes a shell command, either
+// as the job itself or as its precheck. Such jobs need an admin to trigger.
+func (j *CronJob) RunsShell() bool {
+	return j.Exec != "" || j.Precheck != ""
+}
+
+const (
+	defaultCronJobTimeout = 30 * time.Minute
+	defaultCronRetryDelay = time.Minute
+	maxCronRetries        = 10
+	maxCronChainDepth     = 16
+
+	// CronOnFailureSession makes on_failure alert the job's own session.
+	CronOnFailureSession = "session"
+)
 
 var (
 	ErrCronJobNotFound     = errors.New("cron job not found")
@@ -65,6 +119,240 @@ func (j *CronJob) ExecutionTimeout() time.Duration {
 	return time.Duration(*j.TimeoutMins) * time.Minute
 }
 
+// Spec returns the cron spec passed to the scheduler: CronExpr, prefixed with
+// CRON_TZ=<zone> when the job has a time zone and the expression does not
+// carry its own.
+func (j *CronJob) Spec() string {
+	if tz := strings.TrimSpace(j.Timezone); tz != "" && !cronExprHasTZ(j.CronExpr) {
+		return "CRON_TZ=" + tz + " " + j.CronExpr
+	}
+	return j.CronExpr
+}
+
+// Location returns the job's time zone, falling back to server local time.
+func (j *CronJob) Location() *time.Location {
+	if tz := strings.TrimSpace(j.Timezone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// JitterDuration returns the random delay window for scheduled runs (0 = none).
+func (j *CronJob) JitterDuration() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(j.Jitter)); err == nil && d > 0 {
+		return d
+	}
+	return 0
+}
+
+// IsExcludedDate reports whether t falls on a date excluded by ExcludeDates or
+// the ExcludeICS calendar, evaluated in the job's time zone.
+func (j *CronJob) IsExcludedDate(t time.Time) bool {
+	if len(j.ExcludeDates) == 0 && j.ExcludeICS == "" {
+		return false
+	}
+	day := t.In(j.Location()).Format("2006-01-02")
+	for _, d := range j.ExcludeDates {
+		if strings.TrimSpace(d) == day {
+			return true
+		}
+	}
+	if j.ExcludeICS != "" {
+		days, err := icsExcludedDays(j.ExcludeICS, j.Location())
+		if err != nil {
+			slog.Warn("cron: failed to read exclude_ics", "id", j.ID, "path", j.ExcludeICS, "error", err)
+			return false
+		}
+		return days[day]
+	}
+	return false
+}
+
+type icsCacheEntry struct {
+	modTime time.Time
+	size    int64
+	days    map[string]bool
+}
+
+// icsCache holds parsed exclude_ics calendars keyed by path and time zone,
+// so listing next runs does not re-read the file for every candidate.
+var (
+	icsCacheMu sync.Mutex
+	icsCache   = map[string]icsCacheEntry{}
+)
+
+// icsExcludedDays returns the days of the calendar at path in loc, re-parsing
+// the file only when its modification time or size changed.
+func icsExcludedDays(path string, loc *time.Location) (map[string]bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	key := path + "\x00" + loc.String()
+	icsCacheMu.Lock()
+	entry, ok := icsCache[key]
+	icsCacheMu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.days, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	days := parseICSDates(data, loc)
+	icsCacheMu.Lock()
+	icsCache[key] = icsCacheEntry{modTime: info.ModTime(), size: info.Size(), days: days}
+	icsCacheMu.Unlock()
+	return days, nil
+}
+
+// parseICSDates returns the set of YYYY-MM-DD days covered by VEVENTs in an
+// iCalendar file. All-day events cover DTSTART up to (excluding) DTEND; timed
+// events cover the day of DTSTART in loc. Times in UTC ("Z") or with a TZID
+// are converted to loc first; floating times are taken as they are.
+// FREQ=YEARLY recurrences are expanded; other RRULEs exclude only their
+// first occurrence and are logged.
+func parseICSDates(data []byte, loc *time.Location) map[string]bool {
+	// Unfold continuation lines (RFC 5545 §3.1).
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\n ", "")
+	text = strings.ReplaceAll(text, "\n\t", "")
+
+	days := make(map[string]bool)
+	var start, end time.Time
+	var rrule string
+	inEvent := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "BEGIN:VEVENT":
+			inEvent = true
+			start, end, rrule = time.Time{}, time.Time{}, ""
+		case line == "END:VEVENT":
+			if inEvent && !start.IsZero() {
+				extra := 0
+				for d := start.AddDate(0, 0, 1); !end.IsZero() && d.Before(end); d = d.AddDate(0, 0, 1) {
+					extra++
+				}
+				occurrences, ok := icsOccurrences(start, rrule, time.Now().AddDate(icsRecurrenceYears, 0, 0))
+				if !ok {
+					slog.Warn("cron: exclude_ics recurrence not supported, excluding only the first occurrence", "rrule", rrule, "start", start.Format("2006-01-02"))
+				}
+				for _, occ := range occurrences {
+					for i := 0; i <= extra; i++ {
+						days[occ.AddDate(0, 0, i).Format("2006-01-02")] = true
+					}
+				}
+			}
+			inEvent = false
+		case inEvent && strings.HasPrefix(line, "RRULE:"):
+			rrule = strings.TrimPrefix(line, "RRULE:")
+		case inEvent && (strings.HasPrefix(line, "DTSTART") || strings.HasPrefix(line, "DTEND")):
+			name, value, ok := strings.Cut(line, ":")
+			if !ok || len(value) < 8 {
+				continue
+			}
+			d, err := parseICSTime(name, value, loc)
+			if err != nil {
+				continue
+			}
+			if strings.HasPrefix(name, "DTSTART") {
+				start = d
+			} else if strings.Contains(name, "VALUE=DATE") {
+				end = d // all-day DTEND is exclusive
+			}
+		}
+	}
+	return days
+}
+
+// icsRecurrenceYears bounds how far ahead open-ended yearly events are expanded.
+const icsRecurrenceYears = 10
+
+// icsOccurrences returns the start days of an event with the given RRULE, up
+// to horizon. Only FREQ=YEARLY without BY* parts is expanded; for any other
+// rule it returns start alone and false.
+func icsOccurrences(start time.Time, rrule string, horizon time.Time) ([]time.Time, bool) {
+	if rrule == "" {
+		return []time.Time{start}, true
+	}
+	params := make(map[string]string)
+	for _, part := range strings.Split(rrule, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		k = strings.ToUpper(strings.TrimSpace(k))
+		if strings.HasPrefix(k, "BY") {
+			return []time.Time{start}, false
+		}
+		params[k] = strings.TrimSpace(v)
+	}
+	if !strings.EqualFold(params["FREQ"], "YEARLY") {
+		return []time.Time{start}, false
+	}
+	interval, count := 1, 0
+	if v, err := strconv.Atoi(params["INTERVAL"]); err == nil && v > 0 {
+		interval = v
+	}
+	if v, err := strconv.Atoi(params["COUNT"]); err == nil && v > 0 {
+		count = v
+	}
+	if u := params["UNTIL"]; len(u) >= 8 {
+		if until, err := time.ParseInLocation("20060102", u[:8], start.Location()); err == nil && until.AddDate(0, 0, 1).Before(horizon) {
+			horizon = until.AddDate(0, 0, 1)
+		}
+	}
+	var out []time.Time
+	for i := 0; ; i += interval {
+		occ := start.AddDate(i, 0, 0)
+		if i > 0 && !occ.Before(horizon) || (count > 0 && len(out) >= count) {
+			break
+		}
+		if occ.Day() != start.Day() {
+			continue // Feb 29 in a non-leap year
+		}
+		out = append(out, occ)
+	}
+	return out, true
+}
+
+// parseICSTime parses a DTSTART/DTEND value. name carries the property
+// parameters (VALUE=DATE, TZID=...). The result is in loc, except for
+// dates and floating times, whose calendar day is kept.
+func parseICSTime(name, value string, loc *time.Location) (time.Time, error) {
+	if len(value) < len("20060102T150405") {
+		return time.Parse("20060102", value[:8])
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return t.In(loc), nil
+	}
+	for _, param := range strings.Split(name, ";")[1:] {
+		if tzid, ok := strings.CutPrefix(param, "TZID="); ok {
+			if evLoc, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+				t, err := time.ParseInLocation("20060102T150405", value, evLoc)
+				if err != nil {
+					return time.Time{}, err
+				}
+				return t.In(loc), nil
+			}
+		}
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// RetryDelayDuration returns how long to wait between failed attempts.
+// An empty or invalid RetryDelay falls back to one minute.
+func (j *CronJob) RetryDelayDuration() time.Duration {
+	if d, err := time.ParseDuration(strings.TrimSpace(j.RetryDelay)); err == nil && d > 0 {
+		return d
+	}
+	return defaultCronRetryDelay
+}
+
 // UsesNewSessionPerRun reports whether each cron run should use a new engine session
 // instead of reusing the active session for the session_key.
 func (j *CronJob) UsesNewSessionPerRun() bool {
@@ -110,14 +398,84 @@ func validateCronJob(j *CronJob) error {
 	if j.TimeoutMins != nil && *j.TimeoutMins < 0 {
 		return fmt.Errorf("timeout_mins must be >= 0")
 	}
+	if j.Retries < 0 || j.Retries > maxCronRetries {
+		return fmt.Errorf("retries must be between 0 and %d", maxCronRetries)
+	}
+	if err := validateCronRetryDelay(j.RetryDelay); err != nil {
+		return err
+	}
+	if j.Then != "" && j.Then == j.ID {
+		return fmt.Errorf("then cannot reference the job itself")
+	}
+	if err := validateCronTimezone(j.Timezone); err != nil {
+		return err
+	}
+	if err := validateCronTimezoneExpr(j.Timezone, j.CronExpr); err != nil {
+		return err
+	}
+	for _, d := range j.ExcludeDates {
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(d)); err != nil {
+			return fmt.Errorf("invalid exclude_dates entry %q (want YYYY-MM-DD)", d)
+		}
+	}
+	if err := validateCronJitter(j.Jitter); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cronExprHasTZ reports whether expr starts with a CRON_TZ= or TZ= prefix.
+func cronExprHasTZ(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	return strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=")
+}
+
+// validateCronTimezoneExpr rejects a job that names its time zone twice.
+func validateCronTimezoneExpr(tz, expr string) error {
+	if strings.TrimSpace(tz) != "" && cronExprHasTZ(expr) {
+		return fmt.Errorf("cron expression %q already sets a time zone; drop the prefix or the timezone field", expr)
+	}
+	return nil
+}
+
+func validateCronTimezone(tz string) error {
+	if strings.TrimSpace(tz) == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(strings.TrimSpace(tz)); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return nil
+}
+
+func validateCronJitter(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d < 0 {
+		return fmt.Errorf("invalid jitter %q (want a duration such as 30s or 10m)", s)
+	}
+	return nil
+}
+
+func validateCronRetryDelay(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid retry_delay %q (want a positive duration such as 30s or 5m)", s)
+	}
 	return nil
 }
 
 // CronStore persists cron jobs to a JSON file.
 type CronStore struct {
-	path string
-	mu   sync.Mutex
-	jobs []*CronJob
+	path    string
+	runsDir string // per-job run history files: <runsDir>/<id>.json
+	mu      sync.Mutex
+	jobs    []*CronJob
 }
 
 func NewCronStore(dataDir string) (*CronStore, error) {
@@ -126,7 +484,7 @@ func NewCronStore(dataDir string) (*CronStore, error) {
 		return nil, err
 	}
 	path := filepath.Join(dir, "jobs.json")
-	s := &CronStore{path: path}
+	s := &CronStore{path: path, runsDir: filepath.Join(dir, "runs")}
 	s.load()
 	return s, nil
 }
@@ -165,6 +523,9 @@ func (s *CronStore) Remove(id string) bool {
 			if err := s.save(); err != nil {
 				slog.Warn("cron: failed to save after remove", "error", err)
 			}
+			if err := os.Remove(s.runsPath(id)); err != nil && !os.IsNotExist(err) {
+				slog.Warn("cron: failed to remove run history", "id", id, "error", err)
+			}
 			return true
 		}
 	}
@@ -235,6 +596,59 @@ func (s *CronStore) MarkRun(id string, err error) {
 	}
 }
 
+func (s *CronStore) runsPath(id string) string {
+	return filepath.Join(s.runsDir, filepath.Base(id)+".json")
+}
+
+func (s *CronStore) loadRuns(id string) []CronRun {
+	data, err := os.ReadFile(s.runsPath(id))
+	if err != nil {
+		return nil
+	}
+	var runs []CronRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		slog.Warn("cron: failed to load run history", "id", id, "error", err)
+		return nil
+	}
+	return runs
+}
+
+// RecordRun appends a run to the job's history, keeping only the most recent
+// maxCronRunHistory entries. Output is truncated to maxCronRunOutput runes.
+func (s *CronStore) RecordRun(id string, run CronRun) {
+	run.Output = truncateStr(run.Output, maxCronRunOutput)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := append(s.loadRuns(id), run)
+	if len(runs) > maxCronRunHistory {
+		runs = runs[len(runs)-maxCronRunHistory:]
+	}
+	data, err := json.MarshalIndent(runs, "", "  ")
+	if err != nil {
+		slog.Warn("cron: failed to encode run history", "id", id, "error", err)
+		return
+	}
+	if err := os.MkdirAll(s.runsDir, 0o755); err != nil {
+		slog.Warn("cron: failed to create run history dir", "error", err)
+		return
+	}
+	if err := AtomicWriteFile(s.runsPath(id), data, 0o644); err != nil {
+		slog.Warn("cron: failed to save run history", "id", id, "error", err)
+	}
+}
+
+// Runs returns the recorded run history for a job, newest first.
+func (s *CronStore) Runs(id string) []CronRun {
+	s.mu.Lock()
+	runs := s.loadRuns(id)
+	s.mu.Unlock()
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs
+}
+
 func (s *CronStore) List() []*CronJob {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -335,6 +749,11 @@ func updateJobField(job *CronJob, field string, value any) error {
 			job.WorkDir = v
 			return nil
 		}
+	case "precheck":
+		if v, ok := value.(string); ok {
+			job.Precheck = v
+			return nil
+		}
 	case "description":
 		if v, ok := value.(string); ok {
 			job.Description = v
@@ -375,6 +794,50 @@ func updateJobField(job *CronJob, field string, value any) error {
 			job.TimeoutMins = &v
 			return nil
 		}
+	case "retries":
+		if v, ok := value.(float64); ok {
+			job.Retries = int(v)
+			return nil
+		}
+		if v, ok := value.(int); ok {
+			job.Retries = v
+			return nil
+		}
+	case "retry_delay":
+		if v, ok := value.(string); ok {
+			job.RetryDelay = v
+			return nil
+		}
+	case "on_failure":
+		if v, ok := value.(string); ok {
+			job.OnFailure = v
+			return nil
+		}
+	case "then":
+		if v, ok := value.(string); ok {
+			job.Then = v
+			return nil
+		}
+	case "timezone":
+		if v, ok := value.(string); ok {
+			job.Timezone = v
+			return nil
+		}
+	case "jitter":
+		if v, ok := value.(string); ok {
+			job.Jitter = v
+			return nil
+		}
+	case "exclude_ics":
+		if v, ok := value.(string); ok {
+			job.ExcludeICS = v
+			return nil
+		}
+	case "exclude_dates":
+		if dates, ok := parseCronDateList(value); ok {
+			job.ExcludeDates = dates
+			return nil
+		}
 	}
 	// Fallback: try to set string field via reflection
 	if v, ok := value.(string); ok {
@@ -388,6 +851,35 @@ func updateJobField(job *CronJob, field string, value any) error {
 	return fmt.Errorf("unknown or invalid field: %s", field)
 }
 
+// parseCronDateList accepts exclude_dates as a JSON array or a comma-separated
+// string.
+func parseCronDateList(value any) ([]string, bool) {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []string:
+		raw = v
+	case []any:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			raw = append(raw, s)
+		}
+	default:
+		return nil, false
+	}
+	var out []string
+	for _, d := range raw {
+		if d = strings.TrimSpace(d); d != "" {
+			out = append(out, d)
+		}
+	}
+	return out, true
+}
+
 // toExportedFieldName converts snake_case to Go exported field name (e.g., "session_key" -> "SessionKey")
 func toExportedFieldName(s string) string {
 	result := make([]byte, 0, len(s))
@@ -414,6 +906,7 @@ type CronScheduler struct {
 	store              *CronStore
 	cron               *cron.Cron
 	engines            map[string]*Engine // project name → engine
+	stopCh             chan struct{}      // closed by Stop, recreated by Start; aborts pending retry delays
 	mu                 sync.RWMutex
 	entries            map[string]cron.EntryID // job ID → cron entry
 	defaultSilent      bool                    // global default for suppressing cron start notifications
@@ -425,6 +918,7 @@ func NewCronScheduler(store *CronStore) *CronScheduler {
 		store:   store,
 		cron:    cron.New(),
 		engines: make(map[string]*Engine),
+		stopCh:  make(chan struct{}),
 		entries: make(map[string]cron.EntryID),
 	}
 }
@@ -435,6 +929,14 @@ func (cs *CronScheduler) RegisterEngine(name string, e *Engine) {
 	cs.engines[name] = e
 }
 
+// UnregisterEngine detaches a removed project. Its jobs stay in the store
+// and fail with "project not found" until the project is added back.
+func (cs *CronScheduler) UnregisterEngine(name string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	delete(cs.engines, name)
+}
+
 func (cs *CronScheduler) SetDefaultSilent(silent bool) {
 	cs.defaultSilent = silent
 }
@@ -461,6 +963,11 @@ func (cs *CronScheduler) UsesNewSession(job *CronJob) bool {
 }
 
 func (cs *CronScheduler) Start() error {
+	cs.mu.Lock()
+	if cs.stopCh == nil || isClosed(cs.stopCh) {
+		cs.stopCh = make(chan struct{})
+	}
+	cs.mu.Unlock()
 	jobs := cs.store.List()
 	for _, job := range jobs {
 		if job.Enabled {
@@ -476,6 +983,27 @@ func (cs *CronScheduler) Start() error {
 
 func (cs *CronScheduler) Stop() {
 	cs.cron.Stop()
+	cs.mu.Lock()
+	if cs.stopCh != nil && !isClosed(cs.stopCh) {
+		close(cs.stopCh)
+	}
+	cs.mu.Unlock()
+}
+
+// stopped returns the channel the current run of the scheduler closes on Stop.
+func (cs *CronScheduler) stopped() <-chan struct{} {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.stopCh
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
 }
 
 func (cs *CronScheduler) AddJob(job *CronJob) error {
@@ -483,9 +1011,12 @@ func (cs *CronScheduler) AddJob(job *CronJob) error {
 		return err
 	}
 	job.SessionMode = NormalizeCronSessionMode(job.SessionMode)
-	if _, err := cron.ParseStandard(job.CronExpr); err != nil {
+	if _, err := cron.ParseStandard(job.Spec()); err != nil {
 		return fmt.Errorf("invalid cron expression %q: %w", job.CronExpr, err)
 	}
+	if err := cs.validateChain(job.ID, job.Then); err != nil {
+		return err
+	}
 	if err := cs.store.Add(job); err != nil {
 		return err
 	}
@@ -546,6 +1077,9 @@ func (cs *CronScheduler) UpdateJob(id string, field string, value any) error {
 		if _, err := cron.ParseStandard(expr); err != nil {
 			return fmt.Errorf("invalid cron expression %q: %w", expr, err)
 		}
+		if err := validateCronTimezoneExpr(job.Timezone, expr); err != nil {
+			return err
+		}
 	}
 
 	// Validate mode if updating mode field
@@ -569,6 +1103,62 @@ func (cs *CronScheduler) UpdateJob(id string, field string, value any) error {
 		}
 	}
 
+	switch field {
+	case "retries":
+		n, ok := value.(float64)
+		if iv, isInt := value.(int); isInt {
+			n, ok = float64(iv), true
+		}
+		if !ok || n != float64(int(n)) || n < 0 || n > maxCronRetries {
+			return fmt.Errorf("retries must be an integer between 0 and %d", maxCronRetries)
+		}
+	case "retry_delay":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("retry_delay must be a string")
+		}
+		if err := validateCronRetryDelay(v); err != nil {
+			return err
+		}
+	case "then":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("then must be a string")
+		}
+		if err := cs.validateChain(id, v); err != nil {
+			return err
+		}
+	case "timezone":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("timezone must be a string")
+		}
+		if err := validateCronTimezone(v); err != nil {
+			return err
+		}
+		if err := validateCronTimezoneExpr(v, job.CronExpr); err != nil {
+			return err
+		}
+	case "jitter":
+		v, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("jitter must be a string")
+		}
+		if err := validateCronJitter(v); err != nil {
+			return err
+		}
+	case "exclude_dates":
+		dates, ok := parseCronDateList(value)
+		if !ok {
+			return fmt.Errorf("exclude_dates must be a list of YYYY-MM-DD dates")
+		}
+		for _, d := range dates {
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				return fmt.Errorf("invalid exclude_dates entry %q (want YYYY-MM-DD)", d)
+			}
+		}
+	}
+
 	// Validate enabled type up-front. Without this, a non-bool value (e.g. a
 	// JSON string "true" from a misbehaving API client) reaches updateJobField
 	// only after we've already removed the cron entry below, and store.Update
@@ -581,7 +1171,7 @@ func (cs *CronScheduler) UpdateJob(id string, field string, value any) error {
 	}
 
 	// Check if reschedule is needed
-	needsReschedule := field == "cron_expr" || field == "enabled"
+	needsReschedule := field == "cron_expr" || field == "enabled" || field == "timezone"
 
 	if needsReschedule {
 		// Remove current schedule
@@ -611,6 +1201,30 @@ func (cs *CronScheduler) UpdateJob(id string, field string, value any) error {
 	return nil
 }
 
+// validateChain checks that setting jobID's Then to next references an
+// existing job and does not create a cycle.
+func (cs *CronScheduler) validateChain(jobID, next string) error {
+	if next == "" {
+		return nil
+	}
+	seen := map[string]bool{jobID: true}
+	for id := next; id != ""; {
+		if seen[id] {
+			return fmt.Errorf("then %q would create a cycle", next)
+		}
+		seen[id] = true
+		j := cs.store.Get(id)
+		if j == nil {
+			return fmt.Errorf("then: %w: %q", ErrCronJobNotFound, id)
+		}
+		if len(seen) > maxCronChainDepth {
+			return fmt.Errorf("then chain is longer than %d jobs", maxCronChainDepth)
+		}
+		id = j.Then
+	}
+	return nil
+}
+
 func (cs *CronScheduler) Store() *CronStore {
 	return cs.store
 }
@@ -631,6 +1245,35 @@ func (cs *CronScheduler) NextRun(jobID string) time.Time {
 	return time.Time{}
 }
 
+// NextRuns returns up to n upcoming fire times of a scheduled job, in the
+// job's time zone, skipping excluded dates. Jitter is not included.
+func (cs *CronScheduler) NextRuns(jobID string, n int) []time.Time {
+	cs.mu.RLock()
+	_, scheduled := cs.entries[jobID]
+	cs.mu.RUnlock()
+	job := cs.store.Get(jobID)
+	if !scheduled || job == nil || n <= 0 {
+		return nil
+	}
+	sched, err := cron.ParseStandard(job.Spec())
+	if err != nil {
+		return nil
+	}
+	var out []time.Time
+	t := time.Now()
+	// Bound the search so a calendar excluding every fire time cannot spin.
+	for i := 0; i < 1000 && len(out) < n; i++ {
+		t = sched.Next(t)
+		if t.IsZero() {
+			break
+		}
+		if !job.IsExcludedDate(t) {
+			out = append(out, t.In(job.Location()))
+		}
+	}
+	return out
+}
+
 func (cs *CronScheduler) scheduleJob(job *CronJob) error {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -641,7 +1284,7 @@ func (cs *CronScheduler) scheduleJob(job *CronJob) error {
 	}
 
 	jobID := job.ID
-	entryID, err := cs.cron.AddFunc(job.CronExpr, func() {
+	entryID, err := cs.cron.AddFunc(job.Spec(), func() {
 		cs.executeJob(jobID)
 	})
 	if err != nil {
@@ -656,6 +1299,19 @@ func (cs *CronScheduler) executeJob(jobID string) {
 	if job == nil {
 		return
 	}
+	if job.IsExcludedDate(time.Now()) {
+		slog.Info("cron: skipping run on excluded date", "id", jobID, "timezone", job.Timezone)
+		return
+	}
+	if jitter := job.JitterDuration(); jitter > 0 {
+		delay := time.Duration(mathrand.Int64N(int64(jitter)))
+		slog.Debug("cron: applying jitter", "id", jobID, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-cs.stopped():
+			return
+		}
+	}
 	cs.runJob(job, false)
 }
 
@@ -685,42 +1341,149 @@ func (cs *CronScheduler) runJob(job *CronJob, manual bool) {
 		return
 	}
 
+	// Run the job, then follow its Then links as long as each job succeeds.
+	// Chained jobs run even when disabled, like manual triggers, so a
+	// pipeline step can be kept off its own schedule.
+	chainedFrom := ""
+	for depth := 0; job != nil; depth++ {
+		if depth >= maxCronChainDepth {
+			slog.Warn("cron: chain too long, stopping", "id", job.ID, "depth", depth)
+			return
+		}
+		if err := cs.runWithRetries(job, manual, chainedFrom); err != nil || job.Then == "" {
+			return
+		}
+		next := cs.store.Get(job.Then)
+		if next == nil {
+			slog.Warn("cron: chained job not found", "id", job.ID, "then", job.Then)
+			return
+		}
+		slog.Info("cron: running chained job", "from", job.ID, "id", next.ID)
+		chainedFrom = job.ID
+		snapshot := *next
+		job = &snapshot
+	}
+}
+
+// errCronJobTimedOut marks an attempt the scheduler stopped waiting for.
+var errCronJobTimedOut = errors.New("job timed out")
+
+// runWithRetries executes a job up to 1+Retries times, waiting RetryDelay
+// between failed attempts. A timed-out attempt is not retried. After the
+// final failure the on_failure target is alerted.
+func (cs *CronScheduler) runWithRetries(job *CronJob, manual bool, chainedFrom string) error {
 	cs.mu.RLock()
 	engine, ok := cs.engines[job.Project]
 	cs.mu.RUnlock()
 
 	if !ok {
 		slog.Error("cron: project not found", "job", job.ID, "project", job.Project, "manual", manual)
-		cs.store.MarkRun(job.ID, fmt.Errorf("project %q not found", job.Project))
-		return
+		err := fmt.Errorf("project %q not found", job.Project)
+		cs.store.MarkRun(job.ID, err)
+		now := time.Now()
+		cs.store.RecordRun(job.ID, CronRun{StartedAt: now, FinishedAt: now, Manual: manual, Attempt: 1, ChainedFrom: chainedFrom, Error: err.Error()})
+		return err
+	}
+
+	attempts := 1 + job.Retries
+	var err error
+	attempt := 1
+	for ; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := job.RetryDelayDuration()
+			slog.Info("cron: retrying job", "id", job.ID, "attempt", attempt, "of", attempts, "delay", delay)
+			select {
+			case <-time.After(delay):
+			case <-cs.stopped():
+				return err
+			}
+		}
+		err = cs.runAttempt(engine, job, manual, chainedFrom, attempt)
+		if err == nil || errors.Is(err, ErrPrecheckSkipped) {
+			return err
+		}
+		// A timed-out attempt may still be driving the session; another
+		// attempt would only find it busy or run the job twice.
+		if errors.Is(err, errCronJobTimedOut) || attempt == attempts {
+			break
+		}
 	}
+	engine.notifyCronFailure(job, attempt, err)
+	return err
+}
 
-	slog.Info("cron: executing job", "id", job.ID, "project", job.Project, "manual", manual, "prompt", truncateStr(job.Prompt, 60))
+func (cs *CronScheduler) runAttempt(engine *Engine, job *CronJob, manual bool, chainedFrom string, attempt int) error {
+	slog.Info("cron: executing job", "id", job.ID, "project", job.Project, "manual", manual, "attempt", attempt, "prompt", truncateStr(job.Prompt, 60))
 
-	done := make(chan error, 1)
+	type outcome struct {
+		res *CronRunResult
+		err error
+	}
+	started := time.Now()
+	done := make(chan outcome, 1)
 	go func() {
-		done <- engine.ExecuteCronJob(job)
+		res, err := engine.ExecuteCronJobResult(job)
+		done <- outcome{res, err}
 	}()
 
-	var err error
+	var out outcome
 	timeout := job.ExecutionTimeout()
 	if timeout > 0 {
 		select {
-		case err = <-done:
+		case out = <-done:
 		case <-time.After(timeout):
-			err = fmt.Errorf("job timed out after %v", timeout)
+			out.err = fmt.Errorf("%w after %v", errCronJobTimedOut, timeout)
 		}
 	} else {
-		err = <-done
+		out = <-done
+	}
+	err := out.err
+
+	if errors.Is(err, ErrPrecheckSkipped) {
+		// A skipped run is not a failure: clear LastError and record it as
+		// skipped. The sentinel is still returned so chains stop here.
+		cs.store.MarkRun(job.ID, nil)
+		run := newCronRun(started, manual, out.res, nil)
+		run.Attempt = attempt
+		run.ChainedFrom = chainedFrom
+		run.Skipped = true
+		cs.store.RecordRun(job.ID, run)
+		slog.Info("cron: job skipped by precheck", "id", job.ID, "manual", manual)
+		return err
 	}
 
 	cs.store.MarkRun(job.ID, err)
+	run := newCronRun(started, manual, out.res, err)
+	run.Attempt = attempt
+	run.ChainedFrom = chainedFrom
+	cs.store.RecordRun(job.ID, run)
 
 	if err != nil {
-		slog.Error("cron: job failed", "id", job.ID, "manual", manual, "error", err)
+		slog.Error("cron: job failed", "id", job.ID, "manual", manual, "attempt", attempt, "error", err)
 	} else {
-		slog.Info("cron: job completed", "id", job.ID, "manual", manual)
+		slog.Info("cron: job completed", "id", job.ID, "manual", manual, "attempt", attempt)
+	}
+	return err
+}
+
+func newCronRun(started time.Time, manual bool, res *CronRunResult, err error) CronRun {
+	finished := time.Now()
+	run := CronRun{
+		StartedAt:  started,
+		FinishedAt: finished,
+		DurationMs: finished.Sub(started).Milliseconds(),
+		Manual:     manual,
+	}
+	if res != nil {
+		run.Output = res.Output
+		run.ExitCode = res.ExitCode
+		run.InputTokens = res.InputTokens
+		run.OutputTokens = res.OutputTokens
 	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	return run
 }
 
 // mutePlatform wraps a Platform and discards all outgoing messages.
@@ -732,7 +1495,6 @@ type mutePlatform struct {
 func (m *mutePlatform) Reply(_ context.Context, _ any, _ string) error { return nil }
 func (m *mutePlatform) Send(_ context.Context, _ any, _ string) error  { return nil }
 
-
 func GenerateCronID() string {
 	b := make([]byte, 4)
 	if _, err := rand.Read(b); err != nil {
@@ -796,8 +1558,18 @@ func parseStep(field string) (int, bool) {
 }
 
 // CronExprToHuman converts a standard 5-field cron expression to a human-readable string.
+// A leading CRON_TZ=<zone> (or TZ=<zone>) prefix, as produced by CronJob.Spec,
+// is rendered as a trailing "(zone)".
 func CronExprToHuman(expr string, lang Language) string {
 	fields := strings.Fields(expr)
+	if len(fields) > 0 {
+		if tz, ok := strings.CutPrefix(fields[0], "CRON_TZ="); ok {
+			return CronExprToHuman(strings.Join(fields[1:], " "), lang) + " (" + tz + ")"
+		}
+		if tz, ok := strings.CutPrefix(fields[0], "TZ="); ok {
+			return CronExprToHuman(strings.Join(fields[1:], " "), lang) + " (" + tz + ")"
+		}
+	}
 	if len(fields) != 5 {
 		return expr
 	}
@@ -822,6 +1594,28 @@ func CronExprToHuman(expr string, lang Language) string {
 		}
 	}
 
+	// Interval inside an hour window: */15 9-18 * * 1-5, 0 9-18/2 * * *
+	if m := cronHourWindowRe.FindStringSubmatch(hour); m != nil && dom == "*" && month == "*" {
+		var base string
+		switch {
+		case strings.HasPrefix(minute, "*/"):
+			base = CronExprToHuman(minute+" * * * *", lang)
+		case m[3] != "":
+			base = CronExprToHuman(minute+" */"+m[3]+" * * *", lang)
+		default:
+			base = CronExprToHuman(minute+" */1 * * *", lang)
+		}
+		sep := ", "
+		if cjk {
+			sep = " "
+		}
+		out := base + sep + padZero(m[1]) + ":00-" + padZero(m[2]) + ":00"
+		if dow != "*" {
+			out += sep + cronDowToHuman(dow, weekdays, lang)
+		}
+		return out
+	}
+
 	// Hour interval: M */N * * * → "Every N hours (:MM)"
 	if hourStep, ok := parseStep(hour); ok && allWild {
 		m := padZero(minute)
@@ -846,14 +1640,10 @@ func CronExprToHuman(expr string, lang Language) string {
 
 	// Weekday
 	if dow != "*" {
-		if n, err := strconv.Atoi(dow); err == nil && n >= 0 && n <= 6 {
-			if cjk {
-				parts = append(parts, weekdays[n])
-			} else {
-				parts = append(parts, "Every "+weekdays[n])
-			}
+		if cjk {
+			parts = append(parts, cronDowToHuman(dow, weekdays, lang))
 		} else {
-			parts = append(parts, "weekday("+dow+")")
+			parts = append(parts, "Every "+cronDowToHuman(dow, weekdays, lang))
 		}
 	}
 
@@ -959,6 +1749,49 @@ func CronExprToHuman(expr string, lang Language) string {
 	return strings.Join(parts, ", ")
 }
 
+// cronHourWindowRe matches an hour field restricted to a window, optionally
+// with a step: 9-18 or 9-18/2.
+var cronHourWindowRe = regexp.MustCompile(`^(\d{1,2})-(\d{1,2})(?:/(\d{1,2}))?$`)
+
+// cronDowToHuman renders a day-of-week field such as 1, 1-5 or 1,3,5 with
+// localized weekday names. Unrecognized fields fall back to "weekday(<dow>)".
+func cronDowToHuman(dow string, weekdays [7]string, lang Language) string {
+	rangeSep, listSep := "-", ", "
+	switch lang {
+	case LangChinese, LangTraditionalChinese:
+		rangeSep, listSep = "至", "、"
+	case LangJapanese:
+		rangeSep, listSep = "〜", "・"
+	case LangSpanish:
+		rangeSep = " a "
+	}
+	name := func(s string) (string, bool) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 || n > 7 {
+			return "", false
+		}
+		return weekdays[n%7], true
+	}
+	var parts []string
+	for _, item := range strings.Split(dow, ",") {
+		if from, to, ok := strings.Cut(item, "-"); ok {
+			a, okA := name(from)
+			b, okB := name(to)
+			if !okA || !okB {
+				return "weekday(" + dow + ")"
+			}
+			parts = append(parts, a+rangeSep+b)
+			continue
+		}
+		n, ok := name(item)
+		if !ok {
+			return "weekday(" + dow + ")"
+		}
+		parts = append(parts, n)
+	}
+	return strings.Join(parts, listSep)
+}
+
 func padZero(s string) string {
 	if len(s) == 1 {
 		return "0" + s
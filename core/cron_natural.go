@@ -0,0 +1,1037 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Natural-language schedules.
+//
+// ParseNaturalSchedule turns phrases such as "every weekday at 9:30",
+// "每周一早上九点", "2時間ごと 9時から18時まで", "cada lunes a las 9",
+// "chaque jour ouvré à 9h30", "alle 2 Stunden zwischen 9 und 18" or
+// "평일 9시 30분에" into a standard 5-field cron expression. Parsing is deterministic: every supported
+// language is lexed into the same small token set, and a single grammar builds
+// the expression from those tokens.
+
+type nlKind int
+
+const (
+	nlEvery    nlKind = iota + 1 // every / 每 / 毎 / cada, also postfix ごと
+	nlDay                        // day(s) / 天 / día
+	nlDayWord                    // 日 / 号 — day-of-month suffix, or Sunday in a weekday list
+	nlWeekday                    // weekdays / 工作日 / 平日 / laborables
+	nlWeekend                    // weekends / 周末 / 週末 / fin de semana
+	nlWeek                       // week / 周 / 週 / semana
+	nlMonth                      // month / 月 / mes
+	nlHour                       // hour(s) / 小时 / 時間 / hora(s)
+	nlMinute                     // minute(s) / 分钟 / 分 / minuto(s)
+	nlH                          // "h" right after a number: 2h (interval) or 18h (clock)
+	nlDow                        // a day of the week; n = 0 (Sunday) .. 6
+	nlAt                         // at / a las
+	nlBetween                    // between / from / entre / 从
+	nlAnd                        // and / y / 和 / と
+	nlTo                         // to / - / 到 / 至 / から / a / hasta
+	nlSep                        // , 、 ・
+	nlAM                         // am / 早上 / 午前 / de la mañana
+	nlPM                         // pm / 下午 / 午後 / de la tarde
+	nlNight                      // at night / 晚上 / 夜 / de la noche — pm, but 12 is midnight
+	nlNoon                       // noon / 中午 / 正午 / mediodía
+	nlMidnight                   // midnight / 午夜 / medianoche
+	nlHalf                       // 半 / y media
+	nlOClock                     // o'clock / 点 / 時
+	nlNum                        // a number; n = value
+	nlDigits                     // a run of CJK digits without 十, e.g. 三五 in 每周一三五
+	nlTime                       // hh:mm; n = hour, m = minute
+	nlFiller                     // on / the / の / 在 / el … — ignored by the grammar
+)
+
+type nlTok struct {
+	kind   nlKind
+	n, m   int
+	digits []int
+	cjk    bool // token came from CJK text (numerals and weekday names)
+	ord    bool // ordinal number such as 1st / 15th
+}
+
+// nlLexemes maps lower-case phrases in every supported language to tokens.
+// Longer phrases win, so "a las" beats "a" and "時間" beats "時".
+var nlLexemes = map[string][]nlTok{
+	// English
+	"every": {{kind: nlEvery}}, "each": {{kind: nlEvery}},
+	"daily": {{kind: nlEvery}, {kind: nlDay}}, "hourly": {{kind: nlEvery}, {kind: nlHour}},
+	"weekly": {{kind: nlEvery}, {kind: nlWeek}}, "monthly": {{kind: nlEvery}, {kind: nlMonth}},
+	"every morning": {{kind: nlEvery}, {kind: nlDay}, {kind: nlAM}},
+	"every evening": {{kind: nlEvery}, {kind: nlDay}, {kind: nlPM}},
+	"every night":   {{kind: nlEvery}, {kind: nlDay}, {kind: nlNight}},
+	"day":           {{kind: nlDay}}, "days": {{kind: nlDay}},
+	"weekday": {{kind: nlWeekday}}, "weekdays": {{kind: nlWeekday}},
+	"workday": {{kind: nlWeekday}}, "workdays": {{kind: nlWeekday}},
+	"business day": {{kind: nlWeekday}}, "business days": {{kind: nlWeekday}},
+	"weekend": {{kind: nlWeekend}}, "weekends": {{kind: nlWeekend}},
+	"week": {{kind: nlWeek}}, "month": {{kind: nlMonth}},
+	"hour": {{kind: nlHour}}, "hours": {{kind: nlHour}}, "hr": {{kind: nlHour}}, "hrs": {{kind: nlHour}},
+	"minute": {{kind: nlMinute}}, "minutes": {{kind: nlMinute}}, "min": {{kind: nlMinute}},
+	"mins": {{kind: nlMinute}}, "m": {{kind: nlMinute}}, "h": {{kind: nlH}},
+	"at": {{kind: nlAt}}, "@": {{kind: nlAt}},
+	"between": {{kind: nlBetween}}, "from": {{kind: nlBetween}},
+	"and": {{kind: nlAnd}}, "to": {{kind: nlTo}}, "through": {{kind: nlTo}},
+	"until": {{kind: nlTo}}, "till": {{kind: nlTo}},
+	"am": {{kind: nlAM}}, "a.m.": {{kind: nlAM}}, "pm": {{kind: nlPM}}, "p.m.": {{kind: nlPM}},
+	"in the morning": {{kind: nlAM}}, "morning": {{kind: nlAM}},
+	"in the afternoon": {{kind: nlPM}}, "afternoon": {{kind: nlPM}},
+	"in the evening": {{kind: nlPM}}, "evening": {{kind: nlPM}}, "at night": {{kind: nlNight}},
+	"noon": {{kind: nlNoon}}, "midday": {{kind: nlNoon}}, "midnight": {{kind: nlMidnight}},
+	"o'clock": {{kind: nlOClock}}, "oclock": {{kind: nlOClock}},
+	"on": {{kind: nlFiller}}, "the": {{kind: nlFiller}}, "of": {{kind: nlFiller}}, "in": {{kind: nlFiller}},
+
+	// Chinese (simplified and traditional)
+	"每": {{kind: nlEvery}}, "每隔": {{kind: nlEvery}},
+	"每日": {{kind: nlEvery}, {kind: nlDay}}, "天": {{kind: nlDay}},
+	"日": {{kind: nlDayWord}}, "号": {{kind: nlDayWord}}, "號": {{kind: nlDayWord}},
+	"工作日": {{kind: nlWeekday}}, "周末": {{kind: nlWeekend}}, "週末": {{kind: nlWeekend}},
+	"周": {{kind: nlWeek}}, "週": {{kind: nlWeek}}, "星期": {{kind: nlWeek}},
+	"礼拜": {{kind: nlWeek}}, "禮拜": {{kind: nlWeek}},
+	"月": {{kind: nlMonth}}, "个月": {{kind: nlMonth}}, "個月": {{kind: nlMonth}},
+	"小时": {{kind: nlHour}}, "小時": {{kind: nlHour}}, "个小时": {{kind: nlHour}}, "個小時": {{kind: nlHour}},
+	"钟头": {{kind: nlHour}}, "鐘頭": {{kind: nlHour}},
+	"分钟": {{kind: nlMinute}}, "分鐘": {{kind: nlMinute}}, "分": {{kind: nlMinute}},
+	"点": {{kind: nlOClock}}, "點": {{kind: nlOClock}}, "时": {{kind: nlOClock}}, "時": {{kind: nlOClock}},
+	"半":  {{kind: nlHalf}},
+	"早上": {{kind: nlAM}}, "上午": {{kind: nlAM}}, "早晨": {{kind: nlAM}}, "清晨": {{kind: nlAM}}, "凌晨": {{kind: nlAM}},
+	"下午": {{kind: nlPM}}, "晚上": {{kind: nlNight}}, "傍晚": {{kind: nlPM}}, "夜里": {{kind: nlNight}}, "夜裡": {{kind: nlNight}},
+	"中午": {{kind: nlNoon}}, "午夜": {{kind: nlMidnight}}, "半夜": {{kind: nlMidnight}},
+	"从": {{kind: nlBetween}}, "從": {{kind: nlBetween}},
+	"和": {{kind: nlAnd}}, "及": {{kind: nlAnd}},
+	"到": {{kind: nlTo}}, "至": {{kind: nlTo}},
+	"在": {{kind: nlFiller}}, "的": {{kind: nlFiller}}, "个": {{kind: nlFiller}}, "個": {{kind: nlFiller}},
+	// Closing words carry no meaning but may end a schedule, so they lex to nothing.
+	"整": {}, "之间": {}, "之間": {}, "间": {},
+
+	// Japanese
+	"毎": {{kind: nlEvery}}, "ごと": {{kind: nlEvery}}, "おき": {{kind: nlEvery}},
+	"毎日": {{kind: nlEvery}, {kind: nlDay}}, "毎時": {{kind: nlEvery}, {kind: nlHour}},
+	"毎朝": {{kind: nlEvery}, {kind: nlDay}, {kind: nlAM}}, "毎晩": {{kind: nlEvery}, {kind: nlDay}, {kind: nlNight}},
+	"平日": {{kind: nlWeekday}}, "土日": {{kind: nlWeekend}},
+	"時間": {{kind: nlHour}},
+	"午前": {{kind: nlAM}}, "朝": {{kind: nlAM}}, "午後": {{kind: nlPM}}, "夜": {{kind: nlNight}},
+	"夕方": {{kind: nlPM}}, "晩": {{kind: nlNight}}, "正午": {{kind: nlNoon}}, "昼": {{kind: nlNoon}},
+	"から": {{kind: nlTo}}, "と": {{kind: nlAnd}},
+	"の": {{kind: nlFiller}}, "に": {{kind: nlFiller}},
+	"まで": {}, "の間": {}, "間": {},
+
+	// Spanish
+	"cada": {{kind: nlEvery}}, "todos los": {{kind: nlEvery}}, "todas las": {{kind: nlEvery}},
+	"diario": {{kind: nlEvery}, {kind: nlDay}}, "diariamente": {{kind: nlEvery}, {kind: nlDay}},
+	"día": {{kind: nlDay}}, "dia": {{kind: nlDay}}, "días": {{kind: nlDay}}, "dias": {{kind: nlDay}},
+	"día laborable": {{kind: nlWeekday}}, "dia laborable": {{kind: nlWeekday}},
+	"días laborables": {{kind: nlWeekday}}, "dias laborables": {{kind: nlWeekday}},
+	"días hábiles": {{kind: nlWeekday}}, "dias habiles": {{kind: nlWeekday}},
+	"laborables": {{kind: nlWeekday}}, "entre semana": {{kind: nlWeekday}},
+	"de lunes a viernes": {{kind: nlWeekday}},
+	"fin de semana":      {{kind: nlWeekend}}, "fines de semana": {{kind: nlWeekend}},
+	"semana": {{kind: nlWeek}}, "mes": {{kind: nlMonth}}, "de cada mes": {{kind: nlEvery}, {kind: nlMonth}},
+	"hora": {{kind: nlHour}}, "horas": {{kind: nlHour}},
+	"minuto": {{kind: nlMinute}}, "minutos": {{kind: nlMinute}},
+	"a las": {{kind: nlAt}}, "a la": {{kind: nlAt}},
+	"entre": {{kind: nlBetween}}, "entre las": {{kind: nlBetween}}, "de": {{kind: nlBetween}},
+	"desde": {{kind: nlBetween}}, "desde las": {{kind: nlBetween}},
+	"y": {{kind: nlAnd}}, "y las": {{kind: nlAnd}},
+	"a": {{kind: nlTo}}, "hasta": {{kind: nlTo}}, "hasta las": {{kind: nlTo}},
+	"de la mañana": {{kind: nlAM}}, "de la manana": {{kind: nlAM}},
+	"por la mañana": {{kind: nlAM}}, "por la manana": {{kind: nlAM}},
+	"de la tarde": {{kind: nlPM}}, "de la noche": {{kind: nlNight}},
+	"por la tarde": {{kind: nlPM}}, "por la noche": {{kind: nlNight}},
+	"mediodía": {{kind: nlNoon}}, "mediodia": {{kind: nlNoon}}, "medianoche": {{kind: nlMidnight}},
+	"y media": {{kind: nlHalf}},
+	"el":      {{kind: nlFiller}}, "los": {{kind: nlFiller}}, "las": {{kind: nlFiller}}, "en": {{kind: nlFiller}},
+
+	// French
+	"chaque": {{kind: nlEvery}}, "tous les": {{kind: nlEvery}}, "toutes les": {{kind: nlEvery}},
+	"quotidien": {{kind: nlEvery}, {kind: nlDay}}, "de chaque mois": {{kind: nlEvery}, {kind: nlMonth}},
+	"jour": {{kind: nlDay}}, "jours": {{kind: nlDay}},
+	"jour ouvré": {{kind: nlWeekday}}, "jours ouvrés": {{kind: nlWeekday}},
+	"jour ouvrable": {{kind: nlWeekday}}, "jours ouvrables": {{kind: nlWeekday}},
+	"du lundi au vendredi": {{kind: nlWeekday}},
+	"week-end":             {{kind: nlWeekend}}, "week-ends": {{kind: nlWeekend}},
+	"semaine": {{kind: nlWeek}}, "mois": {{kind: nlMonth}},
+	"heure": {{kind: nlHour}}, "heures": {{kind: nlHour}},
+	"à": {{kind: nlAt}}, "et": {{kind: nlAnd}}, "jusqu'à": {{kind: nlTo}}, "au": {{kind: nlTo}},
+	"du matin": {{kind: nlAM}}, "matin": {{kind: nlAM}},
+	"de l'après-midi": {{kind: nlPM}}, "l'après-midi": {{kind: nlPM}}, "après-midi": {{kind: nlPM}},
+	"du soir": {{kind: nlPM}}, "soir": {{kind: nlPM}}, "la nuit": {{kind: nlNight}},
+	"midi": {{kind: nlNoon}}, "minuit": {{kind: nlMidnight}},
+	"et demie": {{kind: nlHalf}},
+	"le":       {{kind: nlFiller}}, "la": {{kind: nlFiller}}, "les": {{kind: nlFiller}},
+
+	// German
+	"jeden": {{kind: nlEvery}}, "jede": {{kind: nlEvery}}, "jedes": {{kind: nlEvery}}, "alle": {{kind: nlEvery}},
+	"täglich": {{kind: nlEvery}, {kind: nlDay}}, "stündlich": {{kind: nlEvery}, {kind: nlHour}},
+	"wöchentlich": {{kind: nlEvery}, {kind: nlWeek}}, "monatlich": {{kind: nlEvery}, {kind: nlMonth}},
+	"tag": {{kind: nlDay}}, "tage": {{kind: nlDay}},
+	"werktag": {{kind: nlWeekday}}, "werktage": {{kind: nlWeekday}}, "werktags": {{kind: nlWeekday}},
+	"wochenende": {{kind: nlWeekend}}, "am wochenende": {{kind: nlWeekend}},
+	"woche": {{kind: nlWeek}}, "monat": {{kind: nlMonth}},
+	"stunde": {{kind: nlHour}}, "stunden": {{kind: nlHour}}, "minuten": {{kind: nlMinute}},
+	"um": {{kind: nlAt}}, "zwischen": {{kind: nlBetween}}, "von": {{kind: nlBetween}},
+	"und": {{kind: nlAnd}}, "bis": {{kind: nlTo}},
+	"uhr":     {{kind: nlOClock}},
+	"morgens": {{kind: nlAM}}, "vormittags": {{kind: nlAM}},
+	"nachmittags": {{kind: nlPM}}, "abends": {{kind: nlPM}}, "nachts": {{kind: nlNight}},
+	"mittag": {{kind: nlNoon}}, "mittags": {{kind: nlNoon}}, "mitternacht": {{kind: nlMidnight}},
+
+	// Korean
+	"매": {{kind: nlEvery}}, "마다": {{kind: nlEvery}},
+	"매일": {{kind: nlEvery}, {kind: nlDay}}, "매시간": {{kind: nlEvery}, {kind: nlHour}},
+	"매주": {{kind: nlEvery}, {kind: nlWeek}}, "매월": {{kind: nlEvery}, {kind: nlMonth}}, "매달": {{kind: nlEvery}, {kind: nlMonth}},
+	"일": {{kind: nlDayWord}}, "평일": {{kind: nlWeekday}}, "주말": {{kind: nlWeekend}},
+	"시간": {{kind: nlHour}}, "분": {{kind: nlMinute}}, "시": {{kind: nlOClock}}, "반": {{kind: nlHalf}},
+	"오전": {{kind: nlAM}}, "아침": {{kind: nlAM}}, "오후": {{kind: nlPM}}, "저녁": {{kind: nlPM}}, "밤": {{kind: nlNight}},
+	"정오": {{kind: nlNoon}}, "자정": {{kind: nlMidnight}},
+	"부터": {{kind: nlTo}}, "와": {{kind: nlAnd}}, "과": {{kind: nlAnd}},
+	"의": {{kind: nlFiller}},
+	"에": {}, "까지": {}, "사이": {}, "사이에": {},
+
+	// Punctuation
+	",": {{kind: nlSep}}, "，": {{kind: nlSep}}, "、": {{kind: nlSep}}, "・": {{kind: nlSep}},
+	"-": {{kind: nlTo}}, "–": {{kind: nlTo}}, "~": {{kind: nlTo}}, "〜": {{kind: nlTo}}, "～": {{kind: nlTo}},
+}
+
+var (
+	nlLexemeOnce sync.Once
+	nlLexemeKeys []string // nlLexemes keys, longest first
+)
+
+func init() {
+	en := [7][]string{
+		{"sunday", "sundays", "sun"},
+		{"monday", "mondays", "mon"},
+		{"tuesday", "tuesdays", "tue", "tues"},
+		{"wednesday", "wednesdays", "wed", "weds"},
+		{"thursday", "thursdays", "thu", "thur", "thurs"},
+		{"friday", "fridays", "fri"},
+		{"saturday", "saturdays", "sat"},
+	}
+	es := [7][]string{
+		{"domingo", "domingos"},
+		{"lunes"},
+		{"martes"},
+		{"miércoles", "miercoles"},
+		{"jueves"},
+		{"viernes"},
+		{"sábado", "sábados", "sabado", "sabados"},
+	}
+	fr := [7][]string{
+		{"dimanche", "dimanches"},
+		{"lundi", "lundis"},
+		{"mardi", "mardis"},
+		{"mercredi", "mercredis"},
+		{"jeudi", "jeudis"},
+		{"vendredi", "vendredis"},
+		{"samedi", "samedis"},
+	}
+	de := [7][]string{
+		{"sonntag", "sonntags"},
+		{"montag", "montags"},
+		{"dienstag", "dienstags"},
+		{"mittwoch", "mittwochs"},
+		{"donnerstag", "donnerstags"},
+		{"freitag", "freitags"},
+		{"samstag", "samstags", "sonnabend"},
+	}
+	ko := [7][]string{
+		{"일요일"}, {"월요일"}, {"화요일"}, {"수요일"}, {"목요일"}, {"금요일"}, {"토요일"},
+	}
+	ja := [7][]string{
+		{"日曜日", "日曜"},
+		{"月曜日", "月曜"},
+		{"火曜日", "火曜", "火"},
+		{"水曜日", "水曜", "水"},
+		{"木曜日", "木曜", "木"},
+		{"金曜日", "金曜", "金"},
+		{"土曜日", "土曜", "土"},
+	}
+	for d := 0; d < 7; d++ {
+		for _, w := range en[d] {
+			nlLexemes[w] = []nlTok{{kind: nlDow, n: d}}
+		}
+		for _, w := range es[d] {
+			nlLexemes[w] = []nlTok{{kind: nlDow, n: d}}
+		}
+		for _, w := range fr[d] {
+			nlLexemes[w] = []nlTok{{kind: nlDow, n: d}}
+		}
+		for _, w := range de[d] {
+			nlLexemes[w] = []nlTok{{kind: nlDow, n: d}}
+		}
+		for _, w := range ko[d] {
+			nlLexemes[w] = []nlTok{{kind: nlDow, n: d}}
+		}
+		for _, w := range ja[d] {
+			nlLexemes[w] = []nlTok{{kind: nlDow, n: d, cjk: true}}
+		}
+	}
+	zhDays := map[string]int{"日": 0, "天": 0, "一": 1, "二": 2, "三": 3, "四": 4, "五": 5, "六": 6}
+	for _, prefix := range []string{"周", "週", "星期", "礼拜", "禮拜"} {
+		for name, d := range zhDays {
+			nlLexemes[prefix+name] = []nlTok{{kind: nlDow, n: d, cjk: true}}
+		}
+	}
+}
+
+func nlSortedLexemes() []string {
+	nlLexemeOnce.Do(func() {
+		for k := range nlLexemes {
+			nlLexemeKeys = append(nlLexemeKeys, k)
+		}
+		sort.Slice(nlLexemeKeys, func(i, j int) bool {
+			if len(nlLexemeKeys[i]) != len(nlLexemeKeys[j]) {
+				return len(nlLexemeKeys[i]) > len(nlLexemeKeys[j])
+			}
+			return nlLexemeKeys[i] < nlLexemeKeys[j]
+		})
+	})
+	return nlLexemeKeys
+}
+
+var cjkDigitValues = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '兩': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// nlLex splits s into schedule tokens. It fails on anything it does not know,
+// which is how SplitNaturalSchedule finds where the schedule ends.
+func nlLex(s string) ([]nlTok, bool) {
+	s = strings.ToLower(s)
+	keys := nlSortedLexemes()
+	var toks []nlTok
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+
+		// ASCII numbers: 9, 15th, 9:30
+		if r >= '0' && r <= '9' {
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(s[i:j])
+			if j+2 < len(s) && (s[j] == ':' || s[j] == '.') && isDigitPair(s[j+1:j+3]) {
+				m, _ := strconv.Atoi(s[j+1 : j+3])
+				toks = append(toks, nlTok{kind: nlTime, n: n, m: m})
+				i = j + 3
+				continue
+			}
+			if strings.HasPrefix(s[j:], "：") && len(s) >= j+len("：")+2 && isDigitPair(s[j+len("："):j+len("：")+2]) {
+				k := j + len("：")
+				m, _ := strconv.Atoi(s[k : k+2])
+				toks = append(toks, nlTok{kind: nlTime, n: n, m: m})
+				i = k + 2
+				continue
+			}
+			tok := nlTok{kind: nlNum, n: n}
+			for _, suf := range []string{"st", "nd", "rd", "th", "º", "°"} {
+				if strings.HasPrefix(s[j:], suf) && (j+len(suf) == len(s) || !isASCIILetter(s[j+len(suf)])) {
+					tok.ord = true
+					j += len(suf)
+					break
+				}
+			}
+			toks = append(toks, tok)
+			i = j
+			continue
+		}
+
+		matched := false
+		for _, k := range keys {
+			if !strings.HasPrefix(s[i:], k) {
+				continue
+			}
+			end := i + len(k)
+			if isASCIILetter(k[len(k)-1]) && end < len(s) && isASCIILetter(s[end]) {
+				continue
+			}
+			toks = append(toks, nlLexemes[k]...)
+			i = end
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		// CJK numerals: 九, 十五, 二十三, or a digit run like 三五
+		if _, ok := cjkDigitValues[r]; ok || r == '十' {
+			j := i
+			var runes []rune
+			for j < len(s) {
+				c, sz := utf8.DecodeRuneInString(s[j:])
+				if _, ok := cjkDigitValues[c]; !ok && c != '十' {
+					break
+				}
+				runes = append(runes, c)
+				j += sz
+			}
+			tok, ok := parseCJKNumber(runes)
+			if !ok {
+				return nil, false
+			}
+			toks = append(toks, tok)
+			i = j
+			continue
+		}
+		return nil, false
+	}
+	return toks, true
+}
+
+func isDigitPair(s string) bool {
+	return len(s) == 2 && s[0] >= '0' && s[0] <= '9' && s[1] >= '0' && s[1] <= '9'
+}
+
+func parseCJKNumber(runes []rune) (nlTok, bool) {
+	tenAt := -1
+	for i, r := range runes {
+		if r == '十' {
+			if tenAt >= 0 {
+				return nlTok{}, false
+			}
+			tenAt = i
+		}
+	}
+	if tenAt < 0 {
+		if len(runes) == 1 {
+			return nlTok{kind: nlNum, n: cjkDigitValues[runes[0]], cjk: true}, true
+		}
+		digits := make([]int, len(runes))
+		for i, r := range runes {
+			digits[i] = cjkDigitValues[r]
+		}
+		return nlTok{kind: nlDigits, digits: digits, cjk: true}, true
+	}
+	tens, ones := 1, 0
+	switch tenAt {
+	case 0:
+	case 1:
+		tens = cjkDigitValues[runes[0]]
+	default:
+		return nlTok{}, false
+	}
+	switch len(runes) - tenAt - 1 {
+	case 0:
+	case 1:
+		ones = cjkDigitValues[runes[tenAt+1]]
+	default:
+		return nlTok{}, false
+	}
+	return nlTok{kind: nlNum, n: tens*10 + ones, cjk: true}, true
+}
+
+// nlSchedule is the parsed meaning of a phrase before it becomes a cron expression.
+type nlSchedule struct {
+	days     bool
+	dows     map[int]bool // nil = every day
+	dom      int          // 0 = any day of month
+	interval int          // 0 = fires once per matching day
+	unit     nlKind       // nlMinute or nlHour when interval > 0
+	hasTime  bool
+	hour     int
+	minute   int
+	hasRange bool
+	from, to int
+}
+
+type nlParser struct {
+	toks []nlTok
+	pos  int
+	s    nlSchedule
+}
+
+func (p *nlParser) peek(off int) nlTok {
+	i := p.pos + off
+	if i < len(p.toks) {
+		return p.toks[i]
+	}
+	return nlTok{}
+}
+
+func (p *nlParser) is(off int, kinds ...nlKind) bool {
+	k := p.peek(off).kind
+	for _, want := range kinds {
+		if k == want {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCronExprPrefix reports whether args start with a valid 5-field cron
+// expression followed by at least one more word.
+func hasCronExprPrefix(args []string) bool {
+	if len(args) < 6 {
+		return false
+	}
+	_, err := cron.ParseStandard(strings.Join(args[:5], " "))
+	return err == nil
+}
+
+// errNaturalScheduleNeedsTime is returned for day-level schedules such as
+// "every sunday" that do not say when during the day to run.
+var errNaturalScheduleNeedsTime = errors.New("schedule needs a time of day, e.g. \"every sunday at 9\"")
+
+// ParseNaturalSchedule converts a natural-language recurring schedule in any
+// supported language into a standard 5-field cron expression. Day-level
+// schedules must include a time of day.
+func ParseNaturalSchedule(text string) (string, error) {
+	toks, ok := nlLex(text)
+	if !ok || len(toks) == 0 {
+		return "", fmt.Errorf("unrecognized schedule %q", text)
+	}
+	switch toks[len(toks)-1].kind {
+	case nlFiller, nlSep, nlAnd, nlTo, nlBetween, nlAt:
+		return "", fmt.Errorf("incomplete schedule %q", text)
+	}
+	var filtered []nlTok
+	for _, t := range toks {
+		if t.kind != nlFiller {
+			filtered = append(filtered, t)
+		}
+	}
+	p := &nlParser{toks: filtered}
+	for p.pos < len(p.toks) {
+		switch {
+		case p.parseRange(), p.parsePeriod(), p.parseTime():
+		case p.is(0, nlSep, nlAnd):
+			p.pos++
+		default:
+			return "", fmt.Errorf("unrecognized schedule %q", text)
+		}
+	}
+	expr, err := p.s.cronExpr()
+	if err != nil {
+		return "", err
+	}
+	if _, err := cron.ParseStandard(expr); err != nil {
+		return "", fmt.Errorf("invalid schedule %q: %w", text, err)
+	}
+	return expr, nil
+}
+
+// SplitNaturalSchedule finds the longest leading part of text that parses as
+// a natural-language schedule and returns its cron expression together with
+// the remaining text (typically the prompt or command).
+//
+// Word boundaries are preferred. Because Chinese and Japanese are written
+// without spaces, the schedule may also end inside the following word
+// ("每天九点提醒我"), but only when that adds a number, so "平日の9時半 朝会"
+// keeps 朝会 intact.
+func SplitNaturalSchedule(text string) (expr, rest string, ok bool) {
+	text = strings.TrimSpace(text)
+	const maxScheduleRunes = 80
+	if utf8.RuneCountInString(text) > maxScheduleRunes {
+		text, rest = string([]rune(text)[:maxScheduleRunes]), string([]rune(text)[maxScheduleRunes:])
+	}
+
+	best := -1
+	next := 0 // start of the word following the best word-boundary match
+	needTime := false
+	inWord := false
+	for i, r := range text + " " {
+		switch {
+		case unicode.IsSpace(r) && inWord:
+			inWord = false
+			e, err := ParseNaturalSchedule(text[:i])
+			switch {
+			case err == nil:
+				expr, best = e, i
+				next = -1
+			case errors.Is(err, errNaturalScheduleNeedsTime):
+				// "每天 九点提醒我": the time may follow in the next word.
+				needTime = true
+				next = -1
+			}
+		case !unicode.IsSpace(r) && !inWord:
+			inWord = true
+			if next < 0 || best < 0 && i == 0 {
+				next = i
+			}
+		}
+	}
+
+	if next >= 0 {
+		prev := rune(0)
+		hasNum := false
+		for i, r := range text[next:] {
+			if unicode.IsSpace(r) {
+				break
+			}
+			if i > 0 && (!isASCIIAlnum(prev) || !isASCIIAlnum(r)) && (best < 0 && !needTime || hasNum) {
+				if e, err := ParseNaturalSchedule(text[:next+i]); err == nil {
+					expr, best = e, next+i
+				}
+			}
+			if _, ok := cjkDigitValues[r]; ok || r == '十' || (r >= '0' && r <= '9') {
+				hasNum = true
+			}
+			prev = r
+		}
+	}
+	if best < 0 {
+		return "", "", false
+	}
+	rest = strings.TrimLeft(text[best:]+rest, " \t\n,，:：;；、")
+	return expr, rest, true
+}
+
+// naturalScheduleNeedsTime reports whether text starts with a recurring
+// schedule that would parse if it had a time of day, so the caller can ask
+// for one instead of guessing.
+func naturalScheduleNeedsTime(text string) bool {
+	text = strings.TrimSpace(text)
+	if _, _, ok := SplitNaturalSchedule(text); ok {
+		return false
+	}
+	n := 0
+	for i := range text {
+		if n++; n > 80 {
+			break
+		}
+		if i > 0 {
+			if _, err := ParseNaturalSchedule(text[:i]); errors.Is(err, errNaturalScheduleNeedsTime) {
+				return true
+			}
+		}
+	}
+	_, err := ParseNaturalSchedule(text)
+	return errors.Is(err, errNaturalScheduleNeedsTime)
+}
+
+func isASCIIAlnum(r rune) bool {
+	return r < utf8.RuneSelf && (isASCIILetter(byte(r)) || (r >= '0' && r <= '9') || r == '\'' || r == '.' || r == ':')
+}
+
+// parsePeriod handles the "how often" part: daily, weekdays, a list of
+// weekdays, a day of the month, or an N-minute/hour interval.
+func (p *nlParser) parsePeriod() bool {
+	start := p.pos
+	every := false
+	if p.is(0, nlEvery) {
+		every = true
+		p.pos++
+	}
+	t := p.peek(0)
+	switch {
+	case t.kind == nlDay && every:
+		p.pos++
+		return p.setDays(nil, 0, start)
+	case t.kind == nlWeekday:
+		p.pos++
+		return p.setDays(map[int]bool{1: true, 2: true, 3: true, 4: true, 5: true}, 0, start)
+	case t.kind == nlWeekend:
+		p.pos++
+		return p.setDays(map[int]bool{0: true, 6: true}, 0, start)
+	case t.kind == nlWeek:
+		p.pos++
+		if p.is(0, nlWeekday, nlWeekend) {
+			return p.parsePeriodFrom(start)
+		}
+		if dows, ok := p.parseDowList(true); ok {
+			return p.setDays(dows, 0, start)
+		}
+	case t.kind == nlDow:
+		if dows, ok := p.parseDowList(false); ok {
+			return p.setDays(dows, 0, start)
+		}
+	case t.kind == nlMonth && every:
+		p.pos++
+		if dom, ok := p.parseDayOfMonth(true); ok {
+			return p.setDays(nil, dom, start)
+		}
+	case (t.kind == nlHour || t.kind == nlMinute) && every:
+		p.pos++
+		return p.setInterval(1, t.kind, start)
+	case t.kind == nlNum && p.is(1, nlHour, nlMinute, nlH):
+		unit := p.peek(1).kind
+		if unit == nlH {
+			unit = nlHour
+		}
+		p.pos += 2
+		if p.is(0, nlEvery) {
+			every = true
+			p.pos++
+		}
+		if every {
+			return p.setInterval(t.n, unit, start)
+		}
+	case !every && (t.kind == nlNum || t.kind == nlDay):
+		if dom, ok := p.parseDayOfMonth(false); ok && p.is(0, nlEvery) && p.is(1, nlMonth) {
+			p.pos += 2
+			return p.setDays(nil, dom, start)
+		}
+	}
+	p.pos = start
+	return false
+}
+
+// parsePeriodFrom re-enters parsePeriod after a leading "every week" so that
+// 毎週土日 / every week on weekdays are accepted.
+func (p *nlParser) parsePeriodFrom(start int) bool {
+	if p.parsePeriod() {
+		return true
+	}
+	p.pos = start
+	return false
+}
+
+func (p *nlParser) setDays(dows map[int]bool, dom, start int) bool {
+	if p.s.days {
+		p.pos = start
+		return false
+	}
+	p.s.days, p.s.dows, p.s.dom = true, dows, dom
+	return true
+}
+
+func (p *nlParser) setInterval(n int, unit nlKind, start int) bool {
+	if p.s.interval > 0 || n <= 0 {
+		p.pos = start
+		return false
+	}
+	p.s.interval, p.s.unit = n, unit
+	return true
+}
+
+// parseDowList parses "monday, wednesday and friday", "周一至周五",
+// "周一三五", "月・水・金" and similar lists. afterWeek allows bare numbers and
+// 月/日 as weekday names, as in 每周一 (lexed as 每 周一) or 毎週月・水.
+func (p *nlParser) parseDowList(afterWeek bool) (map[int]bool, bool) {
+	dows := make(map[int]bool)
+	cjk := afterWeek
+	item := func(first bool) ([]int, bool) {
+		t := p.peek(0)
+		switch {
+		case t.kind == nlDow:
+			cjk = cjk || t.cjk
+			p.pos++
+			return []int{t.n}, true
+		case !first || cjk:
+		default:
+			return nil, false
+		}
+		switch {
+		case t.kind == nlNum && t.n >= 1 && t.n <= 7 && (t.cjk || afterWeek) && !p.is(1, nlOClock, nlH, nlHour, nlMinute):
+			p.pos++
+			return []int{t.n % 7}, true
+		case t.kind == nlDigits:
+			var out []int
+			for _, d := range t.digits {
+				if d < 1 || d > 7 {
+					return nil, false
+				}
+				out = append(out, d%7)
+			}
+			p.pos++
+			return out, true
+		case t.kind == nlMonth && cjk:
+			p.pos++
+			return []int{1}, true
+		case (t.kind == nlDayWord || t.kind == nlDay) && cjk:
+			p.pos++
+			return []int{0}, true
+		}
+		return nil, false
+	}
+
+	first, ok := item(true)
+	if !ok {
+		return nil, false
+	}
+	last := first[len(first)-1]
+	for _, d := range first {
+		dows[d] = true
+	}
+	for {
+		save := p.pos
+		switch {
+		case p.is(0, nlTo):
+			p.pos++
+			next, ok := item(false)
+			if !ok {
+				p.pos = save
+				return dows, true
+			}
+			for d := last; ; d = (d + 1) % 7 {
+				dows[d] = true
+				if d == next[0] {
+					break
+				}
+			}
+			for _, d := range next {
+				dows[d] = true
+			}
+			last = next[len(next)-1]
+		case p.is(0, nlSep, nlAnd):
+			p.pos++
+			next, ok := item(false)
+			if !ok {
+				p.pos = save
+				return dows, true
+			}
+			for _, d := range next {
+				dows[d] = true
+			}
+			last = next[len(next)-1]
+		case cjk && (p.is(0, nlDigits, nlDayWord, nlDow) || (p.is(0, nlNum) && p.peek(0).cjk)):
+			next, ok := item(false)
+			if !ok {
+				p.pos = save
+				return dows, true
+			}
+			for _, d := range next {
+				dows[d] = true
+			}
+			last = next[len(next)-1]
+		default:
+			return dows, true
+		}
+	}
+}
+
+// parseDayOfMonth parses "1st", "15号", "1日", "día 15". afterMonth relaxes
+// the requirement for a day marker, as in "every month on 15".
+func (p *nlParser) parseDayOfMonth(afterMonth bool) (int, bool) {
+	start := p.pos
+	marked := false
+	if p.is(0, nlDay) {
+		marked = true
+		p.pos++
+	}
+	t := p.peek(0)
+	if t.kind != nlNum || t.n < 1 || t.n > 31 {
+		p.pos = start
+		return 0, false
+	}
+	p.pos++
+	if p.is(0, nlDayWord) {
+		marked = true
+		p.pos++
+	}
+	if !marked && !t.ord && !afterMonth {
+		p.pos = start
+		return 0, false
+	}
+	return t.n, true
+}
+
+// parseClock parses a time of day with optional AM/PM markers around it.
+// requireAnchor demands something that marks the number as a time (at, 点,
+// am, 9:30…) so that a bare number is never mistaken for one.
+func (p *nlParser) parseClock(requireAnchor, allowMinutes bool) (h, m int, ok bool) {
+	start := p.pos
+	anchored := false
+	var merid nlKind
+	for p.is(0, nlAt, nlAM, nlPM, nlNight, nlNoon, nlMidnight) {
+		if k := p.peek(0).kind; k != nlAt {
+			merid = k
+		}
+		anchored = true
+		p.pos++
+	}
+	t := p.peek(0)
+	switch t.kind {
+	case nlTime:
+		h, m = t.n, t.m
+		anchored = true
+		p.pos++
+	case nlNum:
+		h = t.n
+		p.pos++
+		if p.is(0, nlOClock, nlH) {
+			anchored = true
+			p.pos++
+			if allowMinutes && p.is(0, nlNum) && p.peek(0).n < 60 {
+				m = p.peek(0).n
+				p.pos++
+				if p.is(0, nlMinute) {
+					p.pos++
+				}
+			}
+		}
+		if allowMinutes && p.is(0, nlHalf) {
+			m = 30
+			p.pos++
+		}
+	default:
+		switch merid {
+		case nlNoon:
+			return 12, 0, true
+		case nlMidnight:
+			return 0, 0, true
+		}
+		p.pos = start
+		return 0, 0, false
+	}
+	for p.is(0, nlAM, nlPM, nlNight) {
+		merid = p.peek(0).kind
+		anchored = true
+		p.pos++
+	}
+	if requireAnchor && !anchored {
+		p.pos = start
+		return 0, 0, false
+	}
+	switch merid {
+	case nlAM, nlMidnight:
+		if h == 12 {
+			h = 0
+		}
+	case nlPM, nlNoon:
+		if h < 12 {
+			h += 12
+		}
+	case nlNight:
+		// 晚上12点 / 12 at night is midnight, not noon.
+		if h == 12 {
+			h = 0
+		} else if h < 12 {
+			h += 12
+		}
+	}
+	if h > 23 || m > 59 {
+		p.pos = start
+		return 0, 0, false
+	}
+	return h, m, true
+}
+
+func (p *nlParser) parseTime() bool {
+	if p.s.hasTime {
+		return false
+	}
+	h, m, ok := p.parseClock(true, true)
+	if !ok {
+		return false
+	}
+	p.s.hasTime, p.s.hour, p.s.minute = true, h, m
+	return true
+}
+
+// parseRange parses an hour window such as "between 9 and 18", "9点到18点",
+// "9時から18時まで", "de 9 a 18" or "de 9h à 18h".
+func (p *nlParser) parseRange() bool {
+	if p.s.hasRange {
+		return false
+	}
+	start := p.pos
+	between := false
+	if p.is(0, nlBetween) {
+		between = true
+		p.pos++
+	}
+	from, _, ok := p.parseClock(false, false)
+	if !ok || !(p.is(0, nlTo) || (between && p.is(0, nlAnd, nlAt))) {
+		p.pos = start
+		return false
+	}
+	p.pos++
+	to, _, ok := p.parseClock(false, false)
+	if !ok || to <= from {
+		p.pos = start
+		return false
+	}
+	p.s.hasRange, p.s.from, p.s.to = true, from, to
+	return true
+}
+
+func (s nlSchedule) cronExpr() (string, error) {
+	if !s.days && s.interval == 0 {
+		return "", fmt.Errorf("schedule has no recurrence")
+	}
+	dom, dow := "*", "*"
+	if s.dom > 0 {
+		dom = strconv.Itoa(s.dom)
+	}
+	if s.dows != nil {
+		dow = formatDowSet(s.dows)
+	}
+
+	if s.interval > 0 {
+		if s.hasTime {
+			return "", fmt.Errorf("an interval schedule cannot also have a time of day; use a range such as \"between 9 and 18\"")
+		}
+		hours := "*"
+		if s.hasRange {
+			hours = fmt.Sprintf("%d-%d", s.from, s.to)
+		}
+		interval, unit := s.interval, s.unit
+		if unit == nlMinute && interval%60 == 0 {
+			interval, unit = interval/60, nlHour
+		}
+		// A cron step restarts at every hour (or day), so only steps that
+		// divide it evenly keep a constant interval.
+		if unit == nlMinute {
+			if interval > 59 || 60%interval != 0 {
+				return "", fmt.Errorf("every %d minutes cannot be scheduled evenly; use a number that divides 60 (5, 10, 15, 20, 30) or whole hours", s.interval)
+			}
+			return fmt.Sprintf("*/%d %s %s * %s", interval, hours, dom, dow), nil
+		}
+		if interval > 23 || (!s.hasRange && 24%interval != 0) {
+			return "", fmt.Errorf("every %d hours cannot be scheduled evenly; use a number that divides 24 (2, 3, 4, 6, 8, 12)", interval)
+		}
+		if hours == "*" {
+			hours = fmt.Sprintf("*/%d", interval)
+		} else if interval > 1 {
+			hours += fmt.Sprintf("/%d", interval)
+		}
+		return fmt.Sprintf("0 %s %s * %s", hours, dom, dow), nil
+	}
+
+	if s.hasRange {
+		return "", fmt.Errorf("a time range needs an interval, e.g. \"every 2 hours between 9 and 18\"")
+	}
+	if !s.hasTime {
+		return "", errNaturalScheduleNeedsTime
+	}
+	return fmt.Sprintf("%d %d %s * %s", s.minute, s.hour, dom, dow), nil
+}
+
+// formatDowSet renders a weekday set as a cron field, collapsing runs of
+// three or more consecutive days into ranges (1-5).
+func formatDowSet(dows map[int]bool) string {
+	var parts []string
+	for d := 0; d < 7; {
+		if !dows[d] {
+			d++
+			continue
+		}
+		end := d
+		for end+1 < 7 && dows[end+1] {
+			end++
+		}
+		switch {
+		case end-d >= 2:
+			parts = append(parts, fmt.Sprintf("%d-%d", d, end))
+		case end > d:
+			parts = append(parts, strconv.Itoa(d), strconv.Itoa(end))
+		default:
+			parts = append(parts, strconv.Itoa(d))
+		}
+		d = end + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNaturalSchedule(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		// English
+		{"every weekday at 9:30", "30 9 * * 1-5"},
+		{"every 2 hours between 9 and 18", "0 9-18/2 * * *"},
+		{"every monday and friday at 5pm", "0 17 * * 1,5"},
+		{"every monday to friday at 9", "0 9 * * 1-5"},
+		{"monthly on the 15th at noon", "0 12 15 * *"},
+		{"daily at midnight", "0 0 * * *"},
+		{"every 15 min from 9am to 5pm", "*/15 9-17 * * *"},
+		{"every morning at 8", "0 8 * * *"},
+		{"every night at 12", "0 0 * * *"},
+		{"every 120 minutes", "0 */2 * * *"},
+		{"every 5 hours between 8 and 20", "0 8-20/5 * * *"},
+		// Chinese
+		{"每周一早上九点", "0 9 * * 1"},
+		{"每天下午3点半", "30 15 * * *"},
+		{"每周一三五 10点", "0 10 * * 1,3,5"},
+		{"每周一至周五 9:00", "0 9 * * 1-5"},
+		{"工作日每2小时 9点到18点", "0 9-18/2 * * 1-5"},
+		{"每隔30分钟", "*/30 * * * *"},
+		{"每月1号 10点", "0 10 1 * *"},
+		{"每周末晚上8点", "0 20 * * 0,6"},
+		{"每天晚上12点", "0 0 * * *"},
+		{"每天中午12点", "0 12 * * *"},
+		{"每週一早上九點", "0 9 * * 1"},
+		// Japanese
+		{"平日の9時半", "30 9 * * 1-5"},
+		{"毎週月曜日の朝9時", "0 9 * * 1"},
+		{"毎週月・水・金 10時", "0 10 * * 1,3,5"},
+		{"2時間ごと 9時から18時まで", "0 9-18/2 * * *"},
+		{"毎月1日 10時", "0 10 1 * *"},
+		// Spanish
+		{"cada lunes a las 9", "0 9 * * 1"},
+		{"de lunes a viernes a las 9:30", "30 9 * * 1-5"},
+		{"cada 2 horas entre las 9 y las 18", "0 9-18/2 * * *"},
+		{"todos los días a las 8 de la mañana", "0 8 * * *"},
+		{"el día 1 de cada mes a las 10", "0 10 1 * *"},
+		// French
+		{"chaque jour ouvré à 9h30", "30 9 * * 1-5"},
+		{"chaque dimanche à 9h", "0 9 * * 0"},
+		{"toutes les 2 heures entre 9 et 18", "0 9-18/2 * * *"},
+		{"tous les jours à 8h du matin", "0 8 * * *"},
+		{"du lundi au vendredi de 9h à 18h toutes les heures", "0 9-18 * * 1-5"},
+		// German
+		{"jeden Werktag um 9:30", "30 9 * * 1-5"},
+		{"jeden Sonntag um 9", "0 9 * * 0"},
+		{"alle 2 Stunden zwischen 9 und 18", "0 9-18/2 * * *"},
+		{"täglich um 20 Uhr", "0 20 * * *"},
+		{"montags und freitags um 17 Uhr", "0 17 * * 1,5"},
+		// Korean
+		{"평일 9시 30분에", "30 9 * * 1-5"},
+		{"매주 일요일 9시에", "0 9 * * 0"},
+		{"9시부터 18시까지 2시간마다", "0 9-18/2 * * *"},
+		{"매일 오후 3시 반", "30 15 * * *"},
+		{"매월 1일 10시", "0 10 1 * *"},
+	}
+	for _, tt := range tests {
+		got, err := ParseNaturalSchedule(tt.in)
+		if err != nil {
+			t.Errorf("ParseNaturalSchedule(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseNaturalSchedule(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseNaturalSchedule_Rejects(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"9 things",
+		"every day at 9 and",
+		"every 2 hours at 9:30",
+		"every day between 9 and 18",
+		"every 90 minutes",
+		"every 45 minutes",
+		"every 7 hours",
+		"every sunday",
+		"daily",
+		"check the report",
+	} {
+		if got, err := ParseNaturalSchedule(in); err == nil {
+			t.Errorf("ParseNaturalSchedule(%q) = %q, want error", in, got)
+		}
+	}
+}
+
+func TestNaturalScheduleNeedsTime(t *testing.T) {
+	for _, in := range []string{"every sunday", "every sunday check the report", "每周一 提醒我开会", "chaque dimanche", "jeden Sonntag", "매주 일요일"} {
+		if !naturalScheduleNeedsTime(in) {
+			t.Errorf("naturalScheduleNeedsTime(%q) = false", in)
+		}
+	}
+	for _, in := range []string{"every sunday at 9 check", "every 45 minutes", "check the report"} {
+		if naturalScheduleNeedsTime(in) {
+			t.Errorf("naturalScheduleNeedsTime(%q) = true", in)
+		}
+	}
+}
+
+func TestSplitNaturalSchedule(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantExpr string
+		wantRest string
+	}{
+		{"every day at 9 check the report", "0 9 * * *", "check the report"},
+		{"every weekday at 9:30 the standup agenda", "30 9 * * 1-5", "the standup agenda"},
+		{"每天九点提醒我喝水", "0 9 * * *", "提醒我喝水"},
+		{"每天9点，提醒我喝水", "0 9 * * *", "提醒我喝水"},
+		{"每天 九点提醒我", "0 9 * * *", "提醒我"},
+		{"平日の9時半 朝会", "30 9 * * 1-5", "朝会"},
+		{"chaque jour ouvré à 9h30 Publie l'ordre du jour du standup", "30 9 * * 1-5", "Publie l'ordre du jour du standup"},
+		{"jeden Werktag um 9:30 Poste die Standup-Agenda", "30 9 * * 1-5", "Poste die Standup-Agenda"},
+		{"평일 9시 30분에 스탠드업 안건 올려 줘", "30 9 * * 1-5", "스탠드업 안건 올려 줘"},
+	}
+	for _, tt := range tests {
+		expr, rest, ok := SplitNaturalSchedule(tt.in)
+		if !ok || expr != tt.wantExpr || rest != tt.wantRest {
+			t.Errorf("SplitNaturalSchedule(%q) = (%q, %q, %v), want (%q, %q, true)", tt.in, expr, rest, ok, tt.wantExpr, tt.wantRest)
+		}
+	}
+	if _, _, ok := SplitNaturalSchedule("9 things to check"); ok {
+		t.Error("a leading bare number must not be taken as a schedule")
+	}
+}
+
+func TestCronExprToHuman_WeekdaysAndWindows(t *testing.T) {
+	tests := []struct {
+		expr string
+		lang Language
+		want string
+	}{
+		{"30 9 * * 1-5", LangEnglish, "Every Monday-Friday at 09:30"},
+		{"30 9 * * 1-5", LangChinese, "每周一至周五 09:30"},
+		{"0 10 * * 1,3,5", LangEnglish, "Every Monday, Wednesday, Friday at 10:00"},
+		{"0 9-18/2 * * *", LangEnglish, "Every 2 h (:00), 09:00-18:00"},
+		{"0 9-18/2 * * 1-5", LangChinese, "每2小时 (:00) 09:00-18:00 周一至周五"},
+		{"*/15 9-17 * * *", LangEnglish, "Every 15 min, 09:00-17:00"},
+	}
+	for _, tt := range tests {
+		if got := CronExprToHuman(tt.expr, tt.lang); got != tt.want {
+			t.Errorf("CronExprToHuman(%q, %v) = %q, want %q", tt.expr, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestCmdCronAdd_NaturalLanguageNeedsConfirm(t *testing.T) {
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &stubPlatformEngine{n: "test"}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.cronScheduler = NewCronScheduler(store)
+	msg := &Message{SessionKey: "test:ch1", UserID: "u1", ReplyCtx: "ctx"}
+
+	e.cmdCronAdd(p, msg, strings.Fields("every weekday at 9:30 post the standup agenda"))
+	if n := len(store.List()); n != 0 {
+		t.Fatalf("job saved before confirmation: %d jobs", n)
+	}
+	sent := p.getSent()
+	if len(sent) == 0 || !strings.Contains(sent[len(sent)-1], "Every Monday-Friday at 09:30") || !strings.Contains(sent[len(sent)-1], "/cron confirm") {
+		t.Fatalf("expected interpreted schedule and confirm hint, got: %v", sent)
+	}
+
+	e.cmdCron(p, msg, []string{"confirm"})
+	jobs := store.List()
+	if len(jobs) != 1 {
+		t.Fatalf("jobs after confirm = %d, want 1", len(jobs))
+	}
+	if jobs[0].CronExpr != "30 9 * * 1-5" || jobs[0].Prompt != "post the standup agenda" {
+		t.Errorf("job = %q / %q", jobs[0].CronExpr, jobs[0].Prompt)
+	}
+
+	e.cmdCron(p, msg, []string{"confirm"})
+	sent = p.getSent()
+	if !strings.Contains(sent[len(sent)-1], "No schedule is waiting") {
+		t.Errorf("second confirm should report nothing pending, got: %q", sent[len(sent)-1])
+	}
+
+	e.cmdCronAdd(p, msg, strings.Fields("every sunday water the plants"))
+	sent = p.getSent()
+	if !strings.Contains(sent[len(sent)-1], "Add a time") || e.takePendingCron(msg.SessionKey) != nil {
+		t.Errorf("schedule without a time should ask for one, got: %q", sent[len(sent)-1])
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -146,6 +149,9 @@ func TestCronExprToHuman_BasicCases(t *testing.T) {
 		// Regular cases still work
 		{"0 0 1 * *", LangEnglish, "Monthly, day 1, 00:00"},
 		{"0 0 1 * *", LangChinese, "每月1日 00:00"},
+		// Time zone prefix
+		{"CRON_TZ=Asia/Shanghai 0 6 * * *", LangEnglish, "Daily at 06:00 (Asia/Shanghai)"},
+		{"TZ=Europe/Berlin 0 6 * * *", LangChinese, "每天 06:00 (Europe/Berlin)"},
 	}
 	for _, tt := range tests {
 		got := CronExprToHuman(tt.expr, tt.lang)
@@ -756,6 +762,54 @@ func TestCronStore_MarkRun(t *testing.T) {
 	}
 }
 
+func TestCronStore_RecordRun(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewCronStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &CronJob{ID: "runs-test", Project: "proj", SessionKey: "test:ch1", CronExpr: "0 6 * * *", Prompt: "hello"}
+	if err := store.Add(job); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs := store.Runs("runs-test"); len(runs) != 0 {
+		t.Fatalf("expected no runs, got %d", len(runs))
+	}
+
+	base := time.Date(2026, 1, 1, 6, 0, 0, 0, time.UTC)
+	for i := 0; i < maxCronRunHistory+5; i++ {
+		store.RecordRun("runs-test", CronRun{
+			StartedAt: base.Add(time.Duration(i) * time.Hour),
+			Output:    fmt.Sprintf("run %d", i),
+		})
+	}
+	store.RecordRun("runs-test", CronRun{StartedAt: base.Add(100 * time.Hour), Output: strings.Repeat("x", maxCronRunOutput+100)})
+
+	// Reload from disk to make sure history is persisted.
+	reloaded, err := NewCronStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := reloaded.Runs("runs-test")
+	if len(runs) != maxCronRunHistory {
+		t.Fatalf("len(runs) = %d, want %d", len(runs), maxCronRunHistory)
+	}
+	if got := len([]rune(runs[0].Output)); got != maxCronRunOutput+3 {
+		t.Errorf("newest output length = %d, want truncated to %d (+ellipsis)", got, maxCronRunOutput)
+	}
+	if runs[1].Output != fmt.Sprintf("run %d", maxCronRunHistory+4) {
+		t.Errorf("runs[1].Output = %q, want newest-first ordering", runs[1].Output)
+	}
+
+	if !reloaded.Remove("runs-test") {
+		t.Fatal("Remove returned false")
+	}
+	if runs := reloaded.Runs("runs-test"); len(runs) != 0 {
+		t.Errorf("expected history removed with job, got %d runs", len(runs))
+	}
+}
+
 func TestCronStore_ListByProject(t *testing.T) {
 	dir := t.TempDir()
 	store, err := NewCronStore(dir)
@@ -839,3 +893,389 @@ func TestCronScheduler_UpdateJob_EnabledNonBoolPreservesSchedule(t *testing.T) {
 		t.Fatalf("stored job state should be unchanged on validation error, got %+v", stored)
 	}
 }
+
+func newShellCronTestScheduler(t *testing.T) (*CronScheduler, *stubCronReplyTargetPlatform) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell cron tests use POSIX sh")
+	}
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronScheduler(store)
+	platform := &stubCronReplyTargetPlatform{stubPlatformEngine: stubPlatformEngine{n: "discord"}}
+	e := NewEngine("proj", &stubAgent{}, []Platform{platform}, "", LangEnglish)
+	t.Cleanup(e.cancel)
+	e.cronScheduler = cs
+	cs.RegisterEngine("proj", e)
+	return cs, platform
+}
+
+func TestCronScheduler_RetriesUntilSuccess(t *testing.T) {
+	cs, _ := newShellCronTestScheduler(t)
+	marker := filepath.Join(t.TempDir(), "attempted")
+	silent := true
+	job := &CronJob{
+		ID:         "retry-ok",
+		Project:    "proj",
+		SessionKey: "discord:channel-1:user-1",
+		CronExpr:   "0 6 * * *",
+		Exec:       fmt.Sprintf("test -f %q || { touch %q; exit 1; }", marker, marker),
+		Silent:     &silent,
+		Retries:    2,
+		RetryDelay: "10ms",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	cs.runJob(job, true)
+
+	runs := cs.Store().Runs(job.ID)
+	if len(runs) != 2 {
+		t.Fatalf("len(runs) = %d, want 2 (one failure, one retry)", len(runs))
+	}
+	if runs[0].Attempt != 2 || runs[0].Error != "" {
+		t.Errorf("latest run = %+v, want successful attempt 2", runs[0])
+	}
+	if runs[1].Attempt != 1 || runs[1].ExitCode == nil || *runs[1].ExitCode != 1 {
+		t.Errorf("first run = %+v, want attempt 1 with exit code 1", runs[1])
+	}
+	if got := cs.Store().Get(job.ID).LastError; got != "" {
+		t.Errorf("LastError = %q, want cleared after successful retry", got)
+	}
+}
+
+func TestCronScheduler_RetriesAfterRestart(t *testing.T) {
+	cs, _ := newShellCronTestScheduler(t)
+	cs.Stop()
+	if err := cs.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cs.Stop)
+	silent := true
+	job := &CronJob{
+		ID:         "retry-restart",
+		Project:    "proj",
+		SessionKey: "discord:channel-1:user-1",
+		CronExpr:   "0 6 * * *",
+		Exec:       "exit 1",
+		Silent:     &silent,
+		Retries:    1,
+		RetryDelay: "10ms",
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	cs.runJob(job, true)
+
+	if runs := cs.Store().Runs(job.ID); len(runs) != 2 {
+		t.Fatalf("len(runs) = %d, want 2: a Stop/Start cycle must not cancel retry delays", len(runs))
+	}
+}
+
+func TestCronScheduler_OnFailureAlertsAfterLastAttempt(t *testing.T) {
+	cs, platform := newShellCronTestScheduler(t)
+	silent := true
+	job := &CronJob{
+		ID:         "retry-fail",
+		Project:    "proj",
+		SessionKey: "discord:channel-1:user-1",
+		CronExpr:   "0 6 * * *",
+		Exec:       "exit 3",
+		Silent:     &silent,
+		Retries:    1,
+		RetryDelay: "10ms",
+		OnFailure:  CronOnFailureSession,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatal(err)
+	}
+	cs.runJob(job, true)
+
+	if runs := cs.Store().Runs(job.ID); len(runs) != 2 {
+		t.Fatalf("len(runs) = %d, want 2", len(runs))
+	}
+	var alerts int
+	for _, msg := range platform.getSent() {
+		if strings.HasPrefix(msg, "🚨") {
+			alerts++
+			if !strings.Contains(msg, "retry-fail") || !strings.Contains(msg, "2 attempt") {
+				t.Errorf("alert = %q, want job id and attempt count", msg)
+			}
+		}
+	}
+	if alerts != 1 {
+		t.Fatalf("alerts = %d, want exactly 1 after the final attempt; sent=%v", alerts, platform.getSent())
+	}
+}
+
+func TestCronScheduler_ThenRunsOnlyAfterSuccess(t *testing.T) {
+	cs, _ := newShellCronTestScheduler(t)
+	silent := true
+	next := &CronJob{ID: "step-2", Project: "proj", SessionKey: "discord:channel-1:user-1", CronExpr: "0 6 * * *", Exec: "echo chained", Silent: &silent}
+	ok := &CronJob{ID: "step-1", Project: "proj", SessionKey: "discord:channel-1:user-1", CronExpr: "0 6 * * *", Exec: "true", Silent: &silent, Enabled: true, Then: "step-2"}
+	bad := &CronJob{ID: "step-1b", Project: "proj", SessionKey: "discord:channel-1:user-1", CronExpr: "0 6 * * *", Exec: "false", Silent: &silent, Enabled: true, Then: "step-2"}
+	for _, j := range []*CronJob{next, ok, bad} {
+		if err := cs.AddJob(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs.runJob(bad, false)
+	if runs := cs.Store().Runs("step-2"); len(runs) != 0 {
+		t.Fatalf("chained job ran after failure: %+v", runs)
+	}
+
+	cs.runJob(ok, false)
+	runs := cs.Store().Runs("step-2")
+	if len(runs) != 1 {
+		t.Fatalf("len(step-2 runs) = %d, want 1", len(runs))
+	}
+	if runs[0].ChainedFrom != "step-1" || !strings.Contains(runs[0].Output, "chained") {
+		t.Errorf("chained run = %+v, want chained_from step-1 with output", runs[0])
+	}
+}
+
+func TestCronScheduler_ValidateChain(t *testing.T) {
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronScheduler(store)
+	a := &CronJob{ID: "a", Project: "p", SessionKey: "s:1", CronExpr: "0 6 * * *", Prompt: "a"}
+	b := &CronJob{ID: "b", Project: "p", SessionKey: "s:1", CronExpr: "0 6 * * *", Prompt: "b", Then: "a"}
+	if err := cs.AddJob(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.AddJob(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cs.UpdateJob("a", "then", "b"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("UpdateJob(a.then=b) error = %v, want cycle error", err)
+	}
+	if err := cs.UpdateJob("a", "then", "missing"); !errors.Is(err, ErrCronJobNotFound) {
+		t.Errorf("UpdateJob(a.then=missing) error = %v, want ErrCronJobNotFound", err)
+	}
+	if err := cs.UpdateJob("a", "retries", float64(maxCronRetries+1)); err == nil {
+		t.Error("expected retries above the limit to be rejected")
+	}
+	if err := cs.UpdateJob("a", "retry_delay", "soon"); err == nil {
+		t.Error("expected invalid retry_delay to be rejected")
+	}
+	if err := cs.UpdateJob("a", "retry_delay", "90s"); err != nil {
+		t.Fatalf("UpdateJob(retry_delay) error = %v", err)
+	}
+	if got := store.Get("a").RetryDelayDuration(); got != 90*time.Second {
+		t.Errorf("RetryDelayDuration = %v, want 90s", got)
+	}
+}
+
+func TestCronScheduler_PrecheckGatesRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("precheck tests use POSIX sh")
+	}
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronScheduler(store)
+	platform := &stubCronReplyTargetPlatform{stubPlatformEngine: stubPlatformEngine{n: "discord"}}
+	agentSession := newResultAgentSession("reviewed")
+	e := NewEngine("proj", &resultAgent{session: agentSession}, []Platform{platform}, "", LangEnglish)
+	defer e.cancel()
+	e.cronScheduler = cs
+	cs.RegisterEngine("proj", e)
+
+	silent := true
+	next := &CronJob{ID: "after", Project: "proj", SessionKey: "discord:channel-1:user-1", CronExpr: "0 6 * * *", Exec: "true", Silent: &silent}
+	skipped := &CronJob{ID: "quiet", Project: "proj", SessionKey: "discord:channel-1:user-1", CronExpr: "0 6 * * *",
+		Prompt: "review", Precheck: "exit 1", Silent: &silent, Enabled: true, Then: "after"}
+	gated := &CronJob{ID: "busy", Project: "proj", SessionKey: "discord:channel-1:user-1", CronExpr: "0 6 * * *",
+		Prompt: "Review these commits:\n{{precheck_output}}", Precheck: "echo abc123 fix bug", Silent: &silent, Enabled: true}
+	for _, j := range []*CronJob{next, skipped, gated} {
+		if err := cs.AddJob(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs.runJob(skipped, false)
+	runs := store.Runs("quiet")
+	if len(runs) != 1 || !runs[0].Skipped || runs[0].Error != "" {
+		t.Fatalf("runs = %+v, want one skipped run without error", runs)
+	}
+	if len(agentSession.sentPrompts) != 0 {
+		t.Fatalf("agent received prompts for a skipped job: %v", agentSession.sentPrompts)
+	}
+	if got := store.Runs("after"); len(got) != 0 {
+		t.Fatalf("chained job ran after a skipped run: %+v", got)
+	}
+
+	cs.runJob(gated, false)
+	if len(agentSession.sentPrompts) != 1 || !strings.Contains(agentSession.sentPrompts[0], "Review these commits:\nabc123 fix bug") {
+		t.Fatalf("agent prompts = %q, want precheck output substituted", agentSession.sentPrompts)
+	}
+}
+
+func TestCronScheduler_TimezoneAndExclusions(t *testing.T) {
+	dir := t.TempDir()
+	ics := filepath.Join(dir, "holidays.ics")
+	tomorrow := time.Now().In(time.UTC).AddDate(0, 0, 1)
+	data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Holiday\r\n" +
+		"DTSTART;VALUE=DATE:" + tomorrow.Format("20060102") + "\r\n" +
+		"DTEND;VALUE=DATE:" + tomorrow.AddDate(0, 0, 2).Format("20060102") + "\r\n" +
+		"END:VEVENT\r\nEND:VCALENDAR\r\n"
+	if err := os.WriteFile(ics, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewCronStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronScheduler(store)
+	job := &CronJob{
+		ID:           "tz1",
+		Project:      "proj",
+		SessionKey:   "discord:ch1:u1",
+		CronExpr:     "0 9 * * *",
+		Prompt:       "hello",
+		Enabled:      true,
+		Timezone:     "UTC",
+		ExcludeDates: []string{tomorrow.AddDate(0, 0, 3).Format("2006-01-02")},
+		ExcludeICS:   ics,
+	}
+	if err := cs.AddJob(job); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+
+	runs := cs.NextRuns("tz1", 3)
+	if len(runs) != 3 {
+		t.Fatalf("NextRuns len = %d, want 3", len(runs))
+	}
+	excluded := map[string]bool{
+		tomorrow.Format("2006-01-02"):                  true,
+		tomorrow.AddDate(0, 0, 1).Format("2006-01-02"): true,
+		tomorrow.AddDate(0, 0, 3).Format("2006-01-02"): true,
+	}
+	for _, r := range runs {
+		if r.Location().String() != "UTC" || r.Hour() != 9 {
+			t.Errorf("run %v: want 09:00 UTC", r)
+		}
+		if excluded[r.Format("2006-01-02")] {
+			t.Errorf("run %v falls on an excluded date", r)
+		}
+	}
+	if !job.IsExcludedDate(tomorrow) {
+		t.Error("ICS holiday should be excluded")
+	}
+	if job.IsExcludedDate(tomorrow.AddDate(0, 0, 2)) {
+		t.Error("all-day DTEND is exclusive and should not be excluded")
+	}
+}
+
+func TestCronJob_ExcludeICSTimedEventsAndReload(t *testing.T) {
+	ics := filepath.Join(t.TempDir(), "holidays.ics")
+	write := func(body string) {
+		t.Helper()
+		data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + body + "END:VEVENT\r\nEND:VCALENDAR\r\n"
+		if err := os.WriteFile(ics, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 20:00 UTC on the 1st is already the 2nd in Tokyo.
+	write("DTSTART:20300501T200000Z\r\n")
+	job := &CronJob{ID: "ics", Timezone: "Asia/Tokyo", ExcludeICS: ics}
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02 15:04", s, job.Location())
+		return d
+	}
+	if job.IsExcludedDate(day("2030-05-01 09:00")) || !job.IsExcludedDate(day("2030-05-02 09:00")) {
+		t.Fatal("UTC event not converted to the job's time zone")
+	}
+
+	write("DTSTART;TZID=America/New_York:20300610T230000\r\n")
+	future := time.Now().Add(time.Hour)
+	_ = os.Chtimes(ics, future, future)
+	if !job.IsExcludedDate(day("2030-06-11 12:00")) {
+		t.Fatal("changed calendar not re-read")
+	}
+}
+
+func TestCronJob_ExcludeICSYearlyRecurrence(t *testing.T) {
+	ics := filepath.Join(t.TempDir(), "holidays.ics")
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20201225\r\nDTEND;VALUE=DATE:20201227\r\nRRULE:FREQ=YEARLY\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20200101\r\nRRULE:FREQ=YEARLY;COUNT=2\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20201126\r\nRRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if err := os.WriteFile(ics, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	job := &CronJob{ID: "yearly", Timezone: "UTC", ExcludeICS: ics}
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", s, time.UTC)
+		return d
+	}
+	next := time.Now().UTC().Year() + 1
+	for _, d := range []string{"2020-12-25", "2023-12-26", fmt.Sprintf("%d-12-25", next), "2021-01-01", "2020-11-26"} {
+		if !job.IsExcludedDate(day(d)) {
+			t.Errorf("%s should be excluded", d)
+		}
+	}
+	for _, d := range []string{"2023-12-27", "2022-01-01", "2021-11-25"} {
+		if job.IsExcludedDate(day(d)) {
+			t.Errorf("%s should not be excluded", d)
+		}
+	}
+}
+
+func TestCronJob_SpecKeepsInlineTimezone(t *testing.T) {
+	job := &CronJob{CronExpr: "CRON_TZ=Asia/Tokyo 0 9 * * *", Timezone: "UTC"}
+	if got := job.Spec(); got != job.CronExpr {
+		t.Errorf("Spec() = %q, want the expression unchanged", got)
+	}
+	if err := validateCronJob(&CronJob{SessionKey: "discord:ch1:u1", CronExpr: job.CronExpr, Prompt: "x", Timezone: "UTC"}); err == nil {
+		t.Error("expected a job with both a CRON_TZ prefix and a timezone to be rejected")
+	}
+}
+
+func TestCronScheduler_AddJob_InvalidTimezoneAndJitter(t *testing.T) {
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronScheduler(store)
+	base := CronJob{Project: "p", SessionKey: "discord:ch1:u1", CronExpr: "0 9 * * *", Prompt: "x", Enabled: true}
+
+	bad := []func(j *CronJob){
+		func(j *CronJob) { j.Timezone = "Mars/Olympus" },
+		func(j *CronJob) { j.Jitter = "soon" },
+		func(j *CronJob) { j.ExcludeDates = []string{"10/01/2026"} },
+	}
+	for i, mutate := range bad {
+		j := base
+		j.ID = fmt.Sprintf("bad%d", i)
+		mutate(&j)
+		if err := cs.AddJob(&j); err == nil {
+			t.Errorf("case %d: expected AddJob to fail", i)
+		}
+	}
+
+	j := base
+	j.ID = "ok"
+	if err := cs.AddJob(&j); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	if err := cs.UpdateJob("ok", "timezone", "Europe/Nowhere"); err == nil {
+		t.Error("expected UpdateJob to reject an unknown timezone")
+	}
+	if err := cs.UpdateJob("ok", "timezone", "Asia/Shanghai"); err != nil {
+		t.Fatalf("UpdateJob timezone: %v", err)
+	}
+	runs := cs.NextRuns("ok", 1)
+	if len(runs) != 1 || runs[0].Location().String() != "Asia/Shanghai" || runs[0].Hour() != 9 {
+		t.Errorf("NextRuns after timezone change = %v, want 09:00 Asia/Shanghai", runs)
+	}
+}
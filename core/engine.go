@@ -17,6 +17,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -329,6 +330,7 @@ type DisplayCfg struct {
 	ToolMessages     bool
 	HistoryMaxLen    *int // max runes for /history entries; nil = default, 0 = no truncation
 	HideAgentFooter  bool // strip model/token footer lines emitted as agent text
+	PagedReplies     bool // send long final replies as navigable pages instead of many messages
 }
 
 // InstantReplyCfg controls the immediate confirmation reply sent when a message
@@ -347,35 +349,45 @@ type RateLimitCfg struct {
 // Engine routes messages between platforms and the agent for a single project.
 type Engine struct {
 	name                  string
-	agent                 Agent
-	platforms             []Platform
+	agent                 Agent // guarded by agentMu; swapped by SwapAgent on config reload
+	agentMu               sync.RWMutex
+	platforms             []Platform // guarded by platformsMu; replaced, never mutated in place
+	platformsMu           sync.RWMutex
 	sessions              *SessionManager
 	ctx                   context.Context
 	cancel                context.CancelFunc
 	i18n                  *I18n
 	speech                SpeechCfg
+	ocr                   OCRCfg
 	tts                   *TTSCfg
 	display               DisplayCfg
+	replyPages            replyPager
+	autoAttach            AutoAttachCfg
+	attachOffers          attachOffers
 	injectSender          bool
 	attachmentSendEnabled bool
 	startedAt             time.Time
 
-	providerSaveFunc        func(providerName string) error
-	providerAddSaveFunc     func(p ProviderConfig) error
-	providerRemoveSaveFunc  func(name string) error
-	providerModelSaveFunc   func(providerName, model string) error
-	providerRefsSaveFunc    func(refs []string) error
+	providerSaveFunc        func(ctx context.Context, providerName string) error
+	providerAddSaveFunc     func(ctx context.Context, p ProviderConfig) error
+	providerRemoveSaveFunc  func(ctx context.Context, name string) error
+	providerModelSaveFunc   func(ctx context.Context, providerName, model string) error
+	providerRefsSaveFunc    func(ctx context.Context, refs []string) error
 	listGlobalProvidersFunc func(agentType string) ([]ProviderConfig, error)
-	modelSaveFunc           func(model string) error
+	modelSaveFunc           func(ctx context.Context, model string) error
 
-	ttsSaveFunc func(mode string) error
+	ttsSaveFunc func(ctx context.Context, mode string) error
 
-	commandSaveAddFunc func(name, description, prompt, exec, workDir string) error
-	commandSaveDelFunc func(name string) error
+	commandSaveAddFunc func(ctx context.Context, name, description, prompt, exec, workDir string) error
+	commandSaveDelFunc func(ctx context.Context, name string) error
 
-	displaySaveFunc  func(mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error
+	displaySaveFunc  func(ctx context.Context, mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error
 	configReloadFunc func() (*ConfigReloadResult, error)
 
+	configHistoryFunc  func() ([]ConfigVersionInfo, error)
+	configDiffFunc     func(from, to int) (string, error)
+	configRollbackFunc func(ctx context.Context, id int) error
+
 	hooks              *HookManager
 	cronScheduler      *CronScheduler
 	timerScheduler     *TimerScheduler
@@ -386,8 +398,8 @@ type Engine struct {
 	aliases  map[string]string // trigger → command (e.g. "帮助" → "/help")
 	aliasMu  sync.RWMutex
 
-	aliasSaveAddFunc func(name, command string) error
-	aliasSaveDelFunc func(name string) error
+	aliasSaveAddFunc func(ctx context.Context, name, command string) error
+	aliasSaveDelFunc func(ctx context.Context, name string) error
 
 	bannedWords []string
 	bannedMu    sync.RWMutex
@@ -404,7 +416,10 @@ type Engine struct {
 	references       ReferenceRenderCfg
 	relayManager     *RelayManager
 	eventIdleTimeout time.Duration
-	maxTurnTime      time.Duration // absolute wall-clock cap per turn (0 = disabled)
+	maxTurnTime      time.Duration                        // absolute wall-clock cap per turn (0 = disabled)
+	providerFailover atomic.Pointer[providerFailover]     // nil = no failover chain configured
+	providerHealth   atomic.Pointer[engineProviderHealth] // nil = health probing disabled
+	providerKeys     providerKeyPools                     // API key pools of providers with several keys
 	// agentSessionIdleTimeoutNanos 在单轮正常结束后关闭空闲的 live agent 进程，
 	// 同时保留已保存的 session ID，便于下次继续恢复。
 	agentSessionIdleTimeoutNanos atomic.Int64
@@ -482,7 +497,7 @@ type Engine struct {
 	pendingRestartTimeout time.Duration
 
 	// /web command callbacks
-	webSetupFunc  func() (port int, token string, needRestart bool, err error)
+	webSetupFunc  func(ctx context.Context) (port int, token string, needRestart bool, err error)
 	webStatusFunc func() (url string)
 
 	// Data directory for socket path injection
@@ -514,6 +529,7 @@ type queuedMessage struct {
 	msgSessionKey     string // session key for extracting chat ID
 	channelKey        string // platform-provided channel identifier (preferred over sessionKey extraction)
 	userMessageTimeMs int64  // Feishu create_time ms (optional); see Message.UserMessageTimeMs
+	retry             bool   // re-sent after a provider failover; already in the session history
 }
 
 // interactiveState tracks a running interactive agent session and its permission state.
@@ -538,8 +554,16 @@ type interactiveState struct {
 	deleteMode               *deleteModeState
 	modelSwitch              *modelSwitchState
 	pendingProviderAdd       *pendingProviderAddState
+	pendingCron              *CronJob // natural-language cron job awaiting /cron confirm
 	lastAutoCompressAt       time.Time
 	lastAutoCompressTokens   int
+	lastTurnInputTokens      int // token usage reported by the last completed turn
+	lastTurnOutputTokens     int
+	provider                 string             // active provider when agentSession was started
+	apiKey                   string             // pool key agentSession was started with ("" = single key)
+	sessionEnv               []string           // session env agentSession was started with, without the key
+	currentTurn              *queuedMessage     // turn in flight, re-queued after a provider failover
+	turnFiles                *workspaceSnapshot // work dir before the current turn (auto attach)
 
 	// Unsolicited event reader: a background goroutine that consumes agent
 	// events between user-initiated turns (e.g. background task completions).
@@ -874,7 +898,7 @@ func (e *Engine) SetTTSConfig(cfg *TTSCfg) {
 }
 
 // SetTTSSaveFunc registers a callback that persists TTS mode changes.
-func (e *Engine) SetTTSSaveFunc(fn func(mode string) error) {
+func (e *Engine) SetTTSSaveFunc(fn func(ctx context.Context, mode string) error) {
 	e.ttsSaveFunc = fn
 }
 
@@ -987,8 +1011,10 @@ func (e *Engine) SetFilterExternalSessions(v bool) {
 	e.filterExternalSessions = v
 }
 
-func (e *Engine) SetWebSetupFunc(fn func() (int, string, bool, error)) { e.webSetupFunc = fn }
-func (e *Engine) SetWebStatusFunc(fn func() string)                    { e.webStatusFunc = fn }
+func (e *Engine) SetWebSetupFunc(fn func(context.Context) (int, string, bool, error)) {
+	e.webSetupFunc = fn
+}
+func (e *Engine) SetWebStatusFunc(fn func() string) { e.webStatusFunc = fn }
 
 func (e *Engine) SetSkipGit(skipGit bool) {
 	e.skipGit = skipGit
@@ -1027,7 +1053,7 @@ func (e *Engine) SetLanguageSaveFunc(fn func(Language) error) {
 // findObserverTarget returns the first platform that implements ObserverTarget,
 // or nil if none do.
 func (e *Engine) findObserverTarget() ObserverTarget {
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if ot, ok := p.(ObserverTarget); ok {
 			return ot
 		}
@@ -1035,23 +1061,23 @@ func (e *Engine) findObserverTarget() ObserverTarget {
 	return nil
 }
 
-func (e *Engine) SetProviderSaveFunc(fn func(providerName string) error) {
+func (e *Engine) SetProviderSaveFunc(fn func(ctx context.Context, providerName string) error) {
 	e.providerSaveFunc = fn
 }
 
-func (e *Engine) SetProviderAddSaveFunc(fn func(ProviderConfig) error) {
+func (e *Engine) SetProviderAddSaveFunc(fn func(context.Context, ProviderConfig) error) {
 	e.providerAddSaveFunc = fn
 }
 
-func (e *Engine) SetProviderRemoveSaveFunc(fn func(string) error) {
+func (e *Engine) SetProviderRemoveSaveFunc(fn func(context.Context, string) error) {
 	e.providerRemoveSaveFunc = fn
 }
 
-func (e *Engine) SetProviderModelSaveFunc(fn func(providerName, model string) error) {
+func (e *Engine) SetProviderModelSaveFunc(fn func(ctx context.Context, providerName, model string) error) {
 	e.providerModelSaveFunc = fn
 }
 
-func (e *Engine) SetProviderRefsSaveFunc(fn func(refs []string) error) {
+func (e *Engine) SetProviderRefsSaveFunc(fn func(ctx context.Context, refs []string) error) {
 	e.providerRefsSaveFunc = fn
 }
 
@@ -1059,7 +1085,7 @@ func (e *Engine) SetListGlobalProvidersFunc(fn func(agentType string) ([]Provide
 	e.listGlobalProvidersFunc = fn
 }
 
-func (e *Engine) SetModelSaveFunc(fn func(model string) error) {
+func (e *Engine) SetModelSaveFunc(fn func(ctx context.Context, model string) error) {
 	e.modelSaveFunc = fn
 }
 
@@ -1067,7 +1093,48 @@ func (e *Engine) SetModelSaveFunc(fn func(model string) error) {
 // The platform is started and wired during the next Engine.Start call,
 // or if the engine is already running, it is started immediately.
 func (e *Engine) AddPlatform(p Platform) {
-	e.platforms = append(e.platforms, p)
+	e.platformsMu.Lock()
+	defer e.platformsMu.Unlock()
+	e.platforms = append(slices.Clip(e.platforms), p)
+}
+
+// platformList returns the engine's current platforms. The slice is shared
+// and must not be modified; reloads replace it instead.
+func (e *Engine) platformList() []Platform {
+	e.platformsMu.RLock()
+	defer e.platformsMu.RUnlock()
+	return e.platforms
+}
+
+// StartPlatform adds a platform to a running engine and starts it. It is used
+// by config reload to bring up new or reconfigured platforms without
+// touching the others.
+func (e *Engine) StartPlatform(p Platform) error {
+	e.AddPlatform(p)
+	_, err := e.startPlatform(p)
+	return err
+}
+
+// StopPlatform stops a platform and removes it from a running engine. Sessions
+// bound to the platform stay in the session store and resume once a platform
+// with the same name is started again.
+func (e *Engine) StopPlatform(p Platform) error {
+	e.platformsMu.Lock()
+	kept := make([]Platform, 0, len(e.platforms))
+	for _, existing := range e.platforms {
+		if existing != p {
+			kept = append(kept, existing)
+		}
+	}
+	e.platforms = kept
+	e.platformsMu.Unlock()
+
+	e.platformLifecycleMu.Lock()
+	delete(e.platformReady, p)
+	e.platformLifecycleMu.Unlock()
+
+	slog.Info("platform stopped", "project", e.name, "platform", p.Name())
+	return p.Stop()
 }
 
 func (e *Engine) SetCronScheduler(cs *CronScheduler) {
@@ -1082,15 +1149,15 @@ func (e *Engine) SetHeartbeatScheduler(hs *HeartbeatScheduler) {
 	e.heartbeatScheduler = hs
 }
 
-func (e *Engine) SetCommandSaveAddFunc(fn func(name, description, prompt, exec, workDir string) error) {
+func (e *Engine) SetCommandSaveAddFunc(fn func(ctx context.Context, name, description, prompt, exec, workDir string) error) {
 	e.commandSaveAddFunc = fn
 }
 
-func (e *Engine) SetCommandSaveDelFunc(fn func(name string) error) {
+func (e *Engine) SetCommandSaveDelFunc(fn func(ctx context.Context, name string) error) {
 	e.commandSaveDelFunc = fn
 }
 
-func (e *Engine) SetDisplaySaveFunc(fn func(mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error) {
+func (e *Engine) SetDisplaySaveFunc(fn func(ctx context.Context, mode *string, thinkingMessages *bool, thinkingMaxLen, toolMaxLen *int, toolMessages *bool) error) {
 	e.displaySaveFunc = fn
 }
 
@@ -1099,17 +1166,128 @@ type ConfigReloadResult struct {
 	DisplayUpdated   bool
 	ProvidersUpdated int
 	CommandsUpdated  int
+
+	// Structural changes applied by a full reload. Platforms are reported
+	// as "project/platform".
+	ProjectsAdded    []string
+	ProjectsRemoved  []string
+	ProjectsRebuilt  []string
+	PlatformsStarted []string
+	PlatformsStopped []string
+	AgentsSwapped    []string
+	// RestartRequired lists changed top-level settings that only take
+	// effect after a restart.
+	RestartRequired []string
+	// Errors lists the parts of the new config that could not be applied;
+	// the affected projects keep running on their previous settings.
+	Errors []string
 }
 
 func (e *Engine) SetConfigReloadFunc(fn func() (*ConfigReloadResult, error)) {
 	e.configReloadFunc = fn
 }
 
+// ConfigVersionInfo is one entry of the config history: a snapshot of a
+// config file taken just before a programmatic write.
+type ConfigVersionInfo struct {
+	ID     int       `json:"id"`
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Reason string    `json:"reason,omitempty"`
+	File   string    `json:"file"`
+}
+
+// ConfigChange says who made a config change and why. Commands and
+// management API requests attach it to the context they pass to the
+// callbacks that persist config, which record it in the config history.
+type ConfigChange struct {
+	Actor  string // e.g. "telegram:42 (alice)" or "api:web"
+	Reason string // e.g. "/provider add relay" or "POST /api/v1/providers"
+}
+
+type configChangeKey struct{}
+
+// WithConfigChange returns a copy of ctx carrying c.
+func WithConfigChange(ctx context.Context, c ConfigChange) context.Context {
+	return context.WithValue(ctx, configChangeKey{}, c)
+}
+
+// ConfigChangeFrom returns the change carried by ctx, if any.
+func ConfigChangeFrom(ctx context.Context) (ConfigChange, bool) {
+	c, ok := ctx.Value(configChangeKey{}).(ConfigChange)
+	return c, ok
+}
+
+// SetConfigHistoryFuncs registers the callbacks behind /config history,
+// /config diff and /config rollback. diff with to == 0 shows what change
+// from did.
+func (e *Engine) SetConfigHistoryFuncs(list func() ([]ConfigVersionInfo, error), diff func(from, to int) (string, error), rollback func(ctx context.Context, id int) error) {
+	e.configHistoryFunc = list
+	e.configDiffFunc = diff
+	e.configRollbackFunc = rollback
+}
+
 // GetAgent returns the engine's agent (for type assertions like ProviderSwitcher).
 func (e *Engine) GetAgent() Agent {
+	e.agentMu.RLock()
+	defer e.agentMu.RUnlock()
 	return e.agent
 }
 
+// SwapAgent replaces the agent used for new sessions and returns the previous
+// one. Sessions that are already running keep the agent that started them,
+// so the caller must not stop the old agent while they may still be in use.
+func (e *Engine) SwapAgent(ag Agent) Agent {
+	e.agentMu.Lock()
+	old := e.agent
+	e.agent = ag
+	e.agentMu.Unlock()
+	slog.Info("engine: agent swapped", "project", e.name, "agent", ag.Name())
+	return old
+}
+
+// retiredAgentPollInterval is how often a retired agent is checked for
+// sessions that still use it.
+var retiredAgentPollInterval = 30 * time.Second
+
+// RetireAgent stops ag, typically the agent returned by SwapAgent, once no
+// interactive session started with it remains, or when the engine stops.
+func (e *Engine) RetireAgent(ag Agent) {
+	if ag == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(retiredAgentPollInterval)
+		defer ticker.Stop()
+		for e.ctx.Err() == nil && e.agentInUse(ag) {
+			select {
+			case <-e.ctx.Done():
+			case <-ticker.C:
+			}
+		}
+		if err := ag.Stop(); err != nil {
+			slog.Warn("engine: stop retired agent", "project", e.name, "agent", ag.Name(), "error", err)
+			return
+		}
+		slog.Info("engine: retired agent stopped", "project", e.name, "agent", ag.Name())
+	}()
+}
+
+// agentInUse reports whether a live interactive session was started by ag.
+func (e *Engine) agentInUse(ag Agent) bool {
+	e.interactiveMu.Lock()
+	defer e.interactiveMu.Unlock()
+	for _, state := range e.interactiveStates {
+		state.mu.Lock()
+		inUse := state.agent == ag && state.agentSession != nil
+		state.mu.Unlock()
+		if inUse {
+			return true
+		}
+	}
+	return false
+}
+
 // GetSessions returns the Engine's session manager (for testing).
 func (e *Engine) GetSessions() *SessionManager {
 	return e.sessions
@@ -1132,11 +1310,11 @@ func (e *Engine) AddAlias(name, command string) {
 	e.aliases[name] = command
 }
 
-func (e *Engine) SetAliasSaveAddFunc(fn func(name, command string) error) {
+func (e *Engine) SetAliasSaveAddFunc(fn func(ctx context.Context, name, command string) error) {
 	e.aliasSaveAddFunc = fn
 }
 
-func (e *Engine) SetAliasSaveDelFunc(fn func(name string) error) {
+func (e *Engine) SetAliasSaveDelFunc(fn func(ctx context.Context, name string) error) {
 	e.aliasSaveDelFunc = fn
 }
 
@@ -1213,6 +1391,76 @@ func (e *Engine) SetAdminFrom(adminFrom string) {
 	}
 }
 
+// configChangingCommands are commands that may write config.toml; their
+// writes are recorded in the config history under the user who ran them.
+// See changesConfig for the forms that only read.
+var configChangingCommands = map[string]bool{
+	"model":    true,
+	"lang":     true,
+	"quiet":    true,
+	"provider": true,
+	"commands": true,
+	"config":   true,
+	"alias":    true,
+	"tts":      true,
+	"web":      true,
+}
+
+// readOnlyConfigSubcommands are the subcommands of configChangingCommands
+// that never write config.toml.
+var readOnlyConfigSubcommands = map[string]map[string]bool{
+	"provider": {"list": true, "current": true},
+	"commands": {"list": true},
+	"config":   {"get": true, "history": true, "diff": true},
+	"alias":    {"list": true},
+	"web":      {"status": true},
+}
+
+// changesConfig reports whether running cmdID with args may write
+// config.toml. Bare commands only show the current setting, except /quiet
+// which cycles the display mode. Abbreviated subcommands are treated as
+// writes.
+func changesConfig(cmdID string, args []string) bool {
+	if !configChangingCommands[cmdID] {
+		return false
+	}
+	if len(args) == 0 {
+		return cmdID == "quiet"
+	}
+	return !readOnlyConfigSubcommands[cmdID][strings.ToLower(args[0])]
+}
+
+// configChangeActor names the sender of msg for the config history.
+func configChangeActor(msg *Message) string {
+	actor := msg.Platform + ":" + msg.UserID
+	if msg.UserName != "" {
+		actor += " (" + msg.UserName + ")"
+	}
+	return actor
+}
+
+// configCtx returns the context the config save callbacks are called with
+// for a change requested by msg.
+func configCtx(msg *Message) context.Context {
+	return WithConfigChange(context.Background(), ConfigChange{Actor: configChangeActor(msg), Reason: msg.configReason})
+}
+
+// cardConfigCtx is configCtx for a change made from a card action, which
+// only identifies the session it was made in.
+func cardConfigCtx(sessionKey, reason string) context.Context {
+	return WithConfigChange(context.Background(), ConfigChange{Actor: sessionKey, Reason: reason})
+}
+
+// configChangeReason describes a command for the config history. Only the
+// first two arguments are kept so that values such as API keys passed to
+// /provider add are not recorded.
+func configChangeReason(cmdID string, args []string) string {
+	if len(args) > 2 {
+		args = args[:2]
+	}
+	return strings.TrimSpace("/" + cmdID + " " + strings.Join(args, " "))
+}
+
 // privilegedCommands are commands that require admin_from authorization.
 var privilegedCommands = map[string]bool{
 	"shell":   true,
@@ -1251,6 +1499,12 @@ func isPrivilegedCommandInvocation(cmdID string, args []string) bool {
 		return matchSubCommand(sub, []string{
 			"list", "add", "addexec", "del", "delete", "rm", "remove",
 		}) == "addexec"
+	case "config":
+		switch matchSubCommand(sub, configSubCommands) {
+		case "history", "diff", "rollback":
+			return true
+		}
+		return false
 	case "cron":
 		return matchSubCommand(sub, []string{
 			"add", "addexec", "list", "del", "delete", "rm", "remove", "enable", "disable", "mute", "unmute", "setup",
@@ -1416,8 +1670,8 @@ func (e *Engine) SkillDirs() []string {
 
 // AgentTypeName returns the agent type name (e.g. "claudecode", "codex").
 func (e *Engine) AgentTypeName() string {
-	if e.agent != nil {
-		return e.agent.Name()
+	if e.GetAgent() != nil {
+		return e.GetAgent().Name()
 	}
 	return ""
 }
@@ -1439,6 +1693,18 @@ func (e *Engine) ActiveSessionKeys() []string {
 // It finds the platform that owns the session key, reconstructs a reply context,
 // and processes the message as if the user sent it.
 func (e *Engine) ExecuteCronJob(job *CronJob) error {
+	_, err := e.ExecuteCronJobResult(job)
+	return err
+}
+
+// ExecuteCronJobResult is ExecuteCronJob but also reports the captured output,
+// exit code (shell jobs) and token usage (agent jobs) for run history.
+func (e *Engine) ExecuteCronJobResult(job *CronJob) (*CronRunResult, error) {
+	precheckOut, err := e.runJobPrecheck(job.Precheck, job.WorkDir)
+	if err != nil {
+		return &CronRunResult{Output: precheckOut}, err
+	}
+
 	e.hooks.Emit(HookEvent{
 		Event:      HookEventCronTriggered,
 		SessionKey: job.SessionKey,
@@ -1453,7 +1719,7 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 	}
 
 	var targetPlatform Platform
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -1463,7 +1729,7 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 	// with the workspace path (e.g. "/home/user/project:slack:C123:U456").
 	// Search for a known platform name within the key and strip the prefix.
 	if targetPlatform == nil {
-		for _, p := range e.platforms {
+		for _, p := range e.platformList() {
 			needle := ":" + p.Name() + ":"
 			if idx := strings.Index(sessionKey, needle); idx >= 0 {
 				targetPlatform = p
@@ -1474,23 +1740,22 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 		}
 	}
 	if targetPlatform == nil {
-		return fmt.Errorf("platform %q not found for session %q", platformName, sessionKey)
+		return nil, fmt.Errorf("platform %q not found for session %q", platformName, sessionKey)
 	}
 
 	rc, ok := targetPlatform.(ReplyContextReconstructor)
 	if !ok {
-		return fmt.Errorf("platform %q does not support proactive messaging (cron)", platformName)
+		return nil, fmt.Errorf("platform %q does not support proactive messaging (cron)", platformName)
 	}
 
 	runSessionKey := sessionKey
 	var replyCtx any
-	var err error
 	if !job.Mute {
 		if resolver, ok := targetPlatform.(CronReplyTargetResolver); ok {
 			resolvedSessionKey, resolvedReplyCtx, err := resolver.ResolveCronReplyTarget(sessionKey, cronRunTitle(job))
 			if err != nil {
 				if !errors.Is(err, ErrNotSupported) {
-					return fmt.Errorf("resolve cron reply target: %w", err)
+					return nil, fmt.Errorf("resolve cron reply target: %w", err)
 				}
 			} else {
 				if resolvedSessionKey != "" {
@@ -1505,7 +1770,7 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 	if replyCtx == nil {
 		replyCtx, err = rc.ReconstructReplyCtx(runSessionKey)
 		if err != nil {
-			return fmt.Errorf("reconstruct reply context: %w", err)
+			return nil, fmt.Errorf("reconstruct reply context: %w", err)
 		}
 	}
 
@@ -1537,10 +1802,14 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 	}
 
 	if job.IsShellJob() {
-		return e.executeCronShell(effectivePlatform, replyCtx, job)
+		res := &CronRunResult{}
+		return res, e.executeCronShell(effectivePlatform, replyCtx, job, res)
 	}
 
 	content := job.Prompt
+	if job.Precheck != "" {
+		content = ExpandPromptVars(content, nil, map[string]string{"precheck_output": precheckOut})
+	}
 	if strings.HasPrefix(content, "/") {
 		parts := strings.Fields(content)
 		if len(parts) > 0 {
@@ -1563,7 +1832,7 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 
 	// Resolve workspace-specific agent and sessions for multi-workspace mode.
 	// Priority: job.WorkDir (explicit) > workspace binding > global agent fallback.
-	agent := e.agent
+	agent := e.GetAgent()
 	sessions := e.sessions
 	workspaceDir := ""
 
@@ -1605,7 +1874,7 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 		msg.SessionKey = runSessionKey
 		session := sessions.NewSideSession(runSessionKey, "cron-"+job.ID)
 		if !session.TryLock() {
-			return fmt.Errorf("session %q is busy", runSessionKey)
+			return nil, fmt.Errorf("session %q is busy", runSessionKey)
 		}
 		iKey := fmt.Sprintf("%s#cron:%s", runSessionKey, session.ID)
 		if workspaceDir != "" {
@@ -1613,6 +1882,7 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 		}
 		prevHistLen := session.HistoryLen()
 		e.processInteractiveMessageWith(effectivePlatform, msg, session, agent, sessions, iKey, workspaceDir, runSessionKey)
+		res := e.cronTurnResult(iKey, session, prevHistLen)
 		e.cleanupInteractiveState(iKey)
 		// Empty-response detection via session history delta: processInteractiveMessageWith
 		// always adds a "user" entry (prevHistLen+1), then an "assistant" entry on success
@@ -1620,14 +1890,14 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 		// delivery modes (plain text, cards, rich cards, DingTalk AI streaming) because
 		// AddHistory("assistant",...) is called before any platform-specific rendering path.
 		if !job.Mute && session.HistoryLen() < prevHistLen+2 {
-			return fmt.Errorf("cron job %q produced an empty response", job.ID)
+			return res, fmt.Errorf("cron job %q produced an empty response", job.ID)
 		}
-		return nil
+		return res, nil
 	}
 
 	session := sessions.GetOrCreateActive(sessionKey)
 	if !session.TryLock() {
-		return fmt.Errorf("session %q is busy", sessionKey)
+		return nil, fmt.Errorf("session %q is busy", sessionKey)
 	}
 
 	iKey := sessionKey
@@ -1636,17 +1906,47 @@ func (e *Engine) ExecuteCronJob(job *CronJob) error {
 	}
 	prevHistLen := session.HistoryLen()
 	e.processInteractiveMessageWith(effectivePlatform, msg, session, agent, sessions, iKey, workspaceDir, sessionKey)
+	res := e.cronTurnResult(iKey, session, prevHistLen)
 	// Same empty-response detection as the useNewSession path above.
 	if !job.Mute && session.HistoryLen() < prevHistLen+2 {
-		return fmt.Errorf("cron job %q produced an empty response", job.ID)
+		return res, fmt.Errorf("cron job %q produced an empty response", job.ID)
 	}
-	return nil
+	return res, nil
+}
+
+// cronTurnResult collects the final assistant reply and token usage of the
+// turn that just ran under iKey. History entries added after prevHistLen
+// belong to this turn.
+func (e *Engine) cronTurnResult(iKey string, session *Session, prevHistLen int) *CronRunResult {
+	res := &CronRunResult{}
+	if n := session.HistoryLen() - prevHistLen; n > 0 {
+		for _, h := range session.GetHistory(n) {
+			if h.Role == "assistant" {
+				res.Output = h.Content
+			}
+		}
+	}
+	e.interactiveMu.Lock()
+	state := e.interactiveStates[iKey]
+	e.interactiveMu.Unlock()
+	if state != nil {
+		state.mu.Lock()
+		res.InputTokens = state.lastTurnInputTokens
+		res.OutputTokens = state.lastTurnOutputTokens
+		state.mu.Unlock()
+	}
+	return res
 }
 
 // ExecuteTimerJob fires a one-shot timer job: resolves the platform, sends a
 // notification (unless muted), and either runs a shell command or injects a
 // synthetic message into the agent session.
 func (e *Engine) ExecuteTimerJob(job *TimerJob) error {
+	precheckOut, err := e.runJobPrecheck(job.Precheck, job.WorkDir)
+	if err != nil {
+		return err
+	}
+
 	e.hooks.Emit(HookEvent{
 		Event:      HookEventTimerTriggered,
 		SessionKey: job.SessionKey,
@@ -1661,7 +1961,7 @@ func (e *Engine) ExecuteTimerJob(job *TimerJob) error {
 	}
 
 	var targetPlatform Platform
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -1669,7 +1969,7 @@ func (e *Engine) ExecuteTimerJob(job *TimerJob) error {
 	}
 	// Multi-workspace fallback: strip workspace prefix from session key.
 	if targetPlatform == nil {
-		for _, p := range e.platforms {
+		for _, p := range e.platformList() {
 			needle := ":" + p.Name() + ":"
 			if idx := strings.Index(sessionKey, needle); idx >= 0 {
 				targetPlatform = p
@@ -1690,7 +1990,6 @@ func (e *Engine) ExecuteTimerJob(job *TimerJob) error {
 
 	runSessionKey := sessionKey
 	var replyCtx any
-	var err error
 	if !job.Mute {
 		if resolver, ok := targetPlatform.(CronReplyTargetResolver); ok {
 			resolvedSessionKey, resolvedReplyCtx, err := resolver.ResolveCronReplyTarget(sessionKey, timerRunTitle(job))
@@ -1744,6 +2043,9 @@ func (e *Engine) ExecuteTimerJob(job *TimerJob) error {
 	}
 
 	content := job.Prompt
+	if job.Precheck != "" {
+		content = ExpandPromptVars(content, nil, map[string]string{"precheck_output": precheckOut})
+	}
 	if strings.HasPrefix(content, "/") {
 		parts := strings.Fields(content)
 		if len(parts) > 0 {
@@ -1764,7 +2066,7 @@ func (e *Engine) ExecuteTimerJob(job *TimerJob) error {
 		ModeOverride: job.Mode,
 	}
 
-	agent := e.agent
+	agent := e.GetAgent()
 	sessions := e.sessions
 	workspaceDir := ""
 
@@ -1847,7 +2149,7 @@ func timerRunTitle(job *TimerJob) string {
 func (e *Engine) executeTimerShell(p Platform, replyCtx any, job *TimerJob) error {
 	workDir := job.WorkDir
 	if workDir == "" {
-		if wd, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+		if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 			workDir = wd.GetWorkDir()
 		}
 	}
@@ -2002,6 +2304,22 @@ func (e *Engine) executeTimerShell(p Platform, replyCtx any, job *TimerJob) erro
 	}
 }
 
+// notifyCronFailure alerts the job's on_failure target after its final
+// failed attempt. Muted jobs still alert: on_failure is an explicit opt-in.
+func (e *Engine) notifyCronFailure(job *CronJob, attempts int, err error) {
+	target := strings.TrimSpace(job.OnFailure)
+	if target == "" || err == nil {
+		return
+	}
+	if target == CronOnFailureSession {
+		target = job.SessionKey
+	}
+	msg := e.i18n.Tf(MsgCronFailureAlert, cronRunTitle(job), job.ID, attempts, truncateStr(err.Error(), 300))
+	if sendErr := e.SendToSession(target, msg); sendErr != nil {
+		slog.Warn("cron: failed to send failure alert", "id", job.ID, "target", target, "error", sendErr)
+	}
+}
+
 func cronRunTitle(job *CronJob) string {
 	if job == nil {
 		return "cron"
@@ -2021,11 +2339,64 @@ func cronRunTitle(job *CronJob) string {
 	return "cron"
 }
 
+// ErrPrecheckSkipped is returned when a cron or timer job's precheck command
+// exits non-zero: the run was intentionally skipped, not failed.
+var ErrPrecheckSkipped = errors.New("precheck did not pass; run skipped")
+
+const (
+	jobPrecheckTimeout   = 5 * time.Minute
+	maxJobPrecheckOutput = 8000
+)
+
+// runJobPrecheck runs a cron/timer precheck command in workDir (falling back
+// to the agent work dir). It returns the trimmed stdout when the command exits
+// 0 and ErrPrecheckSkipped when it exits non-zero. An empty command passes.
+func (e *Engine) runJobPrecheck(command, workDir string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", nil
+	}
+	if workDir == "" {
+		if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
+			workDir = wd.GetWorkDir()
+		}
+	}
+	if workDir == "" {
+		workDir, _ = os.Getwd()
+	}
+
+	ctx, cancel := context.WithTimeout(e.ctx, jobPrecheckTimeout)
+	defer cancel()
+
+	cmd := shellExecCommand(ctx, e.shell, e.shellFlag, e.shellProfile, command)
+	cmd.Dir = workDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+	out := truncateStr(strings.TrimRight(stdout.String(), "\r\n"), maxJobPrecheckOutput)
+
+	if ctx.Err() != nil {
+		return out, fmt.Errorf("precheck timed out after %v", jobPrecheckTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		slog.Info("precheck: non-zero exit, skipping run",
+			"command", truncateStr(command, 60), "exit_code", exitErr.ExitCode(),
+			"stderr", truncateStr(strings.TrimSpace(stderr.String()), 200))
+		return out, fmt.Errorf("%w (exit code %d)", ErrPrecheckSkipped, exitErr.ExitCode())
+	}
+	if runErr != nil {
+		return out, fmt.Errorf("precheck: %w", runErr)
+	}
+	return out, nil
+}
+
 // executeCronShell runs a shell command for a cron job and sends the output.
-func (e *Engine) executeCronShell(p Platform, replyCtx any, job *CronJob) error {
+// When res is non-nil it receives the captured output and exit code.
+func (e *Engine) executeCronShell(p Platform, replyCtx any, job *CronJob, res *CronRunResult) error {
 	workDir := job.WorkDir
 	if workDir == "" {
-		if wd, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+		if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 			workDir = wd.GetWorkDir()
 		}
 	}
@@ -2063,6 +2434,17 @@ func (e *Engine) executeCronShell(p Platform, replyCtx any, job *CronJob) error
 	var mu sync.Mutex
 	var buf bytes.Buffer
 	doneCh := make(chan struct{})
+	if res != nil {
+		defer func() {
+			mu.Lock()
+			res.Output = buf.String()
+			mu.Unlock()
+			if shellCmd.ProcessState != nil {
+				code := shellCmd.ProcessState.ExitCode()
+				res.ExitCode = &code
+			}
+		}()
+	}
 
 	readPipe := func(r io.Reader) {
 		scanner := bufio.NewScanner(r)
@@ -2232,7 +2614,7 @@ func (e *Engine) ExecuteHeartbeat(sessionKey, prompt string, silent bool) error
 	}
 
 	var targetPlatform Platform
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -2242,7 +2624,7 @@ func (e *Engine) ExecuteHeartbeat(sessionKey, prompt string, silent bool) error
 	// with the workspace path (e.g. "/home/user/project:slack:C123:U456").
 	// Search for a known platform name within the key and strip the prefix.
 	if targetPlatform == nil {
-		for _, p := range e.platforms {
+		for _, p := range e.platformList() {
 			needle := ":" + p.Name() + ":"
 			if idx := strings.Index(sessionKey, needle); idx >= 0 {
 				targetPlatform = p
@@ -2292,39 +2674,32 @@ func (e *Engine) Start() error {
 	var startErrs []error
 	readyCount := 0
 	pendingCount := 0
-	for _, p := range e.platforms {
-		_, isAsync := p.(AsyncRecoverablePlatform)
-		if async, ok := p.(AsyncRecoverablePlatform); ok {
-			async.SetLifecycleHandler(e)
-		}
-		if err := p.Start(e.handleMessage); err != nil {
-			slog.Warn("platform start failed", "project", e.name, "platform", p.Name(), "error", err)
-			startErrs = append(startErrs, fmt.Errorf("[%s] start platform %s: %w", e.name, p.Name(), err))
-			continue
-		}
-		if isAsync {
+	for _, p := range e.platformList() {
+		ready, err := e.startPlatform(p)
+		switch {
+		case err != nil:
+			startErrs = append(startErrs, err)
+		case ready:
+			readyCount++
+		default:
 			pendingCount++
-			slog.Info("platform recovery loop started", "project", e.name, "platform", p.Name())
-			continue
 		}
-		e.onPlatformReady(p)
-		readyCount++
 	}
 
 	// Log summary
 	if len(startErrs) > 0 || pendingCount > 0 {
 		slog.Warn("engine started with partial readiness",
 			"project", e.name,
-			"agent", e.agent.Name(),
+			"agent", e.GetAgent().Name(),
 			"ready", readyCount,
 			"pending", pendingCount,
 			"failed", len(startErrs))
 	} else {
-		slog.Info("engine started", "project", e.name, "agent", e.agent.Name(), "platforms", len(e.platforms))
+		slog.Info("engine started", "project", e.name, "agent", e.GetAgent().Name(), "platforms", len(e.platformList()))
 	}
 
 	// Only return error if ALL platforms failed
-	if len(startErrs) == len(e.platforms) && len(e.platforms) > 0 {
+	if len(startErrs) == len(e.platformList()) && len(e.platformList()) > 0 {
 		return startErrs[0] // Return first error
 	}
 
@@ -2332,6 +2707,25 @@ func (e *Engine) Start() error {
 	return nil
 }
 
+// startPlatform starts one platform. It reports ready=false for async
+// platforms, which become ready through their lifecycle callbacks.
+func (e *Engine) startPlatform(p Platform) (ready bool, err error) {
+	_, isAsync := p.(AsyncRecoverablePlatform)
+	if async, ok := p.(AsyncRecoverablePlatform); ok {
+		async.SetLifecycleHandler(e)
+	}
+	if err := p.Start(e.handleMessage); err != nil {
+		slog.Warn("platform start failed", "project", e.name, "platform", p.Name(), "error", err)
+		return false, fmt.Errorf("[%s] start platform %s: %w", e.name, p.Name(), err)
+	}
+	if isAsync {
+		slog.Info("platform recovery loop started", "project", e.name, "platform", p.Name())
+		return false, nil
+	}
+	e.onPlatformReady(p)
+	return true, nil
+}
+
 func (e *Engine) Stop() error {
 	e.platformLifecycleMu.Lock()
 	e.stopping = true
@@ -2346,7 +2740,7 @@ func (e *Engine) Stop() error {
 
 	// Stop platforms after cancellation so they can unwind against the closed context.
 	var errs []error
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if err := p.Stop(); err != nil {
 			errs = append(errs, fmt.Errorf("stop platform %s: %w", p.Name(), err))
 		}
@@ -2376,8 +2770,8 @@ func (e *Engine) Stop() error {
 	}
 	e.userRolesMu.Unlock()
 
-	if err := e.agent.Stop(); err != nil {
-		errs = append(errs, fmt.Errorf("stop agent %s: %w", e.agent.Name(), err))
+	if err := e.GetAgent().Stop(); err != nil {
+		errs = append(errs, fmt.Errorf("stop agent %s: %w", e.GetAgent().Name(), err))
 	}
 	if len(errs) > 0 {
 		return fmt.Errorf("engine stop errors: %v", errs)
@@ -2948,7 +3342,7 @@ func (e *Engine) handleMessage(p Platform, msg *Message) {
 
 	// Select session manager and agent based on workspace mode
 	sessions := e.sessions
-	agent := e.agent
+	agent := e.GetAgent()
 	interactiveKey := msg.SessionKey
 	if resolvedWorkspace != "" && wsSessions != nil {
 		sessions = wsSessions
@@ -3009,6 +3403,8 @@ func (e *Engine) handleMessage(p Platform, msg *Message) {
 		return
 	}
 
+	e.recognizeImageText(agent, msg)
+
 	session := sessions.GetOrCreateActive(msg.SessionKey)
 	sessions.UpdateUserMeta(msg.SessionKey, msg.UserName, msg.ChatName)
 	// Ensure an interactiveState entry exists before taking the session lock.
@@ -3279,7 +3675,7 @@ func (e *Engine) handleVoiceMessage(p Platform, msg *Message) {
 	}
 
 	audio := msg.Audio
-	if NeedsConversion(audio.Format) && !HasFFmpeg() {
+	if audioNeedsFFmpeg(e.speech.STT, audio) && !HasFFmpeg() {
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgVoiceNoFFmpeg))
 		return
 	}
@@ -3290,14 +3686,17 @@ func (e *Engine) handleVoiceMessage(p Platform, msg *Message) {
 	)
 	e.send(p, msg.ReplyCtx, e.i18n.T(MsgVoiceTranscribing))
 
-	text, err := TranscribeAudio(e.ctx, e.speech.STT, audio, e.speech.Language)
+	result, err := TranscribeAudioChunked(e.ctx, e.speech.STT, audio, e.speech.Language, e.speech.Chunk, e.voiceProgressReporter(p, msg.ReplyCtx))
 	if err != nil {
 		slog.Error("speech transcription failed", "error", err)
 		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgVoiceTranscribeFailed), err))
 		return
 	}
+	if result.Failed > 0 {
+		e.send(p, msg.ReplyCtx, e.i18n.Tf(MsgVoiceTranscribePartial, result.Failed, result.Chunks))
+	}
 
-	text = strings.TrimSpace(text)
+	text := strings.TrimSpace(result.Text)
 	if text == "" {
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgVoiceEmpty))
 		return
@@ -3313,6 +3712,31 @@ func (e *Engine) handleVoiceMessage(p Platform, msg *Message) {
 	e.handleMessage(p, msg)
 }
 
+// voiceProgressReporter returns a progress callback for chunked voice
+// transcription. Platforms that can edit messages get one message updated
+// in place; others get a single notice when transcription starts.
+func (e *Engine) voiceProgressReporter(p Platform, replyCtx any) func(done, total int) {
+	var handle any
+	return func(done, total int) {
+		text := e.i18n.Tf(MsgVoiceTranscribeProgress, done, total)
+		if done == 0 {
+			if starter, ok := p.(PreviewStarter); ok {
+				if _, ok := p.(MessageUpdater); ok {
+					if h, err := starter.SendPreviewStart(e.ctx, replyCtx, text); err == nil && h != nil {
+						handle = h
+						return
+					}
+				}
+			}
+			e.send(p, replyCtx, text)
+			return
+		}
+		if handle != nil {
+			_ = updaterFor(p).UpdateMessage(e.ctx, handle, text)
+		}
+	}
+}
+
 // ──────────────────────────────────────────────────────────────
 // Permission handling
 // ──────────────────────────────────────────────────────────────
@@ -3682,7 +4106,7 @@ func isDenyResponse(s string) bool {
 // ──────────────────────────────────────────────────────────────
 
 func (e *Engine) processInteractiveMessage(p Platform, msg *Message, session *Session) {
-	e.processInteractiveMessageWith(p, msg, session, e.agent, e.sessions, msg.SessionKey, "", "")
+	e.processInteractiveMessageWith(p, msg, session, e.GetAgent(), e.sessions, msg.SessionKey, "", "")
 }
 
 // processInteractiveMessageWith is the core interactive processing loop.
@@ -3716,7 +4140,7 @@ func (e *Engine) processInteractiveMessageWith(p Platform, msg *Message, session
 
 	// Use the agent override when available (multi-workspace mode)
 	var agentOverride Agent
-	if agent != e.agent {
+	if agent != e.GetAgent() {
 		agentOverride = agent
 	}
 	state := e.getOrCreateInteractiveStateWith(interactiveKey, p, msg.ReplyCtx, session, sessions, agentOverride, ccSessionKey)
@@ -3762,7 +4186,7 @@ func (e *Engine) processInteractiveMessageWith(p Platform, msg *Message, session
 			if switcher.SetLiveMode(msg.ModeOverride) {
 				defer func() {
 					defaultMode := "default"
-					if ma, ok := e.agent.(interface{ GetMode() string }); ok {
+					if ma, ok := e.GetAgent().(interface{ GetMode() string }); ok {
 						if m := ma.GetMode(); m != "" {
 							defaultMode = m
 						}
@@ -3806,8 +4230,24 @@ func (e *Engine) processInteractiveMessageWith(p Platform, msg *Message, session
 	state.currentMessageID = msg.MessageID
 	state.fromVoice = msg.FromVoice
 	state.sideText = ""
+	state.currentTurn = &queuedMessage{
+		messageID:         msg.MessageID,
+		platform:          p,
+		replyCtx:          msg.ReplyCtx,
+		content:           msg.Content,
+		images:            msg.Images,
+		files:             msg.Files,
+		fromVoice:         msg.FromVoice,
+		userID:            msg.UserID,
+		userName:          msg.UserName,
+		msgPlatform:       msg.Platform,
+		msgSessionKey:     msg.SessionKey,
+		channelKey:        msg.ChannelKey,
+		userMessageTimeMs: msg.UserMessageTimeMs,
+	}
 	as := state.agentSession // capture under lock to avoid race with cleanup
 	state.mu.Unlock()
+	e.beginTurnFiles(state)
 
 	// Run Send concurrently with processInteractiveEvents. Some agents block inside
 	// Send until the prompt turn finishes (e.g. ACP session/prompt); they may emit
@@ -3874,7 +4314,7 @@ func (e *Engine) getOrCreateWorkspaceAgent(workspace string) (Agent, *SessionMan
 	// Create a new agent instance with this workspace's work_dir
 	opts := make(map[string]any)
 	// Let the agent seed its own base options (e.g. tmux session name)
-	if snapshotter, ok := e.agent.(WorkspaceAgentOptionSnapshotter); ok {
+	if snapshotter, ok := e.GetAgent().(WorkspaceAgentOptionSnapshotter); ok {
 		for k, v := range snapshotter.WorkspaceAgentOptions() {
 			opts[k] = v
 		}
@@ -3891,7 +4331,7 @@ func (e *Engine) getOrCreateWorkspaceAgent(workspace string) (Agent, *SessionMan
 
 	// Copy model from original agent if possible
 	if _, ok := opts["model"]; !ok {
-		if ma, ok := e.agent.(interface{ GetModel() string }); ok {
+		if ma, ok := e.GetAgent().(interface{ GetModel() string }); ok {
 			if m := ma.GetModel(); m != "" {
 				opts["model"] = m
 			}
@@ -3899,7 +4339,7 @@ func (e *Engine) getOrCreateWorkspaceAgent(workspace string) (Agent, *SessionMan
 	}
 	// Copy permission mode
 	if _, ok := opts["mode"]; !ok {
-		if ma, ok := e.agent.(interface{ GetMode() string }); ok {
+		if ma, ok := e.GetAgent().(interface{ GetMode() string }); ok {
 			if m := ma.GetMode(); m != "" {
 				opts["mode"] = m
 			}
@@ -3917,20 +4357,20 @@ func (e *Engine) getOrCreateWorkspaceAgent(workspace string) (Agent, *SessionMan
 		}
 	}
 	if _, ok := opts["run_as_env"]; !ok {
-		if ma, ok := e.agent.(interface{ GetRunAsEnv() []string }); ok {
+		if ma, ok := e.GetAgent().(interface{ GetRunAsEnv() []string }); ok {
 			if env := ma.GetRunAsEnv(); len(env) > 0 {
 				opts["run_as_env"] = env
 			}
 		}
 	}
 
-	agent, err := CreateAgent(e.agent.Name(), opts)
+	agent, err := CreateAgent(e.GetAgent().Name(), opts)
 	if err != nil {
 		return nil, nil, fmt.Errorf("create workspace agent for %s: %w", workspace, err)
 	}
 
 	// Wire providers if original agent has them
-	if ps, ok := e.agent.(ProviderSwitcher); ok {
+	if ps, ok := e.GetAgent().(ProviderSwitcher); ok {
 		if ps2, ok2 := agent.(ProviderSwitcher); ok2 {
 			ps2.SetProviders(ps.ListProviders())
 			if active := ps.GetActiveProvider(); active != nil && active.Name != "" {
@@ -3992,12 +4432,19 @@ func adoptPendingFromPlaceholder(existing, newState *interactiveState) {
 	existing.mu.Unlock()
 }
 
-// When agentOverride is non-nil it is used instead of e.agent to start the session.
+// When agentOverride is non-nil it is used instead of e.GetAgent() to start the session.
 // ccSessionKey, when non-empty, is used for CC_SESSION_KEY env injection; otherwise sessionKey is used.
 func (e *Engine) getOrCreateInteractiveStateWith(sessionKey string, p Platform, replyCtx any, session *Session, sessions *SessionManager, agentOverride Agent, ccSessionKey string) *interactiveState {
 	e.interactiveMu.Lock()
 	defer e.interactiveMu.Unlock()
 
+	// Select the agent to use for this session
+	agent := e.GetAgent()
+	if agentOverride != nil {
+		agent = agentOverride
+	}
+	e.applyProviderFailover(agent)
+
 	state, ok := e.interactiveStates[sessionKey]
 	if ok && state.agentSession != nil && state.agentSession.Alive() {
 		// Verify the running agent session matches the current active session.
@@ -4012,7 +4459,19 @@ func (e *Engine) getOrCreateInteractiveStateWith(sessionKey string, p Platform,
 		// If wantID is empty (/new, cleared session) but the process already has
 		// a concrete ID, reusing would keep --resume context — recycle (#238).
 		needRecycle := currentID != "" && (wantID == "" || wantID != currentID)
-		if !needRecycle {
+		if !needRecycle && ((e.providerFailover.Load() != nil && providerChangedSinceStart(state, agent)) || e.providerKeyCooling(state)) {
+			// The provider failover chain switched providers (or switched
+			// back after the cooldown), or the session's API key hit a rate
+			// limit; restart the process on the new provider or key,
+			// resuming the same agent session.
+			slog.Info("active provider or key changed, restarting agent session", "session_key", sessionKey)
+			e.stopUnsolicitedReader(state)
+			state.markStopped()
+			e.closeAgentSessionWithTimeout(sessionKey, state.agentSession)
+			state.mu.Lock()
+			state.agentSession = nil
+			state.mu.Unlock()
+		} else if !needRecycle {
 			return state
 		}
 		// Tear down the stale agent so we start one that matches the Session below.
@@ -4030,35 +4489,28 @@ func (e *Engine) getOrCreateInteractiveStateWith(sessionKey string, p Platform,
 		ok = false // prevent reading stale settings below
 	}
 
-	// Select the agent to use for this session
-	agent := e.agent
-	if agentOverride != nil {
-		agent = agentOverride
-	}
-
 	ccKey := sessionKey
 	if ccSessionKey != "" {
 		ccKey = ccSessionKey
 	}
 
-	// Inject per-session env vars so the agent subprocess can call `cc-connect cron add` etc.
-	if inj, ok := agent.(SessionEnvInjector); ok {
-		envVars := []string{
-			"CC_PROJECT=" + e.name,
-			"CC_SESSION_KEY=" + ccKey,
-		}
-		if e.dataDir != "" {
-			envVars = append(envVars, "CC_DATA_DIR="+e.dataDir)
-		}
-		if exePath, err := os.Executable(); err == nil {
-			binDir := filepath.Dir(exePath)
-			if curPath := os.Getenv("PATH"); curPath != "" {
-				envVars = append(envVars, "PATH="+binDir+string(filepath.ListSeparator)+curPath)
-			} else {
-				envVars = append(envVars, "PATH="+binDir)
-			}
+	// Per-session env vars so the agent subprocess can call `cc-connect cron add` etc.
+	// They are injected right before StartSession, together with the rotated
+	// provider key.
+	sessionEnv := []string{
+		"CC_PROJECT=" + e.name,
+		"CC_SESSION_KEY=" + ccKey,
+	}
+	if e.dataDir != "" {
+		sessionEnv = append(sessionEnv, "CC_DATA_DIR="+e.dataDir)
+	}
+	if exePath, err := os.Executable(); err == nil {
+		binDir := filepath.Dir(exePath)
+		if curPath := os.Getenv("PATH"); curPath != "" {
+			sessionEnv = append(sessionEnv, "PATH="+binDir+string(filepath.ListSeparator)+curPath)
+		} else {
+			sessionEnv = append(sessionEnv, "PATH="+binDir)
 		}
-		inj.SetSessionEnv(envVars)
 	}
 
 	// Inject platform-specific formatting instructions into the agent's system prompt.
@@ -4089,7 +4541,11 @@ func (e *Engine) getOrCreateInteractiveStateWith(sessionKey string, p Platform,
 	// agent_session_id, producing "model X does not exist" errors when
 	// the model name is sent to the wrong base_url
 	// (cc-connect internal task t-20260614-qp7xnl).
-	restoreActiveProviderFromSession(agent, session)
+	// While failed over, the provider saved in the session is the one that
+	// failed, so it must not override the failover.
+	if !e.providerFailoverActive(agent) {
+		restoreActiveProviderFromSession(agent, session)
+	}
 
 	// Resume only when we have a concrete saved agent session ID. If the session
 	// is unbound, force a fresh start instead of attaching to whichever CLI
@@ -4110,6 +4566,12 @@ func (e *Engine) getOrCreateInteractiveStateWith(sessionKey string, p Platform,
 		}
 	}
 	isResume := startSessionID != ""
+	var apiKey string
+	if inj, ok := agent.(SessionEnvInjector); ok {
+		var keyEnv []string
+		apiKey, keyEnv = e.rotateProviderKey(agent)
+		inj.SetSessionEnv(append(slices.Clone(sessionEnv), keyEnv...))
+	}
 	startAt := time.Now()
 	agentSession, err := agent.StartSession(e.ctx, startSessionID)
 	startElapsed := time.Since(startAt)
@@ -4183,6 +4645,11 @@ func (e *Engine) getOrCreateInteractiveStateWith(sessionKey string, p Platform,
 		agent:            agent,
 		eventsNeedResync: true,
 	}
+	if ps, ok := agent.(ProviderSwitcher); ok {
+		newState.provider = activeProviderName(ps)
+		newState.apiKey = apiKey
+		newState.sessionEnv = sessionEnv
+	}
 	adoptPendingFromPlaceholder(e.interactiveStates[sessionKey], newState)
 	state = newState
 	e.interactiveStates[sessionKey] = state
@@ -4748,11 +5215,14 @@ var agentErrorHandlers = []agentErrorHandler{
 }
 
 func (e *Engine) processInteractiveEvents(state *interactiveState, session *Session, sessions *SessionManager, sessionKey string, msgID string, turnStart time.Time, stopTypingFn func(), sendDone <-chan error, replyCtx any) {
+	state.mu.Lock()
 	if msgID != "" {
-		state.mu.Lock()
 		state.currentMessageID = msgID
-		state.mu.Unlock()
 	}
+	// Token usage is per turn; a turn that ends without a result event must
+	// not report the previous turn's counts.
+	state.lastTurnInputTokens, state.lastTurnOutputTokens = 0, 0
+	state.mu.Unlock()
 
 	var textParts []string
 	var segmentStart int // index into textParts: text before this has been sent/displayed
@@ -4787,7 +5257,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 	workspaceDir := state.workspaceDir
 	replyAgent := state.agent
 	if replyAgent == nil {
-		replyAgent = e.agent
+		replyAgent = e.GetAgent()
 	}
 	workspaceRenderer := func(content string) string {
 		return e.renderOutgoingContentForWorkspace(state.platform, content, workspaceDir)
@@ -4814,8 +5284,12 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 		}
 	}
 	sp := newStreamPreview(e.streamPreview, state.platform, state.replyCtx, e.ctx, workspaceRenderer)
-	cp := newCompactProgressWriter(e.ctx, state.platform, state.replyCtx, e.agent.Name(), e.i18n.CurrentLang(), workspaceRenderer)
+	cp := newCompactProgressWriter(e.ctx, state.platform, state.replyCtx, e.GetAgent().Name(), e.i18n.CurrentLang(), workspaceRenderer)
+	ts := e.newTTSStream(state.platform, state.replyCtx, state.fromVoice, nil)
 	state.mu.Unlock()
+	// Paragraphs still queued for speech are dropped when the turn is
+	// stopped or the agent exits; a finished stream keeps draining.
+	defer func() { ts.discard() }()
 
 	// Send instant confirmation reply if enabled and no streaming card is active.
 	// Streaming cards provide their own "processing" indicator, so instant reply
@@ -5016,7 +5490,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 					})
 				}
 				if cardMessageID == nil {
-					card := buildResolvedRichCard(CardStatusThinking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+					card := buildResolvedRichCard(CardStatusThinking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 					if starter, ok := p.(PreviewStarter); ok {
 						handle, err := starter.SendPreviewStart(e.ctx, replyCtx, card)
 						if err != nil {
@@ -5026,7 +5500,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 						}
 					}
 				} else if updater, ok := p.(MessageUpdater); ok {
-					card := buildResolvedRichCard(CardStatusThinking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+					card := buildResolvedRichCard(CardStatusThinking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 					if err := updater.UpdateMessage(e.ctx, cardMessageID, card); err != nil {
 						slog.Debug("rich card: failed to update thinking card", "platform", p.Name(), "error", err)
 					}
@@ -5092,6 +5566,8 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 
 		case EventToolUse:
 			toolCount++
+			// Text before a tool call is narration, not the reply.
+			ts.reset()
 			if hasRichCard {
 				// When tool messages are suppressed, skip card updates on tool events.
 				if !e.display.ToolMessages {
@@ -5103,7 +5579,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 					Summary: truncateIf(event.ToolInput, e.display.ToolMaxLen),
 				})
 				if cardMessageID == nil {
-					card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+					card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 					if starter, ok := p.(PreviewStarter); ok {
 						handle, err := starter.SendPreviewStart(e.ctx, replyCtx, card)
 						if err != nil {
@@ -5113,7 +5589,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 						}
 					}
 				} else if updater, ok := p.(MessageUpdater); ok {
-					card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+					card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 					if err := updater.UpdateMessage(e.ctx, cardMessageID, card); err != nil {
 						slog.Debug("rich card: failed to update tool card", "platform", p.Name(), "error", err)
 					}
@@ -5233,7 +5709,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 					if hasRichCard {
 						toolSteps = mergeRichToolResult(toolSteps, event, result, e.display.ToolMaxLen)
 						if cardMessageID == nil {
-							card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+							card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 							if starter, ok := p.(PreviewStarter); ok {
 								handle, err := starter.SendPreviewStart(e.ctx, replyCtx, card)
 								if err != nil {
@@ -5243,7 +5719,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 								}
 							}
 						} else if updater, ok := p.(MessageUpdater); ok {
-							card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+							card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 							if err := updater.UpdateMessage(e.ctx, cardMessageID, card); err != nil {
 								slog.Debug("rich card: failed to update tool-result card", "platform", p.Name(), "error", err)
 							}
@@ -5273,6 +5749,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				content = stripAgentFooterLines(content)
 			}
 			if content != "" && !isEllipsisOnly(content) {
+				ts.feed(content)
 				// Pre-compute silentHold transition including this chunk so the
 				// rich-card path doesn't leak a preview that gets recalled at
 				// end-of-stream when the text resolves to bare NO_REPLY (Lark
@@ -5302,7 +5779,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 					if len(textParts) == 0 {
 						if hasRichCard {
 							if cardMessageID == nil && !silentHold {
-								card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+								card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 								if starter, ok := p.(PreviewStarter); ok {
 									handle, err := starter.SendPreviewStart(e.ctx, replyCtx, card)
 									if err != nil {
@@ -5326,7 +5803,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 							// here using the accumulated partialText so the card emerges
 							// with the post-prefix content already in body.
 							if cardMessageID == nil {
-								card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+								card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 								if starter, ok := p.(PreviewStarter); ok {
 									handle, err := starter.SendPreviewStart(e.ctx, replyCtx, card)
 									if err != nil {
@@ -5361,7 +5838,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 									}
 								}
 								if !streamed {
-									card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.agent, state.agentSession, state.workspaceDir))
+									card := buildResolvedRichCard(CardStatusWorking, "", toolSteps, partialText, true, e.composeRichStatusFooter(true, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 									if updater, ok := p.(MessageUpdater); ok {
 										if err := updater.UpdateMessage(e.ctx, cardMessageID, card); err == nil {
 											lastRichCardUpdate = time.Now()
@@ -5387,7 +5864,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 			if event.SessionID != "" {
 				wasEmpty := session.GetAgentSessionID() == ""
 				if session.GetAgentSessionID() != event.SessionID {
-					session.SetAgentSessionID(event.SessionID, e.agent.Name())
+					session.SetAgentSessionID(event.SessionID, e.GetAgent().Name())
 					if wasEmpty {
 						pendingName := session.GetName()
 						if pendingName != "" && pendingName != "session" && pendingName != "default" {
@@ -5521,7 +5998,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				if currentID := state.agentSession.CurrentSessionID(); currentID != "" {
 					wasEmpty := session.GetAgentSessionID() == ""
 					if session.GetAgentSessionID() != currentID {
-						session.SetAgentSessionID(currentID, e.agent.Name())
+						session.SetAgentSessionID(currentID, e.GetAgent().Name())
 						if wasEmpty {
 							pendingName := session.GetName()
 							if pendingName != "" && pendingName != "session" && pendingName != "default" {
@@ -5536,6 +6013,8 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 			// Mark clean exit so unsolicited reader preserves buffered events.
 			state.mu.Lock()
 			state.eventsNeedResync = false
+			state.lastTurnInputTokens = event.InputTokens
+			state.lastTurnOutputTokens = event.OutputTokens
 			state.mu.Unlock()
 
 			fullResponse := event.Content
@@ -5730,7 +6209,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 						silentBody = strings.TrimRight(stripped, " \t\r\n")
 					}
 					if silentBody != "" || len(toolSteps) > 0 {
-						card := buildResolvedRichCard(CardStatusDone, "", toolSteps, silentBody, false, e.composeRichStatusFooter(false, turnStart, e.agent, state.agentSession, state.workspaceDir))
+						card := buildResolvedRichCard(CardStatusDone, "", toolSteps, silentBody, false, e.composeRichStatusFooter(false, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 						if updater, ok := p.(MessageUpdater); ok {
 							if err := updater.UpdateMessage(e.ctx, cardMessageID, card); err != nil {
 								slog.Debug("rich card: failed to finalize card on silent reply", "platform", p.Name(), "error", err)
@@ -5751,7 +6230,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				if splitter, ok := p.(MarkdownTableSplitter); ok {
 					parts = splitter.SplitMarkdownByTables(fullResponse, 5)
 				}
-				richStatusFooter := e.composeRichStatusFooter(false, turnStart, e.agent, state.agentSession, state.workspaceDir)
+				richStatusFooter := e.composeRichStatusFooter(false, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir)
 				if legacyStatusFooter != "" {
 					richStatusFooter = formatElapsed(time.Since(turnStart), false, e.i18n.currentLang()) + "\n" + legacyStatusFooter
 				}
@@ -5800,7 +6279,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				if segmentStart < len(textParts) {
 					unsent := strings.Join(textParts[segmentStart:], "")
 					if unsent != "" {
-						if !sendChunksWithStatusFooter(e.ctx, p, replyCtx, unsent, statusFooter, sendWorkspaceWithError) {
+						if !e.sendReplyChunks(p, replyCtx, sessionKey, workspaceDir, unsent, statusFooter, sendWorkspaceWithError) {
 							return
 						}
 					}
@@ -5818,7 +6297,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				slog.Debug("EventResult: finalized via stream preview", "response_len", len(fullResponse), "footer_len", len(statusFooter))
 			} else {
 				slog.Debug("EventResult: sending via p.Send (preview inactive or failed)", "response_len", len(fullResponse), "footer_len", len(statusFooter))
-				if !sendChunksWithStatusFooter(e.ctx, p, replyCtx, fullResponse, statusFooter, sendWorkspaceWithError) {
+				if !e.sendReplyChunks(p, replyCtx, sessionKey, workspaceDir, fullResponse, statusFooter, sendWorkspaceWithError) {
 					return
 				}
 			}
@@ -5827,8 +6306,15 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				slog.Warn("slow final reply send", "platform", p.Name(), "elapsed", elapsed, "response_len", len(fullResponse))
 			}
 
-			// TTS: async voice reply if enabled (skipped for silent replies)
-			if !isSilent && e.tts != nil && e.tts.Enabled && e.tts.TTS != nil {
+			// TTS: async voice reply if enabled (skipped for silent replies).
+			// Streaming TTS has been speaking paragraphs already; flush the rest.
+			if ts != nil {
+				if isSilent {
+					ts.discard()
+				} else {
+					ts.finish(cleanResponse)
+				}
+			} else if !isSilent && e.tts != nil && e.tts.Enabled && e.tts.TTS != nil {
 				state.mu.Lock()
 				fromVoice := state.fromVoice
 				state.mu.Unlock()
@@ -5841,9 +6327,11 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				slog.Debug("tts: not enabled", "tts_nil", e.tts == nil, "enabled", e.tts != nil && e.tts.Enabled, "tts_obj_nil", e.tts == nil || e.tts.TTS == nil)
 			}
 
+			e.offerTurnFiles(state, p, replyCtx, sessionKey)
+
 			// Auto-compress after finishing a turn, before sending any queued messages.
 			if triggerAutoCompress {
-				compressor, ok := e.agent.(ContextCompressor)
+				compressor, ok := e.GetAgent().(ContextCompressor)
 				if ok && compressor.CompressCommand() != "" {
 					if pendingSend != nil {
 						if err := <-pendingSend; err != nil {
@@ -5892,6 +6380,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				state.currentMessageID = queued.messageID
 				state.fromVoice = queued.fromVoice
 				state.currentTurnUserMessageTimeMs = queued.userMessageTimeMs
+				state.currentTurn = &queued
 				state.mu.Unlock()
 
 				// Stop the previous turn's typing indicator
@@ -5923,6 +6412,7 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 				as := state.agentSession // capture under lock to avoid race with cleanup
 				state.mu.Unlock()
 
+				e.beginTurnFiles(state)
 				nextSend := make(chan error, 1)
 				go func() {
 					if as == nil {
@@ -5939,6 +6429,9 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 
 				// Reset per-turn state for the next turn
 				msgID = queued.messageID
+				state.mu.Lock()
+				state.lastTurnInputTokens, state.lastTurnOutputTokens = 0, 0
+				state.mu.Unlock()
 				textParts = nil
 				segmentStart = 0
 				toolCount = 0
@@ -5965,7 +6458,10 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 					return e.renderOutgoingContentForWorkspace(queued.platform, content, workspaceDir)
 				}
 				sp = newStreamPreview(e.streamPreview, queued.platform, queued.replyCtx, e.ctx, queuedRenderer)
-				cp = newCompactProgressWriter(e.ctx, queued.platform, queued.replyCtx, e.agent.Name(), e.i18n.CurrentLang(), queuedRenderer)
+				cp = newCompactProgressWriter(e.ctx, queued.platform, queued.replyCtx, e.GetAgent().Name(), e.i18n.CurrentLang(), queuedRenderer)
+				// The previous turn's stream may still be sending after
+				// finish; the new one waits for it before its first clip.
+				ts = e.newTTSStream(queued.platform, queued.replyCtx, queued.fromVoice, ts)
 
 				// Reset streaming card state for the next turn
 				streamCard = nil
@@ -5991,10 +6487,12 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 					e.send(queued.platform, queued.replyCtx, replyContent)
 				}
 
-				session.AddHistory("user", queued.content)
-				// Persist queued user message immediately (mirror of the
-				// initial AddHistory("user",...) save above).
-				sessions.Save()
+				if !queued.retry {
+					session.AddHistory("user", queued.content)
+					// Persist queued user message immediately (mirror of the
+					// initial AddHistory("user",...) save above).
+					sessions.Save()
+				}
 
 				if idleTimer != nil {
 					if !idleTimer.Stop() {
@@ -6036,8 +6534,15 @@ func (e *Engine) processInteractiveEvents(state *interactiveState, session *Sess
 			state.mu.Lock()
 			state.eventsNeedResync = true
 			state.mu.Unlock()
+			e.reportProviderKeyLimited(state, event.Error)
+			// Nothing of this turn has reached the user yet: when the
+			// provider is rate limited or down, retry it on the next
+			// provider of the failover chain instead of failing.
+			if segmentStart == 0 && e.failoverTurn(state, session, sessions, sessionKey, event.Error) {
+				return
+			}
 			if hasRichCard && cardMessageID != nil {
-				errCard := buildResolvedRichCard(CardStatusError, "", toolSteps, partialText, false, e.composeRichStatusFooter(false, turnStart, e.agent, state.agentSession, state.workspaceDir))
+				errCard := buildResolvedRichCard(CardStatusError, "", toolSteps, partialText, false, e.composeRichStatusFooter(false, turnStart, e.GetAgent(), state.agentSession, state.workspaceDir))
 				if updater, ok := p.(MessageUpdater); ok {
 					if err := updater.UpdateMessage(e.ctx, cardMessageID, errCard); err != nil {
 						slog.Debug("rich card: failed to update error card", "platform", p.Name(), "error", err)
@@ -6233,6 +6738,7 @@ func (e *Engine) drainPendingMessages(state *interactiveState, session *Session,
 		state.currentMessageID = queued.messageID
 		state.fromVoice = queued.fromVoice
 		state.currentTurnUserMessageTimeMs = queued.userMessageTimeMs
+		state.currentTurn = &queued
 		state.mu.Unlock()
 
 		e.i18n.DetectAndSet(queued.content)
@@ -6247,10 +6753,16 @@ func (e *Engine) drainPendingMessages(state *interactiveState, session *Session,
 			return false
 		}
 
+		// The unsolicited reader started after the previous turn must not
+		// compete with this turn for the agent's events.
+		e.stopUnsolicitedReader(state)
 		drainEvents(as.Events())
 
-		session.AddHistory("user", queued.content)
+		if !queued.retry {
+			session.AddHistory("user", queued.content)
+		}
 
+		e.beginTurnFiles(state)
 		sendDone := make(chan error, 1)
 		go func() {
 			if as == nil {
@@ -6267,6 +6779,14 @@ func (e *Engine) drainPendingMessages(state *interactiveState, session *Session,
 
 		slog.Info("processing queued message", "session", sessionKey)
 		e.processInteractiveEvents(state, session, sessions, sessionKey, queued.messageID, time.Now(), stopTyping, sendDone, queued.replyCtx)
+
+		state.mu.Lock()
+		alive := state.agentSession != nil && state.agentSession.Alive() && !state.stopped && !state.eventsNeedResync
+		workspaceDir := state.workspaceDir
+		state.mu.Unlock()
+		if alive {
+			e.startUnsolicitedReader(state, session, sessions, sessionKey, workspaceDir)
+		}
 	}
 }
 
@@ -6323,6 +6843,8 @@ var builtinCommands = []struct {
 	{[]string{"web"}, "web"},
 	{[]string{"diff"}, "diff"},
 	{[]string{"ps", "btw"}, "ps"},
+	{[]string{"page"}, "page"},
+	{[]string{"attach"}, "attach"},
 }
 
 func (e *Engine) cmdPs(p Platform, msg *Message, args []string) {
@@ -6478,6 +7000,10 @@ func (e *Engine) handleCommand(p Platform, msg *Message, raw string) bool {
 			"project", e.name, "command", cmdID)
 	}
 
+	if changesConfig(cmdID, args) {
+		msg.configReason = configChangeReason(cmdID, args)
+	}
+
 	switch cmdID {
 	case "new":
 		e.cmdNew(p, msg, args)
@@ -6572,6 +7098,10 @@ func (e *Engine) handleCommand(p Platform, msg *Message, raw string) bool {
 		e.cmdWeb(p, msg, args)
 	case "ps":
 		e.cmdPs(p, msg, args)
+	case "page":
+		e.cmdPage(p, msg, args)
+	case "attach":
+		e.cmdAttach(p, msg, args)
 	default:
 		if custom, ok := e.commands.Resolve(cmd); ok {
 			if disabledCmds[strings.ToLower(custom.Name)] {
@@ -7118,7 +7648,7 @@ func (e *Engine) commandWorkDir(agent Agent, msg *Message) string {
 			return normalizeWorkspacePath(dir)
 		}
 	}
-	if wd, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+	if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 		if dir := strings.TrimSpace(wd.GetWorkDir()); dir != "" {
 			return normalizeWorkspacePath(dir)
 		}
@@ -8063,7 +8593,7 @@ func (e *Engine) cmdDiff(p Platform, msg *Message, raw string) {
 		}
 	}
 	if workDir == "" {
-		if wd, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+		if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 			workDir = wd.GetWorkDir()
 		}
 	}
@@ -8512,8 +9042,8 @@ func (e *Engine) cmdStatus(p Platform, msg *Message) {
 			e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgWsResolutionError, err))
 			return
 		}
-		platNames := make([]string, len(e.platforms))
-		for i, pl := range e.platforms {
+		platNames := make([]string, len(e.platformList()))
+		for i, pl := range e.platformList() {
 			platNames[i] = pl.Name()
 		}
 		platformStr := strings.Join(platNames, ", ")
@@ -8535,6 +9065,7 @@ func (e *Engine) cmdStatus(p Platform, msg *Message) {
 				modeStr = e.i18n.Tf(MsgStatusMode, mode)
 			}
 		}
+		modeStr += e.statusProviderLine(agent)
 		thinkingStr := e.i18n.T(MsgDisabledShort)
 		if e.display.ThinkingMessages {
 			thinkingStr = e.i18n.T(MsgEnabledShort)
@@ -8931,8 +9462,8 @@ func (e *Engine) renderDirCardSafe(sessionKey string, page int) *Card {
 
 func (e *Engine) renderStatusCard(sessionKey string, userID string) *Card {
 	agent, sessions := e.sessionContextForKey(sessionKey)
-	platNames := make([]string, len(e.platforms))
-	for i, pl := range e.platforms {
+	platNames := make([]string, len(e.platformList()))
+	for i, pl := range e.platformList() {
 		platNames[i] = pl.Name()
 	}
 	platformStr := strings.Join(platNames, ", ")
@@ -8960,6 +9491,7 @@ func (e *Engine) renderStatusCard(sessionKey string, userID string) *Card {
 			modeStr = e.i18n.Tf(MsgStatusMode, mode)
 		}
 	}
+	modeStr += e.statusProviderLine(agent)
 	thinkingStr := e.i18n.T(MsgDisabledShort)
 	if e.display.ThinkingMessages {
 		thinkingStr = e.i18n.T(MsgEnabledShort)
@@ -9026,6 +9558,27 @@ func (e *Engine) renderStatusCard(sessionKey string, userID string) *Card {
 		Build()
 }
 
+// cronListNextRuns is how many upcoming fire times /cron list shows per job.
+const cronListNextRuns = 3
+
+// cronNextRunsText renders the upcoming fire times of a cron job in its own
+// time zone, or "" when the job is not scheduled.
+func (e *Engine) cronNextRunsText(j *CronJob, now time.Time) string {
+	runs := e.cronScheduler.NextRuns(j.ID, cronListNextRuns)
+	if len(runs) == 0 {
+		return ""
+	}
+	now = now.In(j.Location())
+	parts := make([]string, len(runs))
+	for i, t := range runs {
+		parts[i] = t.Format(cronTimeFormat(t, now))
+	}
+	if len(parts) == 1 {
+		return e.i18n.Tf(MsgCronNextRunLabel, parts[0])
+	}
+	return e.i18n.Tf(MsgCronNextRunsLabel, strings.Join(parts, ", "))
+}
+
 func cronTimeFormat(t, now time.Time) string {
 	if t.Year() != now.Year() {
 		return "2006-01-02 15:04"
@@ -9143,18 +9696,15 @@ func (e *Engine) cmdLang(p Platform, msg *Message, args []string) {
 	if len(args) == 0 {
 		cur := e.i18n.CurrentLang()
 		name := langDisplayName(cur)
-		text := e.i18n.Tf(MsgLangCurrent, name)
-		buttons := [][]ButtonOption{
-			{
-				{Text: "English", Data: "cmd:/lang en"},
-				{Text: "中文", Data: "cmd:/lang zh"},
-				{Text: "繁體中文", Data: "cmd:/lang zh-TW"},
-			},
-			{
-				{Text: "日本語", Data: "cmd:/lang ja"},
-				{Text: "Español", Data: "cmd:/lang es"},
-				{Text: "Auto", Data: "cmd:/lang auto"},
-			},
+		text := e.i18n.Tf(MsgLangCurrent, name, languageCodeList("|"))
+		choices := append(AvailableLanguages(), LangAuto)
+		var buttons [][]ButtonOption
+		for i, l := range choices {
+			if i%3 == 0 {
+				buttons = append(buttons, nil)
+			}
+			row := &buttons[len(buttons)-1]
+			*row = append(*row, ButtonOption{Text: langDisplayName(l), Data: "cmd:/lang " + languageCode(l)})
 		}
 		if supportsCards(p) {
 			e.replyWithCard(p, msg.ReplyCtx, e.renderLangCard())
@@ -9166,34 +9716,17 @@ func (e *Engine) cmdLang(p Platform, msg *Message, args []string) {
 		}
 		var sb strings.Builder
 		sb.WriteString(text)
-		sb.WriteString("\n\n")
-		sb.WriteString("- English: `/lang en`\n")
-		sb.WriteString("- 中文: `/lang zh`\n")
-		sb.WriteString("- 繁體中文: `/lang zh-TW`\n")
-		sb.WriteString("- 日本語: `/lang ja`\n")
-		sb.WriteString("- Español: `/lang es`\n")
-		sb.WriteString("- Auto: `/lang auto`")
+		sb.WriteString("\n")
+		for _, l := range choices {
+			fmt.Fprintf(&sb, "\n- %s: `/lang %s`", langDisplayName(l), languageCode(l))
+		}
 		e.reply(p, msg.ReplyCtx, sb.String())
 		return
 	}
 
-	target := strings.ToLower(strings.TrimSpace(args[0]))
-	var lang Language
-	switch target {
-	case "en", "english":
-		lang = LangEnglish
-	case "zh", "cn", "chinese", "中文":
-		lang = LangChinese
-	case "zh-tw", "zh_tw", "zhtw", "繁體", "繁体":
-		lang = LangTraditionalChinese
-	case "ja", "jp", "japanese", "日本語":
-		lang = LangJapanese
-	case "es", "spanish", "español":
-		lang = LangSpanish
-	case "auto":
-		lang = LangAuto
-	default:
-		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgLangInvalid))
+	lang, ok := ParseLanguage(args[0])
+	if !ok {
+		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgLangInvalid, "`"+languageCodeList("`, `")+"`"))
 		return
 	}
 
@@ -9203,20 +9736,7 @@ func (e *Engine) cmdLang(p Platform, msg *Message, args []string) {
 }
 
 func langDisplayName(lang Language) string {
-	switch lang {
-	case LangEnglish:
-		return "English"
-	case LangChinese:
-		return "中文"
-	case LangTraditionalChinese:
-		return "繁體中文"
-	case LangJapanese:
-		return "日本語"
-	case LangSpanish:
-		return "Español"
-	default:
-		return "Auto"
-	}
+	return LanguageName(lang)
 }
 
 func (e *Engine) cmdHelp(p Platform, msg *Message) {
@@ -9242,7 +9762,7 @@ func (e *Engine) cmdHelp(p Platform, msg *Message) {
 func (e *Engine) cmdStart(p Platform, msg *Message) {
 	name := e.name
 	if name == "" {
-		name = e.agent.Name()
+		name = e.GetAgent().Name()
 	}
 	e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgWelcome), name))
 }
@@ -9621,7 +10141,7 @@ func (e *Engine) cmdModel(p Platform, msg *Message, args []string) {
 		target = resolveModelSwitchTarget(target, models)
 	}
 
-	target, err = e.switchModelOnAgent(agent, target, agent == e.agent)
+	target, err = e.switchModelOnAgent(configCtx(msg), agent, target, agent == e.GetAgent())
 	if err != nil {
 		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgModelChangeFailed, err))
 		return
@@ -9694,14 +10214,14 @@ func parseModelSwitchArgs(args []string) (string, bool) {
 
 // switchModel applies a runtime model selection to the global engine agent and
 // persists the change so reloads keep the selected default.
-func (e *Engine) switchModel(target string) (string, error) {
-	return e.switchModelOnAgent(e.agent, target, true)
+func (e *Engine) switchModel(ctx context.Context, target string) (string, error) {
+	return e.switchModelOnAgent(ctx, e.GetAgent(), target, true)
 }
 
 // switchModelOnAgent applies a runtime model selection to the provided agent.
 // When persistConfig is true, config-backed model/provider changes are saved so
 // reloads keep the new default. Workspace-scoped runtime switches pass false.
-func (e *Engine) switchModelOnAgent(agent Agent, target string, persistConfig bool) (string, error) {
+func (e *Engine) switchModelOnAgent(ctx context.Context, agent Agent, target string, persistConfig bool) (string, error) {
 	switcher, ok := agent.(ModelSwitcher)
 	if !ok {
 		return target, nil
@@ -9710,7 +10230,7 @@ func (e *Engine) switchModelOnAgent(agent Agent, target string, persistConfig bo
 	providerSwitcher, ok := agent.(ProviderSwitcher)
 	if !ok {
 		if persistConfig && e.modelSaveFunc != nil {
-			if err := e.modelSaveFunc(target); err != nil {
+			if err := e.modelSaveFunc(ctx, target); err != nil {
 				return "", fmt.Errorf("save model: %w", err)
 			}
 		}
@@ -9720,7 +10240,7 @@ func (e *Engine) switchModelOnAgent(agent Agent, target string, persistConfig bo
 	active := providerSwitcher.GetActiveProvider()
 	if active == nil {
 		if persistConfig && e.modelSaveFunc != nil {
-			if err := e.modelSaveFunc(target); err != nil {
+			if err := e.modelSaveFunc(ctx, target); err != nil {
 				return "", fmt.Errorf("save model: %w", err)
 			}
 		}
@@ -9739,7 +10259,7 @@ func (e *Engine) switchModelOnAgent(agent Agent, target string, persistConfig bo
 		return target, nil
 	}
 	if persistConfig && e.providerModelSaveFunc != nil {
-		if err := e.providerModelSaveFunc(active.Name, target); err != nil {
+		if err := e.providerModelSaveFunc(ctx, active.Name, target); err != nil {
 			return "", fmt.Errorf("save provider model %q: %w", active.Name, err)
 		}
 	}
@@ -9984,7 +10504,7 @@ func (e *Engine) cmdQuiet(p Platform, msg *Message, args []string) {
 	if e.displaySaveFunc != nil {
 		tm := e.display.ThinkingMessages
 		tool := e.display.ToolMessages
-		if err := e.displaySaveFunc(&newMode, &tm, nil, nil, &tool); err != nil {
+		if err := e.displaySaveFunc(configCtx(msg), &newMode, &tm, nil, nil, &tool); err != nil {
 			slog.Error("failed to persist display config after /quiet", "error", err)
 		}
 	}
@@ -10017,7 +10537,7 @@ func (e *Engine) cmdTTS(p Platform, msg *Message, args []string) {
 		mode := args[0]
 		e.tts.SetTTSMode(mode)
 		if e.ttsSaveFunc != nil {
-			if err := e.ttsSaveFunc(mode); err != nil {
+			if err := e.ttsSaveFunc(configCtx(msg), mode); err != nil {
 				slog.Warn("tts: failed to persist mode", "error", err)
 			}
 		}
@@ -10243,7 +10763,7 @@ func (e *Engine) runCompress(state *interactiveState, session *Session, sessions
 
 	drainEvents(state.agentSession.Events())
 
-	compressor, ok := e.agent.(ContextCompressor)
+	compressor, ok := e.GetAgent().(ContextCompressor)
 	if !ok || compressor.CompressCommand() == "" {
 		if !auto {
 			e.reply(p, replyCtx, e.i18n.T(MsgCompressNotSupported))
@@ -10471,6 +10991,9 @@ func (e *Engine) cmdProvider(p Platform, msg *Message, args []string) {
 			if prov.Model != "" {
 				detail += " [" + prov.Model + "]"
 			}
+			if badge := e.providerHealthBadge(prov.Name); badge != "" {
+				detail += " — " + badge
+			}
 			sb.WriteString(fmt.Sprintf("%s%s\n", marker, detail))
 		}
 		sb.WriteString("\n" + e.i18n.T(MsgProviderSwitchHint))
@@ -10479,7 +11002,7 @@ func (e *Engine) cmdProvider(p Platform, msg *Message, args []string) {
 	}
 
 	sub := matchSubCommand(strings.ToLower(args[0]), []string{
-		"list", "add", "remove", "switch", "current", "clear", "reset", "none",
+		"list", "add", "remove", "switch", "current", "keys", "clear", "reset", "none",
 	})
 	switch sub {
 	case "list":
@@ -10503,6 +11026,9 @@ func (e *Engine) cmdProvider(p Platform, msg *Message, args []string) {
 			if prov.Model != "" {
 				detail += " [" + prov.Model + "]"
 			}
+			if badge := e.providerHealthBadge(prov.Name); badge != "" {
+				detail += " — " + badge
+			}
 			sb.WriteString(fmt.Sprintf("%s%s\n", marker, detail))
 		}
 		sb.WriteString("\n" + e.i18n.T(MsgProviderSwitchHint))
@@ -10516,10 +11042,10 @@ func (e *Engine) cmdProvider(p Platform, msg *Message, args []string) {
 
 	case "switch":
 		if len(args) < 2 {
-			e.reply(p, msg.ReplyCtx, "Usage: /provider switch <name>")
+			e.reply(p, msg.ReplyCtx, "Usage: /provider switch <name> [confirm]")
 			return
 		}
-		e.switchProvider(p, msg, sessions, switcher, args[1])
+		e.switchProvider(p, msg, sessions, switcher, args[1], providerSwitchConfirmed(args[2:]))
 
 	case "current":
 		current := switcher.GetActiveProvider()
@@ -10529,6 +11055,9 @@ func (e *Engine) cmdProvider(p Platform, msg *Message, args []string) {
 		}
 		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgProviderCurrent), current.Name))
 
+	case "keys":
+		e.cmdProviderKeys(p, msg, switcher, args[1:])
+
 	case "clear", "reset", "none":
 		switcher.SetActiveProvider("")
 		e.cleanupInteractiveState(e.interactiveKeyForSessionKey(msg.SessionKey))
@@ -10542,17 +11071,23 @@ func (e *Engine) cmdProvider(p Platform, msg *Message, args []string) {
 		// Only persist to global config when operating on the global agent;
 		// in workspace mode the provider state lives on the per-workspace agent.
 		if sessions == e.sessions && e.providerSaveFunc != nil {
-			if err := e.providerSaveFunc(""); err != nil {
+			if err := e.providerSaveFunc(configCtx(msg), ""); err != nil {
 				slog.Error("failed to save provider", "error", err)
 			}
 		}
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgProviderCleared))
 
 	default:
-		e.switchProvider(p, msg, sessions, switcher, args[0])
+		e.switchProvider(p, msg, sessions, switcher, args[0], providerSwitchConfirmed(args[1:]))
 	}
 }
 
+// providerSwitchConfirmed reports whether a /provider switch carries the
+// "confirm" argument that overrides an open circuit.
+func providerSwitchConfirmed(rest []string) bool {
+	return len(rest) > 0 && strings.EqualFold(rest[0], "confirm")
+}
+
 func (e *Engine) cmdProviderAdd(p Platform, msg *Message, switcher ProviderSwitcher, args []string) {
 	if len(args) == 0 {
 		if supportsCards(p) {
@@ -10631,7 +11166,7 @@ func (e *Engine) cmdProviderAdd(p Platform, msg *Message, switcher ProviderSwitc
 
 	// Persist to config
 	if e.providerAddSaveFunc != nil {
-		if err := e.providerAddSaveFunc(prov); err != nil {
+		if err := e.providerAddSaveFunc(configCtx(msg), prov); err != nil {
 			slog.Error("failed to persist provider", "error", err)
 		}
 	}
@@ -10672,7 +11207,7 @@ func (e *Engine) cmdProviderRemove(p Platform, msg *Message, switcher ProviderSw
 
 	// Persist
 	if e.providerRemoveSaveFunc != nil {
-		if err := e.providerRemoveSaveFunc(name); err != nil {
+		if err := e.providerRemoveSaveFunc(configCtx(msg), name); err != nil {
 			slog.Error("failed to persist provider removal", "error", err)
 		}
 	}
@@ -10691,11 +11226,19 @@ func (e *Engine) resetAllSessions() {
 	e.sessions.Save()
 }
 
-func (e *Engine) switchProvider(p Platform, msg *Message, sessions *SessionManager, switcher ProviderSwitcher, name string) {
+func (e *Engine) switchProvider(p Platform, msg *Message, sessions *SessionManager, switcher ProviderSwitcher, name string, confirmed bool) {
+	if !confirmed && e.providerCircuitOpen(name) {
+		reason := e.providerHealthText(e.ProviderHealth().Health(name))
+		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgProviderCircuitOpen), name, reason, name))
+		return
+	}
 	if !switcher.SetActiveProvider(name) {
 		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgProviderNotFound), name))
 		return
 	}
+	if f := e.providerFailover.Load(); f != nil {
+		f.reset(switcher)
+	}
 	e.cleanupInteractiveState(e.interactiveKeyForSessionKey(msg.SessionKey))
 
 	s := sessions.GetOrCreateActive(msg.SessionKey)
@@ -10713,7 +11256,7 @@ func (e *Engine) switchProvider(p Platform, msg *Message, sessions *SessionManag
 	// Only persist to global config when operating on the global agent;
 	// in workspace mode the provider state lives on the per-workspace agent.
 	if sessions == e.sessions && e.providerSaveFunc != nil {
-		if err := e.providerSaveFunc(name); err != nil {
+		if err := e.providerSaveFunc(configCtx(msg), name); err != nil {
 			slog.Error("failed to save provider", "error", err)
 		}
 	}
@@ -10746,7 +11289,7 @@ func (e *Engine) handlePendingProviderAdd(p Platform, msg *Message, content stri
 	state.pendingProviderAdd = nil
 	state.mu.Unlock()
 
-	switcher, ok := e.agent.(ProviderSwitcher)
+	switcher, ok := e.GetAgent().(ProviderSwitcher)
 	if !ok {
 		return false
 	}
@@ -10795,7 +11338,10 @@ func (e *Engine) handlePendingProviderAdd(p Platform, msg *Message, content stri
 	updated := append(switcher.ListProviders(), prov)
 	switcher.SetProviders(updated)
 	if e.providerAddSaveFunc != nil {
-		if err := e.providerAddSaveFunc(prov); err != nil {
+		// The provider was sent as a plain message, so msg carries no
+		// command to record as the reason.
+		ctx := WithConfigChange(context.Background(), ConfigChange{Actor: configChangeActor(msg), Reason: "/provider add " + prov.Name})
+		if err := e.providerAddSaveFunc(ctx, prov); err != nil {
 			slog.Error("failed to persist provider", "error", err)
 		}
 	}
@@ -10839,7 +11385,7 @@ func (e *Engine) getPendingProviderAdd(sessionKey string) *pendingProviderAddSta
 // providerAddPresetButtons builds inline keyboard rows for platforms
 // that support InlineButtonSender but not full cards.
 func (e *Engine) providerAddPresetButtons() [][]ButtonOption {
-	agentType := e.agent.Name()
+	agentType := e.GetAgent().Name()
 	presets, err := FetchProviderPresets()
 	if err != nil || presets == nil || len(presets.Providers) == 0 {
 		return nil
@@ -10868,7 +11414,7 @@ func (e *Engine) providerAddPresetButtons() [][]ButtonOption {
 // tryProviderAddPreset handles "/provider add <name>" with a single arg that
 // matches a preset name — sets up the pending API key flow.
 func (e *Engine) tryProviderAddPreset(p Platform, msg *Message, switcher ProviderSwitcher, presetName string) bool {
-	agentType := e.agent.Name()
+	agentType := e.GetAgent().Name()
 	presets, err := FetchProviderPresets()
 	if err != nil || presets == nil {
 		return false
@@ -11154,14 +11700,14 @@ func (e *Engine) resolveSendTarget(sessionKey string, attachments bool) (sendTar
 			platformName = strippedKey[:idx]
 		}
 		var targetPlatform Platform
-		for _, candidate := range e.platforms {
+		for _, candidate := range e.platformList() {
 			if candidate.Name() == platformName {
 				targetPlatform = candidate
 				break
 			}
 		}
 		if targetPlatform == nil {
-			for _, candidate := range e.platforms {
+			for _, candidate := range e.platformList() {
 				needle := ":" + candidate.Name() + ":"
 				if idx := strings.Index(strippedKey, needle); idx >= 0 {
 					targetPlatform = candidate
@@ -11216,8 +11762,8 @@ func (e *Engine) resolveSendWorkDir(workDir string) (string, bool, error) {
 }
 
 func (e *Engine) currentSendWorkDir() string {
-	if e.agent != nil {
-		if wd, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+	if e.GetAgent() != nil {
+		if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 			if dir := strings.TrimSpace(wd.GetWorkDir()); dir != "" {
 				return dir
 			}
@@ -11440,7 +11986,7 @@ func (e *Engine) resolveOutboundSessionTarget(sessionKey string, hasAttachments
 			platformName = strippedKey[:idx]
 		}
 		var targetPlatform Platform
-		for _, candidate := range e.platforms {
+		for _, candidate := range e.platformList() {
 			if candidate.Name() == platformName {
 				targetPlatform = candidate
 				break
@@ -11449,7 +11995,7 @@ func (e *Engine) resolveOutboundSessionTarget(sessionKey string, hasAttachments
 		// Fallback: multi-workspace mode may prefix the session key with the
 		// workspace path (same heuristic as ExecuteCronJob / ExecuteHeartbeat).
 		if targetPlatform == nil {
-			for _, candidate := range e.platforms {
+			for _, candidate := range e.platformList() {
 				needle := ":" + candidate.Name() + ":"
 				if idx := strings.Index(strippedKey, needle); idx >= 0 {
 					targetPlatform = candidate
@@ -11667,7 +12213,7 @@ func (e *Engine) renderOutgoingContentForWorkspace(p Platform, content, workspac
 	if strings.TrimSpace(content) == "" {
 		return content
 	}
-	return TransformLocalReferences(content, e.references, e.agent.Name(), p.Name(), workspaceDir)
+	return TransformLocalReferences(content, e.references, e.GetAgent().Name(), p.Name(), workspaceDir)
 }
 
 func (e *Engine) sendWithErrorForWorkspace(p Platform, replyCtx any, content, workspaceDir string) error {
@@ -11906,6 +12452,12 @@ func (e *Engine) handleCardNav(action string, sessionKey string) *Card {
 	if prefix == "act" && cmd == "/model" {
 		return e.handleModelCardAction(args, sessionKey)
 	}
+	if cmd == "/page" {
+		return e.renderReplyPageNav(args, sessionKey, prefix == "act")
+	}
+	if prefix == "act" && cmd == "/attach" {
+		return e.renderAttachNav(args, sessionKey)
+	}
 
 	if prefix == "act" {
 		e.executeCardAction(cmd, args, sessionKey)
@@ -11953,7 +12505,7 @@ func (e *Engine) handleCardNav(action string, sessionKey string) *Card {
 	case "/timer":
 		return e.renderTimerCard(sessionKey, extractUserID(sessionKey))
 	case "/heartbeat":
-		return e.renderHeartbeatCard()
+		return e.renderHeartbeatCard(args)
 	case "/commands":
 		return e.renderCommandsCard()
 	case "/alias":
@@ -12008,7 +12560,7 @@ func (e *Engine) handleModelCardAction(args, sessionKey string) *Card {
 		cancel()
 	}
 
-	resolved, err := e.switchModelOnAgent(agent, target, agent == e.agent)
+	resolved, err := e.switchModelOnAgent(cardConfigCtx(sessionKey, "/model "+target), agent, target, agent == e.GetAgent())
 	interactiveKey := e.interactiveKeyForSessionKey(sessionKey)
 	if err == nil {
 		e.persistWorkspaceModelOverride(interactiveKey, sessionKey, agent, resolved)
@@ -12025,7 +12577,7 @@ func (e *Engine) persistWorkspaceModelOverride(interactiveKey, sessionKey string
 	if e.projectState == nil || !e.multiWorkspace || model == "" {
 		return
 	}
-	if agent == e.agent {
+	if agent == e.GetAgent() {
 		return
 	}
 	workspace := workspaceModelOverrideKey(interactiveKey, sessionKey, agent)
@@ -12100,7 +12652,7 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 		if args == "" {
 			return
 		}
-		switcher, ok := e.agent.(ReasoningEffortSwitcher)
+		switcher, ok := e.GetAgent().(ReasoningEffortSwitcher)
 		if !ok {
 			return
 		}
@@ -12125,7 +12677,7 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 		if args == "" {
 			return
 		}
-		switcher, ok := e.agent.(ModeSwitcher)
+		switcher, ok := e.GetAgent().(ModeSwitcher)
 		if !ok {
 			return
 		}
@@ -12146,22 +12698,8 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 		if args == "" {
 			return
 		}
-		target := strings.ToLower(strings.TrimSpace(args))
-		var lang Language
-		switch target {
-		case "en", "english":
-			lang = LangEnglish
-		case "zh", "cn", "chinese":
-			lang = LangChinese
-		case "zh-tw", "zh_tw", "zhtw":
-			lang = LangTraditionalChinese
-		case "ja", "jp", "japanese":
-			lang = LangJapanese
-		case "es", "spanish":
-			lang = LangSpanish
-		case "auto":
-			lang = LangAuto
-		default:
+		lang, ok := ParseLanguage(args)
+		if !ok {
 			return
 		}
 		e.i18n.SetLang(lang)
@@ -12170,7 +12708,7 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 		if args == "" {
 			return
 		}
-		switcher, ok := e.agent.(ProviderSwitcher)
+		switcher, ok := e.GetAgent().(ProviderSwitcher)
 		if !ok {
 			return
 		}
@@ -12185,7 +12723,7 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 			s.ClearHistory()
 			e.sessions.Save()
 			if e.providerSaveFunc != nil {
-				_ = e.providerSaveFunc(provName)
+				_ = e.providerSaveFunc(cardConfigCtx(sessionKey, "/provider "+args), provName)
 			}
 		}
 
@@ -12193,7 +12731,7 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 		if args == "" {
 			return
 		}
-		agentType := e.agent.Name()
+		agentType := e.GetAgent().Name()
 		presets, err := FetchProviderPresets()
 		if err != nil || presets == nil {
 			return
@@ -12291,13 +12829,17 @@ func (e *Engine) executeCardAction(cmd, args, sessionKey string) {
 		if e.heartbeatScheduler == nil {
 			return
 		}
-		switch args {
+		name, rest := e.splitHeartbeatName(strings.Fields(args))
+		if len(rest) == 0 {
+			return
+		}
+		switch rest[0] {
 		case "pause", "stop":
-			e.heartbeatScheduler.Pause(e.name)
+			e.heartbeatScheduler.Pause(e.name, name)
 		case "resume", "start":
-			e.heartbeatScheduler.Resume(e.name)
+			e.heartbeatScheduler.Resume(e.name, name)
 		case "run", "trigger":
-			e.heartbeatScheduler.TriggerNow(e.name)
+			e.heartbeatScheduler.TriggerNow(e.name, name)
 		}
 
 	case "/cron":
@@ -12585,7 +13127,7 @@ func (e *Engine) pushDeleteModeResultCard(sessionKey string) {
 
 	platformName := extractPlatformName(sessionKey)
 	var targetPlatform Platform
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -12620,7 +13162,7 @@ func (e *Engine) pushDeleteModeResultCard(sessionKey string) {
 }
 
 func (e *Engine) performModelSwitchAsync(sessionKey string, state *interactiveState, agent Agent, sessions *SessionManager, target string) {
-	resolved, err := e.switchModelOnAgent(agent, target, agent == e.agent)
+	resolved, err := e.switchModelOnAgent(cardConfigCtx(sessionKey, "/model "+target), agent, target, agent == e.GetAgent())
 	if err == nil {
 		interactiveKey := e.interactiveKeyForSessionKey(sessionKey)
 		e.persistWorkspaceModelOverride(interactiveKey, sessionKey, agent, resolved)
@@ -12648,7 +13190,7 @@ func (e *Engine) performModelSwitchAsync(sessionKey string, state *interactiveSt
 func (e *Engine) pushModelSwitchResultCard(sessionKey string, card *Card) {
 	platformName := extractPlatformName(sessionKey)
 	var targetPlatform Platform
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -12824,22 +13366,18 @@ func (e *Engine) renderLangCard() *Card {
 	cur := e.i18n.CurrentLang()
 	name := langDisplayName(cur)
 
-	langs := []struct{ code, label string }{
-		{"en", "English"}, {"zh", "中文"}, {"zh-TW", "繁體中文"},
-		{"ja", "日本語"}, {"es", "Español"}, {"auto", "Auto"},
-	}
 	var opts []CardSelectOption
 	initVal := ""
-	for _, l := range langs {
-		opts = append(opts, CardSelectOption{Text: l.label, Value: "act:/lang " + l.code})
-		if string(cur) == l.code || (cur == LangAuto && l.code == "auto") {
-			initVal = "act:/lang " + l.code
+	for _, l := range append(AvailableLanguages(), LangAuto) {
+		opts = append(opts, CardSelectOption{Text: langDisplayName(l), Value: "act:/lang " + languageCode(l)})
+		if cur == l {
+			initVal = "act:/lang " + languageCode(l)
 		}
 	}
 
 	return NewCard().
 		Title(e.i18n.T(MsgCardTitleLanguage), "wathet").
-		Markdown(e.i18n.Tf(MsgLangCurrent, name)).
+		Markdown(e.i18n.Tf(MsgLangCurrent, name, languageCodeList("|"))).
 		Select(e.i18n.T(MsgLangSelectPlaceholder), opts, initVal).
 		Buttons(e.cardBackButton()).
 		Build()
@@ -12850,7 +13388,7 @@ func (e *Engine) renderModelCard(sessionKey string) *Card {
 		return e.renderModelSwitchingCard(ms.target)
 	}
 
-	agent := e.agent
+	agent := e.GetAgent()
 	if sessionKey != "" {
 		agent, _ = e.sessionContextForKey(sessionKey)
 	}
@@ -12919,7 +13457,7 @@ func (e *Engine) renderModelSwitchResultCard(target string, err error) *Card {
 }
 
 func (e *Engine) renderReasoningCard() *Card {
-	switcher, ok := e.agent.(ReasoningEffortSwitcher)
+	switcher, ok := e.GetAgent().(ReasoningEffortSwitcher)
 	if !ok {
 		return e.simpleCard(e.i18n.T(MsgCardTitleReasoning), "orange", e.i18n.T(MsgReasoningNotSupported))
 	}
@@ -12953,7 +13491,7 @@ func (e *Engine) renderReasoningCard() *Card {
 }
 
 func (e *Engine) renderModeCard() *Card {
-	switcher, ok := e.agent.(ModeSwitcher)
+	switcher, ok := e.GetAgent().(ModeSwitcher)
 	if !ok {
 		return e.simpleCard(e.i18n.T(MsgCardTitleMode), "violet", e.i18n.T(MsgModeNotSupported))
 	}
@@ -13253,7 +13791,7 @@ func (e *Engine) renderHistoryCard(sessionKey string) *Card {
 }
 
 func (e *Engine) renderProviderCard() *Card {
-	switcher, ok := e.agent.(ProviderSwitcher)
+	switcher, ok := e.GetAgent().(ProviderSwitcher)
 	if !ok {
 		return e.simpleCard(e.i18n.T(MsgCardTitleProvider), "indigo", e.i18n.T(MsgProviderNotSupported))
 	}
@@ -13289,6 +13827,9 @@ func (e *Engine) renderProviderCard() *Card {
 			if prov.BaseURL != "" {
 				label += " (" + prov.BaseURL + ")"
 			}
+			if icon := e.providerHealthIcon(prov.Name); icon != "" {
+				label = icon + " " + label
+			}
 			val := "act:/provider " + prov.Name
 			opts = append(opts, CardSelectOption{Text: label, Value: val})
 			if current != nil && prov.Name == current.Name {
@@ -13322,7 +13863,7 @@ func (e *Engine) renderProviderAddCard(sessionKey string) *Card {
 	}
 
 	// Show preset selection card
-	agentType := e.agent.Name()
+	agentType := e.GetAgent().Name()
 	lang := e.i18n.CurrentLang()
 
 	cb := NewCard().Title(e.i18n.T(MsgCardTitleProviderAdd), "indigo").
@@ -13353,7 +13894,7 @@ func (e *Engine) renderProviderAddCard(sessionKey string) *Card {
 		globals, gErr := e.listGlobalProvidersFunc(agentType)
 		if gErr == nil && len(globals) > 0 {
 			var existing map[string]bool
-			if sw, ok := e.agent.(ProviderSwitcher); ok {
+			if sw, ok := e.GetAgent().(ProviderSwitcher); ok {
 				existing = make(map[string]bool)
 				for _, p := range sw.ListProviders() {
 					existing[p.Name] = true
@@ -13393,7 +13934,7 @@ func (e *Engine) executeProviderLink(sessionKey, name string) {
 	if name == "" || e.listGlobalProvidersFunc == nil {
 		return
 	}
-	agentType := e.agent.Name()
+	agentType := e.GetAgent().Name()
 	globals, err := e.listGlobalProvidersFunc(agentType)
 	if err != nil {
 		slog.Warn("provider link: list global providers", "error", err)
@@ -13411,7 +13952,7 @@ func (e *Engine) executeProviderLink(sessionKey, name string) {
 		return
 	}
 
-	sw, ok := e.agent.(ProviderSwitcher)
+	sw, ok := e.GetAgent().(ProviderSwitcher)
 	if !ok {
 		return
 	}
@@ -13429,7 +13970,7 @@ func (e *Engine) executeProviderLink(sessionKey, name string) {
 		for _, p := range updated {
 			refs = append(refs, p.Name)
 		}
-		if err := e.providerRefsSaveFunc(refs); err != nil {
+		if err := e.providerRefsSaveFunc(cardConfigCtx(sessionKey, "/provider link "+name), refs); err != nil {
 			slog.Error("provider link: save refs", "error", err)
 		}
 	}
@@ -13469,17 +14010,13 @@ func (e *Engine) renderCronCard(sessionKey string, userID string) *Card {
 			desc += " [mute]"
 		}
 
-		human := CronExprToHuman(j.CronExpr, lang)
+		human := CronExprToHuman(j.Spec(), lang)
 
 		var sb strings.Builder
 		sb.WriteString(fmt.Sprintf("%s %s\n", status, desc))
 		sb.WriteString(e.i18n.Tf(MsgCronIDLabel, j.ID))
 		sb.WriteString(e.i18n.Tf(MsgCronScheduleLabel, human, j.CronExpr))
-		nextRun := e.cronScheduler.NextRun(j.ID)
-		if !nextRun.IsZero() {
-			fmtStr := cronTimeFormat(nextRun, now)
-			sb.WriteString(e.i18n.Tf(MsgCronNextRunLabel, nextRun.Format(fmtStr)))
-		}
+		sb.WriteString(e.cronNextRunsText(j, now))
 		if !j.LastRun.IsZero() {
 			fmtStr := cronTimeFormat(j.LastRun, now)
 			sb.WriteString(e.i18n.Tf(MsgCronLastRunLabel, j.LastRun.Format(fmtStr)))
@@ -13655,7 +14192,7 @@ func (e *Engine) renderSkillsCard() *Card {
 	}
 
 	var sb strings.Builder
-	sb.WriteString(e.i18n.Tf(MsgSkillsTitle, e.agent.Name(), len(skills)))
+	sb.WriteString(e.i18n.Tf(MsgSkillsTitle, e.GetAgent().Name(), len(skills)))
 	for _, s := range skills {
 		sb.WriteString(fmt.Sprintf("  /%s — %s\n", s.Name, s.Description))
 	}
@@ -13668,7 +14205,7 @@ func (e *Engine) renderSkillsCard() *Card {
 }
 
 func (e *Engine) renderDoctorCard() *Card {
-	results := RunDoctorChecks(e.ctx, e.agent, e.platforms)
+	results := RunDoctorChecks(e.ctx, e.GetAgent(), e.platformList())
 	report := FormatDoctorResults(results, e.i18n)
 	return NewCard().
 		Title(e.i18n.T(MsgCardTitleDoctor), "orange").
@@ -13861,17 +14398,21 @@ func (e *Engine) cmdCron(p Platform, msg *Message, args []string) {
 	}
 
 	sub := matchSubCommand(strings.ToLower(args[0]), []string{
-		"add", "addexec", "list", "exec", "run", "trigger", "del", "delete", "rm", "remove", "enable", "disable", "mute", "unmute", "setup",
+		"add", "addexec", "confirm", "list", "exec", "run", "trigger", "log", "del", "delete", "rm", "remove", "enable", "disable", "mute", "unmute", "setup",
 	})
 	switch sub {
 	case "add":
 		e.cmdCronAdd(p, msg, args[1:])
+	case "confirm":
+		e.cmdCronConfirm(p, msg)
 	case "addexec":
 		e.cmdCronAddExec(p, msg, args[1:])
 	case "list":
 		e.cmdCronList(p, msg)
 	case "exec", "run", "trigger":
 		e.cmdCronExec(p, msg, args[1:])
+	case "log":
+		e.cmdCronLog(p, msg, args[1:])
 	case "del", "delete", "rm", "remove":
 		e.cmdCronDel(p, msg, args[1:])
 	case "enable":
@@ -13891,9 +14432,15 @@ func (e *Engine) cmdCron(p Platform, msg *Message, args []string) {
 
 func (e *Engine) cmdCronAdd(p Platform, msg *Message, args []string) {
 	// /cron add <min> <hour> <day> <month> <weekday> <prompt...>
-	if len(args) < 6 {
-		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronAddUsage))
-		return
+	// /cron add <natural-language schedule> <prompt...>
+	if !hasCronExprPrefix(args) {
+		if e.proposeNaturalCron(p, msg, args, false) {
+			return
+		}
+		if len(args) < 6 {
+			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronAddUsage))
+			return
+		}
 	}
 
 	cronExpr := strings.Join(args[:5], " ")
@@ -13924,9 +14471,15 @@ func (e *Engine) cmdCronAddExec(p Platform, msg *Message, args []string) {
 	}
 
 	// /cron addexec <min> <hour> <day> <month> <weekday> <shell command...>
-	if len(args) < 6 {
-		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronAddExecUsage))
-		return
+	// /cron addexec <natural-language schedule> <shell command...>
+	if !hasCronExprPrefix(args) {
+		if e.proposeNaturalCron(p, msg, args, true) {
+			return
+		}
+		if len(args) < 6 {
+			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronAddExecUsage))
+			return
+		}
 	}
 
 	cronExpr := strings.Join(args[:5], " ")
@@ -13950,6 +14503,92 @@ func (e *Engine) cmdCronAddExec(p Platform, msg *Message, args []string) {
 	e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronAddedExec), job.ID, cronExpr, truncateStr(shellCmd, 60)))
 }
 
+// proposeNaturalCron parses args as "<natural-language schedule> <content>",
+// stores the resulting job as pending and asks the user to confirm the
+// interpreted schedule. It returns false when args do not start with a
+// recognizable schedule.
+func (e *Engine) proposeNaturalCron(p Platform, msg *Message, args []string, shell bool) bool {
+	text := strings.Join(args, " ")
+	expr, content, ok := SplitNaturalSchedule(text)
+	if !ok || content == "" {
+		if naturalScheduleNeedsTime(text) {
+			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronNeedsTime))
+			return true
+		}
+		return false
+	}
+	job := &CronJob{
+		ID:         GenerateCronID(),
+		Project:    e.name,
+		SessionKey: msg.SessionKey,
+		CronExpr:   expr,
+		Enabled:    true,
+	}
+	if shell {
+		job.Exec = content
+	} else {
+		job.Prompt = content
+	}
+	e.setPendingCron(msg.SessionKey, job)
+	human := CronExprToHuman(expr, e.i18n.CurrentLang())
+	e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgCronConfirmSchedule, human, expr, truncateStr(content, 60)))
+	return true
+}
+
+func (e *Engine) cmdCronConfirm(p Platform, msg *Message) {
+	job := e.takePendingCron(msg.SessionKey)
+	if job == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronNoPending))
+		return
+	}
+	if job.RunsShell() && !e.isAdmin(msg.UserID) {
+		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgAdminRequired), "/cron confirm"))
+		return
+	}
+	job.CreatedAt = time.Now()
+	if err := e.cronScheduler.AddJob(job); err != nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
+		return
+	}
+	if job.IsShellJob() {
+		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronAddedExec), job.ID, job.CronExpr, truncateStr(job.Exec, 60)))
+		return
+	}
+	e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronAdded), job.ID, job.CronExpr, truncateStr(job.Prompt, 60)))
+}
+
+// setPendingCron stores a cron job awaiting /cron confirm, replacing any
+// earlier unconfirmed one for the session.
+func (e *Engine) setPendingCron(sessionKey string, job *CronJob) {
+	interactiveKey := e.interactiveKeyForSessionKey(sessionKey)
+	e.interactiveMu.Lock()
+	state, ok := e.interactiveStates[interactiveKey]
+	if !ok {
+		state = &interactiveState{}
+		e.interactiveStates[interactiveKey] = state
+	}
+	e.interactiveMu.Unlock()
+	state.mu.Lock()
+	state.pendingCron = job
+	state.mu.Unlock()
+}
+
+// takePendingCron removes and returns the session's pending cron job.
+func (e *Engine) takePendingCron(sessionKey string) *CronJob {
+	interactiveKey := e.interactiveKeyForSessionKey(sessionKey)
+	e.interactiveMu.Lock()
+	state := e.interactiveStates[interactiveKey]
+	e.interactiveMu.Unlock()
+	if state == nil {
+		return nil
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	job := state.pendingCron
+	state.pendingCron = nil
+	return job
+}
+
 func (e *Engine) cmdCronList(p Platform, msg *Message) {
 	jobs := e.cronScheduler.Store().ListByProject(e.name)
 	if len(jobs) == 0 {
@@ -13988,14 +14627,9 @@ func (e *Engine) cmdCronList(p Platform, msg *Message) {
 
 		sb.WriteString(fmt.Sprintf("ID: %s\n", j.ID))
 
-		human := CronExprToHuman(j.CronExpr, lang)
+		human := CronExprToHuman(j.Spec(), lang)
 		sb.WriteString(e.i18n.Tf(MsgCronScheduleLabel, human, j.CronExpr))
-
-		nextRun := e.cronScheduler.NextRun(j.ID)
-		if !nextRun.IsZero() {
-			fmtStr := cronTimeFormat(nextRun, now)
-			sb.WriteString(e.i18n.Tf(MsgCronNextRunLabel, nextRun.Format(fmtStr)))
-		}
+		sb.WriteString(e.cronNextRunsText(j, now))
 
 		if !j.LastRun.IsZero() {
 			fmtStr := cronTimeFormat(j.LastRun, now)
@@ -14022,7 +14656,7 @@ func (e *Engine) cmdCronExec(p Platform, msg *Message, args []string) {
 		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronNotFound), id))
 		return
 	}
-	if job.IsShellJob() && !e.isAdmin(msg.UserID) {
+	if job.RunsShell() && !e.isAdmin(msg.UserID) {
 		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgAdminRequired), "/cron exec"))
 		return
 	}
@@ -14040,6 +14674,83 @@ func (e *Engine) cmdCronExec(p Platform, msg *Message, args []string) {
 	e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronTriggered), id))
 }
 
+// defaultCronLogCount is how many runs /cron log shows when no count is given.
+const defaultCronLogCount = 5
+
+func (e *Engine) cmdCronLog(p Platform, msg *Message, args []string) {
+	if len(args) == 0 {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronLogUsage))
+		return
+	}
+	id := args[0]
+	if e.cronScheduler.Store().Get(id) == nil {
+		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronNotFound), id))
+		return
+	}
+	count := defaultCronLogCount
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronLogUsage))
+			return
+		}
+		count = n
+	}
+
+	runs := e.cronScheduler.Store().Runs(id)
+	if len(runs) == 0 {
+		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgCronLogEmpty), id))
+		return
+	}
+	shown := runs
+	if len(shown) > count {
+		shown = shown[:count]
+	}
+
+	now := time.Now()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(e.i18n.T(MsgCronLogTitle), id, len(shown), len(runs)))
+	sb.WriteString("\n")
+	for _, r := range shown {
+		sb.WriteString("\n")
+		sb.WriteString(formatCronRunLine(r, now))
+		sb.WriteString("\n")
+		if r.Error != "" {
+			sb.WriteString(fmt.Sprintf("error: %s\n", truncateStr(r.Error, 200)))
+		}
+		if out := strings.TrimSpace(r.Output); out != "" {
+			sb.WriteString(fmt.Sprintf("```\n%s\n```\n", truncateStr(out, 500)))
+		}
+	}
+	e.reply(p, msg.ReplyCtx, strings.TrimRight(sb.String(), "\n"))
+}
+
+// formatCronRunLine renders the one-line summary of a run for /cron log:
+// status, start time, duration, exit code and token usage.
+func formatCronRunLine(r CronRun, now time.Time) string {
+	status := "✅"
+	switch {
+	case r.Error != "":
+		status = "❌"
+	case r.Skipped:
+		status = "⏭"
+	}
+	parts := []string{
+		status + " " + r.StartedAt.Format(cronTimeFormat(r.StartedAt, now)),
+		(time.Duration(r.DurationMs) * time.Millisecond).Round(100 * time.Millisecond).String(),
+	}
+	if r.Manual {
+		parts = append(parts, "manual")
+	}
+	if r.ExitCode != nil {
+		parts = append(parts, fmt.Sprintf("exit %d", *r.ExitCode))
+	}
+	if r.InputTokens > 0 || r.OutputTokens > 0 {
+		parts = append(parts, fmt.Sprintf("in %s / out %s", formatStatusTokenCount(r.InputTokens), formatStatusTokenCount(r.OutputTokens)))
+	}
+	return strings.Join(parts, " · ")
+}
+
 func (e *Engine) cmdCronDel(p Platform, msg *Message, args []string) {
 	if len(args) == 0 {
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgCronDelUsage))
@@ -14158,6 +14869,10 @@ func (e *Engine) cmdTimerAdd(p Platform, msg *Message, args []string) {
 
 	fireAt, err := ParseDelayOrTime(args[0])
 	if err != nil {
+		// Recurring phrases ("every weekday at 9:30 …") become a cron job.
+		if e.cronScheduler != nil && e.proposeNaturalCron(p, msg, args, false) {
+			return
+		}
 		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
 		return
 	}
@@ -14196,6 +14911,9 @@ func (e *Engine) cmdTimerAddExec(p Platform, msg *Message, args []string) {
 
 	fireAt, err := ParseDelayOrTime(args[0])
 	if err != nil {
+		if e.cronScheduler != nil && e.proposeNaturalCron(p, msg, args, true) {
+			return
+		}
 		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
 		return
 	}
@@ -14296,48 +15014,99 @@ func (e *Engine) cmdTimerMute(p Platform, msg *Message, args []string, mute bool
 // Heartbeat management commands
 // ──────────────────────────────────────────────────────────────
 
+// heartbeatSubCommands are the /heartbeat actions; a leading argument that is
+// not one of them is taken as a heartbeat name.
+var heartbeatSubCommands = []string{
+	"status", "list", "pause", "stop", "resume", "start", "run", "trigger", "interval",
+}
+
+// splitHeartbeatName peels a heartbeat name off the front of /heartbeat
+// arguments. It returns "" when the first argument is a subcommand.
+func (e *Engine) splitHeartbeatName(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", args
+	}
+	for _, sub := range heartbeatSubCommands {
+		if strings.EqualFold(args[0], sub) {
+			return "", args
+		}
+	}
+	if e.heartbeatScheduler.Status(e.name, args[0]) != nil {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+func heartbeatNames(list []*HeartbeatStatus) string {
+	names := make([]string, len(list))
+	for i, st := range list {
+		names[i] = st.Name
+	}
+	return strings.Join(names, ", ")
+}
+
 func (e *Engine) cmdHeartbeat(p Platform, msg *Message, args []string) {
 	if e.heartbeatScheduler == nil {
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatNotAvailable))
 		return
 	}
 
-	status := e.heartbeatScheduler.Status(e.name)
-	if status == nil {
+	list := e.heartbeatScheduler.List(e.name)
+	if len(list) == 0 {
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatNotAvailable))
 		return
 	}
 
+	name, args := e.splitHeartbeatName(args)
 	sub := "status"
 	if len(args) > 0 {
-		sub = matchSubCommand(strings.ToLower(args[0]), []string{
-			"status", "pause", "stop", "resume", "start", "run", "trigger", "interval",
-		})
+		sub = matchSubCommand(strings.ToLower(args[0]), heartbeatSubCommands)
 	}
 
+	// Without a name, status lists every heartbeat; actions go to the
+	// default (or only) heartbeat and need a name otherwise.
+	if name == "" && (sub == "status" || sub == "list") && len(list) > 1 {
+		if supportsCards(p) {
+			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard(""))
+			return
+		}
+		e.reply(p, msg.ReplyCtx, e.heartbeatListText(list))
+		return
+	}
+	status := e.heartbeatScheduler.Status(e.name, name)
+	if status == nil {
+		if len(args) > 0 && !slices.Contains(heartbeatSubCommands, sub) {
+			e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgHeartbeatUnknownName), args[0], heartbeatNames(list)))
+			return
+		}
+		e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgHeartbeatNameRequired), heartbeatNames(list)))
+		return
+	}
+	name = status.Name
+
 	switch sub {
-	case "status", "":
+	case "status", "list", "":
 		if supportsCards(p) {
-			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard())
+			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard(name))
 			return
 		}
 		e.cmdHeartbeatStatusText(p, msg, status)
 	case "pause", "stop":
-		e.heartbeatScheduler.Pause(e.name)
+		e.heartbeatScheduler.Pause(e.name, name)
 		if supportsCards(p) {
-			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard())
+			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard(name))
 		} else {
 			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatPaused))
 		}
 	case "resume", "start":
-		e.heartbeatScheduler.Resume(e.name)
+		e.heartbeatScheduler.Resume(e.name, name)
 		if supportsCards(p) {
-			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard())
+			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard(name))
 		} else {
 			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatResumed))
 		}
 	case "run", "trigger":
-		e.heartbeatScheduler.TriggerNow(e.name)
+		e.heartbeatScheduler.TriggerNow(e.name, name)
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatTriggered))
 	case "interval":
 		if len(args) < 2 {
@@ -14349,18 +15118,34 @@ func (e *Engine) cmdHeartbeat(p Platform, msg *Message, args []string) {
 			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatInvalidMins))
 			return
 		}
-		e.heartbeatScheduler.SetInterval(e.name, mins)
+		e.heartbeatScheduler.SetInterval(e.name, name, mins)
 		if supportsCards(p) {
-			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard())
+			e.replyWithCard(p, msg.ReplyCtx, e.renderHeartbeatCard(name))
 		} else {
 			e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgHeartbeatInterval), mins))
 		}
 	default:
+		if len(list) > 1 && name == "" {
+			e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgHeartbeatUnknownName), args[0], heartbeatNames(list)))
+			return
+		}
 		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgHeartbeatUsage))
 	}
 }
 
-func (e *Engine) cmdHeartbeatStatusText(p Platform, msg *Message, st *HeartbeatStatus) {
+func (e *Engine) heartbeatListText(list []*HeartbeatStatus) string {
+	stateStr, _ := e.heartbeatLocalizedHelpers()
+	var sb strings.Builder
+	for _, st := range list {
+		sb.WriteString(fmt.Sprintf(e.i18n.T(MsgHeartbeatListItem),
+			st.Name, stateStr(st.Paused), st.IntervalMins, st.SessionKey, st.RunCount, st.ErrorCount))
+	}
+	return fmt.Sprintf(e.i18n.T(MsgHeartbeatList), sb.String())
+}
+
+// heartbeatStatusBody renders the detailed status of one heartbeat. The
+// name is shown only when it is not the default heartbeat.
+func (e *Engine) heartbeatStatusBody(st *HeartbeatStatus) string {
 	stateStr, yesNo := e.heartbeatLocalizedHelpers()
 
 	lastRunStr := ""
@@ -14379,7 +15164,7 @@ func (e *Engine) cmdHeartbeatStatusText(p Platform, msg *Message, st *HeartbeatS
 		}
 	}
 
-	e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgHeartbeatStatus),
+	body := fmt.Sprintf(e.i18n.T(MsgHeartbeatStatus),
 		stateStr(st.Paused),
 		st.IntervalMins,
 		yesNo(st.OnlyWhenIdle),
@@ -14388,7 +15173,17 @@ func (e *Engine) cmdHeartbeatStatusText(p Platform, msg *Message, st *HeartbeatS
 		st.ErrorCount,
 		st.SkippedBusy,
 		lastRunStr,
-	))
+	)
+	if st.Name != "" && st.Name != DefaultHeartbeatName {
+		if title, rest, ok := strings.Cut(body, "\n"); ok {
+			body = title + " · " + st.Name + "\n" + rest
+		}
+	}
+	return body
+}
+
+func (e *Engine) cmdHeartbeatStatusText(p Platform, msg *Message, st *HeartbeatStatus) {
+	e.reply(p, msg.ReplyCtx, e.heartbeatStatusBody(st))
 }
 
 func (e *Engine) heartbeatLocalizedHelpers() (stateStr func(paused bool) string, yesNo func(bool) string) {
@@ -14437,56 +15232,54 @@ func (e *Engine) heartbeatLocalizedHelpers() (stateStr func(paused bool) string,
 	return
 }
 
-func (e *Engine) renderHeartbeatCard() *Card {
+// renderHeartbeatCard shows one heartbeat with its controls, or, when the
+// project has several heartbeats and args names none, a list linking to each.
+func (e *Engine) renderHeartbeatCard(args string) *Card {
 	if e.heartbeatScheduler == nil {
 		return e.simpleCard(e.i18n.T(MsgCardTitleHeartbeat), "purple", e.i18n.T(MsgHeartbeatNotAvailable))
 	}
-	st := e.heartbeatScheduler.Status(e.name)
-	if st == nil {
+	list := e.heartbeatScheduler.List(e.name)
+	if len(list) == 0 {
 		return e.simpleCard(e.i18n.T(MsgCardTitleHeartbeat), "purple", e.i18n.T(MsgHeartbeatNotAvailable))
 	}
-
-	stateStr, yesNo := e.heartbeatLocalizedHelpers()
-	lang := e.i18n.CurrentLang()
-
-	lastRunStr := ""
-	if !st.LastRun.IsZero() {
-		switch lang {
-		case LangChinese, LangTraditionalChinese:
-			lastRunStr = "上次执行: " + st.LastRun.Format("01-02 15:04:05") + "\n"
-		case LangJapanese:
-			lastRunStr = "最終実行: " + st.LastRun.Format("01-02 15:04:05") + "\n"
-		default:
-			lastRunStr = "Last run: " + st.LastRun.Format("01-02 15:04:05") + "\n"
-		}
-		if st.LastError != "" {
-			lastRunStr += "⚠️ " + truncateStr(st.LastError, 80) + "\n"
+	name, _ := e.splitHeartbeatName(strings.Fields(args))
+	if name == "" && len(list) > 1 {
+		cb := NewCard().Title(e.i18n.T(MsgCardTitleHeartbeat), "purple").Markdown(e.heartbeatListText(list))
+		btns := make([]CardButton, 0, len(list))
+		for _, st := range list {
+			btns = append(btns, DefaultBtn("💓 "+st.Name, "nav:/heartbeat "+st.Name))
 		}
+		cb.Buttons(btns...)
+		cb.Buttons(e.cardBackButton())
+		return cb.Build()
+	}
+	st := e.heartbeatScheduler.Status(e.name, name)
+	if st == nil {
+		return e.simpleCard(e.i18n.T(MsgCardTitleHeartbeat), "purple", e.i18n.T(MsgHeartbeatNotAvailable))
 	}
 
-	body := fmt.Sprintf(e.i18n.T(MsgHeartbeatStatus),
-		stateStr(st.Paused),
-		st.IntervalMins,
-		yesNo(st.OnlyWhenIdle),
-		yesNo(st.Silent),
-		st.RunCount,
-		st.ErrorCount,
-		st.SkippedBusy,
-		lastRunStr,
-	)
-
-	cb := NewCard().Title(e.i18n.T(MsgCardTitleHeartbeat), "purple").Markdown(body)
+	cb := NewCard().Title(e.i18n.T(MsgCardTitleHeartbeat), "purple").Markdown(e.heartbeatStatusBody(st))
 
+	// Buttons name the heartbeat only when the project has several, so
+	// single-heartbeat cards keep their original actions.
+	prefix := "act:/heartbeat "
+	if len(list) > 1 {
+		prefix += st.Name + " "
+	}
 	var actionBtns []CardButton
 	if st.Paused {
-		actionBtns = append(actionBtns, PrimaryBtn("▶️ Resume", "act:/heartbeat resume"))
+		actionBtns = append(actionBtns, PrimaryBtn("▶️ Resume", prefix+"resume"))
 	} else {
-		actionBtns = append(actionBtns, DefaultBtn("⏸ Pause", "act:/heartbeat pause"))
+		actionBtns = append(actionBtns, DefaultBtn("⏸ Pause", prefix+"pause"))
 	}
-	actionBtns = append(actionBtns, DefaultBtn("💓 Run Now", "act:/heartbeat run"))
+	actionBtns = append(actionBtns, DefaultBtn("💓 Run Now", prefix+"run"))
 	cb.Buttons(actionBtns...)
 
-	cb.Buttons(e.cardBackButton())
+	if len(list) > 1 {
+		cb.Buttons(DefaultBtn(e.i18n.T(MsgCardBack), "nav:/heartbeat"))
+	} else {
+		cb.Buttons(e.cardBackButton())
+	}
 
 	return cb.Build()
 }
@@ -14510,7 +15303,7 @@ func (e *Engine) executeCustomCommand(p Platform, msg *Message, cmd *CustomComma
 	prompt := ExpandPrompt(cmd.Prompt, args)
 
 	// Resolve workspace-aware agent in multi-workspace mode. Without this the
-	// custom command always runs against the global e.agent (with the
+	// custom command always runs against the global e.GetAgent() (with the
 	// project-level work_dir), bypassing any per-channel binding written by
 	// /workspace bind.
 	agent, sessions, interactiveKey, workspaceDir, err := e.commandContextWithWorkspace(p, msg)
@@ -14551,8 +15344,8 @@ func (e *Engine) executeShellCommand(p Platform, msg *Message, cmd *CustomComman
 	workDir := cmd.WorkDir
 	if workDir == "" {
 		// Default to agent's work_dir if available
-		if e.agent != nil {
-			if agentOpts, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+		if e.GetAgent() != nil {
+			if agentOpts, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 				workDir = agentOpts.GetWorkDir()
 			}
 		}
@@ -14645,7 +15438,7 @@ func (e *Engine) cmdCommandsAdd(p Platform, msg *Message, args []string) {
 	e.commands.Add(name, "", prompt, "", "", "config")
 
 	if e.commandSaveAddFunc != nil {
-		if err := e.commandSaveAddFunc(name, "", prompt, "", ""); err != nil {
+		if err := e.commandSaveAddFunc(configCtx(msg), name, "", prompt, "", ""); err != nil {
 			slog.Error("failed to persist command", "error", err)
 		}
 	}
@@ -14697,7 +15490,7 @@ func (e *Engine) cmdCommandsAddExec(p Platform, msg *Message, args []string) {
 	e.commands.Add(name, "", "", execCmd, workDir, "config")
 
 	if e.commandSaveAddFunc != nil {
-		if err := e.commandSaveAddFunc(name, "", "", execCmd, workDir); err != nil {
+		if err := e.commandSaveAddFunc(configCtx(msg), name, "", "", execCmd, workDir); err != nil {
 			slog.Error("failed to persist command", "error", err)
 		}
 	}
@@ -14718,7 +15511,7 @@ func (e *Engine) cmdCommandsDel(p Platform, msg *Message, args []string) {
 	}
 
 	if e.commandSaveDelFunc != nil {
-		if err := e.commandSaveDelFunc(name); err != nil {
+		if err := e.commandSaveDelFunc(configCtx(msg), name); err != nil {
 			slog.Error("failed to persist command removal", "error", err)
 		}
 	}
@@ -14739,7 +15532,7 @@ func (e *Engine) executeSkill(p Platform, msg *Message, skill *Skill, args []str
 	}
 
 	// Resolve workspace-aware agent in multi-workspace mode. Without this the
-	// skill always runs against the global e.agent (with the project-level
+	// skill always runs against the global e.GetAgent() (with the project-level
 	// work_dir), bypassing any per-channel binding written by /workspace bind.
 	agent, sessions, interactiveKey, workspaceDir, err := e.commandContextWithWorkspace(p, msg)
 	if err != nil {
@@ -14773,7 +15566,7 @@ func (e *Engine) cmdSkills(p Platform, msg *Message) {
 		}
 
 		var sb strings.Builder
-		sb.WriteString(e.i18n.Tf(MsgSkillsTitle, e.agent.Name(), len(skills)))
+		sb.WriteString(e.i18n.Tf(MsgSkillsTitle, e.GetAgent().Name(), len(skills)))
 
 		for _, s := range skills {
 			sb.WriteString(fmt.Sprintf("  /%s — %s\n", displayCommandForPlatform(p.Name(), s.Name), s.Description))
@@ -14833,7 +15626,7 @@ type configItem struct {
 	desc    string // en description
 	descZh  string // zh description
 	getFunc func() string
-	setFunc func(string) error
+	setFunc func(ctx context.Context, v string) error
 }
 
 func (ci configItem) description(isZh bool) string {
@@ -14855,7 +15648,7 @@ func (e *Engine) configItems() []configItem {
 				}
 				return e.display.Mode
 			},
-			setFunc: func(v string) error {
+			setFunc: func(ctx context.Context, v string) error {
 				switch v {
 				case "full":
 					e.display.Mode = "full"
@@ -14871,7 +15664,7 @@ func (e *Engine) configItems() []configItem {
 				if e.displaySaveFunc != nil {
 					tm := e.display.ThinkingMessages
 					tool := e.display.ToolMessages
-					return e.displaySaveFunc(&v, &tm, nil, nil, &tool)
+					return e.displaySaveFunc(ctx, &v, &tm, nil, nil, &tool)
 				}
 				return nil
 			},
@@ -14883,14 +15676,14 @@ func (e *Engine) configItems() []configItem {
 			getFunc: func() string {
 				return fmt.Sprintf("%t", e.display.ThinkingMessages)
 			},
-			setFunc: func(v string) error {
+			setFunc: func(ctx context.Context, v string) error {
 				b, err := strconv.ParseBool(v)
 				if err != nil {
 					return fmt.Errorf("invalid boolean: %s", v)
 				}
 				e.display.ThinkingMessages = b
 				if e.displaySaveFunc != nil {
-					return e.displaySaveFunc(nil, &b, nil, nil, nil)
+					return e.displaySaveFunc(ctx, nil, &b, nil, nil, nil)
 				}
 				return nil
 			},
@@ -14902,7 +15695,7 @@ func (e *Engine) configItems() []configItem {
 			getFunc: func() string {
 				return fmt.Sprintf("%d", e.display.ThinkingMaxLen)
 			},
-			setFunc: func(v string) error {
+			setFunc: func(ctx context.Context, v string) error {
 				n, err := strconv.Atoi(v)
 				if err != nil {
 					return fmt.Errorf("invalid integer: %s", v)
@@ -14912,7 +15705,7 @@ func (e *Engine) configItems() []configItem {
 				}
 				e.display.ThinkingMaxLen = n
 				if e.displaySaveFunc != nil {
-					return e.displaySaveFunc(nil, nil, &n, nil, nil)
+					return e.displaySaveFunc(ctx, nil, nil, &n, nil, nil)
 				}
 				return nil
 			},
@@ -14924,14 +15717,14 @@ func (e *Engine) configItems() []configItem {
 			getFunc: func() string {
 				return fmt.Sprintf("%t", e.display.ToolMessages)
 			},
-			setFunc: func(v string) error {
+			setFunc: func(ctx context.Context, v string) error {
 				b, err := strconv.ParseBool(v)
 				if err != nil {
 					return fmt.Errorf("invalid boolean: %s", v)
 				}
 				e.display.ToolMessages = b
 				if e.displaySaveFunc != nil {
-					return e.displaySaveFunc(nil, nil, nil, nil, &b)
+					return e.displaySaveFunc(ctx, nil, nil, nil, nil, &b)
 				}
 				return nil
 			},
@@ -14943,7 +15736,7 @@ func (e *Engine) configItems() []configItem {
 			getFunc: func() string {
 				return fmt.Sprintf("%d", e.display.ToolMaxLen)
 			},
-			setFunc: func(v string) error {
+			setFunc: func(ctx context.Context, v string) error {
 				n, err := strconv.Atoi(v)
 				if err != nil {
 					return fmt.Errorf("invalid integer: %s", v)
@@ -14953,7 +15746,7 @@ func (e *Engine) configItems() []configItem {
 				}
 				e.display.ToolMaxLen = n
 				if e.displaySaveFunc != nil {
-					return e.displaySaveFunc(nil, nil, nil, &n, nil)
+					return e.displaySaveFunc(ctx, nil, nil, nil, &n, nil)
 				}
 				return nil
 			},
@@ -14961,6 +15754,8 @@ func (e *Engine) configItems() []configItem {
 	}
 }
 
+var configSubCommands = []string{"get", "set", "reload", "history", "diff", "rollback"}
+
 func (e *Engine) cmdConfig(p Platform, msg *Message, args []string) {
 	if len(args) == 0 {
 		if !supportsCards(p) {
@@ -14982,12 +15777,21 @@ func (e *Engine) cmdConfig(p Platform, msg *Message, args []string) {
 
 	items := e.configItems()
 	isZh := e.i18n.IsZhLike()
-	sub := matchSubCommand(strings.ToLower(args[0]), []string{"get", "set", "reload"})
+	sub := matchSubCommand(strings.ToLower(args[0]), configSubCommands)
 
 	switch sub {
 	case "reload":
 		e.cmdConfigReload(p, msg)
 		return
+	case "history":
+		e.cmdConfigHistory(p, msg)
+		return
+	case "diff":
+		e.cmdConfigDiff(p, msg, args[1:])
+		return
+	case "rollback":
+		e.cmdConfigRollback(p, msg, args[1:])
+		return
 	case "get":
 		if len(args) < 2 {
 			e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigGetUsage))
@@ -15011,7 +15815,7 @@ func (e *Engine) cmdConfig(p Platform, msg *Message, args []string) {
 		value := args[2]
 		for _, item := range items {
 			if item.key == key {
-				if err := item.setFunc(value); err != nil {
+				if err := item.setFunc(configCtx(msg), value); err != nil {
 					e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
 					return
 				}
@@ -15026,7 +15830,7 @@ func (e *Engine) cmdConfig(p Platform, msg *Message, args []string) {
 		for _, item := range items {
 			if item.key == key {
 				if len(args) >= 2 {
-					if err := item.setFunc(args[1]); err != nil {
+					if err := item.setFunc(configCtx(msg), args[1]); err != nil {
 						e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
 						return
 					}
@@ -15111,7 +15915,7 @@ func (e *Engine) renderWhoamiCard(msg *Message) *Card {
 // ── /doctor command ─────────────────────────────────────────
 
 func (e *Engine) cmdDoctor(p Platform, msg *Message) {
-	results := RunDoctorChecks(e.ctx, e.agent, e.platforms)
+	results := RunDoctorChecks(e.ctx, e.GetAgent(), e.platformList())
 	report := FormatDoctorResults(results, e.i18n)
 	e.reply(p, msg.ReplyCtx, report)
 }
@@ -15202,8 +16006,136 @@ func (e *Engine) cmdConfigReload(p Platform, msg *Message) {
 		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
 		return
 	}
-	e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgConfigReloaded),
-		result.DisplayUpdated, result.ProvidersUpdated, result.CommandsUpdated))
+	text := fmt.Sprintf(e.i18n.T(MsgConfigReloaded),
+		result.DisplayUpdated, result.ProvidersUpdated, result.CommandsUpdated)
+	if changes := configReloadChanges(result); changes != nil {
+		text += "\n\n" + e.i18n.Tf(MsgConfigReloadChanges, changes...)
+	}
+	if len(result.RestartRequired) > 0 {
+		text += "\n\n" + e.i18n.Tf(MsgConfigReloadRestartRequired, strings.Join(result.RestartRequired, ", "))
+	}
+	if len(result.Errors) > 0 {
+		text += "\n\n" + e.i18n.Tf(MsgConfigReloadErrors, strings.Join(result.Errors, "\n"))
+	}
+	e.reply(p, msg.ReplyCtx, text)
+}
+
+// maxConfigHistoryShown caps how many versions /config history lists.
+const maxConfigHistoryShown = 15
+
+func (e *Engine) cmdConfigHistory(p Platform, msg *Message) {
+	if e.configHistoryFunc == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigHistoryUnavailable))
+		return
+	}
+	versions, err := e.configHistoryFunc()
+	if err != nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
+		return
+	}
+	if len(versions) == 0 {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigHistoryEmpty))
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(e.i18n.T(MsgConfigHistoryTitle))
+	for i, v := range versions {
+		if i == maxConfigHistoryShown {
+			sb.WriteString(fmt.Sprintf("… (+%d)\n", len(versions)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("`#%d` %s · %s", v.ID, v.Time.Format("2006-01-02 15:04"), v.Actor))
+		if v.Reason != "" {
+			sb.WriteString(" · " + v.Reason)
+		}
+		if name := filepath.Base(v.File); name != "config.toml" {
+			sb.WriteString(" · " + name)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(e.i18n.T(MsgConfigHistoryHint))
+	e.reply(p, msg.ReplyCtx, sb.String())
+}
+
+// parseConfigVersion parses a version argument such as "3" or "#3".
+func parseConfigVersion(s string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	return n, err == nil && n > 0
+}
+
+func (e *Engine) cmdConfigDiff(p Platform, msg *Message, args []string) {
+	if e.configDiffFunc == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigHistoryUnavailable))
+		return
+	}
+	if len(args) == 0 || len(args) > 2 {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigDiffUsage))
+		return
+	}
+	from, ok := parseConfigVersion(args[0])
+	to := 0
+	if ok && len(args) == 2 {
+		to, ok = parseConfigVersion(args[1])
+	}
+	if !ok {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigDiffUsage))
+		return
+	}
+	diff, err := e.configDiffFunc(from, to)
+	if err != nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
+		return
+	}
+	if diff == "" {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigDiffEmpty))
+		return
+	}
+	e.reply(p, msg.ReplyCtx, "```diff\n"+diff+"\n```")
+}
+
+func (e *Engine) cmdConfigRollback(p Platform, msg *Message, args []string) {
+	if e.configRollbackFunc == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigHistoryUnavailable))
+		return
+	}
+	if len(args) != 1 {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigRollbackUsage))
+		return
+	}
+	id, ok := parseConfigVersion(args[0])
+	if !ok {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgConfigRollbackUsage))
+		return
+	}
+	if err := e.configRollbackFunc(configCtx(msg), id); err != nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
+		return
+	}
+	e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgConfigRolledBack, id))
+	if e.configReloadFunc != nil {
+		e.cmdConfigReload(p, msg)
+	}
+}
+
+// configReloadChanges returns the MsgConfigReloadChanges arguments, or nil
+// when the reload changed no projects, platforms or agents.
+func configReloadChanges(r *ConfigReloadResult) []any {
+	lists := [][]string{r.ProjectsAdded, r.ProjectsRemoved, r.ProjectsRebuilt, r.PlatformsStarted, r.PlatformsStopped, r.AgentsSwapped}
+	var args []any
+	changed := false
+	for _, l := range lists {
+		if len(l) == 0 {
+			args = append(args, "-")
+			continue
+		}
+		changed = true
+		args = append(args, strings.Join(l, ", "))
+	}
+	if !changed {
+		return nil
+	}
+	return args
 }
 
 func (e *Engine) cmdRestart(p Platform, msg *Message) {
@@ -15281,7 +16213,7 @@ func (e *Engine) cmdAliasAdd(p Platform, msg *Message, args []string) {
 	e.aliasMu.Unlock()
 
 	if e.aliasSaveAddFunc != nil {
-		if err := e.aliasSaveAddFunc(name, command); err != nil {
+		if err := e.aliasSaveAddFunc(configCtx(msg), name, command); err != nil {
 			slog.Error("alias: save failed", "error", err)
 		}
 	}
@@ -15309,7 +16241,7 @@ func (e *Engine) cmdAliasDel(p Platform, msg *Message, args []string) {
 	}
 
 	if e.aliasSaveDelFunc != nil {
-		if err := e.aliasSaveDelFunc(name); err != nil {
+		if err := e.aliasSaveDelFunc(configCtx(msg), name); err != nil {
 			slog.Error("alias: save failed", "error", err)
 		}
 	}
@@ -15635,12 +16567,7 @@ func (e *Engine) synthesizeAndSendTTS(p Platform, replyCtx any, text string) err
 		return fmt.Errorf("platform %s does not support audio sending", p.Name())
 	}
 	slog.Info("tts: starting synthesis", "voice", e.tts.Voice, "speed", e.tts.Speed, "text_len", len(text))
-	opts := TTSSynthesisOpts{
-		Voice:        e.tts.Voice,
-		LanguageType: e.tts.LanguageType,
-		Speed:        e.tts.Speed,
-	}
-	audioData, format, err := e.tts.TTS.Synthesize(e.ctx, StripMarkdown(text), opts)
+	audioData, format, err := e.synthesizeTTS(e.ctx, StripMarkdown(text))
 	if err != nil {
 		return fmt.Errorf("synthesize: %w", err)
 	}
@@ -15671,7 +16598,7 @@ func relayConversationKey(fromProject, platformName, chatID string) string {
 }
 
 func (e *Engine) platformForName(name string) Platform {
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if strings.EqualFold(p.Name(), name) {
 			return p
 		}
@@ -15687,7 +16614,7 @@ func (e *Engine) relayContextForSourceSessionKey(fromProject, sourceSessionKey s
 
 	relaySessionKey := relayConversationKey(fromProject, platformName, chatID)
 	if !e.multiWorkspace || e.workspaceBindings == nil {
-		return e.agent, e.sessions, relaySessionKey, nil
+		return e.GetAgent(), e.sessions, relaySessionKey, nil
 	}
 
 	channelKey := workspaceChannelKey(platformName, chatID)
@@ -15733,7 +16660,8 @@ func (e *Engine) HandleRelay(ctx context.Context, fromProject, sourceSessionKey,
 				envVars = append(envVars, "PATH="+binDir+string(filepath.ListSeparator)+curPath)
 			}
 		}
-		inj.SetSessionEnv(envVars)
+		_, keyEnv := e.rotateProviderKey(agent)
+		inj.SetSessionEnv(append(envVars, keyEnv...))
 	}
 
 	// Use the engine context (not the relay timeout context) so that the
@@ -16012,8 +16940,8 @@ func (e *Engine) cmdBind(p Platform, msg *Message, args []string) {
 
 	reply := fmt.Sprintf(e.i18n.T(MsgRelayBindSuccess), strings.Join(boundProjects, " ↔ "), otherProject, otherProject)
 
-	if _, ok := e.agent.(SystemPromptSupporter); !ok {
-		if mp, ok := e.agent.(MemoryFileProvider); ok {
+	if _, ok := e.GetAgent().(SystemPromptSupporter); !ok {
+		if mp, ok := e.GetAgent().(MemoryFileProvider); ok {
 			reply += fmt.Sprintf(e.i18n.T(MsgRelaySetupHint), filepath.Base(mp.ProjectMemoryFile()))
 		}
 	}
@@ -16049,11 +16977,11 @@ const (
 // setupMemoryFile appends AgentSystemPrompt() to the agent's project memory
 // file. It returns the result, the filename (for messages), and any error.
 func (e *Engine) setupMemoryFile() (setupResult, string, error) {
-	if _, ok := e.agent.(SystemPromptSupporter); ok {
+	if _, ok := e.GetAgent().(SystemPromptSupporter); ok {
 		return setupNative, "", nil
 	}
 
-	mp, ok := e.agent.(MemoryFileProvider)
+	mp, ok := e.GetAgent().(MemoryFileProvider)
 	if !ok {
 		return setupNoMemory, "", nil
 	}
@@ -16248,19 +17176,19 @@ func (e *Engine) commandContext(p Platform, msg *Message) (Agent, *SessionManage
 // processInteractiveMessageWith (idle reaper bookkeeping, reply footer, etc).
 func (e *Engine) commandContextWithWorkspace(p Platform, msg *Message) (Agent, *SessionManager, string, string, error) {
 	if !e.multiWorkspace {
-		return e.agent, e.sessions, msg.SessionKey, "", nil
+		return e.GetAgent(), e.sessions, msg.SessionKey, "", nil
 	}
 	channelID := effectiveChannelID(msg)
 	channelKey := effectiveWorkspaceChannelKey(msg)
 	if channelKey == "" || channelID == "" {
-		return e.agent, e.sessions, msg.SessionKey, "", nil
+		return e.GetAgent(), e.sessions, msg.SessionKey, "", nil
 	}
 	workspace, _, err := e.resolveWorkspace(p, channelID)
 	if err != nil {
 		return nil, nil, "", "", err
 	}
 	if workspace == "" {
-		return e.agent, e.sessions, msg.SessionKey, "", nil
+		return e.GetAgent(), e.sessions, msg.SessionKey, "", nil
 	}
 	agent, sessions, interactiveKey, effectiveDir, err := e.workspaceContext(workspace, msg.SessionKey)
 	if err != nil {
@@ -16278,7 +17206,7 @@ func (e *Engine) sessionContextForKey(sessionKey string) (Agent, *SessionManager
 		}
 	}
 	if !e.multiWorkspace || e.workspaceBindings == nil {
-		return e.agent, e.sessions
+		return e.GetAgent(), e.sessions
 	}
 	if channelKey := extractWorkspaceChannelKey(sessionKey); channelKey != "" {
 		if b, _, usable := e.lookupEffectiveWorkspaceBinding(channelKey); usable {
@@ -16299,7 +17227,7 @@ func (e *Engine) sessionContextForKey(sessionKey string) (Agent, *SessionManager
 			return wsAgent, wsSessions
 		}
 	}
-	return e.agent, e.sessions
+	return e.GetAgent(), e.sessions
 }
 
 func (e *Engine) bindSendWorkDir(sessionKey, workDir string) {
@@ -16519,7 +17447,7 @@ func (e *Engine) lookupEffectiveWorkspaceBinding(channelKey string) (*WorkspaceB
 // if OS-level user isolation is not active. Mirrors the capability probe used
 // when copying isolation settings to per-workspace agents (getOrCreateWorkspaceAgent).
 func (e *Engine) runAsUser() string {
-	if ma, ok := e.agent.(interface{ GetRunAsUser() string }); ok {
+	if ma, ok := e.GetAgent().(interface{ GetRunAsUser() string }); ok {
 		return ma.GetRunAsUser()
 	}
 	return ""
@@ -16933,7 +17861,7 @@ func (e *Engine) cmdWebSetup(p Platform, msg *Message) {
 		return
 	}
 
-	port, token, needRestart, err := e.webSetupFunc()
+	port, token, needRestart, err := e.webSetupFunc(configCtx(msg))
 	if err != nil {
 		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgError, err))
 		return
@@ -3668,7 +3668,7 @@ func TestConfigItems_ThinkingMessagesToggle(t *testing.T) {
 	if item == nil {
 		t.Fatal("expected thinking_messages config item")
 	}
-	if err := item.setFunc("false"); err != nil {
+	if err := item.setFunc(context.Background(), "false"); err != nil {
 		t.Fatalf("set thinking_messages: %v", err)
 	}
 	if e.display.ThinkingMessages {
@@ -4536,7 +4536,7 @@ func TestCmdModel_UpdatesActiveProviderModel(t *testing.T) {
 	}
 	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
 	var savedProvider, savedModel string
-	e.SetProviderModelSaveFunc(func(providerName, model string) error {
+	e.SetProviderModelSaveFunc(func(_ context.Context, providerName, model string) error {
 		savedProvider = providerName
 		savedModel = model
 		return nil
@@ -4638,7 +4638,7 @@ func TestCmdModel_SavesModelWhenNoActiveProvider(t *testing.T) {
 	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
 
 	var savedModel string
-	e.SetModelSaveFunc(func(model string) error {
+	e.SetModelSaveFunc(func(_ context.Context, model string) error {
 		savedModel = model
 		return nil
 	})
@@ -4667,7 +4667,7 @@ func TestCmdModel_DoesNotClaimSuccessWhenModelSaveFails(t *testing.T) {
 		},
 	}
 	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
-	e.SetModelSaveFunc(func(model string) error {
+	e.SetModelSaveFunc(func(_ context.Context, model string) error {
 		return errors.New("disk full")
 	})
 
@@ -4796,12 +4796,12 @@ func TestCmdModel_MultiWorkspacePersistsWorkspaceModelForRecreatedAgent(t *testi
 			mode:  "default",
 		},
 	}
-	e := NewEngine("test", globalAgent, []Platform{p}, "", LangEnglish)
+	e := NewEngine("test", globalAgent, []Platform{p}, filepath.Join(t.TempDir(), "sessions.json"), LangEnglish)
 	e.SetProjectStateStore(NewProjectStateStore(filepath.Join(t.TempDir(), "projects", "test.state.json")))
 	e.SetMultiWorkspace(t.TempDir(), filepath.Join(t.TempDir(), "bindings.json"))
 
 	var savedModel string
-	e.SetModelSaveFunc(func(model string) error {
+	e.SetModelSaveFunc(func(_ context.Context, model string) error {
 		savedModel = model
 		return nil
 	})
@@ -5561,7 +5561,7 @@ func TestCmdProvider_ClearMultiWorkspaceUsesWorkspaceSessions(t *testing.T) {
 	e := NewEngine("test", globalAgent, []Platform{p}, "", LangEnglish)
 
 	var savedProvider string
-	e.SetProviderSaveFunc(func(name string) error {
+	e.SetProviderSaveFunc(func(_ context.Context, name string) error {
 		savedProvider = name
 		return nil
 	})
@@ -5616,7 +5616,7 @@ func TestSwitchProvider_MultiWorkspaceUsesWorkspaceSessions(t *testing.T) {
 	e := NewEngine("test", globalAgent, []Platform{p}, "", LangEnglish)
 
 	var savedProvider string
-	e.SetProviderSaveFunc(func(name string) error {
+	e.SetProviderSaveFunc(func(_ context.Context, name string) error {
 		savedProvider = name
 		return nil
 	})
@@ -8274,6 +8274,43 @@ func TestCmdCronExec_BlocksShellJobForNonAdmin(t *testing.T) {
 	}
 }
 
+func TestCmdCronExec_BlocksPrecheckJobForNonAdmin(t *testing.T) {
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := NewCronScheduler(store)
+	p := &stubPlatformEngine{n: "plain"}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetAdminFrom("admin1")
+	e.cronScheduler = scheduler
+	scheduler.RegisterEngine("test", e)
+
+	job := &CronJob{
+		ID:         "precheck-from-chat",
+		Project:    "test",
+		SessionKey: "plain:user1",
+		CronExpr:   "0 6 * * *",
+		Prompt:     "summarize",
+		Precheck:   "echo should-not-run",
+		Enabled:    true,
+		CreatedAt:  time.Now(),
+	}
+	if err := store.Add(job); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := &Message{SessionKey: "plain:user1", UserID: "user1", ReplyCtx: "ctx"}
+	e.cmdCron(p, msg, []string{"exec", job.ID})
+
+	if len(p.sent) != 1 || !strings.Contains(strings.ToLower(p.sent[0]), "admin") {
+		t.Fatalf("sent = %q, want a single admin-required reply", p.sent)
+	}
+	if runs := store.Runs(job.ID); len(runs) != 0 {
+		t.Fatalf("runs = %+v, want none for a non-admin", runs)
+	}
+}
+
 func TestCmdCronExec_ProjectMissingReply(t *testing.T) {
 	store, err := NewCronStore(t.TempDir())
 	if err != nil {
@@ -9528,7 +9565,7 @@ func TestHandleCardNav_ModelSwitchFailureRefreshesCard(t *testing.T) {
 	p := &stubCardPlatform{stubPlatformEngine: stubPlatformEngine{n: "feishu"}}
 	agent := &stubModelModeAgent{model: "old"}
 	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
-	e.modelSaveFunc = func(string) error { return errors.New("save failed") }
+	e.modelSaveFunc = func(context.Context, string) error { return errors.New("save failed") }
 
 	sessionKey := "feishu:channel1:user1"
 	card := e.handleCardNav("act:/model broken-model", sessionKey)
@@ -13257,7 +13294,7 @@ func TestEngine_SetterMethods(t *testing.T) {
 	e.SetTTSConfig(&TTSCfg{Voice: "voice-1"})
 
 	// Test SetTTSSaveFunc (just verify it doesn't panic)
-	e.SetTTSSaveFunc(func(text string) error {
+	e.SetTTSSaveFunc(func(_ context.Context, text string) error {
 		return nil
 	})
 
@@ -13267,32 +13304,32 @@ func TestEngine_SetterMethods(t *testing.T) {
 	})
 
 	// Test SetProviderSaveFunc
-	e.SetProviderSaveFunc(func(providerName string) error {
+	e.SetProviderSaveFunc(func(_ context.Context, providerName string) error {
 		return nil
 	})
 
 	// Test SetProviderAddSaveFunc
-	e.SetProviderAddSaveFunc(func(cfg ProviderConfig) error {
+	e.SetProviderAddSaveFunc(func(_ context.Context, cfg ProviderConfig) error {
 		return nil
 	})
 
 	// Test SetProviderRemoveSaveFunc
-	e.SetProviderRemoveSaveFunc(func(name string) error {
+	e.SetProviderRemoveSaveFunc(func(_ context.Context, name string) error {
 		return nil
 	})
 
 	// Test SetCommandSaveAddFunc
-	e.SetCommandSaveAddFunc(func(name, desc, prompt, exec, workDir string) error {
+	e.SetCommandSaveAddFunc(func(_ context.Context, name, desc, prompt, exec, workDir string) error {
 		return nil
 	})
 
 	// Test SetCommandSaveDelFunc
-	e.SetCommandSaveDelFunc(func(name string) error {
+	e.SetCommandSaveDelFunc(func(_ context.Context, name string) error {
 		return nil
 	})
 
 	// Test SetDisplaySaveFunc
-	e.SetDisplaySaveFunc(func(mode *string, thinkingMessages *bool, thinkMax, toolMax *int, toolMessages *bool) error {
+	e.SetDisplaySaveFunc(func(_ context.Context, mode *string, thinkingMessages *bool, thinkMax, toolMax *int, toolMessages *bool) error {
 		return nil
 	})
 
@@ -13302,12 +13339,12 @@ func TestEngine_SetterMethods(t *testing.T) {
 	})
 
 	// Test SetAliasSaveAddFunc
-	e.SetAliasSaveAddFunc(func(alias, cmd string) error {
+	e.SetAliasSaveAddFunc(func(_ context.Context, alias, cmd string) error {
 		return nil
 	})
 
 	// Test SetAliasSaveDelFunc
-	e.SetAliasSaveDelFunc(func(alias string) error {
+	e.SetAliasSaveDelFunc(func(_ context.Context, alias string) error {
 		return nil
 	})
 
@@ -13377,6 +13414,131 @@ func TestEngine_AddPlatform_Multiple(t *testing.T) {
 	}
 }
 
+type stopCountingPlatform struct {
+	stubPlatformEngine
+	started, stopped int
+}
+
+func (p *stopCountingPlatform) Start(MessageHandler) error { p.started++; return nil }
+func (p *stopCountingPlatform) Stop() error                { p.stopped++; return nil }
+
+func TestEngine_StartStopPlatform(t *testing.T) {
+	p1 := &stopCountingPlatform{stubPlatformEngine: stubPlatformEngine{n: "feishu"}}
+	e := NewEngine("test", &stubAgent{}, []Platform{p1}, "", LangEnglish)
+	if err := e.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	p2 := &stopCountingPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	if err := e.StartPlatform(p2); err != nil {
+		t.Fatal(err)
+	}
+	if p2.started != 1 || len(e.platformList()) != 2 {
+		t.Fatalf("StartPlatform: started=%d platforms=%d", p2.started, len(e.platformList()))
+	}
+
+	before := e.platformList()
+	if err := e.StopPlatform(p1); err != nil {
+		t.Fatal(err)
+	}
+	if p1.stopped != 1 || len(e.platformList()) != 1 || e.platformList()[0] != p2 {
+		t.Fatalf("StopPlatform: stopped=%d platforms=%v", p1.stopped, e.platformList())
+	}
+	if len(before) != 2 {
+		t.Error("StopPlatform must not modify a previously returned platform list")
+	}
+}
+
+type namedStubAgent struct {
+	stubAgent
+	name string
+}
+
+func (a *namedStubAgent) Name() string { return a.name }
+
+func TestEngine_SwapAgent(t *testing.T) {
+	oldAgent := &namedStubAgent{name: "old"}
+	e := NewEngine("test", oldAgent, nil, "", LangEnglish)
+	if got := e.SwapAgent(&namedStubAgent{name: "new"}); got != oldAgent {
+		t.Fatalf("SwapAgent returned %v, want the previous agent", got)
+	}
+	if e.GetAgent().Name() != "new" {
+		t.Errorf("GetAgent() = %q after swap", e.GetAgent().Name())
+	}
+}
+
+// stopCountingAgent counts Stop calls.
+type stopCountingAgent struct {
+	namedStubAgent
+	stops atomic.Int32
+}
+
+func (a *stopCountingAgent) Stop() error {
+	a.stops.Add(1)
+	return nil
+}
+
+func TestEngine_RetireAgentWaitsForSessions(t *testing.T) {
+	old := retiredAgentPollInterval
+	retiredAgentPollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { retiredAgentPollInterval = old })
+
+	busy := &stopCountingAgent{namedStubAgent: namedStubAgent{name: "busy"}}
+	e := NewEngine("test", busy, nil, "", LangEnglish)
+	t.Cleanup(func() { _ = e.Stop() })
+	e.interactiveMu.Lock()
+	e.interactiveStates["s1"] = &interactiveState{agent: busy, agentSession: &stubAgentSession{}}
+	e.interactiveMu.Unlock()
+
+	e.RetireAgent(e.SwapAgent(&namedStubAgent{name: "new"}))
+	time.Sleep(30 * time.Millisecond)
+	if n := busy.stops.Load(); n != 0 {
+		t.Fatalf("agent stopped while a session still uses it (%d stops)", n)
+	}
+
+	e.interactiveMu.Lock()
+	delete(e.interactiveStates, "s1")
+	e.interactiveMu.Unlock()
+	deadline := time.Now().Add(2 * time.Second)
+	for busy.stops.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := busy.stops.Load(); n != 1 {
+		t.Fatalf("retired agent stops = %d, want 1 after its last session ended", n)
+	}
+}
+
+func TestCmdConfigReload_ReportsChanges(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetConfigReloadFunc(func() (*ConfigReloadResult, error) {
+		return &ConfigReloadResult{
+			DisplayUpdated:   true,
+			ProjectsAdded:    []string{"docs"},
+			PlatformsStarted: []string{"test/telegram"},
+			RestartRequired:  []string{"bridge", "management"},
+		}, nil
+	})
+	e.cmdConfigReload(p, &Message{SessionKey: "test:ch1", ReplyCtx: "ctx"})
+
+	sent := p.getSent()
+	if len(sent) != 1 {
+		t.Fatalf("sent = %v", sent)
+	}
+	for _, want := range []string{"Projects added: docs", "Projects removed: -", "Platforms started: test/telegram", "bridge, management"} {
+		if !strings.Contains(sent[0], want) {
+			t.Errorf("reply missing %q:\n%s", want, sent[0])
+		}
+	}
+
+	p.clearSent()
+	e.SetConfigReloadFunc(func() (*ConfigReloadResult, error) { return &ConfigReloadResult{}, nil })
+	e.cmdConfigReload(p, &Message{SessionKey: "test:ch1", ReplyCtx: "ctx"})
+	if sent := p.getSent(); len(sent) != 1 || strings.Contains(sent[0], "Projects added") {
+		t.Errorf("a reload without structural changes should not list them: %v", sent)
+	}
+}
+
 func TestExecuteCronJob_ResolvesCronReplyTarget(t *testing.T) {
 	dir := t.TempDir()
 	store, err := NewCronStore(dir)
@@ -13445,6 +13607,54 @@ func TestExecuteCronJob_ResolvesCronReplyTarget(t *testing.T) {
 	}
 }
 
+// tokenThenErrorSession reports token usage on its first turn and fails the
+// second turn without a result event.
+type tokenThenErrorSession struct {
+	resultAgentSession
+	turns int
+}
+
+func (s *tokenThenErrorSession) Send(prompt string, _ string, _ []ImageAttachment, _ []FileAttachment) error {
+	s.turns++
+	if s.turns == 1 {
+		s.events <- Event{Type: EventResult, Content: "done", Done: true, InputTokens: 120, OutputTokens: 30}
+	} else {
+		s.events <- Event{Type: EventError, Error: errors.New("agent crashed")}
+	}
+	return nil
+}
+
+func TestExecuteCronJobResult_TokensAreNotCarriedOverFromPreviousTurn(t *testing.T) {
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewCronStore() error = %v", err)
+	}
+	platform := &stubCronReplyTargetPlatform{stubPlatformEngine: stubPlatformEngine{n: "discord"}}
+	agentSession := &tokenThenErrorSession{resultAgentSession: resultAgentSession{events: make(chan Event, 1)}}
+	e := NewEngine("test", &resultAgent{session: agentSession}, []Platform{platform}, "", LangEnglish)
+	defer e.cancel()
+	e.cronScheduler = NewCronScheduler(store)
+
+	job := &CronJob{ID: "job-tokens", SessionKey: "discord:channel-1:user-1", Prompt: "summarize"}
+	if err := store.Add(job); err != nil {
+		t.Fatalf("store.Add() error = %v", err)
+	}
+	res, err := e.ExecuteCronJobResult(job)
+	if err != nil {
+		t.Fatalf("first run error = %v", err)
+	}
+	if res.InputTokens != 120 || res.OutputTokens != 30 {
+		t.Fatalf("first run tokens = %d/%d, want 120/30", res.InputTokens, res.OutputTokens)
+	}
+	res, _ = e.ExecuteCronJobResult(job)
+	if res == nil {
+		t.Fatal("second run returned no result")
+	}
+	if res.InputTokens != 0 || res.OutputTokens != 0 {
+		t.Errorf("second run tokens = %d/%d, want 0/0 for a turn without a result event", res.InputTokens, res.OutputTokens)
+	}
+}
+
 func TestExecuteCronJob_WorkspacePrefixedSessionKey(t *testing.T) {
 	dir := t.TempDir()
 	store, err := NewCronStore(dir)
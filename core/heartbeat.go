@@ -6,24 +6,31 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
-// HeartbeatConfig holds runtime heartbeat settings for a single project.
+// DefaultHeartbeatName names a project's unnamed (legacy) heartbeat.
+const DefaultHeartbeatName = "default"
+
+// HeartbeatConfig holds runtime settings for one heartbeat of a project.
 type HeartbeatConfig struct {
+	Name         string // empty = DefaultHeartbeatName
 	Enabled      bool
 	IntervalMins int
 	OnlyWhenIdle bool
 	SessionKey   string
-	Prompt       string // explicit prompt; empty = read HEARTBEAT.md
+	Prompt       string // explicit prompt; empty = read PromptFile, then HEARTBEAT.md
+	PromptFile   string // prompt file, relative to the work dir unless absolute
 	Silent       bool   // suppress "💓" notification
 	TimeoutMins  int
 }
 
 // HeartbeatStatus is returned by the /heartbeat command.
 type HeartbeatStatus struct {
+	Name         string
 	Enabled      bool
 	Paused       bool
 	IntervalMins int
@@ -37,23 +44,29 @@ type HeartbeatStatus struct {
 	LastError    string
 }
 
-// heartbeatPersisted is the JSON-serialisable per-project state.
+// heartbeatPersisted is the JSON-serialisable per-heartbeat state. The state
+// file is keyed by project name, so a project's default heartbeat keeps the
+// entry used before heartbeats had names; named heartbeats are nested under
+// their project.
 type heartbeatPersisted struct {
-	Paused       bool `json:"paused"`
-	IntervalMins int  `json:"interval_mins,omitempty"`
+	Paused       bool                           `json:"paused"`
+	IntervalMins int                            `json:"interval_mins,omitempty"`
+	Heartbeats   map[string]*heartbeatPersisted `json:"heartbeats,omitempty"`
 }
 
 // HeartbeatScheduler manages periodic heartbeat execution across projects.
 type HeartbeatScheduler struct {
 	mu        sync.Mutex
-	entries   map[string]*heartbeatEntry // project name → entry
+	entries   map[heartbeatID]*heartbeatEntry
 	stopCh    chan struct{}
+	started   bool
 	stopped   bool
 	stateFile string // path to heartbeat_state.json; empty = no persistence
 }
 
 type heartbeatEntry struct {
 	project string
+	name    string
 	config  HeartbeatConfig
 	engine  *Engine
 	workDir string
@@ -73,7 +86,7 @@ type heartbeatEntry struct {
 
 func NewHeartbeatScheduler(dataDir string) *HeartbeatScheduler {
 	hs := &HeartbeatScheduler{
-		entries: make(map[string]*heartbeatEntry),
+		entries: make(map[heartbeatID]*heartbeatEntry),
 		stopCh:  make(chan struct{}),
 	}
 	if dataDir != "" {
@@ -82,11 +95,29 @@ func NewHeartbeatScheduler(dataDir string) *HeartbeatScheduler {
 	return hs
 }
 
-// Register adds a heartbeat entry for a project. Call before Start().
+// heartbeatID identifies a heartbeat within the scheduler. name is empty for
+// a project's default heartbeat.
+type heartbeatID struct {
+	project, name string
+}
+
+func heartbeatKey(project, name string) heartbeatID {
+	if name == DefaultHeartbeatName {
+		name = ""
+	}
+	return heartbeatID{project: project, name: name}
+}
+
+// Register adds a heartbeat entry for a project. A project may register
+// several heartbeats as long as their names differ. Registering a name again
+// replaces the previous entry; after Start() the new entry starts at once.
 func (hs *HeartbeatScheduler) Register(project string, cfg HeartbeatConfig, engine *Engine, workDir string) {
 	if !cfg.Enabled || cfg.SessionKey == "" {
 		return
 	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultHeartbeatName
+	}
 	if cfg.IntervalMins <= 0 {
 		cfg.IntervalMins = 30
 	}
@@ -96,8 +127,10 @@ func (hs *HeartbeatScheduler) Register(project string, cfg HeartbeatConfig, engi
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
 
+	key := heartbeatKey(project, cfg.Name)
 	entry := &heartbeatEntry{
 		project:          project,
+		name:             cfg.Name,
 		config:           cfg,
 		engine:           engine,
 		workDir:          workDir,
@@ -106,20 +139,52 @@ func (hs *HeartbeatScheduler) Register(project string, cfg HeartbeatConfig, engi
 	}
 
 	// Restore persisted overrides
-	if saved := hs.loadProjectState(project); saved != nil {
+	if saved := hs.loadProjectState(key); saved != nil {
 		entry.paused = saved.Paused
 		if saved.IntervalMins > 0 {
 			entry.config.IntervalMins = saved.IntervalMins
 		}
 	}
 
-	hs.entries[project] = entry
+	if old, ok := hs.entries[key]; ok {
+		old.halt()
+	}
+	hs.entries[key] = entry
+	if hs.started && !hs.stopped {
+		hs.startEntry(entry)
+	}
+}
+
+// Unregister stops and removes every heartbeat of a project, e.g. when the
+// project is removed or rebuilt by a config reload. The state file is left
+// alone, so heartbeats registered again right away keep their overrides.
+func (hs *HeartbeatScheduler) Unregister(project string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	for key, entry := range hs.entries {
+		if entry.project == project {
+			entry.halt()
+			delete(hs.entries, key)
+		}
+	}
+}
+
+func (entry *heartbeatEntry) halt() {
+	if entry.ticker != nil {
+		entry.ticker.Stop()
+	}
+	select {
+	case <-entry.stopCh:
+	default:
+		close(entry.stopCh)
+	}
 }
 
 // Start begins all registered heartbeat tickers.
 func (hs *HeartbeatScheduler) Start() {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
+	hs.started = true
 	for _, entry := range hs.entries {
 		hs.startEntry(entry)
 	}
@@ -138,6 +203,7 @@ func (hs *HeartbeatScheduler) startEntry(entry *heartbeatEntry) {
 	}
 	slog.Info("heartbeat: started",
 		"project", entry.project,
+		"heartbeat", entry.name,
 		"interval", interval,
 		"state", state,
 		"session_key", entry.config.SessionKey,
@@ -155,26 +221,36 @@ func (hs *HeartbeatScheduler) Stop() {
 	hs.stopped = true
 	close(hs.stopCh)
 	for _, entry := range hs.entries {
-		if entry.ticker != nil {
-			entry.ticker.Stop()
+		entry.halt()
+	}
+}
+
+// lookupLocked resolves a heartbeat by name. An empty name selects the
+// project's default heartbeat, or its only heartbeat when there is just one.
+// Caller must hold hs.mu.
+func (hs *HeartbeatScheduler) lookupLocked(project, name string) *heartbeatEntry {
+	if name != "" {
+		return hs.entries[heartbeatKey(project, name)]
+	}
+	if entry, ok := hs.entries[heartbeatKey(project, "")]; ok {
+		return entry
+	}
+	var found *heartbeatEntry
+	for _, entry := range hs.entries {
+		if entry.project != project {
+			continue
 		}
-		select {
-		case <-entry.stopCh:
-		default:
-			close(entry.stopCh)
+		if found != nil {
+			return nil
 		}
+		found = entry
 	}
+	return found
 }
 
-// Status returns the heartbeat status for a project.
-func (hs *HeartbeatScheduler) Status(project string) *HeartbeatStatus {
-	hs.mu.Lock()
-	defer hs.mu.Unlock()
-	entry, ok := hs.entries[project]
-	if !ok {
-		return nil
-	}
+func (entry *heartbeatEntry) status() *HeartbeatStatus {
 	return &HeartbeatStatus{
+		Name:         entry.name,
 		Enabled:      entry.config.Enabled,
 		Paused:       entry.paused,
 		IntervalMins: entry.config.IntervalMins,
@@ -189,12 +265,44 @@ func (hs *HeartbeatScheduler) Status(project string) *HeartbeatStatus {
 	}
 }
 
-// Pause temporarily stops heartbeat for a project without removing it.
-func (hs *HeartbeatScheduler) Pause(project string) bool {
+// Status returns the status of one heartbeat of a project. An empty name
+// selects the default (or only) heartbeat.
+func (hs *HeartbeatScheduler) Status(project, name string) *HeartbeatStatus {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	entry := hs.lookupLocked(project, name)
+	if entry == nil {
+		return nil
+	}
+	return entry.status()
+}
+
+// List returns the status of every heartbeat of a project, the default
+// heartbeat first and the rest sorted by name.
+func (hs *HeartbeatScheduler) List(project string) []*HeartbeatStatus {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	entry, ok := hs.entries[project]
-	if !ok {
+	var out []*HeartbeatStatus
+	for _, entry := range hs.entries {
+		if entry.project == project {
+			out = append(out, entry.status())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if (out[i].Name == DefaultHeartbeatName) != (out[j].Name == DefaultHeartbeatName) {
+			return out[i].Name == DefaultHeartbeatName
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Pause temporarily stops a heartbeat without removing it.
+func (hs *HeartbeatScheduler) Pause(project, name string) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	entry := hs.lookupLocked(project, name)
+	if entry == nil {
 		return false
 	}
 	if entry.paused {
@@ -204,17 +312,17 @@ func (hs *HeartbeatScheduler) Pause(project string) bool {
 	if entry.ticker != nil {
 		entry.ticker.Stop()
 	}
-	slog.Info("heartbeat: paused", "project", project)
+	slog.Info("heartbeat: paused", "project", project, "heartbeat", entry.name)
 	hs.persistLocked()
 	return true
 }
 
 // Resume resumes a paused heartbeat.
-func (hs *HeartbeatScheduler) Resume(project string) bool {
+func (hs *HeartbeatScheduler) Resume(project, name string) bool {
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	entry, ok := hs.entries[project]
-	if !ok {
+	entry := hs.lookupLocked(project, name)
+	if entry == nil {
 		return false
 	}
 	if !entry.paused {
@@ -225,20 +333,20 @@ func (hs *HeartbeatScheduler) Resume(project string) bool {
 	if entry.ticker != nil {
 		entry.ticker.Reset(interval)
 	}
-	slog.Info("heartbeat: resumed", "project", project, "interval", interval)
+	slog.Info("heartbeat: resumed", "project", project, "heartbeat", entry.name, "interval", interval)
 	hs.persistLocked()
 	return true
 }
 
-// SetInterval changes the heartbeat interval for a project.
-func (hs *HeartbeatScheduler) SetInterval(project string, mins int) bool {
+// SetInterval changes the interval of a heartbeat.
+func (hs *HeartbeatScheduler) SetInterval(project, name string, mins int) bool {
 	if mins <= 0 {
 		return false
 	}
 	hs.mu.Lock()
 	defer hs.mu.Unlock()
-	entry, ok := hs.entries[project]
-	if !ok {
+	entry := hs.lookupLocked(project, name)
+	if entry == nil {
 		return false
 	}
 	entry.config.IntervalMins = mins
@@ -246,17 +354,17 @@ func (hs *HeartbeatScheduler) SetInterval(project string, mins int) bool {
 	if entry.ticker != nil && !entry.paused {
 		entry.ticker.Reset(interval)
 	}
-	slog.Info("heartbeat: interval changed", "project", project, "interval", interval)
+	slog.Info("heartbeat: interval changed", "project", project, "heartbeat", entry.name, "interval", interval)
 	hs.persistLocked()
 	return true
 }
 
 // TriggerNow executes a heartbeat immediately (async).
-func (hs *HeartbeatScheduler) TriggerNow(project string) bool {
+func (hs *HeartbeatScheduler) TriggerNow(project, name string) bool {
 	hs.mu.Lock()
-	entry, ok := hs.entries[project]
+	entry := hs.lookupLocked(project, name)
 	hs.mu.Unlock()
-	if !ok {
+	if entry == nil {
 		return false
 	}
 	go hs.execute(entry)
@@ -265,10 +373,10 @@ func (hs *HeartbeatScheduler) TriggerNow(project string) bool {
 
 // ── persistence ──────────────────────────────────────────────
 
-// loadProjectState reads persisted state for a single project.
+// loadProjectState reads persisted state for a single heartbeat.
 // Must NOT hold hs.mu when reading the file (called during Register which already holds it,
 // but file I/O here is acceptable because Register is called sequentially at startup).
-func (hs *HeartbeatScheduler) loadProjectState(project string) *heartbeatPersisted {
+func (hs *HeartbeatScheduler) loadProjectState(id heartbeatID) *heartbeatPersisted {
 	if hs.stateFile == "" {
 		return nil
 	}
@@ -280,25 +388,45 @@ func (hs *HeartbeatScheduler) loadProjectState(project string) *heartbeatPersist
 	if err := json.Unmarshal(data, &states); err != nil {
 		return nil
 	}
-	return states[project]
+	saved := states[id.project]
+	if saved == nil || id.name == "" {
+		return saved
+	}
+	return saved.Heartbeats[id.name]
 }
 
-// persistLocked saves all project overrides to disk. Caller must hold hs.mu.
+// persistLocked saves all heartbeat overrides to disk. Caller must hold hs.mu.
 func (hs *HeartbeatScheduler) persistLocked() {
 	if hs.stateFile == "" {
 		return
 	}
 	states := make(map[string]*heartbeatPersisted, len(hs.entries))
 	needSave := false
-	for name, entry := range hs.entries {
+	for id, entry := range hs.entries {
 		p := &heartbeatPersisted{Paused: entry.paused}
 		if entry.config.IntervalMins != entry.origIntervalMins {
 			p.IntervalMins = entry.config.IntervalMins
 		}
-		if p.Paused || p.IntervalMins > 0 {
-			states[name] = p
-			needSave = true
+		if !p.Paused && p.IntervalMins == 0 {
+			continue
+		}
+		needSave = true
+		if id.name == "" {
+			if prev := states[id.project]; prev != nil {
+				p.Heartbeats = prev.Heartbeats
+			}
+			states[id.project] = p
+			continue
+		}
+		proj := states[id.project]
+		if proj == nil {
+			proj = &heartbeatPersisted{}
+			states[id.project] = proj
+		}
+		if proj.Heartbeats == nil {
+			proj.Heartbeats = make(map[string]*heartbeatPersisted)
 		}
+		proj.Heartbeats[id.name] = p
 	}
 	if !needSave {
 		os.Remove(hs.stateFile)
@@ -340,7 +468,7 @@ func (hs *HeartbeatScheduler) execute(entry *heartbeatEntry) {
 	if cfg.OnlyWhenIdle {
 		session := entry.engine.sessions.GetOrCreateActive(cfg.SessionKey)
 		if !session.TryLock() {
-			slog.Debug("heartbeat: session busy, skipping", "project", entry.project, "session_key", cfg.SessionKey)
+			slog.Debug("heartbeat: session busy, skipping", "project", entry.project, "heartbeat", entry.name, "session_key", cfg.SessionKey)
 			hs.mu.Lock()
 			entry.skippedBusy++
 			hs.mu.Unlock()
@@ -350,14 +478,21 @@ func (hs *HeartbeatScheduler) execute(entry *heartbeatEntry) {
 	}
 
 	prompt := cfg.Prompt
-	if prompt == "" {
+	if prompt == "" && cfg.PromptFile != "" {
+		// A heartbeat with its own prompt file never falls back to the
+		// shared HEARTBEAT.md, which belongs to a different check.
+		prompt = readHeartbeatPromptFile(entry.workDir, cfg.PromptFile)
+		if prompt == "" {
+			slog.Warn("heartbeat: prompt file missing or empty, using default prompt", "project", entry.project, "heartbeat", entry.name, "prompt_file", cfg.PromptFile)
+		}
+	} else if prompt == "" {
 		prompt = readHeartbeatMD(entry.workDir)
 	}
 	if prompt == "" {
 		prompt = defaultHeartbeatPrompt
 	}
 
-	slog.Info("heartbeat: executing", "project", entry.project, "session_key", cfg.SessionKey, "prompt_len", len(prompt))
+	slog.Info("heartbeat: executing", "project", entry.project, "heartbeat", entry.name, "session_key", cfg.SessionKey, "prompt_len", len(prompt))
 
 	timeout := time.Duration(cfg.TimeoutMins) * time.Minute
 	done := make(chan error, 1)
@@ -378,10 +513,10 @@ func (hs *HeartbeatScheduler) execute(entry *heartbeatEntry) {
 	if err != nil {
 		entry.errorCount++
 		entry.lastError = err.Error()
-		slog.Error("heartbeat: execution failed", "project", entry.project, "error", err)
+		slog.Error("heartbeat: execution failed", "project", entry.project, "heartbeat", entry.name, "error", err)
 	} else {
 		entry.lastError = ""
-		slog.Info("heartbeat: execution completed", "project", entry.project)
+		slog.Info("heartbeat: execution completed", "project", entry.project, "heartbeat", entry.name)
 	}
 	hs.mu.Unlock()
 }
@@ -411,3 +546,20 @@ func readHeartbeatMD(workDir string) string {
 	}
 	return ""
 }
+
+// readHeartbeatPromptFile reads a heartbeat's own prompt file. Relative paths
+// are resolved against workDir.
+func readHeartbeatPromptFile(workDir, file string) string {
+	path := file
+	if !filepath.IsAbs(path) {
+		if workDir == "" {
+			return ""
+		}
+		path = filepath.Join(workDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
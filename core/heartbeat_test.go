@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -64,7 +65,7 @@ func TestHeartbeatScheduler_RegisterDefaults(t *testing.T) {
 	if len(hs.entries) != 1 {
 		t.Fatalf("expected 1 entry, got %d", len(hs.entries))
 	}
-	entry := hs.entries["test"]
+	entry := hs.entries[heartbeatKey("test", "")]
 	if entry == nil {
 		t.Fatal("expected entry for 'test'")
 	}
@@ -85,7 +86,7 @@ func TestHeartbeatScheduler_Status(t *testing.T) {
 		OnlyWhenIdle: true,
 	}, nil, "")
 
-	st := hs.Status("proj")
+	st := hs.Status("proj", "")
 	if st == nil {
 		t.Fatal("expected status")
 	}
@@ -99,7 +100,7 @@ func TestHeartbeatScheduler_Status(t *testing.T) {
 		t.Errorf("expected 0 runs, got %d", st.RunCount)
 	}
 
-	if hs.Status("nonexistent") != nil {
+	if hs.Status("nonexistent", "") != nil {
 		t.Error("expected nil for nonexistent project")
 	}
 }
@@ -111,23 +112,23 @@ func TestHeartbeatScheduler_PauseResume(t *testing.T) {
 		SessionKey: "tg:1:1",
 	}, nil, "")
 
-	if !hs.Pause("proj") {
+	if !hs.Pause("proj", "") {
 		t.Error("pause should succeed")
 	}
-	st := hs.Status("proj")
+	st := hs.Status("proj", "")
 	if !st.Paused {
 		t.Error("expected paused")
 	}
 
-	if !hs.Resume("proj") {
+	if !hs.Resume("proj", "") {
 		t.Error("resume should succeed")
 	}
-	st = hs.Status("proj")
+	st = hs.Status("proj", "")
 	if st.Paused {
 		t.Error("expected not paused")
 	}
 
-	if hs.Pause("nonexistent") {
+	if hs.Pause("nonexistent", "") {
 		t.Error("pause nonexistent should fail")
 	}
 }
@@ -139,18 +140,18 @@ func TestHeartbeatScheduler_SetInterval(t *testing.T) {
 		SessionKey: "tg:1:1",
 	}, nil, "")
 
-	if !hs.SetInterval("proj", 10) {
+	if !hs.SetInterval("proj", "", 10) {
 		t.Error("set interval should succeed")
 	}
-	st := hs.Status("proj")
+	st := hs.Status("proj", "")
 	if st.IntervalMins != 10 {
 		t.Errorf("expected 10, got %d", st.IntervalMins)
 	}
 
-	if hs.SetInterval("proj", 0) {
+	if hs.SetInterval("proj", "", 0) {
 		t.Error("set interval 0 should fail")
 	}
-	if hs.SetInterval("nonexistent", 5) {
+	if hs.SetInterval("nonexistent", "", 5) {
 		t.Error("set interval nonexistent should fail")
 	}
 }
@@ -171,8 +172,8 @@ func TestHeartbeatScheduler_Persistence(t *testing.T) {
 		IntervalMins: 15,
 	}, nil, "")
 
-	hs1.Pause("proj-a")
-	hs1.SetInterval("proj-b", 60)
+	hs1.Pause("proj-a", "")
+	hs1.SetInterval("proj-b", "", 60)
 
 	// Verify state file exists
 	stateFile := filepath.Join(dataDir, "heartbeat_state.json")
@@ -204,19 +205,178 @@ func TestHeartbeatScheduler_Persistence(t *testing.T) {
 		IntervalMins: 15,
 	}, nil, "")
 
-	stA := hs2.Status("proj-a")
+	stA := hs2.Status("proj-a", "")
 	if !stA.Paused {
 		t.Error("proj-a should be paused after restore")
 	}
-	stB := hs2.Status("proj-b")
+	stB := hs2.Status("proj-b", "")
 	if stB.IntervalMins != 60 {
 		t.Errorf("proj-b interval should be 60 after restore, got %d", stB.IntervalMins)
 	}
 
 	// Resume proj-a and reset proj-b interval → no overrides → state file removed
-	hs2.Resume("proj-a")
-	hs2.SetInterval("proj-b", 15) // back to original
+	hs2.Resume("proj-a", "")
+	hs2.SetInterval("proj-b", "", 15) // back to original
 	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
 		t.Error("state file should be removed when no overrides remain")
 	}
 }
+
+func TestHeartbeatScheduler_NamedHeartbeats(t *testing.T) {
+	dataDir := t.TempDir()
+	hs := NewHeartbeatScheduler(dataDir)
+	hs.Register("proj", HeartbeatConfig{Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+	hs.Register("proj", HeartbeatConfig{Name: "builds", Enabled: true, SessionKey: "tg:2:2", IntervalMins: 120}, nil, "")
+	hs.Register("proj", HeartbeatConfig{Name: "inbox", Enabled: true, SessionKey: "tg:1:1", IntervalMins: 30}, nil, "")
+
+	list := hs.List("proj")
+	if len(list) != 3 || list[0].Name != DefaultHeartbeatName || list[1].Name != "builds" || list[2].Name != "inbox" {
+		t.Fatalf("List() = %v, want default, builds, inbox", heartbeatNames(list))
+	}
+	if st := hs.Status("proj", ""); st == nil || st.Name != DefaultHeartbeatName {
+		t.Fatalf("empty name should select the default heartbeat, got %+v", st)
+	}
+
+	if !hs.Pause("proj", "builds") {
+		t.Fatal("pause builds should succeed")
+	}
+	if !hs.Status("proj", "builds").Paused || hs.Status("proj", "inbox").Paused || hs.Status("proj", "").Paused {
+		t.Error("pausing builds must not pause the other heartbeats")
+	}
+	hs.SetInterval("proj", "inbox", 10)
+
+	data, err := os.ReadFile(filepath.Join(dataDir, "heartbeat_state.json"))
+	if err != nil {
+		t.Fatalf("state file should exist: %v", err)
+	}
+	var states map[string]*heartbeatPersisted
+	if err := json.Unmarshal(data, &states); err != nil {
+		t.Fatal(err)
+	}
+	named := states["proj"].Heartbeats
+	if named["builds"] == nil || !named["builds"].Paused || named["inbox"] == nil || named["inbox"].IntervalMins != 10 {
+		t.Errorf("unexpected persisted state: %s", data)
+	}
+
+	hs2 := NewHeartbeatScheduler(dataDir)
+	hs2.Register("proj", HeartbeatConfig{Name: "builds", Enabled: true, SessionKey: "tg:2:2", IntervalMins: 120}, nil, "")
+	if !hs2.Status("proj", "builds").Paused {
+		t.Error("builds should be paused after restore")
+	}
+}
+
+func TestHeartbeatScheduler_ProjectNameWithSlash(t *testing.T) {
+	dataDir := t.TempDir()
+	hs := NewHeartbeatScheduler(dataDir)
+	hs.Register("a/b", HeartbeatConfig{Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+	hs.Register("a", HeartbeatConfig{Name: "b", Enabled: true, SessionKey: "tg:2:2"}, nil, "")
+	if len(hs.entries) != 2 {
+		t.Fatalf("entries = %d, want 2: project a/b and heartbeat a/b must not collide", len(hs.entries))
+	}
+
+	hs.Pause("a", "b")
+	hs2 := NewHeartbeatScheduler(dataDir)
+	hs2.Register("a/b", HeartbeatConfig{Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+	hs2.Register("a", HeartbeatConfig{Name: "b", Enabled: true, SessionKey: "tg:2:2"}, nil, "")
+	if hs2.Status("a/b", "").Paused || !hs2.Status("a", "b").Paused {
+		t.Error("restored pause state leaked between project a/b and heartbeat b of project a")
+	}
+}
+
+func TestHeartbeatScheduler_EmptyNameResolution(t *testing.T) {
+	hs := NewHeartbeatScheduler("")
+	hs.Register("solo", HeartbeatConfig{Name: "inbox", Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+	hs.Register("multi", HeartbeatConfig{Name: "a", Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+	hs.Register("multi", HeartbeatConfig{Name: "b", Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+
+	if st := hs.Status("solo", ""); st == nil || st.Name != "inbox" {
+		t.Errorf("a single heartbeat should be selected without a name, got %+v", st)
+	}
+	if hs.Status("multi", "") != nil || hs.Pause("multi", "") {
+		t.Error("an empty name must not pick one of several named heartbeats")
+	}
+	if hs.Status("multi", "c") != nil {
+		t.Error("unknown heartbeat name should return nil")
+	}
+}
+
+func TestHeartbeatScheduler_UnregisterAndReRegister(t *testing.T) {
+	hs := NewHeartbeatScheduler("")
+	hs.Start()
+	defer hs.Stop()
+
+	hs.Register("proj", HeartbeatConfig{Enabled: true, SessionKey: "tg:1:1", IntervalMins: 30}, nil, "")
+	hs.Register("proj", HeartbeatConfig{Name: "inbox", Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+	hs.Register("other", HeartbeatConfig{Enabled: true, SessionKey: "tg:1:1"}, nil, "")
+
+	// Registering an existing heartbeat again replaces it.
+	hs.Register("proj", HeartbeatConfig{Enabled: true, SessionKey: "tg:1:1", IntervalMins: 60}, nil, "")
+	if st := hs.Status("proj", ""); st == nil || st.IntervalMins != 60 || len(hs.List("proj")) != 2 {
+		t.Fatalf("re-register should replace the default heartbeat, got %+v", st)
+	}
+
+	hs.Unregister("proj")
+	if len(hs.List("proj")) != 0 {
+		t.Errorf("Unregister left %d heartbeats", len(hs.List("proj")))
+	}
+	if hs.Status("other", "") == nil {
+		t.Error("Unregister must not touch other projects")
+	}
+}
+
+func TestReadHeartbeatPromptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "prompts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "prompts", "inbox.md"), []byte("  check inbox\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := readHeartbeatPromptFile(dir, "prompts/inbox.md"); got != "check inbox" {
+		t.Errorf("relative prompt file = %q", got)
+	}
+	if got := readHeartbeatPromptFile("", filepath.Join(dir, "prompts", "inbox.md")); got != "check inbox" {
+		t.Errorf("absolute prompt file = %q", got)
+	}
+	if got := readHeartbeatPromptFile(dir, "missing.md"); got != "" {
+		t.Errorf("missing prompt file = %q, want empty", got)
+	}
+}
+
+func TestCmdHeartbeat_MultipleNamed(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	hs := NewHeartbeatScheduler("")
+	hs.Register("test", HeartbeatConfig{Name: "inbox", Enabled: true, SessionKey: "test:dm", IntervalMins: 30}, e, "")
+	hs.Register("test", HeartbeatConfig{Name: "builds", Enabled: true, SessionKey: "test:group", IntervalMins: 120}, e, "")
+	e.SetHeartbeatScheduler(hs)
+	msg := &Message{SessionKey: "test:dm", UserID: "u1", ReplyCtx: "ctx"}
+
+	e.cmdHeartbeat(p, msg, nil)
+	sent := p.getSent()
+	if len(sent) == 0 || !strings.Contains(sent[len(sent)-1], "builds") || !strings.Contains(sent[len(sent)-1], "inbox") {
+		t.Fatalf("status should list both heartbeats, got %v", sent)
+	}
+
+	e.cmdHeartbeat(p, msg, []string{"pause"})
+	sent = p.getSent()
+	if !strings.Contains(sent[len(sent)-1], "several heartbeats") {
+		t.Errorf("pause without a name should ask for one, got %q", sent[len(sent)-1])
+	}
+
+	e.cmdHeartbeat(p, msg, []string{"builds", "pause"})
+	if !hs.Status("test", "builds").Paused || hs.Status("test", "inbox").Paused {
+		t.Error("/heartbeat builds pause should pause only builds")
+	}
+
+	e.cmdHeartbeat(p, msg, []string{"inbox", "interval", "15"})
+	if got := hs.Status("test", "inbox").IntervalMins; got != 15 {
+		t.Errorf("inbox interval = %d, want 15", got)
+	}
+
+	e.cmdHeartbeat(p, msg, []string{"nightly"})
+	sent = p.getSent()
+	if !strings.Contains(sent[len(sent)-1], `No heartbeat named "nightly"`) {
+		t.Errorf("unknown name reply = %q", sent[len(sent)-1])
+	}
+}
@@ -0,0 +1,360 @@
+package core
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message catalogs ship embedded as locales/<lang>.json. Files with the same
+// layout in an override directory (<data_dir>/i18n) reword individual
+// messages or add whole languages without a rebuild:
+//
+//	{
+//	  "name": "Deutsch",       // shown by /lang; optional for overrides
+//	  "fallback": "en",        // next language to try; default: base language, then en
+//	  "messages": {"starting": "⏳ Wird bearbeitet..."}
+//	}
+//
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+type catalogFile struct {
+	Name     string            `json:"name"`
+	Fallback Language          `json:"fallback"`
+	Messages map[MsgKey]string `json:"messages"`
+}
+
+type catalog struct {
+	name     string
+	fallback Language
+	messages map[MsgKey]string
+}
+
+// builtinLanguageOrder lists the embedded languages in display order;
+// languages added by override files follow in code order.
+var builtinLanguageOrder = []Language{
+	LangEnglish, LangChinese, LangTraditionalChinese, LangJapanese, LangSpanish,
+	LangKorean, LangFrench, LangGerman,
+}
+
+// builtinCatalogs holds the embedded catalogs; it is never modified.
+var builtinCatalogs = mustLoadEmbeddedCatalogs()
+
+var (
+	catalogMu sync.RWMutex
+	catalogs  = builtinCatalogs
+)
+
+func mustLoadEmbeddedCatalogs() map[Language]*catalog {
+	cats, err := loadCatalogDir(embeddedLocales, "locales", nil)
+	if err != nil {
+		panic(fmt.Sprintf("i18n: embedded catalogs: %v", err))
+	}
+	if cats[LangEnglish] == nil {
+		panic("i18n: embedded catalogs: missing en.json")
+	}
+	return cats
+}
+
+// loadCatalogDir reads every <lang>.json in dir of fsys and merges it over
+// base (which may be nil). Files that fail to parse are skipped and
+// reported in the returned error.
+func loadCatalogDir(fsys fs.FS, dir string, base map[Language]*catalog) (map[Language]*catalog, error) {
+	cats := make(map[Language]*catalog, len(base))
+	for lang, c := range base {
+		msgs := make(map[MsgKey]string, len(c.messages))
+		for k, v := range c.messages {
+			msgs[k] = v
+		}
+		cats[lang] = &catalog{name: c.name, fallback: c.fallback, messages: msgs}
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return cats, err
+	}
+	var errs []error
+	for _, ent := range entries {
+		if ent.IsDir() || !strings.HasSuffix(ent.Name(), ".json") {
+			continue
+		}
+		lang := Language(strings.TrimSuffix(ent.Name(), ".json"))
+		data, err := fs.ReadFile(fsys, path.Join(dir, ent.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var f catalogFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ent.Name(), err))
+			continue
+		}
+		c := cats[lang]
+		if c == nil {
+			c = &catalog{messages: make(map[MsgKey]string, len(f.Messages))}
+			cats[lang] = c
+		}
+		if f.Name != "" {
+			c.name = f.Name
+		}
+		if f.Fallback != "" {
+			c.fallback = f.Fallback
+		}
+		for k, v := range f.Messages {
+			c.messages[k] = v
+		}
+	}
+	return cats, errors.Join(errs...)
+}
+
+// LoadI18nOverrides resets the catalogs to the embedded ones and merges the
+// override files found in dir. A missing dir is not an error; files that
+// fail to parse are skipped and reported.
+func LoadI18nOverrides(dir string) error {
+	cats := builtinCatalogs
+	var err error
+	if dir != "" {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			cats, err = loadCatalogDir(os.DirFS(dir), ".", builtinCatalogs)
+			if err != nil {
+				err = fmt.Errorf("i18n overrides in %s: %w", dir, err)
+			}
+		}
+	}
+	catalogMu.Lock()
+	catalogs = cats
+	catalogMu.Unlock()
+	return err
+}
+
+// languageChain returns lang followed by the languages its messages fall
+// back to: the catalog's fallback (transitively), the base language of a
+// regional code ("pt-BR" → "pt"), and finally English. Callers hold
+// catalogMu.
+func languageChain(lang Language) []Language {
+	chain := []Language{lang}
+	seen := map[Language]bool{lang: true}
+	add := func(l Language) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+	for cur := lang; ; {
+		c := catalogs[cur]
+		if c == nil || c.fallback == "" || seen[c.fallback] {
+			break
+		}
+		cur = c.fallback
+		add(cur)
+	}
+	if base, _, ok := strings.Cut(string(lang), "-"); ok {
+		add(Language(base))
+	}
+	add(LangEnglish)
+	return chain
+}
+
+// lookupMessage resolves key for lang along its fallback chain.
+func lookupMessage(lang Language, key MsgKey) (string, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	for _, l := range languageChain(lang) {
+		if c := catalogs[l]; c != nil {
+			if msg, ok := c.messages[key]; ok {
+				return msg, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AvailableLanguages returns the languages with a catalog, built-in ones
+// first.
+func AvailableLanguages() []Language {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	langs := make([]Language, 0, len(catalogs))
+	for _, l := range builtinLanguageOrder {
+		if catalogs[l] != nil {
+			langs = append(langs, l)
+		}
+	}
+	var extra []Language
+	for l := range catalogs {
+		if !isBuiltinLanguage(l) {
+			extra = append(extra, l)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	return append(langs, extra...)
+}
+
+func isBuiltinLanguage(lang Language) bool {
+	for _, l := range builtinLanguageOrder {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// LanguageName returns the display name of lang, e.g. "日本語".
+func LanguageName(lang Language) string {
+	if lang == LangAuto {
+		return "Auto"
+	}
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	if c := catalogs[lang]; c != nil && c.name != "" {
+		return c.name
+	}
+	return string(lang)
+}
+
+// languageAliases maps extra spellings accepted by /lang and the config.
+var languageAliases = map[string]Language{
+	"english": LangEnglish,
+	"cn":      LangChinese, "chinese": LangChinese, "中文": LangChinese,
+	"zh_tw": LangTraditionalChinese, "zhtw": LangTraditionalChinese, "繁體": LangTraditionalChinese, "繁体": LangTraditionalChinese,
+	"jp": LangJapanese, "japanese": LangJapanese, "日本語": LangJapanese,
+	"spanish": LangSpanish, "español": LangSpanish,
+	"kr": LangKorean, "korean": LangKorean, "한국어": LangKorean,
+	"french": LangFrench, "français": LangFrench, "francais": LangFrench,
+	"german": LangGerman, "deutsch": LangGerman,
+}
+
+// ParseLanguage resolves a language code, alias or display name to a
+// language with a catalog. "auto" returns LangAuto.
+func ParseLanguage(s string) (Language, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "auto" {
+		return LangAuto, true
+	}
+	if l, ok := languageAliases[s]; ok {
+		return l, true
+	}
+	s = strings.ReplaceAll(s, "_", "-")
+	for _, l := range AvailableLanguages() {
+		if strings.ToLower(string(l)) == s || strings.ToLower(LanguageName(l)) == s {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// languageCode returns the code /lang accepts for lang.
+func languageCode(lang Language) string {
+	if lang == LangAuto {
+		return "auto"
+	}
+	return string(lang)
+}
+
+// languageCodeList renders the selectable codes, e.g. "en|zh|...|auto".
+func languageCodeList(sep string) string {
+	langs := append(AvailableLanguages(), LangAuto)
+	codes := make([]string, 0, len(langs))
+	for _, l := range langs {
+		codes = append(codes, languageCode(l))
+	}
+	return strings.Join(codes, sep)
+}
+
+// CatalogReport describes one language's catalog relative to the built-in
+// English one.
+type CatalogReport struct {
+	Lang      Language
+	Name      string
+	Fallback  []Language // loaded languages consulted after Lang, in order
+	Keys      int
+	Missing   []MsgKey // English messages the catalog does not translate
+	Unknown   []MsgKey // keys English does not have, e.g. typos in overrides
+	BadFormat []MsgKey // translations whose format arguments differ from English
+}
+
+// CheckI18nCatalogs compares every loaded catalog, overrides included, with
+// the built-in English one.
+func CheckI18nCatalogs() []CatalogReport {
+	langs := AvailableLanguages()
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	en := builtinCatalogs[LangEnglish].messages
+	reports := make([]CatalogReport, 0, len(langs))
+	for _, lang := range langs {
+		c := catalogs[lang]
+		r := CatalogReport{Lang: lang, Name: c.name, Keys: len(c.messages)}
+		for _, l := range languageChain(lang)[1:] {
+			if catalogs[l] != nil {
+				r.Fallback = append(r.Fallback, l)
+			}
+		}
+		for k, enMsg := range en {
+			msg, ok := c.messages[k]
+			if !ok {
+				r.Missing = append(r.Missing, k)
+			} else if formatSignature(msg) != formatSignature(enMsg) {
+				r.BadFormat = append(r.BadFormat, k)
+			}
+		}
+		for k := range c.messages {
+			if _, ok := en[k]; !ok {
+				r.Unknown = append(r.Unknown, k)
+			}
+		}
+		for _, keys := range [][]MsgKey{r.Missing, r.Unknown, r.BadFormat} {
+			sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+var formatVerbRe = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+// formatSignature summarizes the arguments a format string consumes, so
+// translations that reorder them with %[n]s still match.
+func formatSignature(s string) string {
+	args := map[int]string{}
+	next := 1
+	for _, m := range formatVerbRe.FindAllStringSubmatch(s, -1) {
+		if m[2] == "%" {
+			continue
+		}
+		if m[1] != "" {
+			next, _ = strconv.Atoi(m[1])
+		}
+		verb := m[2]
+		if verb == "v" || verb == "q" {
+			verb = "s"
+		}
+		args[next] = verb
+		next++
+	}
+	keys := make([]int, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "%d%s ", k, args[k])
+	}
+	return sb.String()
+}
+
+// I18nOverrideDir returns the catalog override directory of a data dir.
+func I18nOverrideDir(dataDir string) string {
+	return filepath.Join(dataDir, "i18n")
+}
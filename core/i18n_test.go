@@ -1,6 +1,9 @@
 package core
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 )
@@ -50,9 +53,23 @@ func TestI18n_Tf(t *testing.T) {
 }
 
 func TestI18n_AllKeysHaveEnglish(t *testing.T) {
-	for key, langs := range messages {
-		if _, ok := langs[LangEnglish]; !ok {
-			t.Errorf("message key %q missing English translation", key)
+	for _, r := range CheckI18nCatalogs() {
+		for _, key := range r.Unknown {
+			t.Errorf("message key %q in %s catalog missing English translation", key, r.Lang)
+		}
+	}
+}
+
+func TestI18n_BuiltinCatalogsComplete(t *testing.T) {
+	for _, r := range CheckI18nCatalogs() {
+		if len(r.BadFormat) != 0 {
+			t.Errorf("%s catalog: format arguments differ from English in %v", r.Lang, r.BadFormat)
+		}
+		switch r.Lang {
+		case LangKorean, LangFrench, LangGerman:
+			if len(r.Missing) != 0 {
+				t.Errorf("%s catalog misses %d messages: %v", r.Lang, len(r.Missing), r.Missing)
+			}
 		}
 	}
 }
@@ -67,6 +84,8 @@ func TestDetectLanguage(t *testing.T) {
 		{"あいうえお", LangJapanese},
 		// Japanese Katakana
 		{"カタカナ", LangJapanese},
+		// Korean
+		{"안녕하세요", LangKorean},
 		// Chinese
 		{"你好", LangChinese},
 		{"中文测试", LangChinese},
@@ -74,6 +93,16 @@ func TestDetectLanguage(t *testing.T) {
 		{"¿Cómo estás?", LangSpanish},
 		{"Niño español", LangSpanish},
 		{"¡Hola!", LangSpanish},
+		{"¿Qué tal?", LangSpanish},
+		// French
+		{"Ça marche très bien", LangFrench},
+		{"Où est le café ?", LangFrench},
+		{"Créer une tâche", LangFrench},
+		{"Réunion", LangFrench},
+		// German
+		{"Grüß dich", LangGerman},
+		{"Schöne Grüße", LangGerman},
+		{"Für morgen bitte", LangGerman},
 		// English (default)
 		{"Hello world", LangEnglish},
 		{"Just normal text", LangEnglish},
@@ -167,3 +196,115 @@ func TestIsJapanese(t *testing.T) {
 		t.Error("ASCII 'a' should not be Japanese")
 	}
 }
+
+func writeI18nOverride(t *testing.T, dir, lang, body string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, lang+".json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadI18nOverrides_RewordsAndAddsLanguages(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { _ = LoadI18nOverrides("") })
+	writeI18nOverride(t, dir, "en", `{"messages": {"starting": "⏳ On it..."}}`)
+	writeI18nOverride(t, dir, "pt-BR", `{"name": "Português", "fallback": "es", "messages": {"starting": "⏳ Processando..."}}`)
+	if err := LoadI18nOverrides(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := NewI18n(LangEnglish).T(MsgStarting); got != "⏳ On it..." {
+		t.Fatalf("en starting = %q", got)
+	}
+	pt := NewI18n(Language("pt-BR"))
+	if got := pt.T(MsgStarting); got != "⏳ Processando..." {
+		t.Fatalf("pt-BR starting = %q", got)
+	}
+	if got, want := pt.T(MsgNoExecution), NewI18n(LangSpanish).T(MsgNoExecution); got != want {
+		t.Fatalf("pt-BR did not fall back to es: %q, want %q", got, want)
+	}
+	if lang, ok := ParseLanguage("pt_br"); !ok || lang != "pt-BR" {
+		t.Fatalf("ParseLanguage(pt_br) = %q, %v", lang, ok)
+	}
+
+	if err := LoadI18nOverrides(""); err != nil {
+		t.Fatal(err)
+	}
+	if got := NewI18n(LangEnglish).T(MsgStarting); got != "⏳ Processing..." {
+		t.Fatalf("overrides not reset: %q", got)
+	}
+	if slices.Contains(AvailableLanguages(), Language("pt-BR")) {
+		t.Fatal("override language still loaded after reset")
+	}
+}
+
+func TestLoadI18nOverrides_SkipsBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { _ = LoadI18nOverrides("") })
+	writeI18nOverride(t, dir, "fr", `{"messages": {"starting": "⏳ En cours..."}}`)
+	writeI18nOverride(t, dir, "de", `{"messages": `)
+	if err := LoadI18nOverrides(dir); err == nil {
+		t.Fatal("expected error for malformed de.json")
+	}
+	if got := NewI18n(LangFrench).T(MsgStarting); got != "⏳ En cours..." {
+		t.Fatalf("fr starting = %q", got)
+	}
+	if got := NewI18n(LangGerman).T(MsgStarting); got != "⏳ Wird bearbeitet..." {
+		t.Fatalf("de starting = %q", got)
+	}
+}
+
+func TestLanguageChain(t *testing.T) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	if got := languageChain(LangTraditionalChinese); !slices.Equal(got, []Language{"zh-TW", "zh", "en"}) {
+		t.Fatalf("zh-TW chain = %v", got)
+	}
+	if got := languageChain("fr-CA"); !slices.Equal(got, []Language{"fr-CA", "fr", "en"}) {
+		t.Fatalf("fr-CA chain = %v", got)
+	}
+	if got := NewI18n("de-AT").T(MsgStarting); got != "⏳ Wird bearbeitet..." {
+		t.Fatalf("de-AT starting = %q", got)
+	}
+}
+
+func TestParseLanguage(t *testing.T) {
+	tests := map[string]Language{
+		"en": LangEnglish, "ZH-tw": LangTraditionalChinese, "日本語": LangJapanese,
+		"korean": LangKorean, "Français": LangFrench, "de": LangGerman, "auto": LangAuto,
+	}
+	for in, want := range tests {
+		if got, ok := ParseLanguage(in); !ok || got != want {
+			t.Errorf("ParseLanguage(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := ParseLanguage("klingon"); ok {
+		t.Error("ParseLanguage accepted an unknown language")
+	}
+}
+
+func TestCheckI18nCatalogs_ReportsOverrideMistakes(t *testing.T) {
+	dir := t.TempDir()
+	t.Cleanup(func() { _ = LoadI18nOverrides("") })
+	writeI18nOverride(t, dir, "en", `{"messages": {"strating": "typo", "error": "Error: %d"}}`)
+	writeI18nOverride(t, dir, "ko", `{"messages": {"search_result": "🔍 %[2]q: %[1]d"}}`)
+	if err := LoadI18nOverrides(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range CheckI18nCatalogs() {
+		switch r.Lang {
+		case LangEnglish:
+			if !slices.Equal(r.Unknown, []MsgKey{"strating"}) || !slices.Equal(r.BadFormat, []MsgKey{MsgError}) {
+				t.Errorf("en report: unknown %v, bad format %v", r.Unknown, r.BadFormat)
+			}
+		case LangKorean:
+			if len(r.BadFormat) != 0 || len(r.Missing) != 0 || !slices.Equal(r.Fallback, []Language{LangEnglish}) {
+				t.Errorf("ko report: bad format %v, missing %d, fallback %v", r.BadFormat, len(r.Missing), r.Fallback)
+			}
+		default:
+			if len(r.Unknown) != 0 || len(r.BadFormat) != 0 {
+				t.Errorf("%s report: unknown %v, bad format %v", r.Lang, r.Unknown, r.BadFormat)
+			}
+		}
+	}
+}
@@ -64,6 +64,15 @@ type SessionEnvInjector interface {
 	SetSessionEnv(env []string)
 }
 
+// ProviderKeyEnvProvider is an optional interface for agents that pass the
+// API key of the active provider to the CLI through environment variables.
+// ProviderKeyEnv returns the KEY=VALUE entries that make a session use key
+// instead; the engine adds them to the session env, so rotating keys never
+// changes the agent's providers.
+type ProviderKeyEnvProvider interface {
+	ProviderKeyEnv(key string) []string
+}
+
 // FormattingInstructionProvider is an optional interface for platforms that
 // provide platform-specific formatting instructions for the agent system prompt
 // (e.g., Slack mrkdwn vs standard Markdown).
@@ -341,6 +350,14 @@ type InlineButtonSender interface {
 	SendWithButtons(ctx context.Context, replyCtx any, content string, buttons [][]ButtonOption) error
 }
 
+// InlineButtonUpdater is the MessageUpdater counterpart for messages sent
+// with SendWithButtons: it replaces the text and buttons of the message
+// replyCtx refers to. The engine only uses it for messages with FromButton
+// set, where replyCtx identifies the message whose button was pressed.
+type InlineButtonUpdater interface {
+	UpdateWithButtons(ctx context.Context, replyCtx any, content string, buttons [][]ButtonOption) error
+}
+
 // CardSender is an optional interface for platforms that support sending
 // structured rich cards (e.g. Feishu Interactive Card). Platforms that do not
 // implement this interface will receive a plain-text fallback via Card.RenderText().
@@ -454,6 +471,14 @@ type ProviderConfig struct {
 	Models   []ModelOption     // pre-configured list of available models for this provider
 	Thinking string            // override thinking type sent to this provider ("disabled", "enabled", or "" for no rewrite)
 	Env      map[string]string // arbitrary extra env vars (e.g. CLAUDE_CODE_USE_BEDROCK=1)
+	// Key pool: extra keys rotated per new agent session alongside APIKey.
+	APIKeys     []string
+	APIKeyEnv   string        // env var that receives the rotated key (default: APIKey)
+	KeyRotation string        // KeyRotationRoundRobin (default) or KeyRotationLeastLimited
+	KeyCooldown time.Duration // how long a rate-limited key is skipped (0 = DefaultAPIKeyCooldown)
+	// Proxy holds request rewrites applied by the local ProviderProxy
+	// (Claude Code only); Thinking is merged into it by the agent.
+	Proxy ProxyRules
 	// Codex-specific provider config (maps to Codex model_providers.<name>)
 	CodexWireAPI     string            // wire API format (e.g. "responses")
 	CodexHTTPHeaders map[string]string // custom HTTP headers
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -49,22 +50,28 @@ type ManagementServer struct {
 	heartbeatScheduler *HeartbeatScheduler
 	bridgeServer       *BridgeServer
 
-	setupFeishuSave      func(req FeishuSetupSaveRequest) error
-	setupWeixinSave      func(req WeixinSetupSaveRequest) error
-	addPlatformToProject func(projectName, platType string, opts map[string]any, workDir, agentType string) error
-	removeProject        func(projectName string) error
-	saveProjectSettings  func(projectName string, update ProjectSettingsUpdate) error
+	setupFeishuSave      func(ctx context.Context, req FeishuSetupSaveRequest) error
+	setupWeixinSave      func(ctx context.Context, req WeixinSetupSaveRequest) error
+	addPlatformToProject func(ctx context.Context, projectName, platType string, opts map[string]any, workDir, agentType string) error
+	removeProject        func(ctx context.Context, projectName string) error
+	saveProjectSettings  func(ctx context.Context, projectName string, update ProjectSettingsUpdate) error
 	getProjectConfig     func(projectName string) map[string]any
-	saveProviderRefs     func(projectName string, refs []string) error
+	saveProviderRefs     func(ctx context.Context, projectName string, refs []string) error
 	configFilePath       string
 	getGlobalSettings    func() map[string]any
-	saveGlobalSettings   func(map[string]any) error
+	saveGlobalSettings   func(context.Context, map[string]any) error
+	reloadConfig         func() (*ConfigReloadResult, error)
+
+	// Config history callbacks (set by cmd/cc-connect)
+	configHistory  func() ([]ConfigVersionInfo, error)
+	configDiff     func(from, to int) (string, error)
+	configRollback func(ctx context.Context, id int) error
 
 	// Global provider callbacks (set by cmd/cc-connect)
 	listGlobalProviders  func() ([]GlobalProviderInfo, error)
-	addGlobalProvider    func(GlobalProviderInfo) error
-	updateGlobalProvider func(name string, info GlobalProviderInfo) error
-	removeGlobalProvider func(name string) error
+	addGlobalProvider    func(context.Context, GlobalProviderInfo) error
+	updateGlobalProvider func(ctx context.Context, name string, info GlobalProviderInfo) error
+	removeGlobalProvider func(ctx context.Context, name string) error
 	fetchPresets         func() (*ProviderPresetsResponse, error)
 	fetchSkillPresets    func() (*SkillPresetsResponse, error)
 
@@ -89,22 +96,35 @@ func (m *ManagementServer) RegisterEngine(name string, e *Engine) {
 	m.engines[name] = e
 }
 
+// UnregisterEngine removes a project that was dropped by a config reload.
+func (m *ManagementServer) UnregisterEngine(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.engines, name)
+}
+
+// SetReloadFunc sets the process-wide config reload used by POST /reload.
+// Without it each engine's own reload callback is called instead.
+func (m *ManagementServer) SetReloadFunc(fn func() (*ConfigReloadResult, error)) {
+	m.reloadConfig = fn
+}
+
 func (m *ManagementServer) SetCronScheduler(cs *CronScheduler)           { m.cronScheduler = cs }
 func (m *ManagementServer) SetTimerScheduler(ts *TimerScheduler)         { m.timerScheduler = ts }
 func (m *ManagementServer) SetHeartbeatScheduler(hs *HeartbeatScheduler) { m.heartbeatScheduler = hs }
 func (m *ManagementServer) SetBridgeServer(bs *BridgeServer)             { m.bridgeServer = bs }
-func (m *ManagementServer) SetSetupFeishuSave(fn func(FeishuSetupSaveRequest) error) {
+func (m *ManagementServer) SetSetupFeishuSave(fn func(context.Context, FeishuSetupSaveRequest) error) {
 	m.setupFeishuSave = fn
 }
-func (m *ManagementServer) SetSetupWeixinSave(fn func(WeixinSetupSaveRequest) error) {
+func (m *ManagementServer) SetSetupWeixinSave(fn func(context.Context, WeixinSetupSaveRequest) error) {
 	m.setupWeixinSave = fn
 }
 
-func (m *ManagementServer) SetAddPlatformToProject(fn func(string, string, map[string]any, string, string) error) {
+func (m *ManagementServer) SetAddPlatformToProject(fn func(context.Context, string, string, map[string]any, string, string) error) {
 	m.addPlatformToProject = fn
 }
 
-func (m *ManagementServer) SetRemoveProject(fn func(string) error) {
+func (m *ManagementServer) SetRemoveProject(fn func(context.Context, string) error) {
 	m.removeProject = fn
 }
 
@@ -112,7 +132,7 @@ func (m *ManagementServer) SetConfigFilePath(path string) {
 	m.configFilePath = path
 }
 
-func (m *ManagementServer) SetSaveProjectSettings(fn func(string, ProjectSettingsUpdate) error) {
+func (m *ManagementServer) SetSaveProjectSettings(fn func(context.Context, string, ProjectSettingsUpdate) error) {
 	m.saveProjectSettings = fn
 }
 
@@ -120,15 +140,23 @@ func (m *ManagementServer) SetGetProjectConfig(fn func(string) map[string]any) {
 	m.getProjectConfig = fn
 }
 
-func (m *ManagementServer) SetSaveProviderRefs(fn func(string, []string) error) {
+func (m *ManagementServer) SetSaveProviderRefs(fn func(context.Context, string, []string) error) {
 	m.saveProviderRefs = fn
 }
 
+// SetConfigHistoryFuncs registers the callbacks behind /config/history and
+// /config/rollback. diff with to == 0 shows what change from did.
+func (m *ManagementServer) SetConfigHistoryFuncs(list func() ([]ConfigVersionInfo, error), diff func(from, to int) (string, error), rollback func(ctx context.Context, id int) error) {
+	m.configHistory = list
+	m.configDiff = diff
+	m.configRollback = rollback
+}
+
 func (m *ManagementServer) SetGetGlobalSettings(fn func() map[string]any) {
 	m.getGlobalSettings = fn
 }
 
-func (m *ManagementServer) SetSaveGlobalSettings(fn func(map[string]any) error) {
+func (m *ManagementServer) SetSaveGlobalSettings(fn func(context.Context, map[string]any) error) {
 	m.saveGlobalSettings = fn
 }
 
@@ -149,6 +177,8 @@ type GlobalProviderInfo struct {
 	AgentModels     map[string]string              `json:"agent_models,omitempty"`
 	AgentModelLists map[string][]GlobalModelEntry   `json:"agent_model_lists,omitempty"`
 	Codex           *GlobalCodexConfig              `json:"codex,omitempty"`
+	// Health is filled in responses from the projects' health probes.
+	Health *ProviderHealth `json:"health,omitempty"`
 }
 
 // GlobalModelEntry is a model entry inside AgentModelLists.
@@ -166,13 +196,13 @@ type GlobalCodexConfig struct {
 func (m *ManagementServer) SetListGlobalProviders(fn func() ([]GlobalProviderInfo, error)) {
 	m.listGlobalProviders = fn
 }
-func (m *ManagementServer) SetAddGlobalProvider(fn func(GlobalProviderInfo) error) {
+func (m *ManagementServer) SetAddGlobalProvider(fn func(context.Context, GlobalProviderInfo) error) {
 	m.addGlobalProvider = fn
 }
-func (m *ManagementServer) SetUpdateGlobalProvider(fn func(string, GlobalProviderInfo) error) {
+func (m *ManagementServer) SetUpdateGlobalProvider(fn func(context.Context, string, GlobalProviderInfo) error) {
 	m.updateGlobalProvider = fn
 }
-func (m *ManagementServer) SetRemoveGlobalProvider(fn func(string) error) {
+func (m *ManagementServer) SetRemoveGlobalProvider(fn func(context.Context, string) error) {
 	m.removeGlobalProvider = fn
 }
 func (m *ManagementServer) SetFetchPresets(fn func() (*ProviderPresetsResponse, error)) {
@@ -219,7 +249,10 @@ func (m *ManagementServer) buildHandler(mux *http.ServeMux) http.Handler {
 	mux.HandleFunc(prefix+"/restart", m.wrap(m.handleRestart))
 	mux.HandleFunc(prefix+"/reload", m.wrap(m.handleReload))
 	mux.HandleFunc(prefix+"/config", m.wrap(m.handleConfig))
-	mux.HandleFunc(prefix+"/settings", m.wrap(m.handleGlobalSettings))
+	mux.HandleFunc(prefix+"/config/history", m.wrap(m.handleConfigHistory))
+	mux.HandleFunc(prefix+"/config/history/", m.wrap(m.handleConfigHistory))
+	mux.HandleFunc(prefix+"/config/rollback", m.wrap(m.recordConfigChange(m.handleConfigRollback)))
+	mux.HandleFunc(prefix+"/settings", m.wrap(m.recordConfigChange(m.handleGlobalSettings)))
 
 	// Agents & Platforms (registry)
 	mux.HandleFunc(prefix+"/agents", m.wrap(m.handleAgents))
@@ -235,14 +268,14 @@ func (m *ManagementServer) buildHandler(mux *http.ServeMux) http.Handler {
 	// Setup (QR onboarding for feishu/weixin)
 	mux.HandleFunc(prefix+"/setup/feishu/begin", m.wrap(m.handleSetupFeishuBegin))
 	mux.HandleFunc(prefix+"/setup/feishu/poll", m.wrap(m.handleSetupFeishuPoll))
-	mux.HandleFunc(prefix+"/setup/feishu/save", m.wrap(m.handleSetupFeishuSave))
+	mux.HandleFunc(prefix+"/setup/feishu/save", m.wrap(m.recordConfigChange(m.handleSetupFeishuSave)))
 	mux.HandleFunc(prefix+"/setup/weixin/begin", m.wrap(m.handleSetupWeixinBegin))
 	mux.HandleFunc(prefix+"/setup/weixin/poll", m.wrap(m.handleSetupWeixinPoll))
-	mux.HandleFunc(prefix+"/setup/weixin/save", m.wrap(m.handleSetupWeixinSave))
+	mux.HandleFunc(prefix+"/setup/weixin/save", m.wrap(m.recordConfigChange(m.handleSetupWeixinSave)))
 
 	// Global Providers
-	mux.HandleFunc(prefix+"/providers", m.wrap(m.handleGlobalProviders))
-	mux.HandleFunc(prefix+"/providers/", m.wrap(m.handleGlobalProviderRoutes))
+	mux.HandleFunc(prefix+"/providers", m.wrap(m.recordConfigChange(m.handleGlobalProviders)))
+	mux.HandleFunc(prefix+"/providers/", m.wrap(m.recordConfigChange(m.handleGlobalProviderRoutes)))
 
 	// Skills
 	mux.HandleFunc(prefix+"/skills", m.wrap(m.handleSkills))
@@ -322,6 +355,28 @@ func (m *ManagementServer) wrap(handler http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// recordConfigChange attributes config writes made by a mutating request
+// to the API client in the config history.
+func (m *ManagementServer) recordConfigChange(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			r = withConfigChange(r)
+		}
+		handler(w, r)
+	}
+}
+
+// withConfigChange attributes the config writes made while serving r to
+// the API client. Clients may name themselves with the X-CC-Connect-Client
+// header (the web UI sends "web").
+func withConfigChange(r *http.Request) *http.Request {
+	actor := "api"
+	if client := strings.TrimSpace(r.Header.Get("X-CC-Connect-Client")); client != "" {
+		actor += ":" + client
+	}
+	return r.WithContext(WithConfigChange(r.Context(), ConfigChange{Actor: actor, Reason: r.Method + " " + r.URL.Path}))
+}
+
 func (m *ManagementServer) authenticate(r *http.Request) bool {
 	if m.token == "" {
 		return true
@@ -346,7 +401,7 @@ func (m *ManagementServer) setCORS(w http.ResponseWriter, r *http.Request) {
 		if o == "*" || o == origin {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-CC-Connect-Client")
 			w.Header().Set("Access-Control-Max-Age", "86400")
 			break
 		}
@@ -403,7 +458,7 @@ func (m *ManagementServer) handleStatus(w http.ResponseWriter, r *http.Request)
 
 	platformSet := make(map[string]bool)
 	for _, e := range m.engines {
-		for _, p := range e.platforms {
+		for _, p := range e.platformList() {
 			platformSet[p.Name()] = true
 		}
 	}
@@ -464,6 +519,35 @@ func (m *ManagementServer) handleReload(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if m.reloadConfig != nil {
+		// Called without m.mu: a reload registers and unregisters engines.
+		result, err := m.reloadConfig()
+		if err != nil {
+			mgmtError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		m.mu.RLock()
+		updated := make([]string, 0, len(m.engines))
+		for name := range m.engines {
+			updated = append(updated, name)
+		}
+		m.mu.RUnlock()
+		sort.Strings(updated)
+		mgmtJSON(w, http.StatusOK, map[string]any{
+			"message":           "config reloaded",
+			"projects_updated":  updated,
+			"projects_added":    nonNilStrings(result.ProjectsAdded),
+			"projects_removed":  nonNilStrings(result.ProjectsRemoved),
+			"projects_rebuilt":  nonNilStrings(result.ProjectsRebuilt),
+			"platforms_started": nonNilStrings(result.PlatformsStarted),
+			"platforms_stopped": nonNilStrings(result.PlatformsStopped),
+			"agents_swapped":    nonNilStrings(result.AgentsSwapped),
+			"restart_required":  nonNilStrings(result.RestartRequired),
+			"errors":            nonNilStrings(result.Errors),
+		})
+		return
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -484,6 +568,14 @@ func (m *ManagementServer) handleReload(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// nonNilStrings keeps empty lists as [] rather than null in JSON responses.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
 func (m *ManagementServer) handleConfig(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		mgmtError(w, http.StatusMethodNotAllowed, "GET only")
@@ -505,6 +597,85 @@ func (m *ManagementServer) handleConfig(w http.ResponseWriter, r *http.Request)
 	_, _ = w.Write(data)
 }
 
+// handleConfigHistory serves GET /config/history and
+// GET /config/history/{id}/diff[?to={id}].
+func (m *ManagementServer) handleConfigHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		mgmtError(w, http.StatusMethodNotAllowed, "GET only")
+		return
+	}
+	if m.configHistory == nil || m.configDiff == nil {
+		mgmtError(w, http.StatusServiceUnavailable, "config history not available")
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/config/history"), "/")
+	if rest == "" {
+		versions, err := m.configHistory()
+		if err != nil {
+			mgmtError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if versions == nil {
+			versions = []ConfigVersionInfo{}
+		}
+		mgmtJSON(w, http.StatusOK, map[string]any{"versions": versions})
+		return
+	}
+	idStr, sub, _ := strings.Cut(rest, "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 || sub != "diff" {
+		mgmtError(w, http.StatusNotFound, "not found")
+		return
+	}
+	to := 0
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil || to <= 0 {
+			mgmtError(w, http.StatusBadRequest, "invalid to")
+			return
+		}
+	}
+	diff, err := m.configDiff(id, to)
+	if err != nil {
+		mgmtError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	mgmtJSON(w, http.StatusOK, map[string]any{"id": id, "to": to, "diff": diff})
+}
+
+// handleConfigRollback serves POST /config/rollback {"version": n} and
+// reloads the config afterwards.
+func (m *ManagementServer) handleConfigRollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		mgmtError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+	if m.configRollback == nil {
+		mgmtError(w, http.StatusServiceUnavailable, "config history not available")
+		return
+	}
+	var body struct {
+		Version int `json:"version"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Version <= 0 {
+		mgmtError(w, http.StatusBadRequest, "version is required")
+		return
+	}
+	if err := m.configRollback(r.Context(), body.Version); err != nil {
+		mgmtError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp := map[string]any{"message": fmt.Sprintf("config restored to version #%d", body.Version), "version": body.Version}
+	if m.reloadConfig != nil {
+		if result, err := m.reloadConfig(); err != nil {
+			resp["reload_error"] = err.Error()
+		} else {
+			resp["errors"] = nonNilStrings(result.Errors)
+			resp["restart_required"] = nonNilStrings(result.RestartRequired)
+		}
+	}
+	mgmtJSON(w, http.StatusOK, resp)
+}
+
 func (m *ManagementServer) handleGlobalSettings(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -524,7 +695,7 @@ func (m *ManagementServer) handleGlobalSettings(w http.ResponseWriter, r *http.R
 			mgmtError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
 			return
 		}
-		if err := m.saveGlobalSettings(updates); err != nil {
+		if err := m.saveGlobalSettings(r.Context(), updates); err != nil {
 			mgmtError(w, http.StatusInternalServerError, "save: "+err.Error())
 			return
 		}
@@ -551,8 +722,8 @@ func (m *ManagementServer) handleProjects(w http.ResponseWriter, r *http.Request
 
 	projects := make([]map[string]any, 0, len(m.engines))
 	for name, e := range m.engines {
-		platNames := make([]string, len(e.platforms))
-		for i, p := range e.platforms {
+		platNames := make([]string, len(e.platformList()))
+		for i, p := range e.platformList() {
 			platNames[i] = p.Name()
 		}
 
@@ -560,14 +731,14 @@ func (m *ManagementServer) handleProjects(w http.ResponseWriter, r *http.Request
 
 		hbEnabled := false
 		if m.heartbeatScheduler != nil {
-			if st := m.heartbeatScheduler.Status(name); st != nil {
-				hbEnabled = st.Enabled
+			for _, st := range m.heartbeatScheduler.List(name) {
+				hbEnabled = hbEnabled || st.Enabled
 			}
 		}
 
 		projects = append(projects, map[string]any{
 			"name":              name,
-			"agent_type":        e.agent.Name(),
+			"agent_type":        e.GetAgent().Name(),
 			"platforms":         platNames,
 			"sessions_count":    sessCount,
 			"heartbeat_enabled": hbEnabled,
@@ -576,6 +747,17 @@ func (m *ManagementServer) handleProjects(w http.ResponseWriter, r *http.Request
 	mgmtJSON(w, http.StatusOK, map[string]any{"projects": projects})
 }
 
+// projectConfigRoutes are the project sub-routes that may write config;
+// sends and session changes are not attributed.
+var projectConfigRoutes = map[string]bool{
+	"":              true,
+	"add-platform":  true,
+	"providers":     true,
+	"provider-refs": true,
+	"model":         true,
+	"users":         true,
+}
+
 // handleProjectRoutes dispatches /api/v1/projects/{name}/...
 func (m *ManagementServer) handleProjectRoutes(w http.ResponseWriter, r *http.Request) {
 	// Parse: /api/v1/projects/{name}[/sub[/subsub]]
@@ -597,6 +779,10 @@ func (m *ManagementServer) handleProjectRoutes(w http.ResponseWriter, r *http.Re
 		rest = parts[2]
 	}
 
+	if r.Method != http.MethodGet && projectConfigRoutes[sub] {
+		r = withConfigChange(r)
+	}
+
 	// add-platform writes config only; it does not need a running engine
 	// and must work for brand-new projects that have no engine yet.
 	if sub == "add-platform" {
@@ -638,8 +824,8 @@ func (m *ManagementServer) handleProjectRoutes(w http.ResponseWriter, r *http.Re
 
 func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Request, name string, e *Engine) {
 	if r.Method == http.MethodGet {
-		platInfos := make([]map[string]any, len(e.platforms))
-		for i, p := range e.platforms {
+		platInfos := make([]map[string]any, len(e.platformList()))
+		for i, p := range e.platformList() {
 			platInfos[i] = map[string]any{
 				"type":      p.Name(),
 				"connected": true,
@@ -658,21 +844,32 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 
 		data := map[string]any{
 			"name":                name,
-			"agent_type":          e.agent.Name(),
+			"agent_type":          e.GetAgent().Name(),
 			"platforms":           platInfos,
 			"sessions_count":      sessCount,
 			"active_session_keys": keys,
 		}
 
 		if m.heartbeatScheduler != nil {
-			if st := m.heartbeatScheduler.Status(name); st != nil {
-				data["heartbeat"] = map[string]any{
+			hbSummary := func(st *HeartbeatStatus) map[string]any {
+				return map[string]any{
+					"name":          st.Name,
 					"enabled":       st.Enabled,
 					"paused":        st.Paused,
 					"interval_mins": st.IntervalMins,
 					"session_key":   st.SessionKey,
 				}
 			}
+			if st := m.heartbeatScheduler.Status(name, ""); st != nil {
+				data["heartbeat"] = hbSummary(st)
+			}
+			if list := m.heartbeatScheduler.List(name); len(list) > 0 {
+				hbs := make([]map[string]any, len(list))
+				for i, st := range list {
+					hbs[i] = hbSummary(st)
+				}
+				data["heartbeats"] = hbs
+			}
 		}
 
 		e.userRolesMu.RLock()
@@ -686,11 +883,11 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 		}
 
 		var workDir string
-		if wd, ok := e.agent.(interface{ GetWorkDir() string }); ok {
+		if wd, ok := e.GetAgent().(interface{ GetWorkDir() string }); ok {
 			workDir = wd.GetWorkDir()
 		}
 		var agentMode string
-		if am, ok := e.agent.(interface{ GetMode() string }); ok {
+		if am, ok := e.GetAgent().(interface{ GetMode() string }); ok {
 			agentMode = am.GetMode()
 		}
 		data["work_dir"] = workDir
@@ -736,17 +933,8 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 		}
 
 		if body.Language != nil {
-			switch *body.Language {
-			case "en":
-				e.i18n.SetLang(LangEnglish)
-			case "zh":
-				e.i18n.SetLang(LangChinese)
-			case "zh-TW":
-				e.i18n.SetLang(LangTraditionalChinese)
-			case "ja":
-				e.i18n.SetLang(LangJapanese)
-			case "es":
-				e.i18n.SetLang(LangSpanish)
+			if lang, ok := ParseLanguage(*body.Language); ok && lang != LangAuto {
+				e.i18n.SetLang(lang)
 			}
 		}
 		if body.AdminFrom != nil {
@@ -756,12 +944,12 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 			e.SetDisabledCommands(body.DisabledCommands)
 		}
 		if body.WorkDir != nil {
-			if switcher, ok := e.agent.(WorkDirSwitcher); ok {
+			if switcher, ok := e.GetAgent().(WorkDirSwitcher); ok {
 				switcher.SetWorkDir(*body.WorkDir)
 			}
 		}
 		if body.Mode != nil {
-			if switcher, ok := e.agent.(ModeSwitcher); ok {
+			if switcher, ok := e.GetAgent().(ModeSwitcher); ok {
 				switcher.SetMode(*body.Mode)
 			}
 		}
@@ -779,7 +967,7 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 		}
 
 		restartRequired := false
-		if body.AgentType != nil && *body.AgentType != e.agent.Name() {
+		if body.AgentType != nil && *body.AgentType != e.GetAgent().Name() {
 			registered := ListRegisteredAgents()
 			found := false
 			for _, a := range registered {
@@ -809,7 +997,7 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 				InjectSender:         body.InjectSender,
 				PlatformAllowFrom:    body.PlatformAllowFrom,
 			}
-			if err := m.saveProjectSettings(name, patch); err != nil {
+			if err := m.saveProjectSettings(r.Context(), name, patch); err != nil {
 				slog.Warn("management: failed to persist project settings", "project", name, "error", err)
 			}
 		}
@@ -827,7 +1015,7 @@ func (m *ManagementServer) handleProjectDetail(w http.ResponseWriter, r *http.Re
 			mgmtError(w, http.StatusNotImplemented, "project removal not configured")
 			return
 		}
-		if err := m.removeProject(name); err != nil {
+		if err := m.removeProject(r.Context(), name); err != nil {
 			mgmtError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -1147,7 +1335,7 @@ func (m *ManagementServer) handleProjectSend(w http.ResponseWriter, r *http.Requ
 // ── Provider endpoints ────────────────────────────────────────
 
 func (m *ManagementServer) handleProjectProviders(w http.ResponseWriter, r *http.Request, e *Engine, rest string) {
-	ps, ok := e.agent.(ProviderSwitcher)
+	ps, ok := e.GetAgent().(ProviderSwitcher)
 	if !ok {
 		mgmtError(w, http.StatusBadRequest, "agent does not support provider switching")
 		return
@@ -1162,13 +1350,20 @@ func (m *ManagementServer) handleProjectProviders(w http.ResponseWriter, r *http
 			action = parts[1]
 		}
 		if action == "activate" && r.Method == http.MethodPost {
+			if e.providerCircuitOpen(provName) && r.URL.Query().Get("force") != "true" {
+				mgmtError(w, http.StatusConflict, fmt.Sprintf("provider %s circuit is open; retry with ?force=true to activate anyway", provName))
+				return
+			}
 			if !ps.SetActiveProvider(provName) {
 				mgmtError(w, http.StatusNotFound, fmt.Sprintf("provider not found: %s", provName))
 				return
 			}
 			e.resetAllSessions()
+			if f := e.providerFailover.Load(); f != nil {
+				f.reset(ps)
+			}
 			if e.providerSaveFunc != nil {
-				_ = e.providerSaveFunc(provName)
+				_ = e.providerSaveFunc(r.Context(), provName)
 			}
 			mgmtJSON(w, http.StatusOK, map[string]any{
 				"active_provider": provName,
@@ -1198,7 +1393,7 @@ func (m *ManagementServer) handleProjectProviders(w http.ResponseWriter, r *http
 			}
 			ps.SetProviders(remaining)
 			if e.providerRemoveSaveFunc != nil {
-				_ = e.providerRemoveSaveFunc(provName)
+				_ = e.providerRemoveSaveFunc(r.Context(), provName)
 			}
 			mgmtOK(w, "provider removed")
 			return
@@ -1216,6 +1411,7 @@ func (m *ManagementServer) handleProjectProviders(w http.ResponseWriter, r *http
 		if current != nil {
 			activeName = current.Name
 		}
+		health := e.ProviderHealth()
 		for i, p := range providers {
 			provList[i] = map[string]any{
 				"name":     p.Name,
@@ -1223,6 +1419,12 @@ func (m *ManagementServer) handleProjectProviders(w http.ResponseWriter, r *http
 				"model":    p.Model,
 				"base_url": p.BaseURL,
 			}
+			if health != nil {
+				provList[i]["health"] = health.Health(p.Name)
+			}
+			if keys := e.ProviderKeyUsage(p); keys != nil {
+				provList[i]["keys"] = keys
+			}
 		}
 		mgmtJSON(w, http.StatusOK, map[string]any{
 			"providers":       provList,
@@ -1258,7 +1460,7 @@ func (m *ManagementServer) handleProjectProviders(w http.ResponseWriter, r *http
 		providers = append(providers, prov)
 		ps.SetProviders(providers)
 		if e.providerAddSaveFunc != nil {
-			_ = e.providerAddSaveFunc(prov)
+			_ = e.providerAddSaveFunc(r.Context(), prov)
 		}
 		mgmtJSON(w, http.StatusOK, map[string]any{
 			"name":    body.Name,
@@ -1296,12 +1498,12 @@ func (m *ManagementServer) handleProjectProviderRefs(w http.ResponseWriter, r *h
 			mgmtError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
 			return
 		}
-		if err := m.saveProviderRefs(projName, body.ProviderRefs); err != nil {
+		if err := m.saveProviderRefs(r.Context(), projName, body.ProviderRefs); err != nil {
 			mgmtError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		// Reload providers into the running engine, resolving per-agent overrides
-		ps, ok := e.agent.(ProviderSwitcher)
+		ps, ok := e.GetAgent().(ProviderSwitcher)
 		if ok && m.listGlobalProviders != nil {
 			globals, _ := m.listGlobalProviders()
 			globalMap := make(map[string]GlobalProviderInfo, len(globals))
@@ -1313,7 +1515,7 @@ func (m *ManagementServer) handleProjectProviderRefs(w http.ResponseWriter, r *h
 			for _, p := range existing {
 				existingNames[p.Name] = true
 			}
-			agentType := e.agent.Name()
+			agentType := e.GetAgent().Name()
 			for _, ref := range body.ProviderRefs {
 				if existingNames[ref] {
 					continue
@@ -1335,7 +1537,7 @@ func (m *ManagementServer) handleProjectModels(w http.ResponseWriter, r *http.Re
 		mgmtError(w, http.StatusMethodNotAllowed, "GET only")
 		return
 	}
-	ms, ok := e.agent.(ModelSwitcher)
+	ms, ok := e.GetAgent().(ModelSwitcher)
 	if !ok {
 		mgmtError(w, http.StatusBadRequest, "agent does not support model switching")
 		return
@@ -1358,7 +1560,7 @@ func (m *ManagementServer) handleProjectModel(w http.ResponseWriter, r *http.Req
 		mgmtError(w, http.StatusMethodNotAllowed, "POST only")
 		return
 	}
-	if _, ok := e.agent.(ModelSwitcher); !ok {
+	if _, ok := e.GetAgent().(ModelSwitcher); !ok {
 		mgmtError(w, http.StatusBadRequest, "agent does not support model switching")
 		return
 	}
@@ -1373,7 +1575,7 @@ func (m *ManagementServer) handleProjectModel(w http.ResponseWriter, r *http.Req
 		mgmtError(w, http.StatusBadRequest, "model is required")
 		return
 	}
-	model, err := e.switchModel(body.Model)
+	model, err := e.switchModel(r.Context(), body.Model)
 	if err != nil {
 		mgmtError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -1386,38 +1588,77 @@ func (m *ManagementServer) handleProjectModel(w http.ResponseWriter, r *http.Req
 
 // ── Heartbeat endpoints ───────────────────────────────────────
 
+// heartbeatStatusJSON is the API representation of one heartbeat.
+func heartbeatStatusJSON(st *HeartbeatStatus) map[string]any {
+	data := map[string]any{
+		"name":           st.Name,
+		"enabled":        st.Enabled,
+		"paused":         st.Paused,
+		"interval_mins":  st.IntervalMins,
+		"only_when_idle": st.OnlyWhenIdle,
+		"session_key":    st.SessionKey,
+		"silent":         st.Silent,
+		"run_count":      st.RunCount,
+		"error_count":    st.ErrorCount,
+		"skipped_busy":   st.SkippedBusy,
+		"last_error":     st.LastError,
+	}
+	if !st.LastRun.IsZero() {
+		data["last_run"] = st.LastRun.Format(time.RFC3339)
+	}
+	return data
+}
+
+// handleProjectHeartbeat serves /projects/{name}/heartbeat[/{hbname}][/action].
+// Without a heartbeat name the default (or only) heartbeat is addressed, and
+// the status response also lists every heartbeat of the project.
 func (m *ManagementServer) handleProjectHeartbeat(w http.ResponseWriter, r *http.Request, projName, rest string) {
 	if m.heartbeatScheduler == nil {
 		mgmtError(w, http.StatusServiceUnavailable, "heartbeat scheduler not available")
 		return
 	}
 
-	switch rest {
+	hbName, action := "", rest
+	if first, after, _ := strings.Cut(rest, "/"); first != "" {
+		switch first {
+		case "status", "pause", "resume", "run", "interval":
+		default:
+			hbName, action = first, after
+		}
+	}
+	notFound := func() {
+		if hbName != "" {
+			mgmtError(w, http.StatusNotFound, fmt.Sprintf("heartbeat not found: %s", hbName))
+			return
+		}
+		mgmtError(w, http.StatusNotFound, "heartbeat not found for project")
+	}
+
+	switch action {
 	case "", "status":
 		if r.Method != http.MethodGet {
 			mgmtError(w, http.StatusMethodNotAllowed, "GET only")
 			return
 		}
-		st := m.heartbeatScheduler.Status(projName)
-		if st == nil {
-			mgmtJSON(w, http.StatusOK, map[string]any{"enabled": false})
+		st := m.heartbeatScheduler.Status(projName, hbName)
+		if hbName != "" {
+			if st == nil {
+				notFound()
+				return
+			}
+			mgmtJSON(w, http.StatusOK, heartbeatStatusJSON(st))
 			return
 		}
-		data := map[string]any{
-			"enabled":        st.Enabled,
-			"paused":         st.Paused,
-			"interval_mins":  st.IntervalMins,
-			"only_when_idle": st.OnlyWhenIdle,
-			"session_key":    st.SessionKey,
-			"silent":         st.Silent,
-			"run_count":      st.RunCount,
-			"error_count":    st.ErrorCount,
-			"skipped_busy":   st.SkippedBusy,
-			"last_error":     st.LastError,
-		}
-		if !st.LastRun.IsZero() {
-			data["last_run"] = st.LastRun.Format(time.RFC3339)
+		data := map[string]any{"enabled": false}
+		if st != nil {
+			data = heartbeatStatusJSON(st)
+		}
+		list := m.heartbeatScheduler.List(projName)
+		hbs := make([]map[string]any, len(list))
+		for i, hb := range list {
+			hbs[i] = heartbeatStatusJSON(hb)
 		}
+		data["heartbeats"] = hbs
 		mgmtJSON(w, http.StatusOK, data)
 
 	case "pause":
@@ -1425,10 +1666,10 @@ func (m *ManagementServer) handleProjectHeartbeat(w http.ResponseWriter, r *http
 			mgmtError(w, http.StatusMethodNotAllowed, "POST only")
 			return
 		}
-		if m.heartbeatScheduler.Pause(projName) {
+		if m.heartbeatScheduler.Pause(projName, hbName) {
 			mgmtOK(w, "heartbeat paused")
 		} else {
-			mgmtError(w, http.StatusNotFound, "heartbeat not found for project")
+			notFound()
 		}
 
 	case "resume":
@@ -1436,10 +1677,10 @@ func (m *ManagementServer) handleProjectHeartbeat(w http.ResponseWriter, r *http
 			mgmtError(w, http.StatusMethodNotAllowed, "POST only")
 			return
 		}
-		if m.heartbeatScheduler.Resume(projName) {
+		if m.heartbeatScheduler.Resume(projName, hbName) {
 			mgmtOK(w, "heartbeat resumed")
 		} else {
-			mgmtError(w, http.StatusNotFound, "heartbeat not found for project")
+			notFound()
 		}
 
 	case "run":
@@ -1447,10 +1688,10 @@ func (m *ManagementServer) handleProjectHeartbeat(w http.ResponseWriter, r *http
 			mgmtError(w, http.StatusMethodNotAllowed, "POST only")
 			return
 		}
-		if m.heartbeatScheduler.TriggerNow(projName) {
+		if m.heartbeatScheduler.TriggerNow(projName, hbName) {
 			mgmtOK(w, "heartbeat triggered")
 		} else {
-			mgmtError(w, http.StatusNotFound, "heartbeat not found for project")
+			notFound()
 		}
 
 	case "interval":
@@ -1469,13 +1710,13 @@ func (m *ManagementServer) handleProjectHeartbeat(w http.ResponseWriter, r *http
 			mgmtError(w, http.StatusBadRequest, "minutes must be >= 1")
 			return
 		}
-		if m.heartbeatScheduler.SetInterval(projName, body.Minutes) {
+		if m.heartbeatScheduler.SetInterval(projName, hbName, body.Minutes) {
 			mgmtJSON(w, http.StatusOK, map[string]any{
 				"interval_mins": body.Minutes,
 				"message":       "interval updated",
 			})
 		} else {
-			mgmtError(w, http.StatusNotFound, "heartbeat not found for project")
+			notFound()
 		}
 
 	default:
@@ -1537,20 +1778,29 @@ func (m *ManagementServer) handleCron(w http.ResponseWriter, r *http.Request) {
 		}
 
 		job := &CronJob{
-			ID:          GenerateCronID(),
-			Project:     project,
-			SessionKey:  req.SessionKey,
-			CronExpr:    req.CronExpr,
-			Prompt:      req.Prompt,
-			Exec:        req.Exec,
-			WorkDir:     req.WorkDir,
-			Description: req.Description,
-			Enabled:     true,
-			Silent:      req.Silent,
-			SessionMode: NormalizeCronSessionMode(req.SessionMode),
-			Mode:        req.Mode,
-			TimeoutMins: req.TimeoutMins,
-			CreatedAt:   time.Now(),
+			ID:           GenerateCronID(),
+			Project:      project,
+			SessionKey:   req.SessionKey,
+			CronExpr:     req.CronExpr,
+			Prompt:       req.Prompt,
+			Exec:         req.Exec,
+			WorkDir:      req.WorkDir,
+			Precheck:     req.Precheck,
+			Description:  req.Description,
+			Enabled:      true,
+			Silent:       req.Silent,
+			SessionMode:  NormalizeCronSessionMode(req.SessionMode),
+			Mode:         req.Mode,
+			TimeoutMins:  req.TimeoutMins,
+			Retries:      req.Retries,
+			RetryDelay:   req.RetryDelay,
+			OnFailure:    req.OnFailure,
+			Then:         req.Then,
+			Timezone:     req.Timezone,
+			ExcludeDates: req.ExcludeDates,
+			ExcludeICS:   req.ExcludeICS,
+			Jitter:       req.Jitter,
+			CreatedAt:    time.Now(),
 		}
 		if err := m.cronScheduler.AddJob(job); err != nil {
 			mgmtError(w, http.StatusBadRequest, err.Error())
@@ -1586,6 +1836,21 @@ func (m *ManagementServer) handleCronByID(w http.ResponseWriter, r *http.Request
 	}
 
 	switch r.Method {
+	case http.MethodGet:
+		if action != "runs" {
+			mgmtError(w, http.StatusNotFound, "unknown cron route")
+			return
+		}
+		if m.cronScheduler.Store().Get(id) == nil {
+			mgmtError(w, http.StatusNotFound, fmt.Sprintf("cron job not found: %s", id))
+			return
+		}
+		runs := m.cronScheduler.Store().Runs(id)
+		if runs == nil {
+			runs = []CronRun{}
+		}
+		mgmtJSON(w, http.StatusOK, map[string]any{"id": id, "runs": runs})
+
 	case http.MethodDelete:
 		if action != "" {
 			mgmtError(w, http.StatusNotFound, "unknown cron route")
@@ -1639,7 +1904,7 @@ func (m *ManagementServer) handleCronByID(w http.ResponseWriter, r *http.Request
 		})
 
 	default:
-		mgmtError(w, http.StatusMethodNotAllowed, "DELETE, PATCH, or POST only")
+		mgmtError(w, http.StatusMethodNotAllowed, "GET, DELETE, PATCH, or POST only")
 	}
 }
 
@@ -1689,6 +1954,25 @@ func (m *ManagementServer) listBridgeAdapters() []map[string]any {
 
 // ── Global provider endpoints ─────────────────────────────────
 
+// globalProviderHealth returns the most recently checked health of a global
+// provider across the projects that probe it, or nil when none does.
+func (m *ManagementServer) globalProviderHealth(name string) *ProviderHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var best *ProviderHealth
+	for _, e := range m.engines {
+		mon := e.ProviderHealth()
+		if mon == nil {
+			continue
+		}
+		h := mon.Health(name)
+		if best == nil || h.LastCheck.After(best.LastCheck) {
+			best = &h
+		}
+	}
+	return best
+}
+
 func (m *ManagementServer) handleGlobalProviders(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -1701,6 +1985,9 @@ func (m *ManagementServer) handleGlobalProviders(w http.ResponseWriter, r *http.
 			mgmtError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		for i := range providers {
+			providers[i].Health = m.globalProviderHealth(providers[i].Name)
+		}
 		mgmtJSON(w, http.StatusOK, map[string]any{"providers": providers})
 
 	case http.MethodPost:
@@ -1717,7 +2004,7 @@ func (m *ManagementServer) handleGlobalProviders(w http.ResponseWriter, r *http.
 			mgmtError(w, http.StatusBadRequest, "name is required")
 			return
 		}
-		if err := m.addGlobalProvider(body); err != nil {
+		if err := m.addGlobalProvider(r.Context(), body); err != nil {
 			if strings.Contains(err.Error(), "already exists") {
 				mgmtError(w, http.StatusConflict, err.Error())
 			} else {
@@ -1766,7 +2053,7 @@ func (m *ManagementServer) handleGlobalProviderRoutes(w http.ResponseWriter, r *
 			mgmtError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
 			return
 		}
-		if err := m.updateGlobalProvider(name, body); err != nil {
+		if err := m.updateGlobalProvider(r.Context(), name, body); err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				mgmtError(w, http.StatusNotFound, err.Error())
 			} else {
@@ -1781,7 +2068,7 @@ func (m *ManagementServer) handleGlobalProviderRoutes(w http.ResponseWriter, r *
 			mgmtError(w, http.StatusNotImplemented, "not configured")
 			return
 		}
-		if err := m.removeGlobalProvider(name); err != nil {
+		if err := m.removeGlobalProvider(r.Context(), name); err != nil {
 			if strings.Contains(err.Error(), "not found") {
 				mgmtError(w, http.StatusNotFound, err.Error())
 			} else {
@@ -1803,7 +2090,7 @@ func (m *ManagementServer) purgeProviderFromEngines(name string) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	for _, e := range m.engines {
-		ps, ok := e.agent.(ProviderSwitcher)
+		ps, ok := e.GetAgent().(ProviderSwitcher)
 		if !ok {
 			continue
 		}
@@ -1893,7 +2180,7 @@ func (m *ManagementServer) handleCCSwitchProviders(w http.ResponseWriter, r *htt
 			} else if src.AppType == "codex" {
 				gp.AgentTypes = []string{"codex"}
 			}
-			if err := m.addGlobalProvider(gp); err != nil {
+			if err := m.addGlobalProvider(r.Context(), gp); err != nil {
 				skipped = append(skipped, name)
 				continue
 			}
@@ -469,6 +469,44 @@ func TestMgmt_Reload(t *testing.T) {
 	}
 }
 
+func TestMgmt_Reload_UsesProcessReload(t *testing.T) {
+	mgmt, ts, e := testManagementServer(t, "tok")
+	e.configReloadFunc = func() (*ConfigReloadResult, error) {
+		t.Error("per-engine reload must not run when a process reload is set")
+		return &ConfigReloadResult{}, nil
+	}
+	mgmt.SetReloadFunc(func() (*ConfigReloadResult, error) {
+		// A reload registers engines; the handler must not hold m.mu.
+		mgmt.RegisterEngine("added", NewEngine("added", &stubAgent{}, nil, "", LangEnglish))
+		return &ConfigReloadResult{
+			ProjectsAdded:    []string{"added"},
+			PlatformsStopped: []string{"test/feishu"},
+			RestartRequired:  []string{"management"},
+		}, nil
+	})
+
+	r := mgmtPost(t, ts.URL+"/api/v1/reload", "tok", nil)
+	if !r.OK {
+		t.Fatalf("reload failed: %s", r.Error)
+	}
+	var data struct {
+		ProjectsAdded    []string `json:"projects_added"`
+		ProjectsRemoved  []string `json:"projects_removed"`
+		PlatformsStopped []string `json:"platforms_stopped"`
+		RestartRequired  []string `json:"restart_required"`
+		ProjectsUpdated  []string `json:"projects_updated"`
+	}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.ProjectsAdded) != 1 || data.ProjectsRemoved == nil || len(data.PlatformsStopped) != 1 || data.RestartRequired[0] != "management" {
+		t.Errorf("unexpected reload response: %+v", data)
+	}
+	if len(data.ProjectsUpdated) != 2 {
+		t.Errorf("projects_updated = %v, want both engines", data.ProjectsUpdated)
+	}
+}
+
 func TestMgmt_BridgeAdapters(t *testing.T) {
 	_, ts, _ := testManagementServer(t, "tok")
 
@@ -663,6 +701,61 @@ func TestMgmt_CronExecByID(t *testing.T) {
 	t.Fatalf("timed out waiting for triggered cron run alias, sent=%v", platform.getSent())
 }
 
+func TestMgmt_CronRunsByID(t *testing.T) {
+	mgmt, ts, e := testManagementServer(t, "tok")
+	store, err := NewCronStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := NewCronScheduler(store)
+	mgmt.SetCronScheduler(cs)
+
+	platform := &stubCronReplyTargetPlatform{
+		stubPlatformEngine: stubPlatformEngine{n: "discord"},
+	}
+	e.platforms = []Platform{platform}
+	e.agent = &resultAgent{session: newResultAgentSession("nightly summary")}
+	e.cronScheduler = cs
+	cs.RegisterEngine("test-project", e)
+
+	job := &CronJob{
+		ID:         "cron-runs-1",
+		Project:    "test-project",
+		SessionKey: "discord:channel-1:user-1",
+		CronExpr:   "0 9 * * *",
+		Prompt:     "summarize",
+		CreatedAt:  time.Now(),
+	}
+	if err := store.Add(job); err != nil {
+		t.Fatal(err)
+	}
+	cs.runJob(job, true)
+
+	r := mgmtGet(t, ts.URL+"/api/v1/cron/"+job.ID+"/runs", "tok")
+	if !r.OK {
+		t.Fatalf("cron runs failed: %s", r.Error)
+	}
+	var data struct {
+		ID   string    `json:"id"`
+		Runs []CronRun `json:"runs"`
+	}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		t.Fatalf("unmarshal runs response: %v", err)
+	}
+	if len(data.Runs) != 1 {
+		t.Fatalf("len(runs) = %d, want 1", len(data.Runs))
+	}
+	run := data.Runs[0]
+	if run.Error != "" || run.Output != "nightly summary" || !run.Manual {
+		t.Fatalf("unexpected run record: %+v", run)
+	}
+
+	missing := mgmtGet(t, ts.URL+"/api/v1/cron/nope/runs", "tok")
+	if missing.OK {
+		t.Fatal("expected unknown job to be rejected")
+	}
+}
+
 func TestMgmt_CronExecByID_RejectsExtraPathSegments(t *testing.T) {
 	mgmt, ts, e := testManagementServer(t, "tok")
 	store, err := NewCronStore(t.TempDir())
@@ -837,7 +930,7 @@ func TestMgmt_ProjectModel_UsesSwitchModelWithActiveProvider(t *testing.T) {
 	}
 	e := NewEngine("test-project", agent, nil, "", LangEnglish)
 	var savedProvider, savedModel string
-	e.SetProviderModelSaveFunc(func(providerName, model string) error {
+	e.SetProviderModelSaveFunc(func(_ context.Context, providerName, model string) error {
 		savedProvider = providerName
 		savedModel = model
 		return nil
@@ -880,11 +973,11 @@ func TestMgmt_ProjectModel_SavesModelWithoutActiveProvider(t *testing.T) {
 	e := NewEngine("test-project", agent, nil, "", LangEnglish)
 	var savedModel string
 	var providerSaveCalled bool
-	e.SetModelSaveFunc(func(model string) error {
+	e.SetModelSaveFunc(func(_ context.Context, model string) error {
 		savedModel = model
 		return nil
 	})
-	e.SetProviderModelSaveFunc(func(providerName, model string) error {
+	e.SetProviderModelSaveFunc(func(_ context.Context, providerName, model string) error {
 		providerSaveCalled = true
 		return nil
 	})
@@ -924,7 +1017,7 @@ func TestMgmt_ProjectModel_ReturnsErrorWhenModelSaveFails(t *testing.T) {
 		},
 	}
 	e := NewEngine("test-project", agent, nil, "", LangEnglish)
-	e.SetModelSaveFunc(func(model string) error {
+	e.SetModelSaveFunc(func(_ context.Context, model string) error {
 		return errors.New("disk full")
 	})
 
@@ -981,7 +1074,7 @@ func TestMgmt_RemoveGlobalProvider_PurgesFromEngines(t *testing.T) {
 	mgmt.RegisterEngine("proj", e)
 
 	removed := ""
-	mgmt.SetRemoveGlobalProvider(func(name string) error {
+	mgmt.SetRemoveGlobalProvider(func(_ context.Context, name string) error {
 		removed = name
 		return nil
 	})
@@ -1062,7 +1155,7 @@ func TestMgmt_AddPlatformToNewProject_DoesNotRequireEngine(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 
 	var savedProject, savedPlatType string
-	mgmt.SetAddPlatformToProject(func(proj, platType string, opts map[string]any, workDir, agentType string) error {
+	mgmt.SetAddPlatformToProject(func(_ context.Context, proj, platType string, opts map[string]any, workDir, agentType string) error {
 		savedProject = proj
 		savedPlatType = platType
 		return nil
@@ -1088,7 +1181,7 @@ func TestMgmt_AddPlatformToNewProject_RejectsMissingWorkDir(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 
 	called := false
-	mgmt.SetAddPlatformToProject(func(proj, platType string, opts map[string]any, workDir, agentType string) error {
+	mgmt.SetAddPlatformToProject(func(_ context.Context, proj, platType string, opts map[string]any, workDir, agentType string) error {
 		called = true
 		return nil
 	})
@@ -1116,7 +1209,7 @@ func TestMgmt_SetupSave_RejectsMissingWorkDir(t *testing.T) {
 	t.Run("feishu", func(t *testing.T) {
 		mgmt := NewManagementServer(0, "", nil)
 		called := false
-		mgmt.SetSetupFeishuSave(func(req FeishuSetupSaveRequest) error {
+		mgmt.SetSetupFeishuSave(func(_ context.Context, req FeishuSetupSaveRequest) error {
 			called = true
 			return nil
 		})
@@ -1141,7 +1234,7 @@ func TestMgmt_SetupSave_RejectsMissingWorkDir(t *testing.T) {
 	t.Run("weixin", func(t *testing.T) {
 		mgmt := NewManagementServer(0, "", nil)
 		called := false
-		mgmt.SetSetupWeixinSave(func(req WeixinSetupSaveRequest) error {
+		mgmt.SetSetupWeixinSave(func(_ context.Context, req WeixinSetupSaveRequest) error {
 			called = true
 			return nil
 		})
@@ -1311,7 +1404,7 @@ func TestMgmt_GlobalSettings_Patch(t *testing.T) {
 
 	saved := map[string]any{}
 	mgmt.SetGetGlobalSettings(func() map[string]any { return saved })
-	mgmt.SetSaveGlobalSettings(func(updates map[string]any) error {
+	mgmt.SetSaveGlobalSettings(func(_ context.Context, updates map[string]any) error {
 		for k, v := range updates {
 			saved[k] = v
 		}
@@ -1329,7 +1422,7 @@ func TestMgmt_GlobalSettings_Patch(t *testing.T) {
 
 func TestMgmt_GlobalSettings_PatchSaveError(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetSaveGlobalSettings(func(updates map[string]any) error {
+	mgmt.SetSaveGlobalSettings(func(_ context.Context, updates map[string]any) error {
 		return errors.New("write failed")
 	})
 	r := mgmtPatch(t, ts.URL+"/api/v1/settings", "tok", map[string]any{"x": 1})
@@ -1663,7 +1756,7 @@ func TestMgmt_GlobalProviders_PostNotConfigured(t *testing.T) {
 
 func TestMgmt_GlobalProviders_PostMissingName(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetAddGlobalProvider(func(info GlobalProviderInfo) error { return nil })
+	mgmt.SetAddGlobalProvider(func(_ context.Context, info GlobalProviderInfo) error { return nil })
 
 	r := mgmtPost(t, ts.URL+"/api/v1/providers", "tok", map[string]string{"api_key": "sk"})
 	if r.OK {
@@ -1674,7 +1767,7 @@ func TestMgmt_GlobalProviders_PostMissingName(t *testing.T) {
 func TestMgmt_GlobalProviders_PostSuccess(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 	var added string
-	mgmt.SetAddGlobalProvider(func(info GlobalProviderInfo) error {
+	mgmt.SetAddGlobalProvider(func(_ context.Context, info GlobalProviderInfo) error {
 		added = info.Name
 		return nil
 	})
@@ -1690,7 +1783,7 @@ func TestMgmt_GlobalProviders_PostSuccess(t *testing.T) {
 
 func TestMgmt_GlobalProviders_PostDuplicate(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetAddGlobalProvider(func(info GlobalProviderInfo) error {
+	mgmt.SetAddGlobalProvider(func(_ context.Context, info GlobalProviderInfo) error {
 		return errors.New("already exists: " + info.Name)
 	})
 
@@ -1711,7 +1804,7 @@ func TestMgmt_GlobalProviders_UpdateNotConfigured(t *testing.T) {
 func TestMgmt_GlobalProviders_UpdateSuccess(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 	var updated string
-	mgmt.SetUpdateGlobalProvider(func(name string, info GlobalProviderInfo) error {
+	mgmt.SetUpdateGlobalProvider(func(_ context.Context, name string, info GlobalProviderInfo) error {
 		updated = name
 		return nil
 	})
@@ -1727,7 +1820,7 @@ func TestMgmt_GlobalProviders_UpdateSuccess(t *testing.T) {
 
 func TestMgmt_GlobalProviders_DeleteNotFound(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetRemoveGlobalProvider(func(name string) error {
+	mgmt.SetRemoveGlobalProvider(func(_ context.Context, name string) error {
 		return errors.New("not found: " + name)
 	})
 
@@ -2035,7 +2128,7 @@ func TestMgmt_Config_MethodNotAllowed(t *testing.T) {
 
 func TestMgmt_GlobalSettings_PatchInvalidJSON(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetSaveGlobalSettings(func(updates map[string]any) error { return nil })
+	mgmt.SetSaveGlobalSettings(func(_ context.Context, updates map[string]any) error { return nil })
 
 	req, _ := http.NewRequest("PATCH", ts.URL+"/api/v1/settings", strings.NewReader("{bad json"))
 	req.Header.Set("Authorization", "Bearer tok")
@@ -2139,7 +2232,7 @@ func TestMgmt_ProjectPatch_RejectsMissingWorkDirBeforeMutation(t *testing.T) {
 	e.agent = agent
 
 	saveCalled := false
-	mgmt.SetSaveProjectSettings(func(projectName string, update ProjectSettingsUpdate) error {
+	mgmt.SetSaveProjectSettings(func(_ context.Context, projectName string, update ProjectSettingsUpdate) error {
 		saveCalled = true
 		return nil
 	})
@@ -2221,7 +2314,7 @@ func TestMgmt_ProjectDetail_MethodNotAllowed(t *testing.T) {
 func TestMgmt_ProjectDelete_Success(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 	var removed string
-	mgmt.SetRemoveProject(func(name string) error {
+	mgmt.SetRemoveProject(func(_ context.Context, name string) error {
 		removed = name
 		return nil
 	})
@@ -2241,7 +2334,7 @@ func TestMgmt_ProjectDelete_Success(t *testing.T) {
 
 func TestMgmt_ProjectDelete_Error(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetRemoveProject(func(name string) error {
+	mgmt.SetRemoveProject(func(_ context.Context, name string) error {
 		return errors.New("cannot remove last project")
 	})
 	r := mgmtDelete(t, ts.URL+"/api/v1/projects/test-project", "tok")
@@ -2447,7 +2540,7 @@ func TestMgmt_ProjectProviderRefs_PutInvalidJSON(t *testing.T) {
 	e := NewEngine("proj", agent, nil, "", LangEnglish)
 	mgmt := NewManagementServer(0, "tok", nil)
 	mgmt.RegisterEngine("proj", e)
-	mgmt.SetSaveProviderRefs(func(proj string, refs []string) error { return nil })
+	mgmt.SetSaveProviderRefs(func(_ context.Context, proj string, refs []string) error { return nil })
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v1/projects/", mgmt.wrap(mgmt.handleProjectRoutes))
 	ts := httptest.NewServer(mux)
@@ -2473,7 +2566,7 @@ func TestMgmt_ProjectProviderRefs_PutSaveError(t *testing.T) {
 	e := NewEngine("proj", agent, nil, "", LangEnglish)
 	mgmt := NewManagementServer(0, "tok", nil)
 	mgmt.RegisterEngine("proj", e)
-	mgmt.SetSaveProviderRefs(func(proj string, refs []string) error {
+	mgmt.SetSaveProviderRefs(func(_ context.Context, proj string, refs []string) error {
 		return errors.New("disk full")
 	})
 	mux := http.NewServeMux()
@@ -2498,8 +2591,10 @@ func TestMgmt_ProjectProviderRefs_PutSuccess(t *testing.T) {
 	mgmt := NewManagementServer(0, "tok", nil)
 	mgmt.RegisterEngine("proj", e)
 	var savedRefs []string
-	mgmt.SetSaveProviderRefs(func(proj string, refs []string) error {
+	var change ConfigChange
+	mgmt.SetSaveProviderRefs(func(ctx context.Context, proj string, refs []string) error {
 		savedRefs = refs
+		change, _ = ConfigChangeFrom(ctx)
 		return nil
 	})
 	mux := http.NewServeMux()
@@ -2516,6 +2611,9 @@ func TestMgmt_ProjectProviderRefs_PutSuccess(t *testing.T) {
 	if len(savedRefs) != 2 {
 		t.Fatalf("savedRefs = %v", savedRefs)
 	}
+	if change.Actor != "api" || change.Reason != "PUT /api/v1/projects/proj/provider-refs" {
+		t.Errorf("change attributed to %+v", change)
+	}
 }
 
 func TestMgmt_ProjectProviderRefs_MethodNotAllowed(t *testing.T) {
@@ -2598,7 +2696,7 @@ func TestMgmt_GlobalProviders_GetError(t *testing.T) {
 
 func TestMgmt_GlobalProviders_PostInvalidJSON(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetAddGlobalProvider(func(info GlobalProviderInfo) error { return nil })
+	mgmt.SetAddGlobalProvider(func(_ context.Context, info GlobalProviderInfo) error { return nil })
 
 	req, _ := http.NewRequest("POST", ts.URL+"/api/v1/providers", strings.NewReader("{bad"))
 	req.Header.Set("Authorization", "Bearer tok")
@@ -2625,7 +2723,7 @@ func TestMgmt_GlobalProviders_MethodNotAllowed(t *testing.T) {
 
 func TestMgmt_GlobalProviders_UpdateNotFound(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetUpdateGlobalProvider(func(name string, info GlobalProviderInfo) error {
+	mgmt.SetUpdateGlobalProvider(func(_ context.Context, name string, info GlobalProviderInfo) error {
 		return errors.New("not found: " + name)
 	})
 	r := mgmtPut(t, ts.URL+"/api/v1/providers/nope", "tok", map[string]string{"model": "x"})
@@ -2636,7 +2734,7 @@ func TestMgmt_GlobalProviders_UpdateNotFound(t *testing.T) {
 
 func TestMgmt_GlobalProviders_UpdateInvalidJSON(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetUpdateGlobalProvider(func(name string, info GlobalProviderInfo) error { return nil })
+	mgmt.SetUpdateGlobalProvider(func(_ context.Context, name string, info GlobalProviderInfo) error { return nil })
 
 	req, _ := http.NewRequest("PUT", ts.URL+"/api/v1/providers/test", strings.NewReader("{bad"))
 	req.Header.Set("Authorization", "Bearer tok")
@@ -2664,7 +2762,7 @@ func TestMgmt_GlobalProviders_DeleteNotConfigured(t *testing.T) {
 func TestMgmt_GlobalProviders_DeleteSuccess(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 	var deleted string
-	mgmt.SetRemoveGlobalProvider(func(name string) error {
+	mgmt.SetRemoveGlobalProvider(func(_ context.Context, name string) error {
 		deleted = name
 		return nil
 	})
@@ -2679,7 +2777,7 @@ func TestMgmt_GlobalProviders_DeleteSuccess(t *testing.T) {
 
 func TestMgmt_GlobalProviders_RouteMethodNotAllowed(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
-	mgmt.SetUpdateGlobalProvider(func(name string, info GlobalProviderInfo) error { return nil })
+	mgmt.SetUpdateGlobalProvider(func(_ context.Context, name string, info GlobalProviderInfo) error { return nil })
 
 	r := mgmtPost(t, ts.URL+"/api/v1/providers/test-prov", "tok", nil)
 	if r.OK {
@@ -2749,6 +2847,48 @@ func TestMgmt_Heartbeat_UnknownAction(t *testing.T) {
 	}
 }
 
+func TestMgmt_Heartbeat_Named(t *testing.T) {
+	mgmt, ts, e := testManagementServer(t, "tok")
+	hs := NewHeartbeatScheduler("")
+	hs.Register("test-project", HeartbeatConfig{Name: "inbox", Enabled: true, SessionKey: "test:dm"}, e, "")
+	hs.Register("test-project", HeartbeatConfig{Name: "builds", Enabled: true, SessionKey: "test:group", IntervalMins: 120}, e, "")
+	mgmt.SetHeartbeatScheduler(hs)
+
+	r := mgmtGet(t, ts.URL+"/api/v1/projects/test-project/heartbeat", "tok")
+	if !r.OK {
+		t.Fatalf("heartbeat list failed: %s", r.Error)
+	}
+	var data struct {
+		Heartbeats []struct {
+			Name         string `json:"name"`
+			IntervalMins int    `json:"interval_mins"`
+		} `json:"heartbeats"`
+	}
+	if err := json.Unmarshal(r.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Heartbeats) != 2 || data.Heartbeats[0].Name != "builds" || data.Heartbeats[0].IntervalMins != 120 {
+		t.Fatalf("heartbeats = %+v", data.Heartbeats)
+	}
+
+	if r := mgmtPost(t, ts.URL+"/api/v1/projects/test-project/heartbeat/pause", "tok", nil); r.OK {
+		t.Fatal("pause without a name should fail when several heartbeats exist")
+	}
+	if r := mgmtPost(t, ts.URL+"/api/v1/projects/test-project/heartbeat/builds/pause", "tok", nil); !r.OK {
+		t.Fatalf("pause builds failed: %s", r.Error)
+	}
+	if !hs.Status("test-project", "builds").Paused || hs.Status("test-project", "inbox").Paused {
+		t.Fatal("only builds should be paused")
+	}
+	r = mgmtPost(t, ts.URL+"/api/v1/projects/test-project/heartbeat/inbox/interval", "tok", map[string]any{"minutes": 5})
+	if !r.OK || hs.Status("test-project", "inbox").IntervalMins != 5 {
+		t.Fatalf("set inbox interval failed: %s", r.Error)
+	}
+	if r := mgmtGet(t, ts.URL+"/api/v1/projects/test-project/heartbeat/nightly", "tok"); r.OK {
+		t.Fatal("expected 404 for unknown heartbeat name")
+	}
+}
+
 func TestMgmt_Heartbeat_MethodNotAllowed(t *testing.T) {
 	mgmt, ts, _ := testManagementServer(t, "tok")
 	hs := NewHeartbeatScheduler("")
@@ -2948,7 +3088,7 @@ func TestMgmt_CCSwitchProviders_PostMissingNames(t *testing.T) {
 	mgmt.SetListCCSwitchProviders(func() ([]CCSwitchProviderInfo, error) {
 		return nil, nil
 	})
-	mgmt.SetAddGlobalProvider(func(info GlobalProviderInfo) error { return nil })
+	mgmt.SetAddGlobalProvider(func(_ context.Context, info GlobalProviderInfo) error { return nil })
 
 	r := mgmtPost(t, ts.URL+"/api/v1/providers/cc-switch", "tok", map[string]any{
 		"names": []string{},
@@ -5,7 +5,9 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -67,13 +69,49 @@ func CheckAllowFrom(platform, allowFrom string) {
 	}
 }
 
+// minRedactedSecretLen keeps short config values from being redacted out of
+// unrelated text.
+const minRedactedSecretLen = 8
+
+var (
+	redactedSecretsMu sync.RWMutex
+	redactedSecrets   []string // longest first
+)
+
+// SetRedactedSecrets sets the secret values that RedactToken removes in
+// addition to its token argument. It is called with the values config
+// secret references resolved to, on every load and reload.
+func SetRedactedSecrets(values []string) {
+	var secrets []string
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		if len(v) >= minRedactedSecretLen && !seen[v] {
+			seen[v] = true
+			secrets = append(secrets, v)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	redactedSecretsMu.Lock()
+	redactedSecrets = secrets
+	redactedSecretsMu.Unlock()
+}
+
 // RedactToken replaces a secret token in text with [REDACTED] to prevent
-// token leakage in logs or error messages.
+// token leakage in logs or error messages. Secrets registered with
+// SetRedactedSecrets are replaced as well.
 func RedactToken(text, token string) string {
-	if token == "" || text == "" {
+	if text == "" {
 		return text
 	}
-	return strings.ReplaceAll(text, token, "[REDACTED]")
+	if token != "" {
+		text = strings.ReplaceAll(text, token, "[REDACTED]")
+	}
+	redactedSecretsMu.RLock()
+	defer redactedSecretsMu.RUnlock()
+	for _, s := range redactedSecrets {
+		text = strings.ReplaceAll(text, s, "[REDACTED]")
+	}
+	return text
 }
 
 // AllowList checks whether a user ID is permitted based on a comma-separated
@@ -154,6 +192,10 @@ type FileAttachment struct {
 //	overwrite) and the file is skipped. This protects against within-call
 //	collisions but does not protect against concurrent calls racing on the
 //	same name — that case is fixed only when messageID is provided.
+//
+// When attachment conversion is enabled (SetAttachmentConversion), each
+// saved file is followed in the result by the files derived from it, such
+// as extracted text or an unpacked archive directory.
 func SaveFilesToDisk(workDir, messageID string, files []FileAttachment) []string {
 	if len(files) == 0 {
 		return nil
@@ -211,6 +253,7 @@ func SaveFilesToDisk(workDir, messageID string, files []FileAttachment) []string
 		}
 		paths = append(paths, fpath)
 		slog.Debug("SaveFilesToDisk: file saved", "path", fpath, "name", f.FileName, "mime", f.MimeType, "size", len(f.Data))
+		paths = append(paths, convertAttachment(fpath, f.MimeType)...)
 	}
 	return paths
 }
@@ -384,6 +427,11 @@ type Message struct {
 	ReplyCtx         any    // platform-specific context needed for replying
 	FromVoice        bool   // true if message originated from voice transcription
 	ModeOverride     string // if set, temporarily override agent permission mode for this message
+	// FromButton is set by platforms when the message was synthesized from
+	// an inline-button press (cmd: callback data). ReplyCtx then refers to
+	// the message that carries the button, so InlineButtonUpdater can edit
+	// it in place.
+	FromButton bool
 	// IsPermissionResponse is set by inline-button / card-action paths in
 	// platforms when a synthesized message is forwarded as a permission
 	// decision (e.g. Telegram handleCallbackQuery for perm:allow/deny,
@@ -399,6 +447,10 @@ type Message struct {
 	// drop late redeliveries that reuse a new message_id but an older create_time
 	// than a message already processed. Zero means unset (no ordering hint).
 	UserMessageTimeMs int64
+
+	// configReason describes the config-changing command msg runs, for the
+	// config history. Set by the command dispatcher.
+	configReason string
 }
 
 // EventType distinguishes different kinds of agent output.
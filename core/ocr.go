@@ -0,0 +1,117 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ImageRecognizer extracts printed text from an image.
+type ImageRecognizer interface {
+	Recognize(ctx context.Context, img ImageAttachment) (string, error)
+}
+
+// ImageSupportReporter is an optional interface for agents that can tell
+// whether images passed to Send reach the model. Agents that do not
+// implement it are assumed to handle images.
+type ImageSupportReporter interface {
+	SupportsImages() bool
+}
+
+// OCRCfg holds the local OCR configuration used for images sent to agents
+// without image support.
+type OCRCfg struct {
+	Enabled  bool
+	Provider string
+	OCR      ImageRecognizer
+}
+
+// ocrTimeout bounds recognition of a single image.
+const ocrTimeout = 30 * time.Second
+
+// SetOCRConfig configures the OCR fallback for agents without image support.
+func (e *Engine) SetOCRConfig(cfg OCRCfg) {
+	e.ocr = cfg
+}
+
+// agentSupportsImages reports whether agent passes images to its model.
+func agentSupportsImages(agent Agent) bool {
+	if r, ok := agent.(ImageSupportReporter); ok {
+		return r.SupportsImages()
+	}
+	return true
+}
+
+// recognizeImageText appends the text found in msg's images to its content
+// when OCR is enabled and agent cannot see images. The images stay attached
+// so agents that save them to disk still do.
+func (e *Engine) recognizeImageText(agent Agent, msg *Message) {
+	if len(msg.Images) == 0 || !e.ocr.Enabled || e.ocr.OCR == nil || agentSupportsImages(agent) {
+		return
+	}
+	var texts []string
+	for i, img := range msg.Images {
+		ctx, cancel := context.WithTimeout(e.ctx, ocrTimeout)
+		text, err := e.ocr.OCR.Recognize(ctx, img)
+		cancel()
+		if err != nil {
+			slog.Warn("ocr: recognition failed", "provider", e.ocr.Provider, "image", i+1, "error", err)
+			continue
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			texts = append(texts, fmt.Sprintf("[Text recognized in image %d]\n%s", i+1, text))
+		}
+	}
+	slog.Info("ocr: images recognized", "session", msg.SessionKey, "images", len(msg.Images), "with_text", len(texts))
+	if len(texts) == 0 {
+		return
+	}
+	ocrText := strings.Join(texts, "\n\n")
+	if msg.Content == "" {
+		msg.Content = ocrText
+	} else {
+		msg.Content += "\n\n" + ocrText
+	}
+}
+
+// ──────────────────────────────────────────────────────────────
+// TesseractOCR — local Tesseract OCR
+// ──────────────────────────────────────────────────────────────
+
+// TesseractOCR implements ImageRecognizer using the local tesseract command.
+type TesseractOCR struct {
+	Path      string // path to tesseract executable (empty = "tesseract")
+	Languages string // -l value, e.g. "eng+chi_sim" (empty = tesseract default)
+}
+
+// NewTesseractOCR creates a new TesseractOCR instance.
+func NewTesseractOCR(path, languages string) *TesseractOCR {
+	if path == "" {
+		path = "tesseract"
+	}
+	return &TesseractOCR{
+		Path:      path,
+		Languages: languages,
+	}
+}
+
+// Recognize pipes the image through tesseract and returns the text.
+func (t *TesseractOCR) Recognize(ctx context.Context, img ImageAttachment) (string, error) {
+	args := []string{"stdin", "stdout"}
+	if t.Languages != "" {
+		args = append(args, "-l", t.Languages)
+	}
+	cmd := exec.CommandContext(ctx, t.Path, args...)
+	cmd.Stdin = bytes.NewReader(img.Data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
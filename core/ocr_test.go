@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type stubRecognizer struct {
+	texts []string
+	calls int
+}
+
+func (r *stubRecognizer) Recognize(_ context.Context, _ ImageAttachment) (string, error) {
+	i := r.calls
+	r.calls++
+	if i >= len(r.texts) {
+		return "", errors.New("unreadable")
+	}
+	return r.texts[i], nil
+}
+
+type stubBlindAgent struct{ stubAgent }
+
+func (a *stubBlindAgent) SupportsImages() bool { return false }
+
+func TestRecognizeImageText_OnlyForAgentsWithoutImages(t *testing.T) {
+	e := NewEngine("test", &stubAgent{}, nil, "", LangEnglish)
+	ocr := &stubRecognizer{texts: []string{" Invoice #42\n", ""}}
+	e.SetOCRConfig(OCRCfg{Enabled: true, Provider: "stub", OCR: ocr})
+	images := []ImageAttachment{{MimeType: "image/png"}, {MimeType: "image/png"}, {MimeType: "image/png"}}
+
+	msg := &Message{Content: "what is this?", Images: images}
+	e.recognizeImageText(&stubAgent{}, msg)
+	if ocr.calls != 0 || msg.Content != "what is this?" {
+		t.Fatalf("OCR ran for an agent with image support: calls=%d content=%q", ocr.calls, msg.Content)
+	}
+
+	e.recognizeImageText(&stubBlindAgent{}, msg)
+	want := "what is this?\n\n[Text recognized in image 1]\nInvoice #42"
+	if msg.Content != want {
+		t.Fatalf("content = %q, want %q", msg.Content, want)
+	}
+	if len(msg.Images) != 3 {
+		t.Fatal("images were dropped")
+	}
+}
+
+func TestRecognizeImageText_Disabled(t *testing.T) {
+	e := NewEngine("test", &stubAgent{}, nil, "", LangEnglish)
+	msg := &Message{Images: []ImageAttachment{{MimeType: "image/png"}}}
+	e.recognizeImageText(&stubBlindAgent{}, msg)
+	if msg.Content != "" {
+		t.Fatalf("content = %q", msg.Content)
+	}
+}
+
+func TestTesseractOCR_MissingBinary(t *testing.T) {
+	ocr := NewTesseractOCR("/nonexistent/tesseract", "eng")
+	if _, err := ocr.Recognize(context.Background(), ImageAttachment{Data: []byte("png")}); err == nil || !strings.Contains(err.Error(), "tesseract") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// OptionType is the TOML type an entry in a platform's or agent's options
+// table must have.
+type OptionType string
+
+const (
+	OptionString       OptionType = "string"
+	OptionBool         OptionType = "boolean"
+	OptionInt          OptionType = "integer" // integers; whole floats are accepted too
+	OptionStringList   OptionType = "string_list"
+	OptionStringOrList OptionType = "string_or_list"
+	OptionTable        OptionType = "table"
+	OptionAny          OptionType = "any" // shape checked by the factory itself
+)
+
+// OptionSpec describes one key a platform or agent factory reads from its
+// options table.
+type OptionSpec struct {
+	Name        string
+	Type        OptionType
+	Required    bool
+	Enum        []string // allowed values for string options, compared case-insensitively
+	Description string
+	Deprecated  string // non-empty marks the key deprecated; names what to use instead
+}
+
+// OptionSchema lists every option a factory understands. It is registered
+// next to the factory with RegisterPlatformOptions or RegisterAgentOptions
+// and used by `cc-connect config validate`; factories still do their own
+// checks at startup.
+type OptionSchema struct {
+	Options []OptionSpec
+	// Conflicts lists groups of options of which at most one may be set.
+	Conflicts [][]string
+}
+
+// OptionIssue is one problem found by OptionSchema.Check.
+type OptionIssue struct {
+	Key     string // option key the issue is about
+	Message string
+	Warning bool // true for issues that do not stop the factory, e.g. deprecated keys
+}
+
+// commonAgentOptions are read for every agent type outside its factory.
+var commonAgentOptions = []OptionSpec{
+	{Name: "work_dir", Type: OptionString, Description: "Working directory for the agent"},
+	{Name: "provider", Type: OptionString, Description: "Name of the active API provider"},
+}
+
+var (
+	platformOptionSchemas = make(map[string]OptionSchema)
+	agentOptionSchemas    = make(map[string]OptionSchema)
+)
+
+// RegisterPlatformOptions records the options a registered platform accepts.
+func RegisterPlatformOptions(name string, schema OptionSchema) {
+	platformOptionSchemas[name] = schema
+}
+
+// RegisterAgentOptions records the options a registered agent accepts.
+// work_dir and provider are added when the schema does not list them.
+func RegisterAgentOptions(name string, schema OptionSchema) {
+	for _, common := range commonAgentOptions {
+		if _, ok := schema.lookup(common.Name); !ok {
+			schema.Options = append(schema.Options, common)
+		}
+	}
+	agentOptionSchemas[name] = schema
+}
+
+// PlatformOptionSchema returns the registered options of a platform type.
+func PlatformOptionSchema(name string) (OptionSchema, bool) {
+	s, ok := platformOptionSchemas[name]
+	return s, ok
+}
+
+// AgentOptionSchema returns the registered options of an agent type.
+func AgentOptionSchema(name string) (OptionSchema, bool) {
+	s, ok := agentOptionSchemas[name]
+	return s, ok
+}
+
+func (s OptionSchema) lookup(key string) (OptionSpec, bool) {
+	for _, o := range s.Options {
+		if o.Name == key {
+			return o, true
+		}
+	}
+	return OptionSpec{}, false
+}
+
+// Check reports unknown keys, values of the wrong type, missing required
+// options, values outside Enum, deprecated keys and conflicting options.
+// Issues are ordered by key.
+func (s OptionSchema) Check(opts map[string]any) []OptionIssue {
+	var issues []OptionIssue
+	keys := make([]string, 0, len(opts))
+	for k := range opts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		spec, ok := s.lookup(k)
+		if !ok {
+			msg := fmt.Sprintf("unknown option %q", k)
+			if near := s.closest(k); near != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", near)
+			}
+			issues = append(issues, OptionIssue{Key: k, Message: msg})
+			continue
+		}
+		v := opts[k]
+		if !spec.Type.accepts(v) {
+			issues = append(issues, OptionIssue{Key: k, Message: fmt.Sprintf("option %q must be %s, got %s", k, spec.Type.describe(), optionValueType(v))})
+			continue
+		}
+		if str, isStr := v.(string); isStr && len(spec.Enum) > 0 && str != "" && !containsFold(spec.Enum, str) {
+			issues = append(issues, OptionIssue{Key: k, Message: fmt.Sprintf("option %q must be one of %s, got %q", k, strings.Join(spec.Enum, ", "), str)})
+		}
+		if spec.Deprecated != "" {
+			issues = append(issues, OptionIssue{Key: k, Message: fmt.Sprintf("option %q is deprecated: %s", k, spec.Deprecated), Warning: true})
+		}
+	}
+	for _, o := range s.Options {
+		if !o.Required {
+			continue
+		}
+		if v, ok := opts[o.Name]; !ok || v == "" {
+			issues = append(issues, OptionIssue{Key: o.Name, Message: fmt.Sprintf("option %q is required", o.Name)})
+		}
+	}
+	for _, group := range s.Conflicts {
+		var set []string
+		for _, k := range group {
+			if _, ok := opts[k]; ok {
+				set = append(set, k)
+			}
+		}
+		if len(set) > 1 {
+			issues = append(issues, OptionIssue{Key: set[1], Message: fmt.Sprintf("options %s cannot be set together", strings.Join(set, " and "))})
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// closest returns the known option nearest to key, if it is a likely typo.
+func (s OptionSchema) closest(key string) string {
+	best, bestDist := "", 3
+	for _, o := range s.Options {
+		if d := editDistance(key, o.Name); d < bestDist {
+			best, bestDist = o.Name, d
+		}
+	}
+	return best
+}
+
+// JSONSchema returns a JSON Schema object for an options table.
+func (s OptionSchema) JSONSchema() map[string]any {
+	props := make(map[string]any, len(s.Options))
+	var required []string
+	for _, o := range s.Options {
+		p := o.Type.jsonSchema()
+		if o.Description != "" {
+			p["description"] = o.Description
+		}
+		if len(o.Enum) > 0 {
+			p["enum"] = o.Enum
+		}
+		if o.Deprecated != "" {
+			p["deprecated"] = true
+		}
+		props[o.Name] = p
+		if o.Required {
+			required = append(required, o.Name)
+		}
+	}
+	out := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+func (t OptionType) accepts(v any) bool {
+	switch t {
+	case OptionString:
+		_, ok := v.(string)
+		return ok
+	case OptionBool:
+		_, ok := v.(bool)
+		return ok
+	case OptionInt:
+		switch n := v.(type) {
+		case int, int64:
+			return true
+		case float64:
+			return n == math.Trunc(n)
+		}
+		return false
+	case OptionStringList:
+		return isStringList(v)
+	case OptionStringOrList:
+		_, ok := v.(string)
+		return ok || isStringList(v)
+	case OptionTable:
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+func (t OptionType) describe() string {
+	switch t {
+	case OptionString:
+		return "a string"
+	case OptionBool:
+		return "true or false"
+	case OptionInt:
+		return "an integer"
+	case OptionStringList:
+		return "an array of strings"
+	case OptionStringOrList:
+		return "a string or an array of strings"
+	case OptionTable:
+		return "a table"
+	}
+	return "a value"
+}
+
+func (t OptionType) jsonSchema() map[string]any {
+	strList := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	switch t {
+	case OptionString, OptionBool, OptionInt:
+		return map[string]any{"type": string(t)}
+	case OptionStringList:
+		return strList
+	case OptionStringOrList:
+		return map[string]any{"oneOf": []any{map[string]any{"type": "string"}, strList}}
+	case OptionTable:
+		return map[string]any{"type": "object"}
+	}
+	return map[string]any{}
+}
+
+func isStringList(v any) bool {
+	switch list := v.(type) {
+	case []string:
+		return true
+	case []any:
+		for _, item := range list {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// optionValueType names the TOML type of a decoded value for messages.
+func optionValueType(v any) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case int, int64:
+		return "an integer"
+	case float64:
+		return "a float"
+	case []any, []string, []map[string]any:
+		return "an array"
+	case map[string]any:
+		return "a table"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(s), item) {
+			return true
+		}
+	}
+	return false
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptionSchemaCheck(t *testing.T) {
+	schema := OptionSchema{
+		Options: []OptionSpec{
+			{Name: "token", Type: OptionString, Required: true},
+			{Name: "mode", Type: OptionString, Enum: []string{"a", "b"}},
+			{Name: "port", Type: OptionInt},
+			{Name: "cmd", Type: OptionStringOrList},
+			{Name: "cli_path", Type: OptionString, Deprecated: "use cmd"},
+		},
+		Conflicts: [][]string{{"cmd", "cli_path"}},
+	}
+	issues := schema.Check(map[string]any{
+		"tokn":     "x",
+		"mode":     "c",
+		"port":     int64(80),
+		"cmd":      []any{"run", 1},
+		"cli_path": "/bin/x",
+	})
+
+	want := []string{
+		`option "cli_path" is deprecated`,
+		`options cmd and cli_path cannot be set together`,
+		`option "cmd" must be a string or an array of strings`,
+		`option "mode" must be one of a, b`,
+		`option "token" is required`,
+		`unknown option "tokn" (did you mean "token"?)`,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %+v", len(issues), len(want), issues)
+	}
+	for _, w := range want {
+		found := false
+		for _, is := range issues {
+			if strings.Contains(is.Message, w) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing issue %q in %+v", w, issues)
+		}
+	}
+	for _, is := range issues {
+		if is.Warning != (is.Key == "cli_path" && strings.Contains(is.Message, "deprecated")) {
+			t.Errorf("unexpected warning flag on %+v", is)
+		}
+	}
+}
+
+func TestOptionSchemaCheck_Valid(t *testing.T) {
+	schema := OptionSchema{Options: []OptionSpec{
+		{Name: "token", Type: OptionString, Required: true},
+		{Name: "port", Type: OptionInt},
+		{Name: "extra", Type: OptionTable},
+	}}
+	issues := schema.Check(map[string]any{
+		"token": "x",
+		"port":  float64(8080),
+		"extra": map[string]any{"k": "v"},
+	})
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+}
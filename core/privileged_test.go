@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
@@ -104,3 +105,75 @@ func TestHandleCommand_CronAddexecBlocksNonAdmin(t *testing.T) {
 	}
 }
 
+func TestIsPrivilegedCommandInvocation_ConfigHistoryGate(t *testing.T) {
+	for _, sub := range []string{"get", "set", "reload", "thinking_max_len"} {
+		if isPrivilegedCommandInvocation("config", []string{sub}) {
+			t.Errorf("/config %s must NOT require admin", sub)
+		}
+	}
+	for _, sub := range []string{"history", "diff", "rollback", "ROLLBACK", "hist"} {
+		if !isPrivilegedCommandInvocation("config", []string{sub}) {
+			t.Errorf("/config %s must require admin", sub)
+		}
+	}
+}
+
+func TestHandleCommand_ConfigRollbackAttributesChange(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetAdminFrom("admin")
+
+	var change ConfigChange
+	var rolledBack int
+	e.SetConfigHistoryFuncs(
+		func() ([]ConfigVersionInfo, error) { return nil, nil },
+		func(from, to int) (string, error) { return "", nil },
+		func(ctx context.Context, id int) error {
+			change, _ = ConfigChangeFrom(ctx)
+			rolledBack = id
+			return nil
+		},
+	)
+
+	msg := &Message{UserID: "u", Platform: "test", ReplyCtx: "rctx"}
+	e.handleCommand(p, msg, "/config rollback 3")
+	if rolledBack != 0 {
+		t.Fatal("non-admin must not be able to roll back the config")
+	}
+
+	msg.UserID = "admin"
+	e.handleCommand(p, msg, "/config rollback #3")
+	if rolledBack != 3 {
+		t.Fatalf("rolled back to %d, want 3", rolledBack)
+	}
+	if change.Actor != "test:admin" || change.Reason != "/config rollback #3" {
+		t.Errorf("change attributed to %+v", change)
+	}
+	sent := p.getSent()
+	if len(sent) == 0 || !strings.Contains(sent[len(sent)-1], "#3") {
+		t.Errorf("expected rollback confirmation, got %#v", sent)
+	}
+}
+
+func TestChangesConfig_ReadOnlyFormsSkipAttribution(t *testing.T) {
+	for _, c := range []struct {
+		cmd  string
+		args []string
+		want bool
+	}{
+		{"model", nil, false},
+		{"model", []string{"gpt-5"}, true},
+		{"provider", []string{"list"}, false},
+		{"provider", []string{"switch", "relay"}, true},
+		{"config", []string{"history"}, false},
+		{"config", []string{"rollback", "3"}, true},
+		{"quiet", nil, true},
+		{"web", nil, false},
+		{"web", []string{"setup"}, true},
+		{"help", []string{"set"}, false},
+	} {
+		if got := changesConfig(c.cmd, c.args); got != c.want {
+			t.Errorf("changesConfig(%q, %q) = %v, want %v", c.cmd, c.args, got, c.want)
+		}
+	}
+}
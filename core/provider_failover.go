@@ -0,0 +1,277 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultProviderFailoverCooldown is how long a failed provider is skipped
+// before the engine switches back to it.
+const DefaultProviderFailoverCooldown = 10 * time.Minute
+
+var (
+	// providerStatusPattern matches HTTP status codes reported by agents and
+	// provider SDKs, e.g. "API Error: 529", "status 503" or "HTTP/1.1 502".
+	providerStatusPattern = regexp.MustCompile(`(?i)(?:api error|status(?: code)?|http(?:/[0-9.]+)?|error code)\W{0,3}(429|529|5[0-9]{2})\b`)
+	// providerOutagePattern matches the wording providers use for rate limits
+	// and outages when no status code is included.
+	providerOutagePattern = regexp.MustCompile(`(?i)rate[ _-]?limit|too many requests|overloaded|service unavailable|bad gateway|gateway time-?out|internal server error|server_error|upstream connect error`)
+)
+
+// IsProviderOutage reports whether err looks like the provider, rather than
+// the agent or the request, failed: a rate limit (429), an overload (529) or
+// a server error (5xx). Such errors are worth retrying on another provider.
+func IsProviderOutage(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return providerStatusPattern.MatchString(msg) || providerOutagePattern.MatchString(msg)
+}
+
+// providerFailover holds a project's failover chain and, per agent, which
+// providers failed and which provider to return to after the cooldown.
+type providerFailover struct {
+	chain    []string
+	cooldown time.Duration
+
+	mu     sync.Mutex
+	states map[ProviderSwitcher]*failoverState
+}
+
+type failoverState struct {
+	home   string               // provider active before the first failover
+	failed map[string]time.Time // provider name → time of its last outage
+}
+
+func newProviderFailover(chain []string, cooldown time.Duration) *providerFailover {
+	if cooldown <= 0 {
+		cooldown = DefaultProviderFailoverCooldown
+	}
+	return &providerFailover{
+		chain:    append([]string(nil), chain...),
+		cooldown: cooldown,
+		states:   make(map[ProviderSwitcher]*failoverState),
+	}
+}
+
+func activeProviderName(ps ProviderSwitcher) string {
+	if p := ps.GetActiveProvider(); p != nil {
+		return p.Name
+	}
+	return ""
+}
+
+// next records that the active provider of ps failed and returns the first
+// provider in the chain that is registered, has not failed within the
+// cooldown and is not rejected by skip (may be nil), or "" when there is none
+// left to try. It does not switch.
+func (f *providerFailover) next(ps ProviderSwitcher, now time.Time, skip func(name string) bool) (from, to string) {
+	from = activeProviderName(ps)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st := f.states[ps]
+	if st == nil {
+		st = &failoverState{home: from, failed: make(map[string]time.Time)}
+		f.states[ps] = st
+	}
+	st.failed[from] = now
+
+	registered := make(map[string]bool)
+	for _, p := range ps.ListProviders() {
+		registered[p.Name] = true
+	}
+	for _, name := range f.chain {
+		if name == from || !registered[name] {
+			continue
+		}
+		if at, ok := st.failed[name]; ok && now.Sub(at) < f.cooldown {
+			continue
+		}
+		if skip != nil && skip(name) {
+			continue
+		}
+		return from, name
+	}
+	return from, ""
+}
+
+// restore switches ps back to the provider it used before failing over once
+// that provider's cooldown has passed. It reports whether it switched.
+func (f *providerFailover) restore(ps ProviderSwitcher, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st := f.states[ps]
+	if st == nil || now.Sub(st.failed[st.home]) < f.cooldown {
+		return false
+	}
+	delete(f.states, ps)
+	if activeProviderName(ps) == st.home {
+		return false
+	}
+	if !ps.SetActiveProvider(st.home) {
+		slog.Warn("provider failover: could not switch back", "provider", st.home)
+		return false
+	}
+	slog.Info("provider failover: cooldown over, switched back", "provider", st.home)
+	return true
+}
+
+// reset forgets the failover state of ps, e.g. after a manual /provider switch.
+func (f *providerFailover) reset(ps ProviderSwitcher) {
+	f.mu.Lock()
+	delete(f.states, ps)
+	f.mu.Unlock()
+}
+
+// SetProviderFailover configures an ordered list of provider names to fail
+// over to when the active provider reports a rate limit or outage. The turn
+// is retried on the next provider in the same agent session, and the engine
+// switches back after cooldown (DefaultProviderFailoverCooldown when <= 0).
+// An empty chain disables failover.
+func (e *Engine) SetProviderFailover(chain []string, cooldown time.Duration) {
+	if len(chain) == 0 {
+		e.providerFailover.Store(nil)
+		return
+	}
+	f := newProviderFailover(chain, cooldown)
+	if cur := e.providerFailover.Load(); cur != nil && cur.cooldown == f.cooldown && slices.Equal(cur.chain, f.chain) {
+		return // unchanged on config reload; keep the failover state
+	}
+	e.providerFailover.Store(f)
+}
+
+// providerFailoverTarget decides whether a turn that failed with err should
+// be retried on another provider, returning the provider to switch to.
+// Outages also count against the provider's health circuit, and providers
+// whose circuit is open are not failed over to.
+func (e *Engine) providerFailoverTarget(agent Agent, err error) string {
+	if !IsProviderOutage(err) {
+		return ""
+	}
+	ps, ok := agent.(ProviderSwitcher)
+	if !ok {
+		return ""
+	}
+	if m := e.ProviderHealth(); m != nil {
+		if name := activeProviderName(ps); name != "" {
+			m.RecordFailure(name, err)
+		}
+	}
+	f := e.providerFailover.Load()
+	if f == nil {
+		return ""
+	}
+	from, to := f.next(ps, time.Now(), e.providerCircuitOpen)
+	if to == "" {
+		slog.Warn("provider failover: no provider left to try", "provider", from, "error", err)
+	}
+	return to
+}
+
+// applyProviderFailover switches agent back to its original provider once the
+// failover cooldown has passed.
+func (e *Engine) applyProviderFailover(agent Agent) {
+	f := e.providerFailover.Load()
+	if f == nil {
+		return
+	}
+	if ps, ok := agent.(ProviderSwitcher); ok {
+		f.restore(ps, time.Now())
+	}
+}
+
+// providerChangedSinceStart reports whether the agent's active provider
+// differs from the one the live agent session was started with, so the
+// session has to be restarted to pick it up.
+func providerChangedSinceStart(state *interactiveState, agent Agent) bool {
+	ps, ok := agent.(ProviderSwitcher)
+	if !ok {
+		return false
+	}
+	return state.provider != activeProviderName(ps)
+}
+
+// providerFailoverActive reports whether agent is currently failed over, in
+// which case the provider saved in a session must not override the switch.
+func (e *Engine) providerFailoverActive(agent Agent) bool {
+	f := e.providerFailover.Load()
+	if f == nil {
+		return false
+	}
+	ps, ok := agent.(ProviderSwitcher)
+	if !ok {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.states[ps] != nil
+}
+
+// failoverTurn handles a turn that failed with err. When err is a provider
+// outage and the failover chain has a provider left, it switches the agent to
+// that provider, restarts the agent session resuming the same conversation,
+// puts the failed turn back at the head of the queue and tells the user which
+// provider will answer. It reports whether the turn was re-queued.
+func (e *Engine) failoverTurn(state *interactiveState, session *Session, sessions *SessionManager, sessionKey string, err error) bool {
+	state.mu.Lock()
+	agent := state.agent
+	turn := state.currentTurn
+	old := state.agentSession
+	state.mu.Unlock()
+	if agent == nil || turn == nil {
+		return false
+	}
+	to := e.providerFailoverTarget(agent, err)
+	if to == "" {
+		return false
+	}
+	ps := agent.(ProviderSwitcher)
+	from := activeProviderName(ps)
+	if !ps.SetActiveProvider(to) {
+		return false
+	}
+	slog.Warn("provider outage, failing over", "session_key", sessionKey, "from", from, "to", to, "error", err)
+
+	if old != nil {
+		e.closeAgentSessionWithTimeout(sessionKey, old)
+	}
+	var apiKey string
+	if inj, ok := agent.(SessionEnvInjector); ok {
+		var keyEnv []string
+		apiKey, keyEnv = e.rotateProviderKey(agent)
+		state.mu.Lock()
+		env := append(slices.Clone(state.sessionEnv), keyEnv...)
+		state.mu.Unlock()
+		inj.SetSessionEnv(env)
+	}
+	agentSession, startErr := agent.StartSession(e.ctx, session.GetAgentSessionID())
+	if startErr != nil {
+		slog.Error("provider failover: failed to restart agent session", "provider", to, "error", startErr)
+		state.mu.Lock()
+		state.agentSession = nil
+		state.mu.Unlock()
+		return false
+	}
+	if newID := agentSession.CurrentSessionID(); newID != "" && newID != session.GetAgentSessionID() {
+		session.SetAgentSessionID(newID, agent.Name())
+		sessions.Save()
+	}
+
+	retry := *turn
+	retry.retry = true
+	state.mu.Lock()
+	state.agentSession = agentSession
+	state.provider = to
+	state.apiKey = apiKey
+	state.eventsNeedResync = false
+	state.pendingMessages = append([]queuedMessage{retry}, state.pendingMessages...)
+	state.mu.Unlock()
+
+	e.send(turn.platform, turn.replyCtx, fmt.Sprintf(e.i18n.T(MsgProviderFailover), from, to))
+	return true
+}
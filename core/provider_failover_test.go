@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsProviderOutage(t *testing.T) {
+	cases := []struct {
+		msg  string
+		want bool
+	}{
+		{`API Error: 529 {"type":"error","error":{"type":"overloaded_error"}}`, true},
+		{"API Error: 429 rate_limit_error", true},
+		{"unexpected status 503 Service Unavailable", true},
+		{"HTTP/1.1 502 Bad Gateway", true},
+		{"stream error: Too Many Requests", true},
+		{"Prompt is too long", false},
+		{"API Error: 400 invalid_request_error", false},
+		{"read stdout: file already closed", false},
+		{"edited line 500 of main.go", false},
+	}
+	for _, c := range cases {
+		if got := IsProviderOutage(errors.New(c.msg)); got != c.want {
+			t.Errorf("IsProviderOutage(%q) = %v, want %v", c.msg, got, c.want)
+		}
+	}
+	if IsProviderOutage(nil) {
+		t.Error("IsProviderOutage(nil) = true")
+	}
+}
+
+func newFailoverTestAgent(active string, names ...string) *stubProviderAgent {
+	a := &stubProviderAgent{active: active}
+	for _, n := range names {
+		a.providers = append(a.providers, ProviderConfig{Name: n})
+	}
+	return a
+}
+
+func TestProviderFailover_NextAndRestore(t *testing.T) {
+	agent := newFailoverTestAgent("main", "main", "backup", "spare")
+	f := newProviderFailover([]string{"main", "backup", "missing", "spare"}, time.Minute)
+	now := time.Now()
+
+	if from, to := f.next(agent, now, nil); from != "main" || to != "backup" {
+		t.Fatalf("next = %q → %q, want main → backup", from, to)
+	}
+	agent.SetActiveProvider("backup")
+	if _, to := f.next(agent, now, nil); to != "spare" {
+		t.Fatalf("second failover = %q, want spare (unregistered and failed providers skipped)", to)
+	}
+	agent.SetActiveProvider("spare")
+	if _, to := f.next(agent, now, nil); to != "" {
+		t.Fatalf("all providers failed, got %q", to)
+	}
+
+	if f.restore(agent, now.Add(30*time.Second)) {
+		t.Fatal("restored before the cooldown")
+	}
+	if !f.restore(agent, now.Add(time.Minute)) || agent.active != "main" {
+		t.Fatalf("after cooldown active = %q, want main", agent.active)
+	}
+	if f.restore(agent, now.Add(2*time.Minute)) {
+		t.Fatal("restore should be a no-op once switched back")
+	}
+}
+
+type failoverStartAgent struct {
+	stubProviderAgent
+	started []string // "session@provider" per StartSession call
+}
+
+func (a *failoverStartAgent) StartSession(_ context.Context, sessionID string) (AgentSession, error) {
+	a.started = append(a.started, sessionID+"@"+a.active)
+	return newControllableSession(sessionID), nil
+}
+
+func TestProcessInteractiveEvents_FailsOverOnProviderOutage(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	agent := &failoverStartAgent{stubProviderAgent: *newFailoverTestAgent("main", "main", "backup")}
+	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
+	e.SetProviderFailover([]string{"main", "backup"}, time.Minute)
+
+	sessionKey := "test:user1"
+	session := e.sessions.GetOrCreateActive(sessionKey)
+	session.SetAgentSessionID("conv-1", "stub")
+	agentSession := newControllableSession("conv-1")
+	queued := queuedMessage{messageID: "m2", platform: p, replyCtx: "ctx-2", content: "later"}
+	state := &interactiveState{
+		agentSession:    agentSession,
+		agent:           agent,
+		platform:        p,
+		replyCtx:        "ctx-1",
+		provider:        "main",
+		currentTurn:     &queuedMessage{messageID: "m1", platform: p, replyCtx: "ctx-1", content: "hello"},
+		pendingMessages: []queuedMessage{queued},
+	}
+	e.interactiveStates[sessionKey] = state
+
+	agentSession.events <- Event{Type: EventError, Error: errors.New(`API Error: 529 {"type":"overloaded_error"}`)}
+	e.processInteractiveEvents(state, session, e.sessions, sessionKey, "m1", time.Now(), nil, nil, "ctx-1")
+
+	if agent.active != "backup" || state.provider != "backup" {
+		t.Fatalf("active provider = %q (state %q), want backup", agent.active, state.provider)
+	}
+	if len(agent.started) != 1 || agent.started[0] != "conv-1@backup" {
+		t.Fatalf("StartSession calls = %v, want resume of conv-1 on backup", agent.started)
+	}
+	if state.agentSession == agentSession || agentSession.Alive() {
+		t.Fatal("old agent session should be closed and replaced")
+	}
+	if len(state.pendingMessages) != 2 || !state.pendingMessages[0].retry || state.pendingMessages[0].content != "hello" || state.pendingMessages[1].content != "later" {
+		t.Fatalf("pending = %+v, want failed turn re-queued first", state.pendingMessages)
+	}
+	sent := p.getSent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "**main**") || !strings.Contains(sent[0], "**backup**") {
+		t.Fatalf("sent = %q, want one failover notice", sent)
+	}
+}
+
+func TestProcessInteractiveEvents_NoFailoverForOtherErrors(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	agent := &failoverStartAgent{stubProviderAgent: *newFailoverTestAgent("main", "main", "backup")}
+	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
+	e.SetProviderFailover([]string{"backup"}, 0)
+
+	sessionKey := "test:user1"
+	session := e.sessions.GetOrCreateActive(sessionKey)
+	agentSession := newControllableSession("conv-1")
+	state := &interactiveState{
+		agentSession: agentSession,
+		agent:        agent,
+		platform:     p,
+		replyCtx:     "ctx-1",
+		currentTurn:  &queuedMessage{messageID: "m1", platform: p, content: "hello"},
+	}
+	e.interactiveStates[sessionKey] = state
+
+	agentSession.events <- Event{Type: EventError, Error: errors.New("Prompt is too long")}
+	e.processInteractiveEvents(state, session, e.sessions, sessionKey, "m1", time.Now(), nil, nil, "ctx-1")
+
+	if agent.active != "main" || len(agent.started) != 0 || len(state.pendingMessages) != 0 {
+		t.Fatalf("unexpected failover: active=%q started=%v pending=%d", agent.active, agent.started, len(state.pendingMessages))
+	}
+}
@@ -0,0 +1,393 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CircuitState is the circuit-breaker state of a provider.
+type CircuitState string
+
+const (
+	CircuitUnknown  CircuitState = "unknown"   // not probed yet, or nothing to probe
+	CircuitClosed   CircuitState = "closed"    // healthy
+	CircuitOpen     CircuitState = "open"      // failing; switching to it needs confirmation
+	CircuitHalfOpen CircuitState = "half_open" // open long enough; the next probe decides
+)
+
+// ProviderHealth is the health of one provider as seen by the probes and by
+// real agent traffic.
+type ProviderHealth struct {
+	State         CircuitState `json:"state"`
+	Failures      int          `json:"consecutive_failures"`
+	LastCheck     time.Time    `json:"last_check,omitempty"`
+	LastLatencyMs int64        `json:"last_latency_ms,omitempty"`
+	LastError     string       `json:"last_error,omitempty"`
+	OpenedAt      time.Time    `json:"opened_at,omitempty"`
+}
+
+// ProviderHealthCfg configures provider health probing.
+type ProviderHealthCfg struct {
+	Interval         time.Duration // time between probe rounds; <= 0 disables probing
+	Timeout          time.Duration // per-probe timeout (default 10s)
+	FailureThreshold int           // consecutive failures that open the circuit (default 3)
+	OpenDuration     time.Duration // how long a circuit stays open before a retry probe (default 10m)
+}
+
+func (c ProviderHealthCfg) withDefaults() ProviderHealthCfg {
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 3
+	}
+	if c.OpenDuration <= 0 {
+		c.OpenDuration = 10 * time.Minute
+	}
+	return c
+}
+
+// ProviderHealthMonitor probes providers periodically with a lightweight
+// model-list request and keeps a circuit breaker per provider.
+type ProviderHealthMonitor struct {
+	cfg       ProviderHealthCfg
+	client    *http.Client
+	providers func() []ProviderConfig
+	now       func() time.Time
+
+	mu     sync.Mutex
+	health map[string]*ProviderHealth
+	cancel context.CancelFunc
+}
+
+// NewProviderHealthMonitor creates a monitor for the providers returned by
+// providers. Call Start to begin probing.
+func NewProviderHealthMonitor(cfg ProviderHealthCfg, providers func() []ProviderConfig) *ProviderHealthMonitor {
+	cfg = cfg.withDefaults()
+	return &ProviderHealthMonitor{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		providers: providers,
+		now:       time.Now,
+		health:    make(map[string]*ProviderHealth),
+	}
+}
+
+// Start probes all providers now and then every cfg.Interval until ctx is
+// done or Stop is called.
+func (m *ProviderHealthMonitor) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.cancel = cancel
+	m.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(m.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			m.ProbeAll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends probing.
+func (m *ProviderHealthMonitor) Stop() {
+	m.mu.Lock()
+	cancel := m.cancel
+	m.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// ProbeAll probes every provider whose circuit is not held open.
+func (m *ProviderHealthMonitor) ProbeAll(ctx context.Context) {
+	for _, p := range m.providers() {
+		if ctx.Err() != nil {
+			return
+		}
+		if m.Health(p.Name).State == CircuitOpen {
+			continue // wait for OpenDuration before trying again
+		}
+		m.Probe(ctx, p)
+	}
+}
+
+// Probe checks one provider and records the result. Providers without a
+// base URL are left unknown: there is nothing cc-connect could probe.
+func (m *ProviderHealthMonitor) Probe(ctx context.Context, p ProviderConfig) error {
+	url := providerProbeURL(p)
+	if url == "" {
+		return nil
+	}
+	start := m.now()
+	err := m.probe(ctx, url, providerProbeKey(p))
+	latency := m.now().Sub(start)
+	if err != nil {
+		slog.Debug("provider health: probe failed", "provider", p.Name, "url", url, "error", err)
+		m.RecordFailure(p.Name, err)
+	} else {
+		m.RecordSuccess(p.Name)
+	}
+	m.mu.Lock()
+	if h := m.health[p.Name]; h != nil {
+		h.LastLatencyMs = latency.Milliseconds()
+	}
+	m.mu.Unlock()
+	return err
+}
+
+func (m *ProviderHealthMonitor) probe(ctx context.Context, url, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		// Anthropic-style and OpenAI-style auth; each API ignores the other.
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	req.Header.Set("anthropic-version", "2023-06-01")
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed:
+		return nil // reachable, just no model list at this path
+	default:
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+}
+
+// providerProbeURL returns the model-list URL for p, from its base URL or,
+// for env-only providers, the base URL in its env.
+func providerProbeURL(p ProviderConfig) string {
+	base := p.BaseURL
+	if base == "" {
+		for _, k := range []string{"ANTHROPIC_BASE_URL", "OPENAI_BASE_URL"} {
+			if v := p.Env[k]; v != "" {
+				base = v
+				break
+			}
+		}
+	}
+	base = strings.TrimRight(strings.TrimSpace(base), "/")
+	if base == "" {
+		return ""
+	}
+	if strings.HasSuffix(base, "/v1") {
+		return base + "/models"
+	}
+	return base + "/v1/models"
+}
+
+func providerProbeKey(p ProviderConfig) string {
+	if p.APIKey != "" {
+		return p.APIKey
+	}
+	for _, k := range []string{"ANTHROPIC_API_KEY", "ANTHROPIC_AUTH_TOKEN", "OPENAI_API_KEY"} {
+		if v := p.Env[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (m *ProviderHealthMonitor) entry(name string) *ProviderHealth {
+	h := m.health[name]
+	if h == nil {
+		h = &ProviderHealth{State: CircuitUnknown}
+		m.health[name] = h
+	}
+	return h
+}
+
+// RecordSuccess closes the circuit of the named provider.
+func (m *ProviderHealthMonitor) RecordSuccess(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.entry(name)
+	h.State = CircuitClosed
+	h.Failures = 0
+	h.LastError = ""
+	h.OpenedAt = time.Time{}
+	h.LastCheck = m.now()
+}
+
+// RecordFailure counts a failed probe or a provider outage seen in agent
+// traffic. The circuit opens after FailureThreshold consecutive failures, or
+// immediately when a half-open retry fails.
+func (m *ProviderHealthMonitor) RecordFailure(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	h := m.entry(name)
+	halfOpen := m.stateLocked(h, now) == CircuitHalfOpen
+	h.Failures++
+	h.LastCheck = now
+	if err != nil {
+		h.LastError = err.Error()
+	}
+	if halfOpen || h.Failures >= m.cfg.FailureThreshold {
+		if h.State != CircuitOpen || halfOpen {
+			slog.Warn("provider health: circuit open", "provider", name, "failures", h.Failures, "error", h.LastError)
+		}
+		h.State = CircuitOpen
+		h.OpenedAt = now
+	} else if h.State == CircuitUnknown {
+		h.State = CircuitClosed
+	}
+}
+
+func (m *ProviderHealthMonitor) stateLocked(h *ProviderHealth, now time.Time) CircuitState {
+	if h.State == CircuitOpen && now.Sub(h.OpenedAt) >= m.cfg.OpenDuration {
+		return CircuitHalfOpen
+	}
+	return h.State
+}
+
+// Health returns a snapshot of the named provider's health.
+func (m *ProviderHealthMonitor) Health(name string) ProviderHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.health[name]
+	if !ok {
+		return ProviderHealth{State: CircuitUnknown}
+	}
+	out := *h
+	out.State = m.stateLocked(h, m.now())
+	return out
+}
+
+// IsOpen reports whether the named provider's circuit is open.
+func (m *ProviderHealthMonitor) IsOpen(name string) bool {
+	return m.Health(name).State == CircuitOpen
+}
+
+// engineProviderHealth is the monitor an Engine runs and the config it was
+// started with; see SetProviderHealthCheck.
+type engineProviderHealth struct {
+	cfg     ProviderHealthCfg
+	monitor *ProviderHealthMonitor
+}
+
+// SetProviderHealthCheck starts (or, with cfg.Interval <= 0, stops) periodic
+// health probes of the agent's providers. Calling it again with the same
+// config keeps the running monitor and its state.
+func (e *Engine) SetProviderHealthCheck(cfg ProviderHealthCfg) {
+	cur := e.providerHealth.Load()
+	if cur != nil && cur.cfg == cfg {
+		return
+	}
+	if cur != nil {
+		cur.monitor.Stop()
+	}
+	if cfg.Interval <= 0 {
+		e.providerHealth.Store(nil)
+		return
+	}
+	m := NewProviderHealthMonitor(cfg, func() []ProviderConfig {
+		if ps, ok := e.GetAgent().(ProviderSwitcher); ok {
+			return ps.ListProviders()
+		}
+		return nil
+	})
+	e.providerHealth.Store(&engineProviderHealth{cfg: cfg, monitor: m})
+	m.Start(e.ctx)
+}
+
+// ProviderHealth returns the health monitor, or nil when probing is off.
+func (e *Engine) ProviderHealth() *ProviderHealthMonitor {
+	if h := e.providerHealth.Load(); h != nil {
+		return h.monitor
+	}
+	return nil
+}
+
+// providerCircuitOpen reports whether switching to the named provider should
+// be confirmed first.
+func (e *Engine) providerCircuitOpen(name string) bool {
+	m := e.ProviderHealth()
+	return m != nil && m.IsOpen(name)
+}
+
+// providerHealthIcon returns the status icon of a provider's circuit, or ""
+// when probing is off.
+func (e *Engine) providerHealthIcon(name string) string {
+	m := e.ProviderHealth()
+	if m == nil {
+		return ""
+	}
+	switch m.Health(name).State {
+	case CircuitClosed:
+		return "🟢"
+	case CircuitOpen:
+		return "🔴"
+	case CircuitHalfOpen:
+		return "🟡"
+	default:
+		return "⚪"
+	}
+}
+
+// providerHealthText describes a provider's circuit state for chat.
+func (e *Engine) providerHealthText(h ProviderHealth) string {
+	switch h.State {
+	case CircuitClosed:
+		return e.i18n.T(MsgProviderHealthOK)
+	case CircuitOpen:
+		text := e.i18n.Tf(MsgProviderHealthOpen, h.Failures)
+		if h.LastError != "" {
+			text += ": " + h.LastError
+		}
+		return text
+	case CircuitHalfOpen:
+		return e.i18n.T(MsgProviderHealthHalfOpen)
+	default:
+		return e.i18n.T(MsgProviderHealthUnknown)
+	}
+}
+
+// providerHealthBadge renders a provider's circuit state with its icon, or
+// "" when probing is off.
+func (e *Engine) providerHealthBadge(name string) string {
+	icon := e.providerHealthIcon(name)
+	if icon == "" {
+		return ""
+	}
+	return icon + " " + e.providerHealthText(e.ProviderHealth().Health(name))
+}
+
+// statusProviderLine renders the active provider and its health for /status,
+// or "" when the agent has no active provider.
+func (e *Engine) statusProviderLine(agent Agent) string {
+	ps, ok := agent.(ProviderSwitcher)
+	if !ok {
+		return ""
+	}
+	name := activeProviderName(ps)
+	if name == "" {
+		return ""
+	}
+	if badge := e.providerHealthBadge(name); badge != "" {
+		name += " — " + badge
+	}
+	return e.i18n.Tf(MsgStatusProvider, name)
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProviderProbeURL(t *testing.T) {
+	cases := []struct {
+		p    ProviderConfig
+		want string
+	}{
+		{ProviderConfig{BaseURL: "https://api.example.com"}, "https://api.example.com/v1/models"},
+		{ProviderConfig{BaseURL: "https://api.example.com/v1/"}, "https://api.example.com/v1/models"},
+		{ProviderConfig{Env: map[string]string{"ANTHROPIC_BASE_URL": "https://proxy.local"}}, "https://proxy.local/v1/models"},
+		{ProviderConfig{Name: "default"}, ""},
+	}
+	for _, c := range cases {
+		if got := providerProbeURL(c.p); got != c.want {
+			t.Errorf("providerProbeURL(%+v) = %q, want %q", c.p, got, c.want)
+		}
+	}
+}
+
+func TestProviderHealthMonitor_ProbeOpensAndClosesCircuit(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var gotKey, gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey, gotPath = r.Header.Get("x-api-key"), r.URL.Path
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	prov := ProviderConfig{Name: "stub", BaseURL: srv.URL, APIKey: "sk-test"}
+	m := NewProviderHealthMonitor(ProviderHealthCfg{FailureThreshold: 2, OpenDuration: time.Minute}, func() []ProviderConfig {
+		return []ProviderConfig{prov}
+	})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := m.Probe(ctx, prov); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if gotKey != "sk-test" || gotPath != "/v1/models" {
+		t.Fatalf("probe sent key=%q path=%q", gotKey, gotPath)
+	}
+	if h := m.Health("stub"); h.State != CircuitClosed {
+		t.Fatalf("state = %q, want closed", h.State)
+	}
+
+	status.Store(http.StatusServiceUnavailable)
+	m.ProbeAll(ctx)
+	if h := m.Health("stub"); h.State != CircuitClosed || h.Failures != 1 {
+		t.Fatalf("after one failure: %+v, want closed with 1 failure", h)
+	}
+	m.ProbeAll(ctx)
+	if h := m.Health("stub"); h.State != CircuitOpen || h.LastError != "HTTP 503" {
+		t.Fatalf("after threshold: %+v, want open", h)
+	}
+
+	// Open circuits are not probed until OpenDuration has passed.
+	status.Store(http.StatusOK)
+	m.ProbeAll(ctx)
+	if !m.IsOpen("stub") {
+		t.Fatal("circuit closed before OpenDuration")
+	}
+	now = now.Add(time.Minute)
+	if h := m.Health("stub"); h.State != CircuitHalfOpen {
+		t.Fatalf("state = %q, want half_open", h.State)
+	}
+	m.ProbeAll(ctx)
+	if h := m.Health("stub"); h.State != CircuitClosed || h.Failures != 0 {
+		t.Fatalf("after recovery: %+v, want closed", h)
+	}
+}
+
+func TestProviderHealthMonitor_HalfOpenFailureReopens(t *testing.T) {
+	m := NewProviderHealthMonitor(ProviderHealthCfg{FailureThreshold: 3, OpenDuration: time.Minute}, nil)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		m.RecordFailure("p", errors.New("HTTP 429"))
+	}
+	now = now.Add(2 * time.Minute)
+	m.RecordFailure("p", errors.New("HTTP 429"))
+	if h := m.Health("p"); h.State != CircuitOpen || !h.OpenedAt.Equal(now) {
+		t.Fatalf("half-open failure: %+v, want reopened now", h)
+	}
+}
+
+func openProviderCircuit(t *testing.T, e *Engine, name string) {
+	t.Helper()
+	e.SetProviderHealthCheck(ProviderHealthCfg{Interval: time.Hour, FailureThreshold: 1})
+	e.ProviderHealth().RecordFailure(name, errors.New("HTTP 529"))
+	if !e.providerCircuitOpen(name) {
+		t.Fatalf("circuit of %s not open", name)
+	}
+}
+
+func TestCmdProvider_SwitchToOpenCircuitNeedsConfirm(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	agent := &stubProviderAgent{
+		providers: []ProviderConfig{{Name: "main"}, {Name: "backup"}},
+		active:    "main",
+	}
+	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
+	defer e.cancel()
+	openProviderCircuit(t, e, "backup")
+	msg := &Message{SessionKey: "test:user1", ReplyCtx: "ctx"}
+
+	e.cmdProvider(p, msg, []string{"switch", "backup"})
+	if agent.active != "main" {
+		t.Fatalf("switched to open circuit without confirm")
+	}
+	sent := p.getSent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "/provider switch backup confirm") {
+		t.Fatalf("sent = %q, want confirmation hint", sent)
+	}
+
+	e.cmdProvider(p, msg, []string{"switch", "backup", "confirm"})
+	if agent.active != "backup" {
+		t.Fatalf("active = %q, want backup after confirm", agent.active)
+	}
+}
+
+func TestCmdProvider_ListShowsHealth(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	agent := &stubProviderAgent{
+		providers: []ProviderConfig{{Name: "main"}, {Name: "backup"}},
+		active:    "main",
+	}
+	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
+	defer e.cancel()
+	openProviderCircuit(t, e, "backup")
+
+	e.cmdProvider(p, &Message{SessionKey: "test:user1", ReplyCtx: "ctx"}, []string{"list"})
+	sent := p.getSent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "backup — 🔴") || !strings.Contains(sent[0], "main — ⚪") {
+		t.Fatalf("sent = %q, want health badges", sent)
+	}
+}
+
+func TestProviderFailover_SkipsOpenCircuit(t *testing.T) {
+	agent := newFailoverTestAgent("main", "main", "backup", "spare")
+	e := NewEngine("test", agent, nil, "", LangEnglish)
+	defer e.cancel()
+	e.SetProviderFailover([]string{"main", "backup", "spare"}, time.Minute)
+	openProviderCircuit(t, e, "backup")
+
+	if to := e.providerFailoverTarget(agent, errors.New("API Error: 429")); to != "spare" {
+		t.Fatalf("failover target = %q, want spare", to)
+	}
+	if h := e.ProviderHealth().Health("main"); h.Failures != 1 {
+		t.Fatalf("outage not recorded against main: %+v", h)
+	}
+}
+
+func TestMgmt_ProjectProviders_ActivateOpenCircuit(t *testing.T) {
+	agent := &stubProviderAgent{
+		providers: []ProviderConfig{{Name: "openai"}, {Name: "claude"}},
+		active:    "openai",
+	}
+	e := NewEngine("proj", agent, nil, "", LangEnglish)
+	defer e.cancel()
+	openProviderCircuit(t, e, "claude")
+	mgmt := NewManagementServer(0, "tok", nil)
+	mgmt.RegisterEngine("proj", e)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/projects/", mgmt.wrap(mgmt.handleProjectRoutes))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	r := mgmtPost(t, ts.URL+"/api/v1/projects/proj/providers/claude/activate", "tok", nil)
+	if r.OK || agent.active != "openai" {
+		t.Fatalf("activated open circuit without force: %+v", r)
+	}
+	r = mgmtPost(t, ts.URL+"/api/v1/projects/proj/providers/claude/activate?force=true", "tok", nil)
+	if !r.OK || agent.active != "claude" {
+		t.Fatalf("force activate failed: %s", r.Error)
+	}
+}
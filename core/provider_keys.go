@@ -0,0 +1,366 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeyCooldown is how long a key that hit a rate limit is skipped
+// when picking keys for new agent sessions.
+const DefaultAPIKeyCooldown = 5 * time.Minute
+
+// Key rotation strategies for providers with several API keys.
+const (
+	KeyRotationRoundRobin   = "round_robin"   // cycle through the keys in order
+	KeyRotationLeastLimited = "least_limited" // prefer the key rate limited longest ago
+)
+
+// providerRateLimitPattern matches 429 responses and rate-limit wording.
+var providerRateLimitPattern = regexp.MustCompile(`(?i)(?:api error|status(?: code)?|http(?:/[0-9.]+)?|error code)\W{0,3}429\b|rate[ _-]?limit|too many requests`)
+
+// IsProviderRateLimit reports whether err is a rate limit (HTTP 429) rather
+// than another kind of provider outage.
+func IsProviderRateLimit(err error) bool {
+	return err != nil && providerRateLimitPattern.MatchString(err.Error())
+}
+
+// APIKeyUsage reports how one key of a provider's key pool has been used.
+type APIKeyUsage struct {
+	Key           string    `json:"key"` // masked
+	Sessions      int       `json:"sessions"`
+	RateLimits    int       `json:"rate_limits"`
+	LastUsed      time.Time `json:"last_used,omitempty"`
+	LastLimited   time.Time `json:"last_limited,omitempty"`
+	CooldownUntil time.Time `json:"cooldown_until,omitempty"`
+}
+
+type apiKeyState struct {
+	key           string
+	sessions      int
+	rateLimits    int
+	lastUsed      time.Time
+	lastLimited   time.Time
+	cooldownUntil time.Time
+}
+
+// APIKeyPool hands out the API keys of one provider to new agent sessions
+// and keeps rate-limited keys on cooldown.
+type APIKeyPool struct {
+	strategy string
+	cooldown time.Duration
+
+	mu   sync.Mutex
+	keys []*apiKeyState
+	next int // round-robin cursor
+}
+
+// NewAPIKeyPool creates a pool over keys. Unknown strategies fall back to
+// round-robin; cooldown <= 0 means DefaultAPIKeyCooldown.
+func NewAPIKeyPool(keys []string, strategy string, cooldown time.Duration) *APIKeyPool {
+	if strategy != KeyRotationLeastLimited {
+		strategy = KeyRotationRoundRobin
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultAPIKeyCooldown
+	}
+	p := &APIKeyPool{strategy: strategy, cooldown: cooldown}
+	for _, k := range keys {
+		p.keys = append(p.keys, &apiKeyState{key: k})
+	}
+	return p
+}
+
+// Acquire picks the key for a new agent session and counts it as used. Keys
+// on cooldown are skipped; when every key is cooling down, the one whose
+// cooldown ends first is used.
+func (p *APIKeyPool) Acquire(now time.Time) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return ""
+	}
+	var pick *apiKeyState
+	switch p.strategy {
+	case KeyRotationLeastLimited:
+		for _, k := range p.keys {
+			if now.Before(k.cooldownUntil) {
+				continue
+			}
+			if pick == nil || k.lastLimited.Before(pick.lastLimited) ||
+				(k.lastLimited.Equal(pick.lastLimited) && k.lastUsed.Before(pick.lastUsed)) {
+				pick = k
+			}
+		}
+	default:
+		for i := range p.keys {
+			k := p.keys[(p.next+i)%len(p.keys)]
+			if !now.Before(k.cooldownUntil) {
+				pick = k
+				p.next = (p.next + i + 1) % len(p.keys)
+				break
+			}
+		}
+	}
+	if pick == nil {
+		for _, k := range p.keys {
+			if pick == nil || k.cooldownUntil.Before(pick.cooldownUntil) {
+				pick = k
+			}
+		}
+	}
+	pick.sessions++
+	pick.lastUsed = now
+	return pick.key
+}
+
+// ReportLimited puts key on cooldown after it produced a rate limit.
+func (p *APIKeyPool) ReportLimited(key string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, k := range p.keys {
+		if k.key == key {
+			k.rateLimits++
+			k.lastLimited = now
+			k.cooldownUntil = now.Add(p.cooldown)
+			return
+		}
+	}
+}
+
+// Cooling reports whether key is on cooldown while another key is not, i.e.
+// whether a session using key should move to a different one.
+func (p *APIKeyPool) Cooling(key string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cooling, other := false, false
+	for _, k := range p.keys {
+		if k.key == key {
+			cooling = now.Before(k.cooldownUntil)
+		} else if !now.Before(k.cooldownUntil) {
+			other = true
+		}
+	}
+	return cooling && other
+}
+
+// Usage returns per-key usage with the keys masked.
+func (p *APIKeyPool) Usage() []APIKeyUsage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]APIKeyUsage, len(p.keys))
+	for i, k := range p.keys {
+		out[i] = APIKeyUsage{
+			Key:           MaskAPIKey(k.key),
+			Sessions:      k.sessions,
+			RateLimits:    k.rateLimits,
+			LastUsed:      k.lastUsed,
+			LastLimited:   k.lastLimited,
+			CooldownUntil: k.cooldownUntil,
+		}
+	}
+	return out
+}
+
+// MaskAPIKey shortens a key to its first and last characters for display.
+func MaskAPIKey(key string) string {
+	if len(key) <= 10 {
+		return "***"
+	}
+	return key[:3] + "…" + key[len(key)-4:]
+}
+
+// providerKeyList returns the distinct keys of p's pool: the configured key
+// (APIKey, or the APIKeyEnv entry of Env) followed by APIKeys.
+func providerKeyList(p ProviderConfig) []string {
+	var keys []string
+	add := func(k string) {
+		if k != "" && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	add(providerCurrentKey(p))
+	for _, k := range p.APIKeys {
+		add(k)
+	}
+	return keys
+}
+
+// providerCurrentKey returns the key p currently hands to the agent.
+func providerCurrentKey(p ProviderConfig) string {
+	if p.APIKeyEnv != "" && p.Env[p.APIKeyEnv] != "" {
+		return p.Env[p.APIKeyEnv]
+	}
+	return p.APIKey
+}
+
+// providerKeyEnv returns the session env entries that hand key to agent as
+// the API key of provider p: the APIKeyEnv entry when p sets one, and the
+// agent's own key variables when p passes its key as APIKey.
+func providerKeyEnv(agent Agent, p ProviderConfig, key string) []string {
+	var env []string
+	if p.APIKeyEnv != "" {
+		env = append(env, p.APIKeyEnv+"="+key)
+		if p.APIKey == "" {
+			return env
+		}
+	}
+	if kp, ok := agent.(ProviderKeyEnvProvider); ok {
+		env = append(env, kp.ProviderKeyEnv(key)...)
+	}
+	return env
+}
+
+// providerKeyPools holds the key pools of an engine's providers by name.
+type providerKeyPools struct {
+	mu    sync.Mutex
+	pools map[string]*keyPoolEntry
+}
+
+type keyPoolEntry struct {
+	keys     []string // sorted, to detect config changes
+	strategy string
+	cooldown time.Duration
+	pool     *APIKeyPool
+}
+
+// get returns the pool for p, or nil when p has fewer than two keys. The pool
+// and its usage survive config reloads that keep the same keys.
+func (kp *providerKeyPools) get(p ProviderConfig) *APIKeyPool {
+	keys := providerKeyList(p)
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if len(keys) < 2 {
+		delete(kp.pools, p.Name)
+		return nil
+	}
+	sorted := slices.Sorted(slices.Values(keys))
+	if cur := kp.pools[p.Name]; cur != nil && cur.strategy == p.KeyRotation && cur.cooldown == p.KeyCooldown && slices.Equal(cur.keys, sorted) {
+		return cur.pool
+	}
+	if kp.pools == nil {
+		kp.pools = make(map[string]*keyPoolEntry)
+	}
+	entry := &keyPoolEntry{keys: sorted, strategy: p.KeyRotation, cooldown: p.KeyCooldown, pool: NewAPIKeyPool(keys, p.KeyRotation, p.KeyCooldown)}
+	kp.pools[p.Name] = entry
+	return entry.pool
+}
+
+// lookup returns the existing pool of the named provider, or nil.
+func (kp *providerKeyPools) lookup(name string) *APIKeyPool {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if entry := kp.pools[name]; entry != nil {
+		return entry.pool
+	}
+	return nil
+}
+
+// rotateProviderKey picks the API key for the next agent session of agent
+// from its active provider's key pool. It returns the key and the session env
+// entries that pass it to the session, or "" and nil when the provider has a
+// single key or the agent cannot take a key per session (it implements no
+// ProviderKeyEnvProvider and the provider sets no api_key_env).
+//
+// The agent's providers are left alone: the caller adds env to the session
+// env set right before StartSession, so the key only applies to that session.
+func (e *Engine) rotateProviderKey(agent Agent) (key string, env []string) {
+	ps, ok := agent.(ProviderSwitcher)
+	if !ok {
+		return "", nil
+	}
+	active := ps.GetActiveProvider()
+	if active == nil {
+		return "", nil
+	}
+	if _, ok := agent.(ProviderKeyEnvProvider); !ok && active.APIKeyEnv == "" {
+		return "", nil
+	}
+	pool := e.providerKeys.get(*active)
+	if pool == nil {
+		return "", nil
+	}
+	key = pool.Acquire(time.Now())
+	if key == providerCurrentKey(*active) {
+		return key, nil
+	}
+	return key, providerKeyEnv(agent, *active, key)
+}
+
+// reportProviderKeyLimited puts the key of state's agent session on cooldown
+// when err is a rate limit.
+func (e *Engine) reportProviderKeyLimited(state *interactiveState, err error) {
+	if !IsProviderRateLimit(err) {
+		return
+	}
+	state.mu.Lock()
+	provider, key := state.provider, state.apiKey
+	state.mu.Unlock()
+	if key == "" {
+		return
+	}
+	if pool := e.providerKeys.lookup(provider); pool != nil {
+		pool.ReportLimited(key, time.Now())
+		slog.Warn("provider key rate limited, cooling down", "provider", provider, "key", MaskAPIKey(key))
+	}
+}
+
+// providerKeyCooling reports whether the live session of state uses a key
+// that is cooling down while another key of the provider is available.
+func (e *Engine) providerKeyCooling(state *interactiveState) bool {
+	if state.apiKey == "" {
+		return false
+	}
+	pool := e.providerKeys.lookup(state.provider)
+	return pool != nil && pool.Cooling(state.apiKey, time.Now())
+}
+
+// ProviderKeyUsage returns the per-key usage of p, or nil when p has a
+// single key.
+func (e *Engine) ProviderKeyUsage(p ProviderConfig) []APIKeyUsage {
+	if pool := e.providerKeys.get(p); pool != nil {
+		return pool.Usage()
+	}
+	return nil
+}
+
+// cmdProviderKeys handles /provider keys [name]: per-key usage of the named
+// or active provider's key pool.
+func (e *Engine) cmdProviderKeys(p Platform, msg *Message, switcher ProviderSwitcher, args []string) {
+	var prov *ProviderConfig
+	if len(args) > 0 {
+		for _, c := range switcher.ListProviders() {
+			if c.Name == args[0] {
+				prov = &c
+				break
+			}
+		}
+		if prov == nil {
+			e.reply(p, msg.ReplyCtx, fmt.Sprintf(e.i18n.T(MsgProviderNotFound), args[0]))
+			return
+		}
+	} else if prov = switcher.GetActiveProvider(); prov == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgProviderNone))
+		return
+	}
+	usage := e.ProviderKeyUsage(*prov)
+	if len(usage) == 0 {
+		e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgProviderKeysSingle, prov.Name))
+		return
+	}
+	now := time.Now()
+	var sb strings.Builder
+	sb.WriteString(e.i18n.Tf(MsgProviderKeysTitle, prov.Name))
+	for _, u := range usage {
+		sb.WriteString(fmt.Sprintf("\n`%s` — %s", u.Key, e.i18n.Tf(MsgProviderKeyUsage, u.Sessions, u.RateLimits)))
+		if now.Before(u.CooldownUntil) {
+			left := formatDurationI18n(u.CooldownUntil.Sub(now).Round(time.Second), e.i18n.CurrentLang())
+			sb.WriteString(" · " + e.i18n.Tf(MsgProviderKeyCooling, left))
+		}
+	}
+	e.reply(p, msg.ReplyCtx, sb.String())
+}
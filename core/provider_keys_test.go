@@ -0,0 +1,196 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsProviderRateLimit(t *testing.T) {
+	for msg, want := range map[string]bool{
+		"API Error: 429 rate_limit_error":            true,
+		"Too Many Requests":                          true,
+		`API Error: 529 {"type":"overloaded_error"}`: false,
+		"HTTP/1.1 503 Service Unavailable":           false,
+		"edited line 429 of main.go":                 false,
+	} {
+		if got := IsProviderRateLimit(errors.New(msg)); got != want {
+			t.Errorf("IsProviderRateLimit(%q) = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestAPIKeyPool_RoundRobinSkipsCoolingKeys(t *testing.T) {
+	pool := NewAPIKeyPool([]string{"k1", "k2", "k3"}, "", time.Minute)
+	now := time.Now()
+	var got []string
+	for i := 0; i < 4; i++ {
+		got = append(got, pool.Acquire(now))
+	}
+	if strings.Join(got, ",") != "k1,k2,k3,k1" {
+		t.Fatalf("round robin = %v", got)
+	}
+
+	pool.ReportLimited("k2", now)
+	if k := pool.Acquire(now); k != "k3" {
+		t.Fatalf("after k2 limited got %q, want k3 (k2 skipped)", k)
+	}
+	if !pool.Cooling("k2", now) || pool.Cooling("k1", now) {
+		t.Fatal("only k2 should be cooling")
+	}
+	if k := pool.Acquire(now.Add(time.Minute)); k != "k1" {
+		t.Fatalf("got %q, want k1", k)
+	}
+	if k := pool.Acquire(now.Add(time.Minute)); k != "k2" {
+		t.Fatalf("got %q, want k2 back after cooldown", k)
+	}
+
+	usage := pool.Usage()
+	if len(usage) != 3 || usage[1].Sessions != 2 || usage[1].RateLimits != 1 || usage[1].Key != "***" {
+		t.Fatalf("usage = %+v", usage)
+	}
+}
+
+func TestAPIKeyPool_LeastLimited(t *testing.T) {
+	pool := NewAPIKeyPool([]string{"k1", "k2", "k3"}, KeyRotationLeastLimited, time.Minute)
+	now := time.Now()
+	pool.ReportLimited("k1", now.Add(-time.Hour))
+	pool.ReportLimited("k3", now.Add(-2*time.Hour))
+	if k := pool.Acquire(now); k != "k2" {
+		t.Fatalf("got %q, want never-limited k2", k)
+	}
+	if k := pool.Acquire(now); k != "k2" {
+		t.Fatalf("got %q, want k2 again while it is not limited", k)
+	}
+	pool.ReportLimited("k2", now.Add(-time.Minute))
+	if k := pool.Acquire(now); k != "k3" {
+		t.Fatalf("got %q, want k3 (limited longest ago)", k)
+	}
+
+	// All keys cooling: use the one that recovers first.
+	pool.ReportLimited("k2", now)
+	pool.ReportLimited("k1", now.Add(time.Second))
+	pool.ReportLimited("k3", now.Add(2*time.Second))
+	if k := pool.Acquire(now.Add(3 * time.Second)); k != "k2" {
+		t.Fatalf("got %q, want k2", k)
+	}
+}
+
+func TestMaskAPIKey(t *testing.T) {
+	if got := MaskAPIKey("sk-ant-api03-abcdefgh1234"); got != "sk-…1234" {
+		t.Fatalf("MaskAPIKey = %q", got)
+	}
+	if got := MaskAPIKey("short"); got != "***" {
+		t.Fatalf("MaskAPIKey(short) = %q", got)
+	}
+}
+
+// keyEnvProviderAgent takes a provider key per session through STUB_API_KEY.
+type keyEnvProviderAgent struct {
+	stubProviderAgent
+}
+
+func (a *keyEnvProviderAgent) ProviderKeyEnv(key string) []string {
+	return []string{"STUB_API_KEY=" + key}
+}
+
+func TestRotateProviderKey_PassesKeyInSessionEnv(t *testing.T) {
+	agent := &keyEnvProviderAgent{stubProviderAgent{
+		providers: []ProviderConfig{
+			{Name: "pool", APIKey: "key-one", APIKeys: []string{"key-two"}},
+			{Name: "envpool", APIKeyEnv: "OPENROUTER_API_KEY", APIKeys: []string{"env-a", "env-b"}, Env: map[string]string{"OTHER": "x"}},
+		},
+		active: "pool",
+	}}
+	e := NewEngine("test", agent, nil, "", LangEnglish)
+
+	if k, env := e.rotateProviderKey(agent); k != "key-one" || env != nil {
+		t.Fatalf("first session key = %q, env %q; want the configured key and no env", k, env)
+	}
+	if k, env := e.rotateProviderKey(agent); k != "key-two" || !slices.Equal(env, []string{"STUB_API_KEY=key-two"}) {
+		t.Fatalf("second session key = %q, env %q", k, env)
+	}
+	if agent.GetActiveProvider().APIKey != "key-one" {
+		t.Fatalf("rotation changed the agent's provider: %+v", agent.GetActiveProvider())
+	}
+	if k, _ := e.rotateProviderKey(agent); k != "key-one" {
+		t.Fatalf("third session key = %q, want key-one again", k)
+	}
+
+	agent.SetActiveProvider("envpool")
+	e.rotateProviderKey(agent)
+	k, env := e.rotateProviderKey(agent)
+	if k != "env-b" || !slices.Equal(env, []string{"OPENROUTER_API_KEY=env-b"}) {
+		t.Fatalf("env-injected key = %q, env %q", k, env)
+	}
+	active := agent.GetActiveProvider()
+	if _, ok := active.Env["OPENROUTER_API_KEY"]; ok {
+		t.Fatalf("configured env map was modified: %v", active.Env)
+	}
+	if usage := e.ProviderKeyUsage(*active); len(usage) != 2 || usage[0].Sessions != 1 || usage[1].Sessions != 1 {
+		t.Fatalf("usage = %+v", usage)
+	}
+}
+
+func TestRotateProviderKey_AgentWithoutKeyEnv(t *testing.T) {
+	agent := &stubProviderAgent{
+		providers: []ProviderConfig{{Name: "pool", APIKey: "key-one", APIKeys: []string{"key-two"}}},
+		active:    "pool",
+	}
+	e := NewEngine("test", agent, nil, "", LangEnglish)
+	for range 2 {
+		if k, env := e.rotateProviderKey(agent); k != "" || env != nil {
+			t.Fatalf("agent without ProviderKeyEnv rotated to %q, env %q", k, env)
+		}
+	}
+}
+
+func TestRotateProviderKey_SingleKeyUntouched(t *testing.T) {
+	agent := &stubProviderAgent{providers: []ProviderConfig{{Name: "one", APIKey: "only"}}, active: "one"}
+	e := NewEngine("test", agent, nil, "", LangEnglish)
+	if k, _ := e.rotateProviderKey(agent); k != "" {
+		t.Fatalf("single-key provider rotated to %q", k)
+	}
+}
+
+func TestReportProviderKeyLimited_RestartsOnFreshKey(t *testing.T) {
+	agent := &keyEnvProviderAgent{stubProviderAgent{
+		providers: []ProviderConfig{{Name: "pool", APIKey: "key-one", APIKeys: []string{"key-two"}}},
+		active:    "pool",
+	}}
+	e := NewEngine("test", agent, nil, "", LangEnglish)
+	key, _ := e.rotateProviderKey(agent)
+	state := &interactiveState{provider: "pool", apiKey: key}
+
+	e.reportProviderKeyLimited(state, errors.New("API Error: 500 internal"))
+	if e.providerKeyCooling(state) {
+		t.Fatal("non-429 error put the key on cooldown")
+	}
+	e.reportProviderKeyLimited(state, errors.New("API Error: 429 rate_limit_error"))
+	if !e.providerKeyCooling(state) {
+		t.Fatal("rate-limited key not cooling")
+	}
+	if k, _ := e.rotateProviderKey(agent); k != "key-two" {
+		t.Fatalf("next session key = %q, want key-two", k)
+	}
+}
+
+func TestCmdProviderKeys_ReportsUsage(t *testing.T) {
+	p := &stubPlatformEngine{n: "test"}
+	agent := &keyEnvProviderAgent{stubProviderAgent{
+		providers: []ProviderConfig{{Name: "pool", APIKey: "sk-first-key-0001", APIKeys: []string{"sk-second-key-0002"}}},
+		active:    "pool",
+	}}
+	e := NewEngine("test", agent, []Platform{p}, "", LangEnglish)
+	key, _ := e.rotateProviderKey(agent)
+	e.reportProviderKeyLimited(&interactiveState{provider: "pool", apiKey: key}, errors.New("429 Too Many Requests"))
+
+	e.cmdProvider(p, &Message{SessionKey: "test:user1", ReplyCtx: "ctx"}, []string{"keys"})
+	sent := p.getSent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "`sk-…0001` — 1 sessions, 1 rate limits · cooling down") ||
+		!strings.Contains(sent[0], "`sk-…0002` — 0 sessions, 0 rate limits") || strings.Contains(sent[0], "sk-first") {
+		t.Fatalf("sent = %q", sent)
+	}
+}
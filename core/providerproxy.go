@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,23 +11,75 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 )
 
+// ProxyRules are the declarative request rewrites ProviderProxy applies to
+// Anthropic Messages requests (POST .../messages) before forwarding them, so
+// one Claude Code binary can talk to many Anthropic-compatible vendors.
+type ProxyRules struct {
+	// Protocol is the API the upstream speaks: "" (Anthropic Messages) or
+	// ProxyProtocolOpenAI, which translates Messages requests to Chat
+	// Completions and the responses back.
+	Protocol string
+	// Thinking rewrites thinking.type "adaptive" to this value
+	// ("disabled" or "enabled"); empty leaves it alone.
+	Thinking string
+	// ModelMap maps request model names to vendor model IDs. A key ending in
+	// "*" matches by prefix, e.g. "claude-sonnet-*".
+	ModelMap map[string]string
+	// StripFields are body fields removed at the top level and from the
+	// blocks of system, tools and message content, e.g. "cache_control".
+	StripFields []string
+	// StripBetas are values removed from the anthropic-beta header.
+	StripBetas []string
+	// Headers are set on every upstream request.
+	Headers map[string]string
+	// MaxTokens caps max_tokens (0 = no cap).
+	MaxTokens int
+	// LogTraffic logs request and response metadata, including token usage
+	// parsed from the response or its SSE stream.
+	LogTraffic bool
+	// CassetteMode is CassetteRecord or CassetteReplay; CassetteDir holds
+	// the recorded request/response pairs.
+	CassetteMode string
+	CassetteDir  string
+	// CassetteLooseMatch lets a replayed request that matches no recording
+	// take the next unused one with the same method and path.
+	CassetteLooseMatch bool
+	// Secrets are masked in recordings, along with the credential a request
+	// carries and the values of Headers. Agents set the provider's API keys.
+	Secrets []string
+}
+
+// ProxyProtocolOpenAI makes ProviderProxy translate Anthropic Messages
+// requests for an OpenAI-compatible /v1/chat/completions endpoint.
+const ProxyProtocolOpenAI = "openai"
+
+// Empty reports whether the rules leave requests untouched.
+func (r ProxyRules) Empty() bool {
+	return r.Protocol == "" && r.Thinking == "" && len(r.ModelMap) == 0 && len(r.StripFields) == 0 &&
+		len(r.StripBetas) == 0 && len(r.Headers) == 0 && r.MaxTokens <= 0 && !r.LogTraffic &&
+		r.CassetteMode == ""
+}
+
 // ProviderProxy is a lightweight local reverse proxy that rewrites
-// incompatible Anthropic API fields for third-party providers.
+// Anthropic API requests for third-party providers according to
+// ProxyRules.
 //
 // Some providers (e.g. SiliconFlow) don't support thinking.type "adaptive"
-// sent by Claude Code 2.x. The proxy rewrites the thinking field to
-// the configured override value before forwarding.
+// sent by Claude Code 2.x, use their own model IDs or reject fields such as
+// cache_control. The proxy fixes the request up before forwarding it.
 type ProviderProxy struct {
-	targetURL        string
-	thinkingOverride string
-	listener         net.Listener
-	server           *http.Server
-	once             sync.Once
+	targetURL string
+	rules     ProxyRules
+	listener  net.Listener
+	server    *http.Server
+	once      sync.Once
 }
 
 // NewProviderProxy creates and starts a local reverse proxy for the
@@ -34,6 +87,13 @@ type ProviderProxy struct {
 // rewrite "adaptive" to (e.g. "disabled" or "enabled").
 // Returns the local URL to use as ANTHROPIC_BASE_URL.
 func NewProviderProxy(targetURL, thinkingOverride string) (*ProviderProxy, string, error) {
+	return NewProviderProxyWithRules(targetURL, ProxyRules{Thinking: thinkingOverride})
+}
+
+// NewProviderProxyWithRules creates and starts a local reverse proxy for
+// the given upstream URL that applies rules to every Messages request.
+// Returns the local URL to use as ANTHROPIC_BASE_URL.
+func NewProviderProxyWithRules(targetURL string, rules ProxyRules) (*ProviderProxy, string, error) {
 	target, err := url.Parse(strings.TrimRight(targetURL, "/"))
 	if err != nil {
 		return nil, "", fmt.Errorf("providerproxy: parse target: %w", err)
@@ -51,22 +111,61 @@ func NewProviderProxy(targetURL, thinkingOverride string) (*ProviderProxy, strin
 		req.Host = target.Host
 	}
 	proxy.FlushInterval = -1 // flush SSE events immediately
+	if rules.LogTraffic {
+		proxy.ModifyResponse = logProxyResponse
+	}
+
+	var gateway *openAIGateway
+	if rules.Protocol == ProxyProtocolOpenAI {
+		gateway = newOpenAIGateway(target)
+	}
 
-	override := thinkingOverride
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/messages") {
-			rewriteThinkingInRequest(r, override)
+			model, upstreamModel := rewriteMessagesRequest(r, rules)
+			var meta *proxyTraffic
+			if rules.LogTraffic {
+				meta = &proxyTraffic{path: r.URL.Path, model: model, upstreamModel: upstreamModel, start: time.Now()}
+				r = r.WithContext(context.WithValue(r.Context(), proxyTrafficKey{}, meta))
+			}
+			if gateway != nil {
+				gateway.serveMessages(w, r, meta)
+				return
+			}
+		}
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/messages/count_tokens") {
+			// Token counting takes a Messages body, so it must name the
+			// same upstream model and carry the same headers.
+			rewriteMessagesRequest(r, rules)
+			if gateway != nil {
+				gateway.serveCountTokens(w, r)
+				return
+			}
 		}
 		proxy.ServeHTTP(w, r)
 	})
 
+	var handler http.Handler = mux
+	switch rules.CassetteMode {
+	case CassetteRecord:
+		if handler, err = newCassetteRecorder(rules, mux); err != nil {
+			listener.Close()
+			return nil, "", err
+		}
+	case CassetteReplay:
+		if handler, err = newCassetteReplayer(rules); err != nil {
+			listener.Close()
+			return nil, "", err
+		}
+	}
+
 	pp := &ProviderProxy{
-		targetURL:        targetURL,
-		thinkingOverride: thinkingOverride,
-		listener:         listener,
+		targetURL: targetURL,
+		rules:     rules,
+		listener:  listener,
 		server: &http.Server{
-			Handler:      mux,
+			Handler:      handler,
 			ReadTimeout:  10 * time.Minute,
 			WriteTimeout: 10 * time.Minute,
 		},
@@ -79,10 +178,16 @@ func NewProviderProxy(targetURL, thinkingOverride string) (*ProviderProxy, strin
 	}()
 
 	localURL := fmt.Sprintf("http://127.0.0.1:%d", listener.Addr().(*net.TCPAddr).Port)
-	slog.Info("providerproxy: started", "target", targetURL, "local", localURL, "thinking", thinkingOverride)
+	slog.Info("providerproxy: started", "target", targetURL, "local", localURL, "thinking", rules.Thinking, "protocol", rules.Protocol, "cassette", rules.CassetteMode)
 	return pp, localURL, nil
 }
 
+// Serves reports whether the proxy forwards to targetURL with rules, so a
+// caller can tell when a provider change requires a new proxy.
+func (pp *ProviderProxy) Serves(targetURL string, rules ProxyRules) bool {
+	return pp.targetURL == targetURL && reflect.DeepEqual(pp.rules, rules)
+}
+
 // Close shuts down the proxy.
 func (pp *ProviderProxy) Close() {
 	pp.once.Do(func() {
@@ -90,50 +195,322 @@ func (pp *ProviderProxy) Close() {
 	})
 }
 
-// rewriteThinkingInRequest reads the request body and rewrites
-// thinking.type "adaptive" to the given override value.
-func rewriteThinkingInRequest(r *http.Request, override string) {
-	if r.Body == nil || override == "" {
-		return
+// rewriteMessagesRequest applies rules to a Messages or count_tokens
+// request: headers first, then the JSON body. It returns the requested model and the model sent
+// upstream.
+func rewriteMessagesRequest(r *http.Request, rules ProxyRules) (model, upstreamModel string) {
+	for k, v := range rules.Headers {
+		r.Header.Set(k, v)
+	}
+	if len(rules.StripBetas) > 0 {
+		stripBetaHeader(r.Header, rules.StripBetas)
+	}
+	if r.Body == nil {
+		return "", ""
 	}
 	body, err := io.ReadAll(r.Body)
 	r.Body.Close()
 	if err != nil {
 		r.Body = io.NopCloser(bytes.NewReader(body))
-		return
+		return "", ""
 	}
 
 	var data map[string]any
 	if err := json.Unmarshal(body, &data); err != nil {
 		r.Body = io.NopCloser(bytes.NewReader(body))
 		r.ContentLength = int64(len(body))
-		return
+		return "", ""
 	}
+	model, _ = data["model"].(string)
+	upstreamModel = model
 
 	modified := false
-	if thinking, ok := data["thinking"].(map[string]any); ok {
-		if t, ok := thinking["type"].(string); ok && t == "adaptive" {
-			thinking["type"] = override
-			if override == "disabled" {
-				delete(thinking, "budget_tokens")
+	if rules.Thinking != "" {
+		if thinking, ok := data["thinking"].(map[string]any); ok {
+			if t, ok := thinking["type"].(string); ok && t == "adaptive" {
+				thinking["type"] = rules.Thinking
+				if rules.Thinking == "disabled" {
+					delete(thinking, "budget_tokens")
+				}
+				modified = true
+				slog.Debug("providerproxy: rewrote thinking adaptive →", "override", rules.Thinking)
 			}
+		}
+	}
+	if mapped := mapProxyModel(rules.ModelMap, model); mapped != "" && mapped != model {
+		data["model"] = mapped
+		upstreamModel = mapped
+		modified = true
+	}
+	for _, field := range rules.StripFields {
+		if stripJSONField(data, field) {
 			modified = true
-			slog.Debug("providerproxy: rewrote thinking adaptive →", "override", override)
 		}
 	}
+	if rules.MaxTokens > 0 && capMaxTokens(data, rules.MaxTokens) {
+		modified = true
+	}
 
 	if !modified {
 		r.Body = io.NopCloser(bytes.NewReader(body))
 		r.ContentLength = int64(len(body))
-		return
+		return model, upstreamModel
 	}
 
 	newBody, err := json.Marshal(data)
 	if err != nil {
 		r.Body = io.NopCloser(bytes.NewReader(body))
 		r.ContentLength = int64(len(body))
-		return
+		return model, model
 	}
 	r.Body = io.NopCloser(bytes.NewReader(newBody))
 	r.ContentLength = int64(len(newBody))
+	return model, upstreamModel
+}
+
+// mapProxyModel returns the vendor ID for model: an exact key wins over the
+// longest matching "prefix*" key. It returns "" when nothing matches.
+func mapProxyModel(m map[string]string, model string) string {
+	if model == "" || len(m) == 0 {
+		return ""
+	}
+	if v, ok := m[model]; ok {
+		return v
+	}
+	best, bestLen := "", -1
+	for k, v := range m {
+		prefix, ok := strings.CutSuffix(k, "*")
+		if ok && strings.HasPrefix(model, prefix) && len(prefix) > bestLen {
+			best, bestLen = v, len(prefix)
+		}
+	}
+	return best
+}
+
+// stripJSONField deletes field where the Messages API defines request
+// fields: the top level and the blocks of system, tools and each message's
+// content. Tool inputs, tool results and other user data are left alone.
+// It reports whether anything was removed.
+func stripJSONField(data map[string]any, field string) bool {
+	removed := deleteJSONField(data, field)
+	for _, key := range []string{"system", "tools"} {
+		if stripJSONFieldInBlocks(data[key], field) {
+			removed = true
+		}
+	}
+	if messages, ok := data["messages"].([]any); ok {
+		for _, m := range messages {
+			if msg, ok := m.(map[string]any); ok && stripJSONFieldInBlocks(msg["content"], field) {
+				removed = true
+			}
+		}
+	}
+	return removed
+}
+
+// stripJSONFieldInBlocks deletes field from each object of the list blocks.
+func stripJSONFieldInBlocks(blocks any, field string) bool {
+	list, ok := blocks.([]any)
+	if !ok {
+		return false
+	}
+	removed := false
+	for _, b := range list {
+		if block, ok := b.(map[string]any); ok && deleteJSONField(block, field) {
+			removed = true
+		}
+	}
+	return removed
+}
+
+func deleteJSONField(obj map[string]any, field string) bool {
+	if _, ok := obj[field]; !ok {
+		return false
+	}
+	delete(obj, field)
+	return true
+}
+
+// capMaxTokens lowers max_tokens to limit, keeping an extended-thinking
+// budget below it as the Messages API requires.
+func capMaxTokens(data map[string]any, limit int) bool {
+	cur, ok := data["max_tokens"].(float64)
+	if !ok || cur <= float64(limit) {
+		return false
+	}
+	data["max_tokens"] = limit
+	if thinking, ok := data["thinking"].(map[string]any); ok {
+		if budget, ok := thinking["budget_tokens"].(float64); ok && budget >= float64(limit) {
+			thinking["budget_tokens"] = max(limit-1, 1)
+		}
+	}
+	return true
+}
+
+// stripBetaHeader removes the given values from the comma-separated
+// anthropic-beta header.
+func stripBetaHeader(h http.Header, strip []string) {
+	values := h.Values("anthropic-beta")
+	if len(values) == 0 {
+		return
+	}
+	var kept []string
+	for _, v := range values {
+		for _, beta := range strings.Split(v, ",") {
+			beta = strings.TrimSpace(beta)
+			if beta != "" && !slices.Contains(strip, beta) {
+				kept = append(kept, beta)
+			}
+		}
+	}
+	if len(kept) == 0 {
+		h.Del("anthropic-beta")
+		return
+	}
+	h.Set("anthropic-beta", strings.Join(kept, ","))
+}
+
+type proxyTrafficKey struct{}
+
+// proxyTraffic is the metadata logged for one proxied Messages request.
+type proxyTraffic struct {
+	path          string
+	model         string
+	upstreamModel string
+	start         time.Time
+}
+
+func (m *proxyTraffic) log(status int, stream bool, u proxyUsage) {
+	slog.Info("providerproxy: request",
+		"path", m.path,
+		"model", m.model,
+		"upstream_model", m.upstreamModel,
+		"status", status,
+		"stream", stream,
+		"input_tokens", u.InputTokens,
+		"output_tokens", u.OutputTokens,
+		"cache_creation_tokens", u.CacheCreationTokens,
+		"cache_read_tokens", u.CacheReadTokens,
+		"elapsed", time.Since(m.start).Round(time.Millisecond),
+	)
+}
+
+// proxyUsage is the token usage reported by the upstream.
+type proxyUsage struct {
+	InputTokens         int `json:"input_tokens"`
+	OutputTokens        int `json:"output_tokens"`
+	CacheCreationTokens int `json:"cache_creation_input_tokens"`
+	CacheReadTokens     int `json:"cache_read_input_tokens"`
+}
+
+// add merges a usage report; streams report input tokens in message_start
+// and cumulative output tokens in message_delta.
+func (u *proxyUsage) add(o proxyUsage) {
+	if o.InputTokens > 0 {
+		u.InputTokens = o.InputTokens
+	}
+	if o.OutputTokens > 0 {
+		u.OutputTokens = o.OutputTokens
+	}
+	if o.CacheCreationTokens > 0 {
+		u.CacheCreationTokens = o.CacheCreationTokens
+	}
+	if o.CacheReadTokens > 0 {
+		u.CacheReadTokens = o.CacheReadTokens
+	}
+}
+
+// logProxyResponse wraps the response body so its token usage is parsed as
+// it streams through and logged when the body is closed.
+func logProxyResponse(resp *http.Response) error {
+	meta, _ := resp.Request.Context().Value(proxyTrafficKey{}).(*proxyTraffic)
+	if meta == nil {
+		return nil
+	}
+	resp.Body = &usageTapBody{
+		ReadCloser: resp.Body,
+		meta:       meta,
+		status:     resp.StatusCode,
+		sse:        strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"),
+	}
+	return nil
+}
+
+// usageTapBody passes a response body through unchanged while collecting
+// token usage from an SSE stream or a JSON body.
+type usageTapBody struct {
+	io.ReadCloser
+	meta   *proxyTraffic
+	status int
+	sse    bool
+
+	buf   bytes.Buffer // partial SSE line, or the JSON body
+	usage proxyUsage
+	once  sync.Once
+}
+
+const usageTapMaxJSON = 1 << 20
+
+func (b *usageTapBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		if b.sse {
+			b.scanSSE(p[:n])
+		} else if b.buf.Len() < usageTapMaxJSON {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		b.log()
+	}
+	return n, err
+}
+
+func (b *usageTapBody) Close() error {
+	b.log()
+	return b.ReadCloser.Close()
+}
+
+func (b *usageTapBody) scanSSE(chunk []byte) {
+	b.buf.Write(chunk)
+	for {
+		line, err := b.buf.ReadBytes('\n')
+		if err != nil {
+			// Incomplete line: keep it for the next chunk.
+			rest := append([]byte(nil), line...)
+			b.buf.Reset()
+			b.buf.Write(rest)
+			return
+		}
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		var ev struct {
+			Type    string `json:"type"`
+			Message struct {
+				Usage proxyUsage `json:"usage"`
+			} `json:"message"`
+			Usage proxyUsage `json:"usage"`
+		}
+		if json.Unmarshal(bytes.TrimSpace(data), &ev) != nil {
+			continue
+		}
+		b.usage.add(ev.Message.Usage)
+		b.usage.add(ev.Usage)
+	}
+}
+
+func (b *usageTapBody) log() {
+	b.once.Do(func() {
+		if !b.sse {
+			var body struct {
+				Usage proxyUsage `json:"usage"`
+			}
+			if json.Unmarshal(b.buf.Bytes(), &body) == nil {
+				b.usage.add(body.Usage)
+			}
+		}
+		b.meta.log(b.status, b.sse, b.usage)
+	})
 }
@@ -0,0 +1,343 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Cassette modes for ProxyRules.CassetteMode.
+const (
+	// CassetteRecord forwards requests as usual and writes every
+	// request/response pair to the cassette directory.
+	CassetteRecord = "record"
+	// CassetteReplay serves responses from the cassette directory and never
+	// contacts the upstream.
+	CassetteReplay = "replay"
+)
+
+// proxyInteraction is one recorded request/response pair, stored as a JSON
+// file in the cassette directory. It is captured at the client side of the
+// proxy, before any rewriting or translation, so a replay needs no rules.
+type proxyInteraction struct {
+	Request  proxyRecordedRequest  `json:"request"`
+	Response proxyRecordedResponse `json:"response"`
+}
+
+type proxyRecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type proxyRecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// cassetteHeaderSkip lists headers that vary per run or are recomputed on
+// replay.
+var cassetteHeaderSkip = map[string]bool{
+	"Date": true, "Content-Length": true, "Transfer-Encoding": true, "Connection": true,
+	"Accept-Encoding": true, "Set-Cookie": true,
+}
+
+// cassetteRedactor masks secrets in recordings: the provider's keys and
+// the values of injected headers, on top of the request credential and the
+// secrets registered with SetRedactedSecrets. Longest first, so a secret
+// that contains another is masked whole.
+type cassetteRedactor []string
+
+func newCassetteRedactor(rules ProxyRules) cassetteRedactor {
+	var secrets cassetteRedactor
+	for _, v := range rules.Secrets {
+		if v != "" {
+			secrets = append(secrets, v)
+		}
+	}
+	for _, v := range rules.Headers {
+		if v != "" {
+			secrets = append(secrets, v)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// text masks the secrets and the credential token in s.
+func (c cassetteRedactor) text(s, token string) string {
+	s = RedactToken(s, token)
+	for _, secret := range c {
+		s = strings.ReplaceAll(s, secret, "[REDACTED]")
+	}
+	return s
+}
+
+// header drops volatile headers, masks credential headers and masks
+// secrets in the values of the others.
+func (c cassetteRedactor) header(h http.Header, token string) http.Header {
+	out := http.Header{}
+	for k, vs := range h {
+		if cassetteHeaderSkip[k] {
+			continue
+		}
+		upper := strings.ToUpper(k)
+		secret := false
+		for _, s := range []string{"KEY", "TOKEN", "SECRET", "AUTHORIZATION", "COOKIE", "PASSWORD"} {
+			if strings.Contains(upper, s) {
+				secret = true
+				break
+			}
+		}
+		if secret {
+			out[k] = []string{"***"}
+			continue
+		}
+		for _, v := range vs {
+			out[k] = append(out[k], c.text(v, token))
+		}
+	}
+	return out
+}
+
+// requestSecret returns the credential the client sent, so it can be
+// scrubbed from bodies as well.
+func requestSecret(h http.Header) string {
+	if k := h.Get("X-Api-Key"); k != "" {
+		return k
+	}
+	auth := h.Get("Authorization")
+	if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+		return token
+	}
+	return auth
+}
+
+// cassetteMatchKey identifies a request for replay. The Messages metadata
+// field carries a per-session user ID and is ignored.
+func cassetteMatchKey(method, path, body string) string {
+	normalized := []byte(body)
+	var data map[string]any
+	if json.Unmarshal(normalized, &data) == nil {
+		delete(data, "metadata")
+		normalized, _ = json.Marshal(data)
+	}
+	sum := sha256.Sum256(normalized)
+	return method + " " + path + " " + hex.EncodeToString(sum[:8])
+}
+
+// cassetteRecorder wraps the proxy handler and writes each exchange to dir
+// as NNNN-METHOD-path.json, numbered after any existing recordings.
+type cassetteRecorder struct {
+	dir    string
+	redact cassetteRedactor
+	next   http.Handler
+
+	mu  sync.Mutex
+	seq int
+}
+
+func newCassetteRecorder(rules ProxyRules, next http.Handler) (*cassetteRecorder, error) {
+	dir := rules.CassetteDir
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("providerproxy: cassette dir: %w", err)
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("providerproxy: cassette dir: %w", err)
+	}
+	return &cassetteRecorder{dir: dir, redact: newCassetteRedactor(rules), next: next, seq: len(existing)}, nil
+}
+
+func (c *cassetteRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+	// Ask for an uncompressed body so the recording stays readable.
+	r.Header.Del("Accept-Encoding")
+	secret := requestSecret(r.Header)
+	rec := proxyInteraction{Request: proxyRecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: c.redact.header(r.Header, secret),
+		Body:   c.redact.text(string(body), secret),
+	}}
+
+	tee := &teeResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	c.next.ServeHTTP(tee, r)
+
+	rec.Response = proxyRecordedResponse{
+		Status: tee.status,
+		Header: c.redact.header(w.Header(), secret),
+		Body:   c.redact.text(tee.body.String(), secret),
+	}
+	if err := c.write(rec); err != nil {
+		slog.Error("providerproxy: record failed", "path", r.URL.Path, "error", err)
+	}
+}
+
+func (c *cassetteRecorder) write(rec proxyInteraction) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.seq++
+	seq := c.seq
+	c.mu.Unlock()
+	slug := strings.Trim(strings.ReplaceAll(rec.Request.Path, "/", "-"), "-")
+	name := fmt.Sprintf("%04d-%s-%s.json", seq, rec.Request.Method, slug)
+	return os.WriteFile(filepath.Join(c.dir, name), append(data, '\n'), 0o644)
+}
+
+// teeResponseWriter copies the response body while passing it (and SSE
+// flushes) through to the client.
+type teeResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (t *teeResponseWriter) WriteHeader(status int) {
+	if !t.wroteHeader {
+		t.status, t.wroteHeader = status, true
+	}
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *teeResponseWriter) Write(p []byte) (int, error) {
+	t.wroteHeader = true
+	t.body.Write(p)
+	return t.ResponseWriter.Write(p)
+}
+
+func (t *teeResponseWriter) Flush() {
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (t *teeResponseWriter) Unwrap() http.ResponseWriter { return t.ResponseWriter }
+
+// cassetteReplayer serves recorded responses. A request gets the first
+// unused recording with the same method, path and body. With loose set it
+// otherwise gets the next unused recording for the same method and path, so
+// turns whose prompts embed volatile context (dates, git status) still
+// replay in order.
+type cassetteReplayer struct {
+	redact cassetteRedactor
+	loose  bool
+
+	mu           sync.Mutex
+	interactions []proxyInteraction
+	keys         []string
+	used         []bool
+}
+
+func newCassetteReplayer(rules ProxyRules) (*cassetteReplayer, error) {
+	dir := rules.CassetteDir
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("providerproxy: cassette dir: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("providerproxy: no recordings in %s", dir)
+	}
+	sort.Strings(files)
+	c := &cassetteReplayer{redact: newCassetteRedactor(rules), loose: rules.CassetteLooseMatch}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("providerproxy: read cassette: %w", err)
+		}
+		var rec proxyInteraction
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("providerproxy: parse cassette %s: %w", filepath.Base(f), err)
+		}
+		c.interactions = append(c.interactions, rec)
+		c.keys = append(c.keys, cassetteMatchKey(rec.Request.Method, rec.Request.Path, rec.Request.Body))
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+func (c *cassetteReplayer) take(method, path, body string) (proxyInteraction, bool) {
+	key := cassetteMatchKey(method, path, body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	match := -1
+	for i, k := range c.keys {
+		if !c.used[i] && k == key {
+			match = i
+			break
+		}
+	}
+	if match < 0 && c.loose {
+		for i, rec := range c.interactions {
+			if !c.used[i] && rec.Request.Method == method && rec.Request.Path == path {
+				slog.Warn("providerproxy: no recording has this request body, replaying the next one for the path",
+					"method", method, "path", path)
+				match = i
+				break
+			}
+		}
+	}
+	if match < 0 {
+		return proxyInteraction{}, false
+	}
+	c.used[match] = true
+	return c.interactions[match], true
+}
+
+func (c *cassetteReplayer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(r.Body)
+		r.Body.Close()
+	}
+	rec, ok := c.take(r.Method, r.URL.Path, c.redact.text(string(body), requestSecret(r.Header)))
+	if !ok {
+		slog.Warn("providerproxy: no matching interaction for request", "method", r.Method, "path", r.URL.Path)
+		writeAnthropicError(w, http.StatusBadGateway, fmt.Sprintf("no matching interaction for %s %s", r.Method, r.URL.Path))
+		return
+	}
+	for k, vs := range rec.Response.Header {
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(rec.Response.Status)
+	flusher, _ := w.(http.Flusher)
+	if flusher == nil || !strings.HasPrefix(rec.Response.Header.Get("Content-Type"), "text/event-stream") {
+		_, _ = io.WriteString(w, rec.Response.Body)
+		return
+	}
+	// Replay SSE event by event, as the upstream delivered it.
+	rest := rec.Response.Body
+	for rest != "" {
+		event, tail, found := strings.Cut(rest, "\n\n")
+		if found {
+			event += "\n\n"
+		}
+		_, _ = io.WriteString(w, event)
+		flusher.Flush()
+		rest = tail
+	}
+}
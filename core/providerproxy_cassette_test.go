@@ -0,0 +1,154 @@
+package core
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProviderProxy_RecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	stream := "event: message_start\ndata: {\"type\":\"message_start\"}\n\n" +
+		"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"echo sk-live-secret-123\"}}\n\n" +
+		"event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"
+	upstream, _ := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Request-Id", "req_1")
+		_, _ = io.WriteString(w, stream)
+	})
+
+	rec, recURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{CassetteMode: CassetteRecord, CassetteDir: dir})
+	if err != nil {
+		t.Fatalf("start recorder: %v", err)
+	}
+	h := http.Header{}
+	h.Set("x-api-key", "sk-live-secret-123")
+	first := `{"model":"claude-sonnet-4-5","stream":true,"metadata":{"user_id":"session-a"},"messages":[{"role":"user","content":"one"}]}`
+	second := `{"model":"claude-sonnet-4-5","stream":true,"messages":[{"role":"user","content":"two"}]}`
+	if out := postThroughProxy(t, recURL, h, first); out != stream {
+		t.Fatalf("recorded response altered: %q", out)
+	}
+	postThroughProxy(t, recURL, h, second)
+	rec.Close()
+	upstream.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 || filepath.Base(files[0]) != "0001-POST-v1-messages.json" {
+		t.Fatalf("cassette files = %v", files)
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), "sk-live-secret-123") {
+			t.Fatalf("%s leaks the API key:\n%s", f, data)
+		}
+	}
+
+	play, playURL, err := NewProviderProxyWithRules("http://upstream.invalid", ProxyRules{CassetteMode: CassetteReplay, CassetteDir: dir})
+	if err != nil {
+		t.Fatalf("start replayer: %v", err)
+	}
+	defer play.Close()
+
+	// The second request matches its own recording even when sent first, and
+	// the metadata of the first one does not affect matching.
+	resp := postThroughProxy(t, playURL, h, second)
+	if !strings.Contains(resp, "[REDACTED]") || !strings.Contains(resp, "message_stop") {
+		t.Fatalf("replayed = %q", resp)
+	}
+	if out := postThroughProxy(t, playURL, h, strings.Replace(first, "session-a", "session-b", 1)); !strings.Contains(out, "message_start") {
+		t.Fatalf("replayed = %q", out)
+	}
+
+	r, err := http.Post(playURL+"/v1/messages", "application/json", strings.NewReader(second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	if r.StatusCode != http.StatusBadGateway {
+		t.Fatalf("exhausted cassette status = %d, want 502", r.StatusCode)
+	}
+}
+
+func TestProviderProxy_RecordRedactsInjectedHeadersAndPoolKeys(t *testing.T) {
+	dir := t.TempDir()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A vendor that echoes what it received.
+		w.Header().Set("X-Echo-Org", r.Header.Get("X-Vendor-Org"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"org":"`+r.Header.Get("X-Vendor-Org")+`","key":"sk-pool-key-two"}`)
+	}))
+	defer upstream.Close()
+
+	rec, recURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{
+		Headers:      map[string]string{"X-Vendor-Org": "org-private-4242"},
+		Secrets:      []string{"sk-pool-key-one", "sk-pool-key-two"},
+		CassetteMode: CassetteRecord,
+		CassetteDir:  dir,
+	})
+	if err != nil {
+		t.Fatalf("start recorder: %v", err)
+	}
+	out := postThroughProxy(t, recURL, nil, `{"model":"m","messages":[{"role":"user","content":"my org is org-private-4242"}]}`)
+	rec.Close()
+	if !strings.Contains(out, "org-private-4242") {
+		t.Fatalf("the client should still get the real response, got %q", out)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("cassette files = %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, secret := range []string{"org-private-4242", "sk-pool-key-two"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("recording leaks %q:\n%s", secret, data)
+		}
+	}
+}
+
+func TestProviderProxy_ReplayFallsBackToRecordedOrder(t *testing.T) {
+	dir := t.TempDir()
+	for i, text := range []string{"first", "second"} {
+		rec := `{"request":{"method":"POST","path":"/v1/messages","body":"{\"prompt\":\"` + text + `\"}"},` +
+			`"response":{"status":200,"header":{"Content-Type":["application/json"]},"body":"{\"text\":\"` + text + `\"}"}}`
+		name := filepath.Join(dir, []string{"0001-a.json", "0002-b.json"}[i])
+		if err := os.WriteFile(name, []byte(rec), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	strict, strictURL, err := NewProviderProxyWithRules("", ProxyRules{CassetteMode: CassetteReplay, CassetteDir: dir})
+	if err != nil {
+		t.Fatalf("start replayer: %v", err)
+	}
+	r, err := http.Post(strictURL+"/v1/messages", "application/json", strings.NewReader(`{"prompt":"volatile context"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+	strict.Close()
+	if r.StatusCode != http.StatusBadGateway {
+		t.Fatalf("mismatched body status = %d, want 502 without loose matching", r.StatusCode)
+	}
+
+	play, playURL, err := NewProviderProxyWithRules("", ProxyRules{CassetteMode: CassetteReplay, CassetteDir: dir, CassetteLooseMatch: true})
+	if err != nil {
+		t.Fatalf("start replayer: %v", err)
+	}
+	defer play.Close()
+
+	for _, want := range []string{"first", "second"} {
+		if out := postThroughProxy(t, playURL, nil, `{"prompt":"volatile context"}`); !strings.Contains(out, want) {
+			t.Fatalf("got %q, want %s", out, want)
+		}
+	}
+}
+
+func TestNewProviderProxy_ReplayNeedsRecordings(t *testing.T) {
+	if _, _, err := NewProviderProxyWithRules("", ProxyRules{CassetteMode: CassetteReplay, CassetteDir: t.TempDir()}); err == nil {
+		t.Fatal("replay from an empty cassette dir succeeded")
+	}
+}
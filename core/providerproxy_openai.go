@@ -0,0 +1,590 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// openAIGateway serves Anthropic Messages requests from an OpenAI-compatible
+// upstream (vLLM, Ollama, internal gateways) by translating them to
+// /v1/chat/completions and translating the responses back, SSE included.
+type openAIGateway struct {
+	chatURL string
+	client  *http.Client
+}
+
+func newOpenAIGateway(target *url.URL) *openAIGateway {
+	base := strings.TrimRight(target.String(), "/")
+	chatURL := base + "/v1/chat/completions"
+	if strings.HasSuffix(base, "/v1") {
+		chatURL = base + "/chat/completions"
+	}
+	return &openAIGateway{chatURL: chatURL, client: &http.Client{}}
+}
+
+func (g *openAIGateway) serveMessages(w http.ResponseWriter, r *http.Request, meta *proxyTraffic) {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, "read request: "+err.Error())
+		return
+	}
+	var req map[string]any
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	stream, _ := req["stream"].(bool)
+	chatReq, err := json.Marshal(anthropicToOpenAIRequest(req))
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	upReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost, g.chatURL, bytes.NewReader(chatReq))
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	upReq.Header = openAIRequestHeader(r.Header)
+	if stream {
+		upReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := g.client.Do(upReq)
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadGateway, "upstream: "+err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		writeAnthropicError(w, resp.StatusCode, openAIErrorMessage(raw, resp.Status))
+		if meta != nil {
+			meta.log(resp.StatusCode, stream, proxyUsage{})
+		}
+		return
+	}
+
+	var usage proxyUsage
+	if stream {
+		usage = translateOpenAIStream(w, resp.Body)
+	} else {
+		usage, err = translateOpenAIResponse(w, resp.Body)
+		if err != nil {
+			writeAnthropicError(w, http.StatusBadGateway, err.Error())
+		}
+	}
+	if meta != nil {
+		meta.log(resp.StatusCode, stream, usage)
+	}
+}
+
+// serveCountTokens answers /v1/messages/count_tokens, which Chat Completions
+// has no equivalent for, with a rough estimate of four bytes per token.
+func (g *openAIGateway) serveCountTokens(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]int{"input_tokens": max(len(body)/4, 1)})
+}
+
+// openAIRequestHeader keeps the client's headers (including configured
+// proxy headers) minus the Anthropic-specific ones, and turns x-api-key into
+// a Bearer token.
+func openAIRequestHeader(in http.Header) http.Header {
+	h := in.Clone()
+	for k := range h {
+		if strings.HasPrefix(strings.ToLower(k), "anthropic-") {
+			h.Del(k)
+		}
+	}
+	if key := h.Get("X-Api-Key"); key != "" && h.Get("Authorization") == "" {
+		h.Set("Authorization", "Bearer "+key)
+	}
+	h.Del("X-Api-Key")
+	h.Del("Content-Length")
+	h.Del("Accept-Encoding")
+	h.Set("Content-Type", "application/json")
+	return h
+}
+
+// anthropicToOpenAIRequest converts a Messages request body to a Chat
+// Completions request body. Fields without an equivalent (thinking,
+// metadata, top_k, cache_control) are dropped.
+func anthropicToOpenAIRequest(req map[string]any) map[string]any {
+	out := map[string]any{"model": req["model"]}
+	for _, k := range []string{"max_tokens", "temperature", "top_p", "stream"} {
+		if v, ok := req[k]; ok {
+			out[k] = v
+		}
+	}
+	if stream, _ := req["stream"].(bool); stream {
+		out["stream_options"] = map[string]any{"include_usage": true}
+	}
+	if stop, ok := req["stop_sequences"].([]any); ok && len(stop) > 0 {
+		out["stop"] = stop
+	}
+
+	var msgs []any
+	if system := anthropicText(req["system"]); system != "" {
+		msgs = append(msgs, map[string]any{"role": "system", "content": system})
+	}
+	raw, _ := req["messages"].([]any)
+	for _, m := range raw {
+		msg, _ := m.(map[string]any)
+		if msg == nil {
+			continue
+		}
+		role, _ := msg["role"].(string)
+		if role == "assistant" {
+			msgs = append(msgs, openAIAssistantMessage(msg["content"]))
+		} else {
+			msgs = append(msgs, openAIUserMessages(msg["content"])...)
+		}
+	}
+	out["messages"] = msgs
+
+	if tools, ok := req["tools"].([]any); ok && len(tools) > 0 {
+		var fns []any
+		for _, t := range tools {
+			tool, _ := t.(map[string]any)
+			if tool == nil || tool["name"] == nil {
+				continue
+			}
+			fn := map[string]any{"name": tool["name"]}
+			if d, ok := tool["description"]; ok {
+				fn["description"] = d
+			}
+			if s, ok := tool["input_schema"]; ok {
+				fn["parameters"] = s
+			} else {
+				fn["parameters"] = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			fns = append(fns, map[string]any{"type": "function", "function": fn})
+		}
+		if len(fns) > 0 {
+			out["tools"] = fns
+		}
+	}
+	if choice, ok := req["tool_choice"].(map[string]any); ok {
+		switch choice["type"] {
+		case "auto":
+			out["tool_choice"] = "auto"
+		case "any":
+			out["tool_choice"] = "required"
+		case "none":
+			out["tool_choice"] = "none"
+		case "tool":
+			out["tool_choice"] = map[string]any{"type": "function", "function": map[string]any{"name": choice["name"]}}
+		}
+	}
+	return out
+}
+
+// openAIUserMessages converts a user turn. tool_result blocks become "tool"
+// messages, which must directly follow the assistant's tool calls, so they
+// come before the remaining user content.
+func openAIUserMessages(content any) []any {
+	if s, ok := content.(string); ok {
+		return []any{map[string]any{"role": "user", "content": s}}
+	}
+	blocks, _ := content.([]any)
+	var out []any
+	var parts []any
+	textOnly := true
+	for _, b := range blocks {
+		block, _ := b.(map[string]any)
+		switch block["type"] {
+		case "tool_result":
+			text := anthropicText(block["content"])
+			if isErr, _ := block["is_error"].(bool); isErr {
+				text = "Error: " + text
+			}
+			out = append(out, map[string]any{"role": "tool", "tool_call_id": block["tool_use_id"], "content": text})
+		case "text":
+			parts = append(parts, map[string]any{"type": "text", "text": block["text"]})
+		case "image":
+			if u := anthropicImageURL(block); u != "" {
+				parts = append(parts, map[string]any{"type": "image_url", "image_url": map[string]any{"url": u}})
+				textOnly = false
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return out
+	}
+	if textOnly {
+		return append(out, map[string]any{"role": "user", "content": anthropicText(blocks)})
+	}
+	return append(out, map[string]any{"role": "user", "content": parts})
+}
+
+func openAIAssistantMessage(content any) map[string]any {
+	msg := map[string]any{"role": "assistant"}
+	if s, ok := content.(string); ok {
+		msg["content"] = s
+		return msg
+	}
+	blocks, _ := content.([]any)
+	var calls []any
+	for _, b := range blocks {
+		block, _ := b.(map[string]any)
+		if block["type"] != "tool_use" {
+			continue
+		}
+		args, _ := json.Marshal(block["input"])
+		if block["input"] == nil {
+			args = []byte("{}")
+		}
+		calls = append(calls, map[string]any{
+			"id":       block["id"],
+			"type":     "function",
+			"function": map[string]any{"name": block["name"], "arguments": string(args)},
+		})
+	}
+	text := anthropicText(blocks)
+	if text != "" || len(calls) == 0 {
+		msg["content"] = text
+	} else {
+		msg["content"] = nil
+	}
+	if len(calls) > 0 {
+		msg["tool_calls"] = calls
+	}
+	return msg
+}
+
+// anthropicText flattens a string or a list of content blocks to its text.
+func anthropicText(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []any:
+		var parts []string
+		for _, b := range t {
+			block, _ := b.(map[string]any)
+			switch block["type"] {
+			case "text":
+				if s, _ := block["text"].(string); s != "" {
+					parts = append(parts, s)
+				}
+			case "image":
+				parts = append(parts, "[image]")
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	return ""
+}
+
+func anthropicImageURL(block map[string]any) string {
+	src, _ := block["source"].(map[string]any)
+	switch src["type"] {
+	case "base64":
+		return fmt.Sprintf("data:%v;base64,%v", src["media_type"], src["data"])
+	case "url":
+		u, _ := src["url"].(string)
+		return u
+	}
+	return ""
+}
+
+// openAIChatResponse is the subset of a Chat Completions response (or
+// stream chunk) the gateway reads.
+type openAIChatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      openAIChatDelta `json:"message"`
+		Delta        openAIChatDelta `json:"delta"`
+		FinishReason string          `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+type openAIChatDelta struct {
+	Content   string `json:"content"`
+	ToolCalls []struct {
+		Index    int    `json:"index"`
+		ID       string `json:"id"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	} `json:"tool_calls"`
+}
+
+func (r *openAIChatResponse) usage() proxyUsage {
+	if r.Usage == nil {
+		return proxyUsage{}
+	}
+	return proxyUsage{InputTokens: r.Usage.PromptTokens, OutputTokens: r.Usage.CompletionTokens}
+}
+
+func anthropicStopReason(finish string) string {
+	switch finish {
+	case "length":
+		return "max_tokens"
+	case "tool_calls", "function_call":
+		return "tool_use"
+	default:
+		return "end_turn"
+	}
+}
+
+func anthropicMessageID(id string) string {
+	if id == "" {
+		return "msg_proxy"
+	}
+	return "msg_" + strings.TrimPrefix(id, "chatcmpl-")
+}
+
+// translateOpenAIResponse writes a non-streaming Chat Completions response
+// as a Messages response.
+func translateOpenAIResponse(w http.ResponseWriter, body io.Reader) (proxyUsage, error) {
+	var resp openAIChatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return proxyUsage{}, fmt.Errorf("decode upstream response: %w", err)
+	}
+	content := []any{}
+	stop := "end_turn"
+	if len(resp.Choices) > 0 {
+		c := resp.Choices[0]
+		if c.Message.Content != "" {
+			content = append(content, map[string]any{"type": "text", "text": c.Message.Content})
+		}
+		for i, call := range c.Message.ToolCalls {
+			content = append(content, map[string]any{
+				"type":  "tool_use",
+				"id":    openAIToolCallID(call.ID, i),
+				"name":  call.Function.Name,
+				"input": parseToolArguments(call.Function.Arguments),
+			})
+		}
+		stop = anthropicStopReason(c.FinishReason)
+		if len(c.Message.ToolCalls) > 0 {
+			stop = "tool_use"
+		}
+	}
+	usage := resp.usage()
+	w.Header().Set("Content-Type", "application/json")
+	return usage, json.NewEncoder(w).Encode(map[string]any{
+		"id":            anthropicMessageID(resp.ID),
+		"type":          "message",
+		"role":          "assistant",
+		"model":         resp.Model,
+		"content":       content,
+		"stop_reason":   stop,
+		"stop_sequence": nil,
+		"usage":         map[string]int{"input_tokens": usage.InputTokens, "output_tokens": usage.OutputTokens},
+	})
+}
+
+func openAIToolCallID(id string, i int) string {
+	if id != "" {
+		return id
+	}
+	return fmt.Sprintf("toolu_proxy_%d", i)
+}
+
+func parseToolArguments(args string) map[string]any {
+	input := map[string]any{}
+	if strings.TrimSpace(args) != "" {
+		if err := json.Unmarshal([]byte(args), &input); err != nil {
+			slog.Warn("providerproxy: tool call arguments are not a JSON object", "error", err)
+		}
+	}
+	return input
+}
+
+// anthropicSSE writes Messages stream events and tracks the open content
+// block. OpenAI streams tool calls one after another, each identified by
+// its index; a new index closes the previous block.
+type anthropicSSE struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+
+	started   bool
+	block     int    // index of the open block, -1 if none
+	blockType string // "text" or "tool_use"
+	toolIndex int    // OpenAI index of the open tool call
+	nextBlock int
+	toolCalls int
+}
+
+func (s *anthropicSSE) event(name string, data map[string]any) {
+	data["type"] = name
+	raw, _ := json.Marshal(data)
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, raw)
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+func (s *anthropicSSE) start(id, model string) {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.event("message_start", map[string]any{"message": map[string]any{
+		"id": anthropicMessageID(id), "type": "message", "role": "assistant", "model": model,
+		"content": []any{}, "stop_reason": nil, "stop_sequence": nil,
+		"usage": map[string]int{"input_tokens": 0, "output_tokens": 0},
+	}})
+}
+
+func (s *anthropicSSE) closeBlock() {
+	if s.block < 0 {
+		return
+	}
+	s.event("content_block_stop", map[string]any{"index": s.block})
+	s.block = -1
+}
+
+func (s *anthropicSSE) openBlock(typ string, block map[string]any) {
+	s.closeBlock()
+	s.block, s.blockType = s.nextBlock, typ
+	s.nextBlock++
+	s.event("content_block_start", map[string]any{"index": s.block, "content_block": block})
+}
+
+func (s *anthropicSSE) text(t string) {
+	if s.block < 0 || s.blockType != "text" {
+		s.openBlock("text", map[string]any{"type": "text", "text": ""})
+	}
+	s.event("content_block_delta", map[string]any{"index": s.block, "delta": map[string]any{"type": "text_delta", "text": t}})
+}
+
+func (s *anthropicSSE) toolCall(index int, id, name, args string) {
+	if s.block < 0 || s.blockType != "tool_use" || s.toolIndex != index {
+		s.toolIndex = index
+		s.openBlock("tool_use", map[string]any{
+			"type": "tool_use", "id": openAIToolCallID(id, s.toolCalls), "name": name, "input": map[string]any{},
+		})
+		s.toolCalls++
+	}
+	if args != "" {
+		s.event("content_block_delta", map[string]any{"index": s.block, "delta": map[string]any{"type": "input_json_delta", "partial_json": args}})
+	}
+}
+
+// translateOpenAIStream relays a Chat Completions SSE stream as a Messages
+// SSE stream and returns the usage reported at its end.
+func translateOpenAIStream(w http.ResponseWriter, body io.Reader) proxyUsage {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	s := &anthropicSSE{w: w, flusher: flusher, block: -1}
+
+	var usage proxyUsage
+	stop := ""
+	model := ""
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 8<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if chunk.Model != "" {
+			model = chunk.Model
+		}
+		s.start(chunk.ID, model)
+		usage.add(chunk.usage())
+		for _, c := range chunk.Choices {
+			if c.Delta.Content != "" {
+				s.text(c.Delta.Content)
+			}
+			for _, call := range c.Delta.ToolCalls {
+				s.toolCall(call.Index, call.ID, call.Function.Name, call.Function.Arguments)
+			}
+			if c.FinishReason != "" {
+				stop = anthropicStopReason(c.FinishReason)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		s.start("", model)
+		s.closeBlock()
+		s.event("error", map[string]any{"error": map[string]any{"type": "api_error", "message": "upstream stream: " + err.Error()}})
+		return usage
+	}
+
+	s.start("", model)
+	s.closeBlock()
+	if s.toolCalls > 0 {
+		stop = "tool_use"
+	} else if stop == "" {
+		stop = "end_turn"
+	}
+	s.event("message_delta", map[string]any{
+		"delta": map[string]any{"stop_reason": stop, "stop_sequence": nil},
+		"usage": map[string]int{"input_tokens": usage.InputTokens, "output_tokens": usage.OutputTokens},
+	})
+	s.event("message_stop", map[string]any{})
+	return usage
+}
+
+// writeAnthropicError writes an error in the Messages API format so the
+// client's retry and rate-limit handling keeps working.
+func writeAnthropicError(w http.ResponseWriter, status int, msg string) {
+	typ := "api_error"
+	switch status {
+	case http.StatusBadRequest:
+		typ = "invalid_request_error"
+	case http.StatusUnauthorized:
+		typ = "authentication_error"
+	case http.StatusForbidden:
+		typ = "permission_error"
+	case http.StatusNotFound:
+		typ = "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		typ = "request_too_large"
+	case http.StatusTooManyRequests:
+		typ = "rate_limit_error"
+	case http.StatusServiceUnavailable, 529:
+		typ = "overloaded_error"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"type":  "error",
+		"error": map[string]any{"type": typ, "message": msg},
+	})
+}
+
+func openAIErrorMessage(raw []byte, status string) string {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(raw, &body) == nil && body.Error.Message != "" {
+		return body.Error.Message
+	}
+	if s := strings.TrimSpace(string(raw)); s != "" {
+		return s
+	}
+	return status
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const openAIToolTurnRequest = `{
+	"model": "claude-sonnet-4-5",
+	"max_tokens": 1024,
+	"system": [{"type": "text", "text": "You are helpful.", "cache_control": {"type": "ephemeral"}}],
+	"thinking": {"type": "enabled", "budget_tokens": 512},
+	"tools": [{"name": "Read", "description": "Read a file", "input_schema": {"type": "object", "properties": {"path": {"type": "string"}}}}],
+	"tool_choice": {"type": "auto"},
+	"messages": [
+		{"role": "user", "content": "show main.go"},
+		{"role": "assistant", "content": [
+			{"type": "thinking", "thinking": "need the file", "signature": "sig"},
+			{"type": "text", "text": "Reading it."},
+			{"type": "tool_use", "id": "call_1", "name": "Read", "input": {"path": "main.go"}}
+		]},
+		{"role": "user", "content": [
+			{"type": "tool_result", "tool_use_id": "call_1", "content": [{"type": "text", "text": "package main"}]},
+			{"type": "text", "text": "now explain"}
+		]}
+	]%s
+}`
+
+func TestAnthropicToOpenAIRequest(t *testing.T) {
+	var req map[string]any
+	if err := json.Unmarshal([]byte(strings.Replace(openAIToolTurnRequest, "%s", `, "stream": true`, 1)), &req); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := json.Marshal(anthropicToOpenAIRequest(req))
+	var got struct {
+		Model         string         `json:"model"`
+		MaxTokens     int            `json:"max_tokens"`
+		Stream        bool           `json:"stream"`
+		StreamOptions map[string]any `json:"stream_options"`
+		ToolChoice    any            `json:"tool_choice"`
+		Thinking      any            `json:"thinking"`
+		Tools         []struct {
+			Type     string `json:"type"`
+			Function struct {
+				Name       string         `json:"name"`
+				Parameters map[string]any `json:"parameters"`
+			} `json:"function"`
+		} `json:"tools"`
+		Messages []struct {
+			Role       string `json:"role"`
+			Content    any    `json:"content"`
+			ToolCallID string `json:"tool_call_id"`
+			ToolCalls  []struct {
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "claude-sonnet-4-5" || got.MaxTokens != 1024 || !got.Stream || got.StreamOptions["include_usage"] != true {
+		t.Fatalf("request = %s", raw)
+	}
+	if got.Thinking != nil || got.ToolChoice != "auto" {
+		t.Fatalf("thinking/tool_choice = %v/%v", got.Thinking, got.ToolChoice)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Type != "function" || got.Tools[0].Function.Name != "Read" || got.Tools[0].Function.Parameters["type"] != "object" {
+		t.Fatalf("tools = %+v", got.Tools)
+	}
+	roles := make([]string, len(got.Messages))
+	for i, m := range got.Messages {
+		roles[i] = m.Role
+	}
+	if strings.Join(roles, ",") != "system,user,assistant,tool,user" {
+		t.Fatalf("roles = %v", roles)
+	}
+	if got.Messages[0].Content != "You are helpful." {
+		t.Errorf("system = %v", got.Messages[0].Content)
+	}
+	asst := got.Messages[2]
+	if asst.Content != "Reading it." || len(asst.ToolCalls) != 1 || asst.ToolCalls[0].ID != "call_1" ||
+		asst.ToolCalls[0].Function.Arguments != `{"path":"main.go"}` {
+		t.Errorf("assistant = %+v", asst)
+	}
+	if tool := got.Messages[3]; tool.ToolCallID != "call_1" || tool.Content != "package main" {
+		t.Errorf("tool = %+v", tool)
+	}
+	if got.Messages[4].Content != "now explain" {
+		t.Errorf("user = %+v", got.Messages[4])
+	}
+}
+
+func TestProviderProxy_OpenAITranslatesResponse(t *testing.T) {
+	upstream, last := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"id": "chatcmpl-42", "model": "qwen3-coder",
+			"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {
+				"role": "assistant", "content": "Let me look.",
+				"tool_calls": [{"id": "call_9", "type": "function", "function": {"name": "Read", "arguments": "{\"path\":\"go.mod\"}"}}]
+			}}],
+			"usage": {"prompt_tokens": 321, "completion_tokens": 17}
+		}`)
+	})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL+"/v1", ProxyRules{
+		Protocol: ProxyProtocolOpenAI,
+		ModelMap: map[string]string{"claude-sonnet-*": "qwen3-coder"},
+	})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	h := http.Header{}
+	h.Set("x-api-key", "sk-local")
+	h.Set("anthropic-version", "2023-06-01")
+	out := postThroughProxy(t, localURL, h, strings.Replace(openAIToolTurnRequest, "%s", "", 1))
+
+	req := last()
+	if req.path != "/v1/chat/completions" || req.body["model"] != "qwen3-coder" {
+		t.Fatalf("upstream got path=%q model=%v", req.path, req.body["model"])
+	}
+	if req.header.Get("Authorization") != "Bearer sk-local" || req.header.Get("x-api-key") != "" || req.header.Get("anthropic-version") != "" {
+		t.Fatalf("upstream headers = %v", req.header)
+	}
+
+	var resp struct {
+		Type       string `json:"type"`
+		StopReason string `json:"stop_reason"`
+		Content    []struct {
+			Type  string         `json:"type"`
+			Text  string         `json:"text"`
+			ID    string         `json:"id"`
+			Name  string         `json:"name"`
+			Input map[string]any `json:"input"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("response %q: %v", out, err)
+	}
+	if resp.Type != "message" || resp.StopReason != "tool_use" || len(resp.Content) != 2 {
+		t.Fatalf("response = %s", out)
+	}
+	if resp.Content[0].Text != "Let me look." || resp.Content[1].ID != "call_9" || resp.Content[1].Input["path"] != "go.mod" {
+		t.Fatalf("content = %+v", resp.Content)
+	}
+	if resp.Usage.InputTokens != 321 || resp.Usage.OutputTokens != 17 {
+		t.Fatalf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestProviderProxy_OpenAITranslatesStream(t *testing.T) {
+	chunks := []string{
+		`{"id":"chatcmpl-7","model":"qwen3-coder","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"}}]}`,
+		`{"id":"chatcmpl-7","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		`{"id":"chatcmpl-7","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"Bash","arguments":""}}]}}]}`,
+		`{"id":"chatcmpl-7","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"command\":"}}]}}]}`,
+		`{"id":"chatcmpl-7","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"ls\"}"}}]}}]}`,
+		`{"id":"chatcmpl-7","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		`{"id":"chatcmpl-7","choices":[],"usage":{"prompt_tokens":50,"completion_tokens":9}}`,
+	}
+	upstream, last := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, c := range chunks {
+			_, _ = io.WriteString(w, "data: "+c+"\n\n")
+			w.(http.Flusher).Flush()
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{Protocol: ProxyProtocolOpenAI})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	out := postThroughProxy(t, localURL, nil, `{"model":"m","max_tokens":64,"stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	if req := last(); req.path != "/v1/chat/completions" || req.body["stream"] != true {
+		t.Fatalf("upstream got %+v", req)
+	}
+
+	var events []string
+	var text, args, stop string
+	var outputTokens float64
+	for _, line := range strings.Split(out, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var ev map[string]any
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatalf("bad event %q: %v", data, err)
+		}
+		typ := ev["type"].(string)
+		events = append(events, typ)
+		switch typ {
+		case "content_block_start":
+			if b := ev["content_block"].(map[string]any); b["type"] == "tool_use" && (b["id"] != "call_a" || b["name"] != "Bash") {
+				t.Errorf("tool block = %v", b)
+			}
+		case "content_block_delta":
+			d := ev["delta"].(map[string]any)
+			if d["type"] == "text_delta" {
+				text += d["text"].(string)
+			} else {
+				args += d["partial_json"].(string)
+			}
+		case "message_delta":
+			stop = ev["delta"].(map[string]any)["stop_reason"].(string)
+			outputTokens = ev["usage"].(map[string]any)["output_tokens"].(float64)
+		}
+	}
+	want := "message_start,content_block_start,content_block_delta,content_block_delta,content_block_stop," +
+		"content_block_start,content_block_delta,content_block_delta,content_block_stop,message_delta,message_stop"
+	if strings.Join(events, ",") != want {
+		t.Fatalf("events = %v", events)
+	}
+	if text != "Hello" || args != `{"command":"ls"}` || stop != "tool_use" || outputTokens != 9 {
+		t.Fatalf("text=%q args=%q stop=%q output=%v", text, args, stop, outputTokens)
+	}
+}
+
+func TestProviderProxy_OpenAIErrorsUseAnthropicShape(t *testing.T) {
+	upstream, _ := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":{"message":"slow down","type":"rate_limit"}}`)
+	})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{Protocol: ProxyProtocolOpenAI})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	resp, err := http.Post(localURL+"/v1/messages", "application/json", strings.NewReader(`{"model":"m","messages":[]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Type  string `json:"type"`
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusTooManyRequests || body.Type != "error" || body.Error.Type != "rate_limit_error" || body.Error.Message != "slow down" {
+		t.Fatalf("status %d body %+v", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,262 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type capturedRequest struct {
+	path   string
+	header http.Header
+	body   map[string]any
+}
+
+func newCaptureUpstream(t *testing.T, respond func(w http.ResponseWriter)) (*httptest.Server, func() capturedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var last capturedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(raw, &body)
+		mu.Lock()
+		last = capturedRequest{path: r.URL.Path, header: r.Header.Clone(), body: body}
+		mu.Unlock()
+		respond(w)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() capturedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func postThroughProxy(t *testing.T, localURL string, header http.Header, body string) string {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, localURL+"/v1/messages", strings.NewReader(body))
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	defer resp.Body.Close()
+	out, _ := io.ReadAll(resp.Body)
+	return string(out)
+}
+
+func TestProviderProxy_AppliesRules(t *testing.T) {
+	upstream, last := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"type":"message"}`)
+	})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{
+		Thinking:    "enabled",
+		ModelMap:    map[string]string{"claude-sonnet-*": "vendor/sonnet", "claude-opus-4": "vendor/opus"},
+		StripFields: []string{"cache_control"},
+		StripBetas:  []string{"context-1m-2025-08-07"},
+		Headers:     map[string]string{"X-Vendor-Org": "acme"},
+		MaxTokens:   8000,
+	})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	h := http.Header{}
+	h.Set("anthropic-beta", "context-1m-2025-08-07, fine-grained-tool-streaming-2025-05-14")
+	postThroughProxy(t, localURL, h, `{
+		"model": "claude-sonnet-4-5",
+		"max_tokens": 32000,
+		"thinking": {"type": "adaptive", "budget_tokens": 16000},
+		"system": [{"type": "text", "text": "sys", "cache_control": {"type": "ephemeral"}}],
+		"tools": [{"name": "edit", "input_schema": {"type": "object"}, "cache_control": {"type": "ephemeral"}}],
+		"messages": [
+			{"role": "user", "content": [{"type": "text", "text": "hi", "cache_control": {"type": "ephemeral"}}]},
+			{"role": "assistant", "content": [{"type": "tool_use", "id": "t1", "name": "edit", "input": {"cache_control": "user data"}}]}
+		]
+	}`)
+
+	got := last()
+	if got.path != "/v1/messages" {
+		t.Fatalf("path = %q", got.path)
+	}
+	if got.body["model"] != "vendor/sonnet" {
+		t.Errorf("model = %v, want vendor/sonnet", got.body["model"])
+	}
+	if got.body["max_tokens"] != float64(8000) {
+		t.Errorf("max_tokens = %v, want 8000", got.body["max_tokens"])
+	}
+	thinking := got.body["thinking"].(map[string]any)
+	if thinking["type"] != "enabled" || thinking["budget_tokens"] != float64(7999) {
+		t.Errorf("thinking = %v, want enabled with budget below the cap", thinking)
+	}
+	raw, _ := json.Marshal(got.body)
+	if n := bytes.Count(raw, []byte("cache_control")); n != 1 {
+		t.Errorf("cache_control kept %d times, want only the tool input: %s", n, raw)
+	}
+	if !bytes.Contains(raw, []byte(`"input":{"cache_control":"user data"}`)) {
+		t.Errorf("tool input was rewritten: %s", raw)
+	}
+	if b := got.header.Get("anthropic-beta"); b != "fine-grained-tool-streaming-2025-05-14" {
+		t.Errorf("anthropic-beta = %q", b)
+	}
+	if v := got.header.Get("X-Vendor-Org"); v != "acme" {
+		t.Errorf("X-Vendor-Org = %q", v)
+	}
+}
+
+func TestProviderProxy_LeavesOtherRequestsAlone(t *testing.T) {
+	upstream, last := newCaptureUpstream(t, func(w http.ResponseWriter) {})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{
+		ModelMap:   map[string]string{"claude-opus-4": "vendor/opus"},
+		StripBetas: []string{"only-beta"},
+	})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	h := http.Header{}
+	h.Set("anthropic-beta", "only-beta")
+	postThroughProxy(t, localURL, h, `{"model":"claude-haiku-4-5","max_tokens":100}`)
+	got := last()
+	if got.body["model"] != "claude-haiku-4-5" || got.body["max_tokens"] != float64(100) {
+		t.Errorf("unmapped request changed: %v", got.body)
+	}
+	if _, ok := got.header["Anthropic-Beta"]; ok {
+		t.Errorf("empty anthropic-beta header kept: %v", got.header)
+	}
+}
+
+func TestProviderProxy_RewritesCountTokens(t *testing.T) {
+	upstream, last := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"input_tokens":12}`)
+	})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{
+		ModelMap:   map[string]string{"claude-sonnet-*": "vendor/sonnet"},
+		StripBetas: []string{"context-1m-2025-08-07"},
+		Headers:    map[string]string{"X-Vendor-Org": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, localURL+"/v1/messages/count_tokens",
+		strings.NewReader(`{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("anthropic-beta", "context-1m-2025-08-07, token-counting-2024-11-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+
+	got := last()
+	if got.path != "/v1/messages/count_tokens" {
+		t.Fatalf("path = %q", got.path)
+	}
+	if got.body["model"] != "vendor/sonnet" {
+		t.Errorf("model = %v, want vendor/sonnet", got.body["model"])
+	}
+	if b := got.header.Get("anthropic-beta"); b != "token-counting-2024-11-01" {
+		t.Errorf("anthropic-beta = %q", b)
+	}
+	if v := got.header.Get("X-Vendor-Org"); v != "acme" {
+		t.Errorf("X-Vendor-Org = %q", v)
+	}
+}
+
+func TestMapProxyModel(t *testing.T) {
+	m := map[string]string{"claude-*": "generic", "claude-sonnet-*": "sonnet", "claude-sonnet-4-5": "exact"}
+	for model, want := range map[string]string{
+		"claude-sonnet-4-5": "exact",
+		"claude-sonnet-4":   "sonnet",
+		"claude-haiku-4-5":  "generic",
+		"gpt-5":             "",
+	} {
+		if got := mapProxyModel(m, model); got != want {
+			t.Errorf("mapProxyModel(%q) = %q, want %q", model, got, want)
+		}
+	}
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProviderProxy_LogsStreamUsage(t *testing.T) {
+	var logs syncBuffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	defer slog.SetDefault(prev)
+
+	stream := "event: message_start\n" +
+		`data: {"type":"message_start","message":{"model":"vendor/sonnet","usage":{"input_tokens":120,"cache_read_input_tokens":40,"output_tokens":1}}}` + "\n\n" +
+		"event: content_block_delta\n" +
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"hi"}}` + "\n\n" +
+		"event: message_delta\n" +
+		`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":57}}` + "\n\n" +
+		"event: message_stop\n" + `data: {"type":"message_stop"}` + "\n\n"
+	upstream, _ := newCaptureUpstream(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Split mid-line to exercise partial SSE lines.
+		half := len(stream) / 2
+		_, _ = io.WriteString(w, stream[:half])
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, stream[half:])
+	})
+	pp, localURL, err := NewProviderProxyWithRules(upstream.URL, ProxyRules{
+		ModelMap:   map[string]string{"claude-sonnet-4-5": "vendor/sonnet"},
+		LogTraffic: true,
+	})
+	if err != nil {
+		t.Fatalf("start proxy: %v", err)
+	}
+	defer pp.Close()
+
+	if out := postThroughProxy(t, localURL, nil, `{"model":"claude-sonnet-4-5","stream":true}`); out != stream {
+		t.Fatalf("stream altered:\n%s", out)
+	}
+	// The proxy logs on upstream EOF, before the client sees the end of the
+	// chunked response.
+	line := ""
+	for _, l := range strings.Split(logs.String(), "\n") {
+		if strings.Contains(l, "providerproxy: request") {
+			line = l
+		}
+	}
+	for _, want := range []string{
+		"model=claude-sonnet-4-5", "upstream_model=vendor/sonnet", "status=200", "stream=true",
+		"input_tokens=120", "output_tokens=57", "cache_read_tokens=40",
+	} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log line %q missing %q", line, want)
+		}
+	}
+}
@@ -57,6 +57,12 @@ func (rm *RelayManager) RegisterEngine(name string, e *Engine) {
 	rm.engines[name] = e
 }
 
+func (rm *RelayManager) UnregisterEngine(name string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	delete(rm.engines, name)
+}
+
 // SetTimeout overrides the relay response timeout. Set to 0 to disable it.
 func (rm *RelayManager) SetTimeout(d time.Duration) {
 	rm.mu.Lock()
@@ -270,7 +276,7 @@ func (rm *RelayManager) Send(ctx context.Context, req RelayRequest) (*RelayRespo
 
 // sendToGroup sends a message to the group chat for visibility.
 func (rm *RelayManager) sendToGroup(ctx context.Context, e *Engine, platform, sessionKey, content string) {
-	for _, p := range e.platforms {
+	for _, p := range e.platformList() {
 		if p.Name() != platform {
 			continue
 		}
@@ -365,7 +371,7 @@ func (rm *RelayManager) resolveGroupVisibilityKey(platform, chatID, callerSessio
 	if sourceEngine == nil {
 		return defaultKey
 	}
-	for _, p := range sourceEngine.platforms {
+	for _, p := range sourceEngine.platformList() {
 		if p.Name() != platform {
 			continue
 		}
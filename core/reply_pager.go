@@ -0,0 +1,310 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Paged replies: with display.paged_replies, a final reply that would be
+// split into several messages is sent as its first page with "◀ 1/N ▶"
+// navigation instead, on platforms that can render cards or inline buttons.
+// The remaining pages stay in the engine until they expire.
+
+const (
+	maxPagedReplies = 64
+	pagedReplyTTL   = 24 * time.Hour
+	// pagedReplyIDPrefix marks reply IDs in /page arguments, so an ID is
+	// never mistaken for a page number.
+	pagedReplyIDPrefix = "p-"
+)
+
+// pagedReply is one long reply split into pages.
+type pagedReply struct {
+	id           string
+	sessionKey   string
+	platform     Platform
+	replyCtx     any
+	workspaceDir string
+	full         string
+	pages        []string
+	footer       string
+	created      time.Time
+}
+
+// replyPager keeps the most recent paged replies, oldest evicted first.
+type replyPager struct {
+	mu      sync.Mutex
+	replies map[string]*pagedReply
+	order   []string
+	latest  map[string]string // sessionKey -> id of the newest reply
+}
+
+func (rp *replyPager) add(r *pagedReply) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.replies == nil {
+		rp.replies = make(map[string]*pagedReply)
+		rp.latest = make(map[string]string)
+	}
+	rp.replies[r.id] = r
+	rp.order = append(rp.order, r.id)
+	rp.latest[r.sessionKey] = r.id
+	for len(rp.order) > maxPagedReplies {
+		rp.evictLocked(rp.order[0])
+	}
+}
+
+func (rp *replyPager) evictLocked(id string) {
+	if r := rp.replies[id]; r != nil && rp.latest[r.sessionKey] == id {
+		delete(rp.latest, r.sessionKey)
+	}
+	delete(rp.replies, id)
+	for i, v := range rp.order {
+		if v == id {
+			rp.order = append(rp.order[:i], rp.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// get returns the session's reply with the given id, or its newest reply
+// when id is empty. Replies of other sessions are not returned and expired
+// replies are dropped.
+func (rp *replyPager) get(sessionKey, id string) *pagedReply {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if id == "" {
+		id = rp.latest[sessionKey]
+	}
+	r := rp.replies[id]
+	if r == nil || r.sessionKey != sessionKey {
+		return nil
+	}
+	if time.Since(r.created) > pagedReplyTTL {
+		rp.evictLocked(id)
+		return nil
+	}
+	return r
+}
+
+func newShortID() string {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// pagedRepliesSupported reports whether p can show page navigation.
+func pagedRepliesSupported(p Platform) bool {
+	if _, ok := p.(CardSender); ok {
+		return true
+	}
+	_, ok := p.(InlineButtonSender)
+	return ok
+}
+
+// sendReplyChunks sends a final reply: as pages when paged replies are
+// enabled and the body needs more than one message, otherwise as
+// consecutive chunks. It returns false if sending failed.
+func (e *Engine) sendReplyChunks(p Platform, replyCtx any, sessionKey, workspaceDir, body, statusFooter string, sendFn func(Platform, any, string) error) bool {
+	if e.display.PagedReplies && pagedRepliesSupported(p) {
+		if pages := SplitMessageCodeFenceAware(body, maxPlatformMessageLen); len(pages) > 1 {
+			r := &pagedReply{
+				id:           pagedReplyIDPrefix + newShortID(),
+				sessionKey:   sessionKey,
+				platform:     p,
+				replyCtx:     replyCtx,
+				workspaceDir: workspaceDir,
+				full:         body,
+				pages:        pages,
+				footer:       statusFooter,
+				created:      time.Now(),
+			}
+			e.replyPages.add(r)
+			if err := e.sendReplyPage(p, replyCtx, r, 1); err == nil {
+				return true
+			} else {
+				slog.Warn("paged reply: send failed, falling back to chunks", "platform", p.Name(), "error", err)
+			}
+		}
+	}
+	return sendChunksWithStatusFooter(e.ctx, p, replyCtx, body, statusFooter, sendFn)
+}
+
+// sendReplyPage sends one page as a new message: a card on CardSender
+// platforms, text with inline buttons otherwise.
+func (e *Engine) sendReplyPage(p Platform, replyCtx any, r *pagedReply, page int) error {
+	if err := e.waitOutgoing(p); err != nil {
+		return err
+	}
+	if cs, ok := p.(CardSender); ok {
+		card := e.renderCardForPlatformWorkspace(p, e.renderReplyPageCard(r, page, ""), r.workspaceDir)
+		return cs.SendCard(e.ctx, replyCtx, card)
+	}
+	bs, ok := p.(InlineButtonSender)
+	if !ok {
+		return ErrNotSupported
+	}
+	page = clampPage(page, len(r.pages))
+	return bs.SendWithButtons(e.ctx, replyCtx, e.replyPageText(p, r, page), e.replyPageButtons(r, page))
+}
+
+// updateReplyPage shows another page in the button message replyCtx refers
+// to, on platforms that can edit it.
+func (e *Engine) updateReplyPage(p Platform, replyCtx any, r *pagedReply, page int) error {
+	bu, ok := p.(InlineButtonUpdater)
+	if !ok {
+		return ErrNotSupported
+	}
+	page = clampPage(page, len(r.pages))
+	return bu.UpdateWithButtons(e.ctx, replyCtx, e.replyPageText(p, r, page), e.replyPageButtons(r, page))
+}
+
+func (e *Engine) replyPageText(p Platform, r *pagedReply, page int) string {
+	return e.renderOutgoingContentForWorkspace(p, appendReplyFooter(r.pages[page-1], r.footer), r.workspaceDir)
+}
+
+func clampPage(page, total int) int {
+	return max(1, min(page, total))
+}
+
+// replyPageButtons builds "◀ n/N ▶" (and "as file") buttons that re-enter
+// the engine as /page commands.
+func (e *Engine) replyPageButtons(r *pagedReply, page int) [][]ButtonOption {
+	total := len(r.pages)
+	cmd := func(arg string) string { return fmt.Sprintf("cmd:/page %s %s", r.id, arg) }
+	rows := [][]ButtonOption{{
+		{Text: "◀", Data: cmd(strconv.Itoa(clampPage(page-1, total)))},
+		{Text: fmt.Sprintf("%d/%d", page, total), Data: cmd(strconv.Itoa(page))},
+		{Text: "▶", Data: cmd(strconv.Itoa(clampPage(page+1, total)))},
+	}}
+	if _, ok := r.platform.(FileSender); ok {
+		rows = append(rows, []ButtonOption{{Text: e.i18n.T(MsgReplyPageAsFile), Data: cmd("file")}})
+	}
+	return rows
+}
+
+// renderReplyPageCard renders one page with navigation buttons; note, if
+// set, replaces the status footer (e.g. to report the "as file" result).
+func (e *Engine) renderReplyPageCard(r *pagedReply, page int, note string) *Card {
+	total := len(r.pages)
+	page = clampPage(page, total)
+	nav := func(p int) string { return fmt.Sprintf("nav:/page %s %d", r.id, p) }
+	cb := NewCard().Markdown(r.pages[page-1])
+	if note == "" {
+		note = r.footer
+	}
+	if note != "" {
+		cb.Note(note)
+	}
+	cb.ButtonsEqual(
+		DefaultBtn("◀", nav(clampPage(page-1, total))),
+		DefaultBtn(fmt.Sprintf("%d/%d", page, total), nav(page)),
+		DefaultBtn("▶", nav(clampPage(page+1, total))),
+	)
+	if _, ok := r.platform.(FileSender); ok {
+		cb.Buttons(DefaultBtn(e.i18n.T(MsgReplyPageAsFile), fmt.Sprintf("act:/page %s file %d", r.id, page)))
+	}
+	return cb.Build()
+}
+
+// parseReplyPageArgs splits "[id] [file] [page]" into its parts. IDs carry
+// pagedReplyIDPrefix; anything else that is not "file" is the page number.
+func parseReplyPageArgs(args []string) (id string, file bool, page int) {
+	page = 1
+	for _, a := range args {
+		switch {
+		case strings.HasPrefix(a, pagedReplyIDPrefix):
+			id = a
+		case strings.EqualFold(a, "file"):
+			file = true
+		default:
+			if n, err := strconv.Atoi(a); err == nil {
+				page = n
+			}
+		}
+	}
+	return id, file, page
+}
+
+// renderReplyPageNav handles nav:/page and act:/page card actions. The
+// "as file" action sends the file in the background and reports the result
+// through CardRefresher.
+func (e *Engine) renderReplyPageNav(args, sessionKey string, act bool) *Card {
+	id, file, page := parseReplyPageArgs(strings.Fields(args))
+	r := e.replyPages.get(e.interactiveKeyForSessionKey(sessionKey), id)
+	if r == nil {
+		return e.simpleCard("", "grey", e.i18n.T(MsgReplyPageExpired))
+	}
+	if act && file {
+		go func() {
+			note := e.i18n.T(MsgReplyPageFileSent)
+			if err := e.sendPagedReplyFile(r); err != nil {
+				slog.Warn("paged reply: send as file failed", "platform", r.platform.Name(), "error", err)
+				note = e.i18n.Tf(MsgReplyPageFileFailed, err)
+			}
+			if refresher, ok := r.platform.(CardRefresher); ok {
+				card := e.renderCardForPlatformWorkspace(r.platform, e.renderReplyPageCard(r, page, note), r.workspaceDir)
+				if err := refresher.RefreshCard(e.ctx, sessionKey, card); err != nil {
+					slog.Debug("paged reply: refresh card failed", "error", err)
+				}
+			}
+		}()
+	}
+	return e.renderCardForPlatformWorkspace(r.platform, e.renderReplyPageCard(r, page, ""), r.workspaceDir)
+}
+
+// sendPagedReplyFile sends the full reply as a Markdown file.
+func (e *Engine) sendPagedReplyFile(r *pagedReply) error {
+	fs, ok := r.platform.(FileSender)
+	if !ok {
+		return ErrNotSupported
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, 2*time.Minute)
+	defer cancel()
+	return fs.SendFile(ctx, r.replyCtx, FileAttachment{
+		MimeType: "text/markdown",
+		Data:     []byte(r.full),
+		FileName: fmt.Sprintf("reply-%s.md", r.id),
+	})
+}
+
+// cmdPage implements /page [id] [n|file]: show a page of the newest (or
+// the given) paged reply, or send it as a file. Inline page buttons use it
+// and edit their own message where the platform supports it.
+func (e *Engine) cmdPage(p Platform, msg *Message, args []string) {
+	id, file, page := parseReplyPageArgs(args)
+	r := e.replyPages.get(e.interactiveKeyForSessionKey(msg.SessionKey), id)
+	if r == nil {
+		e.reply(p, msg.ReplyCtx, e.i18n.T(MsgReplyPageExpired))
+		return
+	}
+	if file {
+		if err := e.sendPagedReplyFile(r); err != nil {
+			e.reply(p, msg.ReplyCtx, e.i18n.Tf(MsgReplyPageFileFailed, err))
+		}
+		return
+	}
+	if msg.FromButton {
+		err := e.updateReplyPage(p, msg.ReplyCtx, r, page)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, ErrNotSupported) {
+			slog.Debug("paged reply: update in place failed, sending the page", "platform", p.Name(), "error", err)
+		}
+	}
+	if err := e.sendReplyPage(p, msg.ReplyCtx, r, page); err != nil {
+		slog.Warn("paged reply: send page failed", "platform", p.Name(), "error", err)
+		e.reply(p, msg.ReplyCtx, r.pages[clampPage(page, len(r.pages))-1])
+	}
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubCardFilePlatform struct {
+	stubCardPlatform
+	files chan FileAttachment
+}
+
+func (p *stubCardFilePlatform) SendFile(_ context.Context, _ any, file FileAttachment) error {
+	p.files <- file
+	return nil
+}
+
+func longReply(paragraphs int) string {
+	var sb strings.Builder
+	for i := 0; i < paragraphs; i++ {
+		sb.WriteString(strings.Repeat("word ", 300))
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+func TestSendReplyChunks_PagesOnCardPlatform(t *testing.T) {
+	p := &stubCardFilePlatform{stubCardPlatform: stubCardPlatform{stubPlatformEngine: stubPlatformEngine{n: "feishu"}}, files: make(chan FileAttachment, 1)}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetDisplayConfig(DisplayCfg{PagedReplies: true})
+
+	body := longReply(10)
+	send := func(p Platform, replyCtx any, content string) error { return p.Send(e.ctx, replyCtx, content) }
+	if !e.sendReplyChunks(p, "ctx", "s1", "", body, "model · 1k tokens", send) {
+		t.Fatal("sendReplyChunks failed")
+	}
+	if len(p.getSent()) != 0 || len(p.sentCards) != 1 {
+		t.Fatalf("sent %d messages and %d cards, want one card", len(p.getSent()), len(p.sentCards))
+	}
+	pages := len(SplitMessageCodeFenceAware(body, maxPlatformMessageLen))
+	rows := p.sentCards[0].CollectButtons()
+	if len(rows) != 2 || rows[0][1].Text != "1/"+strconv.Itoa(pages) || rows[1][0].Text != e.i18n.T(MsgReplyPageAsFile) {
+		t.Fatalf("buttons = %+v", rows)
+	}
+	if !strings.Contains(p.sentCards[0].RenderText(), "model · 1k tokens") {
+		t.Error("footer missing from first page")
+	}
+
+	next := e.handleCardNav(rows[0][2].Data, "s1")
+	if next == nil || next.CollectButtons()[0][1].Text != "2/"+strconv.Itoa(pages) {
+		t.Fatalf("▶ rendered %+v", next)
+	}
+
+	e.handleCardNav(rows[1][0].Data, "s1")
+	select {
+	case f := <-p.files:
+		if string(f.Data) != body || f.MimeType != "text/markdown" {
+			t.Fatalf("file = %s (%d bytes)", f.FileName, len(f.Data))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("as-file action sent nothing")
+	}
+}
+
+func TestSendReplyChunks_InlineButtonsUsePageCommand(t *testing.T) {
+	p := &stubInlineButtonPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetDisplayConfig(DisplayCfg{PagedReplies: true})
+
+	send := func(p Platform, replyCtx any, content string) error { return p.Send(e.ctx, replyCtx, content) }
+	e.sendReplyChunks(p, "ctx", "s1", "", longReply(6), "", send)
+	if len(p.getSent()) != 0 || len(p.buttonRows) != 1 {
+		t.Fatalf("sent=%d buttons=%+v", len(p.getSent()), p.buttonRows)
+	}
+	next := p.buttonRows[0][2].Data
+	if !strings.HasPrefix(next, "cmd:/page ") || !strings.HasSuffix(next, " 2") {
+		t.Fatalf("▶ data = %q", next)
+	}
+
+	e.cmdPage(p, &Message{SessionKey: "s1", ReplyCtx: "ctx"}, []string{"2"})
+	if p.buttonRows[0][1].Text != "2/"+strconv.Itoa(len(SplitMessageCodeFenceAware(longReply(6), maxPlatformMessageLen))) {
+		t.Fatalf("/page 2 buttons = %+v", p.buttonRows)
+	}
+
+	e.cmdPage(p, &Message{SessionKey: "other", ReplyCtx: "ctx"}, nil)
+	if sent := p.getSent(); len(sent) != 1 || sent[0] != e.i18n.T(MsgReplyPageExpired) {
+		t.Fatalf("unknown session reply = %v", sent)
+	}
+}
+
+type stubButtonUpdaterPlatform struct {
+	stubInlineButtonPlatform
+	updatedCtx  any
+	updatedRows [][]ButtonOption
+}
+
+func (p *stubButtonUpdaterPlatform) UpdateWithButtons(_ context.Context, replyCtx any, _ string, buttons [][]ButtonOption) error {
+	p.updatedCtx = replyCtx
+	p.updatedRows = buttons
+	return nil
+}
+
+func TestCmdPage_ButtonEditsMessageInPlace(t *testing.T) {
+	p := &stubButtonUpdaterPlatform{stubInlineButtonPlatform: stubInlineButtonPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetDisplayConfig(DisplayCfg{PagedReplies: true})
+
+	send := func(p Platform, replyCtx any, content string) error { return p.Send(e.ctx, replyCtx, content) }
+	e.sendReplyChunks(p, "ctx", "s1", "", longReply(6), "", send)
+	first := p.buttonRows
+	args := strings.Fields(strings.TrimPrefix(first[0][2].Data, "cmd:/page"))
+	if len(args) != 2 || !strings.HasPrefix(args[0], pagedReplyIDPrefix) {
+		t.Fatalf("▶ data = %q", first[0][2].Data)
+	}
+
+	e.cmdPage(p, &Message{SessionKey: "s1", ReplyCtx: "button-msg", FromButton: true}, args)
+	if p.updatedCtx != "button-msg" || p.updatedRows[0][1].Text[:2] != "2/" {
+		t.Fatalf("updated %v with %+v", p.updatedCtx, p.updatedRows)
+	}
+	if p.buttonRows[0][1].Text != first[0][1].Text {
+		t.Fatal("a button press sent a new page message")
+	}
+
+	e.cmdPage(p, &Message{SessionKey: "s2", ReplyCtx: "ctx", FromButton: true}, args)
+	if sent := p.getSent(); len(sent) != 1 || sent[0] != e.i18n.T(MsgReplyPageExpired) {
+		t.Fatalf("another session's reply was shown: %v", sent)
+	}
+}
+
+func TestParseReplyPageArgs(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		id   string
+		file bool
+		page int
+	}{
+		{nil, "", false, 1},
+		{[]string{"3"}, "", false, 3},
+		{[]string{"p-12345678", "2"}, "p-12345678", false, 2},
+		{[]string{"p-00000001", "file", "4"}, "p-00000001", true, 4},
+	} {
+		id, file, page := parseReplyPageArgs(c.args)
+		if id != c.id || file != c.file || page != c.page {
+			t.Errorf("parseReplyPageArgs(%q) = %q, %v, %d", c.args, id, file, page)
+		}
+	}
+}
+
+func TestSendReplyChunks_DisabledOrShortSendsChunks(t *testing.T) {
+	p := &stubInlineButtonPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("test", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	send := func(p Platform, replyCtx any, content string) error { return p.Send(e.ctx, replyCtx, content) }
+
+	e.sendReplyChunks(p, "ctx", "s1", "", longReply(6), "", send)
+	if len(p.getSent()) < 2 || p.buttonRows != nil {
+		t.Fatalf("disabled: sent=%d buttons=%v", len(p.getSent()), p.buttonRows)
+	}
+
+	p.clearSent()
+	e.SetDisplayConfig(DisplayCfg{PagedReplies: true})
+	e.sendReplyChunks(p, "ctx", "s1", "", "short answer", "", send)
+	if sent := p.getSent(); len(sent) != 1 || p.buttonRows != nil {
+		t.Fatalf("short: sent=%v buttons=%v", sent, p.buttonRows)
+	}
+}
+
+func TestReplyPager_EvictsOldest(t *testing.T) {
+	var rp replyPager
+	for i := 0; i <= maxPagedReplies; i++ {
+		rp.add(&pagedReply{id: strconv.Itoa(i), sessionKey: "s", created: time.Now()})
+	}
+	if rp.get("s", "0") != nil {
+		t.Error("oldest reply was not evicted")
+	}
+	if r := rp.get("s", ""); r == nil || r.id != strconv.Itoa(maxPagedReplies) {
+		t.Errorf("latest = %+v", r)
+	}
+	rp.add(&pagedReply{id: "old", sessionKey: "t", created: time.Now().Add(-pagedReplyTTL - time.Minute)})
+	if rp.get("t", "") != nil {
+		t.Error("expired reply returned")
+	}
+}
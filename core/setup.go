@@ -212,7 +212,7 @@ func (m *ManagementServer) handleSetupFeishuSave(w http.ResponseWriter, r *http.
 		mgmtError(w, http.StatusServiceUnavailable, "feishu setup save not configured")
 		return
 	}
-	if err := m.setupFeishuSave(req); err != nil {
+	if err := m.setupFeishuSave(r.Context(), req); err != nil {
 		mgmtError(w, http.StatusInternalServerError, "save: "+err.Error())
 		return
 	}
@@ -456,7 +456,7 @@ func (m *ManagementServer) handleSetupWeixinSave(w http.ResponseWriter, r *http.
 		mgmtError(w, http.StatusServiceUnavailable, "weixin setup save not configured")
 		return
 	}
-	if err := m.setupWeixinSave(req); err != nil {
+	if err := m.setupWeixinSave(r.Context(), req); err != nil {
 		mgmtError(w, http.StatusInternalServerError, "save: "+err.Error())
 		return
 	}
@@ -499,7 +499,7 @@ func (m *ManagementServer) handleProjectAddPlatform(w http.ResponseWriter, r *ht
 		mgmtError(w, http.StatusServiceUnavailable, "config persistence not available")
 		return
 	}
-	if err := m.addPlatformToProject(projectName, req.Type, req.Options, req.WorkDir, req.AgentType); err != nil {
+	if err := m.addPlatformToProject(r.Context(), projectName, req.Type, req.Options, req.WorkDir, req.AgentType); err != nil {
 		mgmtError(w, http.StatusInternalServerError, "save config: "+err.Error())
 		return
 	}
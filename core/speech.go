@@ -11,7 +11,10 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -27,6 +30,7 @@ type SpeechCfg struct {
 	Provider string
 	Language string
 	STT      SpeechToText
+	Chunk    SpeechChunkCfg // splitting of long recordings
 }
 
 // OpenAIWhisper implements SpeechToText using the OpenAI-compatible Whisper API.
@@ -296,6 +300,121 @@ func (g *GeminiSTT) Transcribe(ctx context.Context, audio []byte, format string,
 	return strings.TrimSpace(result.Candidates[0].Content.Parts[0].Text), nil
 }
 
+// LocalWhisper implements SpeechToText with a local whisper.cpp or
+// faster-whisper command, for hosts without network access. Audio is
+// converted to 16 kHz mono WAV with ffmpeg before transcription.
+type LocalWhisper struct {
+	Engine  string        // "whisper.cpp" (default) or "faster-whisper"
+	Path    string        // executable (empty = "whisper-cli" / "whisper-ctranslate2")
+	Model   string        // ggml model file (whisper.cpp); model name or directory (faster-whisper)
+	Threads int           // 0 = engine default
+	Timeout time.Duration // per transcription
+}
+
+// Local whisper engines.
+const (
+	WhisperEngineCpp    = "whisper.cpp"
+	WhisperEngineFaster = "faster-whisper"
+)
+
+// NewLocalWhisper creates a new LocalWhisper instance.
+func NewLocalWhisper(engine, path, model string, threads int, timeout time.Duration) *LocalWhisper {
+	if engine == "" {
+		engine = WhisperEngineCpp
+	}
+	if path == "" {
+		path = "whisper-cli"
+		if engine == WhisperEngineFaster {
+			path = "whisper-ctranslate2"
+		}
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	return &LocalWhisper{
+		Engine:  engine,
+		Path:    path,
+		Model:   model,
+		Threads: threads,
+		Timeout: timeout,
+	}
+}
+
+// acceptsAnyAudio tells TranscribeAudio to pass the original audio through;
+// Transcribe converts it to WAV in one ffmpeg pass.
+func (w *LocalWhisper) acceptsAnyAudio() bool { return true }
+
+func (w *LocalWhisper) Transcribe(ctx context.Context, audio []byte, format string, lang string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	// The engines need 16 kHz mono PCM16; a WAV in any other encoding is
+	// converted like every other format.
+	if !isWhisperReadyWAV(audio, format) {
+		converted, err := ConvertAudioToWAV(ctx, audio, strings.ToLower(format))
+		if err != nil {
+			return "", err
+		}
+		audio = converted
+	}
+
+	dir, err := os.MkdirTemp("", "cc-connect-whisper-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	wavPath := filepath.Join(dir, "audio.wav")
+	if err := os.WriteFile(wavPath, audio, 0o600); err != nil {
+		return "", err
+	}
+
+	var args []string
+	switch w.Engine {
+	case WhisperEngineFaster:
+		args = []string{wavPath, "--output_format", "txt", "--output_dir", dir, "--verbose", "False"}
+		if fi, err := os.Stat(w.Model); err == nil && fi.IsDir() {
+			args = append(args, "--model_directory", w.Model)
+		} else if w.Model != "" {
+			args = append(args, "--model", w.Model)
+		}
+		if lang != "" {
+			args = append(args, "--language", lang)
+		}
+		if w.Threads > 0 {
+			args = append(args, "--threads", strconv.Itoa(w.Threads))
+		}
+	default:
+		if lang == "" {
+			lang = "auto" // whisper.cpp assumes English otherwise
+		}
+		args = []string{"-m", w.Model, "-f", wavPath, "-l", lang, "-nt", "-np"}
+		if w.Threads > 0 {
+			args = append(args, "-t", strconv.Itoa(w.Threads))
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, w.Path, args...)
+	cmd.WaitDelay = 5 * time.Second // don't hang on children holding the pipes
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("%s: timed out after %s", w.Engine, w.Timeout)
+		}
+		return "", fmt.Errorf("%s: %w (stderr: %s)", w.Engine, err, strings.TrimSpace(stderr.String()))
+	}
+
+	text := stdout.String()
+	if w.Engine == WhisperEngineFaster {
+		// The transcript file is authoritative; stdout may carry progress output.
+		if data, err := os.ReadFile(filepath.Join(dir, "audio.txt")); err == nil {
+			text = string(data)
+		}
+	}
+	return strings.Join(strings.Fields(text), " "), nil
+}
+
 // ConvertAudioToMP3 uses ffmpeg to convert audio from unsupported formats to mp3.
 // Returns the mp3 bytes. If ffmpeg is not installed, returns an error.
 // The ctx is honored: cancellation kills the ffmpeg subprocess, matching the
@@ -339,6 +458,30 @@ func ConvertAudioToMP3(ctx context.Context, audio []byte, srcFormat string) ([]b
 	return stdout.Bytes(), nil
 }
 
+// ConvertAudioToWAV uses ffmpeg to convert audio to 16 kHz mono 16-bit
+// WAV, the input format local whisper engines expect.
+func ConvertAudioToWAV(ctx context.Context, audio []byte, srcFormat string) ([]byte, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found in PATH: install ffmpeg to enable voice message support")
+	}
+
+	args := []string{"-i", "pipe:0", "-f", "wav", "-acodec", "pcm_s16le", "-ac", "1", "-ar", "16000", "-y", "pipe:1"}
+	if srcFormat == "amr" || srcFormat == "silk" {
+		args = append([]string{"-f", srcFormat}, args...)
+	}
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...)
+	cmd.Stdin = bytes.NewReader(audio)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg wav conversion failed: %w (stderr: %s)", err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
 // ConvertAudioToOpus uses ffmpeg to convert audio to opus format (ogg container).
 // Returns the opus bytes. If ffmpeg is not installed, returns an error.
 func ConvertAudioToOpus(ctx context.Context, audio []byte, srcFormat string) ([]byte, error) {
@@ -512,13 +655,35 @@ func formatToAudioMIME(format string) string {
 	}
 }
 
+// anyAudioTranscriber is implemented by providers that convert audio
+// themselves, so TranscribeAudio skips the MP3 conversion.
+type anyAudioTranscriber interface {
+	acceptsAnyAudio() bool
+}
+
+// isWhisperReadyWAV reports whether audio is a WAV file the local engines
+// read as is: 16 kHz mono PCM16.
+func isWhisperReadyWAV(audio []byte, format string) bool {
+	return strings.EqualFold(format, "wav") && len(audio) >= 12 &&
+		string(audio[0:4]) == "RIFF" && string(audio[8:12]) == "WAVE" && isChunkPCM(audio)
+}
+
+// audioNeedsFFmpeg reports whether transcribing audio with stt requires
+// ffmpeg.
+func audioNeedsFFmpeg(stt SpeechToText, audio *AudioAttachment) bool {
+	if _, ok := stt.(anyAudioTranscriber); ok {
+		return !isWhisperReadyWAV(audio.Data, audio.Format)
+	}
+	return NeedsConversion(audio.Format)
+}
+
 // TranscribeAudio is a convenience function used by the Engine.
 // It handles format conversion (if needed) and calls the STT provider.
 func TranscribeAudio(ctx context.Context, stt SpeechToText, audio *AudioAttachment, lang string) (string, error) {
 	data := audio.Data
 	format := strings.ToLower(audio.Format)
 
-	if NeedsConversion(format) {
+	if _, ok := stt.(anyAudioTranscriber); !ok && NeedsConversion(format) {
 		slog.Debug("speech: converting audio", "from", format, "to", "mp3")
 		converted, err := ConvertAudioToMP3(ctx, data, format)
 		if err != nil {
@@ -0,0 +1,360 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpeechChunkCfg controls how long recordings are split for transcription.
+type SpeechChunkCfg struct {
+	Window      time.Duration // target chunk length; shorter recordings are sent whole
+	Concurrency int           // chunks transcribed at once
+}
+
+const (
+	defaultSpeechChunkWindow      = 5 * time.Minute
+	defaultSpeechChunkConcurrency = 3
+
+	// chunkPCMRate is the byte rate of the 16 kHz mono 16-bit PCM chunks.
+	chunkPCMRate = 16000 * 2
+
+	// chunkGapMarker stands in for a chunk that failed to transcribe.
+	chunkGapMarker = "[...]"
+)
+
+// TranscriptionResult is the outcome of TranscribeAudioChunked.
+type TranscriptionResult struct {
+	Text   string
+	Chunks int // 1 when the recording was transcribed whole
+	Failed int // chunks that could not be transcribed
+}
+
+// TranscribeAudioChunked transcribes audio like TranscribeAudio, but splits
+// recordings longer than cfg.Window at silences (or at the window boundary
+// when there is none) and transcribes the chunks concurrently. Failed chunks
+// are marked with "[...]" and the rest is kept; an error is returned only
+// when every chunk fails. progress, if set, is called with (0, n) once the
+// recording is split and after each chunk finishes; calls are serialized.
+func TranscribeAudioChunked(ctx context.Context, stt SpeechToText, audio *AudioAttachment, lang string, cfg SpeechChunkCfg, progress func(done, total int)) (TranscriptionResult, error) {
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSpeechChunkWindow
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultSpeechChunkConcurrency
+	}
+	whole := func(audio *AudioAttachment) (TranscriptionResult, error) {
+		text, err := TranscribeAudio(ctx, stt, audio, lang)
+		return TranscriptionResult{Text: text, Chunks: 1}, err
+	}
+
+	format := strings.ToLower(audio.Format)
+	if d, ok := audioDuration(ctx, audio.Data, format); ok && d <= cfg.Window {
+		return whole(audio)
+	}
+	wav := audio.Data
+	pcm, err := wavPCM(wav)
+	if format != "wav" || err != nil || !isChunkPCM(wav) {
+		if !HasFFmpeg() {
+			return whole(audio)
+		}
+		if wav, err = ConvertAudioToWAV(ctx, audio.Data, format); err == nil {
+			pcm, err = wavPCM(wav)
+		}
+		if err != nil {
+			slog.Warn("speech: cannot split recording, transcribing it whole", "format", format, "error", err)
+			return whole(audio)
+		}
+	}
+	duration := pcmDuration(len(pcm))
+	if duration <= cfg.Window {
+		// Only reached when the length could not be probed; reuse the
+		// conversion instead of letting TranscribeAudio convert again.
+		return whole(&AudioAttachment{Format: "wav", Data: wav})
+	}
+
+	var silences []time.Duration
+	if HasFFmpeg() {
+		if silences, err = detectSilences(ctx, wav); err != nil {
+			slog.Warn("speech: silence detection failed, splitting at fixed windows", "error", err)
+		}
+	}
+	cuts := chunkCuts(duration, cfg.Window, silences)
+	chunks := make([][]byte, 0, len(cuts)+1)
+	start := 0
+	for _, c := range append(cuts, duration) {
+		end := min(pcmOffset(c), len(pcm))
+		chunks = append(chunks, pcm[start:end])
+		start = end
+	}
+	slog.Info("speech: transcribing in chunks", "duration", duration.Round(time.Second), "chunks", len(chunks))
+
+	var (
+		mu    sync.Mutex
+		done  int
+		texts = make([]string, len(chunks))
+		errs  = make([]error, len(chunks))
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, cfg.Concurrency)
+	)
+	if progress != nil {
+		progress(0, len(chunks))
+	}
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []byte) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			data, format := encodeChunk(ctx, stt, chunk)
+			text, err := stt.Transcribe(ctx, data, format, lang)
+			if err != nil {
+				slog.Warn("speech: chunk transcription failed", "chunk", i+1, "of", len(chunks), "error", err)
+			}
+			mu.Lock()
+			texts[i], errs[i] = strings.TrimSpace(text), err
+			done++
+			if progress != nil {
+				progress(done, len(chunks))
+			}
+			mu.Unlock()
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	res := TranscriptionResult{Chunks: len(chunks)}
+	parts := make([]string, 0, len(chunks))
+	for i := range chunks {
+		switch {
+		case errs[i] != nil:
+			res.Failed++
+			parts = append(parts, chunkGapMarker)
+		case texts[i] != "":
+			parts = append(parts, texts[i])
+		}
+	}
+	if res.Failed == len(chunks) {
+		return res, fmt.Errorf("all %d chunks failed: %w", len(chunks), errors.Join(errs...))
+	}
+	res.Text = strings.Join(parts, " ")
+	return res, nil
+}
+
+// encodeChunk prepares a PCM chunk for upload. Providers that take any
+// audio get WAV; the others get MP3, which is about a tenth of the size and
+// keeps a full window under the upload limits of the hosted APIs. WAV is
+// used as a fallback when the encoding fails.
+func encodeChunk(ctx context.Context, stt SpeechToText, pcm []byte) ([]byte, string) {
+	wav := pcmToWAV(pcm)
+	if _, ok := stt.(anyAudioTranscriber); ok || !HasFFmpeg() {
+		return wav, "wav"
+	}
+	mp3, err := ConvertAudioToMP3(ctx, wav, "wav")
+	if err != nil {
+		slog.Warn("speech: cannot encode chunk as mp3, sending wav", "error", err)
+		return wav, "wav"
+	}
+	return mp3, "mp3"
+}
+
+func pcmDuration(n int) time.Duration {
+	return time.Duration(n) * time.Second / chunkPCMRate
+}
+
+// pcmOffset converts a position to a byte offset on a sample boundary.
+func pcmOffset(d time.Duration) int {
+	return int(d*chunkPCMRate/time.Second) &^ 1
+}
+
+// chunkCuts returns the cut positions for a recording: one near each
+// multiple of window, moved back to the closest silence in the preceding
+// quarter window so words are not split.
+func chunkCuts(duration, window time.Duration, silences []time.Duration) []time.Duration {
+	var cuts []time.Duration
+	last := time.Duration(0)
+	for target := window; duration-last > window; target = last + window {
+		cut := target
+		for _, s := range silences {
+			if s > target-window/4 && s <= target {
+				cut = s // silences are sorted; keep the latest one
+			}
+		}
+		cuts = append(cuts, cut)
+		last = cut
+	}
+	return cuts
+}
+
+var silenceLogRe = regexp.MustCompile(`silence_(start|end): (-?[0-9.]+)`)
+
+// detectSilences runs ffmpeg's silencedetect filter over a WAV recording
+// and returns the midpoint of each silence, in order.
+func detectSilences(ctx context.Context, wav []byte) ([]time.Duration, error) {
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-nostats",
+		"-i", "pipe:0", "-af", "silencedetect=noise=-35dB:d=0.4", "-f", "null", "-")
+	cmd.Stdin = bytes.NewReader(wav)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg silencedetect failed: %w", err)
+	}
+
+	var mids []time.Duration
+	start := -1.0
+	for _, m := range silenceLogRe.FindAllStringSubmatch(stderr.String(), -1) {
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		if m[1] == "start" {
+			start = max(v, 0)
+		} else if start >= 0 {
+			mids = append(mids, time.Duration((start+v)/2*float64(time.Second)))
+			start = -1
+		}
+	}
+	sort.Slice(mids, func(i, j int) bool { return mids[i] < mids[j] })
+	return mids, nil
+}
+
+// audioDuration returns the length of a recording without decoding it: from
+// the header for WAV, otherwise with ffprobe. ok is false when the length
+// cannot be determined.
+func audioDuration(ctx context.Context, data []byte, format string) (time.Duration, bool) {
+	if d, ok := wavDuration(data); ok {
+		return d, true
+	}
+	ffprobePath, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return 0, false
+	}
+	// ffprobe needs a seekable file: several containers (m4a in
+	// particular) keep their duration at the end of the stream.
+	f, err := os.CreateTemp("", "cc-connect-probe-*."+formatToExt(format))
+	if err != nil {
+		return 0, false
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, false
+	}
+	args := []string{"-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1"}
+	if format == "amr" || format == "silk" {
+		args = append(args, "-f", format)
+	}
+	out, err := exec.CommandContext(ctx, ffprobePath, append(args, f.Name())...).Output()
+	if err != nil {
+		slog.Debug("speech: ffprobe failed", "format", format, "error", err)
+		return 0, false
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil || secs <= 0 {
+		return 0, false
+	}
+	return time.Duration(secs * float64(time.Second)), true
+}
+
+// wavDuration computes the length of a PCM WAV file from its byte rate.
+func wavDuration(wav []byte) (time.Duration, bool) {
+	pcm, err := wavPCM(wav)
+	if err != nil {
+		return 0, false
+	}
+	for off := 12; off+8 <= len(wav); {
+		size := int(binary.LittleEndian.Uint32(wav[off+4 : off+8]))
+		if string(wav[off:off+4]) == "fmt " {
+			if off+24 > len(wav) || binary.LittleEndian.Uint16(wav[off+8:]) != 1 {
+				return 0, false
+			}
+			rate := binary.LittleEndian.Uint32(wav[off+16:])
+			if rate == 0 {
+				return 0, false
+			}
+			return time.Duration(len(pcm)) * time.Second / time.Duration(rate), true
+		}
+		off += 8 + size + size&1
+	}
+	return 0, false
+}
+
+// isChunkPCM reports whether a WAV file already holds 16 kHz mono 16-bit
+// PCM, so it can be split without conversion.
+func isChunkPCM(wav []byte) bool {
+	for off := 12; off+8 <= len(wav); {
+		size := int(binary.LittleEndian.Uint32(wav[off+4 : off+8]))
+		if string(wav[off:off+4]) == "fmt " {
+			if off+24 > len(wav) {
+				return false
+			}
+			f := wav[off+8:]
+			return binary.LittleEndian.Uint16(f[0:]) == 1 && // PCM
+				binary.LittleEndian.Uint16(f[2:]) == 1 && // mono
+				binary.LittleEndian.Uint32(f[4:]) == 16000 &&
+				binary.LittleEndian.Uint16(f[14:]) == 16
+		}
+		off += 8 + size + size&1
+	}
+	return false
+}
+
+// wavPCM returns the sample data of a WAV file. The data chunk size is
+// ignored because ffmpeg cannot fill it in when writing to a pipe.
+func wavPCM(wav []byte) ([]byte, error) {
+	if len(wav) < 12 || string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		return nil, errors.New("not a WAV file")
+	}
+	for off := 12; off+8 <= len(wav); {
+		id := string(wav[off : off+4])
+		size := int(binary.LittleEndian.Uint32(wav[off+4 : off+8]))
+		if id == "data" {
+			return wav[off+8:], nil
+		}
+		off += 8 + size + size&1
+	}
+	return nil, errors.New("WAV file has no data chunk")
+}
+
+// pcmToWAV wraps 16 kHz mono 16-bit PCM in a WAV header.
+func pcmToWAV(pcm []byte) []byte {
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+	le := func(v any) { _ = binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("RIFF")
+	le(uint32(36 + len(pcm)))
+	b.WriteString("WAVEfmt ")
+	le(uint32(16))           // fmt chunk size
+	le(uint16(1))            // PCM
+	le(uint16(1))            // mono
+	le(uint32(16000))        // sample rate
+	le(uint32(chunkPCMRate)) // byte rate
+	le(uint16(2))            // block align
+	le(uint16(16))           // bits per sample
+	b.WriteString("data")
+	le(uint32(len(pcm)))
+	b.Write(pcm)
+	return b.Bytes()
+}
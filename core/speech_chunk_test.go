@@ -0,0 +1,153 @@
+package core
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChunkCuts_PrefersSilenceBeforeBoundary(t *testing.T) {
+	w := 5 * time.Minute
+	silences := []time.Duration{4*time.Minute + 10*time.Second, 4*time.Minute + 50*time.Second, 5*time.Minute + 5*time.Second, 9 * time.Minute}
+	got := chunkCuts(12*time.Minute, w, silences)
+	want := []time.Duration{4*time.Minute + 50*time.Second, 9 * time.Minute}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("cuts = %v, want %v", got, want)
+	}
+
+	if got := chunkCuts(11*time.Minute, w, nil); fmt.Sprint(got) != fmt.Sprint([]time.Duration{5 * time.Minute, 10 * time.Minute}) {
+		t.Fatalf("fixed cuts = %v", got)
+	}
+	if got := chunkCuts(4*time.Minute, w, nil); len(got) != 0 {
+		t.Fatalf("short recording cut at %v", got)
+	}
+}
+
+func TestPCMToWAV_RoundTrip(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4, 5, 6}
+	got, err := wavPCM(pcmToWAV(pcm))
+	if err != nil || string(got) != string(pcm) {
+		t.Fatalf("wavPCM = %v, %v", got, err)
+	}
+	if !isChunkPCM(pcmToWAV(pcm)) {
+		t.Fatal("pcmToWAV output not recognized as 16 kHz mono PCM")
+	}
+	if _, err := wavPCM([]byte("ID3 not a wav")); err == nil {
+		t.Fatal("expected error for non-WAV input")
+	}
+}
+
+func TestWAVDuration(t *testing.T) {
+	if d, ok := wavDuration(pcmToWAV(make([]byte, 3*chunkPCMRate))); !ok || d != 3*time.Second {
+		t.Fatalf("16 kHz mono = %v, %v", d, ok)
+	}
+	stereo := pcmToWAV(make([]byte, 44100*4))
+	binary.LittleEndian.PutUint16(stereo[22:], 2)       // channels
+	binary.LittleEndian.PutUint32(stereo[24:], 44100)   // sample rate
+	binary.LittleEndian.PutUint32(stereo[28:], 44100*4) // byte rate
+	if d, ok := wavDuration(stereo); !ok || d != time.Second {
+		t.Fatalf("44.1 kHz stereo = %v, %v", d, ok)
+	}
+	if _, ok := wavDuration([]byte("OggS not a wav")); ok {
+		t.Fatal("duration reported for non-WAV input")
+	}
+}
+
+// levelSTT "transcribes" a chunk as the level of its first sample and
+// fails the chunk starting at failLevel.
+type levelSTT struct {
+	failLevel int16
+	mu        sync.Mutex
+	calls     int
+}
+
+func (s *levelSTT) Transcribe(_ context.Context, audio []byte, format, _ string) (string, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	pcm, err := wavPCM(audio)
+	if err != nil || format != "wav" {
+		return "", fmt.Errorf("bad chunk: %v %s", err, format)
+	}
+	level := int16(binary.LittleEndian.Uint16(pcm))
+	if level == s.failLevel {
+		return "", errors.New("provider timeout")
+	}
+	return fmt.Sprintf("s%d", level), nil
+}
+
+// levelSTT reads the samples itself, like the local engines.
+func (*levelSTT) acceptsAnyAudio() bool { return true }
+
+// steppedPCM returns seconds of audio whose level changes every second, so
+// each chunk can be identified by its first sample and none is silent.
+func steppedPCM(seconds int) []byte {
+	pcm := make([]byte, 0, seconds*chunkPCMRate)
+	for s := 0; s < seconds; s++ {
+		for i := 0; i < 16000; i++ {
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(1000*(s+1)))
+		}
+	}
+	return pcm
+}
+
+func TestTranscribeAudioChunked_KeepsPartialResults(t *testing.T) {
+	stt := &levelSTT{failLevel: 3000}
+	audio := &AudioAttachment{Format: "wav", Data: pcmToWAV(steppedPCM(5))}
+	var progress []string
+	res, err := TranscribeAudioChunked(context.Background(), stt, audio, "", SpeechChunkCfg{Window: 2 * time.Second, Concurrency: 2}, func(done, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", done, total))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Text != "s1000 [...] s5000" || res.Chunks != 3 || res.Failed != 1 {
+		t.Fatalf("result = %+v", res)
+	}
+	if fmt.Sprint(progress) != "[0/3 1/3 2/3 3/3]" {
+		t.Fatalf("progress = %v", progress)
+	}
+}
+
+func TestTranscribeAudioChunked_ShortRecordingSentWhole(t *testing.T) {
+	stt := &levelSTT{}
+	audio := &AudioAttachment{Format: "wav", Data: pcmToWAV(steppedPCM(2))}
+	res, err := TranscribeAudioChunked(context.Background(), stt, audio, "", SpeechChunkCfg{Window: 5 * time.Second}, func(int, int) {
+		t.Error("progress reported for a single chunk")
+	})
+	if err != nil || res.Chunks != 1 || res.Text != "s1000" || stt.calls != 1 {
+		t.Fatalf("result = %+v, err = %v, calls = %d", res, err, stt.calls)
+	}
+}
+
+// formatSTT records the formats it is sent.
+type formatSTT struct {
+	mu      sync.Mutex
+	formats []string
+}
+
+func (s *formatSTT) Transcribe(_ context.Context, _ []byte, format, _ string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.formats = append(s.formats, format)
+	return "ok", nil
+}
+
+func TestTranscribeAudioChunked_HostedProvidersGetMP3Chunks(t *testing.T) {
+	if !HasFFmpeg() {
+		t.Skip("ffmpeg not in PATH")
+	}
+	stt := &formatSTT{}
+	audio := &AudioAttachment{Format: "wav", Data: pcmToWAV(steppedPCM(5))}
+	res, err := TranscribeAudioChunked(context.Background(), stt, audio, "", SpeechChunkCfg{Window: 2 * time.Second}, nil)
+	if err != nil || res.Chunks != 3 {
+		t.Fatalf("result = %+v, err = %v", res, err)
+	}
+	if fmt.Sprint(stt.formats) != "[mp3 mp3 mp3]" {
+		t.Fatalf("chunk formats = %v", stt.formats)
+	}
+}
@@ -2,12 +2,17 @@ package core
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewGeminiSTT_DefaultModel(t *testing.T) {
@@ -181,3 +186,81 @@ func TestConvertAudioToMP3_HonorsContextCancellation(t *testing.T) {
 		t.Fatal("expected error after context cancellation, got nil")
 	}
 }
+
+// fakeWhisper writes a shell script that records its arguments and prints
+// a transcript the way the real engine would.
+func fakeWhisper(t *testing.T, body string) (bin, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script fake")
+	}
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	bin = filepath.Join(dir, "whisper")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n" + body + "\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func TestLocalWhisper_WhisperCpp(t *testing.T) {
+	bin, argsFile := fakeWhisper(t, `printf '\n  Hello from\n  the air gap.\n'`)
+	w := NewLocalWhisper("", bin, "/models/ggml-base.bin", 4, 0)
+
+	text, err := w.Transcribe(context.Background(), pcmToWAV(make([]byte, 320)), "wav", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello from the air gap." {
+		t.Fatalf("text = %q", text)
+	}
+	args, _ := os.ReadFile(argsFile)
+	for _, want := range []string{"-m /models/ggml-base.bin", "-l auto", "-nt -np", "-t 4"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestLocalWhisper_FasterWhisperReadsTranscriptFile(t *testing.T) {
+	// whisper-ctranslate2 writes <output_dir>/<input base>.txt.
+	bin, argsFile := fakeWhisper(t, `echo "progress noise"; dir=$(dirname "$1"); echo "Hola mundo" > "$dir/audio.txt"`)
+	w := NewLocalWhisper(WhisperEngineFaster, bin, "small", 0, 0)
+
+	text, err := w.Transcribe(context.Background(), pcmToWAV(make([]byte, 320)), "wav", "es")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hola mundo" {
+		t.Fatalf("text = %q", text)
+	}
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "--model small") || !strings.Contains(string(args), "--language es") {
+		t.Errorf("args = %q", args)
+	}
+}
+
+func TestLocalWhisper_Timeout(t *testing.T) {
+	bin, _ := fakeWhisper(t, "exec sleep 5")
+	w := NewLocalWhisper("", bin, "model.bin", 0, 100*time.Millisecond)
+	if _, err := w.Transcribe(context.Background(), pcmToWAV(make([]byte, 320)), "wav", "en"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("err = %v, want timeout", err)
+	}
+}
+
+func TestAudioNeedsFFmpeg(t *testing.T) {
+	local := NewLocalWhisper("", "", "model.bin", 0, 0)
+	ready := &AudioAttachment{Format: "wav", Data: pcmToWAV(make([]byte, 320))}
+	if audioNeedsFFmpeg(local, ready) || !audioNeedsFFmpeg(local, &AudioAttachment{Format: "mp3"}) {
+		t.Error("local whisper needs ffmpeg for everything but 16 kHz mono PCM16 wav")
+	}
+	stereo := pcmToWAV(make([]byte, 320))
+	binary.LittleEndian.PutUint16(stereo[22:], 2)
+	if !audioNeedsFFmpeg(local, &AudioAttachment{Format: "wav", Data: stereo}) {
+		t.Error("a stereo wav must be normalized")
+	}
+	if audioNeedsFFmpeg(NewOpenAIWhisper("k", "", ""), &AudioAttachment{Format: "mp3"}) {
+		t.Error("openai whisper accepts mp3 directly")
+	}
+}
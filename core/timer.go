@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -22,6 +23,7 @@ type TimerJob struct {
 	Prompt      string    `json:"prompt"`
 	Exec        string    `json:"exec,omitempty"`     // shell command; mutually exclusive with Prompt
 	WorkDir     string    `json:"work_dir,omitempty"` // working directory for exec; empty = agent work_dir
+	Precheck    string    `json:"precheck,omitempty"` // shell command gating the run; non-zero exit skips it
 	Description string    `json:"description"`
 	Silent      *bool     `json:"silent,omitempty"`       // suppress start notification; nil = use global default
 	Mute        bool      `json:"mute,omitempty"`         // suppress ALL messages (start + result)
@@ -264,6 +266,13 @@ func (ts *TimerScheduler) RegisterEngine(name string, e *Engine) {
 	ts.engines[name] = e
 }
 
+// UnregisterEngine detaches a removed project; see CronScheduler.UnregisterEngine.
+func (ts *TimerScheduler) UnregisterEngine(name string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	delete(ts.engines, name)
+}
+
 // SetDefaultSilent sets the default silent mode for timer jobs.
 // Must be called before Start (not safe for concurrent use).
 func (ts *TimerScheduler) SetDefaultSilent(silent bool) {
@@ -425,6 +434,12 @@ func (ts *TimerScheduler) executeJob(jobID string) {
 		err = <-done
 	}
 
+	if errors.Is(err, ErrPrecheckSkipped) {
+		ts.store.MarkFired(jobID, nil)
+		slog.Info("timer: job skipped by precheck", "id", jobID)
+		return
+	}
+
 	ts.store.MarkFired(jobID, err)
 
 	if err != nil {
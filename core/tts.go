@@ -38,6 +38,10 @@ type TTSCfg struct {
 	Speed        float64 // speaking speed multiplier; 0 = provider default
 	TTS          TextToSpeech
 	MaxTextLen   int // max rune count before skipping TTS; 0 = no limit
+	// Streaming speaks replies paragraph by paragraph while they stream;
+	// MaxTextLen then splits long paragraphs instead of skipping the reply.
+	Streaming bool
+	Cache     *TTSCache // optional on-disk cache of synthesized clips
 
 	mu      sync.RWMutex
 	ttsMode string // "voice_only" (default) | "always"
@@ -407,6 +411,18 @@ func (m *MiniMaxTTS) Synthesize(ctx context.Context, text string, opts TTSSynthe
 	return audioBuf.Bytes(), "mp3", nil
 }
 
+// PauseMarkup returns MiniMax's inline pause marker, <#seconds#>, which
+// accepts 0.01–99.99 seconds.
+func (m *MiniMaxTTS) PauseMarkup(d time.Duration) string {
+	sec := d.Seconds()
+	if sec < 0.01 {
+		sec = 0.01
+	} else if sec > 99.99 {
+		sec = 99.99
+	}
+	return fmt.Sprintf("<#%.2f#>", sec)
+}
+
 // ──────────────────────────────────────────────────────────────
 // MimoTTS — Xiaomi MiMo-V2.5-TTS implementation
 // ──────────────────────────────────────────────────────────────
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultTTSCacheMaxBytes caps the on-disk TTS cache when no size is configured.
+const defaultTTSCacheMaxBytes = 200 << 20
+
+// TTSCache stores synthesized clips on disk, keyed by provider, voice and
+// text, so repeated phrases are not synthesized (and billed) again. The
+// least recently used clips are evicted once the cache outgrows MaxBytes.
+type TTSCache struct {
+	Dir      string
+	MaxBytes int64
+
+	mu sync.Mutex
+}
+
+// NewTTSCache creates a cache rooted at dir. maxBytes <= 0 uses the default.
+func NewTTSCache(dir string, maxBytes int64) *TTSCache {
+	if maxBytes <= 0 {
+		maxBytes = defaultTTSCacheMaxBytes
+	}
+	return &TTSCache{Dir: dir, MaxBytes: maxBytes}
+}
+
+// ttsCacheKey identifies a clip by everything that changes the audio.
+func ttsCacheKey(provider, text string, opts TTSSynthesisOpts) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		provider, opts.Voice, opts.LanguageType, fmt.Sprintf("%g", opts.Speed), text,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached clip for key and marks it as recently used.
+func (c *TTSCache) Get(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	matches, _ := filepath.Glob(filepath.Join(c.Dir, key+".*"))
+	for _, path := range matches {
+		if strings.HasSuffix(path, ".tmp") {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return data, strings.TrimPrefix(filepath.Ext(path), "."), true
+	}
+	return nil, "", false
+}
+
+// Put stores a clip under key and evicts old clips beyond MaxBytes.
+func (c *TTSCache) Put(key string, audio []byte, format string) error {
+	if format == "" || strings.ContainsAny(format, `/\.`) {
+		return fmt.Errorf("tts cache: invalid format %q", format)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("tts cache: %w", err)
+	}
+	path := filepath.Join(c.Dir, key+"."+format)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, audio, 0o644); err != nil {
+		return fmt.Errorf("tts cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("tts cache: %w", err)
+	}
+	c.evictLocked()
+	return nil
+}
+
+// evictLocked removes the least recently used clips until the cache fits.
+func (c *TTSCache) evictLocked() {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	type clip struct {
+		path  string
+		size  int64
+		mtime time.Time
+	}
+	var clips []clip
+	var total int64
+	for _, ent := range entries {
+		if !ent.Type().IsRegular() {
+			continue
+		}
+		info, err := ent.Info()
+		if err != nil {
+			continue
+		}
+		clips = append(clips, clip{filepath.Join(c.Dir, ent.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.MaxBytes {
+		return
+	}
+	sort.Slice(clips, func(i, j int) bool { return clips[i].mtime.Before(clips[j].mtime) })
+	for _, cl := range clips {
+		if total <= c.MaxBytes {
+			break
+		}
+		if err := os.Remove(cl.path); err != nil {
+			slog.Debug("tts cache: evict failed", "path", cl.path, "error", err)
+			continue
+		}
+		total -= cl.size
+	}
+}
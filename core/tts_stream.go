@@ -0,0 +1,380 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// TTSPauseMarker is implemented by TTS providers that accept inline pauses.
+// PauseMarkup returns the provider's markup for a pause of length d.
+type TTSPauseMarker interface {
+	PauseMarkup(d time.Duration) string
+}
+
+// ttsBreakRe matches SSML-style pauses such as <break time="500ms"/>.
+var ttsBreakRe = regexp.MustCompile(`<break\s+time\s*=\s*["']?(\d+(?:\.\d+)?)\s*(ms|s)["']?\s*/?>`)
+
+// applyTTSPauses rewrites SSML-style breaks into tts's pause markup, or
+// drops them when the provider has no pause support.
+func applyTTSPauses(tts TextToSpeech, text string) string {
+	pm, ok := tts.(TTSPauseMarker)
+	return ttsBreakRe.ReplaceAllStringFunc(text, func(tag string) string {
+		if !ok {
+			return ""
+		}
+		m := ttsBreakRe.FindStringSubmatch(tag)
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return ""
+		}
+		unit := time.Second
+		if m[2] == "ms" {
+			unit = time.Millisecond
+		}
+		return pm.PauseMarkup(time.Duration(v * float64(unit)))
+	})
+}
+
+// synthesizeTTS synthesizes plain text with the configured voice, going
+// through the audio cache when one is set.
+func (e *Engine) synthesizeTTS(ctx context.Context, text string) ([]byte, string, error) {
+	text = applyTTSPauses(e.tts.TTS, text)
+	opts := TTSSynthesisOpts{
+		Voice:        e.tts.Voice,
+		LanguageType: e.tts.LanguageType,
+		Speed:        e.tts.Speed,
+	}
+	var key string
+	if e.tts.Cache != nil {
+		key = ttsCacheKey(e.tts.Provider, text, opts)
+		if audio, format, ok := e.tts.Cache.Get(key); ok {
+			slog.Debug("tts: cache hit", "format", format, "audio_size", len(audio))
+			return audio, format, nil
+		}
+	}
+	audio, format, err := e.tts.TTS.Synthesize(ctx, text, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if e.tts.Cache != nil {
+		if err := e.tts.Cache.Put(key, audio, format); err != nil {
+			slog.Warn("tts: cache write failed", "error", err)
+		}
+	}
+	return audio, format, nil
+}
+
+// cutTTSParagraphs splits the complete paragraphs off buf. A paragraph ends
+// at a blank line outside a code fence; the unfinished tail is returned as
+// rest.
+func cutTTSParagraphs(buf string) (paras []string, rest string) {
+	start, from := 0, 0
+	for {
+		i := strings.Index(buf[from:], "\n\n")
+		if i < 0 {
+			break
+		}
+		end := from + i
+		from = end + 2
+		if strings.Count(buf[start:end], "```")%2 != 0 {
+			continue // inside a code block
+		}
+		if p := strings.TrimSpace(buf[start:end]); p != "" {
+			paras = append(paras, p)
+		}
+		start = from
+	}
+	return paras, buf[start:]
+}
+
+// splitTTSText breaks text into pieces of at most maxLen runes, preferring
+// sentence ends, then spaces. maxLen <= 0 leaves text whole.
+func splitTTSText(text string, maxLen int) []string {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return []string{text}
+	}
+	var pieces []string
+	runes := []rune(text)
+	for len(runes) > maxLen {
+		end, space := 0, 0
+		for i := maxLen - 1; i >= maxLen/2 && end == 0; i-- {
+			switch {
+			case strings.ContainsRune(".!?。！？；;\n", runes[i]):
+				end = i + 1
+			case runes[i] == ' ' && space == 0:
+				space = i + 1
+			}
+		}
+		if end == 0 {
+			end = space
+		}
+		if end == 0 {
+			end = maxLen
+		}
+		if p := strings.TrimSpace(string(runes[:end])); p != "" {
+			pieces = append(pieces, p)
+		}
+		runes = runes[end:]
+	}
+	if p := strings.TrimSpace(string(runes)); p != "" {
+		pieces = append(pieces, p)
+	}
+	return pieces
+}
+
+// ttsClip is a synthesized piece of a paragraph waiting to be sent.
+type ttsClip struct {
+	audio  []byte
+	format string
+}
+
+// ttsStream speaks a reply paragraph by paragraph while it is still
+// streaming. Text is fed from the event loop without blocking; a worker
+// synthesizes the paragraphs in order as they complete, but holds the clips
+// until finish: until then a tool call or a NO_REPLY can still turn the
+// text into narration that must not be spoken. A nil *ttsStream is a no-op.
+type ttsStream struct {
+	e        *Engine
+	p        Platform
+	as       AudioSender
+	replyCtx any
+	ctx      context.Context
+	cancel   context.CancelFunc
+	prev     <-chan struct{} // previous turn's stream; its clips go out first
+
+	buf strings.Builder // text not yet cut into paragraphs
+	fed bool
+
+	mu       sync.Mutex
+	pending  []string  // paragraphs not synthesized yet
+	ready    []ttsClip // synthesized clips held until finish
+	gen      int       // bumped by reset and discard to drop in-flight synthesis
+	finished bool
+	wake     chan struct{}
+	done     chan struct{}
+}
+
+// newTTSStream starts a paragraph TTS stream for one turn, or returns nil
+// when streaming TTS does not apply: it is disabled, the TTS mode does not
+// cover this turn, or the platform cannot send audio. prev is the stream of
+// the session's previous turn, if any; this stream sends nothing until prev
+// is done, so the clips of consecutive turns do not interleave.
+func (e *Engine) newTTSStream(p Platform, replyCtx any, fromVoice bool, prev *ttsStream) *ttsStream {
+	if e.tts == nil || !e.tts.Enabled || e.tts.TTS == nil || !e.tts.Streaming {
+		return nil
+	}
+	if mode := e.tts.GetTTSMode(); mode != "always" && !(mode == "voice_only" && fromVoice) {
+		return nil
+	}
+	as, ok := p.(AudioSender)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(e.ctx)
+	ts := &ttsStream{
+		e:        e,
+		p:        p,
+		as:       as,
+		replyCtx: replyCtx,
+		ctx:      ctx,
+		cancel:   cancel,
+		prev:     prev.doneChan(),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go ts.run()
+	return ts
+}
+
+// doneChan returns a channel closed when the worker has exited, or nil for
+// a nil stream.
+func (ts *ttsStream) doneChan() <-chan struct{} {
+	if ts == nil {
+		return nil
+	}
+	return ts.done
+}
+
+// feed adds streamed reply text and queues any paragraphs it completes.
+func (ts *ttsStream) feed(text string) {
+	if ts == nil || text == "" {
+		return
+	}
+	ts.fed = true
+	ts.buf.WriteString(text)
+	paras, rest := cutTTSParagraphs(ts.buf.String())
+	if len(paras) == 0 {
+		return
+	}
+	ts.buf.Reset()
+	ts.buf.WriteString(rest)
+	ts.enqueue(paras, false)
+}
+
+// reset drops the text streamed so far, along with any clips already
+// synthesized from it. It is called on tool calls so that only the text
+// after the last one, the final reply, is read out.
+func (ts *ttsStream) reset() {
+	if ts == nil {
+		return
+	}
+	ts.fed = false
+	ts.buf.Reset()
+	ts.mu.Lock()
+	if !ts.finished {
+		ts.pending, ts.ready = nil, nil
+		ts.gen++
+	}
+	ts.mu.Unlock()
+}
+
+// finish queues the remaining text and lets the worker drain. The tail is
+// cleaned the way the final reply is; fullResponse, which is already clean,
+// is spoken instead when no text was streamed after the last tool call.
+func (ts *ttsStream) finish(fullResponse string) {
+	if ts == nil {
+		return
+	}
+	rest := fullResponse
+	if ts.fed {
+		rest = ts.buf.String()
+		if ts.e.display.HideAgentFooter {
+			rest = stripAgentFooterLines(rest)
+		}
+		rest = strings.TrimRight(ctxSelfReportRe.ReplaceAllString(rest, ""), "\n ")
+		if stripped, ok := stripTrailingSilent(rest); ok {
+			rest = stripped
+		}
+	}
+	ts.buf.Reset()
+	paras, tail := cutTTSParagraphs(rest)
+	if tail = strings.TrimSpace(tail); tail != "" {
+		paras = append(paras, tail)
+	}
+	ts.enqueue(paras, true)
+}
+
+// discard drops queued paragraphs and held clips and stops the worker. It has no effect
+// once finish has been called.
+func (ts *ttsStream) discard() {
+	if ts == nil {
+		return
+	}
+	ts.mu.Lock()
+	if ts.finished {
+		ts.mu.Unlock()
+		return
+	}
+	ts.finished = true
+	ts.pending, ts.ready = nil, nil
+	ts.gen++
+	ts.mu.Unlock()
+	ts.cancel()
+	ts.signal()
+}
+
+func (ts *ttsStream) enqueue(paras []string, last bool) {
+	ts.mu.Lock()
+	if !ts.finished {
+		ts.pending = append(ts.pending, paras...)
+		ts.finished = last
+	}
+	ts.mu.Unlock()
+	ts.signal()
+}
+
+func (ts *ttsStream) signal() {
+	select {
+	case ts.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (ts *ttsStream) run() {
+	defer close(ts.done)
+	defer ts.cancel()
+	sent := 0
+	for {
+		ts.mu.Lock()
+		finished := ts.finished
+		if finished && len(ts.ready) > 0 {
+			clip := ts.ready[0]
+			ts.ready = ts.ready[1:]
+			ts.mu.Unlock()
+			if err := ts.send(clip); err != nil {
+				slog.Warn("tts: paragraph send failed", "platform", ts.p.Name(), "error", err)
+				if ts.ctx.Err() != nil {
+					return
+				}
+			} else {
+				sent++
+			}
+			continue
+		}
+		if len(ts.pending) > 0 {
+			next, gen := ts.pending[0], ts.gen
+			ts.pending = ts.pending[1:]
+			ts.mu.Unlock()
+			clips := ts.synthesize(next)
+			ts.mu.Lock()
+			if ts.gen == gen {
+				ts.ready = append(ts.ready, clips...)
+			}
+			ts.mu.Unlock()
+			continue
+		}
+		ts.mu.Unlock()
+
+		if finished {
+			if sent > 0 {
+				slog.Info("tts: streamed reply sent", "platform", ts.p.Name(), "clips", sent)
+			}
+			return
+		}
+		select {
+		case <-ts.wake:
+		case <-ts.ctx.Done():
+			return
+		}
+	}
+}
+
+// synthesize turns one paragraph into clips, split to max_text_len.
+func (ts *ttsStream) synthesize(para string) []ttsClip {
+	text := strings.TrimSpace(StripMarkdown(ctxSelfReportRe.ReplaceAllString(para, "")))
+	if text == "" {
+		return nil
+	}
+	var clips []ttsClip
+	for _, piece := range splitTTSText(text, ts.e.tts.MaxTextLen) {
+		if ts.ctx.Err() != nil {
+			return clips
+		}
+		audio, format, err := ts.e.synthesizeTTS(ts.ctx, piece)
+		if err != nil {
+			slog.Warn("tts: paragraph synthesis failed", "platform", ts.p.Name(), "text_len", len(piece), "error", err)
+			continue
+		}
+		clips = append(clips, ttsClip{audio: audio, format: format})
+	}
+	return clips
+}
+
+// send delivers one clip once the previous turn's stream is done.
+func (ts *ttsStream) send(clip ttsClip) error {
+	if ts.prev != nil {
+		select {
+		case <-ts.prev:
+			ts.prev = nil
+		case <-ts.ctx.Done():
+			return ts.ctx.Err()
+		}
+	}
+	return ts.as.SendAudio(ts.ctx, ts.replyCtx, clip.audio, clip.format)
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCutTTSParagraphs_KeepsCodeBlocksWhole(t *testing.T) {
+	paras, rest := cutTTSParagraphs("Intro.\n\n```go\nx := 1\n\ny := 2\n```\n\nAfter the code.\n\nStill typ")
+	want := []string{"Intro.", "```go\nx := 1\n\ny := 2\n```", "After the code."}
+	if fmt.Sprintf("%q", paras) != fmt.Sprintf("%q", want) || rest != "Still typ" {
+		t.Fatalf("paras = %q, rest = %q", paras, rest)
+	}
+
+	paras, rest = cutTTSParagraphs("```sh\nls\n\n")
+	if len(paras) != 0 || rest != "```sh\nls\n\n" {
+		t.Fatalf("open code block was cut: %q, %q", paras, rest)
+	}
+}
+
+func TestSplitTTSText_PrefersSentenceEnds(t *testing.T) {
+	got := splitTTSText("One two. Three four five six.", 12)
+	if fmt.Sprintf("%q", got) != `["One two." "Three four" "five six."]` {
+		t.Fatalf("pieces = %q", got)
+	}
+	if got := splitTTSText("short", 0); len(got) != 1 || got[0] != "short" {
+		t.Fatalf("pieces = %q", got)
+	}
+}
+
+func TestApplyTTSPauses(t *testing.T) {
+	text := `Step one.<break time="500ms"/> Step two. <break time='1.5s' />Done.`
+	if got := applyTTSPauses(NewMiniMaxTTS("k", "", "", nil), text); got != "Step one.<#0.50#> Step two. <#1.50#>Done." {
+		t.Fatalf("minimax = %q", got)
+	}
+	if got := applyTTSPauses(NewOpenAITTS("k", "", "", nil), text); got != "Step one. Step two. Done." {
+		t.Fatalf("openai = %q", got)
+	}
+}
+
+func TestTTSCache_HitAndEviction(t *testing.T) {
+	c := NewTTSCache(t.TempDir(), 10)
+	opts := TTSSynthesisOpts{Voice: "alloy"}
+	old := ttsCacheKey("openai", "old", opts)
+	if err := c.Put(old, []byte("123456"), "mp3"); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(c.Dir, old+".mp3"), past, past)
+
+	if ttsCacheKey("openai", "old", TTSSynthesisOpts{Voice: "nova"}) == old {
+		t.Fatal("voice not part of the cache key")
+	}
+	recent := ttsCacheKey("openai", "recent", opts)
+	if err := c.Put(recent, []byte("abcdef"), "wav"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := c.Get(old); ok {
+		t.Fatal("least recently used clip was not evicted")
+	}
+	audio, format, ok := c.Get(recent)
+	if !ok || string(audio) != "abcdef" || format != "wav" {
+		t.Fatalf("Get = %q, %q, %v", audio, format, ok)
+	}
+}
+
+// sequenceTTS records every synthesized text in order.
+type sequenceTTS struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (s *sequenceTTS) Synthesize(_ context.Context, text string, _ TTSSynthesisOpts) ([]byte, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.texts = append(s.texts, text)
+	return []byte("audio:" + text), "mp3", nil
+}
+
+func (s *sequenceTTS) snapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.texts...)
+}
+
+func waitTTSStream(t *testing.T, ts *ttsStream) {
+	t.Helper()
+	select {
+	case <-ts.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tts stream did not finish")
+	}
+}
+
+func TestTTSStream_SpeaksParagraphsWhileStreaming(t *testing.T) {
+	tts := &sequenceTTS{}
+	p := &audioStubPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("assistant", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	cfg := &TTSCfg{Enabled: true, Provider: "test", TTS: tts, Streaming: true, MaxTextLen: 20, Cache: NewTTSCache(t.TempDir(), 0)}
+	cfg.SetTTSMode("always")
+	e.SetTTSConfig(cfg)
+
+	ts := e.newTTSStream(p, "ctx", false, nil)
+	if ts == nil {
+		t.Fatal("stream not started")
+	}
+	ts.feed("**Sure.**\n\nThe longer second paragraph")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(tts.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := tts.snapshot(); len(got) != 1 || got[0] != "Sure." {
+		t.Fatalf("synthesized before finish = %q", got)
+	}
+	if _, _, calls := p.audioSnapshot(); calls != 0 {
+		t.Fatalf("audio sent before the turn finished: %d clips", calls)
+	}
+	ts.feed(" goes on.\n\nSure.")
+	ts.finish("ignored")
+	waitTTSStream(t, ts)
+
+	want := []string{"Sure.", "The longer second", "paragraph goes on."}
+	if got := tts.snapshot(); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Fatalf("synthesized = %q, want %q (last Sure. should come from cache)", got, want)
+	}
+	if _, _, calls := p.audioSnapshot(); calls != 4 {
+		t.Fatalf("audio sends = %d, want 4", calls)
+	}
+}
+
+func TestTTSStream_SkippedWhenModeDoesNotApply(t *testing.T) {
+	p := &audioStubPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("assistant", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetTTSConfig(&TTSCfg{Enabled: true, TTS: &sequenceTTS{}, Streaming: true})
+
+	if ts := e.newTTSStream(p, "ctx", false, nil); ts != nil {
+		t.Fatal("voice_only mode streamed a reply to a text message")
+	}
+	if ts := e.newTTSStream(&stubPlatformEngine{n: "discord"}, "ctx", true, nil); ts != nil {
+		t.Fatal("stream started for a platform without audio sending")
+	}
+	var ts *ttsStream
+	ts.feed("no-op")
+	ts.finish("no-op")
+	ts.discard()
+}
+
+func TestTTSStream_DiscardDropsQueuedText(t *testing.T) {
+	tts := &sequenceTTS{}
+	p := &audioStubPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("assistant", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	e.SetTTSConfig(&TTSCfg{Enabled: true, TTS: tts, Streaming: true})
+
+	ts := e.newTTSStream(p, "ctx", true, nil)
+	ts.feed("Half a sentence")
+	ts.discard()
+	ts.finish("Half a sentence")
+	waitTTSStream(t, ts)
+	if got := tts.snapshot(); len(got) != 0 {
+		t.Fatalf("synthesized after discard: %q", got)
+	}
+}
+
+func TestTTSStream_SpeaksOnlyTextAfterLastToolCall(t *testing.T) {
+	tts := &sequenceTTS{}
+	p := &audioStubPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("assistant", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	cfg := &TTSCfg{Enabled: true, TTS: tts, Streaming: true}
+	cfg.SetTTSMode("always")
+	e.SetTTSConfig(cfg)
+
+	ts := e.newTTSStream(p, "ctx", false, nil)
+	ts.feed("Let me check the logs")
+	ts.reset()
+	ts.feed("The build is green.\n[ctx: ~40%]\n\nNO_REPLY")
+	ts.finish("Let me check the logsThe build is green.")
+	waitTTSStream(t, ts)
+
+	if got := tts.snapshot(); len(got) != 1 || got[0] != "The build is green." {
+		t.Fatalf("synthesized = %q", got)
+	}
+}
+
+func TestTTSStream_ToolCallDropsSynthesizedNarration(t *testing.T) {
+	tts := &sequenceTTS{}
+	p := &audioStubPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("assistant", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	cfg := &TTSCfg{Enabled: true, TTS: tts, Streaming: true}
+	cfg.SetTTSMode("always")
+	e.SetTTSConfig(cfg)
+
+	ts := e.newTTSStream(p, "ctx", false, nil)
+	ts.feed("Let me check the logs.\n\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(tts.snapshot()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ts.reset()
+	ts.feed("The build is green.")
+	ts.finish("The build is green.")
+	waitTTSStream(t, ts)
+
+	audio, _, calls := p.audioSnapshot()
+	if calls != 1 || string(audio) != "audio:The build is green." {
+		t.Fatalf("sent %d clips, last %q", calls, audio)
+	}
+}
+
+func TestTTSStream_WaitsForPreviousTurn(t *testing.T) {
+	tts := &sequenceTTS{}
+	p := &audioStubPlatform{stubPlatformEngine: stubPlatformEngine{n: "telegram"}}
+	e := NewEngine("assistant", &stubAgent{}, []Platform{p}, "", LangEnglish)
+	cfg := &TTSCfg{Enabled: true, TTS: tts, Streaming: true}
+	cfg.SetTTSMode("always")
+	e.SetTTSConfig(cfg)
+
+	first := e.newTTSStream(p, "ctx", false, nil)
+	first.feed("First.\n\n")
+	second := e.newTTSStream(p, "ctx", false, first)
+	second.finish("Second.")
+	deadline := time.Now().Add(5 * time.Second)
+	for len(tts.snapshot()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, _, calls := p.audioSnapshot(); calls != 0 {
+		t.Fatalf("second turn sent %d clips before the first finished", calls)
+	}
+
+	first.finish("First.")
+	waitTTSStream(t, first)
+	waitTTSStream(t, second)
+	audio, _, calls := p.audioSnapshot()
+	if calls != 2 || string(audio) != "audio:Second." {
+		t.Fatalf("sent %d clips, last %q", calls, audio)
+	}
+}
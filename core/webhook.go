@@ -60,6 +60,12 @@ func (ws *WebhookServer) RegisterEngine(name string, e *Engine) {
 	ws.engines[name] = e
 }
 
+func (ws *WebhookServer) UnregisterEngine(name string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	delete(ws.engines, name)
+}
+
 func (ws *WebhookServer) Start() {
 	mux := http.NewServeMux()
 	mux.HandleFunc(ws.path, ws.handleHook)
@@ -205,7 +211,7 @@ func (ws *WebhookServer) executePrompt(engine *Engine, sessionKey, prompt string
 	}
 
 	var targetPlatform Platform
-	for _, p := range engine.platforms {
+	for _, p := range engine.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -264,7 +270,7 @@ func (ws *WebhookServer) executeShell(engine *Engine, req WebhookRequest, event
 	}
 
 	var targetPlatform Platform
-	for _, p := range engine.platforms {
+	for _, p := range engine.platformList() {
 		if p.Name() == platformName {
 			targetPlatform = p
 			break
@@ -293,7 +299,7 @@ func (ws *WebhookServer) executeShell(engine *Engine, req WebhookRequest, event
 
 	workDir := req.WorkDir
 	if workDir == "" {
-		if wd, ok := engine.agent.(interface{ GetWorkDir() string }); ok {
+		if wd, ok := engine.GetAgent().(interface{ GetWorkDir() string }); ok {
 			workDir = wd.GetWorkDir()
 		}
 	}
@@ -15,6 +15,28 @@ import (
 
 func init() {
 	core.RegisterPlatform("cloud_web", New)
+	core.RegisterPlatformOptions("cloud_web", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "token", Type: core.OptionString, Required: true},
+		{Name: "transport", Type: core.OptionString, Enum: []string{"websocket", "long_poll", "gateway"}},
+		{Name: "ws_url", Type: core.OptionString},
+		{Name: "base_url", Type: core.OptionString},
+		{Name: "register_url", Type: core.OptionString},
+		{Name: "public_url", Type: core.OptionString},
+		{Name: "listen", Type: core.OptionString},
+		{Name: "webhook_path", Type: core.OptionString},
+		{Name: "events_path", Type: core.OptionString},
+		{Name: "send_path", Type: core.OptionString},
+		{Name: "long_poll_timeout_ms", Type: core.OptionAny},
+		{Name: "name", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "group_reply_all", Type: core.OptionBool},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 type Platform struct {
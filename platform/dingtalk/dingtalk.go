@@ -26,6 +26,24 @@ import (
 
 func init() {
 	core.RegisterPlatform("dingtalk", New)
+	core.RegisterPlatformOptions("dingtalk", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "client_id", Type: core.OptionString, Required: true},
+		{Name: "client_secret", Type: core.OptionString, Required: true},
+		{Name: "robot_code", Type: core.OptionString},
+		{Name: "agent_id", Type: core.OptionInt},
+		{Name: "card_template_id", Type: core.OptionString},
+		{Name: "card_template_key", Type: core.OptionString},
+		{Name: "card_throttle_ms", Type: core.OptionInt},
+		{Name: "reaction_emoji", Type: core.OptionString},
+		{Name: "done_emoji", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 type replyContext struct {
@@ -20,6 +20,26 @@ import (
 
 func init() {
 	core.RegisterPlatform("discord", New)
+	core.RegisterPlatformOptions("discord", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "token", Type: core.OptionString, Required: true},
+		{Name: "guild_id", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "group_reply_all", Type: core.OptionBool},
+		{Name: "group_reply_all_guilds", Type: core.OptionString},
+		{Name: "thread_isolation", Type: core.OptionBool},
+		{Name: "respond_to_at_everyone_and_here", Type: core.OptionBool},
+		{Name: "progress_style", Type: core.OptionString, Enum: []string{"legacy", "compact", "card"}},
+		{Name: "reply_hud", Type: core.OptionBool, Description: "Documented in config.example.toml; currently has no effect"},
+		{Name: "proxy", Type: core.OptionString},
+		{Name: "proxy_username", Type: core.OptionString},
+		{Name: "proxy_password", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 const maxDiscordLen = 1900
@@ -104,6 +104,37 @@ func init() {
 	core.RegisterPlatform("lark", func(opts map[string]any) (core.Platform, error) {
 		return newPlatform("lark", lark.LarkBaseUrl, opts)
 	})
+	core.RegisterPlatformOptions("feishu", optionSchema)
+	core.RegisterPlatformOptions("lark", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "app_id", Type: core.OptionString, Required: true},
+		{Name: "app_secret", Type: core.OptionString, Required: true},
+		{Name: "domain", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "allow_chat", Type: core.OptionString},
+		{Name: "group_only", Type: core.OptionBool},
+		{Name: "group_reply_all", Type: core.OptionBool},
+		{Name: "require_mention", Type: core.OptionBool},
+		{Name: "resolve_mentions", Type: core.OptionBool},
+		{Name: "mention_map", Type: core.OptionTable},
+		{Name: "peer_bots", Type: core.OptionTable},
+		{Name: "thread_isolation", Type: core.OptionBool},
+		{Name: "reply_to_trigger", Type: core.OptionBool},
+		{Name: "respond_to_at_everyone_and_here", Type: core.OptionBool},
+		{Name: "progress_style", Type: core.OptionString, Enum: []string{"legacy", "compact", "card"}},
+		{Name: "enable_feishu_card", Type: core.OptionBool},
+		{Name: "reaction_emoji", Type: core.OptionString},
+		{Name: "done_emoji", Type: core.OptionString},
+		{Name: "image_batch_window_ms", Type: core.OptionAny},
+		{Name: "port", Type: core.OptionString},
+		{Name: "callback_path", Type: core.OptionString},
+		{Name: "encrypt_key", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 type replyContext struct {
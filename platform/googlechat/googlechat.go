@@ -55,6 +55,17 @@ var chatUploadBase = "https://chat.googleapis.com/upload/v1/"
 
 func init() {
 	core.RegisterPlatform("googlechat", New)
+	core.RegisterPlatformOptions("googlechat", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "subscription", Type: core.OptionString, Required: true},
+		{Name: "credentials_file", Type: core.OptionString, Required: true},
+		{Name: "session_scope", Type: core.OptionString, Enum: []string{"space", "thread", "user"}},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
 
 // replyContext carries the platform-specific data needed to reply: the space
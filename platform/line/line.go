@@ -18,6 +18,18 @@ import (
 
 func init() {
 	core.RegisterPlatform("line", New)
+	core.RegisterPlatformOptions("line", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "channel_secret", Type: core.OptionString, Required: true},
+		{Name: "channel_token", Type: core.OptionString, Required: true},
+		{Name: "port", Type: core.OptionString},
+		{Name: "callback_path", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
 
 // replyContext stores the user/group ID for push messages.
@@ -22,6 +22,23 @@ import (
 
 func init() {
 	core.RegisterPlatform("matrix", New)
+	core.RegisterPlatformOptions("matrix", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "homeserver", Type: core.OptionString, Required: true},
+		{Name: "access_token", Type: core.OptionString, Required: true},
+		{Name: "user_id", Type: core.OptionString},
+		{Name: "auto_join", Type: core.OptionBool},
+		{Name: "auto_verify", Type: core.OptionBool},
+		{Name: "cross_signing_password", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "group_reply_all", Type: core.OptionBool},
+		{Name: "proxy", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 type replyContext struct {
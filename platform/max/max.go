@@ -20,6 +20,21 @@ import (
 
 func init() {
 	core.RegisterPlatform("max", New)
+	core.RegisterPlatformOptions("max", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "token", Type: core.OptionString, Required: true},
+		{Name: "api_base", Type: core.OptionString},
+		{Name: "webhook_url", Type: core.OptionString},
+		{Name: "webhook_listen", Type: core.OptionString},
+		{Name: "webhook_path", Type: core.OptionString},
+		{Name: "webhook_secret", Type: core.OptionString},
+		{Name: "webhook_resubscribe_interval", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
 
 const (
@@ -21,6 +21,18 @@ import (
 
 func init() {
 	core.RegisterPlatform("qq", New)
+	core.RegisterPlatformOptions("qq", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "ws_url", Type: core.OptionString},
+		{Name: "http_url", Type: core.OptionString},
+		{Name: "token", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 // Platform connects to a OneBot v11 implementation (NapCat, LLOneBot, etc.)
@@ -23,6 +23,20 @@ import (
 
 func init() {
 	core.RegisterPlatform("qqbot", New)
+	core.RegisterPlatformOptions("qqbot", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "app_id", Type: core.OptionString, Required: true},
+		{Name: "app_secret", Type: core.OptionString, Required: true},
+		{Name: "sandbox", Type: core.OptionBool},
+		{Name: "intents", Type: core.OptionInt},
+		{Name: "markdown_support", Type: core.OptionBool},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 const (
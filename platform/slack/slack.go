@@ -22,6 +22,18 @@ import (
 
 func init() {
 	core.RegisterPlatform("slack", New)
+	core.RegisterPlatformOptions("slack", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "bot_token", Type: core.OptionString, Required: true},
+		{Name: "app_token", Type: core.OptionString, Required: true},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "session_scope", Type: core.OptionString, Enum: []string{"user", "channel", "thread"}},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 type replyContext struct {
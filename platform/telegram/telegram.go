@@ -26,6 +26,22 @@ var telegramConvertAudioToOpus = core.ConvertAudioToOpus
 
 func init() {
 	core.RegisterPlatform("telegram", New)
+	core.RegisterPlatformOptions("telegram", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "token", Type: core.OptionString, Required: true},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "group_reply_all", Type: core.OptionBool},
+		{Name: "enable_reactions", Type: core.OptionBool},
+		{Name: "progress_style", Type: core.OptionString, Enum: []string{"legacy", "compact", "card"}},
+		{Name: "proxy", Type: core.OptionString},
+		{Name: "proxy_username", Type: core.OptionString},
+		{Name: "proxy_password", Type: core.OptionString},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 type replyContext struct {
@@ -859,6 +875,7 @@ func (p *Platform) handleCallbackQuery(ctx context.Context, cb *models.CallbackQ
 			MessageID:  strconv.Itoa(msgID),
 			ChannelKey: channelKey,
 			ReplyCtx:   rctx,
+			FromButton: true,
 		})
 		return
 	}
@@ -1268,6 +1285,51 @@ func telegramAudioFileExt(format string) string {
 	}
 }
 
+func inlineKeyboardRows(buttons [][]core.ButtonOption) [][]models.InlineKeyboardButton {
+	var rows [][]models.InlineKeyboardButton
+	for _, row := range buttons {
+		var btns []models.InlineKeyboardButton
+		for _, b := range row {
+			btns = append(btns, models.InlineKeyboardButton{Text: b.Text, CallbackData: b.Data})
+		}
+		rows = append(rows, btns)
+	}
+	return rows
+}
+
+// UpdateWithButtons replaces the text and inline keyboard of the message a
+// callback query came from (rctx.messageID).
+func (p *Platform) UpdateWithButtons(ctx context.Context, rctx any, content string, buttons [][]core.ButtonOption) error {
+	rc, ok := rctx.(replyContext)
+	if !ok || rc.messageID == 0 {
+		return fmt.Errorf("telegram: invalid reply context type %T", rctx)
+	}
+	bot, err := p.connectedBot("update with buttons")
+	if err != nil {
+		return err
+	}
+	params := &tgbot.EditMessageTextParams{
+		ChatID:      rc.chatID,
+		MessageID:   rc.messageID,
+		Text:        core.MarkdownToSimpleHTML(content),
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: inlineKeyboardRows(buttons)},
+	}
+	if _, err := bot.EditMessageText(ctx, params); err != nil {
+		if strings.Contains(err.Error(), "not modified") {
+			return nil
+		}
+		if !strings.Contains(err.Error(), "can't parse") {
+			return err
+		}
+		params.Text = content
+		params.ParseMode = ""
+		_, err = bot.EditMessageText(ctx, params)
+		return err
+	}
+	return nil
+}
+
 // SendWithButtons sends a message with an inline keyboard.
 func (p *Platform) SendWithButtons(ctx context.Context, rctx any, content string, buttons [][]core.ButtonOption) error {
 	rc, ok := rctx.(replyContext)
@@ -1279,14 +1341,7 @@ func (p *Platform) SendWithButtons(ctx context.Context, rctx any, content string
 		return err
 	}
 
-	var rows [][]models.InlineKeyboardButton
-	for _, row := range buttons {
-		var btns []models.InlineKeyboardButton
-		for _, b := range row {
-			btns = append(btns, models.InlineKeyboardButton{Text: b.Text, CallbackData: b.Data})
-		}
-		rows = append(rows, btns)
-	}
+	rows := inlineKeyboardRows(buttons)
 
 	html := core.MarkdownToSimpleHTML(content)
 	params := &tgbot.SendMessageParams{
@@ -24,6 +24,25 @@ import (
 
 func init() {
 	core.RegisterPlatform("tuitui", New)
+	core.RegisterPlatformOptions("tuitui", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "app_id", Type: core.OptionString, Required: true},
+		{Name: "app_secret", Type: core.OptionString, Required: true},
+		{Name: "api_base", Type: core.OptionString},
+		{Name: "ws_base", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "ignore_from", Type: core.OptionString},
+		{Name: "group_allow_from", Type: core.OptionString},
+		{Name: "group_policy", Type: core.OptionString, Enum: []string{"allowlist", "open", "disabled"}},
+		{Name: "require_mention", Type: core.OptionBool},
+		{Name: "receive_reaction", Type: core.OptionString},
+		{Name: "history_limit", Type: core.OptionInt},
+		{Name: "share_session_in_channel", Type: core.OptionBool},
+	},
 }
 
 const (
@@ -18,6 +18,15 @@ import (
 
 func init() {
 	core.RegisterPlatform("webex", New)
+	core.RegisterPlatformOptions("webex", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "token", Type: core.OptionString, Required: true},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
 
 // replyContext carries what Reply/Send need to target a Webex room.
@@ -29,6 +29,29 @@ import (
 
 func init() {
 	core.RegisterPlatform("wecom", New)
+	core.RegisterPlatformOptions("wecom", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "mode", Type: core.OptionString},
+		{Name: "corp_id", Type: core.OptionString},
+		{Name: "corp_secret", Type: core.OptionString},
+		{Name: "agent_id", Type: core.OptionString},
+		{Name: "callback_token", Type: core.OptionString},
+		{Name: "callback_aes_key", Type: core.OptionString},
+		{Name: "port", Type: core.OptionString},
+		{Name: "callback_path", Type: core.OptionString},
+		{Name: "bot_id", Type: core.OptionString},
+		{Name: "bot_secret", Type: core.OptionString},
+		{Name: "api_base_url", Type: core.OptionString},
+		{Name: "enable_markdown", Type: core.OptionBool},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "proxy", Type: core.OptionString},
+		{Name: "proxy_username", Type: core.OptionString},
+		{Name: "proxy_password", Type: core.OptionString},
+	},
 }
 
 // Incoming XML envelope from WeChat Work callback.
@@ -33,6 +33,19 @@ const (
 
 func init() {
 	core.RegisterPlatform("weibo", New)
+	core.RegisterPlatformOptions("weibo", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "app_id", Type: core.OptionString, Required: true},
+		{Name: "app_secret", Type: core.OptionString, Required: true},
+		{Name: "name", Type: core.OptionString},
+		{Name: "token_endpoint", Type: core.OptionString},
+		{Name: "ws_endpoint", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
 
 type replyContext struct {
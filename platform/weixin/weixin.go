@@ -21,6 +21,26 @@ import (
 
 func init() {
 	core.RegisterPlatform("weixin", New)
+	core.RegisterPlatformOptions("weixin", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "token", Type: core.OptionString, Required: true},
+		{Name: "account_id", Type: core.OptionString},
+		{Name: "base_url", Type: core.OptionString},
+		{Name: "cdn_base_url", Type: core.OptionString},
+		{Name: "route_tag", Type: core.OptionString},
+		{Name: "state_dir", Type: core.OptionString},
+		{Name: "long_poll_timeout_ms", Type: core.OptionInt},
+		{Name: "burst_limit", Type: core.OptionInt},
+		{Name: "burst_window_secs", Type: core.OptionInt},
+		{Name: "allow_from", Type: core.OptionString},
+		{Name: "proxy", Type: core.OptionString},
+		{Name: "proxy_username", Type: core.OptionString},
+		{Name: "proxy_password", Type: core.OptionString},
+	},
 }
 
 const (
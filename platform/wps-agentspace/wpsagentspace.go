@@ -107,6 +107,18 @@ type errorData struct {
 // init registers the platform with the core registry.
 func init() {
 	core.RegisterPlatform("wps-agentspace", New)
+	core.RegisterPlatformOptions("wps-agentspace", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "app_id", Type: core.OptionString},
+		{Name: "wps_sid", Type: core.OptionString},
+		{Name: "base_url", Type: core.OptionString},
+		{Name: "device_name", Type: core.OptionString},
+		{Name: "device_uuid", Type: core.OptionString},
+	},
 }
 
 // New creates a new Platform instance.
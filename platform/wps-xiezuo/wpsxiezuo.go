@@ -153,6 +153,18 @@ type reactionRequest struct {
 
 func init() {
 	core.RegisterPlatform("wps-xiezuo", New)
+	core.RegisterPlatformOptions("wps-xiezuo", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "app_id", Type: core.OptionString, Required: true},
+		{Name: "app_secret", Type: core.OptionString, Required: true},
+		{Name: "base_url", Type: core.OptionString},
+		{Name: "clean_reply", Type: core.OptionBool},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
 
 // New creates a new WPS Xiezuo platform from config options.
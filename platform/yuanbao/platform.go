@@ -657,4 +657,16 @@ func (p *Platform) resolvePendingAck(msgID string, data []byte) {
 
 func init() {
 	core.RegisterPlatform("yuanbao", New)
+	core.RegisterPlatformOptions("yuanbao", optionSchema)
+}
+
+// optionSchema lists the options New reads, for `cc-connect config validate`.
+var optionSchema = core.OptionSchema{
+	Options: []core.OptionSpec{
+		{Name: "bot_token", Type: core.OptionString, Required: true},
+		{Name: "api_domain", Type: core.OptionString},
+		{Name: "ws_url", Type: core.OptionString},
+		{Name: "route_env", Type: core.OptionString},
+		{Name: "allow_from", Type: core.OptionString},
+	},
 }
@@ -19,7 +19,7 @@ class ApiClient {
   }
 
   private headers(): HeadersInit {
-    const h: HeadersInit = { 'Content-Type': 'application/json' };
+    const h: HeadersInit = { 'Content-Type': 'application/json', 'X-CC-Connect-Client': 'web' };
     if (this.token) h['Authorization'] = `Bearer ${this.token}`;
     return h;
   }
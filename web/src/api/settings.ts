@@ -17,3 +17,16 @@ export interface GlobalSettings {
 
 export const getGlobalSettings = () => api.get<GlobalSettings>('/settings');
 export const updateGlobalSettings = (body: Partial<GlobalSettings>) => api.patch<GlobalSettings>('/settings', body);
+
+export interface ConfigVersion {
+  id: number;
+  time: string;
+  actor: string;
+  reason?: string;
+  file: string;
+}
+
+export const getConfigHistory = () => api.get<{ versions: ConfigVersion[] }>('/config/history');
+export const getConfigDiff = (id: number, to?: number) =>
+  api.get<{ id: number; to: number; diff: string }>(`/config/history/${id}/diff`, to ? { to: String(to) } : undefined);
+export const rollbackConfig = (version: number) => api.post('/config/rollback', { version });